@@ -0,0 +1,224 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Parameters for AntColony. DefaultACOParams has sensible values to start tuning from.
+type ACOParams struct {
+	// Ants per iteration, and iterations to run
+	Ants, Iterations int
+	// How strongly ants follow pheromone (Alpha) and prefer cheap edges (Beta) when picking the next node
+	Alpha, Beta float64
+	// The fraction of pheromone that evaporates after every iteration, between 0 and 1
+	Evaporation float64
+	// The pheromone an ant deposits, divided by its tour's objective, on every edge of its tour
+	Deposit float64
+	// Ants are simulated concurrently by up to Workers goroutines; 0 or 1 simulates them all on the calling goroutine
+	Workers int
+	// Scores a complete tour (with the start repeated at its end), lower being better. If nil, a tour's objective is its cost. A custom objective can add penalties for the side
+	// constraints of routing problems, e.g. vehicle capacities between depot visits.
+	Objective func(tour []Node) float64
+}
+
+// Returns 20 ants for 100 iterations, with Alpha 1, Beta 2, Evaporation 0.5 and Deposit 1, which is a reasonable start for tours of a few dozen nodes.
+func DefaultACOParams() ACOParams {
+	return ACOParams{Ants: 20, Iterations: 100, Alpha: 1, Beta: 2, Evaporation: 0.5, Deposit: 1}
+}
+
+// Looks for a cheap tour visiting every node of graph exactly once and returning to start -- a travelling salesman tour -- with the ant system[1]. Every iteration, each ant builds
+// a tour by walking from start to a random unvisited successor, picking edges with probability proportional to pheromone^Alpha * (1/cost)^Beta, and afterwards every edge's
+// pheromone evaporates a little and each ant deposits pheromone on the edges it used, more the better its tour. Good edges accumulate pheromone, so later ants favor them.
+//
+// Returns the best tour found (start first and last) along with its objective, or nil and +Inf if no ant completed a tour, which on a sparse graph can happen even if one
+// exists. Like any metaheuristic it gives no guarantee of optimality. The pheromone matrix is dense, so memory is quadratic in the number of nodes. With several workers, the
+// graph's Successors and Cost, and the objective, must be safe to call concurrently. The result depends only on src and the parameters.
+//
+// Cost is resolved as usual: Argument > Interface > UniformCost. Costs must be positive.
+//
+// [1] M. Dorigo, V. Maniezzo and A. Colorni, "Ant system: optimization by a colony of cooperating agents", IEEE Transactions on Systems, Man, and Cybernetics B 26 (1996)
+func AntColony(start Node, graph Graph, Cost func(Node, Node) float64, params ACOParams, src *rand.Rand) (tour []Node, objective float64) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	src = randSource(src)
+
+	nodes := append([]Node(nil), graph.NodeList()...)
+	sort.Sort(byID(nodes))
+	n := len(nodes)
+	index := make(map[int]int, n)
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+
+	// The edges out of each node, in a fixed order, and the pheromone on every ordered pair
+	edges := make([][]acoEdge, n)
+	pheromone := make([][]float64, n)
+	for i, node := range nodes {
+		pheromone[i] = make([]float64, n)
+		for j := range pheromone[i] {
+			pheromone[i][j] = 1
+		}
+		for _, succ := range graph.Successors(node) {
+			cost := Cost(node, succ)
+			edges[i] = append(edges[i], acoEdge{index[succ.ID()], math.Pow(1/cost, params.Beta), cost})
+		}
+		sort.Sort(byTarget(edges[i]))
+	}
+
+	objectiveOf := func(tour []int, cost float64) float64 {
+		if params.Objective == nil {
+			return cost
+		}
+		path := make([]Node, len(tour))
+		for i, v := range tour {
+			path[i] = nodes[v]
+		}
+		return params.Objective(path)
+	}
+
+	// One ant's walk, filling in tours[ant] and objectives[ant] (+Inf if it got stuck)
+	tours := make([][]int, params.Ants)
+	objectives := make([]float64, params.Ants)
+	walk := func(ant int, rnd *rand.Rand, visited []bool, weights []float64) {
+		for i := range visited {
+			visited[i] = false
+		}
+		curr := index[start.ID()]
+		tour := append(tours[ant][:0], curr)
+		visited[curr] = true
+		cost := 0.0
+
+		for len(tour) <= n {
+			var candidates []acoEdge
+			if len(tour) == n {
+				// Only the way home is left
+				for _, e := range edges[curr] {
+					if e.to == tour[0] {
+						candidates = append(candidates, e)
+					}
+				}
+			} else {
+				candidates = edges[curr]
+			}
+
+			total := 0.0
+			weights = weights[:0]
+			for _, e := range candidates {
+				w := 0.0
+				if len(tour) == n || !visited[e.to] {
+					w = math.Pow(pheromone[curr][e.to], params.Alpha) * e.eta
+				}
+				weights = append(weights, w)
+				total += w
+			}
+			if total == 0 {
+				tours[ant], objectives[ant] = tour, math.Inf(1)
+				return
+			}
+
+			pick, r := len(weights)-1, rnd.Float64()*total
+			for i, w := range weights {
+				if r -= w; r < 0 && w > 0 {
+					pick = i
+					break
+				}
+			}
+			for weights[pick] == 0 {
+				pick-- // Rounding left r just past the end; fall back to the last edge with any weight
+			}
+
+			next := candidates[pick]
+			cost += next.cost
+			curr = next.to
+			visited[curr] = true
+			tour = append(tour, curr)
+		}
+
+		tours[ant], objectives[ant] = tour, objectiveOf(tour, cost)
+	}
+
+	workers := params.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var best []int
+	objective = math.Inf(1)
+	seeds := make([]int64, workers)
+	for it := 0; it < params.Iterations; it++ {
+		for i := range seeds {
+			seeds[i] = src.Int63()
+		}
+		simulate := func(chunk, from, to int) {
+			rnd := rand.New(rand.NewSource(seeds[chunk]))
+			visited, weights := make([]bool, n), make([]float64, 0, n)
+			for ant := from; ant < to; ant++ {
+				walk(ant, rnd, visited, weights)
+			}
+		}
+		if workers == 1 {
+			simulate(0, 0, params.Ants)
+		} else {
+			inParallel(workers, params.Ants, simulate)
+		}
+
+		for i := range pheromone {
+			for j := range pheromone[i] {
+				pheromone[i][j] *= 1 - params.Evaporation
+			}
+		}
+		for ant, tour := range tours {
+			if math.IsInf(objectives[ant], 1) {
+				continue
+			}
+			if objectives[ant] < objective {
+				best, objective = append(best[:0], tour...), objectives[ant]
+			}
+
+			deposit := params.Deposit / objectives[ant]
+			for i := 0; i+1 < len(tour); i++ {
+				pheromone[tour[i]][tour[i+1]] += deposit
+				if !graph.IsDirected() {
+					pheromone[tour[i+1]][tour[i]] += deposit
+				}
+			}
+		}
+	}
+
+	if best == nil {
+		return nil, math.Inf(1)
+	}
+	tour = make([]Node, len(best))
+	for i, v := range best {
+		tour[i] = nodes[v]
+	}
+
+	return tour, objective
+}
+
+// An edge out of a node in AntColony, to the node with index to, with its heuristic desirability (1/cost)^Beta
+type acoEdge struct {
+	to   int
+	eta  float64
+	cost float64
+}
+
+type byTarget []acoEdge
+
+func (b byTarget) Len() int {
+	return len(b)
+}
+
+func (b byTarget) Less(i, j int) bool {
+	return b[i].to < b[j].to
+}
+
+func (b byTarget) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
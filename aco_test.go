@@ -0,0 +1,92 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// A complete graph on n points evenly spaced around the unit circle, whose shortest tour goes around the circle. n must not be a multiple of 3
+func circleGraph(n int) *graph.GonumGraph {
+	g := graph.NewGonumGraph(false)
+	points := make([]graph.PointNode, n)
+	for i := range points {
+		// Scrambled so the IDs don't give the tour away
+		angle := 2 * math.Pi * float64((i*3)%n) / float64(n)
+		points[i] = graph.PointNode{Id: i, X: math.Cos(angle), Y: math.Sin(angle)}
+		g.AddNode(points[i], nil)
+	}
+	for i := range points {
+		for j := i + 1; j < n; j++ {
+			edge := graph.GonumEdge{points[i], points[j]}
+			g.AddEdge(edge)
+			g.SetEdgeCost(edge, graph.EuclideanDistance(points[i], points[j]))
+		}
+	}
+
+	return g
+}
+
+func isTour(tour []graph.Node, g graph.Graph) bool {
+	seen := make(map[int]bool)
+	for _, node := range tour[1:] {
+		seen[node.ID()] = true
+	}
+	return tour[0].ID() == tour[len(tour)-1].ID() && len(seen) == len(g.NodeList()) && len(tour) == len(seen)+1 && graph.IsPath(tour, g)
+}
+
+func TestAntColonyCircle(t *testing.T) {
+	const n = 10
+	g := circleGraph(n)
+	optimal := n * 2 * math.Sin(math.Pi/n)
+
+	for _, workers := range []int{1, 4} {
+		params := graph.DefaultACOParams()
+		params.Workers = workers
+		tour, cost := graph.AntColony(graph.GonumNode(0), g, nil, params, rand.New(rand.NewSource(1)))
+		if !isTour(tour, g) {
+			t.Fatalf("%d workers: %v isn't a tour", workers, tour)
+		}
+		if math.Abs(cost-optimal) > 1e-9 {
+			t.Errorf("%d workers: found a tour of cost %v, optimal is %v", workers, cost, optimal)
+		}
+
+		again, _ := graph.AntColony(graph.GonumNode(0), g, nil, params, rand.New(rand.NewSource(1)))
+		if !reflect.DeepEqual(tour, again) {
+			t.Errorf("%d workers: the same seed found different tours", workers)
+		}
+	}
+}
+
+func TestAntColonyObjective(t *testing.T) {
+	// Penalizing the edge between 0 and its neighbor 7 on the circle forces the tour off it
+	g := circleGraph(10)
+	params := graph.DefaultACOParams()
+	params.Objective = func(tour []graph.Node) float64 {
+		cost := 0.0
+		for i := 0; i+1 < len(tour); i++ {
+			cost += g.Cost(tour[i], tour[i+1])
+			if a, b := tour[i].ID(), tour[i+1].ID(); a+b == 7 && (a == 0 || b == 0) {
+				cost += 100
+			}
+		}
+		return cost
+	}
+
+	tour, cost := graph.AntColony(graph.GonumNode(0), g, nil, params, rand.New(rand.NewSource(1)))
+	if !isTour(tour, g) || cost >= 100 {
+		t.Errorf("Found tour %v with objective %v, which should avoid the penalty", tour, cost)
+	}
+}
+
+func TestAntColonyNoTour(t *testing.T) {
+	// A path has no tour
+	g := graph.NewGonumGraph(false)
+	g.AddNode(graph.GonumNode(0), []graph.Node{graph.GonumNode(1)})
+	g.AddEdge(graph.GonumEdge{graph.GonumNode(1), graph.GonumNode(2)})
+	if tour, cost := graph.AntColony(graph.GonumNode(0), g, nil, graph.DefaultACOParams(), rand.New(rand.NewSource(1))); tour != nil || !math.IsInf(cost, 1) {
+		t.Errorf("Found tour %v of cost %v in a path", tour, cost)
+	}
+}
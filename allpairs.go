@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// AllShortestPaths holds the shortest path between every pair of nodes in a graph, as FloydWarshall and JohnsonAllPairs find them: a matrix of the distances, and a matrix of
+// the node before the last on each path, from which any path is read back in time proportional to its length. Both take n^2 words, so they're for graphs of thousands of
+// nodes, not millions; for those, see Landmarks and DistanceOracle.
+//
+// Exact distances make the best admissible heuristic there is, so HeuristicCost can be handed to AStar or InitDStar as it is. Distances that were exact when they were worked out
+// stay admissible as long as costs only go up and no edges are added, which is what usually happens to a robot's map as it finds obstacles.
+type AllShortestPaths struct {
+	nodes []Node      // In order of ID
+	index map[int]int // From ID to position in nodes
+	dist  []float64   // dist[i*n+j] is the distance from nodes[i] to nodes[j]
+	pred  []int32     // pred[i*n+j] is the position of the node before nodes[j] on the path from nodes[i], or -1 if there's no path, or i == j
+}
+
+func newAllShortestPaths(graph Graph) *AllShortestPaths {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	n := len(nodes)
+	paths := &AllShortestPaths{nodes: nodes, index: make(map[int]int, n), dist: make([]float64, n*n), pred: make([]int32, n*n)}
+	for i, node := range nodes {
+		paths.index[node.ID()] = i
+	}
+	for k := range paths.dist {
+		paths.dist[k], paths.pred[k] = math.Inf(1), -1
+	}
+	for i := range nodes {
+		paths.dist[i*n+i] = 0
+	}
+	return paths
+}
+
+// Finds the shortest path between every pair of nodes by the Floyd-Warshall algorithm, in O(n^3) time whatever the number of edges, which makes it the one to use on dense
+// graphs; on sparse ones, JohnsonAllPairs is faster. Cost is interpreted as in AStar, and may be negative. If the graph has a cycle of negative total cost, there are no
+// shortest paths through it, and the error is a *NegativeCycleError holding one; in an undirected graph a negative edge is such a cycle on its own.
+func FloydWarshall(graph Graph, Cost func(Node, Node) float64) (*AllShortestPaths, error) {
+	paths := newAllShortestPaths(graph)
+	n := len(paths.nodes)
+	visit := successorVisitor(graph, Cost)
+	for i, node := range paths.nodes {
+		visit(node, func(succ Node, cost float64) bool {
+			if j, ok := paths.index[succ.ID()]; ok && cost < paths.dist[i*n+j] {
+				paths.dist[i*n+j], paths.pred[i*n+j] = cost, int32(i)
+			}
+			return true
+		})
+	}
+
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			dik := paths.dist[i*n+k]
+			if math.IsInf(dik, 1) {
+				continue
+			}
+			row, krow := paths.dist[i*n:(i+1)*n], paths.dist[k*n:(k+1)*n]
+			for j, dkj := range krow {
+				if d := dik + dkj; d < row[j] {
+					row[j], paths.pred[i*n+j] = d, paths.pred[k*n+j]
+				}
+			}
+			// A node that's found a way back to itself for less than nothing is on a negative cycle, which Bellman-Ford can hand back in a usable form
+			if row[i] < 0 {
+				if _, err := BellmanFordTree(paths.nodes[i], graph, Cost); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// Finds the shortest path between every pair of nodes by Johnson's algorithm: Bellman-Ford finds a potential for every node that makes every edge's cost non-negative once
+// the potentials of its ends are taken into account, and then Dijkstra's algorithm runs from every node. That's O(nm log n), far less than FloydWarshall's O(n^3) on a sparse
+// graph, and like it, Cost may be negative. A negative cycle is reported in the same way.
+func JohnsonAllPairs(graph Graph, Cost func(Node, Node) float64) (*AllShortestPaths, error) {
+	paths := newAllShortestPaths(graph)
+	n := len(paths.nodes)
+
+	type arc struct {
+		head, tail int
+		cost       float64
+	}
+	var arcs []arc
+	visit := successorVisitor(graph, Cost)
+	for i, node := range paths.nodes {
+		visit(node, func(succ Node, cost float64) bool {
+			if j, ok := paths.index[succ.ID()]; ok {
+				arcs = append(arcs, arc{i, j, cost})
+			}
+			return true
+		})
+	}
+
+	// The potentials are the distances from a node with an edge costing 0 to every other, which is the same as starting every distance at 0. If the nth round still changes
+	// something there's a negative cycle, and it's behind the last node changed.
+	potential := make([]float64, n)
+	pred := make(map[int]Node)
+	for round := 0; round < n; round++ {
+		var changed Node
+		for _, a := range arcs {
+			if d := potential[a.head] + a.cost; d < potential[a.tail] {
+				potential[a.tail] = d
+				pred[paths.nodes[a.tail].ID()] = paths.nodes[a.head]
+				changed = paths.nodes[a.tail]
+			}
+		}
+		if changed == nil {
+			break
+		}
+		if round == n-1 {
+			return nil, &NegativeCycleError{Cycle: predecessorCycle(pred, changed, n)}
+		}
+	}
+
+	if Cost == nil {
+		Cost = UniformCost
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		}
+	}
+	// Reweighted, every edge costs at least 0, give or take rounding, which mustn't be allowed to make one negative
+	reweighted := func(u, v Node) float64 {
+		i, j := paths.index[u.ID()], paths.index[v.ID()]
+		return math.Max(Cost(u, v)+potential[i]-potential[j], 0)
+	}
+	for i, source := range paths.nodes {
+		tree := DijkstraTree(source, graph, reweighted)
+		for _, node := range tree.Order {
+			j := paths.index[node.ID()]
+			paths.dist[i*n+j] = tree.dist[node.ID()] - potential[i] + potential[j]
+			if pred, ok := tree.pred[node.ID()]; ok {
+				paths.pred[i*n+j] = int32(paths.index[pred.ID()])
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// The cost of the shortest path from u to v, or +Inf if there's none or either isn't in the graph.
+func (paths *AllShortestPaths) Dist(u, v Node) float64 {
+	i, ok1 := paths.index[u.ID()]
+	j, ok2 := paths.index[v.ID()]
+	if !ok1 || !ok2 {
+		return math.Inf(1)
+	}
+	return paths.dist[i*len(paths.nodes)+j]
+}
+
+// The shortest path from u to v, both included, and its cost; nil and +Inf if there's none or either isn't in the graph. The path is the caller's own.
+func (paths *AllShortestPaths) Path(u, v Node) ([]Node, float64) {
+	d := paths.Dist(u, v)
+	if math.IsInf(d, 1) {
+		return nil, d
+	}
+
+	n := len(paths.nodes)
+	i, j := paths.index[u.ID()], paths.index[v.ID()]
+	path := []Node{paths.nodes[j]}
+	for j != i {
+		j = int(paths.pred[i*n+j])
+		path = append(path, paths.nodes[j])
+	}
+	for a, b := 0, len(path)-1; a < b; a, b = a+1, b-1 {
+		path[a], path[b] = path[b], path[a]
+	}
+	return path, d
+}
+
+// The nodes the paths are between, in order of ID.
+func (paths *AllShortestPaths) Nodes() []Node {
+	return append([]Node(nil), paths.nodes...)
+}
+
+// The distance from a to b as a heuristic: Dist, except that it's 0 if either node wasn't in the graph, since nothing's known about the distance to a node added since.
+func (paths *AllShortestPaths) HeuristicCost(a, b Node) float64 {
+	i, ok1 := paths.index[a.ID()]
+	j, ok2 := paths.index[b.ID()]
+	if !ok1 || !ok2 {
+		return 0
+	}
+	return paths.dist[i*len(paths.nodes)+j]
+}
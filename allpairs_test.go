@@ -0,0 +1,132 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A random digraph with some negative costs but no negative cycles: every cost is a non-negative one plus the difference of its ends' random potentials
+func potentialGraph(n int, p float64, seed int64) *graph.GonumGraph {
+	src := rand.New(rand.NewSource(seed))
+	g := randomWeightedGraph(n, p, seed)
+	potential := make(map[int]float64)
+	for _, node := range g.NodeList() {
+		potential[node.ID()] = src.Float64() * 10
+	}
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, g.Cost(edge.Head(), edge.Tail())+potential[edge.Head().ID()]-potential[edge.Tail().ID()])
+	}
+	return g
+}
+
+func TestAllPairs(t *testing.T) {
+	g := potentialGraph(60, 0.08, 3)
+	g.AddNode(graph.GonumNode(100), nil)
+	_, johnsonCosts, aborted := graph.Johnson(g, nil)
+	if aborted {
+		t.Fatal("Johnson found a negative cycle")
+	}
+
+	for name, allPairs := range map[string]func(graph.Graph, func(graph.Node, graph.Node) float64) (*graph.AllShortestPaths, error){
+		"FloydWarshall": graph.FloydWarshall, "JohnsonAllPairs": graph.JohnsonAllPairs} {
+		paths, err := allPairs(g, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(paths.Nodes()) != len(g.NodeList()) {
+			t.Errorf("%s: has paths between %d nodes, expected %d", name, len(paths.Nodes()), len(g.NodeList()))
+		}
+		for _, u := range g.NodeList() {
+			tree, err := graph.BellmanFordTree(u, g, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range g.NodeList() {
+				want := tree.DistTo(v)
+				if d := paths.Dist(u, v); math.Abs(d-want) > 1e-9 || math.IsInf(want, 1) != math.IsInf(d, 1) {
+					t.Fatalf("%s: from %v to %v is %v, expected %v", name, u, v, d, want)
+				}
+				if c, ok := johnsonCosts[u.ID()][v.ID()]; ok && math.Abs(c-want) > 1e-9 {
+					t.Errorf("Johnson: from %v to %v is %v, expected %v", u, v, c, want)
+				}
+
+				path, cost := paths.Path(u, v)
+				if math.IsInf(want, 1) {
+					if path != nil {
+						t.Errorf("%s: found the path %v from %v to %v, which can't be reached", name, nodeIDs(path), u, v)
+					}
+					continue
+				}
+				sum := 0.0
+				for i := 1; i < len(path); i++ {
+					sum += g.Cost(path[i-1], path[i])
+				}
+				if path[0].ID() != u.ID() || path[len(path)-1].ID() != v.ID() || !graph.IsPath(path, g) || math.Abs(sum-cost) > 1e-9 || cost != paths.Dist(u, v) {
+					t.Fatalf("%s: bad path %v costing %v (adds up to %v) from %v to %v", name, nodeIDs(path), cost, sum, u, v)
+				}
+			}
+		}
+	}
+}
+
+func TestAllPairsNegativeCycle(t *testing.T) {
+	g := weightedDigraph([][3]float64{{0, 1, 2}, {1, 2, -3}, {2, 3, 1}, {3, 1, 3}, {3, 4, 5}})
+	for name, allPairs := range map[string]func(graph.Graph, func(graph.Node, graph.Node) float64) (*graph.AllShortestPaths, error){
+		"FloydWarshall": graph.FloydWarshall, "JohnsonAllPairs": graph.JohnsonAllPairs} {
+		if _, err := allPairs(g, nil); err != nil {
+			t.Errorf("%s: %v with no negative cycle", name, err)
+		}
+	}
+
+	// 1 -> 2 -> 3 -> 1 now costs -1
+	g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(3), T: graph.GonumNode(1)}, 1)
+	for name, allPairs := range map[string]func(graph.Graph, func(graph.Node, graph.Node) float64) (*graph.AllShortestPaths, error){
+		"FloydWarshall": graph.FloydWarshall, "JohnsonAllPairs": graph.JohnsonAllPairs} {
+		_, err := allPairs(g, nil)
+		nerr, ok := err.(*graph.NegativeCycleError)
+		if !ok {
+			t.Fatalf("%s: expected a NegativeCycleError, got %v", name, err)
+		}
+		total := 0.0
+		for i, node := range nerr.Cycle {
+			next := nerr.Cycle[(i+1)%len(nerr.Cycle)]
+			if !g.IsSuccessor(node, next) {
+				t.Fatalf("%s: %v isn't a cycle", name, nodeIDs(nerr.Cycle))
+			}
+			total += g.Cost(node, next)
+		}
+		if total >= 0 {
+			t.Errorf("%s: the cycle %v costs %v", name, nodeIDs(nerr.Cycle), total)
+		}
+	}
+}
+
+func TestAllPairsHeuristic(t *testing.T) {
+	g := randomWeightedGraph(120, 0.04, 7)
+	paths, err := graph.JohnsonAllPairs(g, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The exact distances lead A* straight along the path, and keep D*-Lite's plans right as costs go up
+	start, goal := graph.GonumNode(0), graph.GonumNode(119)
+	_, want, blind := graph.AStar(start, goal, g, nil, nil)
+	path, cost, expanded := graph.AStar(start, goal, g, nil, paths.HeuristicCost)
+	if math.Abs(cost-want) > 1e-9 || expanded > len(path) || expanded >= blind {
+		t.Errorf("Expected a path costing %v expanding %d nodes, got one costing %v expanding %d (%d without the heuristic)", want, len(path), cost, expanded, blind)
+	}
+
+	ds := graph.InitDStar(start, goal, g, nil, paths.HeuristicCost)
+	for i := 1; i < len(path); i++ {
+		edge := graph.GonumEdge{H: path[i-1], T: path[i]}
+		g.SetEdgeCost(edge, g.Cost(edge.H, edge.T)+5)
+		ds.Update(nil, []graph.Edge{edge})
+	}
+	_, want, _ = graph.AStar(start, goal, g, nil, nil)
+	if _, cost, err := ds.Path(); err != nil || math.Abs(cost-want) > 1e-9 {
+		t.Errorf("Expected D*-Lite's plan to cost %v once the path got dearer, got %v (%v)", want, cost, err)
+	}
+}
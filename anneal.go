@@ -0,0 +1,298 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+)
+
+// A problem for Anneal: a current solution with an energy to minimize, and a way of proposing random changes to it. Implementations remember the move they last proposed, so Anneal
+// can accept it without the change having to be recomputed.
+type AnnealingState interface {
+	// The current solution's energy.
+	Energy() float64
+	// Picks a random move and returns how much it would change the energy. The move isn't made until Accept is called; a move that isn't accepted is forgotten at the next Propose.
+	Propose(src *rand.Rand) (delta float64)
+	// Makes the last proposed move.
+	Accept()
+	// Records the current solution as the best one, to be returned when annealing finishes. Anneal calls it whenever the energy reaches a new low.
+	Save()
+}
+
+// How Anneal cools down: over Steps proposed moves, the temperature falls geometrically from Initial to Final. If Initial is 0 it's picked by sampling moves, so that about 80% of
+// moves that make things worse are accepted at first; if Final is 0 it's a thousandth of Initial.
+type AnnealingSchedule struct {
+	Initial, Final float64
+	Steps          int
+}
+
+// Minimizes the energy of state by simulated annealing[1]: a random walk over solutions that always takes moves that lower the energy, and takes a move raising it by delta
+// with probability exp(-delta/T). T, the temperature, starts high enough that the walk roams freely, and falls gradually so that it settles into a deep minimum instead of the
+// nearest one. The best solution seen is Saved in state; returns its energy and the number of moves accepted.
+//
+// AnnealTour, AnnealBisection and AnnealColoring are ready-made states for common graph problems, and show how to implement one.
+//
+// [1] S. Kirkpatrick, C. D. Gelatt and M. P. Vecchi, "Optimization by simulated annealing", Science 220 (1983)
+func Anneal(state AnnealingState, schedule AnnealingSchedule, src *rand.Rand) (best float64, accepted int) {
+	src = randSource(src)
+	temp, final := schedule.Initial, schedule.Final
+	if temp == 0 {
+		temp = initialTemperature(state, src)
+	}
+	if final == 0 {
+		final = temp / 1000
+	}
+	cooling := 1.0
+	if schedule.Steps > 1 && temp > 0 {
+		cooling = math.Pow(final/temp, 1/float64(schedule.Steps-1))
+	}
+
+	energy := state.Energy()
+	best = energy
+	state.Save()
+	for step := 0; step < schedule.Steps; step, temp = step+1, temp*cooling {
+		delta := state.Propose(src)
+		if delta > 0 && (temp <= 0 || src.Float64() >= math.Exp(-delta/temp)) {
+			continue
+		}
+
+		state.Accept()
+		accepted++
+		energy += delta
+		if energy < best {
+			best = energy
+			state.Save()
+		}
+	}
+
+	return best, accepted
+}
+
+// Picks a temperature at which about 80% of uphill moves would be accepted, from the average of a sample of them
+func initialTemperature(state AnnealingState, src *rand.Rand) float64 {
+	total, uphill := 0.0, 0
+	for i := 0; i < 100; i++ {
+		if delta := state.Propose(src); delta > 0 && !math.IsInf(delta, 1) {
+			total += delta
+			uphill++
+		}
+	}
+	if uphill == 0 {
+		return 1
+	}
+
+	return -(total / float64(uphill)) / math.Log(0.8)
+}
+
+// Improves a tour (a cycle through nodes, given without repeating the first node at the end) with 2-opt moves, which reverse a stretch of the tour. The tour returned is the best
+// found, with its start repeated at the end as AntColony returns them; its cost includes the edge back to the start.
+//
+// Costs must be symmetric, since reversing a stretch of the tour reverses the edges along it, and come from Cost, the graph's Coster, or UniformCost as usual. A pair of nodes
+// with no edge between them costs +Inf, so moves that would need such an edge are never taken, but the initial tour must be a valid one.
+func AnnealTour(graph Graph, Cost func(Node, Node) float64, tour []Node, schedule AnnealingSchedule, src *rand.Rand) (best []Node, cost float64) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	state := &tourState{tour: append([]Node(nil), tour...), cost: func(a, b Node) float64 {
+		if !graph.IsSuccessor(a, b) {
+			return math.Inf(1)
+		}
+		return Cost(a, b)
+	}}
+	if len(tour) < 4 {
+		// No 2-opt move changes a triangle
+		state.Save()
+		return append(state.best, state.best[0]), state.Energy()
+	}
+
+	cost, _ = Anneal(state, schedule, src)
+	return append(state.best, state.best[0]), cost
+}
+
+type tourState struct {
+	tour, best []Node
+	cost       func(a, b Node) float64
+	i, j       int
+}
+
+func (s *tourState) Energy() float64 {
+	total := 0.0
+	for i, node := range s.tour {
+		total += s.cost(node, s.tour[(i+1)%len(s.tour)])
+	}
+	return total
+}
+
+// Reversing tour[i..j] replaces the edges (i-1, i) and (j, j+1) with (i-1, j) and (i, j+1)
+func (s *tourState) Propose(src *rand.Rand) float64 {
+	n := len(s.tour)
+	s.i, s.j = 1+src.Intn(n-1), 1+src.Intn(n-1)
+	for s.i == s.j {
+		s.j = 1 + src.Intn(n-1)
+	}
+	if s.i > s.j {
+		s.i, s.j = s.j, s.i
+	}
+
+	before, first, last, after := s.tour[s.i-1], s.tour[s.i], s.tour[s.j], s.tour[(s.j+1)%n]
+	if before.ID() == after.ID() {
+		return 0 // The whole tour but one node, which just changes direction
+	}
+	return s.cost(before, last) + s.cost(first, after) - s.cost(before, first) - s.cost(last, after)
+}
+
+func (s *tourState) Accept() {
+	for i, j := s.i, s.j; i < j; i, j = i+1, j-1 {
+		s.tour[i], s.tour[j] = s.tour[j], s.tour[i]
+	}
+}
+
+func (s *tourState) Save() {
+	s.best = append(s.best[:0], s.tour...)
+}
+
+// Bisects the graph into halves of equal size (to within one node) with few edges between them, like KernighanLin, by annealing over swaps of a node from each half. The
+// initial split is random. Edge weights and the returned cut are as in KernighanLin.
+func AnnealBisection(graph Graph, Cost func(Node, Node) float64, schedule AnnealingSchedule, src *rand.Rand) (partA, partB []Node, cut float64) {
+	src = randSource(src)
+	pg := newPartGraph(graph, Cost)
+	n := len(pg.nodes)
+
+	state := &bisectionState{pg: pg, part: make([]int, n)}
+	for i, v := range src.Perm(n) {
+		if i >= n/2 {
+			state.part[v] = 1
+			state.sides[1] = append(state.sides[1], v)
+		} else {
+			state.sides[0] = append(state.sides[0], v)
+		}
+	}
+	if len(state.sides[0]) == 0 {
+		state.Save()
+	} else {
+		cut, _ = Anneal(state, schedule, src)
+	}
+
+	for i, p := range state.best {
+		if p == 0 {
+			partA = append(partA, pg.nodes[i])
+		} else {
+			partB = append(partB, pg.nodes[i])
+		}
+	}
+
+	return partA, partB, pg.cut(state.best)
+}
+
+type bisectionState struct {
+	pg         *partGraph
+	part, best []int
+	sides      [2][]int // The nodes in each part
+	a, b       int      // Indices into sides of the proposed swap
+}
+
+func (s *bisectionState) Energy() float64 {
+	return s.pg.cut(s.part)
+}
+
+func (s *bisectionState) Propose(src *rand.Rand) float64 {
+	s.a, s.b = src.Intn(len(s.sides[0])), src.Intn(len(s.sides[1]))
+	u, v := s.sides[0][s.a], s.sides[1][s.b]
+
+	// Each node's edges within its part become cut and its cut edges become internal, except the edge between the two, which stays cut
+	delta := 2 * s.pg.adj[u][v]
+	for _, node := range [2]int{u, v} {
+		for neighbor, w := range s.pg.adj[node] {
+			if s.part[neighbor] == s.part[node] {
+				delta += w
+			} else {
+				delta -= w
+			}
+		}
+	}
+
+	return delta
+}
+
+func (s *bisectionState) Accept() {
+	u, v := s.sides[0][s.a], s.sides[1][s.b]
+	s.part[u], s.part[v] = 1, 0
+	s.sides[0][s.a], s.sides[1][s.b] = v, u
+}
+
+func (s *bisectionState) Save() {
+	s.best = append(s.best[:0], s.part...)
+}
+
+// Colors the graph's nodes with k colors (0 to k-1) so that as few edges as possible join nodes of the same color, by annealing over recoloring single nodes. Returns the colors
+// by node ID and the number of conflicting edges left, which is 0 for a proper coloring; direction is ignored and self loops don't count. The initial coloring is random.
+func AnnealColoring(graph Graph, k int, schedule AnnealingSchedule, src *rand.Rand) (colors map[int]int, conflicts int) {
+	src = randSource(src)
+	pg := newPartGraph(graph, func(Node, Node) float64 { return 1 })
+
+	state := &coloringState{pg: pg, k: k, color: make([]int, len(pg.nodes))}
+	for i := range state.color {
+		state.color[i] = src.Intn(k)
+	}
+	if k < 2 || len(pg.nodes) == 0 {
+		state.Save()
+	} else {
+		Anneal(state, schedule, src)
+	}
+
+	colors = make(map[int]int, len(pg.nodes))
+	for i, c := range state.best {
+		colors[pg.nodes[i].ID()] = c
+	}
+	state.color = state.best
+
+	return colors, int(state.Energy())
+}
+
+type coloringState struct {
+	pg            *partGraph
+	k             int
+	color, best   []int
+	node, recolor int
+}
+
+// Counts each conflicting pair of neighbors once, however many edges join them
+func (s *coloringState) Energy() float64 {
+	total := 0
+	for i, neighbors := range s.pg.adj {
+		for j := range neighbors {
+			if i < j && s.color[i] == s.color[j] {
+				total++
+			}
+		}
+	}
+	return float64(total)
+}
+
+func (s *coloringState) Propose(src *rand.Rand) float64 {
+	s.node = src.Intn(len(s.color))
+	s.recolor = (s.color[s.node] + 1 + src.Intn(s.k-1)) % s.k
+
+	delta := 0
+	for neighbor := range s.pg.adj[s.node] {
+		switch s.color[neighbor] {
+		case s.color[s.node]:
+			delta--
+		case s.recolor:
+			delta++
+		}
+	}
+	return float64(delta)
+}
+
+func (s *coloringState) Accept() {
+	s.color[s.node] = s.recolor
+}
+
+func (s *coloringState) Save() {
+	s.best = append(s.best[:0], s.color...)
+}
@@ -0,0 +1,77 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestAnnealTour(t *testing.T) {
+	const n = 13
+	g := circleGraph(n)
+	optimal := n * 2 * math.Sin(math.Pi/n)
+
+	// Visiting in order of ID zigzags across the circle
+	initial := make([]graph.Node, n)
+	for i := range initial {
+		initial[i] = graph.GonumNode(i)
+	}
+	tour, cost := graph.AnnealTour(g, nil, initial, graph.AnnealingSchedule{Steps: 20000}, rand.New(rand.NewSource(1)))
+	if !isTour(tour, g) {
+		t.Fatalf("%v isn't a tour", tour)
+	}
+	if math.Abs(cost-optimal) > 1e-9 {
+		t.Errorf("Annealed tour costs %v, optimal is %v", cost, optimal)
+	}
+}
+
+func TestAnnealBisection(t *testing.T) {
+	// Two 8-cliques joined by a single edge
+	g := graph.NewGonumGraph(false)
+	for c := 0; c < 2; c++ {
+		for i := 0; i < 8; i++ {
+			g.AddNode(graph.GonumNode(c*8+i), nil)
+			for j := 0; j < i; j++ {
+				g.AddEdge(graph.GonumEdge{graph.GonumNode(c*8 + i), graph.GonumNode(c*8 + j)})
+			}
+		}
+	}
+	g.AddEdge(graph.GonumEdge{graph.GonumNode(0), graph.GonumNode(8)})
+
+	partA, partB, cut := graph.AnnealBisection(g, nil, graph.AnnealingSchedule{Steps: 5000}, rand.New(rand.NewSource(1)))
+	if len(partA) != 8 || len(partB) != 8 || cut != 1 {
+		t.Errorf("Got parts of %d and %d nodes cutting %v, want the two cliques cutting 1", len(partA), len(partB), cut)
+	}
+}
+
+func TestAnnealColoring(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		build func(graph.MutableGraph)
+		k     int
+	}{
+		{"even cycle", func(g graph.MutableGraph) { graph.CycleGraph(g, 20, false) }, 2},
+		{"Petersen graph", graph.PetersenGraph, 3},
+		{"hypercube", func(g graph.MutableGraph) { graph.HypercubeGraph(g, 4) }, 2},
+	} {
+		g := graph.NewGonumGraph(false)
+		test.build(g)
+		colors, conflicts := graph.AnnealColoring(g, test.k, graph.AnnealingSchedule{Steps: 20000}, rand.New(rand.NewSource(1)))
+		if conflicts != 0 {
+			t.Errorf("%s: %d conflicts left with %d colors", test.name, conflicts, test.k)
+		}
+		for _, edge := range g.EdgeList() {
+			if colors[edge.Head().ID()] == colors[edge.Tail().ID()] {
+				t.Errorf("%s: edge %v joins two nodes colored %d", test.name, edge, colors[edge.Head().ID()])
+			}
+		}
+	}
+
+	// An odd cycle can't be 2-colored; the best has one conflict
+	g := graph.NewGonumGraph(false)
+	graph.CycleGraph(g, 9, false)
+	if _, conflicts := graph.AnnealColoring(g, 2, graph.AnnealingSchedule{Steps: 20000}, rand.New(rand.NewSource(1))); conflicts != 1 {
+		t.Errorf("2-coloring a 9-cycle left %d conflicts, want 1", conflicts)
+	}
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// How much ImprovePath lowers epsilon after each search it finishes.
+const anytimeEpsilonStep = 0.5
+
+// An AnytimeDStarInstance runs Anytime D*[1] (AD*), D*-Lite with its heuristic inflated by a factor epsilon >= 1, for a robot that has to move on a deadline. An inflated heuristic
+// pulls the search straight at the start, so it finishes far sooner, and the plan it leaves costs at most epsilon times the shortest path's. Given time, ImprovePath lowers
+// epsilon and repairs the plan, reusing what the searches before learned, until it's optimal at epsilon = 1.
+//
+// It's a DStarInstance and is driven the same way, with Step and Update, but a search at a large epsilon is cheap enough that every change is searched at the current epsilon
+// before the plan is improved again. The usual cycle is to Step, Update with whatever changed, then ImprovePath with what's left of the planning budget; when a change is big,
+// raising epsilon with SetEpsilon first keeps the repair quick. Options and the methods it shares with DStarInstance work as they do there.
+//
+// [1] M. Likhachev, D. Ferguson, G. Gordon, A. Stentz, S. Thrun, "Anytime Dynamic A*: An Anytime, Replanning Algorithm", ICAPS 2005
+type AnytimeDStarInstance struct {
+	*DStarInstance
+}
+
+// What AD* keeps on top of D*-Lite: epsilon, the nodes each search has expanded, which it mustn't queue again, and the ones it had to leave inconsistent because of that,
+// which wait for the next search.
+type anytimeState struct {
+	epsilon float64
+	bound   float64 // epsilon of the last search that finished since the graph changed, or +Inf
+	closed  map[int]struct{}
+	incons  []Node
+	rekey   []Node // Reused by reopen
+}
+
+func (a *anytimeState) reset() {
+	a.bound = math.Inf(1)
+	a.incons = a.incons[:0]
+	for id := range a.closed {
+		delete(a.closed, id)
+	}
+}
+
+func (a *anytimeState) isClosed(node Node) bool {
+	_, ok := a.closed[node.ID()]
+	return ok
+}
+
+// Initializes AD* and runs its first search at epsilon, which is clamped to at least 1, as InitDStar does for D*-Lite. Any options are applied first, so a budget (see
+// WithDStarBudget) bounds this search too.
+func InitAnytimeDStar(start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64, epsilon float64, options ...DStarOption) *AnytimeDStarInstance {
+	ds := newDStar(start, goal, graph, Cost, HeuristicCost, options)
+	ds.anytime = &anytimeState{epsilon: math.Max(epsilon, 1), closed: make(map[int]struct{})}
+	ds.initialize()
+	return &AnytimeDStarInstance{ds}
+}
+
+// Returns the factor the heuristic is inflated by in the next search.
+func (ads *AnytimeDStarInstance) Epsilon() float64 {
+	return ads.anytime.epsilon
+}
+
+// Sets the factor the heuristic is inflated by from the next search on, clamped to at least 1. Nothing is searched until then: the next Update or ImprovePath rekeys the queue
+// for it. Raising epsilon before a big change makes the repair cheaper at the price of a worse plan, which ImprovePath then works back down from.
+func (ads *AnytimeDStarInstance) SetEpsilon(epsilon float64) {
+	ads.anytime.epsilon = math.Max(epsilon, 1)
+}
+
+// Returns how far from optimal the current plan may be: the epsilon of the last search to finish since the graph last changed, so the plan costs at most Bound times the
+// shortest path's. It's +Inf while a search after a change is unfinished. A search that's cut short while improving the plan leaves the bound as it was, since with no changes
+// it can only make the plan cheaper.
+func (ads *AnytimeDStarInstance) Bound() float64 {
+	return ads.anytime.bound
+}
+
+// Improves the plan until deadline: it finishes the search at the current epsilon if the last one didn't finish, then lowers epsilon by 0.5 at a time (never below 1),
+// searching again each time, until the plan is optimal. Returns nil once it is, or context.DeadlineExceeded if the deadline came first, in which case the plan is still
+// the best found so far and Bound says how good it is; the next ImprovePath, or Update, carries on from where this one stopped. As with WithDStarBudget, the deadline is
+// only checked every few hundred expansions, so it may be overrun slightly.
+func (ads *AnytimeDStarInstance) ImprovePath(deadline time.Time) error {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	ads.cancel = newCanceller(ctx)
+	defer func() { ads.cancel = nil }()
+
+	for {
+		if ads.interrupted != nil || ads.anytime.bound != ads.anytime.epsilon {
+			if err := ads.computeShortestPath(); err != nil {
+				return err
+			}
+		}
+		if ads.anytime.epsilon == 1 {
+			return nil
+		}
+		ads.anytime.epsilon = math.Max(ads.anytime.epsilon-anytimeEpsilonStep, 1)
+	}
+}
+
+// Starts a new AD* search: every node left inconsistent since the last one goes back on the queue, every key is recomputed for the current start and epsilon, and no node
+// counts as expanded yet. Since the keys are all fresh, k_m isn't needed.
+func (ds *DStarInstance) reopen() {
+	a := ds.anytime
+	for _, node := range a.incons {
+		if !ds.equal(ds.g(node.ID()), ds.rhsOf(node.ID())) {
+			ds.u.Fix(node, ds.calculateKey(node))
+		}
+	}
+	a.incons = a.incons[:0]
+
+	// Fix moves items about, so the queue's nodes are gathered before any are rekeyed
+	a.rekey = a.rekey[:0]
+	for _, item := range ds.u.Items() {
+		a.rekey = append(a.rekey, item.Node)
+	}
+	for _, node := range a.rekey {
+		ds.u.Fix(node, ds.calculateKey(node))
+	}
+
+	for id := range a.closed {
+		delete(a.closed, id)
+	}
+}
@@ -0,0 +1,117 @@
+package graph_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/gonum/graph"
+)
+
+// An 8-connected grid with random obstacles, kept clear at the corners
+func obstacleGrid(size, obstacles int, seed int64) *graph.GridGraph {
+	src := rand.New(rand.NewSource(seed))
+	grid := graph.NewGridGraph(size, size, true)
+	for i := 0; i < obstacles; i++ {
+		if x, y := src.Intn(size), src.Intn(size); x+y > 2 && x+y < 2*size-4 {
+			grid.SetObstacle(x, y)
+		}
+	}
+	grid.ChangedEdges()
+	return grid
+}
+
+func TestAnytimeDStar(t *testing.T) {
+	grid := obstacleGrid(80, 1800, 6)
+	start, goal := grid.CoordsToNode(0, 0), grid.CoordsToNode(79, 79)
+	_, optimal, _ := graph.AStar(start, goal, grid, nil, nil)
+
+	ads := graph.InitAnytimeDStar(start, goal, grid, nil, nil, 3, graph.WithDStarStats())
+	_, cost, err := ads.Path()
+	if err != nil || ads.Bound() != 3 || cost > 3*optimal {
+		t.Fatalf("Expected a plan costing at most 3 times %v, got %v with bound %v (%v)", optimal, cost, ads.Bound(), err)
+	}
+	ds := graph.InitDStar(start, goal, grid, nil, nil, graph.WithDStarStats())
+	if inflated, plain := ads.Stats().Expansions, ds.Stats().Expansions; inflated >= plain {
+		t.Errorf("Inflating the heuristic expanded %d nodes, D*-Lite %d", inflated, plain)
+	}
+
+	if err := ads.ImprovePath(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if path, cost, err := ads.Path(); err != nil || ads.Bound() != 1 || ads.Epsilon() != 1 || math.Abs(cost-optimal) > 1e-9 || !graph.IsPath(path, grid) {
+		t.Errorf("Expected an optimal plan costing %v once improved, got %v with bound %v and epsilon %v (%v)", optimal, cost, ads.Bound(), ads.Epsilon(), err)
+	}
+
+	// A deadline that's already passed stops the improvement, leaving the plan from the first search usable
+	ads = graph.InitAnytimeDStar(start, goal, grid, nil, nil, 3)
+	if err := ads.ImprovePath(time.Now().Add(-time.Second)); err != context.DeadlineExceeded {
+		t.Fatalf("Expected the deadline to be exceeded, got %v", err)
+	}
+	if path, cost, err := ads.Path(); err != nil || ads.Bound() < ads.Epsilon() || cost > ads.Bound()*optimal || !graph.IsPath(path, grid) {
+		t.Errorf("Expected a usable plan after the deadline, got one costing %v with bound %v (%v)", cost, ads.Bound(), err)
+	}
+	if err := ads.ImprovePath(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, cost, _ := ads.Path(); math.Abs(cost-optimal) > 1e-9 {
+		t.Errorf("Expected the interrupted improvement to finish optimal, got %v, not %v", cost, optimal)
+	}
+}
+
+func TestAnytimeDStarReplanning(t *testing.T) {
+	truth := obstacleGrid(40, 400, 8)
+	grid := graph.NewGridGraph(40, 40, true)
+	goal := grid.CoordsToNode(39, 39)
+	sense := func() {
+		x, y := grid.IDToCoords(grid.Position().ID())
+		for dx := -2; dx <= 2; dx++ {
+			for dy := -2; dy <= 2; dy++ {
+				if truth.Obstacle(x+dx, y+dy) {
+					grid.SetObstacle(x+dx, y+dy)
+				}
+			}
+		}
+	}
+	sense()
+	grid.ChangedEdges()
+
+	ads := graph.InitAnytimeDStar(grid.Position(), goal, grid, nil, nil, 2.5)
+	for steps := 0; grid.Position().ID() != goal.ID(); steps++ {
+		// Every other cycle gets enough time to plan optimally, and the rest none at all
+		deadline := time.Now().Add(time.Minute)
+		if steps%2 == 1 {
+			deadline = time.Now()
+		}
+		err := ads.ImprovePath(deadline)
+		if err == nil {
+			_, want, _ := graph.AStar(grid.Position(), goal, grid, nil, nil)
+			if _, cost, err := ads.Path(); err != nil || ads.Bound() != 1 || math.Abs(cost-want) > 1e-9 {
+				t.Fatalf("At %v the improved plan costs %v with bound %v (%v), A* on the known grid %v", grid.Position(), cost, ads.Bound(), err, want)
+			}
+		} else if err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+		if steps > 40*40 {
+			t.Fatalf("Still going after %d steps", steps)
+		}
+
+		next, err := ads.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x, y := grid.IDToCoords(next.ID()); truth.Obstacle(x, y) {
+			t.Fatalf("Moved onto the obstacle at (%d, %d)", x, y)
+		}
+		grid.Move(next)
+		sense()
+		// A big change raises epsilon so the repair stays quick
+		_, changed := grid.ChangedEdges()
+		if len(changed) > 20 {
+			ads.SetEpsilon(2.5)
+		}
+		ads.Update(nil, changed)
+	}
+}
@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Arc flags[1] are a goal directed speedup for repeated shortest path queries, simpler than contraction hierarchies: the graph is split into regions, and every edge gets a flag
+// per region saying whether it's on some shortest path into that region. A query toward a target then only follows edges flagged for the target's region, which on road networks
+// prunes away most of the graph that lies away from the target, while the path found is still a shortest one.
+//
+// Preprocessing runs a backward Dijkstra search from every boundary node of every region (a node with an edge coming in from another region), so it's worth picking regions
+// with few boundary nodes, e.g. with MultilevelPartition. The flags take one bit per edge per region, and go stale if the graph changes.
+//
+// [1] U. Lauther, "An extremely fast, exact algorithm for finding shortest paths in static networks with geographical background", Geoinformation und Mobilität (2004)
+type ArcFlags struct {
+	graph   Graph
+	cost    func(Node, Node) float64
+	region  map[int]int
+	flags   map[[2]int][]uint64
+	regions int
+}
+
+// Computes arc flags for graph split into regions. Each node should be in at most one region; nodes in none are never pruned toward, and queries to them search the whole
+// graph. Cost is interpreted as in AStar, and must not be negative.
+func NewArcFlags(graph Graph, Cost func(Node, Node) float64, regions [][]Node) *ArcFlags {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	af := &ArcFlags{graph: graph, cost: Cost, region: make(map[int]int), flags: make(map[[2]int][]uint64), regions: len(regions)}
+	for r, nodes := range regions {
+		for _, node := range nodes {
+			af.region[node.ID()] = r
+		}
+	}
+
+	words := (len(regions) + 63) / 64
+	edges := graph.EdgeList()
+	for _, edge := range edges {
+		af.flags[[2]int{edge.Head().ID(), edge.Tail().ID()}] = make([]uint64, words)
+	}
+	set := func(u, v Node, r int) {
+		af.flags[[2]int{u.ID(), v.ID()}][r/64] |= 1 << uint(r%64)
+	}
+
+	for r, nodes := range regions {
+		for _, node := range nodes {
+			for _, succ := range graph.Successors(node) {
+				if reg, ok := af.region[succ.ID()]; ok && reg == r {
+					set(node, succ, r)
+				}
+			}
+		}
+
+		// Every shortest path from outside into the region ends, after it last enters the region at a boundary node, inside the region; so an edge lies on a shortest path
+		// into the region iff it lies on a shortest path to one of its boundary nodes
+		for _, b := range nodes {
+			boundary := false
+			for _, pred := range graph.Predecessors(b) {
+				if reg, ok := af.region[pred.ID()]; !ok || reg != r {
+					boundary = true
+					break
+				}
+			}
+			if !boundary {
+				continue
+			}
+
+			dist := distancesFrom(b, graph, Cost, true)
+			for _, edge := range edges {
+				du, ok1 := dist[edge.Head().ID()]
+				dv, ok2 := dist[edge.Tail().ID()]
+				if ok1 && ok2 && DefaultTolerance(du, Cost(edge.Head(), edge.Tail())+dv) {
+					set(edge.Head(), edge.Tail(), r)
+				}
+			}
+		}
+	}
+
+	return af
+}
+
+// The region node was put in, or -1 if none.
+func (af *ArcFlags) Region(node Node) int {
+	if r, ok := af.region[node.ID()]; ok {
+		return r
+	}
+	return -1
+}
+
+// Whether the edge from u to v is flagged for region, i.e. it's on a shortest path to some node in the region.
+func (af *ArcFlags) Flagged(u, v Node, region int) bool {
+	flags, ok := af.flags[[2]int{u.ID(), v.ID()}]
+	return ok && region >= 0 && region < af.regions && flags[region/64]&(1<<uint(region%64)) != 0
+}
+
+// Finds the shortest path from start to goal, following only edges flagged for goal's region. HeuristicCost is interpreted as in AStar, and combining arc flags with a good
+// heuristic such as Landmarks prunes the search from both sides. Results are as for AStar.
+func (af *ArcFlags) ShortestPath(start, goal Node, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	if HeuristicCost == nil {
+		if hgraph, ok := af.graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+	region := af.Region(goal)
+
+	openSet := &aStarPriorityQueue{{start, 0, HeuristicCost(start, goal)}}
+	closed := make(map[int]bool)
+	best := map[int]float64{start.ID(): 0}
+	predecessor := make(map[int]Node)
+	for openSet.Len() != 0 {
+		curr := heap.Pop(openSet).(internalNode)
+		if closed[curr.ID()] {
+			continue
+		}
+		closed[curr.ID()] = true
+		nodesExpanded += 1
+		if curr.ID() == goal.ID() {
+			return rebuildPath(predecessor, goal), curr.gscore, nodesExpanded
+		}
+
+		for _, succ := range af.graph.Successors(curr.Node) {
+			if region != -1 && !af.Flagged(curr.Node, succ, region) {
+				continue
+			}
+			g := curr.gscore + af.cost(curr.Node, succ)
+			if b, ok := best[succ.ID()]; closed[succ.ID()] || ok && g >= b || math.IsInf(g, 1) {
+				continue
+			}
+			best[succ.ID()] = g
+			predecessor[succ.ID()] = curr.Node
+			heap.Push(openSet, internalNode{succ, g, g + HeuristicCost(succ, goal)})
+		}
+	}
+
+	return nil, 0.0, nodesExpanded
+}
@@ -0,0 +1,63 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestArcFlags(t *testing.T) {
+	geometric := graph.NewGonumGraph(false)
+	graph.RandomGeometricGraph(geometric, 300, 2, 0.12, rand.New(rand.NewSource(1)))
+
+	directed := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(directed, 200, 0.03, true, rand.New(rand.NewSource(2)))
+	src := rand.New(rand.NewSource(3))
+	for _, edge := range directed.EdgeList() {
+		directed.SetEdgeCost(edge, 1+src.Float64())
+	}
+
+	for name, g := range map[string]*graph.GonumGraph{"geometric": geometric, "directed": directed} {
+		regions, _ := graph.MultilevelPartition(g, nil, 8, 0.1)
+		af := graph.NewArcFlags(g, nil, regions)
+
+		n := len(g.NodeList())
+		pruned, full := 0, 0
+		for i := 0; i < 100; i++ {
+			start, goal := graph.GonumNode(src.Intn(n)), graph.GonumNode(src.Intn(n))
+			path, cost, expanded := af.ShortestPath(start, goal, nil)
+			want, wantCost, wantExpanded := graph.AStar(start, goal, g, nil, nil)
+			pruned += expanded
+			full += wantExpanded
+
+			if (path == nil) != (want == nil) || math.Abs(cost-wantCost) > 1e-9 || path != nil && !graph.IsPath(path, g) {
+				t.Errorf("%s: from %v to %v arc flags found %v costing %v, expected %v costing %v", name, start, goal, path, cost, want, wantCost)
+			}
+		}
+		if pruned >= full {
+			t.Errorf("%s: arc flags didn't prune the search, expanding %d nodes against %d", name, pruned, full)
+		}
+	}
+
+	// Edges within a region are flagged for it, as are edges leading into it, but not edges leading only out of it
+	line := graph.NewGonumGraph(true)
+	for i := 3; i > 0; i-- {
+		line.AddNode(graph.GonumNode(i-1), []graph.Node{graph.GonumNode(i)})
+	}
+	af := graph.NewArcFlags(line, nil, [][]graph.Node{{graph.GonumNode(0), graph.GonumNode(1)}, {graph.GonumNode(2), graph.GonumNode(3)}})
+	for _, test := range []struct {
+		u, v, region int
+		flagged      bool
+	}{
+		{0, 1, 0, true}, {0, 1, 1, true}, {1, 2, 1, true}, {2, 3, 1, true}, {1, 2, 0, false}, {2, 3, 0, false},
+	} {
+		if got := af.Flagged(graph.GonumNode(test.u), graph.GonumNode(test.v), test.region); got != test.flagged {
+			t.Errorf("Edge %d->%d flagged for region %d: %t, expected %t", test.u, test.v, test.region, got, test.flagged)
+		}
+	}
+	if path, _, _ := af.ShortestPath(graph.GonumNode(2), graph.GonumNode(0), nil); path != nil {
+		t.Errorf("Found a path against a one way street: %v", path)
+	}
+}
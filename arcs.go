@@ -0,0 +1,268 @@
+package graph
+
+import (
+	"math"
+)
+
+// An Arc is an edge that's an object in its own right rather than just a pair of nodes, knowing its ends and its own cost, as in multigraphs, where two nodes can be joined
+// by any number of arcs, or in graphs whose edges carry attributes of their own. From and To are an Edge's Head and Tail, and Weight is the cost of following it.
+//
+// Algorithms in this package run on graphs of arcs through ArcsAsGraph, which presents an ArcGraph as a Graph, and ArcPath maps the paths they find back to the arcs they
+// follow; ArcEdge and EdgeArc convert between single arcs and Edges. MultiGraph is a graph of arcs ready to fill.
+type Arc interface {
+	From() Node
+	To() Node
+	Weight() float64
+}
+
+// An arc with an ID of its own, so that parallel arcs can be told apart. Where arcs tie, ArcPath prefers the one with the lowest ID.
+type IdentifiedArc interface {
+	Arc
+	ID() int
+}
+
+// A graph whose edges are Arcs. In an undirected graph, ArcsFrom and ArcsTo both give every arc touching node, whichever way round it is.
+type ArcGraph interface {
+	NodeList() []Node
+	NodeExists(node Node) bool
+	ArcsFrom(node Node) []Arc
+	ArcsTo(node Node) []Arc
+	IsDirected() bool
+}
+
+// An Arc as an Edge: its Head is the arc's From, and its Tail the arc's To. The arc itself stays available, so nothing is lost by passing one through code that only knows
+// about Edges.
+type ArcEdge struct {
+	Arc
+}
+
+func (edge ArcEdge) Head() Node {
+	return edge.From()
+}
+
+func (edge ArcEdge) Tail() Node {
+	return edge.To()
+}
+
+// An Edge as an Arc with the given weight. An ArcEdge comes back as the arc it holds, whatever the weight.
+func EdgeArc(edge Edge, weight float64) Arc {
+	if e, ok := edge.(ArcEdge); ok {
+		return e.Arc
+	}
+	return edgeArc{edge, weight}
+}
+
+type edgeArc struct {
+	edge   Edge
+	weight float64
+}
+
+func (arc edgeArc) From() Node {
+	return arc.edge.Head()
+}
+
+func (arc edgeArc) To() Node {
+	return arc.edge.Tail()
+}
+
+func (arc edgeArc) Weight() float64 {
+	return arc.weight
+}
+
+// An arc followed backwards, as an undirected arc is from its To end. Reversing it again gives the original arc back.
+func ReverseArc(arc Arc) Arc {
+	if r, ok := arc.(reversedArc); ok {
+		return r.Arc
+	}
+	return reversedArc{arc}
+}
+
+type reversedArc struct {
+	Arc
+}
+
+func (arc reversedArc) From() Node {
+	return arc.Arc.To()
+}
+
+func (arc reversedArc) To() Node {
+	return arc.Arc.From()
+}
+
+// An ArcGraph as a CostGraph, so every algorithm in the package can run on it. Parallel arcs become a single edge, costing the least of their weights, which is all any algorithm
+// that works with costs between nodes can see of them; EdgeList still lists every arc, as an ArcEdge, reversed where an undirected arc is listed from its To end.
+type ArcGraphAdapter struct {
+	ArcGraph
+}
+
+// Presents g as a CostGraph.
+func ArcsAsGraph(g ArcGraph) *ArcGraphAdapter {
+	return &ArcGraphAdapter{g}
+}
+
+// The arcs from node, each turned to lead away from it.
+func (g *ArcGraphAdapter) arcsFrom(node Node) []Arc {
+	arcs := g.ArcsFrom(node)
+	if g.IsDirected() {
+		return arcs
+	}
+	turned := make([]Arc, len(arcs))
+	for i, arc := range arcs {
+		if arc.From().ID() != node.ID() {
+			arc = ReverseArc(arc)
+		}
+		turned[i] = arc
+	}
+	return turned
+}
+
+// The distinct nodes arcs lead to or from, in the order they're first seen, with the least weight of the arcs to or from each.
+func distinctEnds(arcs []Arc, end func(Arc) Node) (nodes []Node, weights []float64) {
+	index := make(map[int]int, len(arcs))
+	for _, arc := range arcs {
+		node := end(arc)
+		if i, ok := index[node.ID()]; ok {
+			if arc.Weight() < weights[i] {
+				weights[i] = arc.Weight()
+			}
+			continue
+		}
+		index[node.ID()] = len(nodes)
+		nodes = append(nodes, node)
+		weights = append(weights, arc.Weight())
+	}
+	return nodes, weights
+}
+
+func (g *ArcGraphAdapter) Successors(node Node) []Node {
+	succs, _ := distinctEnds(g.arcsFrom(node), Arc.To)
+	return succs
+}
+
+func (g *ArcGraphAdapter) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	succs, costs := distinctEnds(g.arcsFrom(node), Arc.To)
+	for i, succ := range succs {
+		if !fn(succ, costs[i]) {
+			return
+		}
+	}
+}
+
+func (g *ArcGraphAdapter) IsSuccessor(node, successor Node) bool {
+	for _, arc := range g.arcsFrom(node) {
+		if arc.To().ID() == successor.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *ArcGraphAdapter) Predecessors(node Node) []Node {
+	if !g.IsDirected() {
+		return g.Successors(node)
+	}
+	preds, _ := distinctEnds(g.ArcsTo(node), Arc.From)
+	return preds
+}
+
+func (g *ArcGraphAdapter) IsPredecessor(node, predecessor Node) bool {
+	return g.IsSuccessor(predecessor, node)
+}
+
+func (g *ArcGraphAdapter) IsAdjacent(node, neighbor Node) bool {
+	return g.IsSuccessor(node, neighbor) || g.IsPredecessor(node, neighbor)
+}
+
+func (g *ArcGraphAdapter) Degree(node Node) int {
+	return len(g.Successors(node)) + len(g.Predecessors(node))
+}
+
+func (g *ArcGraphAdapter) EdgeList() []Edge {
+	var edges []Edge
+	for _, node := range g.NodeList() {
+		for _, arc := range g.arcsFrom(node) {
+			edges = append(edges, ArcEdge{arc})
+		}
+	}
+	return edges
+}
+
+// The least weight of the arcs from node to succ, or +Inf if there are none.
+func (g *ArcGraphAdapter) Cost(node, succ Node) float64 {
+	cost := math.Inf(1)
+	for _, arc := range g.arcsFrom(node) {
+		if arc.To().ID() == succ.ID() && arc.Weight() < cost {
+			cost = arc.Weight()
+		}
+	}
+	return cost
+}
+
+// The arcs a path of nodes follows through g, as found on an ArcsAsGraph: the lightest arc between each node and the next, ties going to the arc with the lowest ID if they
+// have IDs, and otherwise to the first listed. Arcs are as ArcsFrom lists them, so an undirected arc may be followed from its To end. Returns nil if some node in the path has
+// no arc to the next.
+func ArcPath(g ArcGraph, path []Node) []Arc {
+	arcs := make([]Arc, 0, len(path))
+	for i := 1; i < len(path); i++ {
+		var best Arc
+		for _, arc := range g.ArcsFrom(path[i-1]) {
+			next := arc.To()
+			if !g.IsDirected() && arc.From().ID() != path[i-1].ID() {
+				next = arc.From()
+			}
+			if next.ID() == path[i].ID() && (best == nil || lessArc(arc, best)) {
+				best = arc
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		arcs = append(arcs, best)
+	}
+	return arcs
+}
+
+func lessArc(a, b Arc) bool {
+	if a.Weight() != b.Weight() {
+		return a.Weight() < b.Weight()
+	}
+	ia, ok1 := a.(IdentifiedArc)
+	ib, ok2 := b.(IdentifiedArc)
+	return ok1 && ok2 && ia.ID() < ib.ID()
+}
+
+// Presents any Graph as an ArcGraph, each edge an arc weighing what Cost says it costs, interpreted as in AStar.
+func GraphArcs(graph Graph, Cost func(Node, Node) float64) ArcGraph {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	return graphArcs{graph, Cost}
+}
+
+type graphArcs struct {
+	Graph
+	cost func(Node, Node) float64
+}
+
+func (g graphArcs) ArcsFrom(node Node) []Arc {
+	var arcs []Arc
+	for _, succ := range g.Successors(node) {
+		arcs = append(arcs, edgeArc{GonumEdge{H: node, T: succ}, g.cost(node, succ)})
+	}
+	return arcs
+}
+
+func (g graphArcs) ArcsTo(node Node) []Arc {
+	if !g.IsDirected() {
+		return g.ArcsFrom(node)
+	}
+	var arcs []Arc
+	for _, pred := range g.Predecessors(node) {
+		arcs = append(arcs, edgeArc{GonumEdge{H: pred, T: node}, g.cost(pred, node)})
+	}
+	return arcs
+}
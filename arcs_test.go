@@ -0,0 +1,170 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A flight between two cities, several of which may run between the same pair
+type flight struct {
+	number   int
+	from, to graph.Node
+	price    float64
+}
+
+func (f *flight) From() graph.Node {
+	return f.from
+}
+
+func (f *flight) To() graph.Node {
+	return f.to
+}
+
+func (f *flight) Weight() float64 {
+	return f.price
+}
+
+func (f *flight) ID() int {
+	return f.number
+}
+
+type routes struct {
+	directed bool
+	cities   []graph.Node
+	flights  []*flight
+}
+
+func (r *routes) NodeList() []graph.Node {
+	return append([]graph.Node(nil), r.cities...)
+}
+
+func (r *routes) NodeExists(node graph.Node) bool {
+	for _, city := range r.cities {
+		if city.ID() == node.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *routes) arcs(node graph.Node, from, to bool) []graph.Arc {
+	var arcs []graph.Arc
+	for _, f := range r.flights {
+		if from && f.from.ID() == node.ID() || to && f.to.ID() == node.ID() {
+			arcs = append(arcs, f)
+		}
+	}
+	return arcs
+}
+
+func (r *routes) ArcsFrom(node graph.Node) []graph.Arc {
+	return r.arcs(node, true, !r.directed)
+}
+
+func (r *routes) ArcsTo(node graph.Node) []graph.Arc {
+	return r.arcs(node, !r.directed, true)
+}
+
+func (r *routes) IsDirected() bool {
+	return r.directed
+}
+
+func testRoutes(directed bool) *routes {
+	r := &routes{directed: directed}
+	for i := 0; i < 4; i++ {
+		r.cities = append(r.cities, graph.GonumNode(i))
+	}
+	for _, f := range []flight{{1, graph.GonumNode(0), graph.GonumNode(1), 300}, {2, graph.GonumNode(0), graph.GonumNode(1), 120}, {3, graph.GonumNode(0), graph.GonumNode(1), 120},
+		{4, graph.GonumNode(1), graph.GonumNode(2), 80}, {5, graph.GonumNode(0), graph.GonumNode(2), 250}, {6, graph.GonumNode(2), graph.GonumNode(3), 40}} {
+		f := f
+		r.flights = append(r.flights, &f)
+	}
+	return r
+}
+
+func flightNumbers(arcs []graph.Arc) []int {
+	var numbers []int
+	for _, arc := range arcs {
+		numbers = append(numbers, arc.(*flight).number)
+	}
+	return numbers
+}
+
+func TestArcsAsGraph(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		r := testRoutes(directed)
+		g := graph.ArcsAsGraph(r)
+		if report := graph.Validate(g); !report.OK() {
+			t.Errorf("directed %t: inconsistent adapter:\n%v", directed, report)
+		}
+		if n := len(g.Successors(graph.GonumNode(0))); n != 2 {
+			t.Errorf("directed %t: expected parallel flights to make one edge, got %d successors", directed, n)
+		}
+		if cost := g.Cost(graph.GonumNode(0), graph.GonumNode(1)); cost != 120 {
+			t.Errorf("directed %t: expected the cheapest flight's price, got %v", directed, cost)
+		}
+		if n := len(g.EdgeList()); directed && n != 6 || !directed && n != 12 {
+			t.Errorf("directed %t: expected every flight in EdgeList, got %d edges", directed, n)
+		}
+		for _, edge := range g.EdgeList() {
+			if arc := graph.EdgeArc(edge, 0); arc.Weight() == 0 {
+				t.Errorf("Expected EdgeArc to give back the arc, got %v", arc)
+			}
+		}
+
+		// Searches use VisitSuccessors, and paths map back to the flights taken
+		path, cost, _ := graph.AStar(graph.GonumNode(3), graph.GonumNode(0), g, nil, nil)
+		if directed {
+			if path != nil {
+				t.Errorf("Expected no path against the flights, got %v", path)
+			}
+			path, cost, _ = graph.AStar(graph.GonumNode(0), graph.GonumNode(3), g, nil, nil)
+		}
+		if cost != 240 || len(path) != 4 {
+			t.Fatalf("directed %t: expected a path of 3 flights costing 240, got %v costing %v", directed, path, cost)
+		}
+		arcs := graph.ArcPath(r, path)
+		if numbers := flightNumbers(arcs); len(numbers) != 3 || !directed && numbers[2] != 2 || directed && numbers[0] != 2 {
+			t.Errorf("directed %t: expected the cheapest flights with ties to the lowest number, got %v", directed, numbers)
+		}
+	}
+
+	if arcs := graph.ArcPath(testRoutes(true), []graph.Node{graph.GonumNode(3), graph.GonumNode(0)}); arcs != nil {
+		t.Errorf("Expected no arcs for a path with no flight, got %v", arcs)
+	}
+	if cost := graph.ArcsAsGraph(testRoutes(true)).Cost(graph.GonumNode(1), graph.GonumNode(0)); !math.IsInf(cost, 1) {
+		t.Errorf("Expected an infinite cost with no flight, got %v", cost)
+	}
+}
+
+func TestGraphArcs(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	g.AddNode(graph.GonumNode(0), nil)
+	g.AddNode(graph.GonumNode(1), nil)
+	edge := graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}
+	g.AddEdge(edge)
+	g.SetEdgeCost(edge, 2.5)
+
+	arcs := graph.GraphArcs(g, nil)
+	if from := arcs.ArcsFrom(graph.GonumNode(0)); len(from) != 1 || from[0].Weight() != 2.5 || from[0].To().ID() != 1 {
+		t.Errorf("Unexpected arcs from 0 %v", from)
+	}
+	if to := arcs.ArcsTo(graph.GonumNode(1)); len(to) != 1 || to[0].From().ID() != 0 {
+		t.Errorf("Unexpected arcs to 1 %v", to)
+	}
+
+	// And back again
+	back := graph.ArcsAsGraph(arcs)
+	if !back.IsSuccessor(graph.GonumNode(0), graph.GonumNode(1)) || back.Cost(graph.GonumNode(0), graph.GonumNode(1)) != 2.5 {
+		t.Error("Expected the edge to survive the round trip")
+	}
+	reversed := graph.ReverseArc(arcs.ArcsFrom(graph.GonumNode(0))[0])
+	if reversed.From().ID() != 1 || graph.ReverseArc(reversed).From().ID() != 0 {
+		t.Error("Unexpected reversal")
+	}
+	if e := (graph.ArcEdge{Arc: reversed}); e.Head().ID() != 1 || e.Tail().ID() != 0 {
+		t.Error("Unexpected ArcEdge ends")
+	}
+}
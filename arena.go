@@ -0,0 +1,144 @@
+package graph
+
+// A searchArena is the bookkeeping of a best-first search (scores, predecessors, closed flags and the open list) kept in flat slices that are reused from one search to the
+// next, so a workload of many short searches makes next to no garbage.
+//
+// Nodes are given a slot the first time they're seen, and every score, predecessor and closed flag lives in a slice indexed by slot. Instead of clearing those slices between
+// searches, every entry is stamped with the search (generation) that wrote it, and entries from older generations read as unset, so starting a new search is O(1).
+type searchArena struct {
+	slots      map[int]int
+	nodes      []Node
+	gScores    []float64
+	pred       []int    // Slot of the predecessor, or -1
+	seen       []uint32 // Generation in which gScores and pred were last written
+	closed     []uint32 // Generation in which the node was expanded
+	generation uint32
+	open       []arenaEntry
+}
+
+type arenaEntry struct {
+	slot           int
+	gscore, fscore float64
+}
+
+// Makes an arena with room for n nodes before it has to grow.
+func newSearchArena(n int) *searchArena {
+	return &searchArena{
+		slots:   make(map[int]int, n),
+		nodes:   make([]Node, 0, n),
+		gScores: make([]float64, 0, n),
+		pred:    make([]int, 0, n),
+		seen:    make([]uint32, 0, n),
+		closed:  make([]uint32, 0, n),
+	}
+}
+
+func (a *searchArena) slot(node Node) int {
+	if s, ok := a.slots[node.ID()]; ok {
+		return s
+	}
+
+	s := len(a.nodes)
+	a.slots[node.ID()] = s
+	a.nodes = append(a.nodes, node)
+	a.gScores = append(a.gScores, 0)
+	a.pred = append(a.pred, -1)
+	a.seen = append(a.seen, 0)
+	a.closed = append(a.closed, 0)
+	return s
+}
+
+// Starts a new search, invalidating everything the previous ones wrote and emptying the open list. Generation 0 is never used, so fresh slots are unset.
+func (a *searchArena) reset() {
+	a.open = a.open[:0]
+	a.generation++
+	if a.generation == 0 {
+		// Wrapped around: stamps from 2^32 searches ago would look current, so they have to be cleared for real
+		for i := range a.seen {
+			a.seen[i], a.closed[i] = 0, 0
+		}
+		a.generation = 1
+	}
+}
+
+// Whether slot s has a score in this search, and the score if it has.
+func (a *searchArena) score(s int) (float64, bool) {
+	if a.seen[s] != a.generation {
+		return 0, false
+	}
+	return a.gScores[s], true
+}
+
+// Scores slot s as reached from slot pred (-1 for none) at cost g.
+func (a *searchArena) setScore(s int, g float64, pred int) {
+	a.gScores[s], a.pred[s], a.seen[s] = g, pred, a.generation
+}
+
+func (a *searchArena) isClosed(s int) bool {
+	return a.closed[s] == a.generation
+}
+
+func (a *searchArena) close(s int) {
+	a.closed[s] = a.generation
+}
+
+// The node slot s was reached from in this search, or nil for the start or a slot that wasn't reached.
+func (a *searchArena) predecessor(s int) Node {
+	if a.seen[s] != a.generation || a.pred[s] == -1 {
+		return nil
+	}
+	return a.nodes[a.pred[s]]
+}
+
+// The path to slot goal, following predecessors back to the start.
+func (a *searchArena) path(goal int) []Node {
+	length := 0
+	for s := goal; s != -1; s = a.pred[s] {
+		length++
+	}
+
+	path := make([]Node, length)
+	for s := goal; s != -1; s = a.pred[s] {
+		length--
+		path[length] = a.nodes[s]
+	}
+
+	return path
+}
+
+// The open list is a binary heap on fscore, sifted by hand since container/heap would box every entry in an interface{}
+func (a *searchArena) push(entry arenaEntry) {
+	a.open = append(a.open, entry)
+	for i := len(a.open) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if a.open[parent].fscore <= a.open[i].fscore {
+			break
+		}
+		a.open[parent], a.open[i] = a.open[i], a.open[parent]
+		i = parent
+	}
+}
+
+func (a *searchArena) pop() arenaEntry {
+	top := a.open[0]
+	last := len(a.open) - 1
+	a.open[0] = a.open[last]
+	a.open = a.open[:last]
+
+	for i := 0; ; {
+		min, left, right := i, 2*i+1, 2*i+2
+		if left < last && a.open[left].fscore < a.open[min].fscore {
+			min = left
+		}
+		if right < last && a.open[right].fscore < a.open[min].fscore {
+			min = right
+		}
+		if min == i {
+			break
+		}
+		a.open[i], a.open[min] = a.open[min], a.open[i]
+		i = min
+	}
+
+	return top
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"math"
+)
+
+// Solves the square assignment problem for the given cost matrix with the Hungarian algorithm (in its O(n^3) shortest augmenting path form). The returned slice maps each row to
+// the column it's assigned to. Infinite costs are treated as forbidden assignments, though if no finite assignment exists one will still be returned.
+func hungarian(cost [][]float64) (assignment []int, total float64) {
+	n := len(cost)
+	if n == 0 {
+		return nil, 0
+	}
+
+	// Infinity would poison the potentials, so forbidden cells get a cost larger than any finite assignment could be
+	big := 1.0
+	for _, row := range cost {
+		for _, c := range row {
+			if !math.IsInf(c, 0) {
+				big += math.Abs(c)
+			}
+		}
+	}
+	big *= float64(n)
+
+	at := func(i, j int) float64 {
+		if c := cost[i][j]; !math.IsInf(c, 0) {
+			return c
+		}
+		return big
+	}
+
+	// 1-indexed, column 0 is a sentinel
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j] is the row assigned to column j
+	way := make([]int, n+1)
+	minv := make([]float64, n+1)
+	used := make([]bool, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.Inf(1)
+			used[j] = false
+		}
+
+		for p[j0] != 0 {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := at(i0-1, j-1) - u[i0] - v[j]; cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment = make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[p[j]-1] = j - 1
+	}
+	for i, j := range assignment {
+		total += cost[i][j]
+	}
+
+	return assignment, total
+}
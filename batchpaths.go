@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// A shortest path query for BatchShortestPaths.
+type PathQuery struct {
+	Source, Target Node
+}
+
+// The answer to the query at index Index of the batch. Path is nil if there's no path (and Cost is then meaningless), as with AStar. Elapsed is how long the search took.
+type PathResult struct {
+	Index         int
+	Query         PathQuery
+	Path          []Node
+	Cost          float64
+	NodesExpanded int
+	Elapsed       time.Duration
+}
+
+// Answers a batch of shortest path queries on workers goroutines (GOMAXPROCS if workers <= 0), streaming the results over the returned channel in the order they're finished, which is
+// closed once every query has been answered. Each worker answers its share of the queries with its own Planner, so there's no per-query allocation of search state beyond the
+// first few queries.
+//
+// Preprocessing is shared through HeuristicCost, which all workers call: build a Landmarks once and pass its HeuristicCost to speed up every query. Cost and HeuristicCost are interpreted
+// as in AStar, and must be safe to call concurrently, as must the graph's Successors; all of that holds for read-only graphs in this package and for Landmarks.
+//
+// The results must be received until the channel is closed, or the workers block forever; see BatchShortestPathsCtx for a way to give up on a batch.
+func BatchShortestPaths(graph Graph, Cost, HeuristicCost func(Node, Node) float64, queries []PathQuery, workers int) <-chan PathResult {
+	return BatchShortestPathsCtx(context.Background(), graph, Cost, HeuristicCost, queries, workers)
+}
+
+// Like BatchShortestPaths, but the workers stop, and the channel is closed, once ctx is cancelled, whether or not all the queries have been answered.
+func BatchShortestPathsCtx(ctx context.Context, graph Graph, Cost, HeuristicCost func(Node, Node) float64, queries []PathQuery, workers int) <-chan PathResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := make(chan PathResult, workers)
+
+	// Queries are handed out one at a time rather than in fixed shards, so a worker that gets the long queries doesn't hold up the rest
+	indices := make(chan int)
+	go func() {
+		defer close(indices)
+		for i := range queries {
+			select {
+			case indices <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			planner := NewPlanner(graph, Cost, HeuristicCost)
+			for i := range indices {
+				began := time.Now()
+				path, cost, expanded := planner.AStar(queries[i].Source, queries[i].Target)
+				select {
+				case results <- PathResult{Index: i, Query: queries[i], Path: path, Cost: cost, NodesExpanded: expanded, Elapsed: time.Since(began)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// Grows a ShortestPathTree from every one of sources, as DijkstraTree does, on workers goroutines (GOMAXPROCS if workers <= 0), for when there are shortest paths wanted from
+// thousands of sources rather than between a few pairs. trees[i] is the tree from sources[i]. The searches are independent, so they scale with the number of cores until memory
+// bandwidth runs out; but every tree holds a distance and a predecessor for every node its source reaches, so thousands of trees over a large graph need a lot of memory, and
+// it may be better to go through the sources a slice at a time.
+//
+// Cost is interpreted as in AStar, and mustn't be negative. As with BatchShortestPaths, Cost and the graph's Successors must be safe to call concurrently.
+func ParallelDijkstraAll(sources []Node, graph ImplicitGraph, Cost func(Node, Node) float64, workers int) (trees []*ShortestPathTree) {
+	trees, _ = ParallelDijkstraAllCtx(context.Background(), sources, graph, Cost, workers)
+	return trees
+}
+
+// Like ParallelDijkstraAll, but gives up when ctx is cancelled, returning ctx.Err() unless every tree was finished anyway. Trees that were finished are kept, and the rest are nil, so a batch can be resumed from the
+// sources whose trees are missing.
+func ParallelDijkstraAllCtx(ctx context.Context, sources []Node, graph ImplicitGraph, Cost func(Node, Node) float64, workers int) (trees []*ShortestPathTree, err error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	trees = make([]*ShortestPathTree, len(sources))
+
+	// As in BatchShortestPathsCtx, sources are handed out one at a time, since some trees take much longer to grow than others
+	indices := make(chan int)
+	go func() {
+		defer close(indices)
+		for i := range sources {
+			select {
+			case indices <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			cancel := newCanceller(ctx)
+			for i := range indices {
+				// Each index is only handed to one worker, so no two write the same element
+				if tree, err := dijkstraTree(cancel, sources[i], graph, Cost); err == nil {
+					trees[i] = tree
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, tree := range trees {
+		if tree == nil {
+			return trees, ctx.Err()
+		}
+	}
+	return trees, nil
+}
@@ -0,0 +1,180 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestLandmarks(t *testing.T) {
+	g := randomWeightedGraph(200, 0.02, 5)
+	lm := graph.NewLandmarks(g, nil, 6, rand.New(rand.NewSource(1)))
+	if len(lm.Nodes) != 6 {
+		t.Fatalf("Got %d landmarks, expected 6", len(lm.Nodes))
+	}
+
+	src := rand.New(rand.NewSource(2))
+	nodes := g.NodeList()
+	plainExpanded, altExpanded := 0, 0
+	for i := 0; i < 300; i++ {
+		a, b := nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]
+		path, cost, expanded := graph.AStar(a, b, g, nil, graph.NullHeuristic)
+		altPath, altCost, altExp := graph.AStar(a, b, g, nil, lm.HeuristicCost)
+		plainExpanded += expanded
+		altExpanded += altExp
+
+		h := lm.HeuristicCost(a, b)
+		if path == nil {
+			if altPath != nil {
+				t.Errorf("Landmark A* found a path from %v to %v where there's none", a, b)
+			}
+			continue
+		}
+		if h > cost+1e-9 || math.IsInf(h, 1) {
+			t.Errorf("Heuristic from %v to %v is %v, more than the true cost %v", a, b, h, cost)
+		}
+		if altPath == nil || math.Abs(altCost-cost) > 1e-9 {
+			t.Errorf("Landmark A* from %v to %v cost %v, expected %v", a, b, altCost, cost)
+		}
+	}
+	if altExpanded >= plainExpanded {
+		t.Errorf("Landmarks didn't help: %d expansions with them, %d without", altExpanded, plainExpanded)
+	}
+}
+
+// Landmarks worked out before costs went up still lead D*-Lite to the shortest paths
+func TestLandmarksDStar(t *testing.T) {
+	g := randomWeightedGraph(200, 0.03, 6)
+	lm := graph.NewLandmarks(g, nil, 6, rand.New(rand.NewSource(1)))
+	src := rand.New(rand.NewSource(2))
+	start, goal := graph.GonumNode(0), graph.GonumNode(199)
+	ds := graph.InitDStar(start, goal, g, nil, lm.HeuristicCost)
+
+	edges := g.EdgeList()
+	for round := 0; round < 10; round++ {
+		var changed []graph.Edge
+		for i := 0; i < 20; i++ {
+			edge := edges[src.Intn(len(edges))]
+			g.SetEdgeCost(edge, g.Cost(edge.Head(), edge.Tail())*(1+src.Float64()))
+			changed = append(changed, edge)
+		}
+		ds.Update(nil, changed)
+
+		_, want, _ := graph.AStar(start, goal, g, nil, nil)
+		if _, cost, err := ds.Path(); err != nil || math.Abs(cost-want) > 1e-9 {
+			t.Fatalf("Round %d: D*-Lite's plan costs %v (%v), A* finds %v", round, cost, err, want)
+		}
+	}
+}
+
+func TestBatchShortestPaths(t *testing.T) {
+	tg := graph.RandomObstacleField(40, 40, 0.25, graph.GonumNode(0), graph.GonumNode(1599), rand.New(rand.NewSource(7)))
+	lm := graph.NewLandmarks(tg, nil, 4, rand.New(rand.NewSource(1)))
+	src := rand.New(rand.NewSource(3))
+	nodes := tg.NodeList()
+	queries := make([]graph.PathQuery, 500)
+	for i := range queries {
+		queries[i] = graph.PathQuery{Source: nodes[src.Intn(len(nodes))], Target: nodes[src.Intn(len(nodes))]}
+	}
+
+	seen := make([]bool, len(queries))
+	for result := range graph.BatchShortestPaths(tg, nil, lm.HeuristicCost, queries, 4) {
+		if seen[result.Index] {
+			t.Fatalf("Query %d answered twice", result.Index)
+		}
+		seen[result.Index] = true
+
+		q := queries[result.Index]
+		path, cost, _ := graph.AStar(q.Source, q.Target, tg, nil, nil)
+		if result.Query != q || (result.Path == nil) != (path == nil) || path != nil && result.Cost != cost {
+			t.Errorf("Query %d from %v to %v: got cost %v, expected %v", result.Index, q.Source, q.Target, result.Cost, cost)
+		}
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("Query %d wasn't answered", i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := graph.BatchShortestPathsCtx(ctx, tg, nil, nil, queries, 2)
+	<-results
+	cancel()
+	answered := 1
+	for range results {
+		answered++
+	}
+	if answered == len(queries) {
+		t.Error("Cancelling the batch didn't stop it")
+	}
+}
+
+func BenchmarkBatchShortestPaths(b *testing.B) {
+	tg := graph.RandomObstacleField(100, 100, 0.25, graph.GonumNode(0), graph.GonumNode(9999), rand.New(rand.NewSource(7)))
+	src := rand.New(rand.NewSource(3))
+	nodes := tg.NodeList()
+	queries := make([]graph.PathQuery, 200)
+	for i := range queries {
+		queries[i] = graph.PathQuery{Source: nodes[src.Intn(len(nodes))], Target: nodes[src.Intn(len(nodes))]}
+	}
+	lm := graph.NewLandmarks(tg, nil, 8, rand.New(rand.NewSource(1)))
+
+	for name, h := range map[string]func(graph.Node, graph.Node) float64{"null": graph.NullHeuristic, "landmarks": lm.HeuristicCost} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for range graph.BatchShortestPaths(tg, nil, h, queries, 0) {
+				}
+			}
+		})
+	}
+}
+
+func TestParallelDijkstraAll(t *testing.T) {
+	g := randomWeightedGraph(150, 0.03, 9)
+	sources := g.NodeList()[:40]
+	trees := graph.ParallelDijkstraAll(sources, g, nil, 4)
+	if len(trees) != len(sources) {
+		t.Fatalf("Got %d trees for %d sources", len(trees), len(sources))
+	}
+	for i, source := range sources {
+		want := graph.DijkstraTree(source, g, nil)
+		if trees[i].Source.ID() != source.ID() || len(trees[i].Order) != len(want.Order) {
+			t.Fatalf("Tree %d is from %v reaching %d nodes, expected from %v reaching %d", i, trees[i].Source, len(trees[i].Order), source, len(want.Order))
+		}
+		for _, node := range want.Order {
+			if d := trees[i].DistTo(node); d != want.DistTo(node) {
+				t.Errorf("Tree %d: distance to %v is %v, expected %v", i, node, d, want.DistTo(node))
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	trees, err := graph.ParallelDijkstraAllCtx(ctx, sources, g, nil, 2)
+	if err != context.Canceled || len(trees) != len(sources) {
+		t.Errorf("Expected a tree slot for every source and context.Canceled, got %d and %v", len(trees), err)
+	}
+}
+
+func BenchmarkParallelDijkstraAll(b *testing.B) {
+	g := randomWeightedGraph(2000, 0.003, 9)
+	sources := g.NodeList()[:64]
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, source := range sources {
+				graph.DijkstraTree(source, g, nil)
+			}
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				graph.ParallelDijkstraAll(sources, g, nil, workers)
+			}
+		})
+	}
+}
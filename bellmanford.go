@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// The error BellmanFordTree returns when a cycle of negative total cost can be reached from the source, so that going round it again and again makes paths through it as cheap
+// as you like, and there's no shortest path to anything it leads to. Cycle is one such cycle: Cycle[0] has an edge to Cycle[1], and so on round to the last node, which has an
+// edge back to Cycle[0]. In an arbitrage graph, where edges cost minus the log of an exchange rate, it's a sequence of trades that makes money.
+type NegativeCycleError struct {
+	Cycle []Node
+}
+
+// Lists the cycle's node IDs, in the form "Negative cycle: 1 -> 2 -> 3 -> 1".
+func (e *NegativeCycleError) Error() string {
+	var buf bytes.Buffer
+	buf.WriteString("Negative cycle: ")
+	for _, node := range e.Cycle {
+		fmt.Fprintf(&buf, "%d -> ", node.ID())
+	}
+	fmt.Fprintf(&buf, "%d", e.Cycle[0].ID())
+	return buf.String()
+}
+
+// Finds the shortest path from source to every node it can reach by the Bellman-Ford algorithm, which unlike Dijkstra's allows edges to cost less than nothing. Cost is
+// interpreted as in AStar. Every round relaxes every edge, in order of head and then tail ID, and after at most one round per node the distances are final unless there's a
+// negative cycle; the search stops at the first round that changes nothing, so it's O(nm) at worst. In an undirected graph a negative edge is a negative cycle on its own,
+// since it can be crossed back and forth.
+//
+// If a negative cycle can be reached from source, the error is a *NegativeCycleError holding one, and the tree is nil. Negative cycles elsewhere in the graph don't matter.
+func BellmanFordTree(source Node, graph Graph, Cost func(Node, Node) float64) (*ShortestPathTree, error) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	type arc struct {
+		head, tail Node
+		cost       float64
+	}
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	var arcs []arc
+	for _, node := range nodes {
+		succs := graph.Successors(node)
+		sort.Sort(byID(succs))
+		for _, succ := range succs {
+			arcs = append(arcs, arc{node, succ, Cost(node, succ)})
+		}
+	}
+
+	tree := &ShortestPathTree{
+		Source: source,
+		dist:   map[int]float64{source.ID(): 0},
+		pred:   make(map[int]Node),
+	}
+	reached := map[int]Node{source.ID(): source}
+	relax := func() (changed Node) {
+		for _, a := range arcs {
+			d, ok := tree.dist[a.head.ID()]
+			if !ok {
+				continue
+			}
+			if old, ok := tree.dist[a.tail.ID()]; !ok || d+a.cost < old {
+				tree.dist[a.tail.ID()] = d + a.cost
+				tree.pred[a.tail.ID()] = a.head
+				reached[a.tail.ID()] = a.tail
+				changed = a.tail
+			}
+		}
+		return changed
+	}
+
+	// Shortest paths have at most n-1 edges, so if the nth round still changes something there's a negative cycle
+	for i := 0; i < len(nodes); i++ {
+		changed := relax()
+		if changed == nil {
+			break
+		}
+		if i == len(nodes)-1 {
+			return nil, &NegativeCycleError{Cycle: predecessorCycle(tree.pred, changed, len(nodes))}
+		}
+	}
+
+	for _, node := range reached {
+		tree.Order = append(tree.Order, node)
+	}
+	sort.Sort(byDistance{tree.Order, tree.dist})
+
+	return tree, nil
+}
+
+// Walks back n predecessors from node, which has been updated in the last of n rounds of relaxation and so has a negative cycle behind it, to land on the cycle, and returns
+// the cycle in the direction of its edges.
+func predecessorCycle(pred map[int]Node, node Node, n int) []Node {
+	for i := 0; i < n; i++ {
+		node = pred[node.ID()]
+	}
+
+	cycle := []Node{node}
+	for prev := pred[node.ID()]; prev.ID() != node.ID(); prev = pred[prev.ID()] {
+		cycle = append(cycle, prev)
+	}
+	for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	}
+
+	return cycle
+}
+
+// Sorts nodes by their distances, nearest first, and then by ID
+type byDistance struct {
+	nodes []Node
+	dist  map[int]float64
+}
+
+func (b byDistance) Len() int {
+	return len(b.nodes)
+}
+
+func (b byDistance) Less(i, j int) bool {
+	di, dj := b.dist[b.nodes[i].ID()], b.dist[b.nodes[j].ID()]
+	return di < dj || di == dj && b.nodes[i].ID() < b.nodes[j].ID()
+}
+
+func (b byDistance) Swap(i, j int) {
+	b.nodes[i], b.nodes[j] = b.nodes[j], b.nodes[i]
+}
@@ -0,0 +1,113 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func weightedDigraph(edges [][3]float64) *graph.DirectedGraph {
+	g := graph.NewDirectedGraph(0, 0)
+	for _, e := range edges {
+		edge := graph.GonumEdge{H: graph.GonumNode(int(e[0])), T: graph.GonumNode(int(e[1]))}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, e[2])
+	}
+	return g
+}
+
+func TestBellmanFordTree(t *testing.T) {
+	g := weightedDigraph([][3]float64{{0, 1, 4}, {0, 2, 5}, {1, 3, -3}, {2, 1, -2}, {3, 4, 2}, {2, 4, 7}})
+	g.AddNode(graph.GonumNode(9), nil)
+	tree, err := graph.BellmanFordTree(graph.GonumNode(0), g, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[int]float64{0: 0, 1: 3, 2: 5, 3: 0, 4: 2} {
+		if d := tree.DistTo(graph.GonumNode(id)); d != want {
+			t.Errorf("Expected %d at %v, got %v", id, want, d)
+		}
+	}
+	if path, _ := tree.PathTo(graph.GonumNode(4)); !reflect.DeepEqual(nodeIDs(path), []int{0, 2, 1, 3, 4}) {
+		t.Errorf("Expected the path 0 2 1 3 4, got %v", nodeIDs(path))
+	}
+	if path, cost := tree.PathTo(graph.GonumNode(9)); path != nil || !math.IsInf(cost, 1) || len(tree.Order) != 5 {
+		t.Errorf("Expected no path to 9 and 5 nodes reached, got %v and %v", nodeIDs(path), nodeIDs(tree.Order))
+	}
+
+	// Without negative costs it agrees with Dijkstra, and BellmanFord with it
+	r := randomWeightedGraph(80, 0.06, 2)
+	dijkstra := graph.DijkstraTree(graph.GonumNode(0), r, nil)
+	tree, err = graph.BellmanFordTree(graph.GonumNode(0), r, nil)
+	_, costs, aborted := graph.BellmanFord(graph.GonumNode(0), r, nil)
+	if err != nil || aborted || len(tree.Order) != len(dijkstra.Order) || len(costs) != len(dijkstra.Order) {
+		t.Fatalf("Expected %d nodes reached, got %d and %d (%v)", len(dijkstra.Order), len(tree.Order), len(costs), err)
+	}
+	for _, node := range dijkstra.Order {
+		if d, want := tree.DistTo(node), dijkstra.DistTo(node); math.Abs(d-want) > 1e-9 || math.Abs(costs[node.ID()]-want) > 1e-9 {
+			t.Errorf("Expected %v at %v, got %v and %v", node, want, d, costs[node.ID()])
+		}
+	}
+}
+
+func TestBellmanFordNegativeCycle(t *testing.T) {
+	// Exchange rates: going 0 -> 1 -> 2 -> 0 turns 1 into 1.0125
+	rates := [][3]float64{{0, 1, 0.9}, {1, 2, 1.5}, {2, 0, 0.75}, {1, 0, 1.1}, {2, 3, 2}}
+	for i := range rates {
+		rates[i][2] = -math.Log(rates[i][2])
+	}
+	g := weightedDigraph(rates)
+	tree, err := graph.BellmanFordTree(graph.GonumNode(3), g, nil)
+	if err != nil || len(tree.Order) != 1 {
+		t.Errorf("Expected the cycle not to matter from 3, which can't reach it, got %v", err)
+	}
+
+	_, err = graph.BellmanFordTree(graph.GonumNode(0), g, nil)
+	nerr, ok := err.(*graph.NegativeCycleError)
+	if !ok {
+		t.Fatalf("Expected a NegativeCycleError, got %v", err)
+	}
+	total := 0.0
+	for i, node := range nerr.Cycle {
+		next := nerr.Cycle[(i+1)%len(nerr.Cycle)]
+		if !g.IsSuccessor(node, next) {
+			t.Fatalf("The cycle %v goes from %v to %v, which isn't an edge", nodeIDs(nerr.Cycle), node, next)
+		}
+		total += g.Cost(node, next)
+	}
+	if total >= 0 || len(nerr.Cycle) != 3 {
+		t.Errorf("Expected the 3 node arbitrage cycle, got %v costing %v", nodeIDs(nerr.Cycle), total)
+	}
+	if _, _, aborted := graph.BellmanFord(graph.GonumNode(0), g, nil); !aborted {
+		t.Error("Expected BellmanFord to abort")
+	}
+
+	u := graph.NewUndirectedGraph(0, 0)
+	edge := graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}
+	u.AddEdge(edge)
+	u.SetEdgeCost(edge, -1)
+	if _, err := graph.BellmanFordTree(graph.GonumNode(0), u, nil); err == nil || len(err.(*graph.NegativeCycleError).Cycle) != 2 {
+		t.Errorf("Expected a negative undirected edge to be a cycle of two nodes, got %v", err)
+	}
+
+	// Random graphs with a negative cycle planted somewhere reachable
+	src := rand.New(rand.NewSource(7))
+	for i := 0; i < 20; i++ {
+		r := randomWeightedGraph(40, 0.1, int64(i))
+		path, _ := graph.BFSShortestPath(graph.GonumNode(0), graph.GonumNode(1+src.Intn(39)), r)
+		if len(path) < 2 {
+			continue
+		}
+		back := graph.GonumEdge{H: path[len(path)-1], T: path[0]}
+		r.AddEdge(back)
+		r.SetEdgeCost(back, -1000)
+		if _, err := graph.BellmanFordTree(graph.GonumNode(0), r, nil); err == nil {
+			t.Errorf("Missed the negative cycle through %v", nodeIDs(path))
+		} else if nerr := err.(*graph.NegativeCycleError); len(nerr.Cycle) < 2 {
+			t.Errorf("Expected a cycle of at least two nodes, got %v", nodeIDs(nerr.Cycle))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"math"
+)
+
+// Finds a shortest path from start to goal by Dijkstra's algorithm run from both ends at once, forwards from start along Successors and backwards from goal along Predecessors,
+// until the two searches have settled enough between them to be sure of the best path where they meet. Each search only has to reach about half way, so on a road network,
+// where the nodes within a distance grow with its square, the two together settle around half the nodes a one sided search would. Costs must not be negative.
+//
+// Cost is interpreted as in AStar, and the results are the same: the path, its cost, and the number of nodes expanded by both searches, or nil, 0 and the expansions if there's
+// no path.
+func BidirectionalDijkstra(start, goal Node, graph ReversibleGraph, Cost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	return BidirectionalAStar(start, goal, graph, Cost, NullHeuristic)
+}
+
+// Like BidirectionalDijkstra, but both searches are guided by HeuristicCost, as A* is: the forward search towards goal by HeuristicCost(node, goal), and the backward search
+// towards start by HeuristicCost(start, node). HeuristicCost is interpreted as in AStar, but it must be consistent (as the geometric heuristics are on graphs whose edges cost at
+// least their length), not just admissible, or the path found may not be the shortest.
+//
+// The two estimates are averaged into one potential, (HeuristicCost(node, goal) - HeuristicCost(start, node)) / 2, that both searches use with opposite signs[1]. That way they
+// search the same reweighted graph, in which the usual bidirectional stopping rule holds: once the smallest keys on the two queues add up to no less than the cost of the best
+// path found, nothing left can beat it.
+//
+// [1] A. V. Goldberg and C. Harrelson, "Computing the shortest path: A* search meets graph theory", SODA '05
+func BidirectionalAStar(start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	visit := successorVisitor(graph, Cost)
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+	if start.ID() == goal.ID() {
+		return []Node{start}, 0, 0
+	}
+
+	potential := func(node Node) float64 {
+		return (HeuristicCost(node, goal) - HeuristicCost(start, node)) / 2
+	}
+	forward := newBidirectionalSide(start, potential(start), visit)
+	backward := newBidirectionalSide(goal, -potential(goal), func(node Node, fn func(Node, float64) bool) {
+		for _, pred := range graph.Predecessors(node) {
+			if !fn(pred, Cost(pred, node)) {
+				return
+			}
+		}
+	})
+
+	// A key is a distance plus the node's potential going forwards, minus it going backwards, so the two keys of a node add up to the cost of the best path through it
+	var meet Node
+	best := math.Inf(1)
+	for forward.open.Len() != 0 && backward.open.Len() != 0 {
+		if forward.open.Peek().Key.(float64)+backward.open.Peek().Key.(float64) >= best {
+			break
+		}
+
+		side, other, sign := forward, backward, 1.0
+		if backward.open.Peek().Key.(float64) < forward.open.Peek().Key.(float64) {
+			side, other, sign = backward, forward, -1.0
+		}
+		curr := side.open.Pop().Node
+		side.settled[curr.ID()] = true
+		nodesExpanded++
+
+		d := side.dist[curr.ID()]
+		side.next(curr, func(succ Node, edgeCost float64) bool {
+			if side.settled[succ.ID()] {
+				return true
+			}
+			if old, ok := side.dist[succ.ID()]; !ok || d+edgeCost < old {
+				side.dist[succ.ID()] = d + edgeCost
+				side.pred[succ.ID()] = curr
+				side.open.Push(succ, d+edgeCost+sign*potential(succ)) // Decreases the key if it's already queued
+			}
+			if od, ok := other.dist[succ.ID()]; ok && side.dist[succ.ID()]+od < best {
+				meet, best = succ, side.dist[succ.ID()]+od
+			}
+			return true
+		})
+	}
+
+	if meet == nil {
+		return nil, 0, nodesExpanded
+	}
+	path = rebuildPath(forward.pred, meet)
+	for node, ok := backward.pred[meet.ID()]; ok; node, ok = backward.pred[node.ID()] {
+		path = append(path, node)
+	}
+
+	return path, best, nodesExpanded
+}
+
+// One direction of a bidirectional Dijkstra or A* search
+type bidirectionalSide struct {
+	open    PriorityQueue
+	dist    map[int]float64
+	pred    map[int]Node
+	settled map[int]bool
+	next    func(node Node, fn func(Node, float64) bool)
+}
+
+func newBidirectionalSide(source Node, key float64, next func(node Node, fn func(Node, float64) bool)) *bidirectionalSide {
+	side := &bidirectionalSide{
+		open: NewPriorityQueue(BinaryHeapQueue, func(a, b HeapItem) bool {
+			return a.Key.(float64) < b.Key.(float64)
+		}),
+		dist:    map[int]float64{source.ID(): 0},
+		pred:    make(map[int]Node),
+		settled: make(map[int]bool),
+		next:    next,
+	}
+	side.open.Push(source, key)
+
+	return side
+}
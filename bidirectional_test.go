@@ -0,0 +1,76 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Checks path goes along edges of g from start to goal and costs cost
+func checkPath(t *testing.T, g graph.Graph, path []graph.Node, cost float64, start, goal graph.Node) {
+	sum := 0.0
+	for i := 1; i < len(path); i++ {
+		if !g.IsSuccessor(path[i-1], path[i]) {
+			t.Fatalf("The path %v goes from %v to %v, which isn't an edge", nodeIDs(path), path[i-1], path[i])
+		}
+		sum += g.(graph.Coster).Cost(path[i-1], path[i])
+	}
+	if path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID() || math.Abs(sum-cost) > 1e-9 {
+		t.Errorf("The path %v, costing %v by its edges, doesn't go from %v to %v for the %v reported", nodeIDs(path), sum, start, goal, cost)
+	}
+}
+
+func TestBidirectionalDijkstra(t *testing.T) {
+	g := randomWeightedGraph(300, 0.02, 6)
+	src := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		start, goal := graph.GonumNode(src.Intn(300)), graph.GonumNode(src.Intn(300))
+		_, want, _ := graph.AStar(start, goal, g, nil, nil)
+		path, cost, _ := graph.BidirectionalDijkstra(start, goal, g, nil)
+		if path == nil {
+			if want != 0 || start.ID() == goal.ID() {
+				t.Errorf("No path from %v to %v, where A* finds one costing %v", start, goal, want)
+			}
+			continue
+		}
+		if math.Abs(cost-want) > 1e-9 {
+			t.Errorf("From %v to %v costs %v, A* says %v", start, goal, cost, want)
+		}
+		checkPath(t, g, path, cost, start, goal)
+	}
+}
+
+func TestBidirectionalAStar(t *testing.T) {
+	grid := graph.NewGridGraph(60, 60, true)
+	src := rand.New(rand.NewSource(3))
+	for i := 0; i < 900; i++ {
+		grid.SetObstacle(src.Intn(60), src.Intn(60))
+	}
+	grid.ClearObstacle(0, 0)
+	grid.ClearObstacle(59, 59)
+	grid.SetObstacle(58, 58)
+	start, goal := grid.CoordsToNode(0, 0), grid.CoordsToNode(59, 59)
+
+	_, want, _ := graph.AStar(start, goal, grid, nil, nil)
+	path, cost, expanded := graph.BidirectionalAStar(start, goal, grid, nil, nil)
+	if path == nil || math.Abs(cost-want) > 1e-9 {
+		t.Fatalf("Expected a path costing %v, got %v costing %v", want, nodeIDs(path), cost)
+	}
+	checkPath(t, grid, path, cost, start, goal)
+	if _, _, blind := graph.BidirectionalDijkstra(start, goal, grid, nil); expanded >= blind {
+		t.Errorf("Bidirectional A* expanded %d nodes, no fewer than bidirectional Dijkstra's %d", expanded, blind)
+	}
+
+	// Walling the goal in leaves no path
+	for _, c := range [][2]int{{58, 59}, {59, 58}} {
+		grid.SetObstacle(c[0], c[1])
+	}
+	if path, cost, _ := graph.BidirectionalAStar(start, goal, grid, nil, nil); path != nil || cost != 0 {
+		t.Errorf("Expected no path to the walled in goal, got %v costing %v", nodeIDs(path), cost)
+	}
+	if path, cost, _ := graph.BidirectionalAStar(start, start, grid, nil, nil); len(path) != 1 || cost != 0 {
+		t.Errorf("Expected the path from a node to itself to be just the node, got %v costing %v", nodeIDs(path), cost)
+	}
+}
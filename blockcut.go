@@ -0,0 +1,192 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Finds the biconnected components (blocks) of the undirected graph underlying graph, and its articulation points, by the algorithm of Hopcroft and Tarjan[1]. A block is a maximal
+// set of nodes that no single node's removal disconnects: a bridge and its two ends, or a set in which any two nodes lie on a common cycle. Articulation points (cut vertices) are the
+// nodes whose removal disconnects their component, and are exactly the nodes in more than one block. Every edge is in exactly one block, and an isolated node is a block of its own.
+//
+// Blocks list their nodes in order of ID; articulation points are also in order of ID. Runs in O(n + m).
+//
+// [1] J. Hopcroft and R. E. Tarjan, "Algorithm 447: Efficient algorithms for graph manipulation", Communications of the ACM 16 (1973)
+func BiconnectedComponents(graph Graph) (blocks [][]Node, articulationPoints []Node) {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+
+	disc := make(map[int]int, len(nodes))
+	low := make(map[int]int, len(nodes))
+	isCut := make(map[int]bool)
+	var edges [][2]Node
+
+	type frame struct {
+		node, parent Node
+		neighbors    []Node
+		next         int
+	}
+	for _, root := range nodes {
+		if _, seen := disc[root.ID()]; seen {
+			continue
+		}
+		disc[root.ID()], low[root.ID()] = len(disc), len(disc)
+		neighbors, _ := treeNeighbors(graph, root)
+		if len(neighbors) == 0 {
+			blocks = append(blocks, []Node{root})
+			continue
+		}
+
+		rootChildren := 0
+		stack := []*frame{{node: root, neighbors: neighbors}}
+		for len(stack) != 0 {
+			top := stack[len(stack)-1]
+			u := top.node
+			if top.next < len(top.neighbors) {
+				w := top.neighbors[top.next]
+				top.next++
+				if _, seen := disc[w.ID()]; !seen {
+					disc[w.ID()], low[w.ID()] = len(disc), len(disc)
+					edges = append(edges, [2]Node{u, w})
+					next, _ := treeNeighbors(graph, w)
+					stack = append(stack, &frame{node: w, parent: u, neighbors: next})
+					if u.ID() == root.ID() {
+						rootChildren++
+					}
+				} else if (top.parent == nil || w.ID() != top.parent.ID()) && disc[w.ID()] < disc[u.ID()] {
+					edges = append(edges, [2]Node{u, w})
+					low[u.ID()] = minInt(low[u.ID()], disc[w.ID()])
+				}
+				continue
+			}
+
+			// u is finished, so its parent learns how high u's subtree reaches, and if no higher than the parent, the edges since the tree edge into u are a block
+			stack = stack[:len(stack)-1]
+			parent := top.parent
+			if parent == nil {
+				continue
+			}
+			low[parent.ID()] = minInt(low[parent.ID()], low[u.ID()])
+			if low[u.ID()] >= disc[parent.ID()] {
+				if parent.ID() != root.ID() {
+					isCut[parent.ID()] = true
+				}
+				in := make(map[int]Node)
+				for {
+					edge := edges[len(edges)-1]
+					edges = edges[:len(edges)-1]
+					in[edge[0].ID()], in[edge[1].ID()] = edge[0], edge[1]
+					if edge[0].ID() == parent.ID() && edge[1].ID() == u.ID() {
+						break
+					}
+				}
+				block := make([]Node, 0, len(in))
+				for _, node := range in {
+					block = append(block, node)
+				}
+				sort.Sort(byID(block))
+				blocks = append(blocks, block)
+			}
+		}
+		// The root is only cut off from its subtrees if it has more than one
+		if rootChildren > 1 {
+			isCut[root.ID()] = true
+		}
+	}
+
+	for _, node := range nodes {
+		if isCut[node.ID()] {
+			articulationPoints = append(articulationPoints, node)
+		}
+	}
+
+	return blocks, articulationPoints
+}
+
+// A BlockCutTree shows how a graph hangs together through its articulation points: it has a node for every block (biconnected component) and one for every articulation point,
+// with an edge between each articulation point and each block containing it. Two nodes of the graph stay connected after any one other node fails unless it's an articulation
+// point on the path between them in this tree, which is what makes it the map of how single failures propagate through a network.
+//
+// Tree is an undirected forest, with a tree for each connected component of the graph. Its nodes are GonumNode(i) for Blocks[i], and GonumNode(len(Blocks)+j) for
+// ArticulationPoints[j].
+type BlockCutTree struct {
+	Tree               *GonumGraph
+	Blocks             [][]Node
+	ArticulationPoints []Node
+
+	treeNode map[int]Node  // Each graph node's articulation point node, or its only block
+	blocksOf map[int][]int // The blocks each graph node is in
+}
+
+// Builds the block-cut tree of the undirected graph underlying graph.
+func NewBlockCutTree(graph Graph) *BlockCutTree {
+	blocks, cuts := BiconnectedComponents(graph)
+	bct := &BlockCutTree{Tree: NewGonumGraph(false), Blocks: blocks, ArticulationPoints: cuts, treeNode: make(map[int]Node), blocksOf: make(map[int][]int)}
+
+	for i, block := range blocks {
+		bct.Tree.AddNode(GonumNode(i), nil)
+		for _, node := range block {
+			bct.treeNode[node.ID()] = GonumNode(i)
+			bct.blocksOf[node.ID()] = append(bct.blocksOf[node.ID()], i)
+		}
+	}
+	for j, cut := range cuts {
+		c := GonumNode(len(blocks) + j)
+		bct.treeNode[cut.ID()] = c
+		bct.Tree.AddNode(c, nil)
+		for _, i := range bct.blocksOf[cut.ID()] {
+			bct.Tree.AddEdge(GonumEdge{H: c, T: GonumNode(i)})
+		}
+	}
+
+	return bct
+}
+
+// The node of Tree that stands for node: its articulation point node if it is one, and otherwise the one block it's in. Returns nil if node isn't in the graph.
+func (bct *BlockCutTree) TreeNode(node Node) Node {
+	return bct.treeNode[node.ID()]
+}
+
+// The indices of the blocks node is in: more than one exactly if it's an articulation point.
+func (bct *BlockCutTree) BlocksOf(node Node) []int {
+	return bct.blocksOf[node.ID()]
+}
+
+// Whether the failure (removal) of failed would leave u and v disconnected: if they're disconnected already, or if failed is an articulation point on the path between them in the
+// tree. The failure of u or v itself doesn't count, and gives false.
+func (bct *BlockCutTree) Separates(failed, u, v Node) bool {
+	from, to := bct.TreeNode(u), bct.TreeNode(v)
+	if from == nil || to == nil {
+		return true
+	}
+	if failed.ID() == u.ID() || failed.ID() == v.ID() {
+		return false
+	}
+	cut := bct.TreeNode(failed)
+
+	// Breadth first search through the tree, which is small: one node per block and articulation point
+	parent := map[int]Node{from.ID(): nil}
+	queue := []Node{from}
+	for len(queue) != 0 && queue[0].ID() != to.ID() {
+		curr := queue[0]
+		queue = queue[1:]
+		for _, next := range bct.Tree.Successors(curr) {
+			if _, seen := parent[next.ID()]; !seen {
+				parent[next.ID()] = curr
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, reached := parent[to.ID()]; !reached {
+		return true
+	}
+	if cut == nil || cut.ID() < len(bct.Blocks) {
+		return false
+	}
+	for node := to; node != nil; node = parent[node.ID()] {
+		if node.ID() == cut.ID() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Whether u and v are connected once failed is removed
+func connectedWithout(g graph.Graph, failed, u, v graph.Node) bool {
+	seen := map[int]bool{u.ID(): true}
+	stack := []graph.Node{u}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node.ID() == v.ID() {
+			return true
+		}
+		for _, next := range g.Successors(node) {
+			if next.ID() != failed.ID() && !seen[next.ID()] {
+				seen[next.ID()] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return false
+}
+
+func TestBiconnectedComponents(t *testing.T) {
+	// Two triangles sharing node 2, a bridge from 4 to 5, and an isolated node
+	g := graph.NewGonumGraph(false)
+	for i := 0; i < 7; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 2}, {4, 5}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	blocks, cuts := graph.BiconnectedComponents(g)
+	if len(blocks) != 4 || len(cuts) != 2 || cuts[0].ID() != 2 || cuts[1].ID() != 4 {
+		t.Errorf("Expected 4 blocks and articulation points 2 and 4, got %v and %v", blocks, cuts)
+	}
+
+	bct := graph.NewBlockCutTree(g)
+	if len(bct.Tree.NodeList()) != 6 || len(bct.BlocksOf(graph.GonumNode(2))) != 2 || len(bct.BlocksOf(graph.GonumNode(0))) != 1 {
+		t.Errorf("Expected a block-cut tree of 4 blocks and 2 articulation points, got %v", bct.Tree.EdgeList())
+	}
+	if !graph.IsForest(bct.Tree) {
+		t.Error("The block-cut tree has a cycle")
+	}
+
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		g := graph.NewGonumGraph(false)
+		graph.GnpRandomGraph(g, 30, 0.07, false, src)
+		nodes := g.NodeList()
+		bct := graph.NewBlockCutTree(g)
+
+		isCut := make(map[int]bool)
+		for _, cut := range bct.ArticulationPoints {
+			isCut[cut.ID()] = true
+		}
+		for _, failed := range nodes {
+			// An articulation point is one whose removal disconnects two of its neighbors
+			cut := false
+			neighbors := g.Successors(failed)
+			for _, u := range neighbors {
+				for _, v := range neighbors {
+					cut = cut || !connectedWithout(g, failed, u, v)
+				}
+			}
+			if cut != isCut[failed.ID()] {
+				t.Errorf("Node %v is an articulation point: %t, expected %t", failed, isCut[failed.ID()], cut)
+			}
+		}
+
+		for i := 0; i < 200; i++ {
+			failed, u, v := nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]
+			want := failed.ID() != u.ID() && failed.ID() != v.ID() && !connectedWithout(g, failed, u, v)
+			if got := bct.Separates(failed, u, v); got != want {
+				t.Errorf("Removing %v separates %v from %v: %t, expected %t", failed, u, v, got, want)
+			}
+		}
+
+		// Every edge is in exactly one block
+		for _, edge := range g.EdgeList() {
+			count := 0
+			for _, block := range bct.Blocks {
+				in := 0
+				for _, node := range block {
+					if node.ID() == edge.Head().ID() || node.ID() == edge.Tail().ID() {
+						in++
+					}
+				}
+				if in == 2 {
+					count++
+				}
+			}
+			if count != 1 {
+				t.Errorf("Edge %v is in %d blocks", edge, count)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Returned when an algorithm for bipartite graphs is given a graph with an odd cycle.
+var ErrNotBipartite = errors.New("Graph is not bipartite")
+
+// Finds a maximum weight b-matching of the undirected graph underlying graph, which must be bipartite: a set of edges, each used at most once, such that every node is an end
+// of at most B of them, with the largest total weight. With B always 1 it's an ordinary maximum weight matching; larger B suits pairing with capacities, such as shifts that
+// need several staff, or staff who can cover several shifts. If B is nil it's 1 for every node. Weight is interpreted as Cost is in AStar (so it's the graph's own Cost if
+// it's a Coster), and edges of no positive weight are never matched.
+//
+// The matching is a minimum cost flow, with the weights negated, from a source to one side, across the edges, and to a sink from the other side, with capacities B. Returns the
+// matched edges, with the ends as they are in graph, and their total weight, or ErrNotBipartite. For graphs that aren't bipartite, see GreedyBMatching.
+func MaxWeightBMatching(graph Graph, B func(Node) int, Weight func(Node, Node) float64) (matching []Edge, weight float64, err error) {
+	B, Weight = bMatchingDefaults(graph, B, Weight)
+	side, ok := bipartition(graph)
+	if !ok {
+		return nil, 0, ErrNotBipartite
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	index := make(map[int]int, len(nodes))
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+
+	n := len(nodes)
+	source, sink := n, n+1
+	network := newFlowNetwork(n + 2)
+	largest := 0.0
+	var edges []Edge
+	var arcs []int
+	for i, node := range nodes {
+		if !side[node.ID()] {
+			network.addArc(i, sink, float64(B(node)))
+			continue
+		}
+		network.addArc(source, i, float64(B(node)))
+		for _, edge := range neighborEdges(graph, node, Weight) {
+			if edge.Weight <= 0 {
+				continue
+			}
+			arc := network.addArc(i, index[otherEnd(edge, node).ID()], 1)
+			network.setCost(arc, -edge.Weight)
+			edges = append(edges, edge.Edge)
+			arcs = append(arcs, arc)
+			largest = math.Max(largest, edge.Weight)
+		}
+	}
+
+	network.minCostFlow(source, sink, math.Inf(1), 0, 1e-9*math.Max(1, largest))
+	for k, arc := range arcs {
+		if network.flow(arc) > 0.5 {
+			matching = append(matching, edges[k])
+			weight += Weight(edges[k].Head(), edges[k].Tail())
+		}
+	}
+
+	return matching, weight, nil
+}
+
+// Finds a b-matching of the undirected graph underlying graph (see MaxWeightBMatching) greedily, taking edges from heaviest to lightest while both ends have room. Works on any
+// graph, in O(m log m), and the matching weighs at least half the maximum.
+func GreedyBMatching(graph Graph, B func(Node) int, Weight func(Node, Node) float64) (matching []Edge, weight float64) {
+	B, Weight = bMatchingDefaults(graph, B, Weight)
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	room := make(map[int]int, len(nodes))
+	var edges []WeightedEdge
+	for _, node := range nodes {
+		room[node.ID()] = B(node)
+		for _, edge := range neighborEdges(graph, node, Weight) {
+			if edge.Weight > 0 && node.ID() < otherEnd(edge, node).ID() {
+				edges = append(edges, edge)
+			}
+		}
+	}
+	sort.Stable(sort.Reverse(edgeSorter(edges)))
+
+	for _, edge := range edges {
+		if room[edge.Head().ID()] > 0 && room[edge.Tail().ID()] > 0 {
+			room[edge.Head().ID()]--
+			room[edge.Tail().ID()]--
+			matching = append(matching, edge.Edge)
+			weight += edge.Weight
+		}
+	}
+
+	return matching, weight
+}
+
+func bMatchingDefaults(graph Graph, B func(Node) int, Weight func(Node, Node) float64) (func(Node) int, func(Node, Node) float64) {
+	if B == nil {
+		B = func(Node) int { return 1 }
+	}
+	if Weight == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Weight = cgraph.Cost
+		} else {
+			Weight = UniformCost
+		}
+	}
+	return B, Weight
+}
+
+// The edges between node and its neighbors, each weighted and with its ends as in graph. If there are edges both ways, it's the one out of node.
+func neighborEdges(graph Graph, node Node, Weight func(Node, Node) float64) (edges []WeightedEdge) {
+	neighbors, _ := treeNeighbors(graph, node)
+	for _, neighbor := range neighbors {
+		if graph.IsSuccessor(node, neighbor) {
+			edges = append(edges, WeightedEdge{GonumEdge{H: node, T: neighbor}, Weight(node, neighbor)})
+		} else {
+			edges = append(edges, WeightedEdge{GonumEdge{H: neighbor, T: node}, Weight(neighbor, node)})
+		}
+	}
+	return edges
+}
+
+// The end of edge that isn't node
+func otherEnd(edge Edge, node Node) Node {
+	if edge.Head().ID() == node.ID() {
+		return edge.Tail()
+	}
+	return edge.Head()
+}
+
+// Two colors the undirected graph underlying graph, returning which nodes are on the side of the first node of each component (in order of ID), or false if there's an odd
+// cycle (or a self loop).
+func bipartition(graph Graph) (side map[int]bool, ok bool) {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	side = make(map[int]bool, len(nodes))
+	for _, root := range nodes {
+		if _, seen := side[root.ID()]; seen {
+			continue
+		}
+		side[root.ID()] = true
+		queue := []Node{root}
+		for len(queue) != 0 {
+			node := queue[0]
+			queue = queue[1:]
+			neighbors, loop := treeNeighbors(graph, node)
+			if loop {
+				return nil, false
+			}
+			for _, neighbor := range neighbors {
+				if s, seen := side[neighbor.ID()]; !seen {
+					side[neighbor.ID()] = !side[node.ID()]
+					queue = append(queue, neighbor)
+				} else if s == side[node.ID()] {
+					return nil, false
+				}
+			}
+		}
+	}
+
+	return side, true
+}
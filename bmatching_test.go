@@ -0,0 +1,119 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Checks no node is matched more than b times nor any edge twice, and that the weight adds up
+func checkBMatching(t *testing.T, matching []graph.Edge, weight float64, b func(graph.Node) int, w func(graph.Node, graph.Node) float64) {
+	degree := make(map[int]int)
+	used := make(map[[2]int]bool)
+	total := 0.0
+	for _, edge := range matching {
+		u, v := edge.Head().ID(), edge.Tail().ID()
+		if u > v {
+			u, v = v, u
+		}
+		if used[[2]int{u, v}] {
+			t.Errorf("Edge %v is matched twice", edge)
+		}
+		used[[2]int{u, v}] = true
+		degree[edge.Head().ID()]++
+		degree[edge.Tail().ID()]++
+		total += w(edge.Head(), edge.Tail())
+	}
+	for id, d := range degree {
+		if d > b(graph.GonumNode(id)) {
+			t.Errorf("Node %d is matched %d times, but b is %d", id, d, b(graph.GonumNode(id)))
+		}
+	}
+	if math.Abs(total-weight) > 1e-9 {
+		t.Errorf("Matching weighs %v, but the weight is %v", total, weight)
+	}
+}
+
+// The heaviest b-matching, by trying every subset of edges
+func bruteForceBMatching(edges [][2]int, b func(graph.Node) int, w func(graph.Node, graph.Node) float64) float64 {
+	best := 0.0
+	for mask := 0; mask < 1<<uint(len(edges)); mask++ {
+		degree := make(map[int]int)
+		total, ok := 0.0, true
+		for i, e := range edges {
+			if mask&(1<<uint(i)) != 0 {
+				degree[e[0]]++
+				degree[e[1]]++
+				total += w(graph.GonumNode(e[0]), graph.GonumNode(e[1]))
+				ok = ok && degree[e[0]] <= b(graph.GonumNode(e[0])) && degree[e[1]] <= b(graph.GonumNode(e[1]))
+			}
+		}
+		if ok {
+			best = math.Max(best, total)
+		}
+	}
+	return best
+}
+
+func TestMaxWeightBMatching(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 30; trial++ {
+		// Nodes 0 to 3 on one side and 4 to 7 on the other
+		g := graph.NewGonumGraph(false)
+		for i := 0; i < 8; i++ {
+			g.AddNode(graph.GonumNode(i), nil)
+		}
+		weights := make(map[[2]int]float64)
+		var edges [][2]int
+		for len(edges) < 10 {
+			u, v := src.Intn(4), 4+src.Intn(4)
+			if _, ok := weights[[2]int{u, v}]; ok {
+				continue
+			}
+			weights[[2]int{u, v}] = float64(src.Intn(10) - 2)
+			weights[[2]int{v, u}] = weights[[2]int{u, v}]
+			edges = append(edges, [2]int{u, v})
+			g.AddEdge(graph.GonumEdge{H: graph.GonumNode(u), T: graph.GonumNode(v)})
+		}
+		bs := make([]int, 8)
+		for i := range bs {
+			bs[i] = 1 + src.Intn(2)
+		}
+		b := func(node graph.Node) int { return bs[node.ID()] }
+		w := func(u, v graph.Node) float64 { return weights[[2]int{u.ID(), v.ID()}] }
+
+		matching, weight, err := graph.MaxWeightBMatching(g, b, w)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		checkBMatching(t, matching, weight, b, w)
+		best := bruteForceBMatching(edges, b, w)
+		if math.Abs(weight-best) > 1e-9 {
+			t.Errorf("Expected a matching weighing %v, got %v", best, weight)
+		}
+
+		greedy, greedyWeight := graph.GreedyBMatching(g, b, w)
+		checkBMatching(t, greedy, greedyWeight, b, w)
+		if greedyWeight < best/2 {
+			t.Errorf("Greedy matching weighs %v, less than half of %v", greedyWeight, best)
+		}
+	}
+}
+
+func TestMaxWeightBMatchingNotBipartite(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.CycleGraph(g, 3, false)
+	if _, _, err := graph.MaxWeightBMatching(g, nil, nil); err != graph.ErrNotBipartite {
+		t.Errorf("Expected ErrNotBipartite for a triangle, got %v", err)
+	}
+
+	// A triangle can still be matched greedily, once with b 1, and all three edges with b 2
+	if matching, _ := graph.GreedyBMatching(g, nil, nil); len(matching) != 1 {
+		t.Errorf("Expected one edge matched, got %v", matching)
+	}
+	if matching, _ := graph.GreedyBMatching(g, func(graph.Node) int { return 2 }, nil); len(matching) != 3 {
+		t.Errorf("Expected every edge matched, got %v", matching)
+	}
+}
@@ -0,0 +1,132 @@
+package graph
+
+// A BucketQueue is a PriorityQueue for non-negative int keys, kept as an array of buckets indexed by key: pushing, fixing and removing are O(1), and popping only has to scan
+// forward from the last minimum to the next non-empty bucket. When keys only ever grow from one pop to the next, as in Dijkstra's algorithm with integer costs, the scanning adds
+// up to the largest key over the whole run, which beats any comparison heap when costs are small integers (grid maps, hop counts). This is Dial's algorithm's queue.
+//
+// Pushing a key below the current minimum is allowed, but the scan then starts over from there. Memory use is proportional to the largest key. Items with equal keys come out in
+// no particular order. Pushing a key that isn't a non-negative int panics.
+type BucketQueue struct {
+	buckets [][]Node
+	where   map[int]bucketPos
+	min     int // There's nothing queued below min
+}
+
+type bucketPos struct {
+	key, i int
+}
+
+// Creates an empty BucketQueue.
+func NewBucketQueue() *BucketQueue {
+	return &BucketQueue{where: make(map[int]bucketPos)}
+}
+
+func (q *BucketQueue) Len() int {
+	return len(q.where)
+}
+
+func (q *BucketQueue) Contains(node Node) bool {
+	_, ok := q.where[node.ID()]
+	return ok
+}
+
+func (q *BucketQueue) Key(node Node) (key interface{}, ok bool) {
+	if pos, ok := q.where[node.ID()]; ok {
+		return pos.key, true
+	}
+
+	return nil, false
+}
+
+func (q *BucketQueue) Push(node Node, key interface{}) {
+	if q.Fix(node, key) {
+		return
+	}
+	q.add(node, key.(int))
+}
+
+func (q *BucketQueue) Fix(node Node, key interface{}) bool {
+	if !q.Remove(node) {
+		return false
+	}
+
+	q.add(node, key.(int))
+	return true
+}
+
+func (q *BucketQueue) Peek() HeapItem {
+	q.advance()
+	bucket := q.buckets[q.min]
+	return HeapItem{Node: bucket[len(bucket)-1], Key: q.min}
+}
+
+func (q *BucketQueue) Pop() HeapItem {
+	item := q.Peek()
+	q.Remove(item.Node)
+
+	return item
+}
+
+func (q *BucketQueue) Remove(node Node) bool {
+	pos, ok := q.where[node.ID()]
+	if !ok {
+		return false
+	}
+
+	bucket := q.buckets[pos.key]
+	last := len(bucket) - 1
+	if pos.i != last {
+		bucket[pos.i] = bucket[last]
+		q.where[bucket[pos.i].ID()] = pos
+	}
+	q.buckets[pos.key] = bucket[:last]
+	delete(q.where, node.ID())
+	return true
+}
+
+func (q *BucketQueue) Clear() {
+	for i := range q.buckets {
+		q.buckets[i] = q.buckets[i][:0]
+	}
+	for id := range q.where {
+		delete(q.where, id)
+	}
+	q.min = 0
+}
+
+// Returns the queued items in key order (ties in no particular order). The slice is freshly allocated.
+func (q *BucketQueue) Items() []HeapItem {
+	items := make([]HeapItem, 0, len(q.where))
+	for key := q.min; key < len(q.buckets); key++ {
+		for _, node := range q.buckets[key] {
+			items = append(items, HeapItem{Node: node, Key: key})
+		}
+	}
+
+	return items
+}
+
+func (q *BucketQueue) add(node Node, key int) {
+	if key < 0 {
+		panic("BucketQueue keys can't be negative")
+	}
+	for key >= len(q.buckets) {
+		q.buckets = append(q.buckets, nil)
+	}
+
+	q.where[node.ID()] = bucketPos{key: key, i: len(q.buckets[key])}
+	q.buckets[key] = append(q.buckets[key], node)
+	if key < q.min {
+		q.min = key
+	}
+}
+
+// Moves min up to the first non-empty bucket. Panics if the queue is empty, like the heaps do.
+func (q *BucketQueue) advance() {
+	if len(q.where) == 0 {
+		panic("BucketQueue is empty")
+	}
+	for len(q.buckets[q.min]) == 0 {
+		q.min++
+	}
+}
@@ -0,0 +1,123 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+)
+
+func TestBucketQueue(t *testing.T) {
+	q := graph.NewBucketQueue()
+	src := rand.New(rand.NewSource(1))
+	expect := make(map[int]int)
+	last := 0
+
+	for i := 0; i < 20000; i++ {
+		node := graph.GonumNode(src.Intn(200))
+		switch op := src.Intn(5); {
+		case op < 3:
+			// Like a search, keys mostly stay at or above the last minimum, but not always
+			key := last + src.Intn(20) - 2
+			if key < 0 {
+				key = 0
+			}
+			q.Push(node, key)
+			expect[node.ID()] = key
+		case op == 3:
+			_, queued := expect[node.ID()]
+			if removed := q.Remove(node); removed != queued {
+				t.Fatalf("Remove(%v) returned %v, but the node queued is %v", node, removed, queued)
+			}
+			delete(expect, node.ID())
+		default:
+			if q.Len() == 0 {
+				continue
+			}
+			min := -1
+			for _, key := range expect {
+				if min == -1 || key < min {
+					min = key
+				}
+			}
+			peeked := q.Peek()
+			item := q.Pop()
+			if item.ID() != peeked.ID() || item.Key.(int) != min || expect[item.ID()] != min {
+				t.Fatalf("Popped %v with key %v (peeked %v), expected a node with key %d", item.Node, item.Key, peeked.Node, min)
+			}
+			delete(expect, item.ID())
+			last = min
+		}
+
+		if q.Len() != len(expect) {
+			t.Fatalf("Queue has %d items, expected %d", q.Len(), len(expect))
+		}
+	}
+
+	items := q.Items()
+	for i, item := range items {
+		if expect[item.ID()] != item.Key.(int) || i > 0 && items[i-1].Key.(int) > item.Key.(int) {
+			t.Errorf("Items has %v with key %v out of order, or expected key %d", item.Node, item.Key, expect[item.ID()])
+		}
+	}
+	if len(items) != len(expect) {
+		t.Errorf("Items returned %d items, expected %d", len(items), len(expect))
+	}
+}
+
+func TestDialDijkstra(t *testing.T) {
+	src := rand.New(rand.NewSource(3))
+	g := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(g, 100, 0.1, true, src)
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, float64(src.Intn(10)))
+	}
+
+	paths, costs, err := graph.DialDijkstra(graph.GonumNode(0), g, nil)
+	if err != nil {
+		t.Fatal("Dial's algorithm failed:", err)
+	}
+	expectPaths, expectCosts := graph.DijkstraQueue(graph.GonumNode(0), g, nil, graph.BinaryHeapQueue)
+	if len(paths) != len(expectPaths) || len(costs) != len(expectCosts) {
+		t.Fatalf("Dial's algorithm reached %d nodes, expected %d", len(paths), len(expectPaths))
+	}
+	for id, cost := range expectCosts {
+		if costs[id] != cost {
+			t.Errorf("Cost to %d is %v, expected %v", id, costs[id], cost)
+		}
+		sum := 0.0
+		for i := 1; i < len(paths[id]); i++ {
+			sum += g.Cost(paths[id][i-1], paths[id][i])
+		}
+		if sum != cost || paths[id][len(paths[id])-1].ID() != id {
+			t.Errorf("Path %v to %d costs %v, expected %v", paths[id], id, sum, cost)
+		}
+	}
+
+	if _, _, err := graph.DialDijkstra(graph.GonumNode(0), g, func(a, b graph.Node) float64 { return 0.5 }); err == nil {
+		t.Error("Dial's algorithm accepted fractional costs")
+	}
+}
+
+// A Dijkstra-like workload on a grid: every pop pushes (or decreases) a few neighbors with slightly larger keys
+func benchmarkMonotoneQueue(b *testing.B, q graph.PriorityQueue) {
+	src := rand.New(rand.NewSource(1))
+	for i := 0; i < b.N; i++ {
+		q.Clear()
+		q.Push(graph.GonumNode(0), 0)
+		for next := 1; q.Len() > 0; {
+			min := q.Pop().Key.(int)
+			for j := 0; j < 4 && next < 50000; j++ {
+				q.Push(graph.GonumNode(next-src.Intn(2)), min+1+src.Intn(3))
+				next++
+			}
+		}
+	}
+}
+
+func BenchmarkBucketQueue(b *testing.B) {
+	benchmarkMonotoneQueue(b, graph.NewBucketQueue())
+}
+
+func BenchmarkBinaryHeapIntKeys(b *testing.B) {
+	benchmarkMonotoneQueue(b, graph.NewIndexedHeap(func(a, b graph.HeapItem) bool { return a.Key.(int) < b.Key.(int) }))
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// How many iterations long running loops go between checks for cancellation. Checking a context isn't free, and a few hundred iterations of any search loop take microseconds.
+const cancelCheckInterval = 256
+
+// Returned by searches that ran out of their SearchBudget.
+var ErrBudgetExhausted = errors.New("Search budget exhausted")
+
+// Limits on the work a single search may do, for bounding the time spent on adversarial or unexpectedly huge graphs. Zero fields mean no limit.
+//
+// MaxExpansions limits the number of nodes taken off the search's queue (which includes stale entries that are skipped, so it's an upper bound on actual expansions).
+// MaxDuration limits wall time; it's checked every few hundred iterations, so it may be overrun slightly.
+type SearchBudget struct {
+	MaxExpansions int
+	MaxDuration   time.Duration
+}
+
+// A canceller is polled once per iteration of a long running loop, and reports the context's error or ErrBudgetExhausted. Contexts and deadlines are only checked periodically,
+// so polling is cheap. A nil canceller never cancels, so algorithms can poll unconditionally.
+type canceller struct {
+	ctx           context.Context
+	n             int
+	maxIterations int
+	deadline      time.Time
+}
+
+func newCanceller(ctx context.Context) *canceller {
+	return &canceller{ctx: ctx}
+}
+
+// Returns a canceller enforcing the budget as well as ctx (which may be nil), starting now.
+func (budget SearchBudget) canceller(ctx context.Context) *canceller {
+	c := &canceller{ctx: ctx, maxIterations: budget.MaxExpansions}
+	if budget.MaxDuration > 0 {
+		c.deadline = time.Now().Add(budget.MaxDuration)
+	}
+
+	return c
+}
+
+func (c *canceller) err() error {
+	if c == nil {
+		return nil
+	}
+
+	c.n++
+	if c.maxIterations > 0 && c.n > c.maxIterations {
+		return ErrBudgetExhausted
+	}
+	if c.n%cancelCheckInterval != 0 {
+		return nil
+	}
+
+	if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		return ErrBudgetExhausted
+	}
+	if c.ctx != nil {
+		return c.ctx.Err()
+	}
+	return nil
+}
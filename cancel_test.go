@@ -0,0 +1,131 @@
+package graph_test
+
+import (
+	"context"
+	"github.com/gonum/graph"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAStarCtx(t *testing.T) {
+	tg := graph.NewTileGraph(200, 200, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(199, 199)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if path, _, expanded, err := graph.AStarCtx(ctx, start, goal, tg, nil, nil); err != context.Canceled || path != nil || expanded > 1000 {
+		t.Errorf("Cancelled A* returned err %v after %d expansions", err, expanded)
+	}
+
+	path, cost, _, err := graph.AStarCtx(context.Background(), start, goal, tg, nil, nil)
+	if err != nil || cost != 398 || len(path) != 399 {
+		t.Errorf("A* with a live context returned err %v, cost %v", err, cost)
+	}
+}
+
+func TestDStarCtx(t *testing.T) {
+	tg := graph.NewTileGraph(200, 200, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(199, 199)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ds, err := graph.InitDStarCtx(ctx, start, goal, tg, nil, nil)
+	if err != context.Canceled {
+		t.Fatal("Cancelled D*-Lite initialization returned", err)
+	}
+
+	// Resuming finishes the interrupted search
+	if err := ds.UpdateCtx(context.Background(), nil, nil); err != nil {
+		t.Fatal("Resumed D*-Lite returned", err)
+	}
+	if plan := ds.PlanAhead(); len(plan) != 398 {
+		t.Errorf("Resumed D*-Lite planned %d moves, expected 398", len(plan))
+	}
+
+	world := graph.NewRevealingTileGraph(tg, start, 1)
+	if err := graph.DStarLiteCtx(ctx, start, goal, world, nil, nil); err != context.Canceled {
+		t.Error("Cancelled D*-Lite returned", err)
+	}
+}
+
+func TestDijkstraCtx(t *testing.T) {
+	tg := graph.NewTileGraph(200, 200, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(199, 199)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if path, _, expanded, err := graph.UniformCostSearchCtx(ctx, start, goal, tg, nil); err != context.Canceled || path != nil || expanded > 1000 {
+		t.Errorf("Cancelled uniform cost search returned err %v after %d expansions", err, expanded)
+	}
+	if path, cost, _, err := graph.UniformCostSearchCtx(context.Background(), start, goal, tg, nil); err != nil || cost != 398 || len(path) != 399 {
+		t.Errorf("Uniform cost search with a live context returned err %v, cost %v", err, cost)
+	}
+
+	tree, err := graph.DijkstraTreeCtx(ctx, start, tg, nil)
+	if err != context.Canceled || len(tree.Order) > 1000 {
+		t.Fatalf("Cancelled Dijkstra tree returned err %v after settling %d nodes", err, len(tree.Order))
+	}
+	full := graph.DijkstraTree(start, tg, nil)
+	for _, node := range tree.Order {
+		if tree.DistTo(node) != full.DistTo(node) {
+			t.Errorf("The cancelled tree has %v at %v, the full one at %v", node, tree.DistTo(node), full.DistTo(node))
+		}
+	}
+	if path, cost := tree.PathTo(goal); path != nil || !math.IsInf(cost, 1) {
+		t.Errorf("The cancelled tree has a path to the far corner costing %v", cost)
+	}
+}
+
+func TestAStarBudget(t *testing.T) {
+	tg := graph.NewTileGraph(200, 200, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(199, 199)
+	manhattan := func(a, b graph.Node) float64 {
+		ar, ac := tg.IDToCoords(a.ID())
+		br, bc := tg.IDToCoords(b.ID())
+		return math.Abs(float64(ar-br)) + math.Abs(float64(ac-bc))
+	}
+
+	path, _, partial, err := graph.AStarBudget(start, goal, tg, nil, manhattan, graph.SearchBudget{MaxExpansions: 100})
+	if err != graph.ErrBudgetExhausted || path != nil || partial == nil {
+		t.Fatalf("A* over budget returned path %v, err %v", path, err)
+	}
+	if partial.NodesExpanded > 100 || partial.Closest.ID() == start.ID() {
+		t.Errorf("Implausible partial search: %+v", partial)
+	}
+	if !graph.IsPath(partial.Path, tg) || partial.Path[0].ID() != start.ID() || partial.Path[len(partial.Path)-1].ID() != partial.Closest.ID() {
+		t.Error("Partial path doesn't lead from the start to the closest node:", partial.Path)
+	}
+	if partial.Cost != float64(len(partial.Path)-1) || partial.Bound > 398 {
+		t.Errorf("Partial cost %v or bound %v is wrong", partial.Cost, partial.Bound)
+	}
+
+	path, cost, partial, err := graph.AStarBudget(start, goal, tg, nil, manhattan, graph.SearchBudget{MaxExpansions: 1000000, MaxDuration: time.Minute})
+	if err != nil || partial != nil || cost != 398 || len(path) != 399 {
+		t.Errorf("A* within budget returned err %v, cost %v, %d nodes, partial %v", err, cost, len(path), partial)
+	}
+}
+
+func TestDStarBudget(t *testing.T) {
+	tg := graph.NewTileGraph(100, 100, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(99, 99)
+
+	ds, err := graph.InitDStarCtx(context.Background(), start, goal, tg, nil, nil, graph.WithDStarBudget(graph.SearchBudget{MaxExpansions: 500}))
+	if err != graph.ErrBudgetExhausted {
+		t.Fatal("D*-Lite over budget returned", err)
+	}
+	if _, err := ds.Step(); err != graph.ErrBudgetExhausted {
+		t.Error("Step before the plan reached the start returned", err)
+	}
+
+	rounds := 0
+	for err != nil {
+		if rounds++; rounds > 100 {
+			t.Fatal("Resumed D*-Lite search never finished")
+		}
+		err = ds.UpdateCtx(context.Background(), nil, nil)
+	}
+	if plan := ds.PlanAhead(); len(plan) != 198 {
+		t.Errorf("D*-Lite planned %d moves after %d rounds, expected 198", len(plan), rounds)
+	}
+}
@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Scores every node of graph by betweenness centrality[1]: for every pair of other nodes s and t, the share of the shortest paths from s to t that go through it, summed over
+// all the pairs. A node that every shortest path between two halves of the graph has to cross scores highly; a leaf scores 0. In an undirected graph each pair counts once,
+// and in a directed one each ordered pair does. Scores aren't normalized, as networkx's betweenness_centrality(normalized=False) doesn't; divide by (n-1)(n-2), halved if
+// undirected, to bring them into [0, 1].
+//
+// Cost is interpreted as in AStar, so by default a graph that's a Coster is weighted by its costs; pass UniformCost to count edges instead. Costs mustn't be negative, and
+// paths count as equally short only if their costs add up to exactly the same, which uniform or integer costs always do. Brandes' algorithm runs a Dijkstra search from every
+// node, taking O(nm log n) in all, or a breadth-first search, taking O(nm), if Cost is nil and graph isn't a Coster.
+//
+// [1] U. Brandes, "A faster algorithm for betweenness centrality", Journal of Mathematical Sociology 25 (2001)
+func BetweennessCentrality(graph Graph, Cost func(Node, Node) float64) map[int]float64 {
+	c := newCentralityGraph(graph, Cost)
+	n := len(c.nodes)
+	between := make([]float64, n)
+
+	// Reused by every search: the nodes in the order they were settled, each node's predecessors on its shortest paths, the number of those paths and its distance, and
+	// the dependency that accumulates on the way back
+	order := make([]int, 0, n)
+	preds := make([][]int, n)
+	sigma, dist, delta := make([]float64, n), make([]float64, n), make([]float64, n)
+	for s := range c.nodes {
+		order = order[:0]
+		for i := range preds {
+			preds[i], sigma[i], dist[i], delta[i] = preds[i][:0], 0, math.Inf(1), 0
+		}
+		sigma[s], dist[s] = 1, 0
+
+		if c.weighted {
+			queue := &centralityQueue{{s, 0}}
+			for queue.Len() > 0 {
+				// A node is only queued again with a shorter distance, so an entry further than the node's distance is one it's already been settled from
+				top := heap.Pop(queue).(centralityItem)
+				if top.dist > dist[top.node] {
+					continue
+				}
+				order = append(order, top.node)
+				for k, w := range c.succs[top.node] {
+					d := dist[top.node] + c.costs[top.node][k]
+					switch {
+					case d < dist[w]:
+						dist[w], sigma[w], preds[w] = d, sigma[top.node], append(preds[w][:0], top.node)
+						heap.Push(queue, centralityItem{w, d})
+					case d == dist[w]:
+						sigma[w] += sigma[top.node]
+						preds[w] = append(preds[w], top.node)
+					}
+				}
+			}
+		} else {
+			order = append(order, s)
+			for head := 0; head < len(order); head++ {
+				v := order[head]
+				for _, w := range c.succs[v] {
+					if math.IsInf(dist[w], 1) {
+						dist[w] = dist[v] + 1
+						order = append(order, w)
+					}
+					if dist[w] == dist[v]+1 {
+						sigma[w] += sigma[v]
+						preds[w] = append(preds[w], v)
+					}
+				}
+			}
+		}
+
+		// Back from the farthest node, each node passes its dependency on to its predecessors in proportion to the paths through them
+		for i := len(order) - 1; i >= 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				between[w] += delta[w]
+			}
+		}
+	}
+
+	scores := make(map[int]float64, n)
+	for i, node := range c.nodes {
+		if !graph.IsDirected() {
+			between[i] /= 2
+		}
+		scores[node.ID()] = between[i]
+	}
+	return scores
+}
+
+// Scores every node of graph by closeness centrality: how near it is, on average, to the nodes it can reach. It's the number of nodes reachable from it over the total cost of
+// the shortest paths to them, scaled by the share of the graph's other nodes it can reach at all, as Wasserman and Faust suggest and networkx does, so that a node close to a
+// handful of others in a small component doesn't outscore one a little further from everything. A node that can't reach anything scores 0. In a directed graph it's
+// distances from the node that count; score the graph with its edges reversed for distances to it, which is what networkx's closeness_centrality does for a digraph.
+//
+// Cost is interpreted as in BetweennessCentrality. It takes a Dijkstra search from every node.
+func ClosenessCentrality(graph Graph, Cost func(Node, Node) float64) map[int]float64 {
+	c := newCentralityGraph(graph, Cost)
+	n := len(c.nodes)
+	planner := NewPlanner(c, nil, nil)
+
+	scores := make(map[int]float64, n)
+	for _, node := range c.nodes {
+		reached, total := 0, 0.0
+		planner.Dijkstra(node, func(_, _ Node, cost float64) bool {
+			reached++
+			total += cost
+			return true
+		})
+		reached-- // node itself
+		if reached == 0 || total == 0 {
+			scores[node.ID()] = 0
+			continue
+		}
+		scores[node.ID()] = float64(reached) / total * float64(reached) / float64(n-1)
+	}
+	return scores
+}
+
+// A graph's nodes in order of ID, numbered by their place in that order, and each node's successors by number, with the costs of the edges to them unless it's unweighted.
+// It's also a SuccessorVisitor, so a Planner can search it with the same costs.
+type centralityGraph struct {
+	nodes    []Node
+	index    map[int]int
+	succs    [][]int
+	costs    [][]float64
+	weighted bool
+}
+
+func newCentralityGraph(graph Graph, Cost func(Node, Node) float64) *centralityGraph {
+	_, isCoster := graph.(Coster)
+	visit := successorVisitor(graph, Cost)
+	c := &centralityGraph{nodes: graph.NodeList(), weighted: Cost != nil || isCoster}
+	sort.Sort(byID(c.nodes))
+	c.index = make(map[int]int, len(c.nodes))
+	for i, node := range c.nodes {
+		c.index[node.ID()] = i
+	}
+
+	c.succs, c.costs = make([][]int, len(c.nodes)), make([][]float64, len(c.nodes))
+	for i, node := range c.nodes {
+		visit(node, func(succ Node, cost float64) bool {
+			if j, ok := c.index[succ.ID()]; ok && j != i {
+				c.succs[i] = append(c.succs[i], j)
+				c.costs[i] = append(c.costs[i], cost)
+			}
+			return true
+		})
+	}
+
+	return c
+}
+
+func (c *centralityGraph) Successors(node Node) []Node {
+	var succs []Node
+	c.VisitSuccessors(node, func(succ Node, _ float64) bool {
+		succs = append(succs, succ)
+		return true
+	})
+	return succs
+}
+
+func (c *centralityGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	i := c.index[node.ID()]
+	for k, j := range c.succs[i] {
+		if !fn(c.nodes[j], c.costs[i][k]) {
+			return
+		}
+	}
+}
+
+type centralityItem struct {
+	node int
+	dist float64
+}
+
+// A binary heap of nodes by distance, with stale entries left in to be skipped when popped
+type centralityQueue []centralityItem
+
+func (q centralityQueue) Len() int {
+	return len(q)
+}
+
+func (q centralityQueue) Less(i, j int) bool {
+	return q[i].dist < q[j].dist
+}
+
+func (q centralityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *centralityQueue) Push(x interface{}) {
+	*q = append(*q, x.(centralityItem))
+}
+
+func (q *centralityQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
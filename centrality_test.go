@@ -0,0 +1,149 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Betweenness by brute force: counts of shortest paths between every pair from the distances Floyd-Warshall finds, and the share of each pair's paths through each node
+func bruteBetweenness(g graph.Graph, cost func(graph.Node, graph.Node) float64) map[int]float64 {
+	nodes := g.NodeList()
+	n := len(nodes)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		for j := range dist[i] {
+			dist[i][j] = math.Inf(1)
+			if i == j {
+				dist[i][j] = 0
+			} else if g.IsSuccessor(nodes[i], nodes[j]) {
+				dist[i][j] = cost(nodes[i], nodes[j])
+			}
+		}
+	}
+	for k := range nodes {
+		for i := range nodes {
+			for j := range nodes {
+				dist[i][j] = math.Min(dist[i][j], dist[i][k]+dist[k][j])
+			}
+		}
+	}
+
+	// Paths from i to j: 1 if they're the same, and otherwise the paths to every predecessor of j that's on a shortest path, nearest j first
+	sigma := make([][]float64, n)
+	for i := range nodes {
+		sigma[i] = make([]float64, n)
+		order := make([]int, n)
+		for j := range order {
+			order[j] = j
+		}
+		for a := range order {
+			for b := a + 1; b < n; b++ {
+				if dist[i][order[b]] < dist[i][order[a]] {
+					order[a], order[b] = order[b], order[a]
+				}
+			}
+		}
+		for _, j := range order {
+			if j == i {
+				sigma[i][j] = 1
+				continue
+			}
+			for k := range nodes {
+				if k != j && g.IsSuccessor(nodes[k], nodes[j]) && dist[i][k]+cost(nodes[k], nodes[j]) == dist[i][j] {
+					sigma[i][j] += sigma[i][k]
+				}
+			}
+		}
+	}
+
+	scores := make(map[int]float64)
+	for v := range nodes {
+		for s := range nodes {
+			for t := range nodes {
+				if s != v && t != v && s != t && sigma[s][t] > 0 && dist[s][v]+dist[v][t] == dist[s][t] {
+					scores[nodes[v].ID()] += sigma[s][v] * sigma[v][t] / sigma[s][t]
+				}
+			}
+		}
+		if !g.IsDirected() {
+			scores[nodes[v].ID()] /= 2
+		}
+	}
+	return scores
+}
+
+func TestBetweennessCentrality(t *testing.T) {
+	path := graph.NewGonumGraph(false)
+	graph.PathGraph(path, 5, false)
+	for _, cost := range []func(graph.Node, graph.Node) float64{nil, graph.UniformCost} {
+		scores := graph.BetweennessCentrality(struct{ graph.Graph }{path}, cost)
+		for id, want := range []float64{0, 3, 4, 3, 0} {
+			if scores[id] != want {
+				t.Errorf("Expected %d on a path of 5 to score %v, got %v", id, want, scores[id])
+			}
+		}
+	}
+
+	// Against networkx's betweenness_centrality(G, normalized=True)
+	karate := graph.NewGonumGraph(false)
+	graph.KarateClubGraph(karate)
+	scores := graph.BetweennessCentrality(karate, nil)
+	for id, want := range map[int]float64{0: 0.43763528138528146, 33: 0.30407497594997596, 32: 0.145247113997114, 11: 0} {
+		if got := scores[id] / (33 * 32 / 2); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Expected karate club member %d to score %v, got %v", id, want, got)
+		}
+	}
+
+	src := rand.New(rand.NewSource(5))
+	for i := 0; i < 20; i++ {
+		directed := i%2 == 0
+		g := graph.NewGonumGraph(directed)
+		graph.GnpRandomGraph(g, 12, 0.3, directed, src)
+		for _, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(1+src.Intn(3)))
+		}
+		for name, cost := range map[string]func(graph.Node, graph.Node) float64{"weighted": g.Cost, "unweighted": graph.UniformCost} {
+			want := bruteBetweenness(g, cost)
+			for id, got := range graph.BetweennessCentrality(g, cost) {
+				if math.Abs(got-want[id]) > 1e-9 {
+					t.Errorf("%d, %s, directed %t: expected %d to score %v, got %v", i, name, directed, id, want[id], got)
+				}
+			}
+		}
+	}
+}
+
+func TestClosenessCentrality(t *testing.T) {
+	path := graph.NewGonumGraph(false)
+	graph.PathGraph(path, 5, false)
+	path.AddNode(graph.GonumNode(5), nil)
+	scores := graph.ClosenessCentrality(path, nil)
+	// Each end reaches 4 of the 5 other nodes at a total of 10, the middle at a total of 6
+	for id, want := range []float64{4.0 / 10 * 4 / 5, 4.0 / 7 * 4 / 5, 4.0 / 6 * 4 / 5, 4.0 / 7 * 4 / 5, 4.0 / 10 * 4 / 5, 0} {
+		if math.Abs(scores[id]-want) > 1e-12 {
+			t.Errorf("Expected %d to score %v, got %v", id, want, scores[id])
+		}
+	}
+
+	weighted := graph.NewGonumGraph(true)
+	graph.PathGraph(weighted, 3, true)
+	weighted.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}, 3)
+	scores, unweighted := graph.ClosenessCentrality(weighted, nil), graph.ClosenessCentrality(weighted, graph.UniformCost)
+	if scores[0] != 2.0/7 || unweighted[0] != 2.0/3 || scores[1] != 1.0/2 || scores[2] != 0 {
+		t.Errorf("Expected 2/7, 2/3, 1/2 and 0, got %v, %v, %v and %v", scores[0], unweighted[0], scores[1], scores[2])
+	}
+
+	// Against networkx's closeness_centrality
+	karate := graph.NewGonumGraph(false)
+	graph.KarateClubGraph(karate)
+	scores = graph.ClosenessCentrality(karate, nil)
+	for id, want := range map[int]float64{0: 0.5689655172413793, 33: 0.55, 2: 0.559322033898305} {
+		if math.Abs(scores[id]-want) > 1e-9 {
+			t.Errorf("Expected karate club member %d to score %v, got %v", id, want, scores[id])
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/graph"
+)
+
+// A command runs on the loaded graph with its own flags, writing its results to w
+type command func(g graph.Graph, args []string, w io.Writer) error
+
+var commands = map[string]command{
+	"stats":         stats,
+	"shortest-path": shortestPath,
+	"components":    components,
+	"pagerank":      pagerank,
+	"centrality":    centrality,
+	"mst":           mst,
+	"export":        export,
+}
+
+func stats(g graph.Graph, args []string, w io.Writer) error {
+	if err := flag.NewFlagSet("stats", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	nodes := g.NodeList()
+	edges, loops := 0, 0
+	for _, edge := range g.EdgeList() {
+		if g.IsDirected() || edge.Head().ID() <= edge.Tail().ID() {
+			edges++
+		}
+		if edge.Head().ID() == edge.Tail().ID() {
+			loops++
+		}
+	}
+	fmt.Fprintf(w, "nodes\t%d\nedges\t%d\ndirected\t%t\nself loops\t%d\n", len(nodes), edges, g.IsDirected(), loops)
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	minDegree, maxDegree, total := g.Degree(nodes[0]), 0, 0
+	for _, node := range nodes {
+		d := g.Degree(node)
+		total += d
+		if d < minDegree {
+			minDegree = d
+		}
+		if d > maxDegree {
+			maxDegree = d
+		}
+	}
+	_, degeneracy := graph.DegeneracyOrdering(g)
+	kind := "strongly connected components"
+	if !g.IsDirected() {
+		kind = "connected components"
+	}
+	fmt.Fprintf(w, "min degree\t%d\nmax degree\t%d\nmean degree\t%.4g\ndegeneracy\t%d\n%s\t%d\n", minDegree, maxDegree, float64(total)/float64(len(nodes)),
+		degeneracy, kind, len(graph.Tarjan(g)))
+	return nil
+}
+
+func shortestPath(g graph.Graph, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("shortest-path", flag.ContinueOnError)
+	from := flags.Int("from", 0, "the ID of the node to start from")
+	to := flags.Int("to", 0, "the ID of the node to go to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	start, goal := graph.GonumNode(*from), graph.GonumNode(*to)
+	for _, node := range []graph.Node{start, goal} {
+		if !g.NodeExists(node) {
+			return fmt.Errorf("node %d isn't in the graph", node.ID())
+		}
+	}
+
+	path, cost, expanded := graph.AStar(start, goal, g, nil, nil)
+	if path == nil {
+		return graph.ErrNoPath
+	}
+	for i, node := range path {
+		if i > 0 {
+			fmt.Fprint(w, " ")
+		}
+		fmt.Fprint(w, node.ID())
+	}
+	fmt.Fprintf(w, "\ncost\t%v\nexpanded\t%d\n", cost, expanded)
+	return nil
+}
+
+func components(g graph.Graph, args []string, w io.Writer) error {
+	if err := flag.NewFlagSet("components", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	sccs := graph.Tarjan(g)
+	for i := range sccs {
+		sccs[i] = sortedNodes(sccs[i])
+	}
+	sort.Sort(bySize(sccs))
+	for _, scc := range sccs {
+		for i, node := range scc {
+			if i > 0 {
+				fmt.Fprint(w, " ")
+			}
+			fmt.Fprint(w, node.ID())
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+func pagerank(g graph.Graph, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("pagerank", flag.ContinueOnError)
+	damping := flags.Float64("damping", 0.85, "the probability of following an edge rather than jumping")
+	tol := flags.Float64("tol", 1e-10, "stop once ranks change by no more than this in total")
+	iter := flags.Int("iter", 1000, "the most iterations to run")
+	top := flags.Int("top", 0, "only list this many of the highest ranked nodes, if positive")
+	seedList := flags.String("seeds", "", "personalize the ranks to these comma separated node IDs")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *damping < 0 || *damping >= 1 {
+		return fmt.Errorf("damping must be at least 0 and less than 1")
+	}
+	var seeds []graph.Node
+	if *seedList != "" {
+		for _, field := range strings.Split(*seedList, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return fmt.Errorf("bad seed %q", field)
+			}
+			if !g.NodeExists(graph.GonumNode(id)) {
+				return fmt.Errorf("node %d isn't in the graph", id)
+			}
+			seeds = append(seeds, graph.GonumNode(id))
+		}
+	}
+
+	ranks, _, _ := graph.PersonalizedPageRank(g, seeds, *damping, *tol, *iter)
+	writeRanks(w, g, ranks, *top)
+	return nil
+}
+
+func centrality(g graph.Graph, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("centrality", flag.ContinueOnError)
+	kind := flags.String("kind", "betweenness", "the centrality: betweenness or closeness")
+	unweighted := flags.Bool("unweighted", false, "count edges rather than adding up their costs")
+	top := flags.Int("top", 0, "only list this many of the highest scoring nodes, if positive")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var cost func(graph.Node, graph.Node) float64
+	if *unweighted {
+		cost = graph.UniformCost
+	}
+	switch *kind {
+	case "betweenness":
+		writeRanks(w, g, graph.BetweennessCentrality(g, cost), *top)
+	case "closeness":
+		writeRanks(w, g, graph.ClosenessCentrality(g, cost), *top)
+	default:
+		return fmt.Errorf("unknown centrality %q", *kind)
+	}
+	return nil
+}
+
+// Lists nodes with their scores, highest first and then by ID, or only the top few if top is positive
+func writeRanks(w io.Writer, g graph.Graph, ranks map[int]float64, top int) {
+	nodes := sortedNodes(g.NodeList())
+	sort.Stable(byRank{nodes, ranks})
+	if top > 0 && top < len(nodes) {
+		nodes = nodes[:top]
+	}
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%d\t%.6g\n", node.ID(), ranks[node.ID()])
+	}
+}
+
+func mst(g graph.Graph, args []string, w io.Writer) error {
+	if err := flag.NewFlagSet("mst", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	forest := graph.NewGonumGraph(false)
+	graph.Kruskal(forest, g, nil)
+	total, edges := 0.0, 0
+	for _, node := range sortedNodes(forest.NodeList()) {
+		for _, succ := range sortedNodes(forest.Successors(node)) {
+			if node.ID() < succ.ID() {
+				cost := forest.Cost(node, succ)
+				fmt.Fprintln(w, node.ID(), succ.ID(), strconv.FormatFloat(cost, 'g', -1, 64))
+				total += cost
+				edges++
+			}
+		}
+	}
+	fmt.Fprintf(w, "# %d edges, total cost %v\n", edges, total)
+	return nil
+}
+
+func export(g graph.Graph, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := flags.String("out", "", "write to this file instead of standard output")
+	as := flags.String("as", "edges", "the format to write: edges, dot, graphml or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if _, ok := marshalers[*as]; !ok && *as != "edges" {
+		return fmt.Errorf("can't export as %q", *as)
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := save(f, g, *as); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return save(w, g, *as)
+}
+
+// Sorts components largest first, then by their lowest ID
+type bySize [][]graph.Node
+
+func (c bySize) Len() int {
+	return len(c)
+}
+
+func (c bySize) Less(i, j int) bool {
+	return len(c[i]) > len(c[j]) || len(c[i]) == len(c[j]) && c[i][0].ID() < c[j][0].ID()
+}
+
+func (c bySize) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+// Sorts nodes highest ranked first
+type byRank struct {
+	nodes []graph.Node
+	ranks map[int]float64
+}
+
+func (r byRank) Len() int {
+	return len(r.nodes)
+}
+
+func (r byRank) Less(i, j int) bool {
+	return r.ranks[r.nodes[i].ID()] > r.ranks[r.nodes[j].ID()]
+}
+
+func (r byRank) Swap(i, j int) {
+	r.nodes[i], r.nodes[j] = r.nodes[j], r.nodes[i]
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding/dot"
+	"github.com/gonum/graph/encoding/graphjson"
+	"github.com/gonum/graph/encoding/graphml"
+)
+
+// The formats other than edge lists and tiles, which package encoding's subpackages read and write
+var (
+	unmarshalers = map[string]func([]byte) (graph.Graph, error){
+		"dot":     dot.Unmarshal,
+		"graphml": graphml.Unmarshal,
+		"json":    graphjson.Unmarshal,
+	}
+	marshalers = map[string]func(graph.Graph) ([]byte, error){
+		"dot":     dot.Marshal,
+		"graphml": graphml.Marshal,
+		"json":    graphjson.Marshal,
+	}
+)
+
+// Reads a graph in the named format
+func load(r io.Reader, format string, directed bool) (graph.Graph, error) {
+	switch format {
+	case "edges":
+		return readEdges(r, directed)
+	case "tiles":
+		template, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return graph.GenerateTileGraph(string(template))
+	default:
+		unmarshal, ok := unmarshalers[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q", format)
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(data)
+	}
+}
+
+func readEdges(r io.Reader, directed bool) (*graph.GonumGraph, error) {
+	g := graph.NewGonumGraph(directed)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected a node, or an edge and its cost", line)
+		}
+
+		var ids [2]int
+		ends := fields
+		if len(ends) > 2 {
+			ends = ends[:2]
+		}
+		for i, field := range ends {
+			id, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad node ID %q", line, field)
+			}
+			ids[i] = id
+			g.AddNode(graph.GonumNode(id), nil)
+		}
+		if len(fields) == 1 {
+			continue
+		}
+
+		edge := graph.GonumEdge{H: graph.GonumNode(ids[0]), T: graph.GonumNode(ids[1])}
+		g.AddEdge(edge)
+		if len(fields) == 3 {
+			cost, err := strconv.ParseFloat(fields[2], 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad cost %q", line, fields[2])
+			}
+			g.SetEdgeCost(edge, cost)
+		}
+	}
+
+	return g, scanner.Err()
+}
+
+// Writes g in the named format
+func save(w io.Writer, g graph.Graph, format string) error {
+	switch format {
+	case "edges":
+		return writeEdges(w, g)
+	default:
+		marshal, ok := marshalers[format]
+		if !ok {
+			return fmt.Errorf("can't export as %q", format)
+		}
+		data, err := marshal(g)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
+
+// Writes g as an edge list that readEdges reads back the same, each undirected edge once
+func writeEdges(w io.Writer, g graph.Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %d nodes, directed: %t\n", len(g.NodeList()), g.IsDirected())
+	cost := graph.UniformCost
+	if cgraph, ok := g.(graph.Coster); ok {
+		cost = cgraph.Cost
+	}
+
+	nodes := sortedNodes(g.NodeList())
+	for _, node := range nodes {
+		succs := sortedNodes(g.Successors(node))
+		isolated := len(succs) == 0 && len(g.Predecessors(node)) == 0
+		if isolated {
+			fmt.Fprintln(bw, node.ID())
+		}
+		for _, succ := range succs {
+			if g.IsDirected() || node.ID() <= succ.ID() {
+				fmt.Fprintln(bw, node.ID(), succ.ID(), strconv.FormatFloat(cost(node, succ), 'g', -1, 64))
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+func sortedNodes(nodes []graph.Node) []graph.Node {
+	nodes = append([]graph.Node(nil), nodes...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
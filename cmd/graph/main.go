@@ -0,0 +1,69 @@
+// Command graph loads a graph from a file and runs one of the package's algorithms on it, for looking over a graph without writing any Go.
+//
+//	graph [-in file] [-format edges|tiles|dot|graphml|json] [-undirected] command [flags]
+//
+// The commands are:
+//
+//	stats                            Counts nodes, edges and components, and summarizes degrees
+//	shortest-path -from id -to id    Finds a shortest path by A*, using the graph's heuristic if it has one
+//	components                       Lists the strongly connected components, largest first (connected components if undirected)
+//	pagerank [-damping d] [-top n]   Ranks nodes by PageRank, personalized to the nodes listed by -seeds if it's given
+//	centrality [-kind k] [-top n]    Ranks nodes by betweenness or closeness centrality, weighted by cost unless -unweighted
+//	mst                              Finds a minimum spanning forest by Kruskal's algorithm
+//	export [-out file] [-as format]  Writes the graph out as an edge list, or with -as as dot, graphml or json
+//
+// The graph is read from standard input unless -in is given. An edge list has an edge per line, as a head ID, a tail ID and optionally a cost, which is 1 if it's left out; a
+// line with a single ID adds a node with no edges, and blank lines and lines starting with # are ignored. An edge given more than once, either way round if undirected, has the cost on
+// its last line. A tile map is a grid of spaces, which are passable, and ▀, which
+// aren't, as GenerateTileGraph reads it. A DOT graph is read as package dot reads it, directed if it's a digraph whatever -undirected says, and GraphML and JSON graphs as
+// packages graphml and graphjson read them, directed as they say.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "graph:", err)
+		os.Exit(1)
+	}
+}
+
+// Runs the command line args, reading the graph from stdin unless told otherwise
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	in := flags.String("in", "", "read the graph from this file instead of standard input")
+	format := flags.String("format", "edges", "the graph's format: edges, tiles, dot, graphml or json")
+	undirected := flags.Bool("undirected", false, "treat an edge list as undirected")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("no command given; try stats, shortest-path, components, pagerank, centrality, mst or export")
+	}
+
+	command, ok := commands[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", flags.Arg(0))
+	}
+
+	r := stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	g, err := load(r, *format, !*undirected)
+	if err != nil {
+		return err
+	}
+
+	return command(g, flags.Args()[1:], stdout)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// 0 -> 1 -> 3 costs 2, 0 -> 2 -> 3 costs 3, 3 and 4 make a cycle and 5 is on its own. Undirected, 4 3 is the same edge as 3 4 and its cost of 1 wins
+const edges = `# a small graph
+0 1
+1 3
+0 2
+2 3 2
+3 4 0.5
+4 3
+5
+`
+
+func runOn(t *testing.T, input string, args ...string) string {
+	var out bytes.Buffer
+	if err := run(args, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestCommands(t *testing.T) {
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"shortest-path", "-from", "0", "-to", "4"}, "0 1 3 4\ncost\t2.5\n"},
+		{[]string{"components"}, "3 4\n0\n1\n2\n5\n"},
+		{[]string{"-undirected", "components"}, "0 1 2 3 4\n5\n"},
+		{[]string{"pagerank", "-seeds", "5"}, "5\t1\n"},
+		{[]string{"centrality", "-top", "2"}, "3\t3\n1\t2\n"},
+		{[]string{"centrality", "-kind", "closeness", "-unweighted", "-top", "1"}, "0\t"},
+		{[]string{"-undirected", "mst"}, "0 1 1\n0 2 1\n1 3 1\n3 4 1\n# 4 edges, total cost 4\n"},
+		{[]string{"export"}, "# 6 nodes, directed: true\n0 1 1\n0 2 1\n1 3 1\n2 3 2\n3 4 0.5\n4 3 1\n5\n"},
+	} {
+		got := runOn(t, edges, test.args...)
+		if !strings.HasPrefix(got, test.want) {
+			t.Errorf("%v: expected output starting %q, got %q", test.args, test.want, got)
+		}
+	}
+
+	stats := runOn(t, edges, "stats")
+	for _, want := range []string{"nodes\t6\n", "edges\t6\n", "directed\ttrue\n", "max degree\t4\n", "strongly connected components\t5\n"} {
+		if !strings.Contains(stats, want) {
+			t.Errorf("Expected stats to contain %q, got %q", want, stats)
+		}
+	}
+
+	ranks := runOn(t, edges, "pagerank", "-top", "2")
+	if lines := strings.Split(strings.TrimSpace(ranks), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], "3\t") && !strings.HasPrefix(lines[0], "4\t") {
+		t.Errorf("Expected the cycle at the top of two ranks, got %q", ranks)
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for _, flags := range [][]string{nil, {"-undirected"}} {
+		once := runOn(t, edges, append(flags, "export")...)
+		if twice := runOn(t, once, append(flags, "export")...); twice != once {
+			t.Errorf("%v: export of an export changed it from %q to %q", flags, once, twice)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	for _, format := range []string{"dot", "graphml", "json"} {
+		for _, flags := range [][]string{nil, {"-undirected"}} {
+			encoded := runOn(t, edges, append(flags, "export", "-as", format)...)
+			back := runOn(t, encoded, "-format", format, "export")
+			if want := runOn(t, edges, append(flags, "export")...); back != want {
+				t.Errorf("%v: exporting as %s and reading it back gave %q, expected %q", flags, format, back, want)
+			}
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"frobnicate"},
+		{"-format", "xml", "stats"},
+		{"-format", "dot", "stats"},
+		{"export", "-as", "xml"},
+		{"shortest-path", "-from", "0", "-to", "9"},
+		{"shortest-path", "-from", "5", "-to", "0"},
+		{"pagerank", "-damping", "1"},
+		{"centrality", "-kind", "eigenvector"},
+		{"pagerank", "-seeds", "1,x"},
+		{"pagerank", "-seeds", "9"},
+	} {
+		if err := run(args, strings.NewReader(edges), &bytes.Buffer{}); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+	if err := run([]string{"stats"}, strings.NewReader("0 x\n"), &bytes.Buffer{}); err == nil {
+		t.Error("Expected a bad edge list to be an error")
+	}
+}
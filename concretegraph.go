@@ -10,17 +10,118 @@ import (
 //
 // However, its generality is also its weakness (and partially a flaw in needing to satisfy MutableGraph). For most purposes, creating your own graph is probably better. For instance, see discrete.TileGraph for an example
 // of an immutable 2D grid of tiles that also implements the Graph interface, but would be more suitable if all you needed was a simple undirected 2D grid.
+//
+// NodeList, Successors, Predecessors and EdgeList come out in Go's randomized map order unless SetOrdering asks for a deterministic one.
 type GonumGraph struct {
 	successors   map[int]map[int]float64
 	predecessors map[int]map[int]float64
 	nodeMap      map[int]Node
 	directed     bool
+
+	// How many neighbours to make room for in each node's successor and predecessor maps, from the hints it was made with
+	degreeHint int
+
+	// Only kept for InsertionOrder: when each node, and each edge by its ends, was added
+	ordering Ordering
+	nodeSeq  map[int]int
+	edgeSeq  map[[2]int]int
+	nextSeq  int
+}
+
+// The order a GonumGraph lists nodes and edges in.
+type Ordering int
+
+const (
+	// Whatever order the graph's maps give, which differs from run to run. The default, and the cheapest.
+	MapOrder Ordering = iota
+	// The order nodes and edges were added in, edges listed by when they were added rather than when their ends were. A node added implicitly, as an edge's end, counts as
+	// added then.
+	InsertionOrder
+	// In order of ID: nodes by their own, successors and predecessors by theirs, and edges by their head's and then their tail's.
+	IDOrder
+)
+
+// Makes the graph list nodes and edges in a deterministic order, so algorithms that break ties by the order they see things in give the same results on every run, as
+// do tests that print what they find. Both deterministic orderings cost a sort of everything listed; InsertionOrder also keeps a sequence number for every node and edge.
+//
+// InsertionOrder can only see what's added once it's set, so anything already in the graph counts as having been added in order of ID, and edges after nodes.
+func (graph *GonumGraph) SetOrdering(ordering Ordering) {
+	graph.ordering = ordering
+	graph.nodeSeq, graph.edgeSeq, graph.nextSeq = nil, nil, 0
+	if ordering != InsertionOrder {
+		return
+	}
+
+	graph.nodeSeq = make(map[int]int, len(graph.nodeMap))
+	graph.edgeSeq = make(map[[2]int]int)
+	ids := make([]int, 0, len(graph.nodeMap))
+	for id := range graph.nodeMap {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		graph.noteNode(id)
+	}
+	for _, id := range ids {
+		succs := make([]int, 0, len(graph.successors[id]))
+		for succ := range graph.successors[id] {
+			succs = append(succs, succ)
+		}
+		sort.Ints(succs)
+		for _, succ := range succs {
+			graph.noteEdge(id, succ)
+		}
+	}
+}
+
+// The order the graph lists nodes and edges in.
+func (graph *GonumGraph) Ordering() Ordering {
+	return graph.ordering
+}
+
+// Records a node as added now, if the graph is keeping insertion order.
+func (graph *GonumGraph) noteNode(id int) {
+	if graph.ordering != InsertionOrder {
+		return
+	}
+	graph.nodeSeq[id] = graph.nextSeq
+	graph.nextSeq++
+}
+
+// Records an edge as added now, unless it already was, if the graph is keeping insertion order.
+func (graph *GonumGraph) noteEdge(head, tail int) {
+	if graph.ordering != InsertionOrder {
+		return
+	}
+	if _, ok := graph.edgeSeq[[2]int{head, tail}]; ok {
+		return
+	}
+	graph.edgeSeq[[2]int{head, tail}] = graph.nextSeq
+	graph.nextSeq++
+}
+
+// Sorts ids in the graph's order, seq giving each one's insertion sequence number.
+func (graph *GonumGraph) order(ids []int, seq func(id int) int) {
+	switch graph.ordering {
+	case IDOrder:
+		sort.Ints(ids)
+	case InsertionOrder:
+		keys := make([]int, len(ids))
+		for i, id := range ids {
+			keys[i] = seq(id)
+		}
+		sort.Sort(byKey{len(ids), func(i int) float64 { return float64(keys[i]) }, func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}})
+	}
 }
 
 func NewGonumGraph(directed bool) *GonumGraph {
 	return &GonumGraph{
 		successors:   make(map[int]map[int]float64),
 		predecessors: make(map[int]map[int]float64),
+		nodeMap:      make(map[int]Node),
 		directed:     directed,
 	}
 }
@@ -29,10 +130,34 @@ func NewPreAllocatedGonumGraph(directed bool, numVertices int) *GonumGraph {
 	return &GonumGraph{
 		successors:   make(map[int]map[int]float64, numVertices),
 		predecessors: make(map[int]map[int]float64, numVertices),
+		nodeMap:      make(map[int]Node, numVertices),
 		directed:     directed,
 	}
 }
 
+// Makes an empty graph with room for about nHint nodes and mHint edges, each undirected edge counting once. Hints of 0 or less make no room at all.
+func newHintedGraph(directed bool, nHint, mHint int) *GonumGraph {
+	if nHint < 0 {
+		nHint = 0
+	}
+	graph := NewPreAllocatedGonumGraph(directed, nHint)
+	if nHint > 0 && mHint > 0 {
+		if !directed {
+			mHint *= 2
+		}
+		graph.degreeHint = (mHint + nHint - 1) / nHint
+	}
+	return graph
+}
+
+// A new map for a node's successors or predecessors, with room for n of them or for as many as the graph's hints expect, whichever is more.
+func (graph *GonumGraph) neighborMap(n int) map[int]float64 {
+	if n < graph.degreeHint {
+		n = graph.degreeHint
+	}
+	return make(map[int]float64, n)
+}
+
 /* Mutable Graph implementation */
 
 func (graph *GonumGraph) NewNode(successors []Node) (node Node) {
@@ -63,12 +188,13 @@ func (graph *GonumGraph) AddNode(node Node, successors []Node) {
 	}
 
 	graph.nodeMap[id] = node
+	graph.noteNode(id)
 
-	graph.successors[id] = make(map[int]float64, len(successors))
+	graph.successors[id] = graph.neighborMap(len(successors))
 	if !graph.directed {
-		graph.predecessors[id] = make(map[int]float64, len(successors))
+		graph.predecessors[id] = graph.neighborMap(len(successors))
 	} else {
-		graph.predecessors[id] = make(map[int]float64)
+		graph.predecessors[id] = graph.neighborMap(0)
 	}
 	for _, successor := range successors {
 		succ := successor.ID()
@@ -77,16 +203,19 @@ func (graph *GonumGraph) AddNode(node Node, successors []Node) {
 		// Always add the reciprocal node to the graph
 		if _, ok := graph.successors[succ]; !ok {
 			graph.nodeMap[succ] = successor
-			graph.predecessors[succ] = make(map[int]float64)
-			graph.successors[succ] = make(map[int]float64)
+			graph.noteNode(succ)
+			graph.predecessors[succ] = graph.neighborMap(0)
+			graph.successors[succ] = graph.neighborMap(0)
 		}
 
 		graph.predecessors[succ][id] = 1.0
+		graph.noteEdge(id, succ)
 
 		// But only add the reciprocal edge if we're undirected
 		if !graph.directed {
 			graph.successors[succ][id] = 1.0
 			graph.predecessors[id][succ] = 1.0
+			graph.noteEdge(succ, id)
 		}
 	}
 }
@@ -100,16 +229,19 @@ func (graph *GonumGraph) AddEdge(e Edge) {
 
 	if _, ok := graph.successors[successor]; !ok {
 		graph.nodeMap[successor] = e.Tail()
-		graph.successors[successor] = make(map[int]float64)
-		graph.predecessors[successor] = make(map[int]float64)
+		graph.noteNode(successor)
+		graph.successors[successor] = graph.neighborMap(0)
+		graph.predecessors[successor] = graph.neighborMap(0)
 	}
 
 	graph.successors[id][successor] = 1.0
 	graph.predecessors[successor][id] = 1.0
+	graph.noteEdge(id, successor)
 
 	if !graph.directed {
 		graph.successors[successor][id] = 1.0
 		graph.predecessors[id][successor] = 1.0
+		graph.noteEdge(successor, id)
 	}
 }
 
@@ -134,18 +266,23 @@ func (graph *GonumGraph) SetEdgeCost(e Edge, cost float64) {
 
 func (graph *GonumGraph) RemoveNode(node Node) {
 	id := node.ID()
-	if _, ok := graph.successors[id]; ok {
+	if _, ok := graph.successors[id]; !ok {
 		return
 	}
 	delete(graph.nodeMap, id)
+	if graph.ordering == InsertionOrder {
+		delete(graph.nodeSeq, id)
+	}
 
 	for succ, _ := range graph.successors[id] {
 		delete(graph.predecessors[succ], id)
+		graph.forgetEdge(id, succ)
 	}
 	delete(graph.successors, id)
 
 	for pred, _ := range graph.predecessors[id] {
 		delete(graph.successors[pred], id)
+		graph.forgetEdge(pred, id)
 	}
 	delete(graph.predecessors, id)
 
@@ -162,9 +299,17 @@ func (graph *GonumGraph) RemoveEdge(e Edge) {
 
 	delete(graph.successors[id], succ)
 	delete(graph.predecessors[succ], id)
+	graph.forgetEdge(id, succ)
 	if !graph.directed {
 		delete(graph.predecessors[id], succ)
 		delete(graph.successors[succ], id)
+		graph.forgetEdge(succ, id)
+	}
+}
+
+func (graph *GonumGraph) forgetEdge(head, tail int) {
+	if graph.ordering == InsertionOrder {
+		delete(graph.edgeSeq, [2]int{head, tail})
 	}
 }
 
@@ -175,6 +320,7 @@ func (graph *GonumGraph) EmptyGraph() {
 	graph.successors = make(map[int]map[int]float64)
 	graph.predecessors = make(map[int]map[int]float64)
 	graph.nodeMap = make(map[int]Node)
+	graph.SetOrdering(graph.ordering)
 }
 
 func (graph *GonumGraph) SetDirected(directed bool) {
@@ -192,9 +338,24 @@ func (graph *GonumGraph) Successors(node Node) []Node {
 		return nil
 	}
 
-	successors := make([]Node, len(graph.successors[id]))
+	if graph.ordering == MapOrder {
+		successors := make([]Node, 0, len(graph.successors[id]))
+		for succ, _ := range graph.successors[id] {
+			successors = append(successors, graph.nodeMap[succ])
+		}
+
+		return successors
+	}
+
+	ids := make([]int, 0, len(graph.successors[id]))
 	for succ, _ := range graph.successors[id] {
-		successors = append(successors, graph.nodeMap[succ])
+		ids = append(ids, succ)
+	}
+	graph.order(ids, func(succ int) int { return graph.edgeSeq[[2]int{id, succ}] })
+
+	successors := make([]Node, len(ids))
+	for i, succ := range ids {
+		successors[i] = graph.nodeMap[succ]
 	}
 
 	return successors
@@ -218,9 +379,24 @@ func (graph *GonumGraph) Predecessors(node Node) []Node {
 		return nil
 	}
 
-	predecessors := make([]Node, len(graph.predecessors[id]))
+	if graph.ordering == MapOrder {
+		predecessors := make([]Node, 0, len(graph.predecessors[id]))
+		for pred, _ := range graph.predecessors[id] {
+			predecessors = append(predecessors, graph.nodeMap[pred])
+		}
+
+		return predecessors
+	}
+
+	ids := make([]int, 0, len(graph.predecessors[id]))
 	for pred, _ := range graph.predecessors[id] {
-		predecessors = append(predecessors, graph.nodeMap[pred])
+		ids = append(ids, pred)
+	}
+	graph.order(ids, func(pred int) int { return graph.edgeSeq[[2]int{pred, id}] })
+
+	predecessors := make([]Node, len(ids))
+	for i, pred := range ids {
+		predecessors[i] = graph.nodeMap[pred]
 	}
 
 	return predecessors
@@ -245,7 +421,7 @@ func (graph *GonumGraph) IsAdjacent(node, neigh Node) bool {
 		return false
 	}
 
-	_, succ := graph.predecessors[id][neighbor]
+	_, succ := graph.successors[id][neighbor]
 	_, pred := graph.predecessors[id][neighbor]
 
 	return succ || pred
@@ -268,9 +444,30 @@ func (graph *GonumGraph) Degree(node Node) int {
 
 func (graph *GonumGraph) EdgeList() []Edge {
 	eList := make([]Edge, 0, len(graph.successors))
-	for id, succMap := range graph.successors {
-		for succ, _ := range succMap {
-			eList = append(eList, GonumEdge{graph.nodeMap[id], graph.nodeMap[succ]})
+	switch graph.ordering {
+	case IDOrder:
+		for _, node := range graph.NodeList() {
+			for _, succ := range graph.Successors(node) {
+				eList = append(eList, GonumEdge{node, succ})
+			}
+		}
+	case InsertionOrder:
+		keys := make([]int, 0, len(graph.edgeSeq))
+		for id, succMap := range graph.successors {
+			for succ, _ := range succMap {
+				eList = append(eList, GonumEdge{graph.nodeMap[id], graph.nodeMap[succ]})
+				keys = append(keys, graph.edgeSeq[[2]int{id, succ}])
+			}
+		}
+		sort.Sort(byKey{len(eList), func(i int) float64 { return float64(keys[i]) }, func(i, j int) {
+			eList[i], eList[j] = eList[j], eList[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}})
+	default:
+		for id, succMap := range graph.successors {
+			for succ, _ := range succMap {
+				eList = append(eList, GonumEdge{graph.nodeMap[id], graph.nodeMap[succ]})
+			}
 		}
 	}
 
@@ -278,9 +475,23 @@ func (graph *GonumGraph) EdgeList() []Edge {
 }
 
 func (graph *GonumGraph) NodeList() []Node {
-	nodes := make([]Node, 0, len(graph.successors))
-	for _, node := range graph.nodeMap {
-		nodes = append(nodes, node)
+	if graph.ordering == MapOrder {
+		nodes := make([]Node, 0, len(graph.successors))
+		for _, node := range graph.nodeMap {
+			nodes = append(nodes, node)
+		}
+
+		return nodes
+	}
+
+	ids := make([]int, 0, len(graph.nodeMap))
+	for id := range graph.nodeMap {
+		ids = append(ids, id)
+	}
+	graph.order(ids, func(id int) int { return graph.nodeSeq[id] })
+	nodes := make([]Node, len(ids))
+	for i, id := range ids {
+		nodes[i] = graph.nodeMap[id]
 	}
 
 	return nodes
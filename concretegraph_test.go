@@ -0,0 +1,114 @@
+package graph_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func nodeIDs(nodes []graph.Node) []int {
+	ids := make([]int, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID()
+	}
+	return ids
+}
+
+func edgeIDs(edges []graph.Edge) [][2]int {
+	ids := make([][2]int, len(edges))
+	for i, edge := range edges {
+		ids[i] = [2]int{edge.Head().ID(), edge.Tail().ID()}
+	}
+	return ids
+}
+
+func TestGonumGraphOrdering(t *testing.T) {
+	build := func(ordering graph.Ordering) *graph.GonumGraph {
+		g := graph.NewGonumGraph(true)
+		g.SetOrdering(ordering)
+		g.AddNode(graph.GonumNode(5), nil)
+		g.AddNode(graph.GonumNode(2), []graph.Node{graph.GonumNode(9), graph.GonumNode(5)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(5), T: graph.GonumNode(1)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(1)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(9), T: graph.GonumNode(2)})
+		return g
+	}
+
+	g := build(graph.InsertionOrder)
+	if ids := nodeIDs(g.NodeList()); !reflect.DeepEqual(ids, []int{5, 2, 9, 1}) {
+		t.Errorf("Expected nodes in insertion order, got %v", ids)
+	}
+	if ids := nodeIDs(g.Successors(graph.GonumNode(2))); !reflect.DeepEqual(ids, []int{9, 5, 1}) {
+		t.Errorf("Expected successors in insertion order, got %v", ids)
+	}
+	if ids := nodeIDs(g.Predecessors(graph.GonumNode(1))); !reflect.DeepEqual(ids, []int{5, 2}) {
+		t.Errorf("Expected predecessors in insertion order, got %v", ids)
+	}
+	if ids := edgeIDs(g.EdgeList()); !reflect.DeepEqual(ids, [][2]int{{2, 9}, {2, 5}, {5, 1}, {2, 1}, {9, 2}}) {
+		t.Errorf("Expected edges in insertion order, got %v", ids)
+	}
+
+	// Removing and re-adding moves a node or edge to the end
+	g.RemoveEdge(graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(9)})
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(9)})
+	g.RemoveNode(graph.GonumNode(5))
+	g.AddNode(graph.GonumNode(5), nil)
+	if ids := nodeIDs(g.Successors(graph.GonumNode(2))); !reflect.DeepEqual(ids, []int{1, 9}) {
+		t.Errorf("Expected a re-added edge to come last, got %v", ids)
+	}
+	if ids := nodeIDs(g.NodeList()); !reflect.DeepEqual(ids, []int{2, 9, 1, 5}) {
+		t.Errorf("Expected a re-added node to come last, got %v", ids)
+	}
+
+	g = build(graph.IDOrder)
+	if ids := nodeIDs(g.NodeList()); !reflect.DeepEqual(ids, []int{1, 2, 5, 9}) {
+		t.Errorf("Expected nodes by ID, got %v", ids)
+	}
+	if ids := edgeIDs(g.EdgeList()); !reflect.DeepEqual(ids, [][2]int{{2, 1}, {2, 5}, {2, 9}, {5, 1}, {9, 2}}) {
+		t.Errorf("Expected edges by ID, got %v", ids)
+	}
+
+	// Switching to insertion order after the fact orders what's there by ID
+	g = build(graph.MapOrder)
+	g.SetOrdering(graph.InsertionOrder)
+	if ids := nodeIDs(g.NodeList()); !reflect.DeepEqual(ids, []int{1, 2, 5, 9}) {
+		t.Errorf("Expected existing nodes by ID, got %v", ids)
+	}
+	g.EmptyGraph()
+	g.AddNode(graph.GonumNode(3), nil)
+	g.AddNode(graph.GonumNode(0), nil)
+	if ids := nodeIDs(g.NodeList()); g.Ordering() != graph.InsertionOrder || !reflect.DeepEqual(ids, []int{3, 0}) {
+		t.Errorf("Expected the ordering to survive emptying, got %v", ids)
+	}
+}
+
+// With a deterministic ordering, algorithms that break ties by the order they see things in give the same answer on every run
+func TestGonumGraphOrderingDeterminism(t *testing.T) {
+	for _, ordering := range []graph.Ordering{graph.InsertionOrder, graph.IDOrder} {
+		var first []interface{}
+		for run := 0; run < 10; run++ {
+			g := graph.NewGonumGraph(false)
+			g.SetOrdering(ordering)
+			src := rand.New(rand.NewSource(4))
+			for i := 0; i < 30; i++ {
+				g.AddNode(graph.GonumNode(i), nil)
+			}
+			for i := 0; i < 60; i++ {
+				g.AddEdge(graph.GonumEdge{H: graph.GonumNode(src.Intn(30)), T: graph.GonumNode(src.Intn(30))})
+			}
+
+			path, _, _ := graph.AStar(graph.GonumNode(0), graph.GonumNode(29), g, nil, nil)
+			mst := graph.NewGonumGraph(false)
+			mst.SetOrdering(graph.IDOrder)
+			graph.Kruskal(mst, g, nil)
+			results := []interface{}{nodeIDs(path), graph.Tarjan(g), edgeIDs(mst.EdgeList()), nodeIDs(graph.DepthFirstSearch(graph.GonumNode(0), graph.GonumNode(29), g))}
+			if run == 0 {
+				first = results
+			} else if !reflect.DeepEqual(results, first) {
+				t.Fatalf("Ordering %v: run %d gave %v, the first gave %v", ordering, run, results, first)
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Finds the connected components of graph. If strong is false, or graph is undirected, they're the components of the undirected graph underlying it (for a directed graph,
+// its weakly connected components): the sets of nodes joined by edges, whichever way round they go. If strong is true, they're the strongly connected components, as Tarjan
+// finds them: the sets in which every node can reach every other along the edges' directions.
+//
+// Unlike Tarjan's, the components are listed in order of their lowest ID, and each lists its nodes in order of ID, so the result is the same however the graph stores them.
+// Runs in O(n + m), plus the sorting.
+func ConnectedComponents(graph Graph, strong bool) [][]Node {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+
+	componentOf := make(map[int]int, len(nodes))
+	if strong {
+		for i, scc := range Tarjan(graph) {
+			for _, node := range scc {
+				componentOf[node.ID()] = i
+			}
+		}
+	} else {
+		for _, root := range nodes {
+			if _, seen := componentOf[root.ID()]; seen {
+				continue
+			}
+			i := root.ID()
+			componentOf[i] = i
+			stack := []Node{root}
+			for len(stack) != 0 {
+				node := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				neighbors, _ := treeNeighbors(graph, node)
+				for _, next := range neighbors {
+					if _, seen := componentOf[next.ID()]; !seen {
+						componentOf[next.ID()] = i
+						stack = append(stack, next)
+					}
+				}
+			}
+		}
+	}
+
+	// Going through the nodes in order of ID numbers the components in order of their lowest, and fills each in order
+	var components [][]Node
+	position := make(map[int]int)
+	for _, node := range nodes {
+		c := componentOf[node.ID()]
+		i, ok := position[c]
+		if !ok {
+			i = len(components)
+			position[c] = i
+			components = append(components, nil)
+		}
+		components[i] = append(components[i], node)
+	}
+
+	return components
+}
+
+// Finds the bridges of the undirected graph underlying graph: the edges whose removal disconnects their component, which in a network are the links with no backup. They're
+// the blocks of BiconnectedComponents with only two nodes. Each is returned the way round graph has it, or, if it has both, from the lower ID to the higher; the bridges are in
+// order of their ends' IDs.
+func Bridges(graph Graph) []Edge {
+	blocks, _ := BiconnectedComponents(graph)
+	var bridges []Edge
+	for _, block := range blocks {
+		if len(block) != 2 {
+			continue
+		}
+		head, tail := block[0], block[1]
+		if !graph.IsSuccessor(head, tail) {
+			head, tail = tail, head
+		}
+		bridges = append(bridges, GonumEdge{H: head, T: tail})
+	}
+	sort.Sort(byEnds(bridges))
+
+	return bridges
+}
+
+// Finds the articulation points (cut vertices) of the undirected graph underlying graph: the nodes whose removal disconnects their component, which in a network are the single
+// points of failure. They're in order of ID. To see which parts of the graph each one holds together, use BiconnectedComponents or NewBlockCutTree.
+func ArticulationPoints(graph Graph) []Node {
+	_, articulationPoints := BiconnectedComponents(graph)
+	return articulationPoints
+}
+
+type byEnds []Edge
+
+func (b byEnds) Len() int {
+	return len(b)
+}
+
+func (b byEnds) Less(i, j int) bool {
+	if b[i].Head().ID() != b[j].Head().ID() {
+		return b[i].Head().ID() < b[j].Head().ID()
+	}
+	return b[i].Tail().ID() < b[j].Tail().ID()
+}
+
+func (b byEnds) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
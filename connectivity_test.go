@@ -0,0 +1,101 @@
+package graph_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func componentIDs(components [][]graph.Node) [][]int {
+	ids := make([][]int, len(components))
+	for i, component := range components {
+		ids[i] = nodeIDs(component)
+	}
+	return ids
+}
+
+func TestConnectedComponents(t *testing.T) {
+	// 0 -> 1 -> 2 -> 0 is a cycle, 3 hangs off it, 4 <-> 5 is a pair on their own, and 6 is alone
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 7; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][2]int{{1, 2}, {2, 0}, {0, 1}, {3, 1}, {5, 4}, {4, 5}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	if ids := componentIDs(graph.ConnectedComponents(g, false)); !reflect.DeepEqual(ids, [][]int{{0, 1, 2, 3}, {4, 5}, {6}}) {
+		t.Errorf("Expected the weak components [[0 1 2 3] [4 5] [6]], got %v", ids)
+	}
+	if ids := componentIDs(graph.ConnectedComponents(g, true)); !reflect.DeepEqual(ids, [][]int{{0, 1, 2}, {3}, {4, 5}, {6}}) {
+		t.Errorf("Expected the strong components [[0 1 2] [3] [4 5] [6]], got %v", ids)
+	}
+}
+
+func TestBridgesAndArticulationPoints(t *testing.T) {
+	// Two triangles sharing node 2, a bridge from 4 to 5, a bridge from 6 to 5, and an isolated node
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 8; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 2}, {4, 5}, {6, 5}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	var bridges [][2]int
+	for _, edge := range graph.Bridges(g) {
+		bridges = append(bridges, [2]int{edge.Head().ID(), edge.Tail().ID()})
+	}
+	if !reflect.DeepEqual(bridges, [][2]int{{4, 5}, {6, 5}}) {
+		t.Errorf("Expected the bridges [[4 5] [6 5]], got %v", bridges)
+	}
+	if ids := nodeIDs(graph.ArticulationPoints(g)); !reflect.DeepEqual(ids, []int{2, 4, 5}) {
+		t.Errorf("Expected the articulation points [2 4 5], got %v", ids)
+	}
+}
+
+func TestBridgesRandom(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		g := graph.NewGonumGraph(false)
+		graph.GnpRandomGraph(g, 40, 0.06, false, rand.New(rand.NewSource(seed)))
+		components := len(graph.ConnectedComponents(g, false))
+
+		isBridge := make(map[[2]int]bool)
+		for _, edge := range graph.Bridges(g) {
+			isBridge[[2]int{edge.Head().ID(), edge.Tail().ID()}] = true
+		}
+		for _, edge := range g.EdgeList() {
+			u, v := edge.Head(), edge.Tail()
+			if u.ID() > v.ID() {
+				continue
+			}
+			cost := g.Cost(u, v)
+			g.RemoveEdge(edge)
+			split := len(graph.ConnectedComponents(g, false)) > components
+			g.AddEdge(edge)
+			g.SetEdgeCost(edge, cost)
+			if split != isBridge[[2]int{u.ID(), v.ID()}] {
+				t.Errorf("seed %d: removing %d-%d splits the graph: %t, but Bridges says %t", seed, u.ID(), v.ID(), split, !split)
+			}
+		}
+
+		isCut := make(map[int]bool)
+		for _, node := range graph.ArticulationPoints(g) {
+			isCut[node.ID()] = true
+		}
+		for _, node := range g.NodeList() {
+			split := false
+			neighbors := g.Successors(node)
+			for _, other := range neighbors {
+				if !connectedWithout(g, node, neighbors[0], other) {
+					split = true
+				}
+			}
+			if split != isCut[node.ID()] {
+				t.Errorf("seed %d: removing %d splits the graph: %t, but ArticulationPoints says %t", seed, node.ID(), split, !split)
+			}
+		}
+	}
+}
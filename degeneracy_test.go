@@ -0,0 +1,126 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// The core numbers of g, by finding the k-core for each k by peeling off every node of degree below k until there are none
+func bruteCoreNumbers(g graph.Graph) map[int]int {
+	adj := make(map[int]map[int]bool)
+	for _, node := range g.NodeList() {
+		adj[node.ID()] = make(map[int]bool)
+	}
+	for _, edge := range g.EdgeList() {
+		if h, t := edge.Head().ID(), edge.Tail().ID(); h != t {
+			adj[h][t], adj[t][h] = true, true
+		}
+	}
+
+	cores := make(map[int]int)
+	for k := 1; ; k++ {
+		in := make(map[int]bool)
+		for id := range adj {
+			in[id] = true
+		}
+		for changed := true; changed; {
+			changed = false
+			for id := range in {
+				degree := 0
+				for other := range adj[id] {
+					if in[other] {
+						degree++
+					}
+				}
+				if degree < k {
+					delete(in, id)
+					changed = true
+				}
+			}
+		}
+		if len(in) == 0 {
+			return cores
+		}
+		for id := range in {
+			cores[id] = k
+		}
+	}
+}
+
+func TestDegeneracyOrdering(t *testing.T) {
+	complete := graph.NewGonumGraph(false)
+	for i := 0; i < 5; i++ {
+		complete.AddNode(graph.GonumNode(i), nil)
+		for j := 0; j < i; j++ {
+			complete.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(j)})
+		}
+	}
+	if _, d := graph.DegeneracyOrdering(complete); d != 4 {
+		t.Errorf("Expected K5 to have degeneracy 4, got %d", d)
+	}
+
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 50; trial++ {
+		directed := trial%2 == 0
+		g := graph.NewGonumGraph(directed)
+		graph.GnpRandomGraph(g, 1+src.Intn(20), 0.05+0.4*src.Float64(), directed, src)
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(0)})
+
+		order, d := graph.DegeneracyOrdering(g)
+		expected := bruteCoreNumbers(g)
+		cores := graph.CoreNumbers(g)
+		max := 0
+		for _, node := range g.NodeList() {
+			if cores[node.ID()] != expected[node.ID()] {
+				t.Errorf("Trial %d: expected node %d to have core number %d, got %d", trial, node.ID(), expected[node.ID()], cores[node.ID()])
+			}
+			if expected[node.ID()] > max {
+				max = expected[node.ID()]
+			}
+		}
+		if d != max {
+			t.Errorf("Trial %d: expected degeneracy %d, got %d", trial, max, d)
+		}
+
+		if len(order) != len(g.NodeList()) {
+			t.Fatalf("Trial %d: expected %d nodes in order, got %d", trial, len(g.NodeList()), len(order))
+		}
+		position := make(map[int]int)
+		for i, node := range order {
+			position[node.ID()] = i
+		}
+		for _, node := range order {
+			later := make(map[int]bool)
+			for _, list := range [][]graph.Node{g.Successors(node), g.Predecessors(node)} {
+				for _, other := range list {
+					if position[other.ID()] > position[node.ID()] {
+						later[other.ID()] = true
+					}
+				}
+			}
+			if len(later) > d {
+				t.Errorf("Trial %d: node %d has %d neighbors after it, more than the degeneracy %d", trial, node.ID(), len(later), d)
+			}
+		}
+
+		k := 1 + src.Intn(max+1)
+		core := graph.KCore(g, k)
+		count := 0
+		for _, node := range g.NodeList() {
+			if expected[node.ID()] >= k {
+				count++
+			}
+		}
+		if len(core) != count {
+			t.Errorf("Trial %d: expected %d nodes in the %d-core, got %d", trial, count, k, len(core))
+		}
+		for i, node := range core {
+			if expected[node.ID()] < k || i > 0 && core[i-1].ID() >= node.ID() {
+				t.Errorf("Trial %d: unexpected %d-core %v", trial, k, core)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package graph
+
+import (
+	"runtime"
+)
+
+type relaxRequest struct {
+	node, pred Node
+	dist       float64
+}
+
+// Delta-stepping[1] is a parallel single source shortest path algorithm. It returns the same as Dijkstra, but instead of settling one node at a time it settles a whole bucket of
+// nodes, those whose tentative distance is within the same band of width delta, scanning their edges on up to workers goroutines. Edges costing at most delta ("light" edges) can
+// lead back into the band being processed, so they're relaxed repeatedly until the band is settled, while "heavy" edges are only relaxed once, after the band is settled.
+//
+// The choice of delta trades parallelism against wasted work: a wide band gives the workers more to do at once, but nodes may have to be rescanned as their distance improves
+// within it (delta = infinity is Bellman-Ford); a narrow band does little redundant work but has little to parallelize (with no ties, delta -> 0 is Dijkstra's algorithm).
+// Around the average edge cost is a good start, and that's what's used if delta <= 0. There's a bucket for every band up to the longest distance, so delta shouldn't be tiny either. If workers <= 0, GOMAXPROCS workers are used.
+//
+// Costs must be non-negative. The graph's Successors and the cost function must be safe to call concurrently, which holds for all read-only graphs in this package.
+//
+// [1] U. Meyer and P. Sanders, "Delta-stepping: a parallelizable shortest path algorithm", Journal of Algorithms 49 (2003)
+func DeltaStepping(source Node, graph Graph, Cost func(Node, Node) float64, delta float64, workers int) (paths map[int][]Node, costs map[int]float64) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if delta <= 0 {
+		delta = meanEdgeCost(graph, Cost)
+	}
+
+	costs = map[int]float64{source.ID(): 0}
+	predecessor := make(map[int]Node)
+	nodes := map[int]Node{source.ID(): source}
+	buckets := [][]Node{{source}}
+	bucketOf := func(dist float64) int {
+		return int(dist / delta)
+	}
+
+	// Runs sequentially, so the parallel scans can read costs without locking
+	relax := func(requests []relaxRequest) {
+		for _, req := range requests {
+			if old, ok := costs[req.node.ID()]; ok && req.dist >= old {
+				continue
+			}
+			costs[req.node.ID()] = req.dist
+			predecessor[req.node.ID()] = req.pred
+			nodes[req.node.ID()] = req.node
+
+			b := bucketOf(req.dist)
+			for b >= len(buckets) {
+				buckets = append(buckets, nil)
+			}
+			buckets[b] = append(buckets[b], req.node) // A node that moves buckets leaves a stale entry behind, which is skipped
+		}
+	}
+
+	scan := func(frontier []Node, light bool) []relaxRequest {
+		chunks := make([][]relaxRequest, workers)
+		inParallel(workers, len(frontier), func(chunk, from, to int) {
+			for _, node := range frontier[from:to] {
+				dist := costs[node.ID()]
+				for _, succ := range graph.Successors(node) {
+					if cost := Cost(node, succ); (cost <= delta) == light {
+						chunks[chunk] = append(chunks[chunk], relaxRequest{node: succ, pred: node, dist: dist + cost})
+					}
+				}
+			}
+		})
+
+		var requests []relaxRequest
+		for _, chunk := range chunks {
+			requests = append(requests, chunk...)
+		}
+		return requests
+	}
+
+	for i := 0; i < len(buckets); i++ {
+		var settled []Node
+		inSettled := make(map[int]bool)
+		for len(buckets[i]) > 0 {
+			var frontier []Node
+			inFrontier := make(map[int]bool)
+			for _, node := range buckets[i] {
+				if id := node.ID(); !inFrontier[id] && bucketOf(costs[id]) == i {
+					inFrontier[id] = true
+					frontier = append(frontier, node)
+				}
+			}
+			buckets[i] = nil
+
+			requests := scan(frontier, true)
+			for _, node := range frontier {
+				if !inSettled[node.ID()] {
+					inSettled[node.ID()] = true
+					settled = append(settled, node)
+				}
+			}
+			relax(requests)
+		}
+
+		relax(scan(settled, false))
+	}
+
+	paths = make(map[int][]Node, len(costs))
+	for id, node := range nodes {
+		paths[id] = rebuildPath(predecessor, node)
+	}
+	return paths, costs
+}
+
+func meanEdgeCost(graph Graph, Cost func(Node, Node) float64) float64 {
+	edges := graph.EdgeList()
+	if len(edges) == 0 {
+		return 1
+	}
+
+	total := 0.0
+	for _, edge := range edges {
+		total += Cost(edge.Head(), edge.Tail())
+	}
+	if total <= 0 {
+		return 1
+	}
+	return total / float64(len(edges))
+}
@@ -0,0 +1,56 @@
+package graph_test
+
+import (
+	"fmt"
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDeltaStepping(t *testing.T) {
+	g := randomWeightedGraph(300, 0.03, 4)
+	source := graph.GonumNode(0)
+	_, expect := graph.DijkstraQueue(source, g, nil, graph.BinaryHeapQueue)
+
+	for _, delta := range []float64{0, 0.5, 3, 100} {
+		for _, workers := range []int{1, 4} {
+			paths, costs := graph.DeltaStepping(source, g, nil, delta, workers)
+			if len(costs) != len(expect) || len(paths) != len(expect) {
+				t.Errorf("delta=%v workers=%d: reached %d nodes, expected %d", delta, workers, len(costs), len(expect))
+			}
+			for id, cost := range expect {
+				sum := 0.0
+				path := paths[id]
+				for i := 1; i < len(path); i++ {
+					sum += g.Cost(path[i-1], path[i])
+				}
+				if math.Abs(costs[id]-cost) > 1e-9 || math.Abs(sum-cost) > 1e-9 || path[len(path)-1].ID() != id {
+					t.Errorf("delta=%v workers=%d: cost to %d is %v (path %v costing %v), expected %v", delta, workers, id, costs[id], path, sum, cost)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkDeltaStepping(b *testing.B) {
+	src := rand.New(rand.NewSource(1))
+	g := graph.NewGonumGraph(true)
+	graph.GnmRandomGraph(g, 20000, 100000, true, src)
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, 1+src.Float64()*9)
+	}
+
+	b.Run("dijkstra", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			graph.DijkstraQueue(graph.GonumNode(0), g, nil, graph.QuaternaryHeapQueue)
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				graph.DeltaStepping(graph.GonumNode(0), g, nil, 0, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"math"
+)
+
+// Finds a densest subgraph of the undirected graph underlying graph: the set of nodes S maximizing the density w(S)/|S|, where w(S) is the total weight of the edges with both
+// ends in S, which for unit weights is half the average degree of the subgraph S induces. Dense subgraphs are tight-knit communities, and in transaction or follower networks,
+// often fraud rings or bots. Weight is interpreted as Cost is in AStar, and must not be negative; self loops are ignored. Returns the nodes sorted by ID along with their density,
+// or nil and 0 for an empty graph.
+//
+// The answer is exact, by Goldberg's reduction to minimum cuts[1]: for a guess g at the density, a cut in a network with an arc from a source to each node, from each node to a
+// sink, and both ways along each edge, sized so the cut around S costs a constant less twice w(S) - g|S|, finds the S that gains the most over g, which is denser than g if any
+// is. Rather than bisecting on g, each guess is the density of the last S found, which gives a strictly denser S each time until there's none, so it takes only a handful of
+// maximum flows in practice.
+//
+// [1] A. V. Goldberg, "Finding a maximum density subgraph", Technical Report UCB/CSD-84-171, University of California, Berkeley (1984)
+func DensestSubgraph(graph Graph, Weight func(Node, Node) float64) (nodes []Node, density float64) {
+	l := newLaplacian(graph, Weight)
+	n := len(l.nodes)
+	if n == 0 {
+		return nil, 0
+	}
+
+	total, degree := densityWeights(l)
+	eps := 1e-9 * math.Max(1, total)
+	in := make([]bool, n)
+	for i := range in {
+		in[i] = true
+	}
+	density = total / float64(n)
+
+	for {
+		// Nodes 0 to n-1 are graph's, then the source and sink
+		source, sink := n, n+1
+		network := newFlowNetwork(n + 2)
+		for i := 0; i < n; i++ {
+			network.addArc(source, i, total)
+			network.addArc(i, sink, total+2*density-degree[i])
+		}
+		l.eachEdge(func(u, v int, w float64) {
+			network.addArc(u, v, w)
+			network.addArc(v, u, w)
+		})
+		network.maxFlow(source, sink, eps)
+
+		reached := network.reachable(source, eps)[:n]
+		size, weight := 0, 0.0
+		for _, r := range reached {
+			if r {
+				size++
+			}
+		}
+		l.eachEdge(func(u, v int, w float64) {
+			if reached[u] && reached[v] {
+				weight += w
+			}
+		})
+		if size == 0 || weight-density*float64(size) <= eps {
+			break
+		}
+		in, density = reached, weight/float64(size)
+	}
+
+	for i, node := range l.nodes {
+		if in[i] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, density
+}
+
+// Finds a subgraph of the undirected graph underlying graph at least half as dense as the densest, by Charikar's peeling[1]: the node of least weighted degree is removed over
+// and over, and the densest of the subgraphs left along the way is returned. Density, Weight and the result are as in DensestSubgraph, but this takes only O(m log n) time, so
+// it scales to networks far too large for maximum flows, and in practice it usually comes much closer than half.
+//
+// [1] M. Charikar, "Greedy approximation algorithms for finding dense components in a graph", APPROX (2000)
+func PeelingDensestSubgraph(graph Graph, Weight func(Node, Node) float64) (nodes []Node, density float64) {
+	l := newLaplacian(graph, Weight)
+	n := len(l.nodes)
+	if n == 0 {
+		return nil, 0
+	}
+
+	weight, degree := densityWeights(l)
+	queue := NewDaryHeap(4, func(a, b HeapItem) bool {
+		return a.Key.(float64) < b.Key.(float64) || a.Key.(float64) == b.Key.(float64) && a.ID() < b.ID()
+	})
+	for i, d := range degree {
+		queue.Push(GonumNode(i), d)
+	}
+
+	// Nodes removed before step best are left out of the densest subgraph seen
+	removed := make([]bool, n)
+	order := make([]int, 0, n)
+	best := 0
+	density = weight / float64(n)
+	for queue.Len() != 0 {
+		u := queue.Pop().ID()
+		removed[u] = true
+		order = append(order, u)
+		weight -= degree[u]
+		for k, v := range l.neighbors[u] {
+			if v != u && !removed[v] {
+				degree[v] -= l.weights[u][k]
+				queue.Fix(GonumNode(v), degree[v])
+			}
+		}
+		if left := n - len(order); left > 0 && weight/float64(left) > density {
+			best, density = len(order), weight/float64(left)
+		}
+	}
+
+	in := make([]bool, n)
+	for _, u := range order[best:] {
+		in[u] = true
+	}
+	for i, node := range l.nodes {
+		if in[i] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, density
+}
+
+// The total weight of the edges of l, and each node's weighted degree, without self loops
+func densityWeights(l *laplacian) (total float64, degree []float64) {
+	degree = make([]float64, len(l.nodes))
+	l.eachEdge(func(u, v int, w float64) {
+		total += w
+		degree[u] += w
+		degree[v] += w
+	})
+	return total, degree
+}
@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// The density of the subgraph of g that nodes induce, with unit weights
+func density(g graph.Graph, nodes []graph.Node) float64 {
+	in := make(map[int]bool)
+	for _, node := range nodes {
+		in[node.ID()] = true
+	}
+	edges := make(map[[2]int]bool)
+	for _, edge := range g.EdgeList() {
+		h, t := edge.Head().ID(), edge.Tail().ID()
+		if h > t {
+			h, t = t, h
+		}
+		if h != t && in[h] && in[t] {
+			edges[[2]int{h, t}] = true
+		}
+	}
+	return float64(len(edges)) / float64(len(nodes))
+}
+
+func TestDensestSubgraph(t *testing.T) {
+	// A K5 with a long tail, which the densest subgraph leaves off
+	g := graph.NewGonumGraph(false)
+	for i := 0; i < 12; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for i := 0; i < 5; i++ {
+		for j := 0; j < i; j++ {
+			g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(j)})
+		}
+	}
+	for i := 5; i < 12; i++ {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i - 1), T: graph.GonumNode(i)})
+	}
+	nodes, d := graph.DensestSubgraph(g, nil)
+	if len(nodes) != 5 || nodes[0].ID() != 0 || nodes[4].ID() != 4 || d != 2 {
+		t.Errorf("Expected K5 with density 2, got %v with density %v", nodes, d)
+	}
+
+	if nodes, d := graph.DensestSubgraph(graph.NewGonumGraph(false), nil); nodes != nil || d != 0 {
+		t.Errorf("Expected nothing from an empty graph, got %v with density %v", nodes, d)
+	}
+
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 40; trial++ {
+		g := graph.NewGonumGraph(trial%2 == 0)
+		n := 1 + src.Intn(11)
+		graph.GnpRandomGraph(g, n, 0.1+0.5*src.Float64(), trial%2 == 0, src)
+
+		best := 0.0
+		for set := 1; set < 1<<uint(n); set++ {
+			var nodes []graph.Node
+			for i := 0; i < n; i++ {
+				if set&(1<<uint(i)) != 0 {
+					nodes = append(nodes, graph.GonumNode(i))
+				}
+			}
+			best = math.Max(best, density(g, nodes))
+		}
+
+		nodes, d := graph.DensestSubgraph(g, nil)
+		if math.Abs(d-best) > 1e-9 || math.Abs(density(g, nodes)-d) > 1e-9 {
+			t.Errorf("Trial %d: expected density %v, got %v with density %v", trial, best, nodes, d)
+		}
+		nodes, d = graph.PeelingDensestSubgraph(g, nil)
+		if d < best/2-1e-9 || d > best+1e-9 || math.Abs(density(g, nodes)-d) > 1e-9 {
+			t.Errorf("Trial %d: expected density at least %v, got %v with density %v", trial, best/2, nodes, d)
+		}
+	}
+}
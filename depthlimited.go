@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"errors"
+)
+
+// Returned by DepthLimitedSearch and IterativeDeepening when they found no path within their depth limit, but didn't explore everything either: a deeper search might find one.
+var ErrDepthCutoff = errors.New("No path within the depth limit")
+
+// Searches depth first from start for goal, going no more than depthLimit edges deep, and returns the first path it finds, which isn't necessarily the shortest. Memory use is
+// only proportional to depthLimit, since the search only remembers the path it's on; it avoids cycles along that path, but may reach the same node many times by different
+// paths, so on graphs with many paths between nodes it takes time exponential in depthLimit.
+//
+// If no path is found, err says why: ErrDepthCutoff if some branch was cut short by the limit, nil if the search was exhaustive and there is no path at all, or
+// ErrBudgetExhausted if budget ran out (MaxExpansions counts expanded nodes).
+func DepthLimitedSearch(start, goal Node, graph ImplicitGraph, depthLimit int, budget SearchBudget) (path []Node, nodesExpanded int, err error) {
+	return depthLimited(start, goal, graph, depthLimit, budget.canceller(nil))
+}
+
+func depthLimited(start, goal Node, graph ImplicitGraph, depthLimit int, cancel *canceller) (path []Node, nodesExpanded int, err error) {
+	path = []Node{start}
+	onPath := map[int]bool{start.ID(): true}
+	cutoff := false
+
+	var search func(node Node, depth int) bool
+	search = func(node Node, depth int) bool {
+		if node.ID() == goal.ID() {
+			return true
+		}
+		if depth == depthLimit {
+			cutoff = true
+			return false
+		}
+		if err = cancel.err(); err != nil {
+			return false
+		}
+
+		nodesExpanded += 1
+		for _, succ := range graph.Successors(node) {
+			if onPath[succ.ID()] {
+				continue
+			}
+
+			path = append(path, succ)
+			onPath[succ.ID()] = true
+			if search(succ, depth+1) {
+				return true
+			}
+			if err != nil {
+				return false
+			}
+			path = path[:len(path)-1]
+			delete(onPath, succ.ID())
+		}
+
+		return false
+	}
+
+	if search(start, 0) {
+		return path, nodesExpanded, nil
+	}
+	if err == nil && cutoff {
+		err = ErrDepthCutoff
+	}
+	return nil, nodesExpanded, err
+}
+
+// Iterative deepening depth first search[1]: runs DepthLimitedSearch with limits of 0, 1, 2 and so on up to maxDepth (or without end if maxDepth is negative), until it finds
+// goal. Like breadth first search, it finds a path with the fewest edges, but like depth first search it only needs memory for the current path, which makes it the usual choice
+// for uninformed search of huge implicit graphs. Re-exploring the shallow levels on every iteration costs little when the graph branches, since most nodes are on the deepest one.
+//
+// The budget applies to all the iterations together. If no path is found, err is as for DepthLimitedSearch: ErrDepthCutoff if maxDepth was reached, nil if there's provably no
+// path, or ErrBudgetExhausted.
+//
+// [1] R. E. Korf, "Depth-first iterative-deepening: An optimal admissible tree search", Artificial Intelligence 27 (1985)
+func IterativeDeepening(start, goal Node, graph ImplicitGraph, maxDepth int, budget SearchBudget) (path []Node, nodesExpanded int, err error) {
+	cancel := budget.canceller(nil)
+	for depth := 0; maxDepth < 0 || depth <= maxDepth; depth++ {
+		path, expanded, err := depthLimited(start, goal, graph, depth, cancel)
+		nodesExpanded += expanded
+		if err != ErrDepthCutoff {
+			return path, nodesExpanded, err
+		}
+	}
+
+	return nil, nodesExpanded, ErrDepthCutoff
+}
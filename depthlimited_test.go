@@ -0,0 +1,52 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestDepthLimitedSearch(t *testing.T) {
+	tg := graph.NewTileGraph(5, 5, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(4, 4)
+
+	if path, _, err := graph.DepthLimitedSearch(start, goal, tg, 7, graph.SearchBudget{}); path != nil || err != graph.ErrDepthCutoff {
+		t.Errorf("Expected ErrDepthCutoff for a goal 8 steps away with a limit of 7, got %v, %v", path, err)
+	}
+	path, _, err := graph.DepthLimitedSearch(start, goal, tg, 8, graph.SearchBudget{})
+	if err != nil || len(path) != 9 || !isWalk(tg, path) {
+		t.Errorf("Expected an 8 step path with a limit of 8, got %v, %v", path, err)
+	}
+	path, _, err = graph.DepthLimitedSearch(start, goal, tg, 20, graph.SearchBudget{})
+	if err != nil || len(path) > 21 || !isWalk(tg, path) || path[len(path)-1].ID() != goal.ID() {
+		t.Errorf("Expected some path with a limit of 20, got %v, %v", path, err)
+	}
+
+	walled := graph.NewTileGraph(1, 3, true)
+	walled.SetPassability(0, 1, false)
+	if path, _, err := graph.DepthLimitedSearch(graph.GonumNode(0), graph.GonumNode(2), walled, 10, graph.SearchBudget{}); path != nil || err != nil {
+		t.Errorf("Expected an exhaustive search to find no path and no cutoff, got %v, %v", path, err)
+	}
+}
+
+func TestIterativeDeepening(t *testing.T) {
+	// On the infinite lattice, where only a depth limit keeps depth first search from wandering off forever
+	start, goal := latticeNode{0, 0}, latticeNode{2, 4}
+	path, expanded, err := graph.IterativeDeepening(start, goal, lattice{}, -1, graph.SearchBudget{})
+	if err != nil || !isLatticePath(path) || path[len(path)-1] != goal {
+		t.Fatalf("Expected a path, got %v, %v", path, err)
+	}
+	if _, cost, _ := graph.AStar(start, goal, lattice{}, nil, manhattan); float64(len(path)-1) != cost {
+		t.Errorf("Expected a path of %v steps, got %d", cost, len(path)-1)
+	}
+	if expanded == 0 {
+		t.Error("No nodes expanded")
+	}
+
+	if path, _, err := graph.IterativeDeepening(start, goal, lattice{}, 5, graph.SearchBudget{}); path != nil || err != graph.ErrDepthCutoff {
+		t.Errorf("Expected ErrDepthCutoff with a maximum depth of 5 for a goal 6 steps away, got %v, %v", path, err)
+	}
+	if path, expanded, err := graph.IterativeDeepening(start, latticeNode{100, 100}, lattice{}, -1, graph.SearchBudget{MaxExpansions: 1000}); path != nil || err != graph.ErrBudgetExhausted || expanded > 1000 {
+		t.Errorf("Expected ErrBudgetExhausted after 1000 expansions, got %v after %d", err, expanded)
+	}
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Thresholds for switching between top-down and bottom-up steps, the values Beamer et al. found to work well
+const (
+	bfsAlpha = 14
+	bfsBeta  = 24
+)
+
+// A breadth first search that returns the level (the number of edges on a shortest path from source) of every node reachable from source, computed with Beamer's
+// direction-optimizing approach[1], and with every step spread over up to workers goroutines (GOMAXPROCS if workers <= 0).
+//
+// A normal, "top-down", BFS step scans the edges out of the frontier for nodes that haven't been visited yet. On graphs with a small diameter, like social networks, the frontier soon
+// covers a good part of the graph and most of those edges lead to nodes that have already been visited. At that point it's cheaper to go "bottom-up": check every unvisited node's
+// predecessors for one in the frontier, stopping at the first one found. The search switches to bottom-up steps when the edges out of the frontier outnumber a fraction of the edges out
+// of the unvisited nodes, and back to top-down once the frontier is a small fraction of the graph again.
+//
+// Every node the search may reach must be in the graph's NodeList. The graph's Successors, Predecessors and Degree must be safe to call concurrently, which holds for all read-only
+// graphs in this package.
+//
+// [1] S. Beamer, K. Asanović and D. Patterson, "Direction-optimizing breadth-first search", SC '12
+func DirectionOptimizingBFS(source Node, graph Graph, workers int) (levels map[int]int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	nodes := graph.NodeList()
+	slots := make(map[int]int, len(nodes))
+	for i, node := range nodes {
+		slots[node.ID()] = i
+	}
+	level := make([]int32, len(nodes))
+	degree := make([]int, len(nodes))
+	unexplored := 0
+	for i, node := range nodes {
+		level[i] = -1
+		degree[i] = graph.Degree(node)
+		unexplored += degree[i]
+	}
+
+	src := slots[source.ID()]
+	level[src] = 0
+	frontier := []int{src}
+	frontierEdges := degree[src]
+	unexplored -= frontierEdges
+	bottomUp := false
+
+	nexts := make([][]int, workers)
+	for depth := int32(0); len(frontier) > 0; depth++ {
+		if !bottomUp && frontierEdges > unexplored/bfsAlpha {
+			bottomUp = true
+		} else if bottomUp && len(frontier) < len(nodes)/bfsBeta {
+			bottomUp = false
+		}
+
+		for i := range nexts {
+			nexts[i] = nexts[i][:0]
+		}
+		if bottomUp {
+			inParallel(workers, len(nodes), func(chunk, from, to int) {
+				for v := from; v < to; v++ {
+					if atomic.LoadInt32(&level[v]) != -1 {
+						continue
+					}
+					for _, pred := range graph.Predecessors(nodes[v]) {
+						if atomic.LoadInt32(&level[slots[pred.ID()]]) == depth {
+							// Only this chunk writes v, but other chunks may be reading it
+							atomic.StoreInt32(&level[v], depth+1)
+							nexts[chunk] = append(nexts[chunk], v)
+							break
+						}
+					}
+				}
+			})
+		} else {
+			inParallel(workers, len(frontier), func(chunk, from, to int) {
+				for _, u := range frontier[from:to] {
+					for _, succ := range graph.Successors(nodes[u]) {
+						v := slots[succ.ID()]
+						if atomic.CompareAndSwapInt32(&level[v], -1, depth+1) {
+							nexts[chunk] = append(nexts[chunk], v)
+						}
+					}
+				}
+			})
+		}
+
+		frontier, frontierEdges = frontier[:0], 0
+		for _, next := range nexts {
+			for _, v := range next {
+				frontier = append(frontier, v)
+				frontierEdges += degree[v]
+			}
+		}
+		unexplored -= frontierEdges
+	}
+
+	levels = make(map[int]int)
+	for i, l := range level {
+		if l != -1 {
+			levels[nodes[i].ID()] = int(l)
+		}
+	}
+	return levels
+}
@@ -0,0 +1,62 @@
+package graph_test
+
+import (
+	"fmt"
+	"github.com/gonum/graph"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func bfsLevels(source graph.Node, g graph.Graph) map[int]int {
+	levels := map[int]int{source.ID(): 0}
+	for frontier := []graph.Node{source}; len(frontier) > 0; {
+		var next []graph.Node
+		for _, node := range frontier {
+			for _, succ := range g.Successors(node) {
+				if _, ok := levels[succ.ID()]; !ok {
+					levels[succ.ID()] = levels[node.ID()] + 1
+					next = append(next, succ)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return levels
+}
+
+func TestDirectionOptimizingBFS(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	sparse, dense, scaleFree := graph.NewGonumGraph(true), graph.NewGonumGraph(false), graph.NewGonumGraph(false)
+	graph.GnpRandomGraph(sparse, 500, 0.005, true, src)
+	graph.GnpRandomGraph(dense, 1000, 0.05, false, src) // Dense enough for bottom-up steps
+	graph.BarabasiAlbertGraph(scaleFree, 2000, 3, nil, src)
+
+	for name, g := range map[string]graph.Graph{"sparse directed": sparse, "dense": dense, "scale-free": scaleFree} {
+		expect := bfsLevels(graph.GonumNode(0), g)
+		for _, workers := range []int{1, 3} {
+			if levels := graph.DirectionOptimizingBFS(graph.GonumNode(0), g, workers); !reflect.DeepEqual(levels, expect) {
+				t.Errorf("%s, %d workers: got %d levels that differ from a plain BFS", name, workers, len(levels))
+			}
+		}
+	}
+}
+
+func BenchmarkDirectionOptimizingBFS(b *testing.B) {
+	g := graph.NewGonumGraph(false)
+	graph.BarabasiAlbertGraph(g, 20000, 8, nil, rand.New(rand.NewSource(1)))
+
+	b.Run("plain", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bfsLevels(graph.GonumNode(0), g)
+		}
+	})
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				graph.DirectionOptimizingBFS(graph.GonumNode(0), g, workers)
+			}
+		})
+	}
+}
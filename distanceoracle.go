@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// A DistanceOracle answers approximate distance queries on an undirected graph in constant time (at most 2k lookups) from tables much smaller than all pairs shortest paths, as
+// described by Thorup and Zwick[1]. Estimates are never less than the true distance and at most 2k-1 times it, so k = 2 gives stretch 3 with tables of around n^1.5 entries,
+// and larger k trade accuracy for space; k = 1 stores every distance and is exact.
+//
+// Nodes are sampled into a hierarchy of ever sparser sets A_0 = V ⊇ A_1 ⊇ ... ⊇ A_k-1, each keeping a node of the last with probability n^(-1/k). Every node stores its distance to
+// the nearest node of each set, and its bunch: the nodes of A_i \ A_i+1 closer to it than anything in A_i+1. A query hops between the bunches of its two ends, climbing a level each
+// time, until it finds a node in both.
+//
+// Preprocessing takes a Dijkstra search per set and a truncated one per node, and the oracle goes stale if the graph changes. On a directed graph distances are taken along
+// Successors only and the stretch guarantee doesn't hold, so symmetrize it first.
+//
+// [1] M. Thorup and U. Zwick, "Approximate distance oracles", Journal of the ACM 52 (2005)
+type DistanceOracle struct {
+	k       int
+	pivots  []map[int]Node    // pivots[i][v] is the node of A_i nearest v
+	toLevel []map[int]float64 // toLevel[i][v] is the distance from v to its pivot in A_i
+	bunches map[int]map[int]float64
+}
+
+// Builds a distance oracle with stretch 2k-1 for graph. Cost is interpreted as in AStar, and must not be negative. If src is nil a time seeded source is used.
+func NewDistanceOracle(graph Graph, Cost func(Node, Node) float64, k int, src *rand.Rand) *DistanceOracle {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	src = randSource(src)
+	if k < 1 {
+		k = 1
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes)) // So the sample only depends on src
+	oracle := &DistanceOracle{k: k, bunches: make(map[int]map[int]float64, len(nodes))}
+	for _, node := range nodes {
+		oracle.bunches[node.ID()] = make(map[int]float64)
+	}
+	if len(nodes) == 0 {
+		return oracle
+	}
+
+	// The top level is never left empty, or queries between nodes far apart would have nowhere to meet
+	levels := [][]Node{nodes}
+	p := math.Pow(float64(len(nodes)), -1/float64(k))
+	for i := 1; i < k; i++ {
+		var sample []Node
+		for _, node := range levels[i-1] {
+			if src.Float64() < p {
+				sample = append(sample, node)
+			}
+		}
+		if len(sample) == 0 {
+			sample = []Node{levels[i-1][src.Intn(len(levels[i-1]))]}
+		}
+		levels = append(levels, sample)
+	}
+
+	for _, level := range levels {
+		dist, pivot := nearestOf(level, graph, Cost)
+		oracle.toLevel = append(oracle.toLevel, dist)
+		oracle.pivots = append(oracle.pivots, pivot)
+	}
+
+	// The cluster of w in A_i \ A_i+1 is every node closer to w than to A_i+1, which is exactly the set of nodes with w in their bunch. Clusters are connected along shortest
+	// paths from w, so a Dijkstra search from w that stops at nodes outside the cluster finds it.
+	for i, level := range levels {
+		next := make(map[int]bool)
+		if i+1 < k {
+			for _, node := range levels[i+1] {
+				next[node.ID()] = true
+			}
+		}
+		for _, w := range level {
+			if next[w.ID()] {
+				continue
+			}
+			for id, d := range oracle.cluster(w, i+1, graph, Cost) {
+				oracle.bunches[id][w.ID()] = d
+			}
+		}
+	}
+
+	return oracle
+}
+
+// The distance from every node to the nearest of sources, and which source that is, by a Dijkstra search from all of them at once
+func nearestOf(sources []Node, graph Graph, Cost func(Node, Node) float64) (dist map[int]float64, pivot map[int]Node) {
+	dist, pivot = make(map[int]float64), make(map[int]Node)
+	openSet := &aStarPriorityQueue{}
+	for _, source := range sources {
+		dist[source.ID()], pivot[source.ID()] = 0, source
+		heap.Push(openSet, internalNode{source, 0, 0})
+	}
+
+	settled := make(map[int]bool)
+	for openSet.Len() != 0 {
+		curr := heap.Pop(openSet).(internalNode)
+		if settled[curr.ID()] {
+			continue
+		}
+		settled[curr.ID()] = true
+
+		for _, succ := range graph.Successors(curr.Node) {
+			d := curr.gscore + Cost(curr.Node, succ)
+			if old, ok := dist[succ.ID()]; settled[succ.ID()] || ok && d >= old || math.IsInf(d, 1) {
+				continue
+			}
+			dist[succ.ID()], pivot[succ.ID()] = d, pivot[curr.ID()]
+			heap.Push(openSet, internalNode{succ, d, d})
+		}
+	}
+
+	return dist, pivot
+}
+
+// The nodes strictly closer to w than to the nearest node of level, with their distances from w
+func (oracle *DistanceOracle) cluster(w Node, level int, graph Graph, Cost func(Node, Node) float64) map[int]float64 {
+	limit := func(id int) float64 {
+		if level >= oracle.k {
+			return math.Inf(1)
+		}
+		if d, ok := oracle.toLevel[level][id]; ok {
+			return d
+		}
+		return math.Inf(1)
+	}
+
+	dist := map[int]float64{w.ID(): 0}
+	settled := make(map[int]bool)
+	openSet := &aStarPriorityQueue{{w, 0, 0}}
+	for openSet.Len() != 0 {
+		curr := heap.Pop(openSet).(internalNode)
+		if settled[curr.ID()] {
+			continue
+		}
+		settled[curr.ID()] = true
+
+		for _, succ := range graph.Successors(curr.Node) {
+			d := curr.gscore + Cost(curr.Node, succ)
+			if old, ok := dist[succ.ID()]; settled[succ.ID()] || ok && d >= old || d >= limit(succ.ID()) {
+				continue
+			}
+			dist[succ.ID()] = d
+			heap.Push(openSet, internalNode{succ, d, d})
+		}
+	}
+
+	return dist
+}
+
+// An estimate of the distance between u and v, at least the true distance and at most 2k-1 times it. +Inf means they aren't connected, or that one of them wasn't in the graph
+// when the oracle was built. The query is run from both ends and the better estimate kept, which makes it symmetric.
+func (oracle *DistanceOracle) Distance(u, v Node) float64 {
+	if u.ID() == v.ID() {
+		return 0
+	}
+
+	return math.Min(oracle.query(u, v), oracle.query(v, u))
+}
+
+func (oracle *DistanceOracle) query(u, v Node) float64 {
+	w, du := u, 0.0
+	for i := 0; ; {
+		if dv, ok := oracle.bunches[v.ID()][w.ID()]; ok {
+			return du + dv
+		}
+
+		i++
+		if i >= oracle.k {
+			return math.Inf(1)
+		}
+		u, v = v, u
+		pivot, ok := oracle.pivots[i][u.ID()]
+		if !ok {
+			return math.Inf(1)
+		}
+		w, du = pivot, oracle.toLevel[i][u.ID()]
+	}
+}
+
+// The number of distances stored in the bunches, which dominate the oracle's size: expected O(k n^(1+1/k)).
+func (oracle *DistanceOracle) Size() int {
+	size := 0
+	for _, bunch := range oracle.bunches {
+		size += len(bunch)
+	}
+	return size
+}
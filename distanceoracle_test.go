@@ -0,0 +1,43 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestDistanceOracle(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.RandomGeometricGraph(g, 400, 2, 0.1, rand.New(rand.NewSource(1)))
+	n := len(g.NodeList())
+
+	src := rand.New(rand.NewSource(2))
+	for k := 1; k <= 3; k++ {
+		oracle := graph.NewDistanceOracle(g, nil, k, rand.New(rand.NewSource(3)))
+		stretch := float64(2*k - 1)
+		for i := 0; i < 200; i++ {
+			u, v := graph.GonumNode(src.Intn(n)), graph.GonumNode(src.Intn(n))
+			estimate := oracle.Distance(u, v)
+			path, d, _ := graph.AStar(u, v, g, nil, nil)
+			switch {
+			case path == nil:
+				if !math.IsInf(estimate, 1) {
+					t.Errorf("k=%d: nodes %v and %v aren't connected, but the estimated distance is %v", k, u, v, estimate)
+				}
+			case estimate < d-1e-9 || estimate > stretch*d+1e-9:
+				t.Errorf("k=%d: estimated distance from %v to %v is %v, expected between %v and %v", k, u, v, estimate, d, stretch*d)
+			case k == 1 && math.Abs(estimate-d) > 1e-9:
+				t.Errorf("k=1: estimated distance from %v to %v is %v, expected exactly %v", u, v, estimate, d)
+			}
+			if back := oracle.Distance(v, u); estimate != back {
+				t.Errorf("k=%d: estimate from %v to %v isn't symmetric", k, u, v)
+			}
+		}
+
+		if k > 1 && oracle.Size() >= n*n/2 {
+			t.Errorf("k=%d: expected the oracle to be much smaller than all pairs distances, it stores %d for %d nodes", k, oracle.Size(), n)
+		}
+	}
+}
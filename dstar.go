@@ -1,9 +1,9 @@
 package graph
 
 import (
-	"container/heap"
-	"errors"
+	"context"
 	"math"
+	"time"
 )
 
 // A DStarGraph is a special interface that allows the DStarLite function to be used on a graph
@@ -16,6 +16,10 @@ import (
 // since the graph no longer has to scan for changes, and only updates when told to. If changedEdges is nil or of len 0, no updates will be performed. If changedEdges is not nil, it
 // will update the internal representation. If newCostFunc is non-nil it will be swapped with dStar's current cost function if and only if changedEdges is non-nil/len>0, however,
 // newCostFunc is not required to be non-nil if updates are present. DStar will continue using the current cost function if that is the case.
+//
+// Changes aren't limited to costs: changedEdges should also list edges that have been added to or removed from the graph (removing a node means reporting all the edges that touched it),
+// and nodes that didn't exist when D*-Lite was initialized may appear. Either way D*-Lite recomputes the estimates of the edges' endpoints from the graph as it is now. In an undirected
+// graph, reporting an edge in one direction is enough.
 type DStarGraph interface {
 	Graph
 	Move(target Node)
@@ -35,19 +39,169 @@ type DStarGraph interface {
 //         perform the move returned
 //         if the graph changed, call Update(... change info ...)
 type DStarInstance struct {
-	graph             Graph
+	graph             ReversibleGraph
 	start, goal, last Node
-	gScores           map[int]float64
+	gScores           ScoreStore
 	cost              func(Node, Node) float64
+	visit             func(node Node, fn func(succ Node, cost float64) bool)
+	visitPreds        func(node Node, fn func(pred Node, cost float64) bool) // Only for the predecessors themselves; their costs are ds.cost's business
 	heuristicCost     func(Node, Node) float64
 	u                 *dStarPriorityQueue
-	rhs               map[int]float64
+	rhs               ScoreStore
 	k_m               float64
+	compare           func(a, b DStarKey) bool
+	equal             Tolerance
+	stats             *SearchStats
+	observer          SearchObserver
+	cancel            *canceller
+	interrupted       error // Why the last search stopped before finishing, if it did
+	budget            SearchBudget
+	workers           int
+	minParallel       int
+	queue             QueueKind
+	anytime           *anytimeState // Set only for an AnytimeDStarInstance
+
+	// Reused by every expansion for the nodes it updates and their new rhs values, so the search's inner loop doesn't allocate
+	updates    []Node
+	updatedRHS []float64
+
+	// The plan Path last worked out, kept until the next search or Step changes it
+	path      []Node
+	pathCost  float64
+	pathErr   error
+	pathValid bool
+}
+
+// The priority of a node in D*-Lite's queue: K1 = min(g, rhs) + h(start, node) + k_m and K2 = min(g, rhs). The node is included so comparators can break ties between equal keys.
+type DStarKey struct {
+	Node   Node
+	K1, K2 float64
+}
+
+// Compares keys lexicographically, as in the original paper. This is the default. Ties between equal keys are broken by insertion order, which is deterministic but not very meaningful.
+func DStarLexicographic(a, b DStarKey) bool {
+	return a.K1 < b.K1 || a.K1 == b.K1 && a.K2 < b.K2
+}
+
+// Compares keys lexicographically, then breaks ties by preferring the lower node ID. This makes the expansion order a function of the graph alone.
+func DStarTieBreakByID(a, b DStarKey) bool {
+	if a.K1 != b.K1 || a.K2 != b.K2 {
+		return DStarLexicographic(a, b)
+	}
+
+	return a.Node.ID() < b.Node.ID()
+}
+
+// Compares by K1, then prefers the larger K2 (g-score) and finally the lower node ID. Since the search runs backwards from the goal, a larger g-score means the node is closer to the start,
+// so among equally promising nodes this digs deeper along one path rather than widening the search, like the usual "prefer larger g" tie breaking in A*. Often expands far fewer nodes on grids.
+func DStarTieBreakLargerG(a, b DStarKey) bool {
+	if a.K1 != b.K1 {
+		return a.K1 < b.K1
+	}
+	if a.K2 != b.K2 {
+		return a.K2 > b.K2
+	}
+
+	return a.Node.ID() < b.Node.ID()
+}
+
+// A DStarOption configures a DStarInstance when it's created by InitDStar.
+type DStarOption func(*DStarInstance)
+
+// Orders D*-Lite's priority queue with less instead of DStarLexicographic. The comparator must be a strict weak ordering that refines the lexicographic order of (K1, K2), or the search may
+// stop before the path is correct; DStarTieBreakByID and DStarTieBreakLargerG are ready made choices.
+func WithDStarComparator(less func(a, b DStarKey) bool) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.compare = less
+	}
+}
+
+// Sets the tolerance used to decide whether a node's g-score and rhs agree (the node is "consistent"). The default is DefaultTolerance; the absolute 1e-6 D*-Lite used to
+// hard code can be had with AbsoluteTolerance(1e-6).
+func WithDStarTolerance(equal Tolerance) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.equal = equal
+	}
+}
+
+// Makes the instance keep SearchStats, retrievable with Stats. Every computeShortestPath (the initial search and each replan in Update) counts as a search, a pop is an expansion unless
+// the node's key was stale and it was only requeued, and every vertex update is counted.
+func WithDStarStats() DStarOption {
+	return func(ds *DStarInstance) {
+		ds.stats = &SearchStats{}
+	}
+}
+
+// Returns the statistics collected so far, or zeroed statistics if the instance wasn't created with WithDStarStats. They accumulate over the life of the instance, including across Reset
+// and Retarget.
+func (ds *DStarInstance) Stats() SearchStats {
+	if ds.stats == nil {
+		return SearchStats{}
+	}
+
+	stats := *ds.stats
+	stats.QueueLen = ds.u.Len()
+	return stats
+}
+
+// Notifies observer of the instance's searches. OnPathFound is called at the end of every search (the initial one and each replan) that leaves a path, with the whole current plan
+// from the start and its cost, i.e. the start's g-score.
+func WithDStarObserver(observer SearchObserver) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.observer = observer
+	}
+}
+
+// Limits every search (the initial one and each replan) to the budget. A search that runs out stops early, leaving its remaining work queued; the next search resumes it, and Update
+// resumes it even if there are no changes, so an agent can interleave bounded planning with moving, in the manner of anytime planners. Until the search completes, Step follows the
+// best plan known so far; if there isn't one yet, it returns ErrBudgetExhausted rather than declaring there's no path.
+//
+// The ctx variants (InitDStarCtx, UpdateCtx) return ErrBudgetExhausted when the budget runs out.
+func WithDStarBudget(budget SearchBudget) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.budget = budget
+	}
+}
+
+// Keeps g-scores and rhs values in the given stores instead of the ones D*-Lite picks for itself. They must be distinct, and are cleared when the instance is initialized.
+//
+// Left to itself, D*-Lite keeps scores in slices indexed by ID when the graph's IDs are dense, as a TileGraph's, a GridGraph's or an ImmutableGraph's are, or a GonumGraph's
+// numbered from 0, and in MapScoreStores otherwise. An implicit graph that can't list its nodes always gets maps, so if its IDs are dense, DenseScoreStores make planning
+// noticeably faster and easier on the garbage collector:
+//
+//     InitDStar(start, goal, lattice, nil, nil, WithDStarScoreStores(NewDenseScoreStore(n), NewDenseScoreStore(n)))
+func WithDStarScoreStores(g, rhs ScoreStore) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.gScores, ds.rhs = g, rhs
+	}
+}
+
+// Selects the priority queue implementation; the default is BinaryHeapQueue. See QueueKind for which to choose.
+func WithDStarQueue(kind QueueKind) DStarOption {
+	return func(ds *DStarInstance) {
+		ds.queue = kind
+	}
+}
+
+func (ds *DStarInstance) less(a, b dStarNode) bool {
+	return ds.compare(DStarKey{Node: a.Node, K1: a.key[0], K2: a.key[1]}, DStarKey{Node: b.Node, K1: b.key[0], K2: b.key[1]})
+}
+
+// Nodes without a score, e.g. ones added to the graph after initialization, haven't been reached yet, so their scores are infinite (which ScoreStore guarantees)
+func (ds *DStarInstance) g(id int) float64 {
+	return ds.gScores.Get(id)
+}
+
+func (ds *DStarInstance) rhsOf(id int) float64 {
+	return ds.rhs.Get(id)
 }
 
 func (ds *DStarInstance) calculateKey(node Node) key {
-	rhs := ds.rhs[node.ID()]
-	gScore := ds.gScores[node.ID()]
+	rhs := ds.rhsOf(node.ID())
+	gScore := ds.g(node.ID())
+	if ds.anytime != nil && gScore > rhs {
+		return key{rhs + ds.anytime.epsilon*ds.heuristicCost(ds.start, node), rhs}
+	}
 	return key{math.Min(gScore, rhs) + ds.heuristicCost(ds.start, node) + ds.k_m, math.Min(gScore, rhs)}
 }
 
@@ -59,7 +213,20 @@ func (ds *DStarInstance) calculateKey(node Node) key {
 //     ComputeShortestPath()
 //
 // In other words, it's all the lines before the main loop in Main() in the original paper. Essentially a full state initialization.
-func InitDStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Node) float64) *DStarInstance {
+//
+// The graph doesn't have to be able to list its nodes: scores are filled in as nodes are reached (a node that hasn't been is infinitely far from the goal), so D*-Lite works on an
+// implicit graph as long as it can be followed backwards.
+//
+// Any options are applied before the initial search.
+func InitDStar(start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64, options ...DStarOption) *DStarInstance {
+	ds := newDStar(start, goal, graph, Cost, HeuristicCost, options)
+	ds.initialize()
+	return ds
+}
+
+// Builds an instance with empty state, resolving the cost functions and applying the options
+func newDStar(start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64, options []DStarOption) *DStarInstance {
+	visit := successorVisitor(graph, Cost)
 	if Cost == nil {
 		if cgraph, ok := graph.(Coster); ok {
 			Cost = cgraph.Cost
@@ -75,116 +242,454 @@ func InitDStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Nod
 		}
 	}
 
-	u := &dStarPriorityQueue{indexList: make(map[int]int, 0), nodes: make([]dStarNode, 0)}
-	heap.Init(u)
-
 	ds := &DStarInstance{
 		graph:         graph,
 		start:         start,
 		goal:          goal,
 		last:          start,
-		u:             u,
 		k_m:           0.0,
-		gScores:       make(map[int]float64, 0),
-		rhs:           make(map[int]float64, 0),
 		cost:          Cost,
+		visit:         visit,
+		visitPreds:    predecessorVisitor(graph, nil),
 		heuristicCost: HeuristicCost,
+		compare:       DStarLexicographic,
+		equal:         DefaultTolerance,
+	}
+	for _, option := range options {
+		option(ds)
 	}
 
-	for _, node := range graph.NodeList() {
-		ds.rhs[node.ID()] = math.Inf(1)
-		ds.gScores[node.ID()] = math.Inf(1)
+	// Unless WithDStarScoreStores chose them, scores go in slices indexed by ID if the graph's IDs are dense enough, and otherwise in maps. Either way, most of the graph's
+	// nodes end up scored on a search of any length, so the stores are made big enough for them up front rather than grown a node at a time.
+	if ds.gScores == nil {
+		if bound := denseIDBound(graph); bound > 0 {
+			ds.gScores, ds.rhs = newBoundedScoreStore(bound), newBoundedScoreStore(bound)
+		} else {
+			n := nodeCount(graph)
+			ds.gScores, ds.rhs = make(MapScoreStore, n), make(MapScoreStore, n)
+		}
 	}
+	ds.u = newDStarPriorityQueue(ds.queue, ds.less)
+	ds.u.stats = ds.stats
+	ds.u.observer = ds.observer
 
-	ds.rhs[goal.ID()] = 0.0
-	heap.Push(ds.u, dStarNode{Node: goal, key: ds.calculateKey(goal)})
-	ds.computeShortestPath()
 	return ds
 }
 
-func (ds *DStarInstance) updateVertex(node Node) {
-	if node.ID() != ds.goal.ID() {
-		min := math.Inf(1)
-		for _, succ := range ds.graph.Successors(node) {
-			min = math.Min(min, ds.cost(node, succ)+ds.gScores[succ.ID()])
+// Throws away all search state and plans from scratch from the current start to the goal. Score stores and the queue are cleared in place, so their allocated capacity is reused.
+func (ds *DStarInstance) initialize() error {
+	ds.rhs.Clear()
+	ds.gScores.Clear()
+	ds.u.Clear()
+	ds.k_m = 0
+	ds.last = ds.start
+	if ds.anytime != nil {
+		ds.anytime.reset()
+	}
+
+	ds.rhs.Set(ds.goal.ID(), 0.0)
+	ds.u.Push(dStarNode{Node: ds.goal, key: ds.calculateKey(ds.goal)})
+	return ds.computeShortestPath()
+}
+
+// Like InitDStar, but the initial search gives up when ctx is cancelled and returns ctx.Err(). The instance is still returned in that case, and is usable: its queue holds the
+// unfinished work, which the next search (e.g. the next UpdateCtx) picks up where this one stopped. Until then, Step and Peek see an incomplete plan.
+func InitDStarCtx(ctx context.Context, start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64, options ...DStarOption) (*DStarInstance, error) {
+	ds := newDStar(start, goal, graph, Cost, HeuristicCost, options)
+	ds.cancel = newCanceller(ctx)
+	defer func() { ds.cancel = nil }()
+
+	err := ds.initialize()
+	return ds, err
+}
+
+// Discards everything learned during incremental updates and recomputes the plan from scratch for the current start and goal, as though the instance had just been created
+// on the graph in its current state. Cheaper than a new InitDStar since the internal maps keep their capacity.
+func (ds *DStarInstance) Reset() {
+	ds.initialize()
+}
+
+// Starts a new episode on the same graph, planning from newStart to newGoal. Like Reset, this reuses the instance's allocations; the cost functions and options are kept.
+func (ds *DStarInstance) Retarget(newStart, newGoal Node) {
+	ds.start, ds.goal = newStart, newGoal
+	ds.initialize()
+}
+
+// Moves the goal to newGoal, as when chasing a moving target, repairing the plan the way Update does rather than starting over as Retarget would: the old goal's rhs goes
+// back to being worked out from its successors, the new goal's becomes 0, and k_m takes up any distance the agent has moved since the last search, so the queue's keys stay
+// valid. Only nodes whose distance to the goal changed get expanded, so a goal that moves a little costs a little.
+//
+// Every node's score is a distance to the goal, though, so one that jumps somewhere the last search never reached has little to reuse; then the instance is reinitialized, as
+// with Retarget from the current start.
+func (ds *DStarInstance) MoveGoal(newGoal Node) {
+	ds.moveGoal(newGoal)
+}
+
+// Like MoveGoal, but the replanning search gives up when ctx is cancelled and returns ctx.Err(), leaving the unfinished work queued as UpdateCtx does.
+func (ds *DStarInstance) MoveGoalCtx(ctx context.Context, newGoal Node) error {
+	ds.cancel = newCanceller(ctx)
+	defer func() { ds.cancel = nil }()
+
+	return ds.moveGoal(newGoal)
+}
+
+func (ds *DStarInstance) moveGoal(newGoal Node) error {
+	oldGoal := ds.goal
+	if newGoal.ID() == oldGoal.ID() {
+		return nil
+	}
+	ds.goal = newGoal
+	if math.IsInf(ds.g(newGoal.ID()), 1) {
+		return ds.initialize()
+	}
+
+	ds.advance()
+	// The old goal isn't the goal any more, so lookahead works its rhs out like any other node's
+	ds.setVertex(oldGoal, ds.lookahead(oldGoal))
+	ds.setVertex(newGoal, 0)
+
+	return ds.computeShortestPath()
+}
+
+// Accounts for the agent's moves since the last search before the queue's keys change: k_m takes up the distance, so the keys already queued stay lower bounds. An anytime
+// instance rekeys its whole queue before every search instead, so it only has to forget the scores' last plan is still to be trusted.
+func (ds *DStarInstance) advance() {
+	if ds.anytime != nil {
+		ds.anytime.bound = math.Inf(1)
+	} else {
+		ds.k_m += ds.heuristicCost(ds.last, ds.start)
+	}
+	ds.last = ds.start
+}
+
+// Appends node's predecessors to nodes, which is kept to be reused by the next call.
+func (ds *DStarInstance) predecessors(node Node, nodes []Node) []Node {
+	ds.visitPreds(node, func(pred Node, _ float64) bool {
+		nodes = append(nodes, pred)
+		return true
+	})
+	ds.updates = nodes
+
+	return nodes
+}
+
+// Updates every node in nodes, in order. The lookaheads may be computed in parallel (see WithDStarParallelism).
+func (ds *DStarInstance) updateVertices(nodes []Node) {
+	// Lookaheads only read g and setVertex only writes rhs, so all the recomputations can be done before the queue is touched
+	var rhs []float64
+	if ds.workers > 1 && len(nodes) >= ds.minParallel {
+		rhs = ds.parallelLookaheads(nodes)
+	} else {
+		rhs = ds.updatedRHS[:0]
+		for _, node := range nodes {
+			rhs = append(rhs, ds.lookahead(node))
+		}
+		ds.updatedRHS = rhs
+	}
+
+	for i, node := range nodes {
+		ds.setVertex(node, rhs[i])
+	}
+}
+
+// Computes the one step lookahead rhs(node) = min over successors of cost + g. The goal's rhs is fixed at 0.
+func (ds *DStarInstance) lookahead(node Node) float64 {
+	if node.ID() == ds.goal.ID() {
+		return ds.rhsOf(node.ID())
+	}
+
+	if ds.workers > 1 {
+		succs := ds.graph.Successors(node)
+		if len(succs) >= ds.minParallel {
+			return ds.parallelMin(node, succs)
 		}
-		ds.rhs[node.ID()] = min
+		return ds.minOver(node, succs)
 	}
 
-	if math.Abs(ds.gScores[node.ID()]-ds.rhs[node.ID()]) > .000001 {
+	min := math.Inf(1)
+	ds.visit(node, func(succ Node, cost float64) bool {
+		min = math.Min(min, cost+ds.g(succ.ID()))
+		return true
+	})
+	return min
+}
+
+func (ds *DStarInstance) minOver(node Node, succs []Node) float64 {
+	min := math.Inf(1)
+	for _, succ := range succs {
+		min = math.Min(min, ds.cost(node, succ)+ds.g(succ.ID()))
+	}
+	return min
+}
+
+// Stores a freshly computed rhs for node and fixes its place in the queue
+func (ds *DStarInstance) setVertex(node Node, rhs float64) {
+	if ds.stats != nil {
+		ds.stats.VertexUpdates++
+	}
+	ds.rhs.Set(node.ID(), rhs)
+	if ds.observer != nil {
+		ds.observer.OnUpdateVertex(node, ds.g(node.ID()), ds.rhsOf(node.ID()))
+	}
+
+	if !ds.equal(ds.g(node.ID()), ds.rhsOf(node.ID())) {
+		if ds.anytime != nil && ds.anytime.isClosed(node) {
+			ds.u.Remove(node)
+			ds.anytime.incons = append(ds.anytime.incons, node)
+			return
+		}
 		ds.u.Fix(node, ds.calculateKey(node))
 	} else {
 		ds.u.Remove(node)
 	}
 }
 
-func (ds *DStarInstance) computeShortestPath() {
-	for ds.u.Peek().Less(dStarNode{Node: ds.start, key: ds.calculateKey(ds.start)}) || math.Abs(ds.rhs[ds.start.ID()]-ds.gScores[ds.start.ID()]) > .000001 {
+// Whether the search has to go on to vert before the start's estimate can be trusted: its key comes first by the comparator, or K1s that only differ by rounding tie and K2
+// comes first. Costs like sqrt(2) add up to slightly different sums along different paths, and without the tolerance a node whose K1 rounded up would be left queued when it's
+// the one node that would have corrected the start's g-score.
+func (ds *DStarInstance) before(vert, start dStarNode) bool {
+	if ds.less(vert, start) {
+		return true
+	}
+	return ds.equal(vert.key[0], start.key[0]) && vert.key[1] < start.key[1] && !ds.equal(vert.key[1], start.key[1])
+}
 
-		vert := heap.Pop(ds.u).(dStarNode)
-		newKey := ds.calculateKey(vert.Node)
-		if vert.Less(dStarNode{Node: vert.Node, key: newKey}) {
+func (ds *DStarInstance) computeShortestPath() error {
+	ds.pathValid = false
+	if ds.stats != nil {
+		defer ds.stats.searchDone(time.Now())
+	}
+	limit := ds.cancel
+	if ds.budget != (SearchBudget{}) {
+		var ctx context.Context
+		if ds.cancel != nil {
+			ctx = ds.cancel.ctx
+		}
+		limit = ds.budget.canceller(ctx)
+	}
+	if ds.anytime != nil {
+		ds.reopen()
+	}
 
-			heap.Push(ds.u, dStarNode{Node: vert.Node, key: newKey})
+	// An empty queue has top key [inf; inf] in the paper, which is never less than the start's key, and the start can't be inconsistent without being queued
+	for ds.u.Len() > 0 && (ds.before(ds.u.Peek(), dStarNode{Node: ds.start, key: ds.calculateKey(ds.start)}) || !ds.equal(ds.rhsOf(ds.start.ID()), ds.g(ds.start.ID()))) {
 
-		} else if ds.gScores[vert.ID()] > ds.rhs[vert.ID()] {
+		if err := limit.err(); err != nil {
+			ds.interrupted = err
+			return err
+		}
 
-			ds.gScores[vert.ID()] = ds.rhs[vert.ID()]
-			for _, pred := range ds.graph.Predecessors(vert.Node) {
-				ds.updateVertex(pred)
+		vert := ds.u.Pop()
+		newKey := ds.calculateKey(vert.Node)
+		if ds.stats != nil {
+			ds.stats.Pops++
+		}
+		if ds.less(vert, dStarNode{Node: vert.Node, key: newKey}) {
+			ds.u.Push(dStarNode{Node: vert.Node, key: newKey})
+			continue
+		}
+
+		if ds.stats != nil {
+			ds.stats.Expansions++
+		}
+		if ds.g(vert.ID()) > ds.rhsOf(vert.ID()) {
+
+			ds.gScores.Set(vert.ID(), ds.rhsOf(vert.ID()))
+			if ds.anytime != nil {
+				ds.anytime.closed[vert.ID()] = struct{}{}
+			}
+			if ds.observer != nil {
+				ds.observer.OnExpand(vert.Node, ds.g(vert.ID()))
 			}
+			ds.updateVertices(ds.predecessors(vert.Node, ds.updates[:0]))
 
 		} else {
 
-			ds.gScores[vert.ID()] = math.Inf(1)
-			ds.updateVertex(vert.Node)
-			for _, pred := range ds.graph.Predecessors(vert.Node) {
-				ds.updateVertex(pred)
+			ds.gScores.Set(vert.ID(), math.Inf(1))
+			if ds.observer != nil {
+				ds.observer.OnExpand(vert.Node, ds.g(vert.ID()))
 			}
+			ds.updateVertices(ds.predecessors(vert.Node, append(ds.updates[:0], vert.Node)))
 
 		}
 	}
+
+	ds.interrupted = nil
+	if ds.anytime != nil {
+		ds.anytime.bound = ds.anytime.epsilon
+	}
+	if ds.observer != nil {
+		ds.notifyPath()
+	}
+	return nil
 }
 
-// Returns the next action to be taken, or nil and an error if it's determined that no path exists.
+func (ds *DStarInstance) notifyPath() {
+	if cost := ds.g(ds.start.ID()); !math.IsInf(cost, 1) {
+		ds.observer.OnPathFound(append([]Node{ds.start}, ds.PlanAhead()...), cost)
+	}
+}
+
+// Returns the next action to be taken, or nil and an error if it's determined that no path exists. The instance assumes the move is made, so the returned node becomes the new start.
 // Should be called before Update every loop
 func (ds *DStarInstance) Step() (succ Node, err error) {
 	if ds.start.ID() == ds.goal.ID() {
 		return ds.start, nil
-	} else if ds.gScores[ds.start.ID()] == math.Inf(1) {
-		return nil, errors.New("No path exists")
+	} else if ds.g(ds.start.ID()) == math.Inf(1) {
+		if ds.interrupted != nil {
+			return nil, ds.interrupted
+		}
+		return nil, ErrNoPath
 	}
 
+	next := ds.bestSuccessor(ds.start)
+	ds.start = next
+	ds.pathValid = false
+
+	return next, nil
+}
+
+// Returns the successor minimizing cost + g, or nil if every successor is unreachable
+func (ds *DStarInstance) bestSuccessor(node Node) Node {
+	next, _ := ds.bestEdge(node)
+	return next
+}
+
+// Like bestSuccessor, but also returns the cost of the edge to it
+func (ds *DStarInstance) bestEdge(node Node) (next Node, edgeCost float64) {
 	min := math.Inf(1)
-	var next Node
-	for _, succ := range ds.graph.Successors(ds.start) {
-		newMin := math.Min(min, ds.cost(ds.start, succ)+ds.gScores[succ.ID()])
-		if newMin < min {
+	ds.visit(node, func(succ Node, cost float64) bool {
+		if newMin := cost + ds.g(succ.ID()); newMin < min {
 			min = newMin
-			next = succ
+			next, edgeCost = succ, cost
 		}
+		return true
+	})
+
+	return next, edgeCost
+}
+
+// Returns up to the next k moves of the current plan, i.e. the nodes successive calls to Step would return if nothing changed, without moving the agent or changing any state.
+// This is useful for showing the intended route between actual moves. The plan stops early at the goal, and is nil if no path exists. If k <= 0 there's no limit.
+func (ds *DStarInstance) Peek(k int) []Node {
+	if ds.g(ds.start.ID()) == math.Inf(1) {
+		return nil
 	}
 
-	return next, nil
+	plan := make([]Node, 0)
+	visited := map[int]bool{ds.start.ID(): true}
+	for node := ds.start; node.ID() != ds.goal.ID() && (k <= 0 || len(plan) < k); {
+		node = ds.bestSuccessor(node)
+		// The g-scores are consistent along the plan after computeShortestPath, but guard against following a stale cycle forever
+		if node == nil || visited[node.ID()] {
+			break
+		}
+		visited[node.ID()] = true
+		plan = append(plan, node)
+	}
+
+	return plan
+}
+
+// Returns the whole remaining plan, from the move after the current position to the goal. Equivalent to Peek(0).
+func (ds *DStarInstance) PlanAhead() []Node {
+	return ds.Peek(0)
+}
+
+// Returns the whole path the instance currently believes is shortest, from the current position (the start, or the last node Step returned) to the goal, both included, and
+// what it costs. This is the plan PlanAhead gives, with the position in front and its cost added up, for drawing or simulating the route rather than just taking its next step.
+// If no path exists the error is ErrNoPath, or, if the last search was cut short, why it was (see WithDStarBudget and InitDStarCtx).
+//
+// The path is worked out the first time it's asked for after a Step or a search, and kept until the next one, so asking again is cheap. The slice returned is the caller's own.
+func (ds *DStarInstance) Path() ([]Node, float64, error) {
+	if !ds.pathValid {
+		ds.path, ds.pathCost, ds.pathErr = ds.walkPath()
+		ds.pathValid = true
+	}
+
+	return append([]Node(nil), ds.path...), ds.pathCost, ds.pathErr
+}
+
+// Follows the best successors from the start to the goal, adding up the costs of the edges followed.
+func (ds *DStarInstance) walkPath() ([]Node, float64, error) {
+	noPath := ErrNoPath
+	if ds.interrupted != nil {
+		noPath = ds.interrupted
+	}
+	if ds.start.ID() == ds.goal.ID() {
+		return []Node{ds.start}, 0, nil
+	} else if ds.g(ds.start.ID()) == math.Inf(1) {
+		return nil, 0, noPath
+	}
+
+	path := []Node{ds.start}
+	cost := 0.0
+	visited := map[int]bool{ds.start.ID(): true}
+	for node := ds.start; node.ID() != ds.goal.ID(); {
+		next, edgeCost := ds.bestEdge(node)
+		// As in Peek, a stale cycle (or a dead end) means the plan isn't finished, which only an interrupted search leaves behind
+		if next == nil || visited[next.ID()] {
+			return nil, 0, noPath
+		}
+		visited[next.ID()] = true
+		cost += edgeCost
+		path = append(path, next)
+		node = next
+	}
+
+	return path, cost, nil
 }
 
 // Updates D*-Lite if new information has been discovered or the graph has changed in any way. Should be called after each call of Step()
-// This is a no-op if changedEdgeCosts is nil or its len is 0.
+// This is a no-op if changedEdgeCosts is nil or its len is 0, unless the last search was stopped early (see WithDStarBudget), in which case it's resumed.
+//
+// Added and removed edges can be passed along with edges whose cost changed, as described for DStarGraph.
 func (ds *DStarInstance) Update(cost func(Node, Node) float64, changedEdgeCosts []Edge) {
+	ds.update(cost, changedEdgeCosts)
+}
+
+// Like Update, but the replanning search gives up when ctx is cancelled and returns ctx.Err(). As with InitDStarCtx, the unfinished work stays queued for the next search.
+// If the changes were empty but an earlier search was cut short, this finishes it.
+func (ds *DStarInstance) UpdateCtx(ctx context.Context, cost func(Node, Node) float64, changedEdgeCosts []Edge) error {
+	ds.cancel = newCanceller(ctx)
+	defer func() { ds.cancel = nil }()
+
+	return ds.update(cost, changedEdgeCosts)
+}
+
+func (ds *DStarInstance) update(cost func(Node, Node) float64, changedEdgeCosts []Edge) error {
 	if changedEdgeCosts == nil || len(changedEdgeCosts) == 0 {
-		return
+		if ds.interrupted != nil {
+			return ds.computeShortestPath()
+		}
+		return nil
 	}
 
 	if cost != nil {
 		ds.cost = cost
+		ds.visit = successorVisitor(ds.graph, cost)
+	}
+	ds.advance()
+
+	// An edge's cost (or existence) only enters into the estimate of its head, but in an undirected graph every edge goes both ways.
+	// A sensor sweep tends to report many edges around the same few nodes, so each affected node is only recomputed (and fixed in the queue) once
+	affected := make([]Node, 0, len(changedEdgeCosts))
+	seen := make(map[int]struct{}, len(changedEdgeCosts))
+	visit := func(node Node) {
+		if _, ok := seen[node.ID()]; !ok {
+			seen[node.ID()] = struct{}{}
+			affected = append(affected, node)
+		}
 	}
-	ds.k_m += ds.heuristicCost(ds.last, ds.start)
-	ds.last = ds.start
-
 	for _, edge := range changedEdgeCosts {
-		ds.updateVertex(edge.Head())
+		visit(edge.Head())
+		if !isDirected(ds.graph) {
+			visit(edge.Tail())
+		}
 	}
-	ds.computeShortestPath()
+	ds.updateVertices(affected)
+
+	return ds.computeShortestPath()
 }
 
 // Runs D*-Lite in its entirety on an appropriate graph. What is D*-Lite? It's an incremental heuristic lifelong planning search. What this means is that
@@ -196,18 +701,29 @@ func (ds *DStarInstance) Update(cost func(Node, Node) float64, changedEdgeCosts
 // and uses the new one. The big trick in D*-lite is that since it has memory, this is significantly cheaper than rerunning A* at every step (though there are edge cases where performance may suffer).
 //
 // D*-Lite is a modification of LPA* (Lifelong Planning A*) that has the same behavior as a little-used algorithm known as D*. It is guaranteed to run as fast or faster than D*, and is generally
-// known to run faster than LPA* and other similar lifelong planning algorithms.
+// known to run faster than LPA* and other similar lifelong planning algorithms. LPA* itself, for when the start doesn't move, is InitLPAStar.
 //
 // It is used often in real world robotics path planning -- a modification of this algorithm known as Field D* (which allows more degrees of freedom in movement) is used in the Mars rovers Spirit and Opportunity.
 // It was also notably used for traffic path planning in the recent reboot of the SimCity franchise.
 //
 // As with other algorithms with cost function arguments in this package, Cost and HeuristicCost are optional, and if absent will default to the graph's Cost/HeuristicCost functions (if present), and finally
-// to UniformCost and NullHeauristic respectively.
+// to UniformCost and NullHeauristic respectively. For nodes that know where they are, EuclideanHeuristic, ManhattanHeuristic, OctileHeuristic and HaversineHeuristic are ready made.
 //
 // [1] http://www.aaai.org/Papers/AAAI/2002/AAAI02-072.pdf
 func DStarLite(start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node, Node) float64) error {
-	ds := InitDStar(start, goal, graph, Cost, HeuristicCost) // InitDStar does s_last = s_start and computeShortestPath for us
+	return DStarLiteCtx(context.Background(), start, goal, graph, Cost, HeuristicCost)
+}
+
+// Like DStarLite, but stops with ctx.Err() if ctx is cancelled, whether during a search or between moves.
+func DStarLiteCtx(ctx context.Context, start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node, Node) float64) error {
+	ds, err := InitDStarCtx(ctx, start, goal, graph, Cost, HeuristicCost) // InitDStar does s_last = s_start and computeShortestPath for us
+	if err != nil {
+		return err
+	}
 	for ds.start.ID() != ds.goal.ID() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		next, err := ds.Step()
 		if err != nil {
 			return err
@@ -215,7 +731,9 @@ func DStarLite(start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node
 
 		graph.Move(next)
 		newCost, edges := graph.ChangedEdges()
-		ds.Update(newCost, edges)
+		if err := ds.UpdateCtx(ctx, newCost, edges); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -227,6 +745,11 @@ func DStarLite(start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node
 // If an error is encountered, it will be sent over the done channel. If D*-lite exits successfully the done channel will be closed with no error written to it.
 //
 // D*-lite is initialized upon call, albeit in the new goroutine. However, the first step/move/update cycle is not performed until a signal is received.
+//
+// See DStarService for a version that reports its moves and plans, and can be paused or told about changes to the graph.
+//
+// The goroutine reads graph whenever it's stepping, so the graph mustn't be changed from anywhere else meanwhile. Either wrap it with SynchronizedDStar and make changes
+// through Mutate, or use a DStarService and make them in a DStarChange's Apply, on the service's own goroutine.
 func SynchronizedDStarLite(start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node, Node) float64, step <-chan struct{}, done chan<- error) {
 	go func() {
 		ds := InitDStar(start, goal, graph, Cost, HeuristicCost) // InitDStar does s_last = s_start and computeShortestPath for us
@@ -254,67 +777,61 @@ func SynchronizedDStarLite(start, goal Node, graph DStarGraph, Cost, HeuristicCo
 
 type key [2]float64
 
-func (k1 key) Less(k2 key) bool {
-	return k1[0] < k2[0] && k1[1] < k2[1]
-}
-
 type dStarNode struct {
 	Node
 	key
 }
 
-func (ds1 dStarNode) Less(ds2 dStarNode) bool {
-	return ds1.key.Less(ds2.key)
-}
-
+// D*-Lite's open list: a PriorityQueue keyed on key, which also keeps the instance's stats and notifies its observer
 type dStarPriorityQueue struct {
-	indexList map[int]int
-	nodes     []dStarNode
+	PriorityQueue
+	stats    *SearchStats
+	observer SearchObserver
 }
 
-func (pq *dStarPriorityQueue) Less(i, j int) bool {
-	return pq.nodes[i].Less(pq.nodes[j])
+func newDStarPriorityQueue(kind QueueKind, less func(a, b dStarNode) bool) *dStarPriorityQueue {
+	return &dStarPriorityQueue{PriorityQueue: NewPriorityQueue(kind, func(a, b HeapItem) bool {
+		return less(dStarNode{Node: a.Node, key: a.Key.(key)}, dStarNode{Node: b.Node, key: b.Key.(key)})
+	})}
 }
 
-func (pq *dStarPriorityQueue) Swap(i, j int) {
-	pq.indexList[pq.nodes[i].ID()] = j
-	pq.indexList[pq.nodes[j].ID()] = i
-
-	pq.nodes[i], pq.nodes[j] = pq.nodes[j], pq.nodes[i]
-}
-
-func (pq *dStarPriorityQueue) Len() int {
-	return len(pq.nodes)
-}
-
-func (pq *dStarPriorityQueue) Push(x interface{}) {
-	node := x.(dStarNode)
-	pq.nodes = append(pq.nodes, node)
-	pq.indexList[node.ID()] = len(pq.nodes) - 1
+func (pq *dStarPriorityQueue) Push(node dStarNode) {
+	if pq.stats != nil {
+		pq.stats.Pushes++
+	}
+	if pq.observer != nil {
+		pq.observer.OnKeyChange(node.Node, node.key[0], node.key[1])
+	}
+	pq.PriorityQueue.Push(node.Node, node.key)
 }
 
-func (pq *dStarPriorityQueue) Pop() interface{} {
-	x := pq.nodes[len(pq.nodes)-1]
-	pq.nodes = pq.nodes[:len(pq.nodes)-1]
-	delete(pq.indexList, x.ID())
-
-	return x
+func (pq *dStarPriorityQueue) Pop() dStarNode {
+	item := pq.PriorityQueue.Pop()
+	return dStarNode{Node: item.Node, key: item.Key.(key)}
 }
 
 func (pq *dStarPriorityQueue) Peek() dStarNode {
-	return pq.nodes[len(pq.nodes)-1]
+	item := pq.PriorityQueue.Peek()
+	return dStarNode{Node: item.Node, key: item.Key.(key)}
 }
 
+// Updates the node's key, inserting it if it isn't queued yet
 func (pq *dStarPriorityQueue) Fix(node Node, newKey key) {
-	if i, ok := pq.indexList[node.ID()]; ok {
-		pq.nodes[i].key = newKey
-		heap.Fix(pq, i)
+	if !pq.PriorityQueue.Fix(node, newKey) {
+		pq.Push(dStarNode{Node: node, key: newKey})
+		return
+	}
+
+	if pq.stats != nil {
+		pq.stats.Fixes++
+	}
+	if pq.observer != nil {
+		pq.observer.OnKeyChange(node, newKey[0], newKey[1])
 	}
 }
 
 func (pq *dStarPriorityQueue) Remove(node Node) {
-	if i, ok := pq.indexList[node.ID()]; ok {
-		heap.Remove(pq, i)
-		delete(pq.indexList, node.ID())
+	if pq.PriorityQueue.Remove(node) && pq.stats != nil {
+		pq.stats.Removes++
 	}
 }
@@ -0,0 +1,506 @@
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// Runs D*-Lite step by step, returning the path walked
+func walkDStar(t *testing.T, world *graph.RevealingTileGraph, goal graph.Node, options ...graph.DStarOption) []graph.Node {
+	start := world.Position()
+	ds := graph.InitDStar(start, goal, world, nil, nil, options...)
+	path := []graph.Node{start}
+	for world.Position().ID() != goal.ID() {
+		if len(path) > 10000 {
+			t.Fatal("D*-Lite is going around in circles")
+		}
+		next, err := ds.Step()
+		if err != nil {
+			t.Fatal("D*-Lite failed:", err)
+		}
+		world.Move(next)
+		path = append(path, next)
+		ds.Update(world.ChangedEdges())
+	}
+
+	return path
+}
+
+func TestDStarLiteRevealedMaze(t *testing.T) {
+	truth := graph.GenerateMaze(10, 10, graph.RecursiveBacktracker, rand.New(rand.NewSource(3)))
+	start, goal := truth.CoordsToNode(1, 1), truth.CoordsToNode(19, 19)
+
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+	if err := graph.DStarLite(start, goal, world, nil, nil); err != nil {
+		t.Fatal("D*-Lite failed to solve revealed maze:", err)
+	}
+	if world.Position().ID() != goal.ID() {
+		t.Error("D*-Lite stopped before the goal at", world.Position())
+	}
+
+	// With full knowledge from the start, D*-Lite walks a shortest path
+	optimal, cost, _ := graph.AStar(start, goal, truth, nil, nil)
+	rows, cols := truth.Dimensions()
+	for _, less := range []func(a, b graph.DStarKey) bool{graph.DStarLexicographic, graph.DStarTieBreakByID, graph.DStarTieBreakLargerG} {
+		known := graph.NewRevealingTileGraph(truth, start, rows+cols)
+		path := walkDStar(t, known, goal, graph.WithDStarComparator(less))
+		if len(path) != len(optimal) || !graph.IsPath(path, truth) {
+			t.Errorf("D*-Lite with full knowledge took a path of %d nodes, optimal has %d (cost %v)", len(path), len(optimal), cost)
+		}
+	}
+}
+
+func TestDStarLiteObstacleFields(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		start, goal := graph.GonumNode(0), graph.GonumNode(40*40-1)
+		truth := graph.RandomObstacleField(40, 40, 0.35, start, goal, rand.New(rand.NewSource(seed)))
+		path := walkDStar(t, graph.NewRevealingTileGraph(truth, start, 2), goal, graph.WithDStarComparator(graph.DStarTieBreakByID))
+		if !graph.IsPath(path, truth) {
+			t.Errorf("D*-Lite walked through an obstacle with seed %d", seed)
+		}
+	}
+}
+
+func TestDStarLiteNoPath(t *testing.T) {
+	tg := graph.NewTileGraph(3, 3, true)
+	for row := 0; row < 3; row++ {
+		tg.SetPassability(row, 1, false)
+	}
+	if err := graph.DStarLite(graph.GonumNode(0), graph.GonumNode(2), graph.NewRevealingTileGraph(tg, graph.GonumNode(0), 5), nil, nil); err == nil {
+		t.Error("D*-Lite found a path through a wall")
+	}
+}
+
+func TestDStarLiteTolerance(t *testing.T) {
+	start, mid, goal := graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(2)
+	for _, test := range []struct {
+		name     string
+		equal    graph.Tolerance
+		expected graph.Node
+	}{
+		{"default", graph.DefaultTolerance, goal},
+		// An absolute tolerance far above the costs misses the change
+		{"absolute", graph.AbsoluteTolerance(1e-6), mid},
+	} {
+		g := graph.NewGonumGraph(true)
+		g.AddNode(start, nil)
+		g.AddNode(mid, nil)
+		for _, e := range []struct {
+			h, t graph.Node
+			cost float64
+		}{{start, mid, 1e-9}, {mid, goal, 1e-9}, {start, goal, 3e-9}} {
+			g.AddEdge(graph.GonumEdge{H: e.h, T: e.t})
+			g.SetEdgeCost(graph.GonumEdge{H: e.h, T: e.t}, e.cost)
+		}
+
+		ds := graph.InitDStar(start, goal, g, nil, nil, graph.WithDStarTolerance(test.equal))
+		g.SetEdgeCost(graph.GonumEdge{H: mid, T: goal}, 1e-8)
+		ds.Update(g.Cost, []graph.Edge{graph.GonumEdge{H: mid, T: goal}})
+		if next, _ := ds.Step(); next.ID() != test.expected.ID() {
+			t.Errorf("With %s tolerance D*-Lite moved to %v, expected %v", test.name, next, test.expected)
+		}
+	}
+}
+
+func TestDStarPeek(t *testing.T) {
+	truth := graph.GenerateMaze(6, 6, graph.PrimMaze, rand.New(rand.NewSource(5)))
+	start, goal := truth.CoordsToNode(1, 1), truth.CoordsToNode(11, 11)
+	ds := graph.InitDStar(start, goal, truth, nil, nil)
+
+	plan := ds.PlanAhead()
+	optimal, _, _ := graph.AStar(start, goal, truth, nil, nil)
+	if len(plan) != len(optimal)-1 || plan[len(plan)-1].ID() != goal.ID() {
+		t.Fatalf("Plan has %d moves ending at %v, expected %d ending at the goal", len(plan), plan[len(plan)-1], len(optimal)-1)
+	}
+	if !graph.IsPath(append([]graph.Node{start}, plan...), truth) {
+		t.Error("Plan isn't a path")
+	}
+
+	ahead := ds.Peek(3)
+	if len(ahead) != 3 {
+		t.Fatalf("Peek(3) returned %d moves", len(ahead))
+	}
+	for i, node := range ahead {
+		if next, _ := ds.Step(); next.ID() != node.ID() || plan[i].ID() != node.ID() {
+			t.Errorf("Move %d is %v, but Peek predicted %v", i, next, node)
+		}
+	}
+	if rest := ds.PlanAhead(); len(rest) != len(plan)-3 {
+		t.Errorf("Remaining plan has %d moves after 3 steps, expected %d", len(rest), len(plan)-3)
+	}
+}
+
+func TestDStarPath(t *testing.T) {
+	// A ladder: two rows of nodes, 0-4 and 5-9, with rungs between them
+	g := graph.NewGonumGraph(false)
+	for i := 0; i < 10; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for i := 0; i < 4; i++ {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(i + 1)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i + 5), T: graph.GonumNode(i + 6)})
+		g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(i + 5), T: graph.GonumNode(i + 6)}, 2)
+	}
+	for i := 0; i < 5; i++ {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(i + 5)})
+	}
+
+	start, goal := graph.GonumNode(5), graph.GonumNode(9)
+	ds := graph.InitDStar(start, goal, g, nil, nil)
+	path, cost, err := ds.Path()
+	if err != nil || cost != 6 || !reflect.DeepEqual(nodeIDs(path), []int{5, 0, 1, 2, 3, 4, 9}) {
+		t.Fatalf("Expected the path along the cheap row, 5 0 1 2 3 4 9 costing 6, got %v costing %v (%v)", nodeIDs(path), cost, err)
+	}
+	path[0] = graph.GonumNode(42)
+	if again, _, _ := ds.Path(); again[0].ID() != 5 {
+		t.Errorf("Changing the returned path changed the next one, which starts at %v", again[0])
+	}
+
+	ds.Step()
+	if path, cost, _ := ds.Path(); cost != 5 || path[0].ID() != 0 {
+		t.Errorf("After a step, expected the path from 0 costing 5, got %v costing %v", nodeIDs(path), cost)
+	}
+
+	rung := graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)}
+	g.SetEdgeCost(rung, 10)
+	ds.Update(nil, []graph.Edge{rung})
+	// There are two ways round costing the same, and which one is taken depends on the order the graph lists successors in
+	want, wantCost, _ := graph.AStar(graph.GonumNode(0), goal, g, nil, nil)
+	path, cost, err = ds.Path()
+	sum := 0.0
+	for i := 1; i < len(path); i++ {
+		if !g.IsSuccessor(path[i-1], path[i]) {
+			t.Fatalf("The path %v goes from %v to %v, which isn't an edge", nodeIDs(path), path[i-1], path[i])
+		}
+		sum += g.Cost(path[i-1], path[i])
+	}
+	if err != nil || cost != wantCost || sum != cost || path[0].ID() != 0 || path[len(path)-1].ID() != goal.ID() {
+		t.Errorf("After making 1-2 dear, expected a path like %v costing %v, got %v costing %v (%v)", nodeIDs(want), wantCost, nodeIDs(path), cost, err)
+	}
+
+	g.RemoveNode(graph.GonumNode(9))
+	g.RemoveNode(graph.GonumNode(4))
+	g.AddNode(graph.GonumNode(9), nil)
+	ds = graph.InitDStar(graph.GonumNode(0), goal, g, nil, nil)
+	if path, _, err := ds.Path(); path != nil || err != graph.ErrNoPath {
+		t.Errorf("With the goal cut off, expected no path and ErrNoPath, got %v (%v)", nodeIDs(path), err)
+	}
+}
+
+func TestDStarRetarget(t *testing.T) {
+	truth := graph.GenerateMaze(6, 6, graph.RecursiveBacktracker, rand.New(rand.NewSource(7)))
+	corners := []graph.Node{truth.CoordsToNode(1, 1), truth.CoordsToNode(1, 11), truth.CoordsToNode(11, 11), truth.CoordsToNode(11, 1)}
+
+	ds := graph.InitDStar(corners[0], corners[1], truth, nil, nil)
+	for i := range corners {
+		start, goal := corners[i], corners[(i+1)%len(corners)]
+		if i > 0 {
+			ds.Retarget(start, goal)
+		}
+
+		optimal, _, _ := graph.AStar(start, goal, truth, nil, nil)
+		if plan := ds.PlanAhead(); len(plan) != len(optimal)-1 || plan[len(plan)-1].ID() != goal.ID() {
+			t.Errorf("Retargeted plan from %v to %v has %d moves, expected %d", start, goal, len(plan), len(optimal)-1)
+		}
+	}
+
+	ds.Step()
+	ds.Reset()
+	start, goal := corners[3], corners[0]
+	optimal, _, _ := graph.AStar(start, goal, truth, nil, nil)
+	if plan := ds.PlanAhead(); len(plan) != len(optimal)-2 {
+		t.Errorf("Plan after a step and a reset has %d moves, expected %d", len(plan), len(optimal)-2)
+	}
+}
+
+func TestDStarMarshal(t *testing.T) {
+	start, goal := graph.GonumNode(0), graph.GonumNode(30*30-1)
+	truth := graph.RandomObstacleField(30, 30, 0.3, start, goal, rand.New(rand.NewSource(2)))
+	world := graph.NewRevealingTileGraph(truth, start, 2)
+
+	ds := graph.InitDStar(start, goal, world, nil, nil)
+	for i := 0; i < 10; i++ {
+		next, _ := ds.Step()
+		world.Move(next)
+		ds.Update(world.ChangedEdges())
+	}
+
+	data, err := ds.Marshal()
+	if err != nil {
+		t.Fatal("Couldn't marshal D* state:", err)
+	}
+	restored, err := graph.UnmarshalDStar(data, world, nil, nil)
+	if err != nil {
+		t.Fatal("Couldn't unmarshal D* state:", err)
+	}
+
+	if a, b := ds.PlanAhead(), restored.PlanAhead(); !reflect.DeepEqual(a, b) {
+		t.Fatalf("Restored plan differs:\n%v\n%v", a, b)
+	}
+
+	// Keep going with both, revealing the same things to each
+	for world.Position().ID() != goal.ID() {
+		next, err := ds.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other, _ := restored.Step(); other.ID() != next.ID() {
+			t.Fatalf("Restored instance moved to %v, original to %v", other, next)
+		}
+		world.Move(next)
+		cost, edges := world.ChangedEdges()
+		ds.Update(cost, edges)
+		restored.Update(cost, edges)
+	}
+
+	if _, err := graph.UnmarshalDStar(data, graph.NewTileGraph(2, 2, true), nil, nil); err == nil {
+		t.Error("Restored D* state against the wrong graph")
+	}
+}
+
+func TestDStarStats(t *testing.T) {
+	start, goal := graph.GonumNode(0), graph.GonumNode(30*30-1)
+	truth := graph.RandomObstacleField(30, 30, 0.3, start, goal, rand.New(rand.NewSource(4)))
+	world := graph.NewRevealingTileGraph(truth, start, 2)
+
+	if stats := graph.InitDStar(start, goal, world, nil, nil).Stats(); stats != (graph.SearchStats{}) {
+		t.Error("Stats collected without WithDStarStats:", stats)
+	}
+
+	ds := graph.InitDStar(start, goal, world, nil, nil, graph.WithDStarStats())
+	initial := ds.Stats()
+	if initial.Searches != 1 || initial.Expansions == 0 || initial.Pushes == 0 || initial.VertexUpdates == 0 {
+		t.Errorf("Implausible stats for the initial search: %+v", initial)
+	}
+	if initial.Pops < initial.Expansions || initial.SearchTime != initial.LastSearch {
+		t.Errorf("Inconsistent stats for the initial search: %+v", initial)
+	}
+
+	for world.Position().ID() != goal.ID() {
+		next, err := ds.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		world.Move(next)
+		ds.Update(world.ChangedEdges())
+	}
+	if final := ds.Stats(); final.Searches <= initial.Searches || final.Expansions <= initial.Expansions || final.SearchTime < initial.SearchTime {
+		t.Errorf("Replanning didn't add to the stats: %+v then %+v", initial, final)
+	}
+}
+
+type recordingObserver struct {
+	graph.NullObserver
+	expanded []graph.Node
+	keys     int
+	paths    [][]graph.Node
+	costs    []float64
+}
+
+func (r *recordingObserver) OnExpand(node graph.Node, g float64) {
+	r.expanded = append(r.expanded, node)
+}
+
+func (r *recordingObserver) OnKeyChange(node graph.Node, k1, k2 float64) {
+	r.keys++
+}
+
+func (r *recordingObserver) OnPathFound(path []graph.Node, cost float64) {
+	r.paths = append(r.paths, path)
+	r.costs = append(r.costs, cost)
+}
+
+func TestDStarObserver(t *testing.T) {
+	start, goal := graph.GonumNode(0), graph.GonumNode(20*20-1)
+	truth := graph.RandomObstacleField(20, 20, 0.3, start, goal, rand.New(rand.NewSource(6)))
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+
+	observer := &recordingObserver{}
+	ds := graph.InitDStar(start, goal, world, nil, nil, graph.WithDStarObserver(observer), graph.WithDStarStats())
+	if len(observer.paths) != 1 || len(observer.paths[0]) != len(ds.PlanAhead())+1 || observer.paths[0][0].ID() != start.ID() {
+		t.Fatalf("Initial search reported paths %v", observer.paths)
+	}
+	if observer.costs[0] != float64(len(observer.paths[0])-1) {
+		t.Errorf("Initial path of %d nodes reported with cost %v", len(observer.paths[0]), observer.costs[0])
+	}
+
+	for world.Position().ID() != goal.ID() {
+		next, err := ds.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		world.Move(next)
+		ds.Update(world.ChangedEdges())
+	}
+
+	stats := ds.Stats()
+	if len(observer.expanded) != stats.Expansions {
+		t.Errorf("Observed %d expansions, stats counted %d", len(observer.expanded), stats.Expansions)
+	}
+	if observer.keys != stats.Pushes+stats.Fixes {
+		t.Errorf("Observed %d key changes, stats counted %d pushes and %d fixes", observer.keys, stats.Pushes, stats.Fixes)
+	}
+	if len(observer.paths) != stats.Searches {
+		t.Errorf("Observed %d paths from %d searches", len(observer.paths), stats.Searches)
+	}
+}
+
+func TestDStarParallel(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.GnmRandomGraph(g, 300, 6000, false, rand.New(rand.NewSource(1)))
+	for _, e := range g.EdgeList() {
+		g.SetEdgeCost(e, float64(1+(e.Head().ID()*7+e.Tail().ID()*13)%10))
+	}
+	start, goal := graph.GonumNode(0), graph.GonumNode(299)
+
+	// GonumGraph lists successors in random order, so break ties by ID to make the expansion order comparable
+	byID := graph.WithDStarComparator(graph.DStarTieBreakByID)
+	sequential := graph.InitDStar(start, goal, g, nil, nil, graph.WithDStarStats(), byID)
+	parallel := graph.InitDStar(start, goal, g, nil, nil, graph.WithDStarStats(), byID, graph.WithDStarParallelism(4, 8))
+	planCost := func(plan []graph.Node) float64 {
+		cost, prev := 0.0, graph.Node(start)
+		for _, node := range plan {
+			cost += g.Cost(prev, node)
+			prev = node
+		}
+		return cost
+	}
+	if a, b := sequential.PlanAhead(), parallel.PlanAhead(); planCost(a) != planCost(b) {
+		t.Errorf("Parallel plan has a different cost:\n%v\n%v", a, b)
+	}
+	if a, b := sequential.Stats(), parallel.Stats(); a.Expansions != b.Expansions || a.VertexUpdates != b.VertexUpdates {
+		t.Errorf("Parallel search did different work: %+v vs %+v", a, b)
+	}
+}
+
+// Measures D*-Lite's initial search on random graphs of increasing degree, sequentially and in parallel, to find where parallelism starts paying off:
+//
+//     go test -run NONE -bench DStarParallel -cpu 4
+func BenchmarkDStarParallel(b *testing.B) {
+	for _, degree := range []int{16, 64, 256} {
+		n := 1024
+		g := graph.NewGonumGraph(false)
+		graph.GnmRandomGraph(g, n, n*degree/2, false, rand.New(rand.NewSource(1)))
+		for _, workers := range []int{1, runtime.GOMAXPROCS(0)} {
+			b.Run(fmt.Sprintf("degree=%d/workers=%d", degree, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					graph.InitDStar(graph.GonumNode(0), graph.GonumNode(n-1), g, nil, nil, graph.WithDStarParallelism(workers, degree/2))
+				}
+			})
+		}
+	}
+}
+
+func TestDStarTopologyChanges(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.PathGraph(g, 5, false)
+	start, goal := graph.GonumNode(0), graph.GonumNode(4)
+	ds := graph.InitDStar(start, goal, g, nil, nil)
+	if next, _ := ds.Step(); next.ID() != 1 {
+		t.Fatal("First move was to", next)
+	}
+
+	// A shortcut through a brand new node, with the edge to the goal reported from the goal's side
+	shortcut := graph.GonumNode(10)
+	g.AddNode(shortcut, []graph.Node{graph.GonumNode(1), goal})
+	ds.Update(nil, []graph.Edge{graph.GonumEdge{H: graph.GonumNode(1), T: shortcut}, graph.GonumEdge{H: goal, T: shortcut}})
+	if plan := ds.PlanAhead(); len(plan) != 2 || plan[0].ID() != shortcut.ID() {
+		t.Fatal("Plan doesn't take the new shortcut:", plan)
+	}
+	ds.Step()
+
+	// Now the shortcut is closed again
+	g.RemoveEdge(graph.GonumEdge{H: shortcut, T: goal})
+	ds.Update(nil, []graph.Edge{graph.GonumEdge{H: shortcut, T: goal}})
+	if plan := ds.PlanAhead(); len(plan) != 4 || plan[0].ID() != 1 {
+		t.Error("Plan doesn't go back around the removed shortcut:", plan)
+	}
+
+	// And removing a node on the only path leaves no path
+	g.RemoveNode(graph.GonumNode(2))
+	ds.Update(nil, []graph.Edge{graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)}, graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(3)}})
+	if _, err := ds.Step(); err == nil {
+		t.Error("Found a path through a removed node")
+	}
+}
+
+// Plans across an obstacle field that's unknown apart from the start's surroundings, then reveals a large area in the middle at once, as a long range sensor sweep would. Several
+// of the reported edges share each affected node.
+func sensorSweep(size, radius int) (*graph.DStarInstance, []graph.Edge) {
+	start, goal := graph.GonumNode(0), graph.GonumNode(size*size-1)
+	truth := graph.RandomObstacleField(size, size, 0.3, start, goal, rand.New(rand.NewSource(1)))
+	world := graph.NewRevealingTileGraph(truth, start, radius)
+	ds := graph.InitDStar(start, goal, world, nil, nil, graph.WithDStarStats())
+
+	world.Move(truth.CoordsToNode(size/2, size/2))
+	_, edges := world.ChangedEdges()
+	return ds, edges
+}
+
+func TestDStarBatchedUpdate(t *testing.T) {
+	// Reporting every edge of a grid without changing anything: each node is the head of up to 8 reported edges (4 neighbors, both directions), but should be recomputed once
+	tg := graph.NewTileGraph(20, 20, true)
+	ds := graph.InitDStar(tg.CoordsToNode(0, 0), tg.CoordsToNode(19, 19), tg, nil, nil, graph.WithDStarStats())
+	before := ds.Stats()
+
+	ds.Update(nil, tg.EdgeList())
+	after := ds.Stats()
+	if updates := after.VertexUpdates - before.VertexUpdates; updates != 400 {
+		t.Errorf("Update recomputed %d vertices, expected each of the 400 once", updates)
+	}
+	if after.Expansions != before.Expansions {
+		t.Errorf("Update expanded %d nodes although nothing changed", after.Expansions-before.Expansions)
+	}
+}
+
+func BenchmarkDStarSensorSweep(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ds, edges := sensorSweep(200, 25)
+		b.StartTimer()
+		ds.Update(nil, edges)
+	}
+}
+
+func TestDStarMoveGoal(t *testing.T) {
+	g := randomWeightedGraph(150, 0.04, 11)
+	src := rand.New(rand.NewSource(5))
+	start, goal := graph.Node(graph.GonumNode(0)), graph.Node(graph.GonumNode(1))
+	ds := graph.InitDStar(start, goal, g, nil, nil)
+
+	for i := 0; i < 30; i++ {
+		// Mostly a neighbour of the current goal, as a target that wanders off would be, but sometimes anywhere at all
+		if succs := g.Successors(goal); len(succs) != 0 && i%4 != 0 {
+			goal = succs[src.Intn(len(succs))]
+		} else {
+			goal = graph.GonumNode(src.Intn(150))
+		}
+		if i%2 == 0 {
+			ds.MoveGoal(goal)
+		} else if err := ds.MoveGoalCtx(context.Background(), goal); err != nil {
+			t.Fatalf("Unexpected error moving the goal to %v: %v", goal, err)
+		}
+
+		_, want, _ := graph.AStar(start, goal, g, nil, nil)
+		path, cost, err := ds.Path()
+		if want == 0 && start.ID() != goal.ID() {
+			if err != graph.ErrNoPath {
+				t.Errorf("With no path from %v to %v, expected ErrNoPath, got %v costing %v (%v)", start, goal, nodeIDs(path), cost, err)
+			}
+			continue
+		}
+		if err != nil || math.Abs(cost-want) > 1e-9 || path[len(path)-1].ID() != goal.ID() {
+			t.Fatalf("After moving the goal to %v, expected a path from %v costing %v, got %v costing %v (%v)", goal, start, want, nodeIDs(path), cost, err)
+		}
+
+		if i%3 == 0 && len(path) > 1 {
+			start, _ = ds.Step()
+		}
+	}
+}
@@ -0,0 +1,192 @@
+// Package dstarrpc runs D*-Lite planners for remote agents over a bidirectional stream, SynchronizedDStarLite's pattern across process boundaries: the client streams in graph
+// changes and the moves its agent makes, and the server streams back the next move to make after each. The protocol is the DStarPlanner service of dstarrpc.proto.
+//
+// The types here mirror its messages, and Server.Plan serves any stream of them, so the package has no dependencies outside the standard library. To serve it over gRPC, generate
+// the service from dstarrpc.proto with protoc-gen-go-grpc and implement its Plan handler by calling Server.Plan with a PlanStream that converts between the generated messages
+// and these; each field maps across one for one.
+package dstarrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/gonum/graph"
+)
+
+// Returned by Server.Plan when a client sends a message that makes no sense at that point, or an empty one.
+var ErrProtocol = errors.New("Unexpected message on the planning stream")
+
+// Returned by Server.Plan when a client names a node that isn't in the graph.
+var ErrUnknownNode = errors.New("Node isn't in the graph")
+
+// A message from the client. Exactly one field is set.
+type Request struct {
+	Start  *Start
+	Change *Change
+	Moved  *Moved
+}
+
+// Starts an episode from Start to Goal, throwing away any earlier one. The first message on every stream must be a Start.
+type Start struct {
+	Start, Goal int64
+}
+
+// New costs for edges of the graph, as the agent discovers them. An infinite cost removes an edge, and a NaN cost puts back the graph's own. In an undirected graph, an edge's
+// cost changes both ways.
+type Change struct {
+	Edges []EdgeCost
+}
+
+type EdgeCost struct {
+	Head, Tail int64
+	Cost       float64
+}
+
+// The agent has moved to Node, which is usually the last decision's Next, but needn't be: if it isn't, the plan is recomputed from Node.
+type Moved struct {
+	Node int64
+}
+
+// The server's decision after each message, once the plan is up to date.
+type Decision struct {
+	Position int64   // Where the server thinks the agent is
+	Next     int64   // Where it should move next, unless Reached or NoPath is set
+	Plan     []int64 // The rest of the plan, starting with Next
+	Cost     float64 // The cost of Plan
+	Reached  bool    // The agent is at the goal
+	NoPath   bool    // There's currently no path to the goal, though changes may open one
+}
+
+// The server's side of a planning stream, as a gRPC server stream provides it: Recv returns io.EOF once the client is done sending.
+type PlanStream interface {
+	Context() context.Context
+	Send(*Decision) error
+	Recv() (*Request, error)
+}
+
+// A Server plans for any number of streams at once on a shared graph, which isn't modified: each stream's changes are kept to itself, as costs that override the graph's. Cost
+// and HeuristicCost are as for InitDStar, with the same defaults, and Options are passed to it for every episode.
+type Server struct {
+	Graph               graph.Graph
+	Cost, HeuristicCost func(graph.Node, graph.Node) float64
+	Options             []graph.DStarOption
+}
+
+// Serves one planning stream until the client stops sending, returning nil, or until a protocol error, an error sending, or the stream's context being cancelled, returning
+// that. A search in progress when the context is cancelled is abandoned.
+func (s *Server) Plan(stream PlanStream) error {
+	ctx := stream.Context()
+	nodes := make(map[int64]graph.Node)
+	for _, node := range s.Graph.NodeList() {
+		nodes[int64(node.ID())] = node
+	}
+	baseCost := s.Cost
+	if baseCost == nil {
+		if cgraph, ok := s.Graph.(graph.Coster); ok {
+			baseCost = cgraph.Cost
+		} else {
+			baseCost = graph.UniformCost
+		}
+	}
+	overrides := make(map[[2]int]float64)
+	cost := func(a, b graph.Node) float64 {
+		if c, ok := overrides[[2]int{a.ID(), b.ID()}]; ok {
+			return c
+		}
+		return baseCost(a, b)
+	}
+
+	var ds *graph.DStarInstance
+	var position, goal graph.Node
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case req.Start != nil:
+			start, ok1 := nodes[req.Start.Start]
+			end, ok2 := nodes[req.Start.Goal]
+			if !ok1 || !ok2 {
+				return ErrUnknownNode
+			}
+			position, goal = start, end
+			if ds == nil {
+				ds, err = graph.InitDStarCtx(ctx, position, goal, s.Graph, cost, s.HeuristicCost, s.Options...)
+			} else {
+				ds.Retarget(position, goal)
+			}
+		case ds == nil:
+			return ErrProtocol
+		case req.Change != nil:
+			var edges []graph.Edge
+			for _, e := range req.Change.Edges {
+				head, ok1 := nodes[e.Head]
+				tail, ok2 := nodes[e.Tail]
+				if !ok1 || !ok2 {
+					return ErrUnknownNode
+				}
+				keys := [][2]int{{head.ID(), tail.ID()}}
+				if !s.Graph.IsDirected() {
+					keys = append(keys, [2]int{tail.ID(), head.ID()})
+				}
+				for _, key := range keys {
+					if math.IsNaN(e.Cost) {
+						delete(overrides, key)
+					} else {
+						overrides[key] = e.Cost
+					}
+				}
+				edges = append(edges, graph.GonumEdge{H: head, T: tail})
+			}
+			err = ds.UpdateCtx(ctx, cost, edges)
+		case req.Moved != nil:
+			node, ok := nodes[req.Moved.Node]
+			if !ok {
+				return ErrUnknownNode
+			}
+			if next := ds.Peek(1); len(next) == 1 && next[0].ID() == node.ID() {
+				_, err = ds.Step()
+			} else if node.ID() != position.ID() {
+				ds.Retarget(node, goal)
+			}
+			position = node
+		default:
+			return ErrProtocol
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(s.decide(ds, position, goal, cost)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) decide(ds *graph.DStarInstance, position, goal graph.Node, cost func(graph.Node, graph.Node) float64) *Decision {
+	decision := &Decision{Position: int64(position.ID())}
+	if position.ID() == goal.ID() {
+		decision.Reached = true
+		return decision
+	}
+	plan := ds.PlanAhead()
+	if len(plan) == 0 || plan[len(plan)-1].ID() != goal.ID() {
+		decision.NoPath = true
+		return decision
+	}
+
+	prev := position
+	for _, node := range plan {
+		decision.Plan = append(decision.Plan, int64(node.ID()))
+		decision.Cost += cost(prev, node)
+		prev = node
+	}
+	decision.Next = decision.Plan[0]
+	return decision
+}
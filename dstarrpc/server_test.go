@@ -0,0 +1,94 @@
+package dstarrpc
+
+import (
+	"context"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A PlanStream whose client end is a pair of channels
+type pipe struct {
+	ctx       context.Context
+	requests  chan *Request
+	decisions chan *Decision
+}
+
+func (p *pipe) Context() context.Context {
+	return p.ctx
+}
+
+func (p *pipe) Send(d *Decision) error {
+	p.decisions <- d
+	return nil
+}
+
+func (p *pipe) Recv() (*Request, error) {
+	req, ok := <-p.requests
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func TestServer(t *testing.T) {
+	// 0 - 1 - 2 - 3 costs 3, and 0 - 4 - 3 costs 4
+	g := graph.NewGonumGraph(false)
+	for i := 0; i < 5; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][3]int{{0, 1, 1}, {1, 2, 1}, {2, 3, 1}, {0, 4, 2}, {4, 3, 2}} {
+		edge := graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, float64(e[2]))
+	}
+	s := &Server{Graph: g}
+
+	p := &pipe{ctx: context.Background(), requests: make(chan *Request), decisions: make(chan *Decision)}
+	done := make(chan error, 1)
+	go func() { done <- s.Plan(p) }()
+	exchange := func(req *Request, expected Decision) {
+		p.requests <- req
+		if got := <-p.decisions; !reflect.DeepEqual(*got, expected) {
+			t.Errorf("After %+v, expected %+v, got %+v", req, expected, *got)
+		}
+	}
+
+	exchange(&Request{Start: &Start{0, 3}}, Decision{Position: 0, Next: 1, Plan: []int64{1, 2, 3}, Cost: 3})
+	exchange(&Request{Moved: &Moved{1}}, Decision{Position: 1, Next: 2, Plan: []int64{2, 3}, Cost: 2})
+	// The agent finds the way on blocked, and goes back
+	exchange(&Request{Change: &Change{[]EdgeCost{{2, 1, math.Inf(1)}}}}, Decision{Position: 1, Next: 0, Plan: []int64{0, 4, 3}, Cost: 5})
+	exchange(&Request{Moved: &Moved{0}}, Decision{Position: 0, Next: 4, Plan: []int64{4, 3}, Cost: 4})
+	// Then hears it's clear again
+	exchange(&Request{Change: &Change{[]EdgeCost{{1, 2, math.NaN()}}}}, Decision{Position: 0, Next: 1, Plan: []int64{1, 2, 3}, Cost: 3})
+	// And goes its own way regardless
+	exchange(&Request{Moved: &Moved{4}}, Decision{Position: 4, Next: 3, Plan: []int64{3}, Cost: 2})
+	exchange(&Request{Change: &Change{[]EdgeCost{{4, 3, math.Inf(1)}, {2, 3, math.Inf(1)}}}}, Decision{Position: 4, NoPath: true})
+	exchange(&Request{Change: &Change{[]EdgeCost{{4, 3, 7}}}}, Decision{Position: 4, Next: 3, Plan: []int64{3}, Cost: 7})
+	exchange(&Request{Moved: &Moved{3}}, Decision{Position: 3, Reached: true})
+	// A new episode keeps what the stream has learned
+	exchange(&Request{Start: &Start{1, 4}}, Decision{Position: 1, Next: 0, Plan: []int64{0, 4}, Cost: 3})
+	close(p.requests)
+	if err := <-done; err != nil {
+		t.Errorf("Expected the stream to end cleanly, got %v", err)
+	}
+
+	// Streams that break the protocol
+	for _, reqs := range [][]*Request{
+		{{Moved: &Moved{1}}},
+		{{Start: &Start{0, 9}}},
+		{{}},
+	} {
+		p := &pipe{ctx: context.Background(), requests: make(chan *Request, len(reqs)), decisions: make(chan *Decision, len(reqs))}
+		for _, req := range reqs {
+			p.requests <- req
+		}
+		close(p.requests)
+		if err := s.Plan(p); err != ErrProtocol && err != ErrUnknownNode {
+			t.Errorf("Expected a protocol error from %+v, got %v", reqs[0], err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package graph
+
+// Commands accepted by a DStarService.
+type DStarCommand int
+
+const (
+	// Performs a single step/move/update cycle, like a signal to SynchronizedDStarLite.
+	DStarStep DStarCommand = iota
+	// Stops a running service from stepping on its own, and drops any steps still pending. It still accepts graph changes and DStarStep while paused.
+	DStarPause
+	// Makes the service step continuously until it's paused or reaches the goal.
+	DStarResume
+)
+
+// A graph change that didn't come from the agent moving (e.g. a map update from another robot). Apply, if non-nil, is called on the service's goroutine before the change is processed,
+// which is the safe place to actually modify the graph since the service may be reading it at any other time. Cost and Edges are then passed to Update.
+type DStarChange struct {
+	Apply func()
+	Cost  func(Node, Node) float64
+	Edges []Edge
+}
+
+// What the service reports after every move and every processed change. Move is nil for the initial plan and for changes that didn't come with a move.
+// Plan is the rest of the current plan (see PlanAhead), and Cost its estimated cost, which is +Inf if there's currently no path.
+type DStarEvent struct {
+	Move  Node
+	Plan  []Node
+	Cost  float64
+	Stats SearchStats
+}
+
+// A D*-Lite planner running on its own goroutine, driven entirely over channels. It's a superset of SynchronizedDStarLite: as well as stepping on demand,
+// it can run freely, be paused and resumed, accept graph changes from outside, and reports every move with the current plan and planner stats.
+//
+// Every event must be received from Events, the service blocks until it is (though it still accepts commands while blocked). When the service stops, Events is closed,
+// after which Done yields the error that stopped it, if any. Closing Commands stops the service early, with no error. Changes may be closed if no more changes will be sent.
+type DStarService struct {
+	Commands chan<- DStarCommand
+	Changes  chan<- DStarChange
+	Events   <-chan DStarEvent
+	Done     <-chan error
+}
+
+// Starts a D*-Lite service in a seperate goroutine. It starts paused: the initial plan is sent on Events as soon as it's computed, but no move is made until it's told to.
+// Stats are always recorded, so WithDStarStats doesn't need to be passed in options.
+func NewDStarService(start, goal Node, graph DStarGraph, Cost, HeuristicCost func(Node, Node) float64, options ...DStarOption) *DStarService {
+	commands, changes := make(chan DStarCommand), make(chan DStarChange)
+	events, done := make(chan DStarEvent), make(chan error, 1)
+
+	var incoming <-chan DStarChange = changes // Set to nil once closed, the caller keeps its own reference
+	go func() {
+		defer close(done)
+		defer close(events)
+
+		ds := InitDStar(start, goal, graph, Cost, HeuristicCost, append(options, WithDStarStats())...)
+		running, pending := false, 0
+
+		// Returns false if the service should stop
+		handle := func(cmd DStarCommand, ok bool) bool {
+			if !ok {
+				return false
+			}
+			switch cmd {
+			case DStarStep:
+				pending++
+			case DStarPause:
+				running, pending = false, 0
+			case DStarResume:
+				running = true
+			}
+			return true
+		}
+
+		// Commands are still accepted while waiting on the receiver, otherwise a consumer trying to pause a running service would deadlock with it
+		emit := func(move Node) bool {
+			event := DStarEvent{Move: move, Plan: ds.PlanAhead(), Cost: ds.g(ds.start.ID()), Stats: ds.Stats()}
+			for {
+				select {
+				case events <- event:
+					return true
+				case cmd, ok := <-commands:
+					if !handle(cmd, ok) {
+						return false
+					}
+				}
+			}
+		}
+
+		change := func(c DStarChange, ok bool) bool {
+			if !ok {
+				incoming = nil
+				return true
+			}
+			if c.Apply != nil {
+				c.Apply()
+			}
+			ds.Update(c.Cost, c.Edges)
+			return emit(nil)
+		}
+
+		if !emit(nil) {
+			return
+		}
+		for ds.start.ID() != ds.goal.ID() {
+			if !running && pending == 0 {
+				select {
+				case cmd, ok := <-commands:
+					if !handle(cmd, ok) {
+						return
+					}
+				case c, ok := <-incoming:
+					if !change(c, ok) {
+						return
+					}
+				}
+				continue
+			}
+
+			// Changes and commands that are already waiting go first, so a running service doesn't plan with stale information
+			select {
+			case cmd, ok := <-commands:
+				if !handle(cmd, ok) {
+					return
+				}
+				continue
+			case c, ok := <-incoming:
+				if !change(c, ok) {
+					return
+				}
+				continue
+			default:
+			}
+
+			if pending > 0 {
+				pending--
+			}
+			next, err := ds.Step()
+			if err != nil {
+				done <- err
+				return
+			}
+
+			graph.Move(next)
+			newCost, edges := graph.ChangedEdges()
+			ds.Update(newCost, edges)
+			if !emit(next) {
+				return
+			}
+		}
+	}()
+
+	return &DStarService{Commands: commands, Changes: changes, Events: events, Done: done}
+}
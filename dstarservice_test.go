@@ -0,0 +1,116 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDStarServiceRun(t *testing.T) {
+	truth := graph.GenerateMaze(8, 8, graph.RecursiveBacktracker, rand.New(rand.NewSource(5)))
+	start, goal := truth.CoordsToNode(1, 1), truth.CoordsToNode(15, 15)
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+
+	service := graph.NewDStarService(start, goal, world, nil, nil)
+	initial := <-service.Events
+	if initial.Move != nil || len(initial.Plan) == 0 || initial.Plan[len(initial.Plan)-1].ID() != goal.ID() || initial.Cost != float64(len(initial.Plan)) {
+		t.Fatalf("Bad initial event: move %v, plan %v, cost %v", initial.Move, initial.Plan, initial.Cost)
+	}
+
+	service.Commands <- graph.DStarResume
+	prev := graph.Node(start)
+	var last graph.DStarEvent
+	for event := range service.Events {
+		if event.Move == nil {
+			t.Fatal("Got an event without a move though no changes were sent")
+		}
+		if !truth.IsSuccessor(prev, event.Move) {
+			t.Fatalf("Service moved from %v to %v, which aren't adjacent", prev, event.Move)
+		}
+		prev, last = event.Move, event
+	}
+
+	if err := <-service.Done; err != nil {
+		t.Fatal("Service failed:", err)
+	}
+	if prev.ID() != goal.ID() || len(last.Plan) != 0 || last.Cost != 0 {
+		t.Errorf("Service stopped at %v with plan %v and cost %v, expected to be at the goal", prev, last.Plan, last.Cost)
+	}
+	if last.Stats.Searches == 0 || last.Stats.Expansions == 0 {
+		t.Errorf("Stats weren't recorded: %+v", last.Stats)
+	}
+}
+
+func TestDStarServiceChanges(t *testing.T) {
+	truth := graph.NewTileGraph(5, 5, true)
+	start, goal := truth.CoordsToNode(2, 0), truth.CoordsToNode(2, 4)
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+
+	service := graph.NewDStarService(start, goal, world, nil, nil, graph.WithDStarComparator(graph.DStarTieBreakByID))
+	<-service.Events
+	service.Commands <- graph.DStarStep
+	event := <-service.Events
+	if event.Move == nil || event.Cost != 3 {
+		t.Fatalf("Expected a single move, 3 away from the goal, got %v with cost %v", event.Move, event.Cost)
+	}
+
+	// Wall off the whole column ahead but one tile
+	var walls []graph.Node
+	var edges []graph.Edge
+	for row := 0; row < 4; row++ {
+		wall := world.CoordsToNode(row, 2)
+		walls = append(walls, wall)
+		for _, neighbor := range world.Successors(wall) {
+			edges = append(edges, graph.GonumEdge{H: neighbor, T: wall}, graph.GonumEdge{H: wall, T: neighbor})
+		}
+	}
+	service.Changes <- graph.DStarChange{
+		Apply: func() {
+			for _, wall := range walls {
+				row, col := world.IDToCoords(wall.ID())
+				world.SetPassability(row, col, false)
+			}
+		},
+		Edges: edges,
+	}
+
+	event = <-service.Events
+	if event.Move != nil || event.Cost != 7 {
+		t.Fatalf("Expected the change to be reported with no move and a cost of 7, got %v with cost %v", event.Move, event.Cost)
+	}
+	for _, node := range event.Plan {
+		for _, wall := range walls {
+			if node.ID() == wall.ID() {
+				t.Errorf("New plan %v goes through the wall at %v", event.Plan, wall)
+			}
+		}
+	}
+
+	close(service.Commands)
+	for range service.Events {
+		t.Error("Got an event after the service was stopped")
+	}
+	if err := <-service.Done; err != nil {
+		t.Error("Stopping the service early returned an error:", err)
+	}
+}
+
+func TestDStarServiceNoPath(t *testing.T) {
+	truth := graph.NewTileGraph(5, 5, true)
+	for row := 0; row < 5; row++ {
+		truth.SetPassability(row, 2, false)
+	}
+	start, goal := truth.CoordsToNode(2, 0), truth.CoordsToNode(2, 4)
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+
+	service := graph.NewDStarService(start, goal, world, nil, nil)
+	service.Commands <- graph.DStarResume
+	var last graph.DStarEvent
+	for event := range service.Events {
+		last = event
+	}
+	if err := <-service.Done; err == nil || !math.IsInf(last.Cost, 1) {
+		t.Errorf("Expected the service to fail once the wall was found, got error %v and last cost %v", err, last.Cost)
+	}
+}
@@ -0,0 +1,112 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+// The serialized form of a DStarInstance. Nodes are stored by ID, and infinite scores (the vast majority on a large graph that's barely been searched) are left out.
+type dStarState struct {
+	Start, Goal, Last int
+	KM                float64
+	G, RHS            map[int]float64
+	Queue             []dStarQueued // In heap order
+}
+
+type dStarQueued struct {
+	ID     int
+	K1, K2 float64
+}
+
+// Serializes the planner's state (g-scores, rhs values, k_m, the queue, and the start, goal and last position) so planning can resume after a restart, e.g. for checkpointing a robot's planner.
+// The graph, cost functions and options aren't included; see UnmarshalDStar.
+func (ds *DStarInstance) Marshal() ([]byte, error) {
+	state := dStarState{
+		Start: ds.start.ID(),
+		Goal:  ds.goal.ID(),
+		Last:  ds.last.ID(),
+		KM:    ds.k_m,
+		G:     finiteScores(ds.gScores),
+		RHS:   finiteScores(ds.rhs),
+		Queue: make([]dStarQueued, ds.u.Len()),
+	}
+	for i, item := range ds.u.Items() {
+		k := item.Key.(key)
+		state.Queue[i] = dStarQueued{ID: item.ID(), K1: k[0], K2: k[1]}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(state); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func finiteScores(scores ScoreStore) map[int]float64 {
+	finite := make(map[int]float64)
+	scores.Range(func(id int, score float64) {
+		finite[id] = score
+	})
+
+	return finite
+}
+
+// Restores a DStarInstance serialized by Marshal. The graph must be the one the instance was planning on (in the same state, or with its changes still to be passed to Update),
+// since node IDs are resolved against its NodeList. Cost, HeuristicCost and options are interpreted as in InitDStar and should match the original instance's, since they aren't serialized.
+//
+// Unlike InitDStar no search is performed: the instance continues exactly where the original left off.
+func UnmarshalDStar(data []byte, graph Graph, Cost, HeuristicCost func(Node, Node) float64, options ...DStarOption) (*DStarInstance, error) {
+	var state dStarState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[int]Node)
+	for _, node := range graph.NodeList() {
+		nodes[node.ID()] = node
+	}
+	lookup := func(id int) (Node, error) {
+		if node, ok := nodes[id]; ok {
+			return node, nil
+		}
+		return nil, errors.New("D* state refers to a node that isn't in the graph")
+	}
+
+	start, err := lookup(state.Start)
+	if err != nil {
+		return nil, err
+	}
+	goal, err := lookup(state.Goal)
+	if err != nil {
+		return nil, err
+	}
+	last, err := lookup(state.Last)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := newDStar(start, goal, graph, Cost, HeuristicCost, options)
+	ds.last = last
+	ds.k_m = state.KM
+	ds.gScores.Clear()
+	ds.rhs.Clear()
+	for id, g := range state.G {
+		ds.gScores.Set(id, g)
+	}
+	for id, rhs := range state.RHS {
+		ds.rhs.Set(id, rhs)
+	}
+	// Pushing the items in the order they were saved rebuilds the same heap if the queue is of the same kind (see IndexedHeap.Items), or at least the same queue.
+	// This bypasses the queue's stats and observer since it isn't part of a search
+	for _, queued := range state.Queue {
+		node, err := lookup(queued.ID)
+		if err != nil {
+			return nil, err
+		}
+		ds.u.PriorityQueue.Push(node, key{queued.K1, queued.K2})
+	}
+
+	return ds, nil
+}
@@ -0,0 +1,375 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// EditCosts describes the cost of each elementary edit operation used by the graph edit distance functions. Any nil function takes its unit cost default:
+// substitutions are free, and every insertion or deletion costs 1. This is the classic "unit cost" graph edit distance.
+//
+// Substitution functions are called with the element from the first graph followed by the element from the second graph.
+type EditCosts struct {
+	NodeSubstitution func(n1, n2 Node) float64
+	NodeDeletion     func(n Node) float64
+	NodeInsertion    func(n Node) float64
+	EdgeSubstitution func(e1, e2 Edge) float64
+	EdgeDeletion     func(e Edge) float64
+	EdgeInsertion    func(e Edge) float64
+}
+
+func (ec *EditCosts) nodeSub(n1, n2 Node) float64 {
+	if ec == nil || ec.NodeSubstitution == nil {
+		return 0
+	}
+	return ec.NodeSubstitution(n1, n2)
+}
+
+func (ec *EditCosts) nodeDel(n Node) float64 {
+	if ec == nil || ec.NodeDeletion == nil {
+		return 1
+	}
+	return ec.NodeDeletion(n)
+}
+
+func (ec *EditCosts) nodeIns(n Node) float64 {
+	if ec == nil || ec.NodeInsertion == nil {
+		return 1
+	}
+	return ec.NodeInsertion(n)
+}
+
+func (ec *EditCosts) edgeSub(e1, e2 Edge) float64 {
+	if ec == nil || ec.EdgeSubstitution == nil {
+		return 0
+	}
+	return ec.EdgeSubstitution(e1, e2)
+}
+
+func (ec *EditCosts) edgeDel(e Edge) float64 {
+	if ec == nil || ec.EdgeDeletion == nil {
+		return 1
+	}
+	return ec.EdgeDeletion(e)
+}
+
+func (ec *EditCosts) edgeIns(e Edge) float64 {
+	if ec == nil || ec.EdgeInsertion == nil {
+		return 1
+	}
+	return ec.EdgeInsertion(e)
+}
+
+// Returns the cost of the edit path implied by a node mapping from g1 to g2. Mapping is keyed by the IDs of nodes in g1; a node of g1 that is absent from the mapping
+// (or is mapped to nil) is deleted, and any node of g2 that isn't the image of some node is inserted. Edges are then substituted if both endpoints are mapped onto an edge of g2,
+// deleted otherwise, and any remaining edges in g2 are inserted.
+//
+// The mapping must be injective, otherwise the result is meaningless. Since any valid mapping is a valid edit path, the return value is always an upper bound on the true graph edit distance.
+func EditPathCost(g1, g2 Graph, costs *EditCosts, mapping map[int]Node) float64 {
+	total := 0.0
+	image := make(map[int]bool, len(mapping))
+	for _, n1 := range g1.NodeList() {
+		if n2 := mapping[n1.ID()]; n2 != nil {
+			total += costs.nodeSub(n1, n2)
+			image[n2.ID()] = true
+		} else {
+			total += costs.nodeDel(n1)
+		}
+	}
+	for _, n2 := range g2.NodeList() {
+		if !image[n2.ID()] {
+			total += costs.nodeIns(n2)
+		}
+	}
+
+	covered := make(map[[2]int]bool)
+	for _, e1 := range undirectedOnce(g1, g1.EdgeList()) {
+		h, t := mapping[e1.Head().ID()], mapping[e1.Tail().ID()]
+		if h != nil && t != nil && g2.IsSuccessor(h, t) {
+			e2 := GonumEdge{H: h, T: t}
+			total += costs.edgeSub(e1, e2)
+			covered[edgeKey(g2, e2)] = true
+		} else {
+			total += costs.edgeDel(e1)
+		}
+	}
+	for _, e2 := range undirectedOnce(g2, g2.EdgeList()) {
+		if !covered[edgeKey(g2, e2)] {
+			total += costs.edgeIns(e2)
+		}
+	}
+
+	return total
+}
+
+// Since undirected graphs list each edge in both directions, this filters the list down to one direction so edges aren't paid for twice.
+func undirectedOnce(graph Graph, edges []Edge) []Edge {
+	if graph.IsDirected() {
+		return edges
+	}
+
+	once := make([]Edge, 0, len(edges)/2+1)
+	for _, e := range edges {
+		if e.Head().ID() <= e.Tail().ID() {
+			once = append(once, e)
+		}
+	}
+
+	return once
+}
+
+func edgeKey(graph Graph, e Edge) [2]int {
+	h, t := e.Head().ID(), e.Tail().ID()
+	if !graph.IsDirected() && t < h {
+		h, t = t, h
+	}
+
+	return [2]int{h, t}
+}
+
+// Approximates the graph edit distance between g1 and g2 with the bipartite method of Riesen and Bunke[1]. Every node substitution, deletion, and insertion is priced along with
+// an optimal assignment of the edges incident to it, and the node assignment minimizing the total is found with the Hungarian algorithm in O((n+m)^3).
+//
+// The returned distance is the exact cost of the edit path the assignment implies (see EditPathCost), and so is an upper bound on the true edit distance. The mapping is from
+// the IDs of nodes in g1 to their substitutes in g2; deleted nodes are absent. A nil costs argument uses unit costs.
+//
+// [1] K. Riesen, H. Bunke, "Approximate graph edit distance computation by means of bipartite graph matching", Image and Vision Computing 27(7), 2009
+func BipartiteEditDistance(g1, g2 Graph, costs *EditCosts) (distance float64, mapping map[int]Node) {
+	nodes1, nodes2 := g1.NodeList(), g2.NodeList()
+	n, m := len(nodes1), len(nodes2)
+	size := n + m
+
+	matrix := make([][]float64, size)
+	for i := range matrix {
+		matrix[i] = make([]float64, size)
+	}
+
+	inf := math.Inf(1)
+	for i, n1 := range nodes1 {
+		for j, n2 := range nodes2 {
+			matrix[i][j] = costs.nodeSub(n1, n2) + localEdgeCost(g1, g2, n1, n2, costs)
+		}
+		for j := 0; j < n; j++ {
+			if i == j {
+				matrix[i][m+j] = costs.nodeDel(n1) + incidentEdgeCost(g1, n1, costs.edgeDel)
+			} else {
+				matrix[i][m+j] = inf
+			}
+		}
+	}
+	for i, n2 := range nodes2 {
+		for j := 0; j < m; j++ {
+			if i == j {
+				matrix[n+i][j] = costs.nodeIns(n2) + incidentEdgeCost(g2, n2, costs.edgeIns)
+			} else {
+				matrix[n+i][j] = inf
+			}
+		}
+		// The bottom right block is all zeros; substituting an epsilon for an epsilon costs nothing
+	}
+
+	assignment, _ := hungarian(matrix)
+	mapping = make(map[int]Node, n)
+	for i, n1 := range nodes1 {
+		if j := assignment[i]; j < m {
+			mapping[n1.ID()] = nodes2[j]
+		}
+	}
+
+	return EditPathCost(g1, g2, costs, mapping), mapping
+}
+
+// Half the cost of deleting (or inserting) all of a node's edges. It's halved because each edge is shared with its other endpoint.
+func incidentEdgeCost(graph Graph, node Node, edgeCost func(Edge) float64) float64 {
+	total := 0.0
+	for _, succ := range graph.Successors(node) {
+		total += edgeCost(GonumEdge{H: node, T: succ})
+	}
+	if graph.IsDirected() {
+		for _, pred := range graph.Predecessors(node) {
+			total += edgeCost(GonumEdge{H: pred, T: node})
+		}
+	}
+
+	return total / 2
+}
+
+// The (halved) cost of optimally transforming n1's incident edges into n2's incident edges, computed as a small assignment problem. For directed graphs the outgoing and incoming edges are handled separately.
+func localEdgeCost(g1, g2 Graph, n1, n2 Node, costs *EditCosts) float64 {
+	out1, out2 := make([]Edge, 0), make([]Edge, 0)
+	for _, succ := range g1.Successors(n1) {
+		out1 = append(out1, GonumEdge{H: n1, T: succ})
+	}
+	for _, succ := range g2.Successors(n2) {
+		out2 = append(out2, GonumEdge{H: n2, T: succ})
+	}
+	total := edgeAssignmentCost(out1, out2, costs)
+
+	if g1.IsDirected() || g2.IsDirected() {
+		in1, in2 := make([]Edge, 0), make([]Edge, 0)
+		for _, pred := range g1.Predecessors(n1) {
+			in1 = append(in1, GonumEdge{H: pred, T: n1})
+		}
+		for _, pred := range g2.Predecessors(n2) {
+			in2 = append(in2, GonumEdge{H: pred, T: n2})
+		}
+		total += edgeAssignmentCost(in1, in2, costs)
+	}
+
+	return total / 2
+}
+
+func edgeAssignmentCost(edges1, edges2 []Edge, costs *EditCosts) float64 {
+	n, m := len(edges1), len(edges2)
+	if n+m == 0 {
+		return 0
+	}
+
+	inf := math.Inf(1)
+	matrix := make([][]float64, n+m)
+	for i := range matrix {
+		matrix[i] = make([]float64, n+m)
+	}
+	for i, e1 := range edges1 {
+		for j, e2 := range edges2 {
+			matrix[i][j] = costs.edgeSub(e1, e2)
+		}
+		for j := 0; j < n; j++ {
+			if i == j {
+				matrix[i][m+j] = costs.edgeDel(e1)
+			} else {
+				matrix[i][m+j] = inf
+			}
+		}
+	}
+	for i, e2 := range edges2 {
+		for j := 0; j < m; j++ {
+			if i == j {
+				matrix[n+i][j] = costs.edgeIns(e2)
+			} else {
+				matrix[n+i][j] = inf
+			}
+		}
+	}
+
+	_, total := hungarian(matrix)
+	return total
+}
+
+// Approximates the graph edit distance between g1 and g2 with a beam search over edit paths. The nodes of g1 are processed one at a time (highest degree first), and each partial
+// edit path is extended by substituting the node with every unused node of g2 or deleting it; only the beamWidth cheapest partial paths survive each round.
+//
+// A beamWidth of 1 is a greedy search, while an unbounded beam (beamWidth <= 0) is an exhaustive, exact, and exponential search. Like BipartiteEditDistance, the distance returned is the cost
+// of an actual edit path, so it's an upper bound on the true edit distance. A nil costs argument uses unit costs.
+func BeamEditDistance(g1, g2 Graph, costs *EditCosts, beamWidth int) (distance float64, mapping map[int]Node) {
+	nodes1, nodes2 := g1.NodeList(), g2.NodeList()
+	in1, out1 := degreeMaps(g1, nodes1)
+	sort.Sort(byDegree{nodes: nodes1, in: in1, out: out1})
+
+	type partial struct {
+		cost   float64
+		images []Node // images[i] is the substitute for nodes1[i], or nil if deleted
+		used   map[int]bool
+	}
+
+	beam := []partial{{images: make([]Node, 0, len(nodes1)), used: make(map[int]bool)}}
+	for k, n1 := range nodes1 {
+		next := make([]partial, 0, len(beam)*(len(nodes2)+1))
+		for _, p := range beam {
+			options := make([]Node, 0, len(nodes2)+1)
+			for _, n2 := range nodes2 {
+				if !p.used[n2.ID()] {
+					options = append(options, n2)
+				}
+			}
+			options = append(options, nil)
+
+			for _, n2 := range options {
+				cost := p.cost
+				if n2 != nil {
+					cost += costs.nodeSub(n1, n2)
+				} else {
+					cost += costs.nodeDel(n1)
+				}
+				for i := 0; i <= k; i++ {
+					prev, prevImage := nodes1[i], n2
+					if i < k {
+						prevImage = p.images[i]
+					}
+					cost += pairEditCost(g1, g2, n1, prev, n2, prevImage, costs)
+				}
+
+				images := make([]Node, k, k+1)
+				copy(images, p.images)
+				used := p.used
+				if n2 != nil {
+					used = make(map[int]bool, len(p.used)+1)
+					for id := range p.used {
+						used[id] = true
+					}
+					used[n2.ID()] = true
+				}
+				next = append(next, partial{cost: cost, images: append(images, n2), used: used})
+			}
+		}
+
+		sort.Stable(byKey{len(next), func(i int) float64 { return next[i].cost }, func(i, j int) { next[i], next[j] = next[j], next[i] }})
+		if beamWidth > 0 && len(next) > beamWidth {
+			next = next[:beamWidth]
+		}
+		beam = next
+	}
+
+	best := beam[0]
+	mapping = make(map[int]Node, len(nodes1))
+	for i, n1 := range nodes1 {
+		if best.images[i] != nil {
+			mapping[n1.ID()] = best.images[i]
+		}
+	}
+
+	return EditPathCost(g1, g2, costs, mapping), mapping
+}
+
+// The cost of the edges between a (newly processed) node u and an earlier processed node v, given their images (which may be nil for deleted nodes).
+// When u == v this prices u's self loop.
+func pairEditCost(g1, g2 Graph, u, v, uImage, vImage Node, costs *EditCosts) float64 {
+	total := 0.0
+	price := func(h1, t1, h2, t2 Node) {
+		e1 := g1.IsSuccessor(h1, t1)
+		e2 := h2 != nil && t2 != nil && g2.IsSuccessor(h2, t2)
+		switch {
+		case e1 && e2:
+			total += costs.edgeSub(GonumEdge{H: h1, T: t1}, GonumEdge{H: h2, T: t2})
+		case e1:
+			total += costs.edgeDel(GonumEdge{H: h1, T: t1})
+		case e2:
+			total += costs.edgeIns(GonumEdge{H: h2, T: t2})
+		}
+	}
+
+	price(u, v, uImage, vImage)
+	if g1.IsDirected() && u.ID() != v.ID() {
+		price(v, u, vImage, uImage)
+	}
+
+	return total
+}
+
+type byKey struct {
+	n    int
+	key  func(int) float64
+	swap func(int, int)
+}
+
+func (bp byKey) Len() int {
+	return bp.n
+}
+
+func (bp byKey) Less(i, j int) bool {
+	return bp.key(i) < bp.key(j)
+}
+
+func (bp byKey) Swap(i, j int) {
+	bp.swap(i, j)
+}
@@ -0,0 +1,46 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	triangle := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 0}})
+	path := buildGraph(false, [][2]int{{5, 6}, {6, 7}})
+	square := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+
+	if d, _ := graph.BipartiteEditDistance(triangle, triangle, nil); d != 0 {
+		t.Errorf("Bipartite edit distance between identical graphs is %v", d)
+	}
+	if d, _ := graph.BeamEditDistance(triangle, triangle, nil, 5); d != 0 {
+		t.Errorf("Beam edit distance between identical graphs is %v", d)
+	}
+
+	if d, _ := graph.BipartiteEditDistance(triangle, path, nil); d != 1 {
+		t.Errorf("Bipartite edit distance between triangle and 3-path is %v, expected 1", d)
+	}
+	if d, _ := graph.BeamEditDistance(triangle, path, nil, 0); d != 1 {
+		t.Errorf("Exhaustive edit distance between triangle and 3-path is %v, expected 1", d)
+	}
+
+	// Square -> triangle: delete a node and two edges, then insert one edge
+	d, mapping := graph.BeamEditDistance(square, triangle, nil, 0)
+	if d != 4 {
+		t.Errorf("Exhaustive edit distance between square and triangle is %v, expected 4", d)
+	}
+	if d != graph.EditPathCost(square, triangle, nil, mapping) {
+		t.Error("Beam edit distance doesn't match the cost of its own edit path")
+	}
+
+	// Expensive substitutions make deleting and reinserting everything cheaper
+	costs := &graph.EditCosts{NodeSubstitution: func(n1, n2 graph.Node) float64 {
+		if n1.ID() != n2.ID() {
+			return 10
+		}
+		return 0
+	}}
+	if d, _ := graph.BipartiteEditDistance(triangle, path, costs); d != 11 {
+		t.Errorf("Bipartite edit distance with custom costs is %v, expected 11", d)
+	}
+}
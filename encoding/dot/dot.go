@@ -0,0 +1,276 @@
+// Package dot reads and writes graphs in Graphviz's DOT language, for looking at a graph with Graphviz's tools and for writing test graphs by hand.
+//
+// Nodes are named by their IDs, and an edge's cost is its weight attribute:
+//
+//	digraph {
+//		0;
+//		1;
+//		2;
+//		0 -> 1 [weight=2.5];
+//		1 -> 2 [weight=1];
+//	}
+//
+// Unmarshal reads the part of the language that says which nodes and edges there are: node and edge statements, including chains like a -> b -> c, with their attributes,
+// comments, and graph, node and edge attribute statements, which are ignored, as are all attributes but weight. Subgraphs and ports aren't supported, and node IDs must be
+// integers, quoted or not.
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/graph"
+)
+
+// Writes g as a DOT graph, a digraph if g is directed: every node in order of ID, and then every edge, ordered by its head's ID and then its tail's, each undirected edge
+// once. Each edge's weight is its cost, as g's Cost method gives it, or 1 if g isn't a Coster. Unmarshal reads the result back as the same graph.
+func Marshal(g graph.Graph) ([]byte, error) {
+	cost := graph.UniformCost
+	if cgraph, ok := g.(graph.Coster); ok {
+		cost = cgraph.Cost
+	}
+	kind, op := "graph", "--"
+	if g.IsDirected() {
+		kind, op = "digraph", "->"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s {\n", kind)
+	nodes := sortedNodes(g.NodeList())
+	for _, node := range nodes {
+		fmt.Fprintf(&buf, "\t%d;\n", node.ID())
+	}
+	for _, node := range nodes {
+		for _, succ := range sortedNodes(g.Successors(node)) {
+			if g.IsDirected() || node.ID() <= succ.ID() {
+				fmt.Fprintf(&buf, "\t%d %s %d [weight=%s];\n", node.ID(), op, succ.ID(), formatWeight(cost(node, succ)))
+			}
+		}
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes(), nil
+}
+
+// Weights are numerals where they can be, and quoted where they can't, as infinities and NaN can't
+func formatWeight(w float64) string {
+	s := strconv.FormatFloat(w, 'g', -1, 64)
+	if math.IsInf(w, 0) || math.IsNaN(w) || strings.ContainsAny(s, "e") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// Reads a DOT graph: a *graph.DirectedGraph for a digraph, and a *graph.UndirectedGraph otherwise. Every node named in a node or edge statement is in the graph, and an edge
+// costs its weight, or 1 if it has none. An edge given more than once, either way round in an undirected graph, costs the last weight it was given.
+func Unmarshal(data []byte) (graph.Graph, error) {
+	p := &parser{lex: newLexer(data)}
+	return p.parse()
+}
+
+type parser struct {
+	lex      *lexer
+	directed bool
+	g        graph.MutableGraph
+}
+
+func (p *parser) parse() (g graph.Graph, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			g, err = nil, e
+		}
+	}()
+
+	tok := p.lex.next()
+	if tok.is("strict") {
+		tok = p.lex.next()
+	}
+	switch {
+	case tok.is("digraph"):
+		p.directed = true
+		p.g = graph.NewDirectedGraph(0, 0)
+	case tok.is("graph"):
+		p.g = graph.NewUndirectedGraph(0, 0)
+	default:
+		p.fail(tok, "expected graph or digraph, found %s", tok)
+	}
+
+	tok = p.lex.next()
+	if tok.kind == idToken {
+		tok = p.lex.next()
+	}
+	if tok.kind != '{' {
+		p.fail(tok, "expected {, found %s", tok)
+	}
+	for !p.statement() {
+	}
+	if tok := p.lex.next(); tok.kind != eofToken {
+		p.fail(tok, "expected the end of the graph, found %s", tok)
+	}
+
+	return p.g, nil
+}
+
+// Reads a statement, and the ; after it if there is one. Returns true at the } that ends the graph.
+func (p *parser) statement() (done bool) {
+	tok := p.lex.next()
+	switch {
+	case tok.kind == '}':
+		return true
+	case tok.kind == ';':
+		return false
+	case tok.kind == '{' || tok.is("subgraph"):
+		p.fail(tok, "subgraphs aren't supported")
+	case tok.kind != idToken:
+		p.fail(tok, "expected a statement, found %s", tok)
+	case tok.is("graph") || tok.is("node") || tok.is("edge"):
+		p.attributes()
+	case p.lex.peek().kind == '=':
+		p.lex.next()
+		if value := p.lex.next(); value.kind != idToken {
+			p.fail(value, "expected a value for %s, found %s", tok, value)
+		}
+	default:
+		p.nodeOrEdges(tok)
+	}
+
+	if p.lex.peek().kind == ';' {
+		p.lex.next()
+	}
+	return false
+}
+
+// Reads a node statement, or an edge statement starting at first
+func (p *parser) nodeOrEdges(first token) {
+	nodes := []graph.Node{p.node(first)}
+	for p.lex.peek().kind == edgeOpToken {
+		op := p.lex.next()
+		if want := p.edgeOp(); op.text != want {
+			p.fail(op, "%s in a %s, which takes %s", op.text, p.kind(), want)
+		}
+		nodes = append(nodes, p.node(p.lex.next()))
+	}
+
+	attrs := p.attributes()
+	for _, node := range nodes {
+		if !p.g.NodeExists(node) {
+			p.g.AddNode(node, nil)
+		}
+	}
+	if len(nodes) == 1 {
+		return
+	}
+
+	w, weighted := attrs["weight"]
+	weight, err := strconv.ParseFloat(w.text, 64)
+	if weighted && err != nil {
+		p.fail(w, "weight %s isn't a number", w)
+	}
+	// A new edge costs 1, and an old one keeps its cost, unless a weight says otherwise
+	for i := 1; i < len(nodes); i++ {
+		edge := graph.GonumEdge{H: nodes[i-1], T: nodes[i]}
+		p.g.AddEdge(edge)
+		if weighted {
+			p.g.SetEdgeCost(edge, weight)
+		}
+	}
+}
+
+func (p *parser) node(tok token) graph.Node {
+	if tok.kind != idToken {
+		p.fail(tok, "expected a node, found %s", tok)
+	}
+	if next := p.lex.peek(); next.kind == ':' {
+		p.fail(next, "ports aren't supported")
+	}
+	id, err := strconv.Atoi(tok.text)
+	if err != nil {
+		p.fail(tok, "node %s isn't an integer", tok)
+	}
+	return graph.GonumNode(id)
+}
+
+// Reads any number of attribute lists, returning the last value given to each attribute
+func (p *parser) attributes() map[string]token {
+	attrs := make(map[string]token)
+	for p.lex.peek().kind == '[' {
+		p.lex.next()
+		for {
+			name := p.lex.next()
+			if name.kind == ']' {
+				break
+			}
+			if name.kind != idToken {
+				p.fail(name, "expected an attribute, found %s", name)
+			}
+			if eq := p.lex.next(); eq.kind != '=' {
+				p.fail(eq, "expected = after %s, found %s", name, eq)
+			}
+			value := p.lex.next()
+			if value.kind != idToken {
+				p.fail(value, "expected a value for %s, found %s", name, value)
+			}
+			attrs[name.text] = value
+			if sep := p.lex.peek(); sep.kind == ',' || sep.kind == ';' {
+				p.lex.next()
+			}
+		}
+	}
+	return attrs
+}
+
+func (p *parser) edgeOp() string {
+	if p.directed {
+		return "->"
+	}
+	return "--"
+}
+
+func (p *parser) kind() string {
+	if p.directed {
+		return "digraph"
+	}
+	return "graph"
+}
+
+func (p *parser) fail(tok token, format string, args ...interface{}) {
+	panic(&SyntaxError{Line: tok.line, Msg: fmt.Sprintf(format, args...)})
+}
+
+// A SyntaxError is what Unmarshal returns for input that isn't DOT, or uses a part of it the package doesn't read.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("dot: line %d: %s", e.Line, e.Msg)
+}
+
+func sortedNodes(nodes []graph.Node) []graph.Node {
+	nodes = append([]graph.Node(nil), nodes...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
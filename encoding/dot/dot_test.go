@@ -0,0 +1,134 @@
+package dot
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestMarshal(t *testing.T) {
+	g := graph.NewDirectedGraph(0, 0)
+	g.AddNode(graph.GonumNode(3), nil)
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(0)})
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)})
+	g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}, 2.5)
+
+	data, err := Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "digraph {\n\t0;\n\t1;\n\t3;\n\t0 -> 1 [weight=2.5];\n\t1 -> 0 [weight=1];\n}\n"
+	if string(data) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, data)
+	}
+
+	u := graph.NewUndirectedGraph(0, 0)
+	u.AddEdge(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(0)})
+	data, _ = Marshal(u)
+	if want := "graph {\n\t0;\n\t1;\n\t0 -- 1 [weight=1];\n}\n"; string(data) != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := graph.NewGonumGraph(directed)
+		graph.GnmRandomGraph(g, 20, 40, directed, nil)
+		for i, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(i)/3-4)
+		}
+		g.AddNode(graph.GonumNode(-7), nil)
+		g.AddNode(graph.GonumNode(100), []graph.Node{graph.GonumNode(-7)})
+		g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(100), T: graph.GonumNode(-7)}, math.Inf(1))
+
+		data, err := Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Couldn't read back\n%s\n%v", data, err)
+		}
+		if back.IsDirected() != directed || len(back.NodeList()) != len(g.NodeList()) || len(back.EdgeList()) != len(g.EdgeList()) {
+			t.Fatalf("Read back a graph with %d nodes and %d edges, directed %t, from one with %d and %d, directed %t", len(back.NodeList()), len(back.EdgeList()), back.IsDirected(),
+				len(g.NodeList()), len(g.EdgeList()), directed)
+		}
+		for _, edge := range g.EdgeList() {
+			if got, want := back.(graph.Coster).Cost(edge.Head(), edge.Tail()), g.Cost(edge.Head(), edge.Tail()); got != want {
+				t.Errorf("Edge %v-%v costs %v after a round trip, %v before", edge.Head(), edge.Tail(), got, want)
+			}
+		}
+		if again, _ := Marshal(back); string(again) != string(data) {
+			t.Errorf("Marshalling the graph read back gives\n%s\nnot\n%s", again, data)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	src := `/* A hand written fixture */
+strict digraph "fixture" {
+	rankdir = LR; // ignored
+	node [shape=circle]
+	# A chain, with one weight for every edge in it
+	0 -> 1 -> "2" [weight=3, color=red][label="x"]
+	2 -> 0
+	5
+	1 -> 2 [weight=0.5]; 1 -> 2
+}`
+	g, err := Unmarshal([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.(*graph.DirectedGraph); !ok {
+		t.Fatalf("A digraph was read as a %T", g)
+	}
+
+	cost := g.(graph.Coster).Cost
+	edges := map[[2]int]float64{}
+	for _, edge := range g.EdgeList() {
+		edges[[2]int{edge.Head().ID(), edge.Tail().ID()}] = cost(edge.Head(), edge.Tail())
+	}
+	want := map[[2]int]float64{{0, 1}: 3, {1, 2}: 0.5, {2, 0}: 1}
+	if !reflect.DeepEqual(edges, want) {
+		t.Errorf("Expected edges %v, got %v", want, edges)
+	}
+	if !g.NodeExists(graph.GonumNode(5)) || len(g.NodeList()) != 4 {
+		t.Errorf("Expected nodes 0, 1, 2 and 5, got %d nodes", len(g.NodeList()))
+	}
+
+	u, err := Unmarshal([]byte("graph { 1 -- 2 [weight=4]; 2 -- 1 }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := u.(*graph.UndirectedGraph); !ok || u.(graph.Coster).Cost(graph.GonumNode(1), graph.GonumNode(2)) != 4 {
+		t.Errorf("Expected an undirected edge costing 4, got a %T with cost %v", u, u.(graph.Coster).Cost(graph.GonumNode(1), graph.GonumNode(2)))
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		msg  string
+	}{
+		{"", 1, "expected graph or digraph"},
+		{"digraph {\n\t0 -- 1\n}", 2, "-- in a digraph"},
+		{"graph {\n\n\ta -- b\n}", 3, "isn't an integer"},
+		{"graph {\n\tsubgraph { 1 }\n}", 2, "subgraphs aren't supported"},
+		{"graph {\n\t1:n -- 2\n}", 2, "ports aren't supported"},
+		{"digraph {\n\t0 -> 1 [weight=heavy]\n}", 2, "isn't a number"},
+		{"digraph {\n\t\"0 -> 1\n}", 2, "unterminated string"},
+		{"digraph { 0 -> 1", 1, "expected a statement"},
+		{"digraph { } }", 1, "expected the end of the graph"},
+	}
+	for _, test := range tests {
+		_, err := Unmarshal([]byte(test.src))
+		serr, ok := err.(*SyntaxError)
+		if !ok || serr.Line != test.line || !strings.Contains(serr.Msg, test.msg) {
+			t.Errorf("Reading %q, expected an error on line %d saying %q, got %v", test.src, test.line, test.msg, err)
+		}
+	}
+}
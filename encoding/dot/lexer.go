@@ -0,0 +1,156 @@
+package dot
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	eofToken    = -1
+	idToken     = -2
+	edgeOpToken = -3
+)
+
+// A token is an ID (a name, numeral or quoted string, unquoted), an edge operator, or one of the punctuation characters, whose kind is the character itself.
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// Whether the token is the given keyword, which DOT doesn't care about the case of
+func (tok token) is(keyword string) bool {
+	return tok.kind == idToken && strings.EqualFold(tok.text, keyword)
+}
+
+func (tok token) String() string {
+	switch tok.kind {
+	case eofToken:
+		return "the end of the input"
+	case idToken, edgeOpToken:
+		return strconv.Quote(tok.text)
+	}
+	return strconv.Quote(string(rune(tok.kind)))
+}
+
+type lexer struct {
+	data   []byte
+	pos    int
+	line   int
+	peeked *token
+}
+
+func newLexer(data []byte) *lexer {
+	return &lexer{data: data, line: 1}
+}
+
+func (l *lexer) peek() token {
+	if l.peeked == nil {
+		tok := l.scan()
+		l.peeked = &tok
+	}
+	return *l.peeked
+}
+
+func (l *lexer) next() token {
+	tok := l.peek()
+	l.peeked = nil
+	return tok
+}
+
+func (l *lexer) scan() token {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return token{kind: eofToken, line: l.line}
+	}
+
+	start, line := l.pos, l.line
+	c := l.data[l.pos]
+	switch {
+	case c == '"':
+		return l.quoted()
+	case c == '-' && l.pos+1 < len(l.data) && (l.data[l.pos+1] == '>' || l.data[l.pos+1] == '-'):
+		l.pos += 2
+		return token{kind: edgeOpToken, text: string(l.data[start:l.pos]), line: line}
+	case c == '-' || c == '.' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.data) && (isDigit(l.data[l.pos]) || l.data[l.pos] == '.') {
+			l.pos++
+		}
+		return token{kind: idToken, text: string(l.data[start:l.pos]), line: line}
+	case isNameStart(l.data[l.pos:]):
+		for l.pos < len(l.data) && (isNameStart(l.data[l.pos:]) || isDigit(l.data[l.pos])) {
+			_, size := utf8.DecodeRune(l.data[l.pos:])
+			l.pos += size
+		}
+		return token{kind: idToken, text: string(l.data[start:l.pos]), line: line}
+	}
+
+	l.pos++
+	return token{kind: int(c), line: line}
+}
+
+// Reads a quoted string, in which \" is a quote and a backslash before a newline continues the line
+func (l *lexer) quoted() token {
+	line := l.line
+	var text []byte
+	for l.pos++; l.pos < len(l.data); l.pos++ {
+		c := l.data[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: idToken, text: string(text), line: line}
+		case c == '\\' && l.pos+1 < len(l.data) && (l.data[l.pos+1] == '"' || l.data[l.pos+1] == '\n'):
+			l.pos++
+			if l.data[l.pos] == '"' {
+				text = append(text, '"')
+			} else {
+				l.line++
+			}
+			continue
+		case c == '\n':
+			l.line++
+		}
+		text = append(text, c)
+	}
+	panic(&SyntaxError{Line: line, Msg: "unterminated string"})
+}
+
+// Skips white space and comments: // and # to the end of the line, and /* to */
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		rest := l.data[l.pos:]
+		switch {
+		case rest[0] == '\n':
+			l.line++
+			l.pos++
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			l.pos++
+		case rest[0] == '#' || len(rest) > 1 && rest[0] == '/' && rest[1] == '/':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' {
+				l.pos++
+			}
+		case len(rest) > 1 && rest[0] == '/' && rest[1] == '*':
+			end := strings.Index(string(rest[2:]), "*/")
+			if end < 0 {
+				panic(&SyntaxError{Line: l.line, Msg: "unterminated comment"})
+			}
+			l.line += strings.Count(string(rest[:end+4]), "\n")
+			l.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// Names start with a letter or underscore, counting any non-ASCII character as a letter
+func isNameStart(b []byte) bool {
+	r, _ := utf8.DecodeRune(b)
+	return r == '_' || r >= utf8.RuneSelf || r < utf8.RuneSelf && unicode.IsLetter(r)
+}
@@ -0,0 +1,54 @@
+// Package encoding holds what the graph encodings in its subpackages share: a way for nodes to carry attributes beyond their IDs through a round trip.
+//
+// A node type that implements Attributer has its attributes written along with its ID, and a NodeFunc builds nodes from what's read back. DefaultNode, which the encodings use
+// unless they're given another NodeFunc, makes a graph.GonumNode of a node without attributes and an *AttributeNode of one with them, so attributes survive a round trip even
+// without a node type of your own.
+package encoding
+
+import (
+	"github.com/gonum/graph"
+)
+
+// A named attribute of a node. Values are strings, which every encoding can hold; it's up to the node type to format and parse anything else.
+type Attribute struct {
+	Key, Value string
+}
+
+// An Attributer is a node with attributes to be written along with its ID. The encodings reserve a few keys for their own use, such as the node's ID, and refuse to write a
+// node that uses one.
+type Attributer interface {
+	Attributes() []Attribute
+}
+
+// Makes a node from its ID and the attributes read with it, in the order they were read. An error stops the decoding and is returned from it.
+type NodeFunc func(id int, attrs []Attribute) (graph.Node, error)
+
+// A node that just holds the attributes it was read with.
+type AttributeNode struct {
+	NodeID int
+	Attrs  []Attribute
+}
+
+func (node *AttributeNode) ID() int {
+	return node.NodeID
+}
+
+func (node *AttributeNode) Attributes() []Attribute {
+	return node.Attrs
+}
+
+// The NodeFunc used by default: a graph.GonumNode if there are no attributes, and an *AttributeNode holding them otherwise.
+func DefaultNode(id int, attrs []Attribute) (graph.Node, error) {
+	if len(attrs) == 0 {
+		return graph.GonumNode(id), nil
+	}
+	return &AttributeNode{NodeID: id, Attrs: attrs}, nil
+}
+
+// The attributes of node, if it's an Attributer, and nil otherwise.
+func Attributes(node graph.Node) []Attribute {
+	if attributer, ok := node.(Attributer); ok {
+		return attributer.Attributes()
+	}
+	return nil
+}
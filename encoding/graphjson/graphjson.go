@@ -0,0 +1,239 @@
+// Package graphjson reads and writes graphs as JSON, in the node-link form networkx's node_link_data and node_link_graph use and d3's force layouts draw:
+//
+//	{
+//		"directed": true,
+//		"multigraph": false,
+//		"graph": {},
+//		"nodes": [{"id": 0}, {"id": 1, "label": "b"}],
+//		"links": [{"source": 0, "target": 1, "weight": 2.5}]
+//	}
+//
+// A node's attributes, if it's an encoding.Attributer, are written as string fields beside its id, and any field other than id is read back as an attribute: strings as they
+// are, and other values as their JSON text. A link's weight is its cost. JSON has no infinities, so a weight that isn't finite is written as the string "Infinity",
+// "-Infinity" or "NaN", and read back from one. Node IDs must be integers, multigraphs aren't supported, and the graph's own fields are ignored.
+package graphjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding"
+)
+
+type document struct {
+	Directed   bool                       `json:"directed"`
+	Multigraph bool                       `json:"multigraph"`
+	Graph      map[string]json.RawMessage `json:"graph"`
+	Nodes      []node                     `json:"nodes"`
+	Links      []link                     `json:"links"`
+}
+
+// A node is its id and then its attributes, in order, as fields of the one object
+type node struct {
+	ID    int
+	Attrs []encoding.Attribute
+}
+
+func (n node) MarshalJSON() ([]byte, error) {
+	buf := []byte(`{"id":` + strconv.Itoa(n.ID))
+	for _, attr := range n.Attrs {
+		if attr.Key == "id" {
+			return nil, fmt.Errorf("graphjson: node %d has an attribute called id, which is its ID's field", n.ID)
+		}
+		k, _ := json.Marshal(attr.Key)
+		v, _ := json.Marshal(attr.Value)
+		buf = append(append(append(append(buf, ','), k...), ':'), v...)
+	}
+	return append(buf, '}'), nil
+}
+
+func (n *node) UnmarshalJSON(text []byte) error {
+	// Go through the tokens rather than into a map so the attributes keep their order
+	dec := json.NewDecoder(bytes.NewReader(text))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	hasID := false
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		k := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		if k == "id" {
+			if err := json.Unmarshal(raw, &n.ID); err != nil {
+				return fmt.Errorf("graphjson: node id %s isn't an integer", raw)
+			}
+			hasID = true
+			continue
+		}
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			s = string(raw)
+		}
+		n.Attrs = append(n.Attrs, encoding.Attribute{Key: k, Value: s})
+	}
+	if !hasID {
+		return errors.New("graphjson: a node has no id")
+	}
+	return nil
+}
+
+type link struct {
+	Source int     `json:"source"`
+	Target int     `json:"target"`
+	Weight *weight `json:"weight,omitempty"`
+}
+
+type weight float64
+
+func (w weight) MarshalJSON() ([]byte, error) {
+	switch f := float64(w); {
+	case math.IsInf(f, 1):
+		return []byte(`"Infinity"`), nil
+	case math.IsInf(f, -1):
+		return []byte(`"-Infinity"`), nil
+	case math.IsNaN(f):
+		return []byte(`"NaN"`), nil
+	}
+	return json.Marshal(float64(w))
+}
+
+func (w *weight) UnmarshalJSON(text []byte) error {
+	var s string
+	if json.Unmarshal(text, &s) == nil {
+		switch s {
+		case "Infinity":
+			*w = weight(math.Inf(1))
+		case "-Infinity":
+			*w = weight(math.Inf(-1))
+		case "NaN":
+			*w = weight(math.NaN())
+		default:
+			return fmt.Errorf("graphjson: weight %s isn't a number", text)
+		}
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(text, &f); err != nil {
+		return fmt.Errorf("graphjson: weight %s isn't a number", text)
+	}
+	*w = weight(f)
+	return nil
+}
+
+// Writes g as JSON: every node in order of ID, with its attributes if it's an encoding.Attributer, and then every link, ordered by its source's ID and then its target's, each
+// undirected edge once. Each link's weight is its cost, as g's Cost method gives it, or 1 if g isn't a Coster. An attribute called id is an error, since that's where the
+// node's ID goes. Unmarshal reads the result back as the same graph.
+func Marshal(g graph.Graph) ([]byte, error) {
+	cost := graph.UniformCost
+	if cgraph, ok := g.(graph.Coster); ok {
+		cost = cgraph.Cost
+	}
+	doc := document{Directed: g.IsDirected(), Graph: map[string]json.RawMessage{}, Nodes: []node{}, Links: []link{}}
+
+	nodes := sortedNodes(g.NodeList())
+	for _, n := range nodes {
+		doc.Nodes = append(doc.Nodes, node{ID: n.ID(), Attrs: encoding.Attributes(n)})
+	}
+	for _, n := range nodes {
+		for _, succ := range sortedNodes(g.Successors(n)) {
+			if g.IsDirected() || n.ID() <= succ.ID() {
+				w := weight(cost(n, succ))
+				doc.Links = append(doc.Links, link{Source: n.ID(), Target: succ.ID(), Weight: &w})
+			}
+		}
+	}
+
+	text, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		// json wraps the errors of the Marshalers it calls, so dig ours back out
+		if merr, ok := err.(*json.MarshalerError); ok {
+			return nil, merr.Err
+		}
+		return nil, err
+	}
+	return append(text, '\n'), nil
+}
+
+// Reads a JSON graph: a *graph.DirectedGraph if it's directed, and a *graph.UndirectedGraph otherwise. Nodes are made by encoding.DefaultNode, and a link costs its weight, or
+// 1 if it has none. A link given more than once, either way round in an undirected graph, costs the last weight it was given.
+func Unmarshal(text []byte) (graph.Graph, error) {
+	return UnmarshalNodes(text, encoding.DefaultNode)
+}
+
+// Like Unmarshal, but nodes are made by newNode, from their IDs and attributes, which are in the order their fields are in the node's object.
+func UnmarshalNodes(text []byte, newNode encoding.NodeFunc) (graph.Graph, error) {
+	var doc document
+	if err := json.Unmarshal(text, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Multigraph {
+		return nil, errors.New("graphjson: multigraphs aren't supported")
+	}
+
+	var g graph.MutableGraph
+	if doc.Directed {
+		g = graph.NewDirectedGraph(len(doc.Nodes), len(doc.Links))
+	} else {
+		g = graph.NewUndirectedGraph(len(doc.Nodes), len(doc.Links))
+	}
+
+	nodes := make(map[int]graph.Node, len(doc.Nodes))
+	for _, elem := range doc.Nodes {
+		n, err := newNode(elem.ID, elem.Attrs)
+		if err != nil {
+			return nil, err
+		}
+		nodes[elem.ID] = n
+		g.AddNode(n, nil)
+	}
+
+	for _, l := range doc.Links {
+		var ends [2]graph.Node
+		for i, id := range [2]int{l.Source, l.Target} {
+			n, ok := nodes[id]
+			if !ok {
+				return nil, fmt.Errorf("graphjson: a link goes to node %d, which isn't in the graph", id)
+			}
+			ends[i] = n
+		}
+		e := graph.GonumEdge{H: ends[0], T: ends[1]}
+		g.AddEdge(e)
+		if l.Weight != nil {
+			g.SetEdgeCost(e, float64(*l.Weight))
+		}
+	}
+
+	return g, nil
+}
+
+func sortedNodes(nodes []graph.Node) []graph.Node {
+	nodes = append([]graph.Node(nil), nodes...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
@@ -0,0 +1,110 @@
+package graphjson
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := graph.NewGonumGraph(directed)
+		graph.GnmRandomGraph(g, 20, 40, directed, nil)
+		for i, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(i)/3-4)
+		}
+		g.AddNode(graph.GonumNode(-7), nil)
+		g.AddNode(graph.GonumNode(100), []graph.Node{graph.GonumNode(-7)})
+		g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(100), T: graph.GonumNode(-7)}, math.Inf(-1))
+		attrs := []encoding.Attribute{{Key: "label", Value: "\"quoted\"\n"}, {Key: "colour", Value: "red"}}
+		g.AddNode(&encoding.AttributeNode{NodeID: 50, Attrs: attrs}, nil)
+
+		text, err := Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := Unmarshal(text)
+		if err != nil {
+			t.Fatalf("Couldn't read back\n%s\n%v", text, err)
+		}
+		if back.IsDirected() != directed || len(back.NodeList()) != len(g.NodeList()) || len(back.EdgeList()) != len(g.EdgeList()) {
+			t.Fatalf("Read back a graph with %d nodes and %d edges, directed %t, from one with %d and %d, directed %t", len(back.NodeList()), len(back.EdgeList()), back.IsDirected(),
+				len(g.NodeList()), len(g.EdgeList()), directed)
+		}
+		for _, edge := range g.EdgeList() {
+			if got, want := back.(graph.Coster).Cost(edge.Head(), edge.Tail()), g.Cost(edge.Head(), edge.Tail()); got != want {
+				t.Errorf("Edge %v-%v costs %v after a round trip, %v before", edge.Head(), edge.Tail(), got, want)
+			}
+		}
+		for _, node := range back.NodeList() {
+			if node.ID() == 50 {
+				if got := encoding.Attributes(node); !reflect.DeepEqual(got, attrs) {
+					t.Errorf("Node 50 has the attributes %v after a round trip", got)
+				}
+			} else if _, ok := node.(graph.GonumNode); !ok {
+				t.Errorf("Node %v without attributes was read back as a %T", node, node)
+			}
+		}
+		if again, _ := Marshal(back); string(again) != string(text) {
+			t.Errorf("Marshalling the graph read back gives\n%s\nnot\n%s", again, text)
+		}
+	}
+}
+
+// As networkx's node_link_data writes a graph with node attributes of several types and some weighted edges
+const networkx = `{"directed": false, "multigraph": false, "graph": {"name": "cities"},
+	"nodes": [{"population": 700000, "id": 1, "name": "Oslo", "coast": true}, {"id": 2}, {"id": 3, "tags": ["a", "b"]}],
+	"links": [{"weight": 0.5, "source": 1, "target": 2}, {"source": 2, "target": 3, "colour": "blue"}]}`
+
+func TestUnmarshalNetworkx(t *testing.T) {
+	g, err := Unmarshal([]byte(networkx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.IsDirected() || len(g.NodeList()) != 3 {
+		t.Fatalf("Expected 3 nodes in an undirected graph, got %v, directed %t", g.NodeList(), g.IsDirected())
+	}
+	attrs := make(map[int][]encoding.Attribute)
+	for _, node := range g.NodeList() {
+		attrs[node.ID()] = encoding.Attributes(node)
+	}
+	want := map[int][]encoding.Attribute{
+		1: {{Key: "population", Value: "700000"}, {Key: "name", Value: "Oslo"}, {Key: "coast", Value: "true"}},
+		2: nil,
+		3: {{Key: "tags", Value: `["a", "b"]`}},
+	}
+	if !reflect.DeepEqual(attrs, want) {
+		t.Errorf("Expected the attributes %v, got %v", want, attrs)
+	}
+	cost := g.(graph.Coster).Cost
+	if c1, c2 := cost(graph.GonumNode(2), graph.GonumNode(1)), cost(graph.GonumNode(2), graph.GonumNode(3)); c1 != 0.5 || c2 != 1 {
+		t.Errorf("Expected the edges to cost 0.5 and 1, got %v and %v", c1, c2)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, test := range []struct {
+		text, err string
+	}{
+		{`{"nodes": [{"id": "a"}]}`, `node id "a" isn't an integer`},
+		{`{"nodes": [{"name": "a"}]}`, `a node has no id`},
+		{`{"nodes": [{"id": 1}], "links": [{"source": 1, "target": 2}]}`, `which isn't in the graph`},
+		{`{"nodes": [{"id": 1}], "links": [{"source": 1, "target": 1, "weight": "heavy"}]}`, `isn't a number`},
+		{`{"multigraph": true}`, `multigraphs aren't supported`},
+		{`{"nodes": [`, `unexpected end of JSON input`},
+	} {
+		if _, err := Unmarshal([]byte(test.text)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Expected an error saying %q for %s, got %v", test.err, test.text, err)
+		}
+	}
+
+	g := graph.NewUndirectedGraph(0, 0)
+	g.AddNode(&encoding.AttributeNode{NodeID: 1, Attrs: []encoding.Attribute{{Key: "id", Value: "one"}}}, nil)
+	if _, err := Marshal(g); err == nil || !strings.Contains(err.Error(), "attribute called id") {
+		t.Errorf("Expected an error about the id attribute, got %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+// Package graphml reads and writes graphs in GraphML, the XML format networkx's read_graphml and write_graphml, yEd and Gephi exchange graphs in.
+//
+// Nodes are named by their IDs, and an edge's cost is its weight attribute, a double, which is what networkx calls an edge's weight too:
+//
+//	<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+//		<key id="weight" for="edge" attr.name="weight" attr.type="double"></key>
+//		<graph edgedefault="directed">
+//			<node id="0"></node>
+//			<node id="1"></node>
+//			<edge source="0" target="1">
+//				<data key="weight">2.5</data>
+//			</edge>
+//		</graph>
+//	</graphml>
+//
+// Nodes that are encoding.Attributers have their attributes written as node data, each as a string attribute named by its key, and read back as such. Node IDs must be
+// integers, and a file holds one graph, all directed or all undirected. Hyperedges, ports and nested graphs aren't supported, and data on the graph itself, and on edges other
+// than their weights, is ignored.
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding"
+)
+
+const namespace = "http://graphml.graphdrawing.org/xmlns"
+
+type document struct {
+	XMLName xml.Name       `xml:"graphml"`
+	Xmlns   string         `xml:"xmlns,attr,omitempty"`
+	Keys    []key          `xml:"key"`
+	Graphs  []graphElement `xml:"graph"`
+}
+
+type key struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default *struct {
+		Value string `xml:",chardata"`
+	} `xml:"default"`
+}
+
+type graphElement struct {
+	ID          string `xml:"id,attr,omitempty"`
+	EdgeDefault string `xml:"edgedefault,attr"`
+	Nodes       []node `xml:"node"`
+	Edges       []edge `xml:"edge"`
+}
+
+type node struct {
+	ID   string `xml:"id,attr"`
+	Data []data `xml:"data"`
+}
+
+type edge struct {
+	Source   string `xml:"source,attr"`
+	Target   string `xml:"target,attr"`
+	Directed string `xml:"directed,attr,omitempty"`
+	Data     []data `xml:"data"`
+}
+
+type data struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Writes g as GraphML: every node in order of ID, with its attributes if it's an encoding.Attributer, and then every edge, ordered by its head's ID and then its tail's, each
+// undirected edge once. Each edge's weight is its cost, as g's Cost method gives it, or 1 if g isn't a Coster. Unmarshal reads the result back as the same graph.
+func Marshal(g graph.Graph) ([]byte, error) {
+	cost := graph.UniformCost
+	if cgraph, ok := g.(graph.Coster); ok {
+		cost = cgraph.Cost
+	}
+	doc := document{Xmlns: namespace, Graphs: []graphElement{{ID: "G", EdgeDefault: "undirected"}}}
+	out := &doc.Graphs[0]
+	if g.IsDirected() {
+		out.EdgeDefault = "directed"
+	}
+
+	// Every attribute key gets a GraphML key, d0, d1 and so on in order of first use, as networkx names them
+	keyIDs := make(map[string]string)
+	nodes := sortedNodes(g.NodeList())
+	for _, n := range nodes {
+		elem := node{ID: strconv.Itoa(n.ID())}
+		for _, attr := range encoding.Attributes(n) {
+			id, ok := keyIDs[attr.Key]
+			if !ok {
+				id = "d" + strconv.Itoa(len(keyIDs))
+				keyIDs[attr.Key] = id
+				doc.Keys = append(doc.Keys, key{ID: id, For: "node", Name: attr.Key, Type: "string"})
+			}
+			elem.Data = append(elem.Data, data{Key: id, Value: attr.Value})
+		}
+		out.Nodes = append(out.Nodes, elem)
+	}
+	doc.Keys = append(doc.Keys, key{ID: "weight", For: "edge", Name: "weight", Type: "double"})
+
+	for _, n := range nodes {
+		for _, succ := range sortedNodes(g.Successors(n)) {
+			if g.IsDirected() || n.ID() <= succ.ID() {
+				out.Edges = append(out.Edges, edge{
+					Source: strconv.Itoa(n.ID()),
+					Target: strconv.Itoa(succ.ID()),
+					Data:   []data{{Key: "weight", Value: formatDouble(cost(n, succ))}},
+				})
+			}
+		}
+	}
+
+	text, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(text, '\n')...), nil
+}
+
+// Doubles as XML Schema writes them, which is how GraphML says to
+func formatDouble(w float64) string {
+	switch {
+	case math.IsInf(w, 1):
+		return "INF"
+	case math.IsInf(w, -1):
+		return "-INF"
+	case math.IsNaN(w):
+		return "NaN"
+	}
+	return strconv.FormatFloat(w, 'g', -1, 64)
+}
+
+// Reads a GraphML graph: a *graph.DirectedGraph if its edges are directed by default, and a *graph.UndirectedGraph otherwise. Nodes are made by encoding.DefaultNode, and an edge
+// costs its weight, or the weight key's default, or 1 if it has neither. An edge given more than once, either way round in an undirected graph, costs the last weight it was
+// given.
+func Unmarshal(text []byte) (graph.Graph, error) {
+	return UnmarshalNodes(text, encoding.DefaultNode)
+}
+
+// Like Unmarshal, but nodes are made by newNode, from their IDs and attributes. Attributes are in the order the file lists them, including any with a default that the node
+// doesn't give itself, after the node's own.
+func UnmarshalNodes(text []byte, newNode encoding.NodeFunc) (graph.Graph, error) {
+	var doc document
+	if err := xml.Unmarshal(text, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Graphs) != 1 {
+		return nil, fmt.Errorf("graphml: expected one graph, found %d", len(doc.Graphs))
+	}
+	in := doc.Graphs[0]
+
+	var g graph.MutableGraph
+	directed := in.EdgeDefault == "directed"
+	switch in.EdgeDefault {
+	case "directed":
+		g = graph.NewDirectedGraph(len(in.Nodes), len(in.Edges))
+	case "undirected":
+		g = graph.NewUndirectedGraph(len(in.Nodes), len(in.Edges))
+	default:
+		return nil, fmt.Errorf("graphml: edgedefault %q isn't directed or undirected", in.EdgeDefault)
+	}
+
+	nodeKeys := make(map[string]key)
+	var weightKey *key
+	for i, k := range doc.Keys {
+		switch {
+		case k.For == "node":
+			nodeKeys[k.ID] = k
+		case k.For == "edge" && k.Name == "weight":
+			weightKey = &doc.Keys[i]
+		}
+	}
+
+	nodes := make(map[string]graph.Node, len(in.Nodes))
+	for _, elem := range in.Nodes {
+		id, err := strconv.Atoi(elem.ID)
+		if err != nil {
+			return nil, fmt.Errorf("graphml: node %q isn't an integer", elem.ID)
+		}
+		var attrs []encoding.Attribute
+		given := make(map[string]bool)
+		for _, d := range elem.Data {
+			if k, ok := nodeKeys[d.Key]; ok {
+				attrs = append(attrs, encoding.Attribute{Key: k.Name, Value: d.Value})
+				given[d.Key] = true
+			}
+		}
+		for _, k := range doc.Keys {
+			if k.For == "node" && k.Default != nil && !given[k.ID] {
+				attrs = append(attrs, encoding.Attribute{Key: k.Name, Value: k.Default.Value})
+			}
+		}
+
+		n, err := newNode(id, attrs)
+		if err != nil {
+			return nil, err
+		}
+		nodes[elem.ID] = n
+		g.AddNode(n, nil)
+	}
+
+	for _, elem := range in.Edges {
+		if elem.Directed != "" && (elem.Directed == "true") != directed {
+			return nil, fmt.Errorf("graphml: the edge from %s to %s has directed=%s in a graph whose edges are %s", elem.Source, elem.Target, elem.Directed, in.EdgeDefault)
+		}
+		var ends [2]graph.Node
+		for i, id := range [2]string{elem.Source, elem.Target} {
+			n, ok := nodes[id]
+			if !ok {
+				return nil, fmt.Errorf("graphml: an edge goes to node %q, which isn't in the graph", id)
+			}
+			ends[i] = n
+		}
+
+		weight, weighted := "", false
+		if weightKey != nil && weightKey.Default != nil {
+			weight, weighted = weightKey.Default.Value, true
+		}
+		for _, d := range elem.Data {
+			if weightKey != nil && d.Key == weightKey.ID {
+				weight, weighted = d.Value, true
+			}
+		}
+
+		e := graph.GonumEdge{H: ends[0], T: ends[1]}
+		g.AddEdge(e)
+		if weighted {
+			w, err := strconv.ParseFloat(weight, 64)
+			if err != nil {
+				return nil, fmt.Errorf("graphml: the edge from %s to %s has weight %q, which isn't a number", elem.Source, elem.Target, weight)
+			}
+			g.SetEdgeCost(e, w)
+		}
+	}
+
+	return g, nil
+}
+
+func sortedNodes(nodes []graph.Node) []graph.Node {
+	nodes = append([]graph.Node(nil), nodes...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
@@ -0,0 +1,136 @@
+package graphml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/encoding"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := graph.NewGonumGraph(directed)
+		graph.GnmRandomGraph(g, 20, 40, directed, nil)
+		for i, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(i)/3-4)
+		}
+		g.AddNode(graph.GonumNode(-7), nil)
+		g.AddNode(graph.GonumNode(100), []graph.Node{graph.GonumNode(-7)})
+		g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(100), T: graph.GonumNode(-7)}, math.Inf(1))
+		g.AddNode(&encoding.AttributeNode{NodeID: 50, Attrs: []encoding.Attribute{{Key: "label", Value: "a <b> & c"}, {Key: "colour", Value: "red"}}}, nil)
+
+		text, err := Marshal(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := Unmarshal(text)
+		if err != nil {
+			t.Fatalf("Couldn't read back\n%s\n%v", text, err)
+		}
+		if back.IsDirected() != directed || len(back.NodeList()) != len(g.NodeList()) || len(back.EdgeList()) != len(g.EdgeList()) {
+			t.Fatalf("Read back a graph with %d nodes and %d edges, directed %t, from one with %d and %d, directed %t", len(back.NodeList()), len(back.EdgeList()), back.IsDirected(),
+				len(g.NodeList()), len(g.EdgeList()), directed)
+		}
+		for _, edge := range g.EdgeList() {
+			if got, want := back.(graph.Coster).Cost(edge.Head(), edge.Tail()), g.Cost(edge.Head(), edge.Tail()); got != want {
+				t.Errorf("Edge %v-%v costs %v after a round trip, %v before", edge.Head(), edge.Tail(), got, want)
+			}
+		}
+		for _, node := range back.NodeList() {
+			if node.ID() == 50 {
+				if attrs := encoding.Attributes(node); !reflect.DeepEqual(attrs, []encoding.Attribute{{Key: "label", Value: "a <b> & c"}, {Key: "colour", Value: "red"}}) {
+					t.Errorf("Node 50 has the attributes %v after a round trip", attrs)
+				}
+			} else if _, ok := node.(graph.GonumNode); !ok {
+				t.Errorf("Node %v without attributes was read back as a %T", node, node)
+			}
+		}
+		if again, _ := Marshal(back); string(again) != string(text) {
+			t.Errorf("Marshalling the graph read back gives\n%s\nnot\n%s", again, text)
+		}
+	}
+}
+
+// As networkx's write_graphml writes a graph with a node attribute and weighted edges
+const networkx = `<?xml version='1.0' encoding='utf-8'?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">
+  <key id="d1" for="edge" attr.name="weight" attr.type="double" />
+  <key id="d0" for="node" attr.name="city" attr.type="string">
+    <default>nowhere</default>
+  </key>
+  <graph edgedefault="undirected">
+    <node id="1">
+      <data key="d0">Oslo</data>
+    </node>
+    <node id="2" />
+    <node id="3" />
+    <edge source="1" target="2">
+      <data key="d1">0.5</data>
+    </edge>
+    <edge source="2" target="3" />
+  </graph>
+</graphml>
+`
+
+type city struct {
+	id   int
+	name string
+}
+
+func (c city) ID() int {
+	return c.id
+}
+
+func TestUnmarshalNetworkx(t *testing.T) {
+	g, err := UnmarshalNodes([]byte(networkx), func(id int, attrs []encoding.Attribute) (graph.Node, error) {
+		if len(attrs) != 1 || attrs[0].Key != "city" {
+			return nil, errors.New("expected a city")
+		}
+		return city{id, attrs[0].Value}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.IsDirected() || len(g.NodeList()) != 3 {
+		t.Fatalf("Expected 3 nodes in an undirected graph, got %v, directed %t", g.NodeList(), g.IsDirected())
+	}
+	names := make(map[int]string)
+	for _, node := range g.NodeList() {
+		names[node.ID()] = node.(city).name
+	}
+	if want := map[int]string{1: "Oslo", 2: "nowhere", 3: "nowhere"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected the cities %v, got %v", want, names)
+	}
+	cost := g.(graph.Coster).Cost
+	if c1, c2 := cost(graph.GonumNode(2), graph.GonumNode(1)), cost(graph.GonumNode(2), graph.GonumNode(3)); c1 != 0.5 || c2 != 1 {
+		t.Errorf("Expected the edges to cost 0.5 and 1, got %v and %v", c1, c2)
+	}
+
+	if _, err := UnmarshalNodes([]byte(networkx), func(id int, attrs []encoding.Attribute) (graph.Node, error) {
+		return nil, errors.New("no nodes today")
+	}); err == nil || err.Error() != "no nodes today" {
+		t.Errorf("Expected the NodeFunc's error, got %v", err)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, test := range []struct {
+		text, err string
+	}{
+		{`<graphml><graph edgedefault="directed"><node id="a"/></graph></graphml>`, `node "a" isn't an integer`},
+		{`<graphml><graph edgedefault="sideways"/></graphml>`, `isn't directed or undirected`},
+		{`<graphml><graph edgedefault="directed"><node id="1"/><edge source="1" target="2"/></graph></graphml>`, `which isn't in the graph`},
+		{`<graphml><graph edgedefault="directed"><node id="1"/><edge source="1" target="1" directed="false"/></graph></graphml>`, `directed=false`},
+		{`<graphml><key id="w" for="edge" attr.name="weight"/><graph edgedefault="directed"><node id="1"/><edge source="1" target="1"><data key="w">heavy</data></edge></graph></graphml>`, `isn't a number`},
+		{`<graphml></graphml>`, `expected one graph, found 0`},
+		{`<graphml><graph`, `XML syntax error`},
+	} {
+		if _, err := Unmarshal([]byte(test.text)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("Expected an error saying %q for %s, got %v", test.err, test.text, err)
+		}
+	}
+}
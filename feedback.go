@@ -0,0 +1,460 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Finds a small set of edges whose removal leaves the directed graph acyclic: a feedback arc set. Reversing them instead works just as well, which is how a nearly acyclic
+// dependency graph can be forced into layers for SugiyamaLayout. Finding the cheapest such set is NP-hard, so this is a heuristic, weighted by Cost (interpreted as in AStar).
+//
+// Only edges within a strongly connected component can be on a cycle, so each component is ordered separately by the greedy heuristic of Eades, Lin and Smyth[1]: sinks go to
+// the end of the order and sources to the front as they appear, and when there are neither, the node whose outgoing edges outweigh its incoming ones by the most goes to the
+// front. The edges that point backwards in that order are a feedback arc set, which is then made minimal by putting back, most expensive first, every edge that no longer closes
+// a cycle. Self loops are always included. It runs in O(m log n) plus O(m) for every edge that's tried again.
+//
+// [1] P. Eades, X. Lin and W. F. Smyth, "A fast and effective heuristic for the feedback arc set problem", Information Processing Letters 47 (1993)
+func FeedbackArcSet(graph Graph, Cost func(Node, Node) float64) (arcs []Edge) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	_, _, componentOf := Condense(graph)
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+
+	// The edges within components, which are the only ones that matter
+	succs := make(map[int][]Node, len(nodes))
+	preds := make(map[int][]Node, len(nodes))
+	var loops []Edge
+	for _, node := range nodes {
+		next := graph.Successors(node)
+		sort.Sort(byID(next))
+		for _, succ := range next {
+			switch {
+			case succ.ID() == node.ID():
+				loops = append(loops, GonumEdge{H: node, T: succ})
+			case componentOf[succ.ID()] == componentOf[node.ID()]:
+				succs[node.ID()] = append(succs[node.ID()], succ)
+				preds[succ.ID()] = append(preds[succ.ID()], node)
+			}
+		}
+	}
+
+	position := eadesOrder(nodes, succs, preds, Cost)
+	var back []WeightedEdge
+	for _, node := range nodes {
+		for _, succ := range succs[node.ID()] {
+			if position[succ.ID()] < position[node.ID()] {
+				back = append(back, WeightedEdge{Edge: GonumEdge{H: node, T: succ}, Weight: Cost(node, succ)})
+			}
+		}
+	}
+
+	// Put back every edge that doesn't close a cycle with the edges kept so far, most expensive first
+	sort.Stable(sort.Reverse(edgeSorter(back)))
+	removed := make(map[[2]int]bool, len(back))
+	for _, edge := range back {
+		removed[[2]int{edge.Head().ID(), edge.Tail().ID()}] = true
+	}
+	for _, edge := range back {
+		key := [2]int{edge.Head().ID(), edge.Tail().ID()}
+		if reaches(edge.Tail(), edge.Head(), succs, removed) {
+			arcs = append(arcs, edge.Edge)
+		} else {
+			delete(removed, key)
+		}
+	}
+
+	return append(loops, arcs...)
+}
+
+// Orders nodes by the Eades-Lin-Smyth heuristic over the edges in succs and preds, returning each node's position. Every node is ordered, but only nodes joined by edges are ordered
+// relative to each other in any meaningful way.
+func eadesOrder(nodes []Node, succs, preds map[int][]Node, Cost func(Node, Node) float64) (position map[int]int) {
+	outCount, inCount := make(map[int]int, len(nodes)), make(map[int]int, len(nodes))
+	delta := make(map[int]float64, len(nodes)) // Outgoing minus incoming weight
+	for _, node := range nodes {
+		for _, succ := range succs[node.ID()] {
+			c := Cost(node, succ)
+			outCount[node.ID()]++
+			inCount[succ.ID()]++
+			delta[node.ID()] += c
+			delta[succ.ID()] -= c
+		}
+	}
+
+	// Largest delta first, ties to the lowest ID
+	queue := NewIndexedHeap(func(a, b HeapItem) bool {
+		if a.Key.(float64) != b.Key.(float64) {
+			return a.Key.(float64) > b.Key.(float64)
+		}
+		return a.Node.ID() < b.Node.ID()
+	})
+	var sinks, sources []Node
+	for _, node := range nodes {
+		queue.Push(node, delta[node.ID()])
+		switch {
+		case outCount[node.ID()] == 0:
+			sinks = append(sinks, node)
+		case inCount[node.ID()] == 0:
+			sources = append(sources, node)
+		}
+	}
+
+	var front, back []Node
+	take := func(node Node) {
+		queue.Remove(node)
+		for _, succ := range succs[node.ID()] {
+			if queue.Contains(succ) {
+				delta[succ.ID()] += Cost(node, succ)
+				queue.Fix(succ, delta[succ.ID()])
+				if inCount[succ.ID()]--; inCount[succ.ID()] == 0 {
+					sources = append(sources, succ)
+				}
+			}
+		}
+		for _, pred := range preds[node.ID()] {
+			if queue.Contains(pred) {
+				delta[pred.ID()] -= Cost(pred, node)
+				queue.Fix(pred, delta[pred.ID()])
+				if outCount[pred.ID()]--; outCount[pred.ID()] == 0 {
+					sinks = append(sinks, pred)
+				}
+			}
+		}
+	}
+
+	for queue.Len() != 0 {
+		switch {
+		case len(sinks) != 0:
+			node := sinks[0]
+			sinks = sinks[1:]
+			if queue.Contains(node) {
+				take(node)
+				back = append(back, node)
+			}
+		case len(sources) != 0:
+			node := sources[0]
+			sources = sources[1:]
+			if queue.Contains(node) {
+				take(node)
+				front = append(front, node)
+			}
+		default:
+			node := queue.Peek().Node
+			take(node)
+			front = append(front, node)
+		}
+	}
+
+	position = make(map[int]int, len(nodes))
+	for i, node := range front {
+		position[node.ID()] = i
+	}
+	for i, node := range back {
+		position[node.ID()] = len(nodes) - 1 - i
+	}
+
+	return position
+}
+
+// Whether to can be reached from from by edges in succs that aren't removed
+func reaches(from, to Node, succs map[int][]Node, removed map[[2]int]bool) bool {
+	seen := map[int]bool{from.ID(): true}
+	stack := []Node{from}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if node.ID() == to.ID() {
+			return true
+		}
+		for _, succ := range succs[node.ID()] {
+			if !seen[succ.ID()] && !removed[[2]int{node.ID(), succ.ID()}] {
+				seen[succ.ID()] = true
+				stack = append(stack, succ)
+			}
+		}
+	}
+
+	return false
+}
+
+// Finds a small set of nodes whose removal leaves the directed graph acyclic: a feedback vertex set. In a wait-for or resource allocation graph, these are the processes to
+// abort (or resources to preempt) to break every deadlock. Finding the smallest one is NP-hard; this is a fast heuristic, and MinimumFeedbackVertexSet finds the smallest
+// for small graphs.
+//
+// The graph is first shrunk by the reductions of Levy and Low[1], none of which lose optimality: nodes with no predecessors or no successors are on no cycle and are dropped,
+// nodes with a self loop must be in the set, and a node with a single predecessor (or successor) is merged into it, since any cycle through the one passes through the other.
+// When nothing reduces, the node with the most cycles through it by the measure of in-degree times out-degree goes into the set, and the graph is reduced again. Finally the set
+// is made minimal by taking out every node that isn't needed after all.
+//
+// [1] H. Levy and D. W. Low, "A contraction algorithm for finding small cycle cutsets", Journal of Algorithms 9 (1988)
+func FeedbackVertexSet(graph Graph) (nodes []Node) {
+	fvs := newFVSGraph(graph)
+	var chosen []int
+	for {
+		chosen = append(chosen, fvs.reduce()...)
+		v, ok := fvs.densest()
+		if !ok {
+			break
+		}
+		chosen = append(chosen, v)
+		fvs.remove(v)
+	}
+
+	return fvs.minimal(graph, chosen)
+}
+
+// Finds a smallest feedback vertex set of the directed graph by branch and bound: after reducing the graph as FeedbackVertexSet does, the densest node is either in the set, and
+// removed, or not, and merged into its neighbors by linking each of its predecessors to each of its successors. Branches that can't beat FeedbackVertexSet's answer, or the best
+// found so far, are cut off, using disjoint two cycles (each of which needs a node of its own) as the lower bound.
+//
+// The search takes exponential time, so it's only for small graphs, up to a few dozen nodes that survive the reductions. Each branch counts as an expansion against budget;
+// if it runs out, ErrBudgetExhausted is returned along with the best set found so far, which is still a feedback vertex set, just maybe not the smallest.
+func MinimumFeedbackVertexSet(graph Graph, budget SearchBudget) (nodes []Node, err error) {
+	root := newFVSGraph(graph)
+	best := FeedbackVertexSet(graph)
+	bestIDs := make([]int, len(best))
+	for i, node := range best {
+		bestIDs[i] = node.ID()
+	}
+
+	cancel := budget.canceller(nil)
+	var branch func(fvs *fvsGraph, chosen []int)
+	branch = func(fvs *fvsGraph, chosen []int) {
+		if err != nil {
+			return
+		}
+		if err = cancel.err(); err != nil {
+			return
+		}
+
+		chosen = append(chosen, fvs.reduce()...)
+		if len(chosen)+fvs.lowerBound() >= len(bestIDs) {
+			return
+		}
+		v, ok := fvs.densest()
+		if !ok {
+			bestIDs = append([]int(nil), chosen...)
+			return
+		}
+
+		with := fvs.copy()
+		with.remove(v)
+		branch(with, append(chosen[:len(chosen):len(chosen)], v))
+
+		fvs.bypass(v)
+		branch(fvs, chosen)
+	}
+	branch(root, nil)
+
+	return root.minimal(graph, bestIDs), err
+}
+
+// The part of a graph a feedback vertex set search is still working on, as adjacency sets of node IDs
+type fvsGraph struct {
+	succs, preds map[int]map[int]bool
+	nodes        map[int]Node
+}
+
+func newFVSGraph(graph Graph) *fvsGraph {
+	fvs := &fvsGraph{succs: make(map[int]map[int]bool), preds: make(map[int]map[int]bool), nodes: make(map[int]Node)}
+	for _, node := range graph.NodeList() {
+		fvs.nodes[node.ID()] = node
+		fvs.succs[node.ID()] = make(map[int]bool)
+		fvs.preds[node.ID()] = make(map[int]bool)
+	}
+	for _, node := range graph.NodeList() {
+		for _, succ := range graph.Successors(node) {
+			fvs.succs[node.ID()][succ.ID()] = true
+			fvs.preds[succ.ID()][node.ID()] = true
+		}
+	}
+
+	return fvs
+}
+
+func (fvs *fvsGraph) copy() *fvsGraph {
+	c := &fvsGraph{succs: make(map[int]map[int]bool, len(fvs.succs)), preds: make(map[int]map[int]bool, len(fvs.preds)), nodes: fvs.nodes}
+	for v, succs := range fvs.succs {
+		c.succs[v] = make(map[int]bool, len(succs))
+		for w := range succs {
+			c.succs[v][w] = true
+		}
+	}
+	for v, preds := range fvs.preds {
+		c.preds[v] = make(map[int]bool, len(preds))
+		for w := range preds {
+			c.preds[v][w] = true
+		}
+	}
+
+	return c
+}
+
+func (fvs *fvsGraph) remove(v int) {
+	for w := range fvs.succs[v] {
+		delete(fvs.preds[w], v)
+	}
+	for w := range fvs.preds[v] {
+		delete(fvs.succs[w], v)
+	}
+	delete(fvs.succs, v)
+	delete(fvs.preds, v)
+}
+
+// Removes v, linking each of its predecessors to each of its successors so every cycle through v still exists without it
+func (fvs *fvsGraph) bypass(v int) {
+	for p := range fvs.preds[v] {
+		for s := range fvs.succs[v] {
+			if p != v && s != v {
+				fvs.succs[p][s] = true
+				fvs.preds[s][p] = true
+			}
+		}
+	}
+	fvs.remove(v)
+}
+
+// Applies the Levy-Low reductions until none applies, returning the nodes they found must be in the set. The IDs are visited in sorted order, so the result is deterministic.
+func (fvs *fvsGraph) reduce() (forced []int) {
+	for changed := true; changed; {
+		changed = false
+		ids := make([]int, 0, len(fvs.succs))
+		for v := range fvs.succs {
+			ids = append(ids, v)
+		}
+		sort.Ints(ids)
+
+		for _, v := range ids {
+			succs, ok := fvs.succs[v]
+			if !ok {
+				continue
+			}
+			preds := fvs.preds[v]
+			switch {
+			case succs[v]:
+				forced = append(forced, v)
+				fvs.remove(v)
+			case len(succs) == 0 || len(preds) == 0:
+				fvs.remove(v)
+			case len(succs) == 1 || len(preds) == 1:
+				fvs.bypass(v)
+			default:
+				continue
+			}
+			changed = true
+		}
+	}
+
+	return forced
+}
+
+// The node with the largest product of in- and out-degree, ties going to the lowest ID, and false if the graph is empty
+func (fvs *fvsGraph) densest() (v int, ok bool) {
+	best := -1
+	for w, succs := range fvs.succs {
+		score := len(succs) * len(fvs.preds[w])
+		if score > best || score == best && w < v {
+			v, best, ok = w, score, true
+		}
+	}
+
+	return v, ok
+}
+
+// A lower bound on the size of a feedback vertex set of a reduced graph: the number of node disjoint two cycles found greedily, and at least one if there are any nodes left
+func (fvs *fvsGraph) lowerBound() int {
+	if len(fvs.succs) == 0 {
+		return 0
+	}
+
+	ids := make([]int, 0, len(fvs.succs))
+	for v := range fvs.succs {
+		ids = append(ids, v)
+	}
+	sort.Ints(ids)
+
+	used := make(map[int]bool)
+	bound := 0
+	for _, v := range ids {
+		if used[v] {
+			continue
+		}
+		for w := range fvs.succs[v] {
+			if !used[w] && fvs.preds[v][w] {
+				used[v], used[w] = true, true
+				bound++
+				break
+			}
+		}
+	}
+	if bound == 0 {
+		return 1
+	}
+
+	return bound
+}
+
+// Drops every node of chosen that the rest of it doesn't need to break all the cycles of graph, last chosen first, and returns the rest as graph's nodes in order of ID
+func (fvs *fvsGraph) minimal(graph Graph, chosen []int) []Node {
+	in := make(map[int]bool, len(chosen))
+	for _, v := range chosen {
+		in[v] = true
+	}
+	for i := len(chosen) - 1; i >= 0; i-- {
+		delete(in, chosen[i])
+		if !acyclicWithoutNodes(graph, in) {
+			in[chosen[i]] = true
+		}
+	}
+
+	nodes := make([]Node, 0, len(in))
+	for v := range in {
+		nodes = append(nodes, fvs.nodes[v])
+	}
+	sort.Sort(byID(nodes))
+
+	return nodes
+}
+
+// Whether graph has no cycles once the removed nodes are taken out, by Kahn's algorithm
+func acyclicWithoutNodes(graph Graph, removed map[int]bool) bool {
+	indegree := make(map[int]int)
+	var ready []Node
+	left := 0
+	for _, node := range graph.NodeList() {
+		if removed[node.ID()] {
+			continue
+		}
+		left++
+		for _, pred := range graph.Predecessors(node) {
+			if !removed[pred.ID()] {
+				indegree[node.ID()]++
+			}
+		}
+		if indegree[node.ID()] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	for len(ready) != 0 {
+		node := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		left--
+		for _, succ := range graph.Successors(node) {
+			if removed[succ.ID()] {
+				continue
+			}
+			if indegree[succ.ID()]--; indegree[succ.ID()] == 0 {
+				ready = append(ready, succ)
+			}
+		}
+	}
+
+	return left == 0
+}
@@ -0,0 +1,137 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Whether graph without the removed edges and nodes has no cycles
+func acyclicWithout(g graph.Graph, edges []graph.Edge, nodes []graph.Node) bool {
+	dst := graph.NewGonumGraph(true)
+	gone := make(map[[2]int]bool)
+	for _, edge := range edges {
+		gone[[2]int{edge.Head().ID(), edge.Tail().ID()}] = true
+	}
+	goneNodes := make(map[int]bool)
+	for _, node := range nodes {
+		goneNodes[node.ID()] = true
+	}
+	for _, node := range g.NodeList() {
+		if !goneNodes[node.ID()] {
+			dst.AddNode(node, nil)
+		}
+	}
+	for _, edge := range g.EdgeList() {
+		if !gone[[2]int{edge.Head().ID(), edge.Tail().ID()}] && !goneNodes[edge.Head().ID()] && !goneNodes[edge.Tail().ID()] {
+			if edge.Head().ID() == edge.Tail().ID() {
+				return false
+			}
+			dst.AddEdge(edge)
+		}
+	}
+
+	return len(graph.Tarjan(dst)) == len(dst.NodeList())
+}
+
+func TestFeedbackArcSet(t *testing.T) {
+	dag := graph.NewGonumGraph(true)
+	graph.RandomDAG(dag, 6, 5, 0.3, false, rand.New(rand.NewSource(1)))
+	if arcs := graph.FeedbackArcSet(dag, nil); len(arcs) != 0 {
+		t.Errorf("Expected no feedback arcs in a DAG, got %v", arcs)
+	}
+
+	// A cycle is broken at its cheapest edge
+	cycle := graph.NewGonumGraph(true)
+	for i := 0; i < 5; i++ {
+		cycle.AddNode(graph.GonumNode(i), nil)
+	}
+	for i := 0; i < 5; i++ {
+		edge := graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode((i + 1) % 5)}
+		cycle.AddEdge(edge)
+		cycle.SetEdgeCost(edge, 10)
+	}
+	cycle.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(3), T: graph.GonumNode(4)}, 1)
+	if arcs := graph.FeedbackArcSet(cycle, nil); len(arcs) != 1 || arcs[0].Head().ID() != 3 || arcs[0].Tail().ID() != 4 {
+		t.Errorf("Expected the cheapest edge 3->4 to break the cycle, got %v", arcs)
+	}
+
+	// A nearly acyclic dependency graph: a DAG with a few edges pointing back up, and a self loop
+	nearly := graph.NewGonumGraph(true)
+	graph.RandomDAG(nearly, 8, 6, 0.3, false, rand.New(rand.NewSource(2)))
+	src := rand.New(rand.NewSource(3))
+	n := len(nearly.NodeList())
+	for i := 0; i < 5; i++ {
+		u, v := src.Intn(n), src.Intn(n)
+		if u < v {
+			u, v = v, u
+		}
+		nearly.AddEdge(graph.GonumEdge{H: graph.GonumNode(u), T: graph.GonumNode(v)})
+	}
+	nearly.AddEdge(graph.GonumEdge{H: graph.GonumNode(7), T: graph.GonumNode(7)})
+	arcs := graph.FeedbackArcSet(nearly, nil)
+	if !acyclicWithout(nearly, arcs, nil) {
+		t.Errorf("Removing %v leaves a cycle", arcs)
+	}
+	if len(arcs) > 6 {
+		t.Errorf("Expected at most the 6 added edges to be removed, got %v", arcs)
+	}
+	for i := range arcs {
+		if acyclicWithout(nearly, append(append([]graph.Edge(nil), arcs[:i]...), arcs[i+1:]...), nil) {
+			t.Errorf("The feedback arc set isn't minimal: %v isn't needed", arcs[i])
+		}
+	}
+
+	random := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(random, 60, 0.08, true, rand.New(rand.NewSource(4)))
+	if arcs := graph.FeedbackArcSet(random, nil); !acyclicWithout(random, arcs, nil) || len(arcs) >= len(random.EdgeList())/2 {
+		t.Errorf("Expected removing fewer than half of the %d edges to leave a random graph acyclic, removed %d", len(random.EdgeList()), len(arcs))
+	}
+}
+
+func TestFeedbackVertexSet(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 30; trial++ {
+		g := graph.NewGonumGraph(true)
+		graph.GnpRandomGraph(g, 9, 0.25, true, src)
+		nodes := g.NodeList()
+
+		// The smallest by brute force
+		smallest := len(nodes)
+		for mask := 0; mask < 1<<uint(len(nodes)); mask++ {
+			var subset []graph.Node
+			for i, node := range nodes {
+				if mask&(1<<uint(i)) != 0 {
+					subset = append(subset, node)
+				}
+			}
+			if len(subset) < smallest && acyclicWithout(g, nil, subset) {
+				smallest = len(subset)
+			}
+		}
+
+		heuristic := graph.FeedbackVertexSet(g)
+		if !acyclicWithout(g, nil, heuristic) {
+			t.Errorf("Removing %v leaves a cycle", heuristic)
+		}
+		for i := range heuristic {
+			if acyclicWithout(g, nil, append(append([]graph.Node(nil), heuristic[:i]...), heuristic[i+1:]...)) {
+				t.Errorf("The feedback vertex set %v isn't minimal: %v isn't needed", heuristic, heuristic[i])
+			}
+		}
+
+		exact, err := graph.MinimumFeedbackVertexSet(g, graph.SearchBudget{})
+		if err != nil || len(exact) != smallest || !acyclicWithout(g, nil, exact) {
+			t.Errorf("Expected a feedback vertex set of %d nodes, got %v, %v", smallest, exact, err)
+		}
+	}
+
+	// A dense graph that the reductions can't touch, with a budget too small to finish
+	dense := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(dense, 30, 0.3, true, rand.New(rand.NewSource(2)))
+	set, err := graph.MinimumFeedbackVertexSet(dense, graph.SearchBudget{MaxExpansions: 10})
+	if err != graph.ErrBudgetExhausted || !acyclicWithout(dense, nil, set) {
+		t.Errorf("Expected ErrBudgetExhausted and a feedback vertex set all the same, got %v, %v", set, err)
+	}
+}
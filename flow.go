@@ -0,0 +1,538 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Returned when a flow has more flow into some node than out of it, or the other way around, at a node other than its source or sink.
+var ErrFlowNotConserved = errors.New("Flow is not conserved")
+
+// A FlowPath is an amount of flow routed along a path or around a cycle. A cycle's first and last nodes are the same.
+type FlowPath struct {
+	Nodes []Node
+	Flow  float64
+}
+
+// Decomposes a flow from source to sink into flow along paths from source to sink, plus flow around cycles, which together add up to the flow on every edge. A flow is given
+// by the amount on each edge, keyed by {head ID, tail ID}; amounts that aren't positive are ignored. Every decomposed path or cycle takes all the remaining flow from at least one
+// edge, so there are at most as many of them as edges with flow (one more if flow comes back into source, when the last path may stop short). Paths are found first, so a flow
+// with no cycles (such as any min-cost flow) is all paths. The nodes are graph's, looked up by ID.
+//
+// Returns ErrFlowNotConserved if flow in and out doesn't balance at some node other than source and sink, along with what was decomposed before the imbalance was found.
+// Amounts within a part in a billion of the largest are taken as rounding errors and ignored.
+func DecomposeFlow(graph Graph, flow map[[2]int]float64, source, sink Node) (paths, cycles []FlowPath, err error) {
+	nodes := make(map[int]Node)
+	for _, node := range graph.NodeList() {
+		nodes[node.ID()] = node
+	}
+	node := func(id int) Node {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		return GonumNode(id)
+	}
+
+	largest := 0.0
+	for _, f := range flow {
+		largest = math.Max(largest, f)
+	}
+	eps := 1e-9 * largest
+
+	// What's left to decompose, and each node's edges out in order of tail, with a cursor past the ones used up
+	left := make(map[[2]int]float64, len(flow))
+	out := make(map[int][]int)
+	for edge, f := range flow {
+		if f > eps {
+			left[edge] = f
+			out[edge[0]] = append(out[edge[0]], edge[1])
+		}
+	}
+	for _, tails := range out {
+		sort.Ints(tails)
+	}
+	cursor := make(map[int]int)
+	next := func(u int) (int, bool) {
+		tails := out[u]
+		for cursor[u] < len(tails) {
+			if left[[2]int{u, tails[cursor[u]]}] > eps {
+				return tails[cursor[u]], true
+			}
+			cursor[u]++
+		}
+		return 0, false
+	}
+
+	// Takes the most it can, up to limit, off every edge of walk, returned as a FlowPath
+	route := func(walk []int, limit float64) FlowPath {
+		f := limit
+		for i := 0; i+1 < len(walk); i++ {
+			f = math.Min(f, left[[2]int{walk[i], walk[i+1]}])
+		}
+		route := FlowPath{Flow: f}
+		for i, id := range walk {
+			route.Nodes = append(route.Nodes, node(id))
+			if i+1 < len(walk) {
+				left[[2]int{id, walk[i+1]}] -= f
+			}
+		}
+		return route
+	}
+
+	// Walks forward from start until it reaches stop, or runs out of edges (stuck), taking off any cycles met on the way. Returns the walk, which is a path without repeated nodes.
+	walk := func(start, stop int) (walk []int, stuck bool) {
+		walk = []int{start}
+		index := map[int]int{start: 0}
+		for {
+			u := walk[len(walk)-1]
+			if len(walk) > 1 && u == stop {
+				return walk, false
+			}
+			v, ok := next(u)
+			if !ok {
+				return walk, true
+			}
+			if i, seen := index[v]; seen {
+				cycles = append(cycles, route(append(append([]int(nil), walk[i:]...), v), math.Inf(1)))
+				for _, id := range walk[i+1:] {
+					delete(index, id)
+				}
+				walk = walk[:i+1]
+				continue
+			}
+			index[v] = len(walk)
+			walk = append(walk, v)
+		}
+	}
+
+	// The flow's value is what leaves source and doesn't come back; any more than that around source is cycles
+	value := 0.0
+	for edge, f := range left {
+		if edge[0] == source.ID() {
+			value += f
+		}
+		if edge[1] == source.ID() {
+			value -= f
+		}
+	}
+	for value > eps {
+		w, stuck := walk(source.ID(), sink.ID())
+		if stuck {
+			return paths, cycles, ErrFlowNotConserved
+		}
+		path := route(w, value)
+		paths = append(paths, path)
+		value -= path.Flow
+	}
+
+	// Whatever's left must go around in circles, which walking from any edge with flow left finds, until the walk is back where it started with nowhere to go
+	heads := make([]int, 0, len(out))
+	for head := range out {
+		heads = append(heads, head)
+	}
+	sort.Ints(heads)
+	for _, head := range heads {
+		if w, _ := walk(head, head); len(w) > 1 {
+			return paths, cycles, ErrFlowNotConserved
+		}
+	}
+
+	return paths, cycles, nil
+}
+
+// Returned when no flow meets the demands within the capacities or bounds.
+var ErrInfeasible = errors.New("No feasible flow")
+
+// Finds a circulation: a flow on every edge of graph between its lower and upper bound, such that the flow into each node less the flow out of it is the node's demand. Nodes with
+// negative demands are supplies, and demands must add up to zero. Lower and Upper give each edge's bounds, by head and tail; if Lower is nil every lower bound is 0, and if Upper
+// is nil it's the graph's Cost if it's a Coster, and 1 otherwise. If Demand is nil every demand is 0. Edges are as Successors gives them, so an undirected edge is a pair of
+// opposite edges, bounded separately.
+//
+// The lower bounds are taken off by the standard transformation: sending every edge's lower bound leaves each node short or over by some amount, which is then made up by a
+// maximum flow, within the rest of the capacity, from the nodes over to those short. Returns the flow on every edge with any, keyed by {head ID, tail ID}.
+//
+// If there's no circulation, returns ErrInfeasible along with a cut that proves it, by Hoffman's theorem[1]: a set of nodes demanding more than the most that can flow into it
+// (the upper bounds of edges in less the lower bounds of edges out), or less than the least (the lower bounds in less the upper bounds out). The cut is nil if some edge's lower
+// bound is above its upper bound.
+//
+// [1] A. J. Hoffman, "Some recent applications of the theory of linear inequalities to extremal combinatorial analysis", Proceedings of Symposia in Applied Mathematics 10 (1960)
+func FeasibleCirculation(graph Graph, Lower, Upper func(Node, Node) float64, Demand func(Node) float64) (flow map[[2]int]float64, cut []Node, err error) {
+	if Lower == nil {
+		Lower = func(Node, Node) float64 { return 0 }
+	}
+	if Upper == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Upper = cgraph.Cost
+		} else {
+			Upper = UniformCost
+		}
+	}
+	if Demand == nil {
+		Demand = func(Node) float64 { return 0 }
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	index := make(map[int]int, len(nodes))
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+
+	// Nodes 0 to n-1 are graph's, then the super source and super sink
+	n := len(nodes)
+	source, sink := n, n+1
+	network := newFlowNetwork(n + 2)
+	excess := make([]float64, n) // How much each node must take in once every edge carries its lower bound
+	type boundedEdge struct {
+		head, tail Node
+		lower      float64
+		arc        int
+	}
+	var edges []boundedEdge
+	largest := 0.0
+	for i, node := range nodes {
+		excess[i] += Demand(node)
+		for _, succ := range graph.Successors(node) {
+			j, ok := index[succ.ID()]
+			if !ok {
+				continue
+			}
+			lower, upper := Lower(node, succ), Upper(node, succ)
+			if lower > upper {
+				return nil, nil, ErrInfeasible
+			}
+			excess[i] += lower
+			excess[j] -= lower
+			edges = append(edges, boundedEdge{node, succ, lower, network.addArc(i, j, upper-lower)})
+			largest = math.Max(largest, math.Max(math.Abs(lower), math.Abs(upper)))
+		}
+	}
+
+	short, over := 0.0, 0.0
+	for i, e := range excess {
+		if e > 0 {
+			network.addArc(i, sink, e)
+			short += e
+		} else if e < 0 {
+			network.addArc(source, i, -e)
+			over -= e
+		}
+	}
+	eps := 1e-9 * math.Max(1, math.Max(largest, math.Max(short, over)))
+
+	if network.maxFlow(source, sink, eps) < short-eps {
+		// The nodes the super source can't reach any more want more than they can get
+		reached := network.reachable(source, eps)
+		for i, node := range nodes {
+			if !reached[i] {
+				cut = append(cut, node)
+			}
+		}
+		return nil, cut, ErrInfeasible
+	}
+	if over > short+eps {
+		// The demands don't add up, so the graph as a whole is supplying more than it can
+		return nil, nodes, ErrInfeasible
+	}
+
+	flow = make(map[[2]int]float64)
+	for _, edge := range edges {
+		if f := edge.lower + network.flow(edge.arc); f != 0 {
+			flow[[2]int{edge.head.ID(), edge.tail.ID()}] += f
+		}
+	}
+
+	return flow, nil, nil
+}
+
+// Finds a maximum flow from source to sink, by Dinic's algorithm, and a minimum cut, which by the max-flow min-cut theorem has the same capacity. Capacity gives each edge's
+// capacity by head and tail; if it's nil it's the graph's Cost if it's a Coster, and 1 otherwise, and capacities that aren't positive are none. Edges are as Successors gives
+// them, so an undirected edge is a pair of opposite edges that can each carry up to its capacity.
+//
+// Returns the value of the flow, the flow on every edge with any, keyed by {head ID, tail ID} as DecomposeFlow takes it, and the edges of the cut: those from the nodes the
+// source can still reach once the flow is sent, to the rest, in order of head ID and then tail ID. Flow never goes both ways between two nodes. The flow is 0, and the cut
+// empty, if source and sink are the same node or either isn't in the graph.
+func MaxFlow(graph Graph, source, sink Node, Capacity func(Node, Node) float64) (flow float64, flowEdges map[[2]int]float64, minCut []Edge) {
+	if Capacity == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Capacity = cgraph.Cost
+		} else {
+			Capacity = UniformCost
+		}
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	index := make(map[int]int, len(nodes))
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+	s, okS := index[source.ID()]
+	t, okT := index[sink.ID()]
+	flowEdges = make(map[[2]int]float64)
+	if !okS || !okT || s == t {
+		return 0, flowEdges, nil
+	}
+
+	network := newFlowNetwork(len(nodes))
+	type capacitatedEdge struct {
+		head, tail Node
+		arc        int
+	}
+	var edges []capacitatedEdge
+	largest := 0.0
+	for i, node := range nodes {
+		succs := graph.Successors(node)
+		sort.Sort(byID(succs))
+		for _, succ := range succs {
+			j, ok := index[succ.ID()]
+			if c := Capacity(node, succ); ok && c > 0 {
+				edges = append(edges, capacitatedEdge{node, succ, network.addArc(i, j, c)})
+				largest = math.Max(largest, c)
+			}
+		}
+	}
+	eps := 1e-9 * math.Max(1, largest)
+
+	flow = network.maxFlow(s, t, eps)
+	reached := network.reachable(s, eps)
+	for _, edge := range edges {
+		key := [2]int{edge.head.ID(), edge.tail.ID()}
+		if f := network.flow(edge.arc); f > eps {
+			flowEdges[key] += f
+		}
+		if reached[index[key[0]]] && !reached[index[key[1]]] {
+			minCut = append(minCut, GonumEdge{H: edge.head, T: edge.tail})
+		}
+	}
+	// Flow both ways between two nodes cancels out, leaving the same flow through every node
+	for key, f := range flowEdges {
+		back := [2]int{key[1], key[0]}
+		if r, ok := flowEdges[back]; ok && key[0] != key[1] {
+			switch {
+			case f > r+eps:
+				flowEdges[key] = f - r
+				delete(flowEdges, back)
+			case r > f+eps:
+				flowEdges[back] = r - f
+				delete(flowEdges, key)
+			default:
+				delete(flowEdges, key)
+				delete(flowEdges, back)
+			}
+		}
+	}
+
+	return flow, flowEdges, minCut
+}
+
+// A flow network on nodes numbered from 0, for the flow algorithms. Arcs come in pairs, each arc's reverse being arc^1, and capacity holds what's left of each arc's capacity, so
+// the flow along an arc is its reverse's capacity less the reverse's original capacity. The cost of sending flow back along a reverse arc is minus its arc's cost.
+type flowNetwork struct {
+	out      [][]int // The arcs out of each node
+	to       []int
+	capacity []float64
+	original []float64
+	cost     []float64
+
+	level, next []int // Dinic's level graph, and each node's first arc not yet found blocked
+}
+
+func newFlowNetwork(n int) *flowNetwork {
+	return &flowNetwork{out: make([][]int, n), level: make([]int, n), next: make([]int, n)}
+}
+
+// Adds an arc from u to v, and its reverse with no capacity, returning the arc
+func (fn *flowNetwork) addArc(u, v int, capacity float64) int {
+	arc := len(fn.to)
+	fn.out[u] = append(fn.out[u], arc)
+	fn.out[v] = append(fn.out[v], arc+1)
+	fn.to = append(fn.to, v, u)
+	fn.capacity = append(fn.capacity, capacity, 0)
+	fn.original = append(fn.original, capacity, 0)
+	fn.cost = append(fn.cost, 0, 0)
+	return arc
+}
+
+// Sets the cost per unit of flow along arc
+func (fn *flowNetwork) setCost(arc int, cost float64) {
+	fn.cost[arc], fn.cost[arc^1] = cost, -cost
+}
+
+// The flow along arc
+func (fn *flowNetwork) flow(arc int) float64 {
+	return fn.capacity[arc^1] - fn.original[arc^1]
+}
+
+// Pushes as much more flow as it can from s to t by Dinic's algorithm[1], returning how much: O(n²m) time, and much less on most networks. Capacities of eps or less count
+// as none.
+//
+// [1] E. A. Dinic, "Algorithm for solution of a problem of maximum flow in networks with power estimation", Soviet Mathematics Doklady 11 (1970)
+func (fn *flowNetwork) maxFlow(s, t int, eps float64) float64 {
+	total := 0.0
+	for fn.levels(s, t, eps) {
+		for i := range fn.next {
+			fn.next[i] = 0
+		}
+		for {
+			f := fn.augment(s, t, math.Inf(1), eps)
+			if f <= eps {
+				break
+			}
+			total += f
+		}
+	}
+	return total
+}
+
+// Numbers the nodes by breadth first distance from s along arcs with capacity left, returning whether t is reached
+func (fn *flowNetwork) levels(s, t int, eps float64) bool {
+	for i := range fn.level {
+		fn.level[i] = -1
+	}
+	fn.level[s] = 0
+	queue := []int{s}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, arc := range fn.out[u] {
+			if v := fn.to[arc]; fn.level[v] == -1 && fn.capacity[arc] > eps {
+				fn.level[v] = fn.level[u] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	return fn.level[t] != -1
+}
+
+// Pushes up to limit from u to t along a path in the level graph, returning how much
+func (fn *flowNetwork) augment(u, t int, limit, eps float64) float64 {
+	if u == t {
+		return limit
+	}
+	for ; fn.next[u] < len(fn.out[u]); fn.next[u]++ {
+		arc := fn.out[u][fn.next[u]]
+		v := fn.to[arc]
+		if fn.capacity[arc] <= eps || fn.level[v] != fn.level[u]+1 {
+			continue
+		}
+		if f := fn.augment(v, t, math.Min(limit, fn.capacity[arc]), eps); f > eps {
+			fn.capacity[arc] -= f
+			fn.capacity[arc^1] += f
+			return f
+		}
+	}
+	return 0
+}
+
+// Which nodes can be reached from s along arcs with capacity left: after a maximum flow, the source side of a minimum cut
+func (fn *flowNetwork) reachable(s int, eps float64) []bool {
+	reached := make([]bool, len(fn.out))
+	reached[s] = true
+	stack := []int{s}
+	for len(stack) != 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, arc := range fn.out[u] {
+			if v := fn.to[arc]; !reached[v] && fn.capacity[arc] > eps {
+				reached[v] = true
+				stack = append(stack, v)
+			}
+		}
+	}
+	return reached
+}
+
+// Sends up to limit from s to t as cheaply as it can by successive shortest paths, returning how much it sent and what that cost. The paths get no cheaper as it goes, and it
+// stops at the first costing more than maxPathCost per unit. Costs may be negative, but there mustn't be a cycle of negative cost. Bellman-Ford finds the first potentials, and after that the reduced costs stay non-negative, so each path is found by Dijkstra's algorithm[1]; that's
+// O(n²) per path, which suits the dense networks it's used for.
+//
+// [1] J. Edmonds and R. M. Karp, "Theoretical improvements in algorithmic efficiency for network flow problems", Journal of the ACM 19 (1972)
+func (fn *flowNetwork) minCostFlow(s, t int, limit, maxPathCost, eps float64) (flow, cost float64) {
+	n := len(fn.out)
+	inf := math.Inf(1)
+	dist := make([]float64, n)
+	for i := range dist {
+		dist[i] = inf
+	}
+	dist[s] = 0
+	for round := 0; round < n; round++ {
+		changed := false
+		for u := range fn.out {
+			if math.IsInf(dist[u], 1) {
+				continue
+			}
+			for _, arc := range fn.out[u] {
+				if v := fn.to[arc]; fn.capacity[arc] > eps && dist[u]+fn.cost[arc] < dist[v] {
+					dist[v], changed = dist[u]+fn.cost[arc], true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	// Nodes s can't reach stay out of reach, so their potential doesn't matter
+	potential := make([]float64, n)
+	for i, d := range dist {
+		if !math.IsInf(d, 1) {
+			potential[i] = d
+		}
+	}
+
+	prev := make([]int, n) // The arc each node was reached by
+	done := make([]bool, n)
+	for flow < limit-eps {
+		for i := range dist {
+			dist[i], done[i], prev[i] = inf, false, -1
+		}
+		dist[s] = 0
+		for {
+			u := -1
+			for v := range dist {
+				if !done[v] && !math.IsInf(dist[v], 1) && (u == -1 || dist[v] < dist[u]) {
+					u = v
+				}
+			}
+			if u == -1 {
+				break
+			}
+			done[u] = true
+			for _, arc := range fn.out[u] {
+				v := fn.to[arc]
+				if fn.capacity[arc] <= eps || done[v] {
+					continue
+				}
+				if d := dist[u] + fn.cost[arc] + potential[u] - potential[v]; d < dist[v] {
+					dist[v], prev[v] = d, arc
+				}
+			}
+		}
+		if math.IsInf(dist[t], 1) {
+			break
+		}
+		for i, d := range dist {
+			if !math.IsInf(d, 1) {
+				potential[i] += d
+			}
+		}
+
+		f, pathCost := limit-flow, 0.0
+		for v := t; v != s; v = fn.to[prev[v]^1] {
+			f = math.Min(f, fn.capacity[prev[v]])
+			pathCost += fn.cost[prev[v]]
+		}
+		if pathCost > maxPathCost {
+			break
+		}
+		for v := t; v != s; v = fn.to[prev[v]^1] {
+			fn.capacity[prev[v]] -= f
+			fn.capacity[prev[v]^1] += f
+		}
+		flow += f
+		cost += f * pathCost
+	}
+
+	return flow, cost
+}
@@ -0,0 +1,334 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Adds up the flow on each edge of paths and cycles, checking each goes along edges of g
+func recompose(t *testing.T, g graph.Graph, flows ...[]graph.FlowPath) map[[2]int]float64 {
+	total := make(map[[2]int]float64)
+	for _, fps := range flows {
+		for _, fp := range fps {
+			if fp.Flow <= 0 {
+				t.Errorf("Non-positive flow %v along %v", fp.Flow, fp.Nodes)
+			}
+			for i := 0; i+1 < len(fp.Nodes); i++ {
+				if !g.IsSuccessor(fp.Nodes[i], fp.Nodes[i+1]) {
+					t.Errorf("%v to %v isn't an edge", fp.Nodes[i], fp.Nodes[i+1])
+				}
+				total[[2]int{fp.Nodes[i].ID(), fp.Nodes[i+1].ID()}] += fp.Flow
+			}
+		}
+	}
+	return total
+}
+
+func sameFlow(a, b map[[2]int]float64) bool {
+	for edge, f := range a {
+		if math.Abs(f-b[edge]) > 1e-9 {
+			return false
+		}
+	}
+	for edge, f := range b {
+		if math.Abs(f-a[edge]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDecomposeFlow(t *testing.T) {
+	// Two routes from 0 to 3, and a cycle 1 -> 4 -> 1 on the way
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 5; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	flow := map[[2]int]float64{{0, 1}: 2, {1, 3}: 2, {0, 2}: 1, {2, 3}: 1, {1, 4}: 0.5, {4, 1}: 0.5}
+	for edge := range flow {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(edge[0]), T: graph.GonumNode(edge[1])})
+	}
+
+	paths, cycles, err := graph.DecomposeFlow(g, flow, graph.GonumNode(0), graph.GonumNode(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(paths) != 2 || len(cycles) != 1 {
+		t.Fatalf("Expected 2 paths and 1 cycle, got %v and %v", paths, cycles)
+	}
+	value := 0.0
+	for _, path := range paths {
+		if path.Nodes[0].ID() != 0 || path.Nodes[len(path.Nodes)-1].ID() != 3 {
+			t.Errorf("Path %v doesn't go from source to sink", path.Nodes)
+		}
+		value += path.Flow
+	}
+	if value != 3 {
+		t.Errorf("Paths carry %v, expected 3", value)
+	}
+	if c := cycles[0]; c.Flow != 0.5 || c.Nodes[0].ID() != c.Nodes[len(c.Nodes)-1].ID() || len(c.Nodes) != 3 {
+		t.Errorf("Expected the cycle through 1 and 4 carrying 0.5, got %v", c)
+	}
+	if total := recompose(t, g, paths, cycles); !sameFlow(total, flow) {
+		t.Errorf("Decomposition adds up to %v, expected %v", total, flow)
+	}
+}
+
+func TestDecomposeFlowRandom(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		g := graph.NewGonumGraph(true)
+		graph.GnpRandomGraph(g, 15, 0.3, true, src)
+		nodes := make([]graph.Node, 15)
+		for i := range nodes {
+			nodes[i] = graph.GonumNode(i)
+		}
+		source, sink := nodes[0], nodes[1]
+
+		// Random walks along the graph's edges, each carrying some flow, closed up into cycles or ending at the sink
+		flow := make(map[[2]int]float64)
+		for i := 0; i < 10; i++ {
+			start := nodes[src.Intn(len(nodes))]
+			if i%2 == 0 {
+				start = source
+			}
+			f := float64(1 + src.Intn(5))
+			walk := []graph.Node{start}
+			for len(walk) < 30 {
+				succs := g.Successors(walk[len(walk)-1])
+				if len(succs) == 0 {
+					break
+				}
+				walk = append(walk, succs[src.Intn(len(succs))])
+				last := walk[len(walk)-1]
+				if i%2 == 0 && last.ID() == sink.ID() || i%2 == 1 && last.ID() == start.ID() {
+					for j := 0; j+1 < len(walk); j++ {
+						flow[[2]int{walk[j].ID(), walk[j+1].ID()}] += f
+					}
+					break
+				}
+			}
+		}
+
+		paths, cycles, err := graph.DecomposeFlow(g, flow, source, sink)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(paths)+len(cycles) > len(flow)+1 {
+			t.Errorf("%d paths and cycles for %d edges", len(paths)+len(cycles), len(flow))
+		}
+		if total := recompose(t, g, paths, cycles); !sameFlow(total, flow) {
+			t.Errorf("Decomposition adds up to %v, expected %v", total, flow)
+		}
+	}
+}
+
+func TestDecomposeFlowNotConserved(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 3; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)})
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)})
+
+	flow := map[[2]int]float64{{0, 1}: 2, {1, 2}: 1}
+	if _, _, err := graph.DecomposeFlow(g, flow, graph.GonumNode(0), graph.GonumNode(2)); err != graph.ErrFlowNotConserved {
+		t.Errorf("Expected ErrFlowNotConserved, got %v", err)
+	}
+}
+
+// Checks flow is a circulation within the bounds meeting the demands
+func checkCirculation(t *testing.T, g graph.Graph, flow map[[2]int]float64, lower, upper func(graph.Node, graph.Node) float64, demand func(graph.Node) float64) {
+	net := make(map[int]float64)
+	for _, node := range g.NodeList() {
+		for _, succ := range g.Successors(node) {
+			f := flow[[2]int{node.ID(), succ.ID()}]
+			if f < lower(node, succ)-1e-9 || f > upper(node, succ)+1e-9 {
+				t.Errorf("Flow %v from %v to %v is outside [%v, %v]", f, node, succ, lower(node, succ), upper(node, succ))
+			}
+			net[node.ID()] -= f
+			net[succ.ID()] += f
+		}
+	}
+	for _, node := range g.NodeList() {
+		if math.Abs(net[node.ID()]-demand(node)) > 1e-9 {
+			t.Errorf("Node %v takes in %v, but demands %v", node, net[node.ID()], demand(node))
+		}
+	}
+}
+
+// Whether the demand of cut is more than can flow into it, or less than must
+func violatesHoffman(g graph.Graph, cut []graph.Node, lower, upper func(graph.Node, graph.Node) float64, demand func(graph.Node) float64) bool {
+	in := make(map[int]bool)
+	d := 0.0
+	for _, node := range cut {
+		in[node.ID()] = true
+		d += demand(node)
+	}
+	most, least := 0.0, 0.0
+	for _, node := range g.NodeList() {
+		for _, succ := range g.Successors(node) {
+			if !in[node.ID()] && in[succ.ID()] {
+				most += upper(node, succ)
+				least += lower(node, succ)
+			} else if in[node.ID()] && !in[succ.ID()] {
+				most -= lower(node, succ)
+				least -= upper(node, succ)
+			}
+		}
+	}
+	return d > most+1e-9 || d < least-1e-9
+}
+
+func TestFeasibleCirculation(t *testing.T) {
+	// 0 supplies 2 to 2, through 1 or directly, and the cycle 0 -> 1 -> 2 -> 0 must carry at least 1 round
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 3; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	bounds := map[[2]int][2]float64{{0, 1}: {0, 1}, {0, 2}: {0, 2}, {1, 2}: {0, 5}, {2, 0}: {1, 3}}
+	for edge := range bounds {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(edge[0]), T: graph.GonumNode(edge[1])})
+	}
+	lower := func(u, v graph.Node) float64 { return bounds[[2]int{u.ID(), v.ID()}][0] }
+	upper := func(u, v graph.Node) float64 { return bounds[[2]int{u.ID(), v.ID()}][1] }
+	demands := map[int]float64{0: -2, 2: 2}
+	demand := func(node graph.Node) float64 { return demands[node.ID()] }
+
+	flow, cut, err := graph.FeasibleCirculation(g, lower, upper, demand)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v, cut %v", err, cut)
+	}
+	checkCirculation(t, g, flow, lower, upper, demand)
+
+	// Only 1 + 2 can go from 0, and 2 must come back, so 2 can't get 3
+	demands = map[int]float64{0: -3, 2: 3}
+	flow, cut, err = graph.FeasibleCirculation(g, lower, upper, demand)
+	if err != graph.ErrInfeasible {
+		t.Fatalf("Expected ErrInfeasible, got %v and flow %v", err, flow)
+	}
+	if !violatesHoffman(g, cut, lower, upper, demand) {
+		t.Errorf("Cut %v doesn't show the circulation is infeasible", cut)
+	}
+
+	// Unbalanced demands
+	demands = map[int]float64{0: -1}
+	if _, cut, err = graph.FeasibleCirculation(g, lower, upper, demand); err != graph.ErrInfeasible || !violatesHoffman(g, cut, lower, upper, demand) {
+		t.Errorf("Expected ErrInfeasible with a violated cut, got %v and %v", err, cut)
+	}
+}
+
+func TestFeasibleCirculationRandom(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	feasible := 0
+	for trial := 0; trial < 50; trial++ {
+		g := graph.NewGonumGraph(true)
+		graph.GnpRandomGraph(g, 12, 0.4, true, src)
+		bounds := make(map[[2]int][2]float64)
+		demands := make(map[int]float64)
+		for _, node := range g.NodeList() {
+			for _, succ := range g.Successors(node) {
+				l := 0.0
+				if src.Intn(6) == 0 {
+					l = 1
+				}
+				bounds[[2]int{node.ID(), succ.ID()}] = [2]float64{l, l + float64(src.Intn(5))}
+			}
+		}
+		// Demands from moving random amounts between random pairs, which are often, but not always, possible
+		for i := 0; i < 4; i++ {
+			f := float64(1 + src.Intn(4))
+			demands[src.Intn(12)] -= f
+			demands[src.Intn(12)] += f
+		}
+		lower := func(u, v graph.Node) float64 { return bounds[[2]int{u.ID(), v.ID()}][0] }
+		upper := func(u, v graph.Node) float64 { return bounds[[2]int{u.ID(), v.ID()}][1] }
+		demand := func(node graph.Node) float64 { return demands[node.ID()] }
+
+		flow, cut, err := graph.FeasibleCirculation(g, lower, upper, demand)
+		switch err {
+		case nil:
+			feasible++
+			checkCirculation(t, g, flow, lower, upper, demand)
+		case graph.ErrInfeasible:
+			if !violatesHoffman(g, cut, lower, upper, demand) {
+				t.Errorf("Cut %v doesn't show the circulation is infeasible", cut)
+			}
+		default:
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if feasible == 0 || feasible == 50 {
+		t.Errorf("Expected some feasible and some infeasible circulations, got %d feasible", feasible)
+	}
+}
+
+func TestMaxFlow(t *testing.T) {
+	// The network from CLRS, whose maximum flow is 23
+	g := graph.NewDirectedGraph(0, 0)
+	for _, e := range [][3]int{{0, 1, 16}, {0, 2, 13}, {2, 1, 4}, {1, 3, 12}, {3, 2, 9}, {2, 4, 14}, {4, 3, 7}, {3, 5, 20}, {4, 5, 4}} {
+		edge := graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, float64(e[2]))
+	}
+	g.AddNode(graph.GonumNode(6), nil)
+	source, sink := graph.GonumNode(0), graph.GonumNode(5)
+
+	flow, flowEdges, minCut := graph.MaxFlow(g, source, sink, nil)
+	if flow != 23 {
+		t.Errorf("Expected a flow of 23, got %v", flow)
+	}
+	cut := 0.0
+	for _, edge := range minCut {
+		cut += g.Cost(edge.Head(), edge.Tail())
+	}
+	if cut != flow {
+		t.Errorf("The cut %v has capacity %v, not the flow's %v", edgeIDs(minCut), cut, flow)
+	}
+	for key, f := range flowEdges {
+		if c := g.Cost(graph.GonumNode(key[0]), graph.GonumNode(key[1])); f > c+1e-9 {
+			t.Errorf("%v carries %v, over its capacity %v", key, f, c)
+		}
+	}
+	paths, cycles, err := graph.DecomposeFlow(g, flowEdges, source, sink)
+	if err != nil {
+		t.Fatalf("The flow doesn't decompose: %v", err)
+	}
+	value := 0.0
+	for _, path := range paths {
+		value += path.Flow
+	}
+	if math.Abs(value-flow) > 1e-9 || !sameFlow(recompose(t, g, paths, cycles), flowEdges) {
+		t.Errorf("The flow's paths carry %v, expected %v", value, flow)
+	}
+
+	if flow, flowEdges, minCut := graph.MaxFlow(g, source, graph.GonumNode(6), nil); flow != 0 || len(flowEdges) != 0 || len(minCut) != 0 {
+		t.Errorf("Expected no flow to a node that can't be reached, got %v over %v cut by %v", flow, flowEdges, edgeIDs(minCut))
+	}
+	if flow, _, _ := graph.MaxFlow(g, source, source, nil); flow != 0 {
+		t.Errorf("Expected no flow from a node to itself, got %v", flow)
+	}
+}
+
+func TestMaxFlowUndirected(t *testing.T) {
+	// Unit capacities; an undirected edge carries flow whichever way it's needed, but only one way
+	g := graph.NewUndirectedGraph(0, 0)
+	for _, e := range [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {1, 2}, {3, 4}, {2, 4}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+	flow, flowEdges, minCut := graph.MaxFlow(g, graph.GonumNode(0), graph.GonumNode(4), nil)
+	if flow != 2 || len(minCut) != 2 {
+		t.Errorf("Expected a flow of 2 and a cut of 2 edges, got %v and %v", flow, edgeIDs(minCut))
+	}
+	for key := range flowEdges {
+		if _, ok := flowEdges[[2]int{key[1], key[0]}]; ok {
+			t.Errorf("Flow goes both ways between %d and %d", key[0], key[1])
+		}
+	}
+	if _, _, err := graph.DecomposeFlow(g, flowEdges, graph.GonumNode(0), graph.GonumNode(4)); err != nil {
+		t.Errorf("The flow doesn't decompose: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package graph
+
+// A FogOfWarGraph turns any graph into a DStarGraph for an agent that knows the graph's layout but not its costs. Until an edge has been seen, the agent assumes it costs what
+// an optimistic estimate says, typically the least it could cost; every time the agent moves, the true cost of every edge within radius hops of its new position is revealed,
+// and ChangedEdges reports the ones that turned out different. Edges that are really impassable should have a true cost of +Inf.
+//
+// This is the freespace assumption D*-Lite was designed around, generalized from grids (see RevealingTileGraph) to any graph, e.g. a road network where the agent learns of
+// closures and traffic as it gets near them. The graph's Cost method gives the costs as the agent currently believes them, which is what D*-Lite should plan with.
+type FogOfWarGraph struct {
+	Graph
+	truth, optimistic func(Node, Node) float64
+	position          Node
+	radius            int
+	known             map[[2]int]bool
+	changed           []Edge
+}
+
+// Creates a FogOfWarGraph over graph, with the agent at start seeing radius hops around it. The true costs come from Cost, and what the agent assumes until it sees an edge from
+// Optimistic. If Cost is nil it's the graph's Cost function if it's a Coster, and UniformCost otherwise; if Optimistic is nil it's UniformCost, which is optimistic for any graph
+// whose edges cost at least 1, like a grid where walls cost +Inf.
+//
+// The surroundings of start are revealed immediately (and aren't reported by ChangedEdges), since an agent can see them before planning its first move.
+func NewFogOfWarGraph(graph Graph, Cost, Optimistic func(Node, Node) float64, start Node, radius int) *FogOfWarGraph {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if Optimistic == nil {
+		Optimistic = UniformCost
+	}
+
+	fog := &FogOfWarGraph{Graph: graph, truth: Cost, optimistic: Optimistic, position: start, radius: radius, known: make(map[[2]int]bool)}
+	fog.reveal()
+	fog.changed = nil
+
+	return fog
+}
+
+// Returns the agent's current position.
+func (fog *FogOfWarGraph) Position() Node {
+	return fog.position
+}
+
+// Whether the agent has seen the edge from node1 to node2, so that its cost is the true one.
+func (fog *FogOfWarGraph) Known(node1, node2 Node) bool {
+	return fog.known[[2]int{node1.ID(), node2.ID()}]
+}
+
+// The cost of the edge from node1 to node2 as the agent believes it: the true cost if it has seen the edge, the optimistic estimate otherwise.
+func (fog *FogOfWarGraph) Cost(node1, node2 Node) float64 {
+	if fog.Known(node1, node2) {
+		return fog.truth(node1, node2)
+	}
+	return fog.optimistic(node1, node2)
+}
+
+// The wrapped graph's heuristic, if it's a HeuristicCoster, and NullHeuristic otherwise. A heuristic for the true costs may not be admissible for the optimistic ones.
+func (fog *FogOfWarGraph) HeuristicCost(node1, node2 Node) float64 {
+	if hgraph, ok := fog.Graph.(HeuristicCoster); ok {
+		return hgraph.HeuristicCost(node1, node2)
+	}
+	return NullHeuristic(node1, node2)
+}
+
+// Moves the agent to target and reveals its surroundings.
+func (fog *FogOfWarGraph) Move(target Node) {
+	fog.position = target
+	fog.reveal()
+}
+
+// Returns and forgets the edges whose believed cost changed since the last call, along with the graph's Cost function, which now gives their true costs.
+func (fog *FogOfWarGraph) ChangedEdges() (newCostFunc func(Node, Node) float64, changedEdges []Edge) {
+	changedEdges, fog.changed = fog.changed, nil
+	return fog.Cost, changedEdges
+}
+
+// Reveals the true cost of every edge leaving a node less than radius hops from the agent, in either direction for a directed graph, so that every edge with both ends within
+// radius hops is seen
+func (fog *FogOfWarGraph) reveal() {
+	seen := map[int]bool{fog.position.ID(): true}
+	frontier := []Node{fog.position}
+	for depth := 0; depth < fog.radius && len(frontier) > 0; depth++ {
+		var next []Node
+		for _, node := range frontier {
+			for _, succ := range fog.Graph.Successors(node) {
+				fog.see(node, succ)
+				if !seen[succ.ID()] {
+					seen[succ.ID()] = true
+					next = append(next, succ)
+				}
+			}
+			for _, pred := range fog.Graph.Predecessors(node) {
+				fog.see(pred, node)
+				if !seen[pred.ID()] {
+					seen[pred.ID()] = true
+					next = append(next, pred)
+				}
+			}
+		}
+		frontier = next
+	}
+}
+
+func (fog *FogOfWarGraph) see(head, tail Node) {
+	edge := [2]int{head.ID(), tail.ID()}
+	if fog.known[edge] {
+		return
+	}
+
+	fog.known[edge] = true
+	if fog.truth(head, tail) != fog.optimistic(head, tail) {
+		fog.changed = append(fog.changed, GonumEdge{H: head, T: tail})
+	}
+}
@@ -0,0 +1,87 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestFogOfWarReveals(t *testing.T) {
+	line := graph.NewTileGraph(1, 5, true)
+	truth := func(a, b graph.Node) float64 {
+		if a.ID()+b.ID() == 5 { // Between 2 and 3
+			return 3
+		}
+		return 1
+	}
+	fog := graph.NewFogOfWarGraph(line, truth, nil, graph.GonumNode(0), 1)
+
+	if !fog.Known(graph.GonumNode(0), graph.GonumNode(1)) || fog.Known(graph.GonumNode(1), graph.GonumNode(2)) {
+		t.Error("Expected only the edge next to the agent to be known")
+	}
+	if c := fog.Cost(graph.GonumNode(2), graph.GonumNode(3)); c != 1 {
+		t.Errorf("Expected an unseen edge to cost the optimistic 1, got %v", c)
+	}
+
+	fog.Move(graph.GonumNode(1))
+	if _, changed := fog.ChangedEdges(); len(changed) != 0 {
+		t.Errorf("Nothing was different, but got changes %v", changed)
+	}
+
+	fog.Move(graph.GonumNode(2))
+	cost, changed := fog.ChangedEdges()
+	if len(changed) != 2 {
+		t.Fatalf("Expected the edge between 2 and 3 in both directions, got %v", changed)
+	}
+	for _, edge := range changed {
+		if edge.Head().ID()+edge.Tail().ID() != 5 || cost(edge.Head(), edge.Tail()) != 3 {
+			t.Errorf("Unexpected change %v costing %v", edge, cost(edge.Head(), edge.Tail()))
+		}
+	}
+	if _, changed := fog.ChangedEdges(); len(changed) != 0 {
+		t.Errorf("Changes weren't forgotten once reported: %v", changed)
+	}
+}
+
+func TestFogOfWarDStar(t *testing.T) {
+	// An open grid where tiles in column 5 are walls, except at the bottom, and the tiles in column 2 are mud
+	tg := graph.NewTileGraph(10, 10, true)
+	truth := func(a, b graph.Node) float64 {
+		row, col := tg.IDToCoords(b.ID())
+		switch {
+		case col == 5 && row < 9:
+			return math.Inf(1)
+		case col == 2:
+			return 5
+		}
+		return 1
+	}
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(0, 9)
+	optimal, best, _ := graph.AStar(start, goal, tg, truth, nil)
+
+	for _, radius := range []int{1, 3, 20} {
+		fog := graph.NewFogOfWarGraph(tg, truth, nil, start, radius)
+		ds := graph.InitDStar(start, goal, fog, nil, graph.ManhattanHeuristic(tg.Position, 1))
+		walked := 0.0
+		for fog.Position().ID() != goal.ID() {
+			if walked > 1000 {
+				t.Fatalf("Radius %d: D*-Lite is going around in circles", radius)
+			}
+			next, err := ds.Step()
+			if err != nil {
+				t.Fatalf("Radius %d: %v", radius, err)
+			}
+			walked += truth(fog.Position(), next)
+			fog.Move(next)
+			ds.Update(fog.ChangedEdges())
+		}
+
+		if math.IsInf(walked, 1) || walked < best {
+			t.Errorf("Radius %d: walked a path costing %v, the best costs %v", radius, walked, best)
+		}
+		if radius == 20 && walked != best {
+			t.Errorf("With the whole grid in sight, expected to walk the best path costing %v (%d tiles), walked %v", best, len(optimal), walked)
+		}
+	}
+}
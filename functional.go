@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"errors"
+	"sort"
+)
+
+// Returned when a graph that should have exactly one edge out of every node doesn't.
+var ErrNotFunctional = errors.New("Graph is not functional")
+
+// A FunctionalGraph is a graph with exactly one edge out of every node, which is to say a function from its nodes to themselves: a permutation, the transitions of a
+// deterministic state machine, or the iterates of x ↦ f(x). Following edges from any node eventually goes around a cycle, so every component is one cycle with trees hanging
+// into it, and the walk from a node is shaped like a ρ: a tail of distinct nodes, then the cycle forever.
+//
+// Every node's tail and cycle are found up front in O(n), and binary lifting tables over the tails take O(n log n) more, after which the k-th successor of any node, for any k,
+// takes O(log n).
+type FunctionalGraph struct {
+	nodes  []Node
+	index  map[int]int
+	up     [][]int  // up[j][i] is the 2^j-th successor of node i
+	tail   []int    // How many steps from each node to its cycle
+	entry  []int    // Where each node's walk joins its cycle
+	cycle  []int    // Which cycle each node's walk ends in
+	pos    []int    // Where each node on a cycle is in it
+	cycles [][]Node // Each cycle, in order of its edges
+}
+
+// Analyzes graph, which must have exactly one successor for every node, or ErrNotFunctional is returned.
+func NewFunctionalGraph(graph Graph) (*FunctionalGraph, error) {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	n := len(nodes)
+	fg := &FunctionalGraph{nodes: nodes, index: make(map[int]int, n), tail: make([]int, n), entry: make([]int, n), cycle: make([]int, n), pos: make([]int, n)}
+	for i, node := range nodes {
+		fg.index[node.ID()] = i
+	}
+
+	next := make([]int, n)
+	for i, node := range nodes {
+		succs := graph.Successors(node)
+		if len(succs) != 1 {
+			return nil, ErrNotFunctional
+		}
+		j, ok := fg.index[succs[0].ID()]
+		if !ok {
+			return nil, ErrNotFunctional
+		}
+		next[i] = j
+	}
+
+	// Walk from each node not yet seen until the walk meets itself, which closes a new cycle, or meets an earlier walk, whose cycle it joins
+	const (
+		unseen = iota
+		onWalk
+		done
+	)
+	state := make([]int, n)
+	for start := range nodes {
+		var walk []int
+		u := start
+		for state[u] == unseen {
+			state[u] = onWalk
+			walk = append(walk, u)
+			u = next[u]
+		}
+		if state[u] == onWalk {
+			var cycle []Node
+			for i := len(walk) - 1; ; i-- {
+				if walk[i] == u {
+					for _, v := range walk[i:] {
+						fg.entry[v], fg.cycle[v], fg.pos[v] = v, len(fg.cycles), len(cycle)
+						cycle = append(cycle, nodes[v])
+						state[v] = done
+					}
+					walk = walk[:i]
+					break
+				}
+			}
+			fg.cycles = append(fg.cycles, cycle)
+		}
+		for i := len(walk) - 1; i >= 0; i-- {
+			v := walk[i]
+			fg.tail[v], fg.entry[v], fg.cycle[v] = fg.tail[next[v]]+1, fg.entry[next[v]], fg.cycle[next[v]]
+			state[v] = done
+		}
+	}
+
+	fg.up = [][]int{next}
+	for steps := 2; steps <= n; steps *= 2 {
+		prev := fg.up[len(fg.up)-1]
+		up := make([]int, n)
+		for i := range up {
+			up[i] = prev[prev[i]]
+		}
+		fg.up = append(fg.up, up)
+	}
+
+	return fg, nil
+}
+
+// The node reached from node after k steps (node itself for k = 0), and false if node isn't in the graph or k is negative.
+func (fg *FunctionalGraph) Successor(node Node, k int) (Node, bool) {
+	i, ok := fg.index[node.ID()]
+	if !ok || k < 0 {
+		return nil, false
+	}
+
+	if k >= fg.tail[i] {
+		cycle := fg.cycles[fg.cycle[i]]
+		return cycle[(fg.pos[fg.entry[i]]+(k-fg.tail[i])%len(cycle))%len(cycle)], true
+	}
+	for j := 0; k != 0; j, k = j+1, k/2 {
+		if k&1 == 1 {
+			i = fg.up[j][i]
+		}
+	}
+	return fg.nodes[i], true
+}
+
+// The ρ shape of the walk from node: the tail of nodes before the cycle, starting with node itself, and the cycle, starting where the tail joins it. A node on a cycle has no
+// tail. Both are nil if node isn't in the graph.
+func (fg *FunctionalGraph) Rho(node Node) (tail, cycle []Node) {
+	i, ok := fg.index[node.ID()]
+	if !ok {
+		return nil, nil
+	}
+
+	for k := 0; k < fg.tail[i]; k++ {
+		next, _ := fg.Successor(node, k)
+		tail = append(tail, next)
+	}
+	all := fg.cycles[fg.cycle[i]]
+	p := fg.pos[fg.entry[i]]
+	cycle = append(append(cycle, all[p:]...), all[:p]...)
+	return tail, cycle
+}
+
+// How many steps it takes node to reach a cycle, and the length of that cycle, which together are the shape of its ρ. Returns -1 for both if node isn't in the graph.
+func (fg *FunctionalGraph) TailAndCycleLength(node Node) (tail, cycle int) {
+	i, ok := fg.index[node.ID()]
+	if !ok {
+		return -1, -1
+	}
+	return fg.tail[i], len(fg.cycles[fg.cycle[i]])
+}
+
+// All the cycles, each in the order its edges go, starting from its node of lowest ID. There's one for each weakly connected component, and for a permutation, they're its
+// cycle decomposition.
+func (fg *FunctionalGraph) Cycles() [][]Node {
+	cycles := make([][]Node, len(fg.cycles))
+	for c, cycle := range fg.cycles {
+		low := 0
+		for i, node := range cycle {
+			if node.ID() < cycle[low].ID() {
+				low = i
+			}
+		}
+		cycles[c] = append(append([]Node(nil), cycle[low:]...), cycle[:low]...)
+	}
+	return cycles
+}
+
+// Whether node is on a cycle, which in a state machine makes it a recurrent state, and in a permutation is true of every node.
+func (fg *FunctionalGraph) OnCycle(node Node) bool {
+	i, ok := fg.index[node.ID()]
+	return ok && fg.tail[i] == 0
+}
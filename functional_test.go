@@ -0,0 +1,96 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestFunctionalGraph(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		n := 1 + src.Intn(40)
+		f := make([]int, n)
+		g := graph.NewGonumGraph(true)
+		for i := range f {
+			g.AddNode(graph.GonumNode(i), nil)
+		}
+		for i := range f {
+			f[i] = src.Intn(n)
+			g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(f[i])})
+		}
+
+		fg, err := graph.NewFunctionalGraph(g)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		onCycles := 0
+		for _, cycle := range fg.Cycles() {
+			onCycles += len(cycle)
+			for i, node := range cycle {
+				if f[node.ID()] != cycle[(i+1)%len(cycle)].ID() {
+					t.Errorf("Cycle %v doesn't follow the edges", cycle)
+				}
+			}
+		}
+
+		for start := 0; start < n; start++ {
+			node := graph.GonumNode(start)
+			u := start
+			for k := 0; k <= 2*n; k++ {
+				if next, _ := fg.Successor(node, k); next.ID() != u {
+					t.Fatalf("Successor %d of %d is %v, expected %d", k, start, next, u)
+				}
+				u = f[u]
+			}
+
+			tail, cycle := fg.Rho(node)
+			tailLength, cycleLength := fg.TailAndCycleLength(node)
+			if len(tail) != tailLength || len(cycle) != cycleLength {
+				t.Errorf("Rho of %d has lengths %d and %d, expected %d and %d", start, len(tail), len(cycle), tailLength, cycleLength)
+			}
+			if len(tail) > 0 && tail[0].ID() != start || len(tail) == 0 && cycle[0].ID() != start {
+				t.Errorf("Rho of %d doesn't start there: %v and %v", start, tail, cycle)
+			}
+			if fg.OnCycle(node) != (tailLength == 0) {
+				t.Errorf("Node %d is on a cycle, but its tail is %d long", start, tailLength)
+			}
+
+			// Far enough along, going round the cycle any number of times changes nothing
+			far, _ := fg.Successor(node, tailLength+3)
+			farther, _ := fg.Successor(node, tailLength+3+1000000007*cycleLength)
+			if far.ID() != farther.ID() {
+				t.Errorf("Going round the cycle from %d moves from %v to %v", start, far, farther)
+			}
+		}
+		if onCycles > n {
+			t.Errorf("%d nodes on cycles, of %d", onCycles, n)
+		}
+	}
+}
+
+func TestFunctionalGraphPermutation(t *testing.T) {
+	// The permutation (0 1 2)(3 4)(5)
+	perm := []int{1, 2, 0, 4, 3, 5}
+	g := graph.NewGonumGraph(true)
+	for i := range perm {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for i, j := range perm {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(j)})
+	}
+	fg, err := graph.NewFunctionalGraph(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cycles := fg.Cycles()
+	if len(cycles) != 3 || len(cycles[0]) != 3 || len(cycles[1]) != 2 || len(cycles[2]) != 1 {
+		t.Errorf("Expected cycles of 3, 2 and 1, got %v", cycles)
+	}
+
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(5), T: graph.GonumNode(0)})
+	if _, err := graph.NewFunctionalGraph(g); err != graph.ErrNotFunctional {
+		t.Errorf("Expected ErrNotFunctional with two edges out of a node, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Groups the nodes of a DAG into generations: generation L holds the nodes whose longest path from a source (a node without predecessors) has L edges, so sources are
+// generation 0 and every edge goes from an earlier generation to a later one. Nodes within a generation don't depend on each other, which makes the generations the rounds of
+// a build that runs everything it can in parallel, and the layers of a layered drawing (see SugiyamaLayout). Each generation lists its nodes in order of ID.
+//
+// Found by Kahn's algorithm in O(n + m). Returns ErrNotDAG if the graph is undirected or has a cycle.
+func TopologicalGenerations(graph Graph) (generations [][]Node, err error) {
+	if !graph.IsDirected() {
+		return nil, ErrNotDAG
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	indegree := make(map[int]int, len(nodes))
+	for _, node := range nodes {
+		for _, succ := range graph.Successors(node) {
+			indegree[succ.ID()]++
+		}
+	}
+
+	var current []Node
+	for _, node := range nodes {
+		if indegree[node.ID()] == 0 {
+			current = append(current, node)
+		}
+	}
+	placed := 0
+	for len(current) != 0 {
+		generations = append(generations, current)
+		placed += len(current)
+
+		// A node joins the next generation when its last predecessor is placed, which is in the generation before it
+		var next []Node
+		for _, node := range current {
+			for _, succ := range graph.Successors(node) {
+				if indegree[succ.ID()]--; indegree[succ.ID()] == 0 {
+					next = append(next, succ)
+				}
+			}
+		}
+		sort.Sort(byID(next))
+		current = next
+	}
+	if placed < len(nodes) {
+		return nil, ErrNotDAG
+	}
+
+	return generations, nil
+}
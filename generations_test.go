@@ -0,0 +1,83 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestTopologicalGenerations(t *testing.T) {
+	// A diamond with a shortcut from the top to the bottom, and a separate edge
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 6; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][2]int{{0, 1}, {0, 2}, {1, 3}, {2, 3}, {0, 3}, {4, 5}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	generations, err := graph.TopologicalGenerations(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := [][]int{{0, 4}, {1, 2, 5}, {3}}
+	if len(generations) != len(expected) {
+		t.Fatalf("Expected generations %v, got %v", expected, generations)
+	}
+	for i, generation := range generations {
+		if len(generation) != len(expected[i]) {
+			t.Fatalf("Expected generations %v, got %v", expected, generations)
+		}
+		for j, node := range generation {
+			if node.ID() != expected[i][j] {
+				t.Errorf("Expected generations %v, got %v", expected, generations)
+			}
+		}
+	}
+
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(3), T: graph.GonumNode(0)})
+	if _, err := graph.TopologicalGenerations(g); err != graph.ErrNotDAG {
+		t.Errorf("Expected ErrNotDAG for a cycle, got %v", err)
+	}
+}
+
+func TestTopologicalGenerationsRandom(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 10; trial++ {
+		g := graph.NewGonumGraph(true)
+		graph.RandomDAG(g, 6, 5, 0.3, false, src)
+		generations, err := graph.TopologicalGenerations(g)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		generation := make(map[int]int)
+		count := 0
+		for l, nodes := range generations {
+			for _, node := range nodes {
+				generation[node.ID()] = l
+				count++
+			}
+		}
+		if count != len(g.NodeList()) {
+			t.Errorf("%d nodes in generations, expected %d", count, len(g.NodeList()))
+		}
+		// Every edge goes forward, and every node but a source has a predecessor in the generation just before
+		for _, node := range g.NodeList() {
+			l := generation[node.ID()]
+			for _, succ := range g.Successors(node) {
+				if generation[succ.ID()] <= l {
+					t.Errorf("Edge from %v in generation %d to %v in %d", node, l, succ, generation[succ.ID()])
+				}
+			}
+			tight := l == 0
+			for _, pred := range g.Predecessors(node) {
+				tight = tight || generation[pred.ID()] == l-1
+			}
+			if !tight {
+				t.Errorf("Node %v in generation %d could be earlier", node, l)
+			}
+		}
+	}
+}
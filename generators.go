@@ -0,0 +1,454 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+/* Random graph generators. All of them empty dst first, and label the nodes they create GonumNode(0)...GonumNode(n-1).
+
+Every randomized routine in this package takes an explicit *rand.Rand and draws from nothing else: given a source seeded the same way, it does exactly the same thing on every run
+(in particular, it never lets Go's randomized map iteration order influence which random numbers go where). A nil source means "don't care", and gets a time seeded one. */
+
+// Returns src, or a freshly seeded source if src is nil. Generators take an explicit source so tests and benchmarks can be reproduced exactly.
+func randSource(src *rand.Rand) *rand.Rand {
+	if src != nil {
+		return src
+	}
+
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+func addNodes(dst MutableGraph, n int) {
+	for i := 0; i < n; i++ {
+		dst.AddNode(GonumNode(i), nil)
+	}
+}
+
+// Fills dst with an Erdős–Rényi G(n,p) random graph: n nodes where each of the possible edges is present independently with probability p. Self loops are never generated.
+//
+// Rather than flipping a coin for every pair, this skips ahead geometrically between edges (Batagelj and Brandes[1]), so it runs in O(n + m) for m generated edges, making sparse graphs with millions of nodes practical.
+// If src is nil a time seeded source is used; pass your own for reproducible output.
+//
+// [1] V. Batagelj, U. Brandes, "Efficient generation of large random networks", Physical Review E 71, 2005
+func GnpRandomGraph(dst MutableGraph, n int, p float64, directed bool, src *rand.Rand) {
+	dst.EmptyGraph()
+	dst.SetDirected(directed)
+	addNodes(dst, n)
+	if p <= 0 || n < 2 {
+		return
+	}
+	src = randSource(src)
+
+	skip := func() int {
+		if p >= 1 {
+			return 0
+		}
+		return int(math.Floor(math.Log(1-src.Float64()) / math.Log(1-p)))
+	}
+
+	if directed {
+		// Walk the n(n-1) candidate edges by index; row i holds the edges out of node i, skipping the diagonal
+		for idx := skip(); idx < n*(n-1); idx += 1 + skip() {
+			head, tail := idx/(n-1), idx%(n-1)
+			if tail >= head {
+				tail++
+			}
+			dst.AddEdge(GonumEdge{H: GonumNode(head), T: GonumNode(tail)})
+		}
+		return
+	}
+
+	// Walk the lower triangle (w < v) row by row
+	for v, w := 1, -1; v < n; {
+		w += 1 + skip()
+		for w >= v && v < n {
+			w -= v
+			v++
+		}
+		if v < n {
+			dst.AddEdge(GonumEdge{H: GonumNode(v), T: GonumNode(w)})
+		}
+	}
+}
+
+// Fills dst with an Erdős–Rényi G(n,m) random graph: n nodes and exactly m edges chosen uniformly at random from all the possible edges (self loops excluded).
+// If m exceeds the number of possible edges, the complete graph is generated. If src is nil a time seeded source is used.
+func GnmRandomGraph(dst MutableGraph, n, m int, directed bool, src *rand.Rand) {
+	dst.EmptyGraph()
+	dst.SetDirected(directed)
+	addNodes(dst, n)
+	src = randSource(src)
+
+	max := n * (n - 1)
+	if !directed {
+		max /= 2
+	}
+	if m > max {
+		m = max
+	}
+
+	// When more than half the edges are wanted, it's cheaper to choose the ones to leave out
+	complement := m > max/2
+	picks := m
+	if complement {
+		picks = max - m
+	}
+
+	chosen := make(map[[2]int]bool, picks)
+	for len(chosen) < picks {
+		v, w := src.Intn(n), src.Intn(n)
+		if v == w {
+			continue
+		}
+		if !directed && w < v {
+			v, w = w, v
+		}
+		chosen[[2]int{v, w}] = true
+	}
+
+	if !complement {
+		for e := range chosen {
+			dst.AddEdge(GonumEdge{H: GonumNode(e[0]), T: GonumNode(e[1])})
+		}
+		return
+	}
+
+	for v := 0; v < n; v++ {
+		start := 0
+		if !directed {
+			start = v + 1
+		}
+		for w := start; w < n; w++ {
+			if v != w && !chosen[[2]int{v, w}] {
+				dst.AddEdge(GonumEdge{H: GonumNode(v), T: GonumNode(w)})
+			}
+		}
+	}
+}
+
+// Fills dst with an undirected Barabási–Albert[1] scale-free graph of n nodes. Nodes are added one at a time, and each new node is connected to m distinct existing nodes chosen
+// with probability proportional to their degree ("preferential attachment"), which produces the heavy-tailed degree distribution seen in many real networks.
+//
+// If seed is non-nil, it's copied into dst as the starting graph and n-len(seed.NodeList()) nodes are added to it, numbered from one past the seed's largest ID. The seed must have at least m nodes.
+// Otherwise the process starts from m isolated nodes, which all get attached to the first new node. Seed nodes without any edges are chosen uniformly at first, as though they had degree 1,
+// so they aren't shut out forever. If src is nil a time seeded source is used.
+//
+// [1] A.-L. Barabási, R. Albert, "Emergence of scaling in random networks", Science 286, 1999
+func BarabasiAlbertGraph(dst MutableGraph, n, m int, seed Graph, src *rand.Rand) {
+	src = randSource(src)
+
+	nextID := 0
+	if seed != nil {
+		CopyGraph(dst, seed)
+		dst.SetDirected(false)
+		for _, node := range seed.NodeList() {
+			if node.ID() >= nextID {
+				nextID = node.ID() + 1
+			}
+		}
+	} else {
+		dst.EmptyGraph()
+		dst.SetDirected(false)
+		addNodes(dst, m)
+		nextID = m
+	}
+
+	// Every node appears in targets once per unit of degree, so a uniform draw from it is a degree proportional draw
+	// Both the initial nodes and each round's picks are visited in a fixed order, so that the same src always produces the same graph
+	targets := make([]Node, 0, 2*n*m)
+	initial := dst.NodeList()
+	sort.Sort(byID(initial))
+	for _, node := range initial {
+		degree := len(dst.Successors(node))
+		if degree == 0 {
+			degree = 1
+		}
+		for i := 0; i < degree; i++ {
+			targets = append(targets, node)
+		}
+	}
+
+	for count := len(dst.NodeList()); count < n; count++ {
+		node := GonumNode(nextID)
+		nextID++
+
+		seen := make(map[int]bool, m)
+		chosen := make([]Node, 0, m)
+		for len(chosen) < m && len(chosen) < count {
+			target := targets[src.Intn(len(targets))]
+			if !seen[target.ID()] {
+				seen[target.ID()] = true
+				chosen = append(chosen, target)
+			}
+		}
+
+		dst.AddNode(node, nil)
+		for _, target := range chosen {
+			dst.AddEdge(GonumEdge{H: node, T: target})
+			targets = append(targets, node, target)
+		}
+	}
+}
+
+// Fills dst with an undirected Watts–Strogatz[1] small-world graph. It starts as a ring lattice of n nodes each joined to its k nearest neighbors (k/2 on either side, so k should be even),
+// then every lattice edge is rewired with probability beta: its far endpoint is replaced by a uniformly chosen node, avoiding self loops and duplicate edges.
+//
+// Small beta keeps the lattice's high clustering while a few long range shortcuts collapse the average path length; beta = 1 gives something close to a random graph.
+// If src is nil a time seeded source is used.
+//
+// [1] D. J. Watts, S. H. Strogatz, "Collective dynamics of 'small-world' networks", Nature 393, 1998
+func WattsStrogatzGraph(dst MutableGraph, n, k int, beta float64, src *rand.Rand) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	addNodes(dst, n)
+	src = randSource(src)
+
+	if k >= n {
+		k = n - 1
+	}
+	for j := 1; j <= k/2; j++ {
+		for v := 0; v < n; v++ {
+			dst.AddEdge(GonumEdge{H: GonumNode(v), T: GonumNode((v + j) % n)})
+		}
+	}
+
+	// Rewire in the same order as the original paper: lap by lap around the ring
+	for j := 1; j <= k/2; j++ {
+		for v := 0; v < n; v++ {
+			if src.Float64() >= beta {
+				continue
+			}
+
+			head, tail := GonumNode(v), GonumNode((v+j)%n)
+			if len(dst.Successors(head)) >= n-1 {
+				continue // Nowhere to rewire to
+			}
+
+			var w Node
+			for w = GonumNode(src.Intn(n)); w.ID() == v || dst.IsSuccessor(head, w); w = GonumNode(src.Intn(n)) {
+			}
+			dst.RemoveEdge(GonumEdge{H: head, T: tail})
+			dst.AddEdge(GonumEdge{H: head, T: w})
+		}
+	}
+}
+
+// A PointNode is a node with a position in (up to) three dimensional space; two dimensional points leave Z at 0. It's what the geometric generators produce,
+// so their graphs can be searched with EuclideanDistance as the heuristic straight away.
+type PointNode struct {
+	Id      int
+	X, Y, Z float64
+}
+
+func (node PointNode) ID() int {
+	return node.Id
+}
+
+// The straight line distance between two PointNodes. It's an admissible and consistent heuristic for any graph whose edge costs are at least the distance between their endpoints,
+// such as the ones built by RandomGeometricGraph. If either node isn't a PointNode, it degrades to the NullHeuristic.
+func EuclideanDistance(a, b Node) float64 {
+	p, ok1 := a.(PointNode)
+	q, ok2 := b.(PointNode)
+	if !ok1 || !ok2 {
+		return 0
+	}
+
+	dx, dy, dz := p.X-q.X, p.Y-q.Y, p.Z-q.Z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// Fills dst with an undirected random geometric graph: n PointNodes placed uniformly at random in the unit square (dim 2) or unit cube (dim 3), with an edge between every pair closer than radius.
+// Each edge's cost is set to the distance between its endpoints, so EuclideanDistance is an exact lower bound for searches over the result.
+//
+// Points are bucketed into a grid of radius sized cells so only neighboring cells are compared, which makes generation roughly linear for small radii. If src is nil a time seeded source is used.
+func RandomGeometricGraph(dst MutableGraph, n, dim int, radius float64, src *rand.Rand) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	src = randSource(src)
+	if dim != 3 {
+		dim = 2
+	}
+
+	// Cells must be at least radius wide so that neighbors are never more than one cell apart
+	cells := 1
+	if radius > 0 && radius < 1 {
+		cells = int(1 / radius)
+	}
+	cellOf := func(x float64) int {
+		c := int(x * float64(cells))
+		if c >= cells {
+			c = cells - 1
+		}
+		return c
+	}
+
+	points := make([]PointNode, n)
+	grid := make(map[[3]int][]int)
+	for i := range points {
+		p := PointNode{Id: i, X: src.Float64(), Y: src.Float64()}
+		if dim == 3 {
+			p.Z = src.Float64()
+		}
+		points[i] = p
+		dst.AddNode(p, nil)
+
+		cell := [3]int{cellOf(p.X), cellOf(p.Y), cellOf(p.Z)}
+		grid[cell] = append(grid[cell], i)
+	}
+
+	dz := 0
+	if dim == 3 {
+		dz = 1
+	}
+	for i, p := range points {
+		cx, cy, cz := cellOf(p.X), cellOf(p.Y), cellOf(p.Z)
+		for x := cx - 1; x <= cx+1; x++ {
+			for y := cy - 1; y <= cy+1; y++ {
+				for z := cz - dz; z <= cz+dz; z++ {
+					for _, j := range grid[[3]int{x, y, z}] {
+						if j <= i {
+							continue
+						}
+						if d := EuclideanDistance(p, points[j]); d <= radius {
+							edge := GonumEdge{H: p, T: points[j]}
+							dst.AddEdge(edge)
+							dst.SetEdgeCost(edge, d)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// A BlockNode is a node labelled with the block (community) it was generated in, so a generated graph carries its own ground truth.
+type BlockNode struct {
+	Id, Block int
+}
+
+func (node BlockNode) ID() int {
+	return node.Id
+}
+
+// Fills dst with a stochastic block model graph: the nodes are divided into blocks with the given sizes, and an edge from a node in block r to a node in block s is present independently
+// with probability probs[r][s]. For undirected graphs only the upper triangle (r <= s) of probs is read. Nodes are BlockNodes numbered consecutively block by block, and the returned slice lists the
+// members of each block, so community detection output can be scored against the planted partition.
+//
+// Like GnpRandomGraph, this skips geometrically between edges, so it runs in time proportional to the number of nodes and edges generated. If src is nil a time seeded source is used.
+func StochasticBlockModel(dst MutableGraph, sizes []int, probs [][]float64, directed bool, src *rand.Rand) (blocks [][]Node) {
+	dst.EmptyGraph()
+	dst.SetDirected(directed)
+	src = randSource(src)
+
+	blocks = make([][]Node, len(sizes))
+	id := 0
+	for b, size := range sizes {
+		for i := 0; i < size; i++ {
+			node := BlockNode{Id: id, Block: b}
+			dst.AddNode(node, nil)
+			blocks[b] = append(blocks[b], node)
+			id++
+		}
+	}
+
+	// Visits the indices of a candidate space of the given size, keeping each with probability p
+	sample := func(size int, p float64, keep func(idx int)) {
+		if p <= 0 {
+			return
+		}
+		skip := func() int {
+			if p >= 1 {
+				return 0
+			}
+			return int(math.Floor(math.Log(1-src.Float64()) / math.Log(1-p)))
+		}
+		for idx := skip(); idx < size; idx += 1 + skip() {
+			keep(idx)
+		}
+	}
+
+	for r := range sizes {
+		for s := range sizes {
+			if !directed && s < r {
+				continue
+			}
+			br, bs, p := blocks[r], blocks[s], probs[r][s]
+
+			switch {
+			case r != s:
+				sample(len(br)*len(bs), p, func(idx int) {
+					dst.AddEdge(GonumEdge{H: br[idx/len(bs)], T: bs[idx%len(bs)]})
+				})
+			case directed:
+				n := len(br)
+				sample(n*(n-1), p, func(idx int) {
+					head, tail := idx/(n-1), idx%(n-1)
+					if tail >= head {
+						tail++
+					}
+					dst.AddEdge(GonumEdge{H: br[head], T: br[tail]})
+				})
+			default:
+				// Lower triangle index idx -> (v, w) with w < v
+				n := len(br)
+				sample(n*(n-1)/2, p, func(idx int) {
+					v := int((1 + math.Sqrt(float64(1+8*idx))) / 2)
+					for v*(v-1)/2 > idx {
+						v--
+					}
+					for (v+1)*v/2 <= idx {
+						v++
+					}
+					dst.AddEdge(GonumEdge{H: br[v], T: br[idx-v*(v-1)/2]})
+				})
+			}
+		}
+	}
+
+	return blocks
+}
+
+// Fills dst with a random layered DAG of depth layers of width nodes each. Node i lies in layer i/width, so node IDs are already in topological order, and every pair of nodes in different
+// layers is joined by an edge from the earlier to the later layer independently with probability p.
+//
+// If singleSourceSink is set, two extra nodes are added: GonumNode(depth*width) gets an edge to every node without predecessors, and every node without successors gets an edge to
+// GonumNode(depth*width+1), making them the unique source and sink. If src is nil a time seeded source is used.
+func RandomDAG(dst MutableGraph, depth, width int, p float64, singleSourceSink bool, src *rand.Rand) {
+	dst.EmptyGraph()
+	dst.SetDirected(true)
+	src = randSource(src)
+	n := depth * width
+	addNodes(dst, n)
+
+	hasPred, hasSucc := make([]bool, n), make([]bool, n)
+	for u := 0; u < n; u++ {
+		for v := (u/width + 1) * width; v < n; v++ {
+			if src.Float64() < p {
+				dst.AddEdge(GonumEdge{H: GonumNode(u), T: GonumNode(v)})
+				hasSucc[u], hasPred[v] = true, true
+			}
+		}
+	}
+
+	if !singleSourceSink {
+		return
+	}
+
+	source, sink := GonumNode(n), GonumNode(n+1)
+	dst.AddNode(source, nil)
+	dst.AddNode(sink, nil)
+	for u := 0; u < n; u++ {
+		if !hasPred[u] {
+			dst.AddEdge(GonumEdge{H: source, T: GonumNode(u)})
+		}
+		if !hasSucc[u] {
+			dst.AddEdge(GonumEdge{H: GonumNode(u), T: sink})
+		}
+	}
+	if n == 0 {
+		dst.AddEdge(GonumEdge{H: source, T: sink})
+	}
+}
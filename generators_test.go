@@ -0,0 +1,241 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGnpRandomGraph(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		g := graph.NewGonumGraph(directed)
+		graph.GnpRandomGraph(g, 200, 0.1, directed, rand.New(rand.NewSource(1)))
+		if len(g.NodeList()) != 200 {
+			t.Fatalf("G(n,p) has %d nodes, expected 200", len(g.NodeList()))
+		}
+
+		pairs := 200 * 199
+		edges := len(g.EdgeList()) // Undirected graphs list each edge twice, and have half the pairs
+		if expected := 0.1 * float64(pairs); float64(edges) < 0.9*expected || float64(edges) > 1.1*expected {
+			t.Errorf("G(n,p) (directed: %v) has %d edges, expected about %v", directed, edges, expected)
+		}
+		for _, e := range g.EdgeList() {
+			if e.Head().ID() == e.Tail().ID() {
+				t.Error("G(n,p) generated a self loop")
+			}
+		}
+
+		again := graph.NewGonumGraph(directed)
+		graph.GnpRandomGraph(again, 200, 0.1, directed, rand.New(rand.NewSource(1)))
+		if len(again.EdgeList()) != edges {
+			t.Error("G(n,p) isn't reproducible with the same seed")
+		}
+	}
+
+	complete := graph.NewGonumGraph(false)
+	graph.GnpRandomGraph(complete, 10, 1, false, nil)
+	if len(complete.EdgeList()) != 90 {
+		t.Errorf("G(10,1) has %d directed edges, expected 90", len(complete.EdgeList()))
+	}
+}
+
+func TestGnmRandomGraph(t *testing.T) {
+	for _, m := range []int{0, 10, 40, 45, 100} {
+		g := graph.NewGonumGraph(false)
+		graph.GnmRandomGraph(g, 10, m, false, rand.New(rand.NewSource(int64(m))))
+		expected := m
+		if expected > 45 {
+			expected = 45
+		}
+		if len(g.EdgeList()) != 2*expected {
+			t.Errorf("G(10,%d) has %d edges, expected %d", m, len(g.EdgeList())/2, expected)
+		}
+	}
+
+	g := graph.NewGonumGraph(true)
+	graph.GnmRandomGraph(g, 10, 80, true, rand.New(rand.NewSource(1)))
+	if len(g.EdgeList()) != 80 {
+		t.Errorf("Directed G(10,80) has %d edges", len(g.EdgeList()))
+	}
+}
+
+func TestBarabasiAlbertGraph(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.BarabasiAlbertGraph(g, 1000, 3, nil, rand.New(rand.NewSource(1)))
+	if len(g.NodeList()) != 1000 {
+		t.Fatalf("BA graph has %d nodes, expected 1000", len(g.NodeList()))
+	}
+	if edges := len(g.EdgeList()) / 2; edges != 3*997 {
+		t.Errorf("BA graph has %d edges, expected %d", edges, 3*997)
+	}
+
+	maxDegree := 0
+	for _, node := range g.NodeList() {
+		if d := len(g.Successors(node)); d > maxDegree {
+			maxDegree = d
+		}
+	}
+	if maxDegree < 30 {
+		t.Errorf("BA graph has no hubs, the largest degree is %d", maxDegree)
+	}
+
+	seed := buildGraph(false, completeEdges(4))
+	graph.BarabasiAlbertGraph(g, 10, 2, seed, rand.New(rand.NewSource(1)))
+	if len(g.NodeList()) != 10 || len(g.EdgeList())/2 != 6+2*6 {
+		t.Errorf("Seeded BA graph has %d nodes and %d edges", len(g.NodeList()), len(g.EdgeList())/2)
+	}
+}
+
+func TestWattsStrogatzGraph(t *testing.T) {
+	lattice := graph.NewGonumGraph(false)
+	graph.WattsStrogatzGraph(lattice, 20, 4, 0, nil)
+	for _, node := range lattice.NodeList() {
+		if d := len(lattice.Successors(node)); d != 4 {
+			t.Errorf("Unrewired ring lattice node %v has degree %d, expected 4", node, d)
+		}
+	}
+
+	g := graph.NewGonumGraph(false)
+	graph.WattsStrogatzGraph(g, 100, 6, 0.2, rand.New(rand.NewSource(1)))
+	if edges := len(g.EdgeList()) / 2; edges != 300 {
+		t.Errorf("Rewiring changed the number of edges from 300 to %d", edges)
+	}
+
+	rewired := 0
+	for _, e := range g.EdgeList() {
+		if d := (e.Head().ID() - e.Tail().ID() + 100) % 100; d > 3 && d < 97 {
+			rewired++
+		}
+	}
+	if rewired == 0 {
+		t.Error("No edges were rewired with beta = 0.2")
+	}
+}
+
+func TestRandomGeometricGraph(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.RandomGeometricGraph(g, 300, 2, 0.15, rand.New(rand.NewSource(1)))
+
+	nodes := g.NodeList()
+	if len(nodes) != 300 {
+		t.Fatalf("RGG has %d nodes, expected 300", len(nodes))
+	}
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			d := graph.EuclideanDistance(a, b)
+			if (d <= 0.15) != g.IsSuccessor(a, b) {
+				t.Fatalf("Nodes %v and %v at distance %v have edge: %v", a, b, d, g.IsSuccessor(a, b))
+			}
+			if g.IsSuccessor(a, b) && g.Cost(a, b) != d {
+				t.Fatalf("Edge cost %v differs from distance %v", g.Cost(a, b), d)
+			}
+		}
+	}
+}
+
+func TestStochasticBlockModel(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	probs := [][]float64{{1, 0}, {0, 1}}
+	blocks := graph.StochasticBlockModel(g, []int{5, 7}, probs, false, rand.New(rand.NewSource(1)))
+	if len(blocks) != 2 || len(blocks[0]) != 5 || len(blocks[1]) != 7 {
+		t.Fatalf("Wrong blocks: %v", blocks)
+	}
+
+	// Two disjoint cliques
+	if edges := len(g.EdgeList()) / 2; edges != 10+21 {
+		t.Errorf("SBM has %d edges, expected 31", edges)
+	}
+	for _, e := range g.EdgeList() {
+		if e.Head().(graph.BlockNode).Block != e.Tail().(graph.BlockNode).Block {
+			t.Error("Edge between blocks with probability 0:", e)
+		}
+	}
+
+	directed := graph.NewGonumGraph(true)
+	graph.StochasticBlockModel(directed, []int{4, 3}, [][]float64{{0, 1}, {0, 0}}, true, nil)
+	if edges := len(directed.EdgeList()); edges != 12 {
+		t.Errorf("Directed SBM has %d edges, expected 12", edges)
+	}
+}
+
+func TestRandomDAG(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	graph.RandomDAG(g, 6, 5, 0.3, true, rand.New(rand.NewSource(1)))
+	if n := len(g.NodeList()); n != 32 {
+		t.Fatalf("DAG has %d nodes, expected 32", n)
+	}
+
+	sources, sinks := 0, 0
+	for _, node := range g.NodeList() {
+		if len(g.Predecessors(node)) == 0 {
+			sources++
+		}
+		if len(g.Successors(node)) == 0 {
+			sinks++
+		}
+	}
+	if sources != 1 || sinks != 1 {
+		t.Errorf("DAG has %d sources and %d sinks, expected one of each", sources, sinks)
+	}
+
+	for _, e := range g.EdgeList() {
+		h, tl := e.Head().ID(), e.Tail().ID()
+		if h >= 30 || tl >= 30 {
+			continue
+		}
+		if h/5 >= tl/5 {
+			t.Errorf("Edge %v doesn't go to a later layer", e)
+		}
+	}
+}
+
+func sortedEdges(g graph.Graph) [][2]int {
+	edges := make([][2]int, 0)
+	for _, e := range g.EdgeList() {
+		edges = append(edges, [2]int{e.Head().ID(), e.Tail().ID()})
+	}
+	sort.Sort(byPair(edges))
+
+	return edges
+}
+
+type byPair [][2]int
+
+func (p byPair) Len() int      { return len(p) }
+func (p byPair) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byPair) Less(i, j int) bool {
+	return p[i][0] < p[j][0] || p[i][0] == p[j][0] && p[i][1] < p[j][1]
+}
+
+func TestGeneratorsReproducible(t *testing.T) {
+	generators := map[string]func(dst graph.MutableGraph, src *rand.Rand){
+		"Gnp": func(dst graph.MutableGraph, src *rand.Rand) { graph.GnpRandomGraph(dst, 100, 0.05, false, src) },
+		"Gnm": func(dst graph.MutableGraph, src *rand.Rand) { graph.GnmRandomGraph(dst, 100, 300, true, src) },
+		"BA":  func(dst graph.MutableGraph, src *rand.Rand) { graph.BarabasiAlbertGraph(dst, 200, 3, nil, src) },
+		"WS":  func(dst graph.MutableGraph, src *rand.Rand) { graph.WattsStrogatzGraph(dst, 100, 4, 0.3, src) },
+		"RGG": func(dst graph.MutableGraph, src *rand.Rand) { graph.RandomGeometricGraph(dst, 100, 2, 0.15, src) },
+		"SBM": func(dst graph.MutableGraph, src *rand.Rand) {
+			graph.StochasticBlockModel(dst, []int{30, 30}, [][]float64{{0.3, 0.02}, {0.02, 0.3}}, false, src)
+		},
+		"DAG": func(dst graph.MutableGraph, src *rand.Rand) { graph.RandomDAG(dst, 5, 10, 0.2, true, src) },
+	}
+
+	for name, generate := range generators {
+		first, second := graph.NewGonumGraph(false), graph.NewGonumGraph(false)
+		generate(first, rand.New(rand.NewSource(42)))
+		generate(second, rand.New(rand.NewSource(42)))
+		if !reflect.DeepEqual(sortedEdges(first), sortedEdges(second)) {
+			t.Errorf("%s generator isn't reproducible from a seed", name)
+		}
+	}
+
+	for _, algorithm := range []graph.MazeAlgorithm{graph.RecursiveBacktracker, graph.PrimMaze} {
+		first := graph.GenerateMaze(10, 10, algorithm, rand.New(rand.NewSource(42)))
+		second := graph.GenerateMaze(10, 10, algorithm, rand.New(rand.NewSource(42)))
+		if first.String() != second.String() {
+			t.Errorf("Maze %d isn't reproducible from a seed", algorithm)
+		}
+	}
+}
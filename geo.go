@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"math"
+)
+
+// The mean radius of the Earth, in meters.
+const EarthRadius = 6371008.8
+
+// Units for the geographic helpers, which measure distances in meters and speeds in meters per second; e.g. 50*KilometersPerHour is 50 km/h in meters per second, and a
+// distance divided by Mile is in miles.
+const (
+	Meter     = 1.0
+	Kilometer = 1000 * Meter
+	Mile      = 1609.344 * Meter
+
+	MetersPerSecond   = 1.0
+	KilometersPerHour = Kilometer / 3600
+	MilesPerHour      = Mile / 3600
+)
+
+// A node that knows where it is on the Earth, in degrees of latitude and longitude. GeoNode is one.
+type GeoPositioner interface {
+	LatLon() (lat, lon float64)
+}
+
+// Where a node is on the Earth, in degrees, if that's known. NodeLatLon works for GeoPositioner nodes.
+type LatLonFunc func(node Node) (lat, lon float64, ok bool)
+
+// A node at a latitude and longitude, in degrees, as read from a map.
+type GeoNode struct {
+	Id       int
+	Lat, Lon float64
+}
+
+func (node GeoNode) ID() int {
+	return node.Id
+}
+
+func (node GeoNode) LatLon() (lat, lon float64) {
+	return node.Lat, node.Lon
+}
+
+// The latitude and longitude of a GeoPositioner node; ok is false for any other node.
+func NodeLatLon(node Node) (lat, lon float64, ok bool) {
+	if p, ok := node.(GeoPositioner); ok {
+		lat, lon = p.LatLon()
+		return lat, lon, true
+	}
+
+	return 0, 0, false
+}
+
+// The great circle distance in meters between two points given in degrees, by the haversine formula, on a spherical Earth of radius EarthRadius. The Earth is slightly flattened,
+// so over long distances this can be off by up to half a percent either way.
+func HaversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat, dLon := (lat2-lat1)*rad, (lon2-lon1)*rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Returns a heuristic estimating the cost between two nodes as the great circle distance between them in meters, for graphs whose edges cost their length in meters (see
+// SetGeoDistances). Positions come from position, or NodeLatLon if it's nil; a node without one gets an estimate of 0.
+//
+// Because of the Earth's flattening it may overestimate by a fraction of a percent, so A* may return a path that much longer than the shortest; scaling the result by 0.995 makes
+// it strictly admissible.
+func HaversineHeuristic(position LatLonFunc) func(a, b Node) float64 {
+	return TravelTimeHeuristic(position, 1)
+}
+
+// Returns a cost function giving the great circle distance in meters between the ends of an edge, to pass as the Cost of a search on a graph of positioned nodes that has no
+// costs of its own, or can't be changed with SetGeoDistances, such as an ImmutableGraph; HaversineHeuristic is admissible for it. Positions come from position, or NodeLatLon if
+// it's nil. An edge touching a node without a position can't be measured, so it costs +Inf.
+func HaversineCost(position LatLonFunc) func(a, b Node) float64 {
+	if position == nil {
+		position = NodeLatLon
+	}
+
+	return func(a, b Node) float64 {
+		lat1, lon1, ok1 := position(a)
+		lat2, lon2, ok2 := position(b)
+		if !ok1 || !ok2 {
+			return math.Inf(1)
+		}
+
+		return HaversineDistance(lat1, lon1, lat2, lon2)
+	}
+}
+
+// Returns a heuristic estimating the time in seconds to travel between two nodes at maxSpeed meters per second, for graphs whose edges cost their travel time (see
+// SetGeoTravelTimes). maxSpeed must be at least the fastest speed on any edge for the estimate to be admissible.
+func TravelTimeHeuristic(position LatLonFunc, maxSpeed float64) func(a, b Node) float64 {
+	if position == nil {
+		position = NodeLatLon
+	}
+
+	return func(a, b Node) float64 {
+		lat1, lon1, ok1 := position(a)
+		lat2, lon2, ok2 := position(b)
+		if !ok1 || !ok2 {
+			return 0
+		}
+
+		return HaversineDistance(lat1, lon1, lat2, lon2) / maxSpeed
+	}
+}
+
+// Sets the cost of every edge of graph to its length in meters, measured as the great circle distance between its ends. Positions come from position, or NodeLatLon if it's
+// nil; edges touching a node without a position are left alone.
+func SetGeoDistances(graph MutableGraph, position LatLonFunc) {
+	SetGeoTravelTimes(graph, position, func(Edge) float64 { return 1 })
+}
+
+// Sets the cost of every edge of graph to the time in seconds it takes to travel its length (as in SetGeoDistances) at the speed, in meters per second, that speed gives for it.
+// The speed profile would typically look the edge up by road class, e.g. returning 110*KilometersPerHour for motorways. A speed of 0 or less makes the edge cost +Inf.
+func SetGeoTravelTimes(graph MutableGraph, position LatLonFunc, speed func(edge Edge) float64) {
+	if position == nil {
+		position = NodeLatLon
+	}
+
+	for _, edge := range graph.EdgeList() {
+		lat1, lon1, ok1 := position(edge.Head())
+		lat2, lon2, ok2 := position(edge.Tail())
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		cost := math.Inf(1)
+		if s := speed(edge); s > 0 {
+			cost = HaversineDistance(lat1, lon1, lat2, lon2) / s
+		}
+		graph.SetEdgeCost(edge, cost)
+	}
+}
@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"testing"
+)
+
+func TestHaversineDistance(t *testing.T) {
+	for _, test := range []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 343.5 * graph.Kilometer},
+		{"one degree along the equator", 0, 0, 0, 1, 2 * math.Pi * graph.EarthRadius / 360},
+		{"across the date line", 0, 179.5, 0, -179.5, 2 * math.Pi * graph.EarthRadius / 360},
+		{"antipodes", 90, 0, -90, 0, math.Pi * graph.EarthRadius},
+	} {
+		if d := graph.HaversineDistance(test.lat1, test.lon1, test.lat2, test.lon2); math.Abs(d-test.want) > test.want*1e-3 {
+			t.Errorf("%s: got %v m, want %v m", test.name, d, test.want)
+		}
+	}
+}
+
+func TestGeoCosts(t *testing.T) {
+	// A triangle of roads: a slow direct road from 0 to 2, and a fast detour through 1
+	nodes := []graph.GeoNode{{0, 52.0, 4.0}, {1, 52.05, 4.1}, {2, 52.0, 4.2}}
+	g := graph.NewGonumGraph(false)
+	for _, node := range nodes {
+		g.AddNode(node, nil)
+	}
+	direct := graph.GonumEdge{nodes[0], nodes[2]}
+	for _, edge := range []graph.GonumEdge{direct, {nodes[0], nodes[1]}, {nodes[1], nodes[2]}} {
+		g.AddEdge(edge)
+	}
+
+	graph.SetGeoDistances(g, nil)
+	if d, want := g.Cost(nodes[0], nodes[2]), graph.HaversineDistance(52, 4, 52, 4.2); d != want {
+		t.Errorf("The direct road is %v m long, want %v m", d, want)
+	}
+	path, _, _ := graph.AStar(nodes[0], nodes[2], g, nil, graph.HaversineHeuristic(nil))
+	if len(path) != 2 {
+		t.Errorf("The shortest route is %v, want the direct road", path)
+	}
+
+	speed := func(edge graph.Edge) float64 {
+		if edge.Head().ID()+edge.Tail().ID() == 2 {
+			return 30 * graph.KilometersPerHour
+		}
+		return 120 * graph.KilometersPerHour
+	}
+	graph.SetGeoTravelTimes(g, nil, speed)
+	path, seconds, _ := graph.AStar(nodes[0], nodes[2], g, nil, graph.TravelTimeHeuristic(nil, 120*graph.KilometersPerHour))
+	if len(path) != 3 {
+		t.Errorf("The fastest route is %v, want the detour", path)
+	}
+	if detour := g.Cost(nodes[0], nodes[1]) + g.Cost(nodes[1], nodes[2]); seconds != detour || seconds > 10*60 {
+		t.Errorf("The detour takes %v s, want %v s and under 10 minutes", seconds, detour)
+	}
+}
+
+func TestHaversineCost(t *testing.T) {
+	// The same triangle, with no costs set: measured on the fly, the direct road is the shortest
+	nodes := []graph.GeoNode{{0, 52.0, 4.0}, {1, 52.05, 4.1}, {2, 52.0, 4.2}}
+	g := graph.NewGonumGraph(false)
+	for _, node := range nodes {
+		g.AddNode(node, nil)
+	}
+	for _, edge := range []graph.GonumEdge{{nodes[0], nodes[2]}, {nodes[0], nodes[1]}, {nodes[1], nodes[2]}} {
+		g.AddEdge(edge)
+	}
+
+	ds := graph.InitDStar(nodes[0], nodes[2], g, graph.HaversineCost(nil), graph.HaversineHeuristic(nil))
+	path, meters, err := ds.Path()
+	if want := graph.HaversineDistance(52, 4, 52, 4.2); err != nil || len(path) != 2 || meters != want {
+		t.Errorf("Expected the direct road, %v m long, got %v, %v m long (%v)", want, path, meters, err)
+	}
+	if cost := graph.HaversineCost(nil)(nodes[0], graph.GonumNode(3)); !math.IsInf(cost, 1) {
+		t.Errorf("Expected an edge to a node without a position to cost +Inf, got %v", cost)
+	}
+}
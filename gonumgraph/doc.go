@@ -0,0 +1,9 @@
+// Package gonumgraph adapts graphs between this package's interfaces and those of gonum.org/v1/gonum/graph, both ways, so algorithms from either can run on graphs from
+// the other without copying them: Import makes a gonum graph a graph.Graph, and Export makes a graph.Graph a gonum weighted graph, directed or undirected as it is. Nodes that
+// have been through one adapter come back out of the other as themselves.
+//
+// The package needs gonum, so it's only built with the gonum build tag, which keeps gonum out of the dependencies of everyone who doesn't use it:
+//
+//	go get gonum.org/v1/gonum/graph
+//	go build -tags gonum
+package gonumgraph
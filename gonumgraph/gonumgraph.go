@@ -0,0 +1,330 @@
+//go:build gonum
+// +build gonum
+
+package gonumgraph
+
+import (
+	"math"
+	"sync"
+
+	"github.com/gonum/graph"
+	gonum "gonum.org/v1/gonum/graph"
+)
+
+// A gonum node as a graph.Node. Its ID is the gonum node's, which must fit in an int.
+type Node struct {
+	gonum.Node
+}
+
+func (node Node) ID() int {
+	return int(node.Node.ID())
+}
+
+// A graph.Node as a gonum node.
+type gonumNode struct {
+	graph.Node
+}
+
+func (node gonumNode) ID() int64 {
+	return int64(node.Node.ID())
+}
+
+// The graph.Node for a gonum node, unwrapping it if it came from Export.
+func importNode(node gonum.Node) graph.Node {
+	if n, ok := node.(gonumNode); ok {
+		return n.Node
+	}
+	return Node{node}
+}
+
+// The gonum node for a graph.Node, unwrapping it if it came from Import.
+func exportNode(node graph.Node) gonum.Node {
+	if n, ok := node.(Node); ok {
+		return n.Node
+	}
+	return gonumNode{node}
+}
+
+// A gonum graph as a graph.Graph: directed if it's a gonum.Directed, and undirected otherwise. Its Cost is the gonum graph's Weight, if it's a gonum.Weighted, and 1 if it
+// isn't. Every call goes through to the gonum graph, so the adapter sees it change.
+type Graph struct {
+	g gonum.Graph
+}
+
+// Adapts g as a graph.Graph.
+func Import(g gonum.Graph) *Graph {
+	return &Graph{g}
+}
+
+// The gonum graph being adapted.
+func (g *Graph) Gonum() gonum.Graph {
+	return g.g
+}
+
+func (g *Graph) Successors(node graph.Node) []graph.Node {
+	if !g.NodeExists(node) {
+		return nil
+	}
+	return importNodes(g.g.From(int64(node.ID())))
+}
+
+func (g *Graph) IsSuccessor(node, successor graph.Node) bool {
+	if d, ok := g.g.(gonum.Directed); ok {
+		return d.HasEdgeFromTo(int64(node.ID()), int64(successor.ID()))
+	}
+	return g.g.HasEdgeBetween(int64(node.ID()), int64(successor.ID()))
+}
+
+func (g *Graph) Predecessors(node graph.Node) []graph.Node {
+	d, ok := g.g.(gonum.Directed)
+	if !ok {
+		return g.Successors(node)
+	}
+	if !g.NodeExists(node) {
+		return nil
+	}
+	return importNodes(d.To(int64(node.ID())))
+}
+
+func (g *Graph) IsPredecessor(node, predecessor graph.Node) bool {
+	return g.IsSuccessor(predecessor, node)
+}
+
+func (g *Graph) IsAdjacent(node, neighbor graph.Node) bool {
+	return g.g.HasEdgeBetween(int64(node.ID()), int64(neighbor.ID()))
+}
+
+func (g *Graph) NodeExists(node graph.Node) bool {
+	return g.g.Node(int64(node.ID())) != nil
+}
+
+func (g *Graph) Degree(node graph.Node) int {
+	return len(g.Successors(node)) + len(g.Predecessors(node))
+}
+
+func (g *Graph) EdgeList() []graph.Edge {
+	var edges []graph.Edge
+	for _, node := range g.NodeList() {
+		for _, succ := range g.Successors(node) {
+			edges = append(edges, graph.GonumEdge{H: node, T: succ})
+		}
+	}
+	return edges
+}
+
+func (g *Graph) NodeList() []graph.Node {
+	return importNodes(g.g.Nodes())
+}
+
+func (g *Graph) IsDirected() bool {
+	_, ok := g.g.(gonum.Directed)
+	return ok
+}
+
+// The weight of the edge from node to succ, if the gonum graph is weighted, and 1 otherwise. Where there's no edge it's +Inf.
+func (g *Graph) Cost(node, succ graph.Node) float64 {
+	w, ok := g.g.(gonum.Weighted)
+	if !ok {
+		return 1
+	}
+	if cost, ok := w.Weight(int64(node.ID()), int64(succ.ID())); ok {
+		return cost
+	}
+	return math.Inf(1)
+}
+
+func importNodes(it gonum.Nodes) []graph.Node {
+	if it == nil {
+		return nil
+	}
+	nodes := make([]graph.Node, 0, it.Len())
+	for it.Next() {
+		nodes = append(nodes, importNode(it.Node()))
+	}
+	return nodes
+}
+
+// A graph.Graph as a gonum weighted graph, whose Weight is the graph's Cost, as Coster gives it, or 1 if it isn't one. A node's weight to itself is 0 unless it has a self
+// loop, and the weight between nodes with no edge between them is +Inf, as in gonum's simple graphs. Every call goes through to the graph, so the adapter sees it change.
+// It's safe for concurrent use if the graph is.
+type Exported struct {
+	g    graph.Graph
+	cost func(graph.Node, graph.Node) float64
+
+	// Node looks nodes up by ID here, reindexing when it misses a node the graph has
+	mu    sync.Mutex
+	index map[int]graph.Node
+}
+
+// A directed graph.Graph as a gonum.WeightedDirected.
+type ExportedDirected struct {
+	*Exported
+}
+
+// An undirected graph.Graph as a gonum.WeightedUndirected.
+type ExportedUndirected struct {
+	*Exported
+}
+
+// Adapts g as a gonum graph: an ExportedDirected if g is directed, and an ExportedUndirected if not.
+func Export(g graph.Graph) gonum.Weighted {
+	e := &Exported{g: g, cost: graph.UniformCost}
+	if cgraph, ok := g.(graph.Coster); ok {
+		e.cost = cgraph.Cost
+	}
+	if g.IsDirected() {
+		return ExportedDirected{e}
+	}
+	return ExportedUndirected{e}
+}
+
+// The graph.Graph being adapted.
+func (e *Exported) Graph() graph.Graph {
+	return e.g
+}
+
+// The node with the ID, or nil if there isn't one.
+func (e *Exported) Node(id int64) gonum.Node {
+	node := e.node(id)
+	if node == nil {
+		return nil
+	}
+	return exportNode(node)
+}
+
+func (e *Exported) node(id int64) graph.Node {
+	if !e.g.NodeExists(graph.GonumNode(id)) {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if node, ok := e.index[int(id)]; ok {
+		return node
+	}
+	e.index = make(map[int]graph.Node)
+	for _, node := range e.g.NodeList() {
+		e.index[node.ID()] = node
+	}
+	return e.index[int(id)]
+}
+
+func (e *Exported) Nodes() gonum.Nodes {
+	return exportNodes(e.g.NodeList())
+}
+
+func (e *Exported) From(id int64) gonum.Nodes {
+	node := e.node(id)
+	if node == nil {
+		return exportNodes(nil)
+	}
+	return exportNodes(e.g.Successors(node))
+}
+
+func (e *Exported) HasEdgeBetween(xid, yid int64) bool {
+	return e.g.IsAdjacent(graph.GonumNode(xid), graph.GonumNode(yid))
+}
+
+func (e *Exported) Edge(uid, vid int64) gonum.Edge {
+	return e.WeightedEdge(uid, vid)
+}
+
+func (e *Exported) WeightedEdge(uid, vid int64) gonum.WeightedEdge {
+	u, v := e.node(uid), e.node(vid)
+	if u == nil || v == nil || !e.g.IsSuccessor(u, v) {
+		return nil
+	}
+	return edge{exportNode(u), exportNode(v), e.cost(u, v)}
+}
+
+func (e *Exported) Weight(xid, yid int64) (w float64, ok bool) {
+	x, y := e.node(xid), e.node(yid)
+	if x != nil && y != nil && e.g.IsSuccessor(x, y) {
+		return e.cost(x, y), true
+	}
+	if x != nil && xid == yid {
+		return 0, true
+	}
+	return math.Inf(1), false
+}
+
+func (e ExportedDirected) HasEdgeFromTo(uid, vid int64) bool {
+	return e.g.IsSuccessor(graph.GonumNode(uid), graph.GonumNode(vid))
+}
+
+func (e ExportedDirected) To(id int64) gonum.Nodes {
+	node := e.node(id)
+	if node == nil {
+		return exportNodes(nil)
+	}
+	return exportNodes(e.g.Predecessors(node))
+}
+
+func (e ExportedUndirected) EdgeBetween(xid, yid int64) gonum.Edge {
+	return e.Edge(xid, yid)
+}
+
+func (e ExportedUndirected) WeightedEdgeBetween(xid, yid int64) gonum.WeightedEdge {
+	return e.WeightedEdge(xid, yid)
+}
+
+// A weighted gonum edge.
+type edge struct {
+	from, to gonum.Node
+	weight   float64
+}
+
+func (e edge) From() gonum.Node {
+	return e.from
+}
+
+func (e edge) To() gonum.Node {
+	return e.to
+}
+
+func (e edge) ReversedEdge() gonum.Edge {
+	return edge{e.to, e.from, e.weight}
+}
+
+func (e edge) Weight() float64 {
+	return e.weight
+}
+
+// A gonum iterator over a list of nodes.
+type nodes struct {
+	nodes []graph.Node
+	i     int
+}
+
+func exportNodes(list []graph.Node) *nodes {
+	return &nodes{nodes: list, i: -1}
+}
+
+func (it *nodes) Next() bool {
+	if it.i+1 >= len(it.nodes) {
+		it.i = len(it.nodes)
+		return false
+	}
+	it.i++
+	return true
+}
+
+// How many nodes are left to iterate over.
+func (it *nodes) Len() int {
+	if it.i >= len(it.nodes) {
+		return 0
+	}
+	return len(it.nodes) - it.i - 1
+}
+
+func (it *nodes) Reset() {
+	it.i = -1
+}
+
+// The node the iterator is at, or nil before the first call to Next or after the last.
+func (it *nodes) Node() gonum.Node {
+	if it.i < 0 || it.i >= len(it.nodes) {
+		return nil
+	}
+	return exportNode(it.nodes[it.i])
+}
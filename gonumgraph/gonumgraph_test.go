@@ -0,0 +1,136 @@
+//go:build gonum
+// +build gonum
+
+package gonumgraph
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+	gonum "gonum.org/v1/gonum/graph"
+)
+
+// 0 -> 1 -> 3 costs 2, 0 -> 2 -> 3 costs 3, and 0 -> 3 costs 5. 4 is on its own
+func testGraph(directed bool) *graph.GonumGraph {
+	g := graph.NewGonumGraph(directed)
+	for i := 0; i < 5; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range []struct {
+		h, t int
+		cost float64
+	}{{0, 1, 1}, {1, 3, 1}, {0, 2, 1}, {2, 3, 2}, {0, 3, 5}} {
+		edge := graph.GonumEdge{H: graph.GonumNode(e.h), T: graph.GonumNode(e.t)}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, e.cost)
+	}
+	return g
+}
+
+func gonumIDs(it gonum.Nodes) []int {
+	var ids []int
+	for it.Next() {
+		ids = append(ids, int(it.Node().ID()))
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func TestExport(t *testing.T) {
+	g := testGraph(true)
+	e, ok := Export(g).(gonum.WeightedDirected)
+	if !ok {
+		t.Fatal("Expected a directed graph to export as a gonum.WeightedDirected")
+	}
+	if _, ok := e.(gonum.Undirected); ok {
+		t.Error("A directed graph shouldn't export as a gonum.Undirected")
+	}
+
+	if ids := gonumIDs(e.Nodes()); !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("Unexpected nodes %v", ids)
+	}
+	if ids := gonumIDs(e.From(0)); !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("Unexpected successors of 0 %v", ids)
+	}
+	if ids := gonumIDs(e.To(3)); !reflect.DeepEqual(ids, []int{0, 1, 2}) {
+		t.Errorf("Unexpected predecessors of 3 %v", ids)
+	}
+	if e.Node(9) != nil || e.Node(4) == nil || e.Node(4).ID() != 4 {
+		t.Error("Node should find 4 and not 9")
+	}
+	if !e.HasEdgeFromTo(2, 3) || e.HasEdgeFromTo(3, 2) || !e.HasEdgeBetween(3, 2) {
+		t.Error("Unexpected edge between 2 and 3")
+	}
+	if edge := e.WeightedEdge(2, 3); edge == nil || edge.From().ID() != 2 || edge.To().ID() != 3 || edge.Weight() != 2 || edge.ReversedEdge().From().ID() != 3 {
+		t.Errorf("Unexpected edge %v", edge)
+	}
+	if e.Edge(3, 2) != nil {
+		t.Error("Expected no edge from 3 to 2")
+	}
+	if w, ok := e.Weight(0, 3); w != 5 || !ok {
+		t.Errorf("Unexpected weight %v %t", w, ok)
+	}
+	if w, ok := e.Weight(4, 4); w != 0 || !ok {
+		t.Errorf("Unexpected self weight %v %t", w, ok)
+	}
+	if w, ok := e.Weight(3, 0); !math.IsInf(w, 1) || ok {
+		t.Errorf("Unexpected weight without an edge %v %t", w, ok)
+	}
+
+	it := e.From(0)
+	if it.Len() != 3 || !it.Next() || it.Len() != 2 {
+		t.Error("Len should count the nodes left")
+	}
+	for it.Next() {
+	}
+	it.Reset()
+	if it.Len() != 3 || it.Node() != nil {
+		t.Error("Reset should go back to before the first node")
+	}
+
+	// Nodes added after exporting are found
+	g.AddNode(graph.GonumNode(7), nil)
+	if e.Node(7) == nil {
+		t.Error("Expected to find a node added after exporting")
+	}
+
+	if _, ok := Export(testGraph(false)).(gonum.WeightedUndirected); !ok {
+		t.Error("Expected an undirected graph to export as a gonum.WeightedUndirected")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := testGraph(directed)
+		back := Import(Export(g))
+		if back.IsDirected() != directed {
+			t.Errorf("Directedness changed from %t", directed)
+		}
+
+		path, cost, _ := graph.AStar(graph.GonumNode(0), graph.GonumNode(3), back, nil, nil)
+		if cost != 2 || len(path) != 3 {
+			t.Errorf("Unexpected path %v costing %v through an imported graph", path, cost)
+		}
+		for _, node := range path {
+			if _, ok := node.(graph.GonumNode); !ok {
+				t.Errorf("Expected the original nodes back, got %T", node)
+			}
+		}
+
+		if len(back.EdgeList()) != len(g.EdgeList()) || back.Degree(graph.GonumNode(3)) != g.Degree(graph.GonumNode(3)) {
+			t.Errorf("Edges or degrees changed on the way through")
+		}
+		if sccs := graph.Tarjan(back); len(sccs) != len(graph.Tarjan(g)) {
+			t.Errorf("Expected the same components, got %v", sccs)
+		}
+		if back.NodeExists(graph.GonumNode(9)) || back.Successors(graph.GonumNode(9)) != nil {
+			t.Error("Expected nothing of a missing node")
+		}
+		if !math.IsInf(back.Cost(graph.GonumNode(4), graph.GonumNode(0)), 1) {
+			t.Error("Expected an infinite cost between nodes with no edge")
+		}
+	}
+}
@@ -1,10 +1,10 @@
 package graph
 
 import (
+	"fmt"
 	"github.com/gonum/graph/set"
 	"github.com/gonum/graph/xifo"
 	"math"
-	"sort"
 )
 
 type Node interface {
@@ -31,6 +31,20 @@ type Graph interface {
 	IsDirected() bool                          // Returns whether this graph is directed or not
 }
 
+// An ImplicitGraph is a graph defined only by how to get from one node to the next, like the state space of a puzzle or a motion planning lattice, which may be far too large
+// (or infinite) to list with NodeList. Every Graph is an ImplicitGraph. Searches that only ever look at the neighborhood of the nodes they've reached, like AStar, IDAStar and
+// a Planner, take one.
+type ImplicitGraph interface {
+	Successors(node Node) []Node
+}
+
+// A ReversibleGraph is an ImplicitGraph that can also be followed backwards, as D*-Lite needs to, which searches from the goal. If it has an IsDirected method returning false,
+// it's treated as undirected, and as directed otherwise. Every Graph is a ReversibleGraph.
+type ReversibleGraph interface {
+	ImplicitGraph
+	Predecessors(node Node) []Node
+}
+
 // A Graph that implements Coster has an actual cost between adjacent nodes, also known as a weighted graph. If a graph implements coster and a function needs to read cost (e.g. A*), this function will
 // take precedence over the Uniform Cost function (all weights are 1) if "nil" is passed in for the function argument
 //
@@ -40,6 +54,28 @@ type Coster interface {
 	Cost(node1, node2 Node) float64
 }
 
+// A graph that implements SuccessorVisitor can list a node's successors without allocating a slice for them, which adds up in the inner loops of searches. VisitSuccessors calls fn
+// with every successor of node and the cost of the edge to it (as Cost would return, or 1 for a graph without costs), stopping early if fn returns false.
+//
+// Searches in this package use it when a graph implements it and no Cost function overrides the graph's costs; VisitSuccessors (the function) adapts any other graph. Beware of
+// embedding a SuccessorVisitor in a type that overrides Successors: VisitSuccessors has to be overridden to match.
+type SuccessorVisitor interface {
+	VisitSuccessors(node Node, fn func(succ Node, cost float64) bool)
+}
+
+// PredecessorVisitor is to Predecessors what SuccessorVisitor is to Successors: VisitPredecessors calls fn with every predecessor of node and the cost of the edge from it to node,
+// stopping early if fn returns false. D*-Lite, which searches backwards from the goal, uses it to find the nodes to update after every expansion.
+type PredecessorVisitor interface {
+	VisitPredecessors(node Node, fn func(pred Node, cost float64) bool)
+}
+
+// A NeighborIterator can visit neighbors both ways without allocating, as every graph type in this package can. A search uses whichever half it needs, so a graph only
+// has to implement that half to benefit, and one that implements neither is visited through Successors and Predecessors as before.
+type NeighborIterator interface {
+	SuccessorVisitor
+	PredecessorVisitor
+}
+
 type CostGraph interface {
 	Coster
 	Graph
@@ -142,9 +178,9 @@ func Tarjan(graph Graph) (sccs [][]Node) {
 	lowlinks := make(map[int]int, len(nodes))
 	indices := make(map[int]int, len(nodes))
 
-	var strongconnect func(Node) []Node
+	var strongconnect func(Node)
 
-	strongconnect = func(node Node) []Node {
+	strongconnect = func(node Node) {
 		indices[node.ID()] = index
 		lowlinks[node.ID()] = index
 		index += 1
@@ -156,11 +192,12 @@ func Tarjan(graph Graph) (sccs [][]Node) {
 			if _, ok := indices[succ.ID()]; !ok {
 				strongconnect(succ)
 				lowlinks[node.ID()] = int(math.Min(float64(lowlinks[node.ID()]), float64(lowlinks[succ.ID()])))
-			} else if stackSet.Contains(succ) {
+			} else if stackSet.Contains(succ.ID()) {
 				lowlinks[node.ID()] = int(math.Min(float64(lowlinks[node.ID()]), float64(lowlinks[succ.ID()])))
 			}
 		}
 
+		// Components are finished deepest first, so every one is collected here rather than only those the outer loop started from
 		if lowlinks[node.ID()] == indices[node.ID()] {
 			scc := make([]Node, 0)
 			for {
@@ -168,23 +205,57 @@ func Tarjan(graph Graph) (sccs [][]Node) {
 				stackSet.Remove(v.(Node).ID())
 				scc = append(scc, v.(Node))
 				if v.(Node).ID() == node.ID() {
-					return scc
+					sccs = append(sccs, scc)
+					return
 				}
 			}
 		}
-
-		return nil
 	}
 
 	for _, n := range nodes {
 		if _, ok := indices[n.ID()]; !ok {
-			sccs = append(sccs, strongconnect(n))
+			strongconnect(n)
 		}
 	}
 
 	return sccs
 }
 
+// Builds the condensation of graph: the DAG with a node for each strongly connected component, and an edge from one component to another wherever graph has an edge between
+// them. Any graph becomes acyclic this way, so DAG algorithms such as topological sorting or longest paths can be run on the components of a cyclic one.
+//
+// The DAG's nodes are GonumNodes numbered in topological order, so every edge goes from a lower ID to a higher one, and components[i] lists the nodes of graph in component
+// GonumNode(i). componentOf maps the ID of every node in graph to its component's node. The DAG's edges have the default cost of 1; an undirected graph condenses to one
+// node per connected component and no edges.
+func Condense(graph Graph) (dag *GonumGraph, components [][]Node, componentOf map[int]Node) {
+	sccs := Tarjan(graph)
+
+	// Tarjan finds components sinks first
+	dag = NewGonumGraph(true)
+	components = make([][]Node, len(sccs))
+	componentOf = make(map[int]Node)
+	for i, scc := range sccs {
+		c := GonumNode(len(sccs) - 1 - i)
+		components[c] = scc
+		for _, node := range scc {
+			componentOf[node.ID()] = c
+		}
+		dag.AddNode(c, nil)
+	}
+
+	for c, scc := range components {
+		for _, node := range scc {
+			for _, succ := range graph.Successors(node) {
+				if to := componentOf[succ.ID()]; to.ID() != c {
+					dag.AddEdge(GonumEdge{H: GonumNode(c), T: to})
+				}
+			}
+		}
+	}
+
+	return dag, components, componentOf
+}
+
 // Returns true if, starting at path[0] and ending at path[len(path)-1], all nodes between are valid neighbors. That is, for each element path[i], path[i+1] is a valid successor
 //
 // Special case: a nil or zero length path is considered valid (true), a path of length 1 (only one node) is the trivial case, but only if the node listed in path exists.
@@ -204,62 +275,41 @@ func IsPath(path []Node, graph Graph) bool {
 	return true
 }
 
-/* Implements minimum-spanning tree algorithms; puts the resulting minimum spanning tree in the dst graph */
-
-// Generates a minimum spanning tree with sets.
-//
-// As with other algorithms that use Cost, the order of precedence is Argument > Interface > UniformCost
-func Prim(dst MutableGraph, graph Graph, Cost func(Node, Node) float64) {
-	if Cost == nil {
-		if cgraph, ok := graph.(Coster); ok {
-			Cost = cgraph.Cost
-		} else {
-			Cost = UniformCost
-		}
-	}
-	dst.EmptyGraph()
-	dst.SetDirected(false)
-
-	nlist := graph.NodeList()
+// Describes where a path handed to ValidatePath or PathCost breaks: there's no edge From To, the nodes at Index and Index+1. A path of a single node that isn't in the graph
+// breaks at Index 0, with To nil.
+type PathError struct {
+	Index    int
+	From, To Node
+}
 
-	if nlist == nil || len(nlist) == 0 {
-		return
+func (err *PathError) Error() string {
+	if err.To == nil {
+		return fmt.Sprintf("Path node %v is not in the graph", err.From)
 	}
+	return fmt.Sprintf("Path has no edge from %v to %v (step %d)", err.From, err.To, err.Index)
+}
 
-	dst.AddNode(nlist[0], nil)
-	remainingNodes := set.NewSet()
-	for _, node := range nlist[1:] {
-		remainingNodes.Add(node.ID())
+// Like IsPath, but says where the path breaks: returns nil for a valid path (including an empty one), or a *PathError for the first step without an edge.
+func ValidatePath(graph Graph, path []Node) error {
+	if len(path) == 1 && !graph.NodeExists(path[0]) {
+		return &PathError{Index: 0, From: path[0]}
 	}
 
-	edgeList := graph.EdgeList()
-	for remainingNodes.Cardinality() != 0 {
-		edgeWeights := make(edgeSorter, 0)
-		for _, edge := range edgeList {
-			if dst.NodeExists(edge.Head()) && remainingNodes.Contains(edge.Tail().ID()) {
-				edgeWeights = append(edgeWeights, WeightedEdge{Edge: edge, Weight: Cost(edge.Head(), edge.Tail())})
-			}
-		}
-
-		sort.Sort(edgeWeights)
-		myEdge := edgeWeights[0]
-
-		if !dst.NodeExists(myEdge.Head()) {
-			dst.AddNode(myEdge.Head(), []Node{myEdge.Tail()})
-		} else {
-			dst.AddEdge(myEdge.Edge)
+	for i := 0; i < len(path)-1; i++ {
+		if !graph.IsSuccessor(path[i], path[i+1]) {
+			return &PathError{Index: i, From: path[i], To: path[i+1]}
 		}
-		dst.SetEdgeCost(myEdge.Edge, myEdge.Weight)
-
-		remainingNodes.Remove(myEdge.Edge.Head())
 	}
 
+	return nil
 }
 
-// Generates a minimum spanning tree for a graph using discrete.DisjointSet
-//
-// As with other algorithms with Cost, the precedence goes Argument > Interface > UniformCost
-func Kruskal(dst MutableGraph, graph Graph, Cost func(Node, Node) float64) {
+// Validates a path as ValidatePath does and totals the costs of its edges, for checking a planner's result independently of the planner. Costs come from Cost, the graph's
+// Coster, or UniformCost as usual. An empty or single node path costs 0.
+func PathCost(graph Graph, Cost func(Node, Node) float64, path []Node) (cost float64, err error) {
+	if err := ValidatePath(graph, path); err != nil {
+		return 0, err
+	}
 	if Cost == nil {
 		if cgraph, ok := graph.(Coster); ok {
 			Cost = cgraph.Cost
@@ -267,33 +317,30 @@ func Kruskal(dst MutableGraph, graph Graph, Cost func(Node, Node) float64) {
 			Cost = UniformCost
 		}
 	}
-	dst.EmptyGraph()
-	dst.SetDirected(false)
 
-	edgeList := graph.EdgeList()
-	edgeWeights := make(edgeSorter, 0, len(edgeList))
-	for _, edge := range edgeList {
-		edgeWeights = append(edgeWeights, WeightedEdge{Edge: edge, Weight: Cost(edge.Head(), edge.Tail())})
+	for i := 0; i < len(path)-1; i++ {
+		cost += Cost(path[i], path[i+1])
 	}
 
-	sort.Sort(edgeWeights)
+	return cost, nil
+}
 
-	ds := set.NewDisjointSet()
-	for _, node := range graph.NodeList() {
-		ds.MakeSet(node.ID())
-	}
+/* Implements minimum-spanning tree algorithms; puts the resulting minimum spanning tree in the dst graph */
 
-	for _, edge := range edgeWeights {
-		if s1, s2 := ds.Find(edge.Edge.Head().ID()), ds.Find(edge.Edge.Tail().ID); s1 != s2 {
-			ds.Union(s1, s2)
-			if !dst.NodeExists(edge.Edge.Head()) {
-				dst.AddNode(edge.Edge.Head(), []Node{edge.Edge.Tail()})
-			} else {
-				dst.AddEdge(edge.Edge)
-			}
-			dst.SetEdgeCost(edge.Edge, edge.Weight)
-		}
-	}
+// Generates a minimum spanning tree with Prim's algorithm, or on a disconnected graph a minimum spanning forest, as PrimEdges finds it. dst ends up undirected, with every node
+// of graph, including those with no edges, and the forest's edges at their costs.
+//
+// As with other algorithms that use Cost, the order of precedence is Argument > Interface > UniformCost
+func Prim(dst MutableGraph, graph Graph, Cost func(Node, Node) float64) {
+	fillForest(dst, graph, PrimEdges(graph, Cost))
+}
+
+// Generates a minimum spanning tree for a graph using discrete.DisjointSet, or on a disconnected graph a minimum spanning forest, as KruskalEdges finds it. dst ends up as
+// Prim leaves it.
+//
+// As with other algorithms with Cost, the precedence goes Argument > Interface > UniformCost
+func Kruskal(dst MutableGraph, graph Graph, Cost func(Node, Node) float64) {
+	fillForest(dst, graph, KruskalEdges(graph, Cost))
 }
 
 /* Control flow graph stuff */
@@ -2,13 +2,18 @@ package graph
 
 import (
 	"container/heap"
+	"context"
+	"errors"
 	"github.com/gonum/graph/set"
 	"github.com/gonum/graph/xifo"
+	"math"
+	"time"
 )
 
 // Returns an ordered list consisting of the nodes between start and goal. The path will be the shortest path assuming the function heuristicCost is admissible.
 // The second return value is the cost, and the third is the number of nodes expanded while searching (useful info for tuning heuristics). Negative Costs will cause
-// bad things to happen, as well as negative heuristic estimates.
+// bad things to happen, as well as negative heuristic estimates. If there's no path, the path is nil and the cost 0, with nodesExpanded still counting the search's work;
+// AStarCtx has the same results with an error besides, for searches that can be cancelled.
 //
 // A heuristic is admissible if, for any node in the graph, the heuristic estimate of the cost between the node and the goal is less than or equal to the true cost.
 //
@@ -21,17 +26,50 @@ import (
 //
 // Finally, if neither the argument nor the interface is present, the function will assume discrete.UniformCost for Cost and discrete.NullHeuristic for HeuristicCost
 //
-// To run Uniform Cost Search, run A* with the NullHeuristic
+// To run Uniform Cost Search, run A* with the NullHeuristic, or better, UniformCostSearch
 //
-// To run Breadth First Search, run A* with both the NullHeuristic and UniformCost (or any cost function that returns a uniform positive value)
-func AStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
-	if Cost == nil {
-		if cgraph, ok := graph.(Coster); ok {
-			Cost = cgraph.Cost
-		} else {
-			Cost = UniformCost
-		}
-	}
+// To run Breadth First Search, run A* with both the NullHeuristic and UniformCost (or any cost function that returns a uniform positive value), or better, BFSShortestPath
+func AStar(start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	path, cost, nodesExpanded, _, _ = aStar(nil, nil, start, goal, graph, Cost, HeuristicCost)
+	return path, cost, nodesExpanded
+}
+
+// Like AStar, but gives up when ctx is cancelled, returning ctx.Err() along with the number of nodes expanded so far. The context is checked periodically
+// rather than on every expansion, so cancellation takes effect within a few hundred expansions.
+func AStarCtx(ctx context.Context, start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int, err error) {
+	path, cost, nodesExpanded, _, err = aStar(newCanceller(ctx), nil, start, goal, graph, Cost, HeuristicCost)
+	return path, cost, nodesExpanded, err
+}
+
+// Like AStar, but tells observer about the search as it goes, for animating it: every node queued or requeued with a better score (OnKeyChange, with its f-score as k1 and
+// g-score as k2), every node expanded (OnExpand), and the path found, if there is one (OnPathFound). A* keeps no lookahead, so OnUpdateVertex is never called.
+func AStarObserved(start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64, observer SearchObserver) (path []Node, cost float64, nodesExpanded int) {
+	path, cost, nodesExpanded, _, _ = aStar(nil, observer, start, goal, graph, Cost, HeuristicCost)
+	return path, cost, nodesExpanded
+}
+
+// What a search had found when it was stopped early.
+type PartialSearch struct {
+	Closest       Node    // The expanded node with the lowest heuristic estimate to the goal (ties broken by lower cost from the start)
+	Path          []Node  // The best path found from the start to Closest
+	Cost          float64 // The cost of Path
+	Bound         float64 // A lower bound on the cost of the optimal path to the goal, if the heuristic is admissible
+	NodesExpanded int
+}
+
+// Runs A* within a budget. If the goal is reached, the result is the same as AStar's. If the budget runs out first, ErrBudgetExhausted is returned along with the best partial
+// result: a path to the node that seemed closest to the goal, and a lower bound on the optimal cost (the smallest f-score still queued). With the NullHeuristic, every expanded
+// node is equally "close", so Closest is just the start; use an informative heuristic.
+//
+// If no path exists, all return values are nil/zero, as with AStar.
+func AStarBudget(start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64, budget SearchBudget) (path []Node, cost float64, partial *PartialSearch, err error) {
+	path, cost, _, partial, err = aStar(budget.canceller(nil), nil, start, goal, graph, Cost, HeuristicCost)
+	return path, cost, partial, err
+}
+
+// The partial result is only returned along with an error.
+func aStar(cancel *canceller, observer SearchObserver, start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int, partial *PartialSearch, err error) {
+	visit := successorVisitor(graph, Cost)
 	if HeuristicCost == nil {
 		if hgraph, ok := graph.(HeuristicCoster); ok {
 			HeuristicCost = hgraph.HeuristicCost
@@ -45,9 +83,24 @@ func AStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Node) f
 	heap.Init(openSet)
 	node := internalNode{start, 0, HeuristicCost(start, goal)}
 	heap.Push(openSet, node)
+	if observer != nil {
+		observer.OnKeyChange(start, node.fscore, 0)
+	}
 	predecessor := make(map[int]Node)
+	queued := map[int]float64{start.ID(): 0} // The best g-score each node has been queued with
+	closest := node
 
 	for openSet.Len() != 0 {
+		if err := cancel.err(); err != nil {
+			partial = &PartialSearch{
+				Closest:       closest.Node,
+				Path:          rebuildPath(predecessor, closest.Node),
+				Cost:          closest.gscore,
+				Bound:         (*openSet)[0].fscore,
+				NodesExpanded: nodesExpanded,
+			}
+			return nil, 0.0, nodesExpanded, partial, err
+		}
 		curr := heap.Pop(openSet).(internalNode)
 
 		// This isn't in most implementations of A*, it's a restructuring of the step "if node not in openSet, add it"
@@ -57,30 +110,324 @@ func AStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Node) f
 		}
 
 		nodesExpanded += 1
+		if h, best := curr.fscore-curr.gscore, closest.fscore-closest.gscore; h < best || h == best && curr.gscore < closest.gscore {
+			closest = curr
+		}
+
+		if observer != nil {
+			observer.OnExpand(curr.Node, curr.gscore)
+		}
 
 		if curr.ID() == goal.ID() {
-			return rebuildPath(predecessor, goal), curr.gscore, nodesExpanded
+			path = rebuildPath(predecessor, goal)
+			if observer != nil {
+				observer.OnPathFound(path, curr.gscore)
+			}
+			return path, curr.gscore, nodesExpanded, nil, nil
 		}
 
 		closedSet[curr.ID()] = curr
 
-		for _, neighbor := range graph.Successors(curr.Node) {
-			g := curr.gscore + Cost(curr.Node, neighbor)
+		visit(curr.Node, func(neighbor Node, edgeCost float64) bool {
+			g := curr.gscore + edgeCost
 			if _, ok := closedSet[neighbor.ID()]; ok && g >= closedSet[neighbor.ID()].gscore {
-				continue
+				return true
+			}
+
+			// Only record the predecessor if this is the best way to the neighbor found so far, or a worse path could overwrite the one that's still queued
+			if best, ok := queued[neighbor.ID()]; ok && g >= best {
+				return true
 			}
 
 			if _, ok := closedSet[neighbor.ID()]; !ok || g < closedSet[neighbor.ID()].gscore {
+				queued[neighbor.ID()] = g
 				node = internalNode{neighbor, g, g + HeuristicCost(neighbor, goal)}
-				predecessor[node.ID()] = curr
+				predecessor[node.ID()] = curr.Node
 				heap.Push(openSet, node)
+				if observer != nil {
+					observer.OnKeyChange(neighbor, node.fscore, g)
+				}
 			}
+			return true
+		})
+	}
+
+	return nil, 0.0, nodesExpanded, nil, nil
+}
+
+// Iterative deepening A*[1]: a series of depth first searches, each cut off where a node's f-score (cost so far plus heuristic estimate) exceeds a bound, starting from the start's
+// estimate and raised to the lowest f-score that was cut off each time. It returns the same as AStar, and likewise finds the shortest path if the heuristic is admissible, but it only
+// remembers the path it's currently on, so its memory use is proportional to the length of the path instead of the number of nodes reached. That makes it the search of choice
+// for huge implicit state spaces like sliding puzzles, at the cost of expanding nodes again in every iteration (nodesExpanded counts every time).
+//
+// Cost and HeuristicCost are interpreted as in AStar. Costs must be positive. If no path exists and the graph is infinite, IDAStar never returns.
+//
+// [1] R. E. Korf, "Depth-first iterative-deepening: An optimal admissible tree search", Artificial Intelligence 27 (1985)
+func IDAStar(start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	visit := successorVisitor(graph, Cost)
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+
+	path = []Node{start}
+	onPath := map[int]bool{start.ID(): true}
+
+	// Returns the lowest f-score beyond bound, or the path's cost with found set
+	var search func(node Node, g, bound float64) (next float64, found bool)
+	search = func(node Node, g, bound float64) (next float64, found bool) {
+		if f := g + HeuristicCost(node, goal); f > bound {
+			return f, false
+		}
+		if node.ID() == goal.ID() {
+			return g, true
 		}
+
+		nodesExpanded += 1
+		next = math.Inf(1)
+		visit(node, func(succ Node, edgeCost float64) bool {
+			if onPath[succ.ID()] {
+				return true // Cycles can't be part of the shortest path
+			}
+
+			path = append(path, succ)
+			onPath[succ.ID()] = true
+			t, ok := search(succ, g+edgeCost, bound)
+			if ok {
+				next, found = t, true
+				return false
+			}
+			next = math.Min(next, t)
+			path = path[:len(path)-1]
+			delete(onPath, succ.ID())
+			return true
+		})
+
+		return next, found
+	}
+
+	for bound := HeuristicCost(start, goal); !math.IsInf(bound, 1); {
+		next, found := search(start, 0, bound)
+		if found {
+			return path, next, nodesExpanded
+		}
+		bound = next
 	}
 
 	return nil, 0.0, nodesExpanded
 }
 
+// Greedy best-first search: always expands the queued node that the heuristic estimates is closest to the goal, ignoring the cost of getting there. It heads straight for the
+// goal and usually expands far fewer nodes than A*, but the path it returns can be much longer than the shortest, especially where obstacles lie between the start and goal; use
+// it when any path found fast is better than the best path found slowly. With the NullHeuristic it degrades to an arbitrary order of expansion, so give it an informative one.
+//
+// Arguments and results are as for AStar; Cost is only used to total the cost of the returned path.
+func GreedyBestFirst(start, goal Node, graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	visit := successorVisitor(graph, Cost)
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+
+	// Nodes are queued by their estimate alone, and only the first time they're reached: a later, cheaper way to a node doesn't change how close to the goal it looks
+	openSet := &aStarPriorityQueue{}
+	heap.Push(openSet, internalNode{start, 0, HeuristicCost(start, goal)})
+	predecessor := make(map[int]Node)
+	reached := map[int]bool{start.ID(): true}
+
+	for openSet.Len() != 0 {
+		curr := heap.Pop(openSet).(internalNode)
+		nodesExpanded += 1
+		if curr.ID() == goal.ID() {
+			return rebuildPath(predecessor, goal), curr.gscore, nodesExpanded
+		}
+
+		visit(curr.Node, func(neighbor Node, edgeCost float64) bool {
+			if !reached[neighbor.ID()] {
+				reached[neighbor.ID()] = true
+				predecessor[neighbor.ID()] = curr.Node
+				heap.Push(openSet, internalNode{neighbor, curr.gscore + edgeCost, HeuristicCost(neighbor, goal)})
+			}
+			return true
+		})
+	}
+
+	return nil, 0.0, nodesExpanded
+}
+
+// Uniform cost search: Dijkstra's algorithm from start, stopping as soon as goal is settled. It finds the same shortest paths as A* with the NullHeuristic, without the
+// heuristic machinery (no estimates computed or stored), and unlike Dijkstra it works on implicit graphs and doesn't explore beyond the goal. Costs must not be negative.
+//
+// Arguments and results are as for AStar.
+func UniformCostSearch(start, goal Node, graph ImplicitGraph, Cost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	path, cost, nodesExpanded, _ = uniformCostSearch(nil, start, goal, graph, Cost)
+	return path, cost, nodesExpanded
+}
+
+// Like UniformCostSearch, but gives up when ctx is cancelled, returning ctx.Err() along with the number of nodes expanded so far, as AStarCtx does.
+func UniformCostSearchCtx(ctx context.Context, start, goal Node, graph ImplicitGraph, Cost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int, err error) {
+	return uniformCostSearch(newCanceller(ctx), start, goal, graph, Cost)
+}
+
+func uniformCostSearch(cancel *canceller, start, goal Node, graph ImplicitGraph, Cost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int, err error) {
+	visit := successorVisitor(graph, Cost)
+
+	// With no heuristic a node's f-score is just its g-score. Nodes are queued again when a cheaper way to them is found, and the stale entries skipped when popped
+	openSet := &aStarPriorityQueue{{start, 0, 0}}
+	settled := make(map[int]bool)
+	best := map[int]float64{start.ID(): 0}
+	predecessor := make(map[int]Node)
+
+	for openSet.Len() != 0 {
+		if err := cancel.err(); err != nil {
+			return nil, 0.0, nodesExpanded, err
+		}
+		curr := heap.Pop(openSet).(internalNode)
+		if settled[curr.ID()] {
+			continue
+		}
+		settled[curr.ID()] = true
+		nodesExpanded += 1
+		if curr.ID() == goal.ID() {
+			return rebuildPath(predecessor, goal), curr.gscore, nodesExpanded, nil
+		}
+
+		visit(curr.Node, func(neighbor Node, edgeCost float64) bool {
+			g := curr.gscore + edgeCost
+			if b, ok := best[neighbor.ID()]; settled[neighbor.ID()] || ok && g >= b {
+				return true
+			}
+			best[neighbor.ID()] = g
+			predecessor[neighbor.ID()] = curr.Node
+			heap.Push(openSet, internalNode{neighbor, g, g})
+			return true
+		})
+	}
+
+	return nil, 0.0, nodesExpanded, nil
+}
+
+// Finds a path from start to goal with the fewest edges, by breadth first search, ignoring any costs. Returns the path and its length in edges (hops), or nil and 0 if goal can't
+// be reached. This is the fastest way to answer shortest path queries on unweighted graphs: a plain FIFO queue and no costs or priorities at all.
+func BFSShortestPath(start, goal Node, graph ImplicitGraph) (path []Node, hops int) {
+	predecessor := map[int]Node{start.ID(): nil}
+	for queue := []Node{start}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		if node.ID() == goal.ID() {
+			for ; node != nil; node = predecessor[node.ID()] {
+				path = append(path, node)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, len(path) - 1
+		}
+
+		for _, succ := range graph.Successors(node) {
+			if _, seen := predecessor[succ.ID()]; !seen {
+				predecessor[succ.ID()] = node
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	return nil, 0
+}
+
+// The number of edges on the shortest path from source to every node reachable from it, by node ID, found by breadth first search (source itself is at level 0). The graph
+// must be finite, or at least source's component of it. For big Graphs, DirectionOptimizingBFS computes the same levels faster.
+func HopDistances(source Node, graph ImplicitGraph) (levels map[int]int) {
+	levels = map[int]int{source.ID(): 0}
+	for queue := []Node{source}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		for _, succ := range graph.Successors(node) {
+			if _, seen := levels[succ.ID()]; !seen {
+				levels[succ.ID()] = levels[node.ID()] + 1
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	return levels
+}
+
+// Finds a path from start to goal with the fewest edges, like BFSShortestPath, but searching from both ends at once until the searches meet in the middle. Each step expands the
+// whole next level of whichever side has the smaller frontier. On graphs where the number of nodes within k hops grows quickly with k, like small world social networks, the
+// two searches together reach around the square root of the nodes a one sided search would, which answers "degrees of separation" queries orders of magnitude faster.
+//
+// The backward search follows Predecessors, so the graph must be reversible. Returns nil and 0 if goal can't be reached from start.
+func BidirectionalBFS(start, goal Node, graph ReversibleGraph) (path []Node, hops int) {
+	if start.ID() == goal.ID() {
+		return []Node{start}, 0
+	}
+
+	forward := &bfsSide{parent: map[int]Node{start.ID(): nil}, dist: map[int]int{start.ID(): 0}, frontier: []Node{start}, next: graph.Successors}
+	backward := &bfsSide{parent: map[int]Node{goal.ID(): nil}, dist: map[int]int{goal.ID(): 0}, frontier: []Node{goal}, next: graph.Predecessors}
+
+	for len(forward.frontier) > 0 && len(backward.frontier) > 0 {
+		var meet Node
+		if len(forward.frontier) <= len(backward.frontier) {
+			meet = forward.expand(backward)
+		} else {
+			meet = backward.expand(forward)
+		}
+		if meet == nil {
+			continue
+		}
+
+		for node := meet; node != nil; node = forward.parent[node.ID()] {
+			path = append(path, node)
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		for node := backward.parent[meet.ID()]; node != nil; node = backward.parent[node.ID()] {
+			path = append(path, node)
+		}
+		return path, len(path) - 1
+	}
+
+	return nil, 0
+}
+
+// One direction of a bidirectional BFS
+type bfsSide struct {
+	parent   map[int]Node
+	dist     map[int]int
+	frontier []Node
+	next     func(Node) []Node
+}
+
+// Expands the whole frontier by one level, returning the node where this side met the other on the shortest path through them, or nil if they haven't met. Every meeting
+// found within a level has to be compared, since the other side's nodes aren't all at the same distance.
+func (side *bfsSide) expand(other *bfsSide) (meet Node) {
+	best := -1
+	var next []Node
+	for _, node := range side.frontier {
+		for _, succ := range side.next(node) {
+			if _, seen := side.dist[succ.ID()]; seen {
+				continue
+			}
+			side.parent[succ.ID()] = node
+			side.dist[succ.ID()] = side.dist[node.ID()] + 1
+			next = append(next, succ)
+
+			if d, ok := other.dist[succ.ID()]; ok && (best == -1 || side.dist[succ.ID()]+d < best) {
+				meet, best = succ, side.dist[succ.ID()]+d
+			}
+		}
+	}
+	side.frontier = next
+
+	return meet
+}
+
 // Dijkstra's Algorithm is essentially a goalless Uniform Cost Search. That is, its results are roughly equivalent to
 // running A* with the Null Heuristic from a single node to every other node in the graph -- though it's a fair bit faster
 // because running A* in that way will recompute things it's already computed every call. Note that you won't necessarily get the same path
@@ -88,8 +435,21 @@ func AStar(start, goal Node, graph Graph, Cost, HeuristicCost func(Node, Node) f
 //
 // Like A*, Dijkstra's Algorithm likely won't run correctly with negative edge weights -- use Bellman-Ford for that instead
 //
-// Dijkstra's algorithm usually only returns a cost map, however, since the data is available this version will also reconstruct the path to every node
+// Dijkstra's algorithm usually only returns a cost map, however, since the data is available this version will also reconstruct the path to every node. DijkstraTree
+// gives the same paths without building them all up front.
 func Dijkstra(source Node, graph Graph, Cost func(Node, Node) float64) (paths map[int][]Node, costs map[int]float64) {
+	tree := DijkstraTree(source, graph, Cost)
+	paths = make(map[int][]Node, len(tree.Order))
+	costs = make(map[int]float64, len(tree.Order))
+	for _, node := range tree.Order {
+		paths[node.ID()], costs[node.ID()] = tree.PathTo(node)
+	}
+	return paths, costs
+}
+
+// Like Dijkstra, but with a true decrease-key priority queue of the given kind instead of queueing a node again every time a shorter path to it is found. Each node is queued at
+// most once, so the queue stays as small as the frontier, which matters on dense graphs where most edges find a shorter path to a node that's already queued.
+func DijkstraQueue(source Node, graph Graph, Cost func(Node, Node) float64, kind QueueKind) (paths map[int][]Node, costs map[int]float64) {
 	if Cost == nil {
 		if cgraph, ok := graph.(Coster); ok {
 			Cost = cgraph.Cost
@@ -97,69 +457,43 @@ func Dijkstra(source Node, graph Graph, Cost func(Node, Node) float64) (paths ma
 			Cost = UniformCost
 		}
 	}
-	nodes := graph.NodeList()
-	openSet := &aStarPriorityQueue{}
-	closedSet := set.NewSet()                 // This is to make use of that same
-	costs = make(map[int]float64, len(nodes)) // May overallocate, will change if it becomes a problem
-	predecessor := make(map[int]Node, len(nodes))
-	nodeIDMap := make(map[int]Node, len(nodes))
-	heap.Init(openSet)
-
-	// I don't think we actually need the init step since I use a map check rather than inf to check if we're done
-	/*for _, node := range nodes {
-		if node == source {
-			heap.Push(openSet, internalNode{node, 0, 0})
-			costs[node] = 0
-		} else {
-			heap.Push(openSet, internalNode{node, math.MaxFloat64, math.MaxFloat64})
-			predecessor[node] = -1
-		}
-	}*/
+	openSet := NewPriorityQueue(kind, func(a, b HeapItem) bool {
+		return a.Key.(float64) < b.Key.(float64)
+	})
+	costs = make(map[int]float64)
+	predecessor := make(map[int]Node)
+	settled := make(map[int]Node)
 
 	costs[source.ID()] = 0
-	heap.Push(openSet, internalNode{source, 0, 0})
-
+	openSet.Push(source, 0.0)
 	for openSet.Len() != 0 {
-		node := heap.Pop(openSet).(internalNode)
-		/* if _, ok := costs[node.int]; !ok {
-			 break
-		 } */
-
-		if closedSet.Contains(node.ID()) { // As in A*, prevents us from having to slowly search and reorder the queue
-			continue
-		}
-
-		nodeIDMap[node.ID()] = node
+		curr := openSet.Pop()
+		settled[curr.ID()] = curr.Node
 
-		closedSet.Add(node.ID())
-
-		for _, neighbor := range graph.Successors(node) {
-			tmpCost := costs[node.ID()] + Cost(node, neighbor)
+		for _, neighbor := range graph.Successors(curr.Node) {
+			if _, ok := settled[neighbor.ID()]; ok {
+				continue
+			}
+			tmpCost := curr.Key.(float64) + Cost(curr.Node, neighbor)
 			if cost, ok := costs[neighbor.ID()]; !ok || tmpCost < cost {
-				costs[neighbor.ID()] = cost
-				predecessor[neighbor.ID()] = node
-				heap.Push(openSet, internalNode{neighbor, cost, cost})
+				costs[neighbor.ID()] = tmpCost
+				predecessor[neighbor.ID()] = curr.Node
+				openSet.Push(neighbor, tmpCost) // Decreases the key if it's already queued
 			}
 		}
 	}
 
-	paths = make(map[int][]Node, len(costs))
-	for node, _ := range costs { // Only reconstruct the path if one exists
-		paths[node] = rebuildPath(predecessor, nodeIDMap[node])
+	paths = make(map[int][]Node, len(settled))
+	for id, node := range settled {
+		paths[id] = rebuildPath(predecessor, node)
 	}
 	return paths, costs
 }
 
-// The Bellman-Ford Algorithm is the same as Dijkstra's Algorithm with a key difference. They both take a single source and find the shortest path to every other
-// (reachable) node in the graph. Bellman-Ford, however, will detect negative edge loops and abort if one is present. A negative edge loop occurs when there is a cycle in the graph
-// such that it can take an edge with a negative cost over and over. A -(-2)> B -(2)> C isn't a loop because A->B can only be taken once, but A<-(-2)->B-(2)>C is one because
-// A and B have a bi-directional edge, and algorithms like Dijkstra's will infinitely flail between them getting progressively lower costs.
-//
-// That said, if you do not have a negative edge weight, use Dijkstra's Algorithm instead, because it's faster.
-//
-// Like Dijkstra's, along with the costs this implementation will also construct all the paths for you. In addition, it has a third return value which will be true if the algorithm was aborted
-// due to the presence of a negative edge weight cycle.
-func BellmanFord(source Node, graph Graph, Cost func(Node, Node) float64) (paths map[int][]Node, costs map[int]float64, aborted bool) {
+// Dial's algorithm: Dijkstra's algorithm for graphs whose costs are all small non-negative integers, using a BucketQueue instead of a heap. It returns the same as Dijkstra, or an error
+// if Cost returns anything but a non-negative whole number. Its running time is linear in the size of the graph plus the cost of the longest shortest path, so it's best suited to
+// grid maps and hop counts rather than graphs with large costs.
+func DialDijkstra(source Node, graph Graph, Cost func(Node, Node) float64) (paths map[int][]Node, costs map[int]float64, err error) {
 	if Cost == nil {
 		if cgraph, ok := graph.(Coster); ok {
 			Cost = cgraph.Cost
@@ -167,37 +501,63 @@ func BellmanFord(source Node, graph Graph, Cost func(Node, Node) float64) (paths
 			Cost = UniformCost
 		}
 	}
-
+	openSet := NewBucketQueue()
+	dist := make(map[int]int)
 	predecessor := make(map[int]Node)
-	costs = make(map[int]float64)
-	nodeIDMap := make(map[int]Node)
-	nodeIDMap[source.ID()] = source
-	costs[source.ID()] = 0
-	nodes := graph.NodeList()
-	edges := graph.EdgeList()
-
-	for i := 1; i < len(nodes)-1; i++ {
-		for _, edge := range edges {
-			weight := Cost(edge.Head(), edge.Tail())
-			nodeIDMap[edge.Head().ID()] = edge.Head()
-			nodeIDMap[edge.Tail().ID()] = edge.Tail()
-			if dist := costs[edge.Head().ID()] + weight; dist < costs[edge.Tail().ID()] {
-				costs[edge.Tail().ID()] = dist
-				predecessor[edge.Tail().ID()] = edge.Head()
+	settled := make(map[int]Node)
+
+	dist[source.ID()] = 0
+	openSet.Push(source, 0)
+	for openSet.Len() != 0 {
+		curr := openSet.Pop()
+		settled[curr.ID()] = curr.Node
+
+		for _, neighbor := range graph.Successors(curr.Node) {
+			if _, ok := settled[neighbor.ID()]; ok {
+				continue
+			}
+			cost := Cost(curr.Node, neighbor)
+			if cost < 0 || cost != math.Floor(cost) || math.IsInf(cost, 0) {
+				return nil, nil, errors.New("Dial's algorithm needs non-negative integer costs")
+			}
+
+			tmpDist := curr.Key.(int) + int(cost)
+			if d, ok := dist[neighbor.ID()]; !ok || tmpDist < d {
+				dist[neighbor.ID()] = tmpDist
+				predecessor[neighbor.ID()] = curr.Node
+				openSet.Push(neighbor, tmpDist)
 			}
 		}
 	}
 
-	for _, edge := range edges {
-		weight := Cost(edge.Head(), edge.Tail())
-		if costs[edge.Head().ID()]+weight < costs[edge.Tail().ID()] {
-			return nil, nil, true // Abandoned because a cycle is detected
-		}
+	paths = make(map[int][]Node, len(settled))
+	costs = make(map[int]float64, len(settled))
+	for id, node := range settled {
+		paths[id] = rebuildPath(predecessor, node)
+		costs[id] = float64(dist[id])
 	}
+	return paths, costs, nil
+}
 
-	paths = make(map[int][]Node, len(costs))
-	for node, _ := range costs {
-		paths[node] = rebuildPath(predecessor, nodeIDMap[node])
+// The Bellman-Ford Algorithm is the same as Dijkstra's Algorithm with a key difference. They both take a single source and find the shortest path to every other
+// (reachable) node in the graph. Bellman-Ford, however, will detect negative edge loops and abort if one is present. A negative edge loop occurs when there is a cycle in the graph
+// such that it can take an edge with a negative cost over and over. A -(-2)> B -(2)> C isn't a loop because A->B can only be taken once, but A<-(-2)->B-(2)>C is one because
+// A and B have a bi-directional edge, and algorithms like Dijkstra's will infinitely flail between them getting progressively lower costs.
+//
+// That said, if you do not have a negative edge weight, use Dijkstra's Algorithm instead, because it's faster.
+//
+// Like Dijkstra's, along with the costs this implementation will also construct all the paths for you. In addition, it has a third return value which will be true if the algorithm was aborted
+// due to the presence of a negative edge weight cycle. BellmanFordTree says which cycle that was.
+func BellmanFord(source Node, graph Graph, Cost func(Node, Node) float64) (paths map[int][]Node, costs map[int]float64, aborted bool) {
+	tree, err := BellmanFordTree(source, graph, Cost)
+	if err != nil {
+		return nil, nil, true // Abandoned because a cycle is detected
+	}
+
+	paths = make(map[int][]Node, len(tree.Order))
+	costs = make(map[int]float64, len(tree.Order))
+	for _, node := range tree.Order {
+		paths[node.ID()], costs[node.ID()] = tree.PathTo(node)
 	}
 	return paths, costs, false
 }
@@ -211,10 +571,17 @@ func BellmanFord(source Node, graph Graph, Cost func(Node, Node) float64) (paths
 //
 // This algorithm is fairly slow. Its purpose is to remove negative edge weights to allow Dijkstra's to function properly. It's probably not worth it to run this algorithm if you have
 // all non-negative edge weights. Also note that this implementation copies your whole graph into a GonumGraph (so it can add/remove the dummy node and edges and reweight the graph).
+// JohnsonAllPairs does without the copy, and keeps its results in matrices rather than maps of maps.
 //
 // Its return values are, in order: a map from the source node, to the destination node, to the path between them; a map from the source node, to the destination node, to the cost of the path between them;
 // and a bool that is true if Bellman-Ford detected a negative edge weight cycle -- thus causing it (and this algorithm) to abort (if aborted is true, both maps will be nil).
 func Johnson(graph Graph, Cost func(Node, Node) float64) (nodePaths map[int]map[int][]Node, nodeCosts map[int]map[int]float64, aborted bool) {
+	return JohnsonProgress(graph, Cost, nil, 0)
+}
+
+// Johnson's Algorithm with progress reporting. Progress is reported at most once per interval (and once at the end), counting one unit per source node whose Dijkstra run is done.
+// The reported Bound is the largest path cost in the results so far.
+func JohnsonProgress(graph Graph, Cost func(Node, Node) float64, report ProgressFunc, interval time.Duration) (nodePaths map[int]map[int][]Node, nodeCosts map[int]map[int]float64, aborted bool) {
 	if Cost == nil {
 		if cgraph, ok := graph.(Coster); ok {
 			Cost = cgraph.Cost
@@ -262,8 +629,20 @@ func Johnson(graph Graph, Cost func(Node, Node) float64) (nodePaths map[int]map[
 	nodePaths = make(map[int]map[int][]Node, len(graph.NodeList()))
 	nodeCosts = make(map[int]map[int]float64)
 
-	for _, node := range graph.NodeList() {
+	progress := newProgressReporter(report, interval)
+	nodes := graph.NodeList()
+	bound := noBound
+	for i, node := range nodes {
 		nodePaths[node.ID()], nodeCosts[node.ID()] = Dijkstra(node, dummyGraph, nil)
+		// Dijkstra's costs are in the reweighted graph, which the potentials have to be taken back out of
+		for id, cost := range nodeCosts[node.ID()] {
+			cost += costs[id] - costs[node.ID()]
+			nodeCosts[node.ID()][id] = cost
+			if math.IsNaN(bound) || cost > bound {
+				bound = cost
+			}
+		}
+		progress.update(i+1, len(nodes), bound)
 	}
 
 	return nodePaths, nodeCosts, false
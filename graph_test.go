@@ -3,6 +3,8 @@ package graph_test
 import (
 	"github.com/gonum/graph"
 	"math"
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -140,3 +142,157 @@ func TestHarderAStar(t *testing.T) {
 		t.Error("Non-optimal or impossible path found for 100x100 grid; cost:", cost, "path:\n"+tg.PathString(path))
 	}
 }
+
+func TestPathCost(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	g.AddNode(graph.GonumNode(0), []graph.Node{graph.GonumNode(1)})
+	g.AddEdge(graph.GonumEdge{graph.GonumNode(1), graph.GonumNode(2)})
+	g.SetEdgeCost(graph.GonumEdge{graph.GonumNode(0), graph.GonumNode(1)}, 2.5)
+
+	path := []graph.Node{graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(2)}
+	if cost, err := graph.PathCost(g, nil, path); err != nil || cost != 3.5 {
+		t.Errorf("Path costs %v (error %v), want 3.5", cost, err)
+	}
+	if cost, err := graph.PathCost(g, graph.UniformCost, path); err != nil || cost != 2 {
+		t.Errorf("Path costs %v (error %v) with uniform costs, want 2", cost, err)
+	}
+
+	// Against the direction of an edge
+	backwards := []graph.Node{graph.GonumNode(0), graph.GonumNode(2), graph.GonumNode(1)}
+	err := graph.ValidatePath(g, backwards)
+	if perr, ok := err.(*graph.PathError); !ok || perr.Index != 0 || perr.To.ID() != 2 {
+		t.Errorf("Got error %v for a broken path, want a PathError at step 0", err)
+	}
+	if _, err := graph.PathCost(g, nil, backwards); err == nil {
+		t.Error("Costed a broken path")
+	}
+
+	if err := graph.ValidatePath(g, []graph.Node{graph.GonumNode(7)}); err == nil {
+		t.Error("Validated a path through a node not in the graph")
+	}
+	if err := graph.ValidatePath(g, nil); err != nil {
+		t.Error("The empty path is invalid:", err)
+	}
+}
+
+func TestUniformCostSearch(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(g, 60, 0.1, true, rand.New(rand.NewSource(1)))
+	src := rand.New(rand.NewSource(2))
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, 1+9*src.Float64())
+	}
+
+	start := graph.GonumNode(0)
+	for _, goal := range g.NodeList() {
+		path, cost, _ := graph.UniformCostSearch(start, goal, g, nil)
+		optimal, want, _ := graph.AStar(start, goal, g, nil, nil)
+		if optimal == nil {
+			if path != nil {
+				t.Errorf("Found a path to unreachable node %v", goal)
+			}
+			continue
+		}
+		if math.Abs(cost-want) > 1e-9 || !graph.IsPath(path, g) || path[len(path)-1].ID() != goal.ID() {
+			t.Errorf("Expected a path to %v costing %v, got %v costing %v", goal, want, path, cost)
+		}
+		if pathCost, err := graph.PathCost(g, nil, path); err != nil || math.Abs(pathCost-cost) > 1e-9 {
+			t.Errorf("Path to %v costs %v, but was reported to cost %v", goal, pathCost, cost)
+		}
+	}
+}
+
+func TestBFSShortestPath(t *testing.T) {
+	maze := graph.GenerateMaze(10, 10, graph.RecursiveBacktracker, rand.New(rand.NewSource(4)))
+	start, goal := maze.CoordsToNode(1, 1), maze.CoordsToNode(19, 19)
+
+	path, hops := graph.BFSShortestPath(start, goal, maze)
+	optimal, cost, _ := graph.AStar(start, goal, maze, nil, nil)
+	if float64(hops) != cost || len(path) != len(optimal) || !graph.IsPath(path, maze) {
+		t.Errorf("Expected a path of %v hops, got %d: %v", cost, hops, path)
+	}
+
+	if path, hops := graph.BFSShortestPath(start, start, maze); len(path) != 1 || hops != 0 {
+		t.Errorf("Expected the path from a node to itself to be just the node, got %v (%d hops)", path, hops)
+	}
+	if path, _ := graph.BFSShortestPath(start, graph.GonumNode(0), maze); path != nil {
+		t.Errorf("Found a path into a wall: %v", path)
+	}
+
+	g := graph.NewGonumGraph(false)
+	graph.WattsStrogatzGraph(g, 200, 4, 0.1, rand.New(rand.NewSource(5)))
+	if levels := graph.HopDistances(graph.GonumNode(0), g); !reflect.DeepEqual(levels, graph.DirectionOptimizingBFS(graph.GonumNode(0), g, 1)) {
+		t.Error("HopDistances disagrees with DirectionOptimizingBFS")
+	}
+}
+
+func TestBidirectionalBFS(t *testing.T) {
+	for _, directed := range []bool{false, true} {
+		g := graph.NewGonumGraph(directed)
+		if directed {
+			graph.GnpRandomGraph(g, 300, 0.01, true, rand.New(rand.NewSource(6)))
+		} else {
+			graph.WattsStrogatzGraph(g, 300, 4, 0.1, rand.New(rand.NewSource(6)))
+		}
+
+		for i := 0; i < 300; i += 7 {
+			start, goal := graph.GonumNode(i), graph.GonumNode((i*31+11)%300)
+			path, hops := graph.BidirectionalBFS(start, goal, g)
+			shortest, want := graph.BFSShortestPath(start, goal, g)
+			if shortest == nil {
+				if path != nil {
+					t.Errorf("Directed %t: found a path from %v to unreachable %v", directed, start, goal)
+				}
+				continue
+			}
+			if hops != want || len(path) != hops+1 || !graph.IsPath(path, g) || path[0].ID() != start.ID() || path[hops].ID() != goal.ID() {
+				t.Errorf("Directed %t: expected a path of %d hops from %v to %v, got %v", directed, want, start, goal, path)
+			}
+		}
+	}
+
+	g := graph.NewGonumGraph(false)
+	if path, hops := graph.BidirectionalBFS(graph.GonumNode(3), graph.GonumNode(3), g); len(path) != 1 || hops != 0 {
+		t.Errorf("Expected the path from a node to itself to be just the node, got %v", path)
+	}
+}
+
+func TestCondense(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 6; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 0}, {2, 3}, {3, 4}, {4, 3}, {1, 5}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	dag, components, componentOf := graph.Condense(g)
+	if len(components) != 3 || len(dag.NodeList()) != 3 {
+		t.Fatalf("Expected 3 components, got %v", components)
+	}
+	if componentOf[0] != componentOf[1] || componentOf[1] != componentOf[2] || componentOf[3] != componentOf[4] || componentOf[0] == componentOf[3] || componentOf[5] == componentOf[3] {
+		t.Errorf("Wrong components: %v", componentOf)
+	}
+	if !dag.IsSuccessor(componentOf[0], componentOf[3]) || !dag.IsSuccessor(componentOf[0], componentOf[5]) || len(dag.EdgeList()) != 2 {
+		t.Errorf("Wrong condensation edges: %v", dag.EdgeList())
+	}
+
+	random := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(random, 100, 0.015, true, rand.New(rand.NewSource(1)))
+	dag, components, componentOf = graph.Condense(random)
+	for c, component := range components {
+		for _, node := range component {
+			if componentOf[node.ID()].ID() != c {
+				t.Errorf("Node %v is listed in component %d but mapped to %v", node, c, componentOf[node.ID()])
+			}
+		}
+	}
+	for _, edge := range random.EdgeList() {
+		if from, to := componentOf[edge.Head().ID()].ID(), componentOf[edge.Tail().ID()].ID(); from > to || from < to && !dag.IsSuccessor(graph.GonumNode(from), graph.GonumNode(to)) {
+			t.Errorf("Edge %v goes from component %d to %d, which the condensation doesn't order or connect", edge, from, to)
+		}
+	}
+	if sccs := graph.Tarjan(dag); len(sccs) != len(components) {
+		t.Errorf("The condensation has a cycle")
+	}
+}
@@ -0,0 +1,354 @@
+// Package graphbench loads the standard shortest path benchmark datasets and times the package's searches on them against each other, writing CSV reports of how long each took
+// and how many nodes it expanded, so regressions and algorithm choices can be judged on real graphs rather than on toy ones.
+//
+// The loaders read the formats as they're distributed:
+//
+//	DIMACS       the 9th DIMACS implementation challenge's road networks: a .gr file of weighted arcs and optionally a .co file of node coordinates
+//	SNAP         the Stanford Network Analysis Project's edge lists, such as its social networks, with unit costs
+//	Moving AI    movingai.com's grid pathfinding benchmarks: a .map file of terrain and a .scen file of queries with their optimal costs
+//
+// Many of the files are distributed compressed; wrap the reader with gzip.NewReader or the like before loading them.
+package graphbench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gonum/graph"
+)
+
+// A shortest path query to benchmark, with its optimal cost if that's known, as it is for Moving AI scenarios, or -1 if it isn't.
+type Query struct {
+	Source, Target graph.Node
+	Optimal        float64
+}
+
+// Loads a DIMACS shortest path problem from its .gr file, whose "p sp n m" line declares nodes 1 to n and whose "a u v w" lines are arcs from u to v costing w. Where an arc is
+// given more than once the cheapest counts. If co isn't nil it's read as the matching .co file, whose "v id x y" lines place the nodes, which are then PointNodes at those
+// coordinates for the geometric heuristics; otherwise they're GonumNodes. The challenge's coordinates are in millionths of a degree of longitude and latitude, so scale a
+// heuristic to the arc costs before relying on it being admissible.
+func LoadDIMACS(gr, co io.Reader) (*graph.GonumGraph, error) {
+	var positions map[int]graph.PointNode
+	if co != nil {
+		positions = make(map[int]graph.PointNode)
+		err := scanLines(co, "c", func(line int, fields []string) error {
+			if fields[0] != "v" {
+				return nil
+			}
+			if len(fields) != 4 {
+				return fmt.Errorf("line %d: expected v id x y", line)
+			}
+			nums, err := parseInts(fields[1:])
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			positions[nums[0]] = graph.PointNode{Id: nums[0], X: float64(nums[1]), Y: float64(nums[2])}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := graph.NewGonumGraph(true)
+	n := -1
+	err := scanLines(gr, "c", func(line int, fields []string) error {
+		switch fields[0] {
+		case "p":
+			if len(fields) != 4 || fields[1] != "sp" {
+				return fmt.Errorf("line %d: expected p sp n m", line)
+			}
+			if n >= 0 {
+				return fmt.Errorf("line %d: a second problem line", line)
+			}
+			nums, err := parseInts(fields[2:3])
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			n = nums[0]
+			for id := 1; id <= n; id++ {
+				if p, ok := positions[id]; ok {
+					g.AddNode(p, nil)
+				} else {
+					g.AddNode(graph.GonumNode(id), nil)
+				}
+			}
+		case "a":
+			if n < 0 {
+				return fmt.Errorf("line %d: an arc before the problem line", line)
+			}
+			if len(fields) != 4 {
+				return fmt.Errorf("line %d: expected a u v w", line)
+			}
+			nums, err := parseInts(fields[1:3])
+			if err != nil {
+				return fmt.Errorf("line %d: %v", line, err)
+			}
+			cost, err := strconv.ParseFloat(fields[3], 64)
+			if err != nil {
+				return fmt.Errorf("line %d: bad cost %q", line, fields[3])
+			}
+			for _, id := range nums {
+				if id < 1 || id > n {
+					return fmt.Errorf("line %d: node %d isn't between 1 and %d", line, id, n)
+				}
+			}
+			from, tail := graph.GonumNode(nums[0]), graph.GonumNode(nums[1])
+			if g.IsSuccessor(from, tail) && g.Cost(from, tail) <= cost {
+				return nil
+			}
+			edge := graph.GonumEdge{H: from, T: tail}
+			g.AddEdge(edge)
+			g.SetEdgeCost(edge, cost)
+		default:
+			return fmt.Errorf("line %d: unexpected %q", line, fields[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("no problem line")
+	}
+
+	return g, nil
+}
+
+// Loads a SNAP edge list: a "from to" pair of node IDs per line, separated by whitespace, with lines starting with # ignored. Every edge costs 1, and nodes are GonumNodes.
+func LoadSNAP(r io.Reader, directed bool) (*graph.GonumGraph, error) {
+	g := graph.NewGonumGraph(directed)
+	err := scanLines(r, "#", func(line int, fields []string) error {
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expected two node IDs", line)
+		}
+		ids, err := parseInts(fields)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		g.AddNode(graph.GonumNode(ids[0]), nil)
+		g.AddNode(graph.GonumNode(ids[1]), nil)
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(ids[0]), T: graph.GonumNode(ids[1])})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// Loads a Moving AI .map file as the octile grid its benchmarks are solved on: an undirected graph of the passable tiles, '.', 'G' and 'S', each joined to its passable
+// neighbors at cost 1 straight and √2 diagonally, where a diagonal move is only allowed if both tiles it cuts the corner of are passable too. Tiles are PointNodes with x the
+// column and y the row, so OctileHeuristic(graph.NodeCoordinates, 1, math.Sqrt2) is exact on an open map, and the ID of the tile at (x, y) is y*width + x, as Scenario.Query
+// has it.
+func LoadMovingAIMap(r io.Reader) (g *graph.GonumGraph, width, height int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	header := map[string]int{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 1 && fields[0] == "map" {
+			break
+		}
+		if len(fields) != 2 {
+			return nil, 0, 0, fmt.Errorf("line %d: expected a header line", line)
+		}
+		if fields[0] == "type" {
+			if fields[1] != "octile" {
+				return nil, 0, 0, fmt.Errorf("line %d: unsupported map type %q", line, fields[1])
+			}
+			continue
+		}
+		v, err := strconv.Atoi(fields[1])
+		if err != nil || v < 0 {
+			return nil, 0, 0, fmt.Errorf("line %d: bad %s %q", line, fields[0], fields[1])
+		}
+		header[fields[0]] = v
+	}
+	width, ok1 := header["width"]
+	height, ok2 := header["height"]
+	if !ok1 || !ok2 {
+		return nil, 0, 0, fmt.Errorf("map has no width or height")
+	}
+
+	passable := make([][]bool, height)
+	for row := range passable {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, 0, 0, err
+			}
+			return nil, 0, 0, fmt.Errorf("map has %d rows, expected %d", row, height)
+		}
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if len(text) != width {
+			return nil, 0, 0, fmt.Errorf("line %d: row is %d tiles wide, expected %d", line, len(text), width)
+		}
+		passable[row] = make([]bool, width)
+		for col := 0; col < width; col++ {
+			switch text[col] {
+			case '.', 'G', 'S':
+				passable[row][col] = true
+			}
+		}
+	}
+
+	g = graph.NewGonumGraph(false)
+	tile := func(row, col int) graph.Node {
+		return graph.PointNode{Id: row*width + col, X: float64(col), Y: float64(row)}
+	}
+	open := func(row, col int) bool {
+		return row >= 0 && row < height && col >= 0 && col < width && passable[row][col]
+	}
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			if passable[row][col] {
+				g.AddNode(tile(row, col), nil)
+			}
+		}
+	}
+	// Each tile links to its neighbors right, down and diagonally down, which covers every adjacency once
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			if !passable[row][col] {
+				continue
+			}
+			for _, step := range [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}} {
+				r, c := row+step[0], col+step[1]
+				if !open(r, c) {
+					continue
+				}
+				cost := 1.0
+				if step[0] != 0 && step[1] != 0 {
+					if !open(row, c) || !open(r, col) {
+						continue
+					}
+					cost = math.Sqrt2
+				}
+				edge := graph.GonumEdge{H: tile(row, col), T: tile(r, c)}
+				g.AddEdge(edge)
+				g.SetEdgeCost(edge, cost)
+			}
+		}
+	}
+
+	return g, width, height, scanner.Err()
+}
+
+// A query from a Moving AI .scen file, on the map named Map of the given size. Bucket groups queries of similar length.
+type Scenario struct {
+	Bucket                       int
+	Map                          string
+	Width, Height                int
+	StartX, StartY, GoalX, GoalY int
+	Optimal                      float64
+}
+
+// The scenario as a Query on the map LoadMovingAIMap loads.
+func (s Scenario) Query() Query {
+	return Query{
+		Source:  graph.PointNode{Id: s.StartY*s.Width + s.StartX, X: float64(s.StartX), Y: float64(s.StartY)},
+		Target:  graph.PointNode{Id: s.GoalY*s.Width + s.GoalX, X: float64(s.GoalX), Y: float64(s.GoalY)},
+		Optimal: s.Optimal,
+	}
+}
+
+// Loads a Moving AI .scen file: a "version" line and then a scenario per line.
+func LoadMovingAIScenarios(r io.Reader) ([]Scenario, error) {
+	var scenarios []Scenario
+	err := scanLines(r, "", func(line int, fields []string) error {
+		if fields[0] == "version" {
+			return nil
+		}
+		if len(fields) != 9 {
+			return fmt.Errorf("line %d: expected bucket, map, width, height, start, goal and optimal cost", line)
+		}
+		nums, err := parseInts(append([]string{fields[0]}, fields[2:8]...))
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		optimal, err := strconv.ParseFloat(fields[8], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: bad optimal cost %q", line, fields[8])
+		}
+		scenarios = append(scenarios, Scenario{
+			Bucket: nums[0], Map: fields[1], Width: nums[1], Height: nums[2],
+			StartX: nums[3], StartY: nums[4], GoalX: nums[5], GoalY: nums[6],
+			Optimal: optimal,
+		})
+		return nil
+	})
+
+	return scenarios, err
+}
+
+// Picks n queries between nodes of g chosen uniformly at random, for datasets that don't come with their own. Their optimal costs aren't known. The same source gives the same
+// queries every time; a nil source gets a time seeded one.
+func RandomQueries(g graph.Graph, n int, src *rand.Rand) []Query {
+	nodes := g.NodeList()
+	if len(nodes) == 0 {
+		return nil
+	}
+	sort.Sort(byID(nodes))
+	if src == nil {
+		src = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	queries := make([]Query, n)
+	for i := range queries {
+		queries[i] = Query{Source: nodes[src.Intn(len(nodes))], Target: nodes[src.Intn(len(nodes))], Optimal: -1}
+	}
+	return queries
+}
+
+// Calls fn with the fields of every line of r that isn't blank or a comment, which starts with the field comment if that isn't empty, stopping at the first error.
+func scanLines(r io.Reader, comment string, fn func(line int, fields []string) error) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || comment != "" && strings.HasPrefix(fields[0], comment) {
+			continue
+		}
+		if err := fn(line, fields); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseInts(fields []string) ([]int, error) {
+	nums := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("bad integer %q", field)
+		}
+		nums[i] = n
+	}
+
+	return nums, nil
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
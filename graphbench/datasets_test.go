@@ -0,0 +1,136 @@
+package graphbench
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestLoadDIMACS(t *testing.T) {
+	gr := `c a tiny road network
+p sp 4 5
+a 1 2 3
+a 2 1 3
+a 2 3 4
+a 2 3 2
+a 1 4 10
+`
+	co := `c coordinates
+p aux sp co 4
+v 1 0 0
+v 2 3 0
+v 3 3 2
+v 4 10 0
+`
+	g, err := LoadDIMACS(strings.NewReader(gr), strings.NewReader(co))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.NodeList()); n != 4 {
+		t.Errorf("Expected 4 nodes, got %d", n)
+	}
+	if cost := g.Cost(graph.GonumNode(2), graph.GonumNode(3)); cost != 2 {
+		t.Errorf("Expected the cheaper of the parallel arcs to count, got cost %v", cost)
+	}
+	if g.IsSuccessor(graph.GonumNode(3), graph.GonumNode(2)) {
+		t.Error("Arcs should be directed")
+	}
+	for _, node := range g.Successors(graph.GonumNode(1)) {
+		if x, y, ok := graph.NodeCoordinates(node); !ok || node.ID() == 4 && (x != 10 || y != 0) {
+			t.Errorf("Node %d has no position, or the wrong one", node.ID())
+		}
+	}
+
+	if _, err := LoadDIMACS(strings.NewReader(gr), nil); err != nil {
+		t.Errorf("Coordinates should be optional, got %v", err)
+	}
+	for _, bad := range []string{"a 1 2 3\n", "p sp 2 1\na 1 3 1\n", "p sp 2 1\na 1 2 x\n", "c nothing\n", "p sp 2 1\nq\n"} {
+		if _, err := LoadDIMACS(strings.NewReader(bad), nil); err == nil {
+			t.Errorf("Expected an error loading %q", bad)
+		}
+	}
+}
+
+func TestLoadSNAP(t *testing.T) {
+	g, err := LoadSNAP(strings.NewReader("# Directed graph\n# FromNodeId\tToNodeId\n0\t1\n1\t2\n0\t1\n7\t0\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, m := len(g.NodeList()), len(g.EdgeList()); n != 4 || m != 3 {
+		t.Errorf("Expected 4 nodes and 3 edges, got %d and %d", n, m)
+	}
+	if _, err := LoadSNAP(strings.NewReader("0 1 2\n"), true); err == nil {
+		t.Error("Expected an error for a line with three fields")
+	}
+}
+
+const testMap = `type octile
+height 4
+width 5
+map
+..@..
+.@...
+.....
+T.G.S
+`
+
+func TestLoadMovingAIMap(t *testing.T) {
+	g, width, height, err := LoadMovingAIMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != 5 || height != 4 {
+		t.Fatalf("Expected a 5x4 map, got %dx%d", width, height)
+	}
+	if n := len(g.NodeList()); n != 17 {
+		t.Errorf("Expected 17 passable tiles, got %d", n)
+	}
+
+	tile := func(x, y int) graph.Node { return graph.GonumNode(y*width + x) }
+	// (0,1) to (1,2) is diagonal past the wall at (1,1), so it's cut off, but (3,1) to (4,0) cuts no walls
+	if g.IsSuccessor(tile(0, 1), tile(1, 2)) {
+		t.Error("A diagonal move cutting a wall's corner should be disallowed")
+	}
+	if !g.IsSuccessor(tile(3, 1), tile(4, 0)) || g.Cost(tile(3, 1), tile(4, 0)) != math.Sqrt2 {
+		t.Error("Expected an open diagonal move costing √2")
+	}
+
+	scen := "version 1\n0\ttest.map\t5\t4\t0\t0\t4\t0\t6.82842712\n"
+	scenarios, err := LoadMovingAIScenarios(strings.NewReader(scen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Scenario{{Map: "test.map", Width: 5, Height: 4, GoalX: 4, Optimal: 6.82842712}}
+	if !reflect.DeepEqual(scenarios, want) {
+		t.Fatalf("Expected %+v, got %+v", want, scenarios)
+	}
+	q := scenarios[0].Query()
+	_, cost, _ := graph.AStar(q.Source, q.Target, g, nil, graph.OctileHeuristic(graph.NodeCoordinates, 1, math.Sqrt2))
+	if !graph.RelativeTolerance(1e-8)(cost, q.Optimal) {
+		t.Errorf("Expected the scenario's optimal cost %v, got %v", q.Optimal, cost)
+	}
+
+	for _, bad := range []string{"type octile\nheight 1\nwidth 2\nmap\n...\n", "type octile\nheight 2\nwidth 1\nmap\n.\n", "type hex\nheight 1\nwidth 1\nmap\n.\n", "map\n"} {
+		if _, _, _, err := LoadMovingAIMap(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error loading %q", bad)
+		}
+	}
+}
+
+func TestRandomQueries(t *testing.T) {
+	g, _, _, _ := LoadMovingAIMap(strings.NewReader(testMap))
+	a := RandomQueries(g, 10, rand.New(rand.NewSource(1)))
+	b := RandomQueries(g, 10, rand.New(rand.NewSource(1)))
+	if len(a) != 10 || !reflect.DeepEqual(a, b) {
+		t.Errorf("Expected the same 10 queries from the same seed, got %v and %v", a, b)
+	}
+	for _, q := range a {
+		if !g.NodeExists(q.Source) || !g.NodeExists(q.Target) || q.Optimal >= 0 {
+			t.Errorf("Unexpected query %+v", q)
+		}
+	}
+}
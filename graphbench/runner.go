@@ -0,0 +1,184 @@
+package graphbench
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gonum/graph"
+)
+
+// A search to benchmark, bound to the graph and costs it searches. Search answers a query as AStar does, with a nil path if there's none.
+type Algorithm struct {
+	Name   string
+	Search func(start, goal graph.Node) (path []graph.Node, cost float64, nodesExpanded int)
+}
+
+// AStar with the given costs, as for graph.AStar.
+func AStar(g graph.ImplicitGraph, Cost, HeuristicCost func(graph.Node, graph.Node) float64) Algorithm {
+	return Algorithm{"astar", func(start, goal graph.Node) ([]graph.Node, float64, int) {
+		return graph.AStar(start, goal, g, Cost, HeuristicCost)
+	}}
+}
+
+// A Planner's AStar, which reuses its search state from one query to the next.
+func Planner(g graph.ImplicitGraph, Cost, HeuristicCost func(graph.Node, graph.Node) float64) Algorithm {
+	p := graph.NewPlanner(g, Cost, HeuristicCost)
+	return Algorithm{"planner", p.AStar}
+}
+
+// Dijkstra's algorithm, as graph.UniformCostSearch.
+func Dijkstra(g graph.ImplicitGraph, Cost func(graph.Node, graph.Node) float64) Algorithm {
+	return Algorithm{"dijkstra", func(start, goal graph.Node) ([]graph.Node, float64, int) {
+		return graph.UniformCostSearch(start, goal, g, Cost)
+	}}
+}
+
+// Greedy best-first search, which is fast but finds paths that are often far from the shortest: a baseline for what a heuristic alone achieves.
+func GreedyBestFirst(g graph.ImplicitGraph, Cost, HeuristicCost func(graph.Node, graph.Node) float64) Algorithm {
+	return Algorithm{"greedy", func(start, goal graph.Node) ([]graph.Node, float64, int) {
+		return graph.GreedyBestFirst(start, goal, g, Cost, HeuristicCost)
+	}}
+}
+
+// How one algorithm did on one query, the Index'th of those it was given. Time is the fastest of the runs.
+type Result struct {
+	Algorithm string
+	Index     int
+	Query     Query
+	Found     bool
+	Cost      float64
+	Expanded  int
+	Time      time.Duration
+}
+
+// Whether a path was found that costs more than the query's known optimum, or none was found for a query known to have one; never true if the optimum isn't known. A nil
+// tolerance is graph.DefaultTolerance, which Moving AI's optimal costs, given to 8 decimal places, are within.
+func (r Result) Suboptimal(tol graph.Tolerance) bool {
+	if r.Query.Optimal < 0 {
+		return false
+	}
+	if !r.Found {
+		return true
+	}
+	if tol == nil {
+		tol = graph.DefaultTolerance
+	}
+
+	return r.Cost > r.Query.Optimal && !tol(r.Cost, r.Query.Optimal)
+}
+
+// Runs every algorithm on every query, each runs times, keeping the fastest of the runs to keep noise from the rest of the machine out of the timings; runs below 1 are 1. Results
+// are in query order, and in the order of algorithms within a query. Searches run one at a time, so each has the machine to itself.
+func Compare(algorithms []Algorithm, queries []Query, runs int) []Result {
+	if runs < 1 {
+		runs = 1
+	}
+
+	results := make([]Result, 0, len(algorithms)*len(queries))
+	for i, query := range queries {
+		for _, algorithm := range algorithms {
+			result := Result{Algorithm: algorithm.Name, Index: i, Query: query}
+			for run := 0; run < runs; run++ {
+				began := time.Now()
+				path, cost, expanded := algorithm.Search(query.Source, query.Target)
+				elapsed := time.Since(began)
+				if run == 0 || elapsed < result.Time {
+					result.Time = elapsed
+				}
+				result.Found, result.Cost, result.Expanded = path != nil, cost, expanded
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// Writes results as CSV, a row per result after a header: algorithm, query, source, target, found, cost, optimal, suboptimal, expanded and nanoseconds. The cost is blank if no
+// path was found, and the optimal cost if it isn't known. tol is as for Result.Suboptimal.
+func WriteCSV(w io.Writer, results []Result, tol graph.Tolerance) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"algorithm", "query", "source", "target", "found", "cost", "optimal", "suboptimal", "expanded", "nanoseconds"})
+	for _, r := range results {
+		cw.Write([]string{
+			r.Algorithm,
+			strconv.Itoa(r.Index),
+			strconv.Itoa(r.Query.Source.ID()),
+			strconv.Itoa(r.Query.Target.ID()),
+			strconv.FormatBool(r.Found),
+			formatCost(r.Cost, r.Found),
+			formatCost(r.Query.Optimal, r.Query.Optimal >= 0),
+			strconv.FormatBool(r.Suboptimal(tol)),
+			strconv.Itoa(r.Expanded),
+			strconv.FormatInt(int64(r.Time), 10),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// The totals of one algorithm's results.
+type Summary struct {
+	Algorithm  string
+	Queries    int
+	Found      int
+	Suboptimal int
+	Expanded   int
+	Time       time.Duration
+}
+
+// Totals results by algorithm, in the order the algorithms first appear. tol is as for Result.Suboptimal.
+func Summarize(results []Result, tol graph.Tolerance) []Summary {
+	var summaries []Summary
+	index := make(map[string]int)
+	for _, r := range results {
+		i, ok := index[r.Algorithm]
+		if !ok {
+			i = len(summaries)
+			index[r.Algorithm] = i
+			summaries = append(summaries, Summary{Algorithm: r.Algorithm})
+		}
+
+		s := &summaries[i]
+		s.Queries++
+		if r.Found {
+			s.Found++
+		}
+		if r.Suboptimal(tol) {
+			s.Suboptimal++
+		}
+		s.Expanded += r.Expanded
+		s.Time += r.Time
+	}
+
+	return summaries
+}
+
+// Writes summaries as CSV, a row per algorithm after a header: algorithm, queries, found, suboptimal, expanded and nanoseconds, each a total over the queries.
+func WriteSummaryCSV(w io.Writer, summaries []Summary) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"algorithm", "queries", "found", "suboptimal", "expanded", "nanoseconds"})
+	for _, s := range summaries {
+		cw.Write([]string{
+			s.Algorithm,
+			strconv.Itoa(s.Queries),
+			strconv.Itoa(s.Found),
+			strconv.Itoa(s.Suboptimal),
+			strconv.Itoa(s.Expanded),
+			strconv.FormatInt(int64(s.Time), 10),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCost(cost float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return strconv.FormatFloat(cost, 'g', -1, 64)
+}
@@ -0,0 +1,90 @@
+package graphbench
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestCompare(t *testing.T) {
+	g, _, _, err := LoadMovingAIMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := graph.OctileHeuristic(graph.NodeCoordinates, 1, math.Sqrt2)
+	algorithms := []Algorithm{AStar(g, nil, h), Planner(g, nil, h), Dijkstra(g, nil), GreedyBestFirst(g, nil, h)}
+	scenarios := []Scenario{
+		{Width: 5, Height: 4, GoalX: 4, Optimal: 4 + 2*math.Sqrt2},
+		{Width: 5, Height: 4, StartX: 4, StartY: 3, GoalX: 1, GoalY: 3, Optimal: 3},
+		// Wrong on purpose, so every algorithm is suboptimal
+		{Width: 5, Height: 4, GoalX: 1, Optimal: 0.5},
+	}
+	var queries []Query
+	for _, s := range scenarios {
+		queries = append(queries, s.Query())
+	}
+
+	results := Compare(algorithms, queries, 3)
+	if len(results) != len(algorithms)*len(queries) {
+		t.Fatalf("Expected %d results, got %d", len(algorithms)*len(queries), len(results))
+	}
+	for i, r := range results {
+		if r.Index != i/len(algorithms) || r.Algorithm != algorithms[i%len(algorithms)].Name {
+			t.Errorf("Result %d is for %s on query %d", i, r.Algorithm, r.Index)
+		}
+		if !r.Found || r.Expanded == 0 {
+			t.Errorf("%s didn't find a path for query %d", r.Algorithm, r.Index)
+		}
+		if r.Algorithm != "greedy" && r.Suboptimal(nil) != (r.Index == 2) {
+			t.Errorf("%s on query %d cost %v, expected %v", r.Algorithm, r.Index, r.Cost, r.Query.Optimal)
+		}
+	}
+
+	summaries := Summarize(results, nil)
+	if len(summaries) != len(algorithms) {
+		t.Fatalf("Expected a summary per algorithm, got %+v", summaries)
+	}
+	for i, s := range summaries {
+		if s.Algorithm != algorithms[i].Name || s.Queries != 3 || s.Found != 3 {
+			t.Errorf("Unexpected summary %+v", s)
+		}
+		if s.Algorithm == "astar" && s.Suboptimal != 1 {
+			t.Errorf("Expected A* to be suboptimal only on the wrong query, got %+v", s)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, results, nil); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(results)+1 || rows[0][0] != "algorithm" || rows[1][0] != "astar" || rows[1][7] != "false" {
+		t.Errorf("Unexpected CSV %v", rows)
+	}
+
+	buf.Reset()
+	if err := WriteSummaryCSV(&buf, summaries); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != len(summaries)+1 {
+		t.Errorf("Expected %d lines of summary, got %q", len(summaries)+1, buf.String())
+	}
+}
+
+func TestUnknownOptimal(t *testing.T) {
+	r := Result{Query: Query{Optimal: -1}}
+	if r.Suboptimal(nil) {
+		t.Error("A result can't be suboptimal if the optimum isn't known")
+	}
+	r.Query.Optimal = 3
+	if !r.Suboptimal(nil) {
+		t.Error("Finding no path where there's known to be one should count as suboptimal")
+	}
+}
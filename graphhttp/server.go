@@ -0,0 +1,356 @@
+// Package graphhttp serves a graph over HTTP, answering shortest path, k shortest paths and centrality queries with JSON, so a graph can be deployed as a service without
+// writing the same wrapper each time. Nodes are named by ID everywhere. The endpoints, all GET, are:
+//
+//	/health                              {"status": "ok"}
+//	/stats                               {"nodes": 5, "edges": 7, "directed": true}
+//	/path?from=1&to=4                    {"path": [1, 3, 4], "cost": 2.5, "expanded": 4}
+//	/paths?from=1&to=4&k=3               {"paths": [{"path": [1, 3, 4], "cost": 2.5}, ...]}
+//	/centrality                          {"centralities": ["degree", ...]}
+//	/centrality/degree?top=10            {"name": "degree", "scores": [{"node": 3, "score": 4}, ...]}
+//	/centrality/degree?node=3            {"name": "degree", "scores": [{"node": 3, "score": 4}]}
+//
+// Errors come back as {"error": "..."} with a 400 for a bad request, 404 for an unknown node or centrality or when there's no path, and 405 for a method other than GET.
+package graphhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/metrics"
+)
+
+// The most paths /paths returns unless told otherwise, since each takes a shortest path search per node of the one before.
+const DefaultMaxK = 100
+
+// A Server is an http.Handler serving a graph. The graph mustn't be modified while it's being served; to change it, stop serving it, or otherwise make sure no requests are
+// being handled, make the changes, and call Reload before serving again.
+type Server struct {
+	// The most paths a /paths request may ask for.
+	MaxK int
+
+	graph           graph.Graph
+	cost, heuristic func(graph.Node, graph.Node) float64
+	mux             *http.ServeMux
+	metrics         *metrics.Set
+	expansions      *metrics.Counter
+
+	mu           sync.Mutex
+	nodes        map[int]graph.Node
+	edges        int
+	centralities map[string]func(graph.Graph) map[int]float64
+	scores       map[string][]Score // Each centrality's scores, computed on first request, highest first
+}
+
+// A node's score in a centrality.
+type Score struct {
+	Node  int     `json:"node"`
+	Score float64 `json:"score"`
+}
+
+// Creates a Server for g. Cost and HeuristicCost are used for path queries as they are by AStar, with the same defaults. Degree centrality is served as "degree", and more can
+// be added with AddCentrality.
+func NewServer(g graph.Graph, Cost, HeuristicCost func(graph.Node, graph.Node) float64) *Server {
+	s := &Server{
+		MaxK:         DefaultMaxK,
+		graph:        g,
+		cost:         Cost,
+		heuristic:    HeuristicCost,
+		mux:          http.NewServeMux(),
+		centralities: make(map[string]func(graph.Graph) map[int]float64),
+	}
+	s.AddCentrality("degree", func(g graph.Graph) map[int]float64 {
+		scores := make(map[int]float64)
+		for _, node := range g.NodeList() {
+			scores[node.ID()] = float64(g.Degree(node))
+		}
+		return scores
+	})
+	s.Reload()
+
+	s.mux.HandleFunc("/health", s.health)
+	s.mux.HandleFunc("/stats", s.stats)
+	s.mux.HandleFunc("/path", s.path)
+	s.mux.HandleFunc("/paths", s.paths)
+	s.mux.HandleFunc("/centrality", s.centralityNames)
+	s.mux.HandleFunc("/centrality/", s.centrality)
+	return s
+}
+
+// Serves a centrality under name, computed by fn the first time it's asked for, and then kept until Reload. fn returns each node's score by ID, as the centrality functions of
+// package graph do.
+func (s *Server) AddCentrality(name string, fn func(graph.Graph) map[int]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.centralities[name] = fn
+	delete(s.scores, name)
+}
+
+// Re-reads the graph's nodes and edges and forgets every centrality computed, after the graph has changed.
+func (s *Server) Reload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes = make(map[int]graph.Node)
+	for _, node := range s.graph.NodeList() {
+		s.nodes[node.ID()] = node
+	}
+	s.edges = 0
+	for _, edge := range s.graph.EdgeList() {
+		if s.graph.IsDirected() || edge.Head().ID() <= edge.Tail().ID() {
+			s.edges++
+		}
+	}
+	s.scores = make(map[string][]Score)
+}
+
+// Records the server's requests in set, which must be done before it starts serving. The metrics are:
+//
+//	graphhttp_requests_total{endpoint="/path",code="200"}   requests answered, by endpoint and status code
+//	graphhttp_request_seconds{endpoint="/path"}             a histogram of how long requests took to answer, by endpoint
+//	graphhttp_expansions_total                              nodes expanded by /path searches
+//
+// Endpoints are as the table in the package documentation has them, with every centrality counted under /centrality/, and anything else under "other".
+func (s *Server) Instrument(set *metrics.Set) {
+	s.metrics = set
+	s.expansions = set.Counter("graphhttp_expansions_total", "Nodes expanded by shortest path searches.")
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.metrics != nil {
+		began := time.Now()
+		_, endpoint := s.mux.Handler(r)
+		if endpoint == "" {
+			endpoint = "other"
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		w = recorder
+		defer func() {
+			s.metrics.Counter("graphhttp_requests_total", "HTTP requests answered.", "endpoint", endpoint, "code", strconv.Itoa(recorder.status)).Inc()
+			s.metrics.Histogram("graphhttp_request_seconds", "How long HTTP requests took to answer.", metrics.DefaultLatencyBuckets, "endpoint", endpoint).ObserveDuration(time.Since(began))
+		}()
+	}
+
+	if r.Method != "GET" && r.Method != "HEAD" {
+		writeError(w, http.StatusMethodNotAllowed, "Only GET is supported")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Remembers the status code written, for the metrics
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) stats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	nodes, edges := len(s.nodes), s.edges
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, struct {
+		Nodes    int  `json:"nodes"`
+		Edges    int  `json:"edges"`
+		Directed bool `json:"directed"`
+	}{nodes, edges, s.graph.IsDirected()})
+}
+
+func (s *Server) path(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.endpoints(w, r)
+	if !ok {
+		return
+	}
+
+	path, cost, expanded, err := graph.AStarCtx(r.Context(), from, to, s.graph, s.cost, s.heuristic)
+	if err != nil {
+		// The client has gone away
+		return
+	}
+	if s.expansions != nil {
+		s.expansions.Add(uint64(expanded))
+	}
+	if path == nil {
+		writeError(w, http.StatusNotFound, graph.ErrNoPath.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Path     []int   `json:"path"`
+		Cost     float64 `json:"cost"`
+		Expanded int     `json:"expanded"`
+	}{nodeIDs(path), cost, expanded})
+}
+
+func (s *Server) paths(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := s.endpoints(w, r)
+	if !ok {
+		return
+	}
+	k, err := strconv.Atoi(r.URL.Query().Get("k"))
+	if err != nil || k < 1 || k > s.MaxK {
+		writeError(w, http.StatusBadRequest, "k must be a whole number from 1 to "+strconv.Itoa(s.MaxK))
+		return
+	}
+
+	paths, costs, err := graph.KShortestPaths(from, to, s.graph, k, s.cost)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	type costedPath struct {
+		Path []int   `json:"path"`
+		Cost float64 `json:"cost"`
+	}
+	result := make([]costedPath, len(paths))
+	for i, path := range paths {
+		result[i] = costedPath{nodeIDs(path), costs[i]}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Paths []costedPath `json:"paths"`
+	}{result})
+}
+
+func (s *Server) centralityNames(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.centralities))
+	for name := range s.centralities {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, struct {
+		Centralities []string `json:"centralities"`
+	}{names})
+}
+
+func (s *Server) centrality(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/centrality/")
+	scores, ok := s.scoresOf(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, "No centrality named "+strconv.Quote(name))
+		return
+	}
+
+	query := r.URL.Query()
+	if id := query.Get("node"); id != "" {
+		node, ok := s.node(w, id)
+		if !ok {
+			return
+		}
+		found := []Score{}
+		for _, score := range scores {
+			if score.Node == node.ID() {
+				found = append(found, score)
+			}
+		}
+		scores = found
+	} else if top := query.Get("top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "top must be a whole number")
+			return
+		}
+		if n < len(scores) {
+			scores = scores[:n]
+		}
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Name   string  `json:"name"`
+		Scores []Score `json:"scores"`
+	}{name, scores})
+}
+
+// The scores of the named centrality, highest first, computing them if they haven't been. Computing holds the lock, so concurrent requests for a centrality compute it once.
+func (s *Server) scoresOf(name string) ([]Score, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scores, ok := s.scores[name]; ok {
+		return scores, true
+	}
+	fn, ok := s.centralities[name]
+	if !ok {
+		return nil, false
+	}
+
+	var scores []Score
+	for id, score := range fn(s.graph) {
+		scores = append(scores, Score{id, score})
+	}
+	sort.Sort(byScore(scores))
+	s.scores[name] = scores
+	return scores, true
+}
+
+// The nodes named by the from and to parameters, writing an error if they're missing or not in the graph
+func (s *Server) endpoints(w http.ResponseWriter, r *http.Request) (from, to graph.Node, ok bool) {
+	query := r.URL.Query()
+	if from, ok = s.node(w, query.Get("from")); !ok {
+		return nil, nil, false
+	}
+	if to, ok = s.node(w, query.Get("to")); !ok {
+		return nil, nil, false
+	}
+	return from, to, true
+}
+
+// The node with the given ID, writing an error if it's malformed or not in the graph
+func (s *Server) node(w http.ResponseWriter, id string) (graph.Node, bool) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Node IDs must be integers, got "+strconv.Quote(id))
+		return nil, false
+	}
+	s.mu.Lock()
+	node, ok := s.nodes[n]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "No node "+id)
+		return nil, false
+	}
+	return node, true
+}
+
+func nodeIDs(nodes []graph.Node) []int {
+	ids := make([]int, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID()
+	}
+	return ids
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// Sorts scores highest first, then by node ID
+type byScore []Score
+
+func (s byScore) Len() int {
+	return len(s)
+}
+
+func (s byScore) Less(i, j int) bool {
+	return s[i].Score > s[j].Score || s[i].Score == s[j].Score && s[i].Node < s[j].Node
+}
+
+func (s byScore) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
@@ -0,0 +1,163 @@
+package graphhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/metrics"
+)
+
+// Serves a request, decoding the JSON response into v
+func get(t *testing.T, s *Server, method, url string, v interface{}) int {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, url, nil))
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s: can't decode %q: %v", url, w.Body.String(), err)
+	}
+	return w.Code
+}
+
+func TestServer(t *testing.T) {
+	// 0 -> 1 -> 3 costs 2, 0 -> 2 -> 3 costs 3, and 0 -> 3 costs 5. 4 is on its own
+	g := graph.NewGonumGraph(true)
+	for i := 0; i < 5; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for _, e := range []struct {
+		h, t int
+		cost float64
+	}{{0, 1, 1}, {1, 3, 1}, {0, 2, 1}, {2, 3, 2}, {0, 3, 5}} {
+		edge := graph.GonumEdge{H: graph.GonumNode(e.h), T: graph.GonumNode(e.t)}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, e.cost)
+	}
+	s := NewServer(g, nil, nil)
+
+	var health map[string]string
+	if code := get(t, s, "GET", "/health", &health); code != http.StatusOK || health["status"] != "ok" {
+		t.Errorf("Unexpected health %d %v", code, health)
+	}
+
+	var stats struct {
+		Nodes, Edges int
+		Directed     bool
+	}
+	if code := get(t, s, "GET", "/stats", &stats); code != http.StatusOK || stats.Nodes != 5 || stats.Edges != 5 || !stats.Directed {
+		t.Errorf("Unexpected stats %d %+v", code, stats)
+	}
+
+	var path struct {
+		Path []int
+		Cost float64
+	}
+	if code := get(t, s, "GET", "/path?from=0&to=3", &path); code != http.StatusOK || !reflect.DeepEqual(path.Path, []int{0, 1, 3}) || path.Cost != 2 {
+		t.Errorf("Unexpected path %d %+v", code, path)
+	}
+
+	var paths struct {
+		Paths []struct {
+			Path []int
+			Cost float64
+		}
+	}
+	if code := get(t, s, "GET", "/paths?from=0&to=3&k=5", &paths); code != http.StatusOK || len(paths.Paths) != 3 {
+		t.Errorf("Unexpected paths %d %+v", code, paths)
+	} else {
+		for i, cost := range []float64{2, 3, 5} {
+			if paths.Paths[i].Cost != cost {
+				t.Errorf("Expected path %d to cost %v, got %+v", i, cost, paths.Paths[i])
+			}
+		}
+	}
+
+	var centrality struct {
+		Name   string
+		Scores []Score
+	}
+	if code := get(t, s, "GET", "/centrality/degree?top=2", &centrality); code != http.StatusOK || !reflect.DeepEqual(centrality.Scores, []Score{{0, 3}, {3, 3}}) {
+		t.Errorf("Unexpected centrality %d %+v", code, centrality)
+	}
+	if code := get(t, s, "GET", "/centrality/degree?node=4", &centrality); code != http.StatusOK || !reflect.DeepEqual(centrality.Scores, []Score{{4, 0}}) {
+		t.Errorf("Unexpected centrality %d %+v", code, centrality)
+	}
+
+	calls := 0
+	s.AddCentrality("id", func(g graph.Graph) map[int]float64 {
+		calls++
+		scores := make(map[int]float64)
+		for _, node := range g.NodeList() {
+			scores[node.ID()] = float64(node.ID())
+		}
+		return scores
+	})
+	var names struct{ Centralities []string }
+	if code := get(t, s, "GET", "/centrality", &names); code != http.StatusOK || !reflect.DeepEqual(names.Centralities, []string{"degree", "id"}) {
+		t.Errorf("Unexpected centralities %d %+v", code, names)
+	}
+	get(t, s, "GET", "/centrality/id", &centrality)
+	get(t, s, "GET", "/centrality/id?top=1", &centrality)
+	if calls != 1 || !reflect.DeepEqual(centrality.Scores, []Score{{4, 4}}) {
+		t.Errorf("Expected the id centrality computed once, topped by 4, got %d calls and %+v", calls, centrality)
+	}
+
+	errors := []struct {
+		method, url string
+		code        int
+	}{
+		{"GET", "/path?from=0&to=4", http.StatusNotFound},
+		{"GET", "/path?from=0&to=9", http.StatusNotFound},
+		{"GET", "/path?from=x&to=1", http.StatusBadRequest},
+		{"GET", "/paths?from=0&to=3", http.StatusBadRequest},
+		{"GET", "/paths?from=0&to=3&k=1000", http.StatusBadRequest},
+		{"GET", "/paths?from=4&to=3&k=2", http.StatusNotFound},
+		{"GET", "/centrality/nope", http.StatusNotFound},
+		{"GET", "/centrality/degree?top=-1", http.StatusBadRequest},
+		{"POST", "/health", http.StatusMethodNotAllowed},
+	}
+	for _, test := range errors {
+		var body map[string]string
+		if code := get(t, s, test.method, test.url, &body); code != test.code || body["error"] == "" {
+			t.Errorf("%s %s: expected a %d error, got %d %v", test.method, test.url, test.code, code, body)
+		}
+	}
+
+	// New nodes are only served after a reload
+	g.AddNode(graph.GonumNode(5), []graph.Node{graph.GonumNode(4)})
+	if code := get(t, s, "GET", "/path?from=5&to=4", &path); code != http.StatusNotFound {
+		t.Errorf("Expected node 5 to be unknown before reloading, got %d", code)
+	}
+	s.Reload()
+	if code := get(t, s, "GET", "/path?from=5&to=4", &path); code != http.StatusOK || !reflect.DeepEqual(path.Path, []int{5, 4}) {
+		t.Errorf("Unexpected path %d %+v after reloading", code, path)
+	}
+}
+
+func TestServerInstrument(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	g.AddNode(graph.GonumNode(0), []graph.Node{graph.GonumNode(1)})
+	s := NewServer(g, nil, nil)
+	set := metrics.NewSet("")
+	s.Instrument(set)
+
+	var v interface{}
+	get(t, s, "GET", "/path?from=0&to=1", &v)
+	get(t, s, "GET", "/path?from=1&to=0", &v)
+	get(t, s, "GET", "/centrality/degree", &v)
+	s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nowhere", nil))
+
+	for _, c := range []struct {
+		endpoint, code string
+		want           uint64
+	}{{"/path", "200", 1}, {"/path", "404", 1}, {"/centrality/", "200", 1}, {"other", "404", 1}} {
+		if n := set.Counter("graphhttp_requests_total", "", "endpoint", c.endpoint, "code", c.code).Value(); n != c.want {
+			t.Errorf("Expected %d %s requests to %s, got %d", c.want, c.code, c.endpoint, n)
+		}
+	}
+	if n := set.Counter("graphhttp_expansions_total", "").Value(); n == 0 {
+		t.Error("Expected the searches' expansions to be counted")
+	}
+}
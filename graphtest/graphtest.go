@@ -0,0 +1,202 @@
+// Package graphtest checks invariants of the graph package's algorithms, and of Graph implementations, on randomly generated graphs: a Generator makes the graphs, Properties
+// say what must hold of each, and Check tries one against the other for as many cases as asked, shrinking any graph a property fails on down to a small counterexample.
+//
+// Every case is reproducible from its seed, which a failure reports, so a failure found once can be turned into a regression test by checking the same seed again. To check
+// a Graph implementation of your own, pass Check a convert function that builds one from each generated graph: the properties then run on yours.
+package graphtest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/gonum/graph"
+)
+
+// Makes a random graph using src, and only src, so the same seed makes the same graph.
+type Generator func(src *rand.Rand) *graph.GonumGraph
+
+// An invariant that must hold of every graph. Check returns why it doesn't hold of g, or nil if it does; src is for choosing queries and the like, seeded the same way
+// whenever the case is rerun.
+type Property struct {
+	Name  string
+	Check func(g graph.Graph, src *rand.Rand) error
+}
+
+// A counterexample to a property: the case with seed Seed, whose graph shrunk as far as it could be while the property still fails on it is Graph.
+type Failure struct {
+	Property string
+	Seed     int64
+	Graph    *graph.GonumGraph
+	Err      error
+}
+
+// Describes the failure, listing the shrunk graph's edges.
+func (f *Failure) Error() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s failed on the case with seed %d: %v\n", f.Property, f.Seed, f.Err)
+	fmt.Fprintf(&buf, "shrunk to %d nodes, directed: %t", len(f.Graph.NodeList()), f.Graph.IsDirected())
+	for _, node := range sortedNodes(f.Graph.NodeList()) {
+		succs := sortedNodes(f.Graph.Successors(node))
+		if len(succs) == 0 && len(f.Graph.Predecessors(node)) == 0 {
+			fmt.Fprintf(&buf, "\n\t%d", node.ID())
+		}
+		for _, succ := range succs {
+			if f.Graph.IsDirected() || node.ID() <= succ.ID() {
+				fmt.Fprintf(&buf, "\n\t%d -> %d costs %v", node.ID(), succ.ID(), f.Graph.Cost(node, succ))
+			}
+		}
+	}
+	return buf.String()
+}
+
+// Checks every property on cases graphs from gen, the i'th made from seed+i, returning a *Failure for the first case a property fails on, or nil if they all hold. If convert
+// isn't nil, the properties are checked on convert's version of each graph instead of the generated one itself. A property that panics fails.
+func Check(gen Generator, convert func(*graph.GonumGraph) graph.Graph, props []Property, cases int, seed int64) error {
+	if convert == nil {
+		convert = func(g *graph.GonumGraph) graph.Graph {
+			return g
+		}
+	}
+
+	for i := 0; i < cases; i++ {
+		caseSeed := seed + int64(i)
+		g := gen(rand.New(rand.NewSource(caseSeed)))
+		for _, prop := range props {
+			if err := checkCase(prop, convert, g, caseSeed); err != nil {
+				g, err = shrink(prop, convert, g, caseSeed, err)
+				return &Failure{Property: prop.Name, Seed: caseSeed, Graph: g, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkCase(prop Property, convert func(*graph.GonumGraph) graph.Graph, g *graph.GonumGraph, seed int64) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return prop.Check(convert(copyGraph(g)), rand.New(rand.NewSource(seed)))
+}
+
+// Removes nodes, then edges, one at a time from a graph prop fails on, keeping each removal the property still fails after, until no single removal keeps it failing. Returns
+// the smallest graph and the error the property gave for it.
+func shrink(prop Property, convert func(*graph.GonumGraph) graph.Graph, g *graph.GonumGraph, seed int64, err error) (*graph.GonumGraph, error) {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, node := range sortedNodes(g.NodeList()) {
+			smaller := copyGraph(g)
+			smaller.RemoveNode(node)
+			if e := checkCase(prop, convert, smaller, seed); e != nil {
+				g, err, shrunk = smaller, e, true
+			}
+		}
+		for _, edge := range sortedEdges(g) {
+			smaller := copyGraph(g)
+			smaller.RemoveEdge(edge)
+			if e := checkCase(prop, convert, smaller, seed); e != nil {
+				g, err, shrunk = smaller, e, true
+			}
+		}
+	}
+
+	return g, err
+}
+
+// Makes n nodes, 0 to n-1, joined by m distinct edges chosen uniformly at random, as GnmRandomGraph does, with costs drawn uniformly from [minCost, maxCost).
+func Weighted(n, m int, directed bool, minCost, maxCost float64) Generator {
+	return func(src *rand.Rand) *graph.GonumGraph {
+		g := graph.NewGonumGraph(directed)
+		graph.GnmRandomGraph(g, n, m, directed, src)
+		for _, edge := range sortedEdges(g) {
+			g.SetEdgeCost(edge, minCost+src.Float64()*(maxCost-minCost))
+		}
+		return g
+	}
+}
+
+// Makes an undirected rows by cols grid of PointNodes, with x the column and y the row, each tile a wall with probability walls and left out of the graph if it is, and the rest
+// joined to the tiles above, below and to either side of them. Costs are drawn uniformly from [1, maxCost), so ManhattanHeuristic(graph.NodeCoordinates, 1) is admissible.
+func Grid(rows, cols int, walls, maxCost float64) Generator {
+	return func(src *rand.Rand) *graph.GonumGraph {
+		g := graph.NewGonumGraph(false)
+		open := make([]bool, rows*cols)
+		tile := func(row, col int) graph.Node {
+			return graph.PointNode{Id: row*cols + col, X: float64(col), Y: float64(row)}
+		}
+		for i := range open {
+			if open[i] = src.Float64() >= walls; open[i] {
+				g.AddNode(tile(i/cols, i%cols), nil)
+			}
+		}
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				if !open[row*cols+col] {
+					continue
+				}
+				if col+1 < cols && open[row*cols+col+1] {
+					edge := graph.GonumEdge{H: tile(row, col), T: tile(row, col+1)}
+					g.AddEdge(edge)
+					g.SetEdgeCost(edge, 1+src.Float64()*(maxCost-1))
+				}
+				if row+1 < rows && open[(row+1)*cols+col] {
+					edge := graph.GonumEdge{H: tile(row, col), T: tile(row+1, col)}
+					g.AddEdge(edge)
+					g.SetEdgeCost(edge, 1+src.Float64()*(maxCost-1))
+				}
+			}
+		}
+		return g
+	}
+}
+
+func copyGraph(g *graph.GonumGraph) *graph.GonumGraph {
+	c := graph.NewGonumGraph(g.IsDirected())
+	for _, node := range g.NodeList() {
+		c.AddNode(node, nil)
+	}
+	for _, edge := range g.EdgeList() {
+		c.AddEdge(edge)
+	}
+	for _, edge := range g.EdgeList() {
+		c.SetEdgeCost(edge, g.Cost(edge.Head(), edge.Tail()))
+	}
+	return c
+}
+
+// The graph's edges in order of their ends' IDs, each undirected edge once.
+func sortedEdges(g graph.Graph) []graph.Edge {
+	var edges []graph.Edge
+	for _, node := range sortedNodes(g.NodeList()) {
+		for _, succ := range sortedNodes(g.Successors(node)) {
+			if g.IsDirected() || node.ID() <= succ.ID() {
+				edges = append(edges, graph.GonumEdge{H: node, T: succ})
+			}
+		}
+	}
+	return edges
+}
+
+func sortedNodes(nodes []graph.Node) []graph.Node {
+	nodes = append([]graph.Node(nil), nodes...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+type byID []graph.Node
+
+func (nodes byID) Len() int {
+	return len(nodes)
+}
+
+func (nodes byID) Less(i, j int) bool {
+	return nodes[i].ID() < nodes[j].ID()
+}
+
+func (nodes byID) Swap(i, j int) {
+	nodes[i], nodes[j] = nodes[j], nodes[i]
+}
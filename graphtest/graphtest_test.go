@@ -0,0 +1,102 @@
+package graphtest
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestPropertiesHold(t *testing.T) {
+	props := []Property{Consistent(), AStarMatchesDijkstra(5, nil), DStarMatchesAStar(10, 3)}
+	manhattan := AStarMatchesDijkstra(5, func(graph.Graph) func(graph.Node, graph.Node) float64 {
+		return graph.ManhattanHeuristic(graph.NodeCoordinates, 1)
+	})
+	for _, test := range []struct {
+		name  string
+		gen   Generator
+		props []Property
+	}{
+		{"directed", Weighted(12, 30, true, 1, 10), props},
+		{"undirected", Weighted(12, 20, false, 0, 5), props},
+		{"grid", Grid(6, 6, 0.25, 4), append(props, manhattan)},
+	} {
+		if err := Check(test.gen, nil, test.props, 20, 1); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestGeneratorsAreReproducible(t *testing.T) {
+	for _, gen := range []Generator{Weighted(10, 15, true, 1, 2), Grid(4, 5, 0.3, 3)} {
+		a, b := gen(rand.New(rand.NewSource(7))), gen(rand.New(rand.NewSource(7)))
+		if (&Failure{Graph: a}).Error() != (&Failure{Graph: b}).Error() {
+			t.Errorf("The same seed made different graphs:\n%v\n%v", a, b)
+		}
+	}
+}
+
+func TestFailureShrinks(t *testing.T) {
+	// Wrong whenever there's any path of more than one edge
+	shortPaths := Property{"ShortPaths", func(g graph.Graph, src *rand.Rand) error {
+		for _, a := range g.NodeList() {
+			for _, b := range g.Successors(a) {
+				if len(g.Successors(b)) > 0 && g.IsDirected() {
+					return errFound
+				}
+			}
+		}
+		return nil
+	}}
+	err := Check(Weighted(10, 30, true, 1, 2), nil, []Property{Consistent(), shortPaths}, 10, 3)
+	f, ok := err.(*Failure)
+	if !ok {
+		t.Fatalf("Expected a Failure, got %v", err)
+	}
+	if f.Property != "ShortPaths" || f.Seed != 3 || f.Err != errFound {
+		t.Errorf("Unexpected failure %+v", f)
+	}
+	if n, m := len(f.Graph.NodeList()), len(f.Graph.EdgeList()); n != 3 || m != 2 {
+		t.Errorf("Expected the counterexample to shrink to a path of 2 edges, got %d nodes and %d edges:\n%v", n, m, f)
+	}
+	if !strings.Contains(f.Error(), "seed 3") {
+		t.Errorf("Expected the seed in the message, got %q", f.Error())
+	}
+}
+
+var errFound = errors.New("path of more than one edge")
+
+// A graph whose Successors forgets the last successor of every node
+type forgetful struct {
+	*graph.GonumGraph
+}
+
+func (g forgetful) Successors(node graph.Node) []graph.Node {
+	succs := sortedNodes(g.GonumGraph.Successors(node))
+	if len(succs) == 0 {
+		return nil
+	}
+	return succs[:len(succs)-1]
+}
+
+func TestCheckConverted(t *testing.T) {
+	convert := func(g *graph.GonumGraph) graph.Graph {
+		return forgetful{g}
+	}
+	err := Check(Weighted(8, 20, true, 1, 2), convert, []Property{Consistent()}, 5, 1)
+	if f, ok := err.(*Failure); !ok || f.Property != "Consistent" || len(f.Graph.EdgeList()) != 1 {
+		t.Errorf("Expected an inconsistency shrunk to one edge, got %v", err)
+	}
+
+	panics := Property{"Panics", func(g graph.Graph, src *rand.Rand) error {
+		if len(g.NodeList()) > 0 {
+			panic("oops")
+		}
+		return nil
+	}}
+	if err := Check(Weighted(3, 2, false, 1, 2), nil, []Property{panics}, 1, 1); err == nil || !strings.Contains(err.Error(), "panic: oops") {
+		t.Errorf("Expected a panic to fail the property, got %v", err)
+	}
+}
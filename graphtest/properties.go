@@ -0,0 +1,161 @@
+package graphtest
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/gonum/graph"
+)
+
+// The graph is internally consistent, as graph.Validate checks: it has no errors, though it may have warnings, such as self loops, which are legal.
+func Consistent() Property {
+	return Property{"Consistent", func(g graph.Graph, src *rand.Rand) error {
+		if errs := graph.Validate(g).Errors(); len(errs) != 0 {
+			return errors.New(errs[0].Message)
+		}
+		return nil
+	}}
+}
+
+// For queries pairs of nodes picked at random, search finds a path exactly when UniformCostSearch does, which is a real path through the graph costing what search says it does,
+// and as little as UniformCostSearch's, as judged by graph.DefaultTolerance. Costs are the graph's, as Coster gives them, or 1 if it isn't one.
+func SearchMatchesDijkstra(name string, queries int, search func(g graph.Graph, start, goal graph.Node) (path []graph.Node, cost float64)) Property {
+	return Property{name + "MatchesDijkstra", func(g graph.Graph, src *rand.Rand) error {
+		nodes := sortedNodes(g.NodeList())
+		if len(nodes) == 0 {
+			return nil
+		}
+		cost := costOf(g)
+		for i := 0; i < queries; i++ {
+			start, goal := nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]
+			want, wantCost, _ := graph.UniformCostSearch(start, goal, g, cost)
+			path, got := search(g, start, goal)
+			if (path == nil) != (want == nil) {
+				return fmt.Errorf("from %d to %d, %s found %v where Dijkstra found %v", start.ID(), goal.ID(), name, ids(path), ids(want))
+			}
+			if path == nil {
+				continue
+			}
+			if err := checkPath(g, cost, path, start, goal, got); err != nil {
+				return fmt.Errorf("%s's path %v: %v", name, ids(path), err)
+			}
+			if !graph.DefaultTolerance(got, wantCost) {
+				return fmt.Errorf("from %d to %d, %s's path %v costs %v where Dijkstra's %v costs %v", start.ID(), goal.ID(), name, ids(path), got, ids(want), wantCost)
+			}
+		}
+		return nil
+	}}
+}
+
+// A* finds shortest paths, as SearchMatchesDijkstra checks, with the heuristic heuristic gives for the graph, which must be admissible for A* to be right. A nil heuristic
+// gives graph.Landmarks' with 4 landmarks, which is always admissible.
+func AStarMatchesDijkstra(queries int, heuristic func(graph.Graph) func(graph.Node, graph.Node) float64) Property {
+	return SearchMatchesDijkstra("AStar", queries, func(g graph.Graph, start, goal graph.Node) ([]graph.Node, float64) {
+		var h func(graph.Node, graph.Node) float64
+		if heuristic != nil {
+			h = heuristic(g)
+		} else {
+			h = graph.NewLandmarks(g, nil, 4, rand.New(rand.NewSource(int64(start.ID())))).HeuristicCost
+		}
+		path, cost, _ := graph.AStar(start, goal, g, nil, h)
+		return path, cost
+	})
+}
+
+// D*-Lite keeps its plan optimal as costs change: an agent is sent from a random start to a random goal, and for up to rounds rounds it checks that the plan from where the
+// agent is costs what a fresh A* search on the changed costs does, moves the agent along the plan one step, and then changes the costs of changes random edges to between half
+// and three and a half times the graph's own, reporting them to D*-Lite. In an undirected graph, a change applies both ways.
+func DStarMatchesAStar(rounds, changes int) Property {
+	return Property{"DStarMatchesAStar", func(g graph.Graph, src *rand.Rand) error {
+		nodes := sortedNodes(g.NodeList())
+		edges := sortedEdges(g)
+		if len(nodes) == 0 {
+			return nil
+		}
+		baseCost := costOf(g)
+		overrides := make(map[[2]int]float64)
+		cost := func(a, b graph.Node) float64 {
+			if c, ok := overrides[[2]int{a.ID(), b.ID()}]; ok {
+				return c
+			}
+			return baseCost(a, b)
+		}
+
+		position, goal := nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]
+		ds := graph.InitDStar(position, goal, g, cost, nil)
+		for round := 0; round < rounds; round++ {
+			want, wantCost, _ := graph.AStar(position, goal, g, cost, nil)
+			if position.ID() == goal.ID() {
+				return nil
+			}
+			plan := ds.PlanAhead()
+			if want == nil {
+				if len(plan) != 0 && plan[len(plan)-1].ID() == goal.ID() {
+					return fmt.Errorf("round %d: D*-Lite planned %v from %d to %d where A* found no path", round, ids(plan), position.ID(), goal.ID())
+				}
+				return nil
+			}
+			path := append([]graph.Node{position}, plan...)
+			if err := checkPath(g, cost, path, position, goal, wantCost); err != nil {
+				return fmt.Errorf("round %d: D*-Lite's plan %v against A*'s %v: %v", round, ids(path), ids(want), err)
+			}
+
+			next, err := ds.Step()
+			if err != nil {
+				return fmt.Errorf("round %d: D*-Lite couldn't step along its plan %v: %v", round, ids(plan), err)
+			}
+			position = next
+
+			var changed []graph.Edge
+			for i := 0; i < changes && len(edges) > 0; i++ {
+				edge := edges[src.Intn(len(edges))]
+				h, t := edge.Head(), edge.Tail()
+				c := baseCost(h, t) * (0.5 + 3*src.Float64())
+				overrides[[2]int{h.ID(), t.ID()}] = c
+				if !g.IsDirected() {
+					overrides[[2]int{t.ID(), h.ID()}] = c
+				}
+				changed = append(changed, edge)
+			}
+			ds.Update(cost, changed)
+		}
+		return nil
+	}}
+}
+
+// Checks path runs from start to goal along edges of g, costing want.
+func checkPath(g graph.Graph, cost func(graph.Node, graph.Node) float64, path []graph.Node, start, goal graph.Node, want float64) error {
+	if path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID() {
+		return fmt.Errorf("doesn't run from %d to %d", start.ID(), goal.ID())
+	}
+	total := 0.0
+	for i := 1; i < len(path); i++ {
+		if !g.IsSuccessor(path[i-1], path[i]) {
+			return fmt.Errorf("there's no edge from %d to %d", path[i-1].ID(), path[i].ID())
+		}
+		total += cost(path[i-1], path[i])
+	}
+	if !graph.DefaultTolerance(total, want) {
+		return fmt.Errorf("costs %v, expected %v", total, want)
+	}
+	return nil
+}
+
+func costOf(g graph.Graph) func(graph.Node, graph.Node) float64 {
+	if cgraph, ok := g.(graph.Coster); ok {
+		return cgraph.Cost
+	}
+	return graph.UniformCost
+}
+
+func ids(path []graph.Node) []int {
+	if path == nil {
+		return nil
+	}
+	ids := make([]int, len(path))
+	for i, node := range path {
+		ids[i] = node.ID()
+	}
+	return ids
+}
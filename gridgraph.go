@@ -0,0 +1,247 @@
+package graph
+
+import (
+	"math"
+)
+
+// A GridGraph is a 2D occupancy grid for D*-Lite: cells are nodes, free cells are joined to their free neighbors, and obstacles can be added and removed as the agent finds them,
+// with every edge that changes remembered until ChangedEdges hands them to the planner. It's a DStarGraph, a Coster and a HeuristicCoster, so D*-Lite needs nothing else:
+//
+//	grid := NewGridGraph(100, 100, true)
+//	goal := grid.CoordsToNode(99, 99)
+//	ds := InitDStar(grid.Position(), goal, grid, nil, nil)
+//	for grid.Position().ID() != goal.ID() {
+//	    next, err := ds.Step()
+//	    if err != nil { ... no way through ... }
+//	    grid.Move(next)
+//	    ... for each obstacle the sensors report, grid.SetObstacle(x, y) ...
+//	    ds.Update(grid.ChangedEdges())
+//	}
+//
+// A cell's ID is y*width + x. A 4-connected grid only has moves along the axes, costing 1 each; an 8-connected one also has diagonal moves, costing sqrt(2), but never cuts a
+// corner: a diagonal move needs both of the cells beside it to be free too.
+type GridGraph struct {
+	width, height int
+	diagonal      bool
+	blocked       []bool
+	position      Node
+	changed       []Edge
+	heuristic     func(Node, Node) float64
+}
+
+// Creates a width by height grid with every cell free, 8-connected if diagonal is true and 4-connected otherwise. The agent starts at the cell (0, 0).
+func NewGridGraph(width, height int, diagonal bool) *GridGraph {
+	grid := &GridGraph{width: width, height: height, diagonal: diagonal, blocked: make([]bool, width*height), position: GonumNode(0)}
+	if diagonal {
+		grid.heuristic = OctileHeuristic(grid.CellPosition, 1, math.Sqrt2)
+	} else {
+		grid.heuristic = ManhattanHeuristic(grid.CellPosition, 1)
+	}
+
+	return grid
+}
+
+func (grid *GridGraph) Dimensions() (width, height int) {
+	return grid.width, grid.height
+}
+
+// The node for the cell at (x, y), or nil if that's off the grid.
+func (grid *GridGraph) CoordsToNode(x, y int) Node {
+	if !grid.inside(x, y) {
+		return nil
+	}
+	return GonumNode(y*grid.width + x)
+}
+
+// The coordinates of the cell with the given ID, which is assumed to be on the grid.
+func (grid *GridGraph) IDToCoords(id int) (x, y int) {
+	return id % grid.width, id / grid.width
+}
+
+func (grid *GridGraph) inside(x, y int) bool {
+	return x >= 0 && x < grid.width && y >= 0 && y < grid.height
+}
+
+func (grid *GridGraph) free(x, y int) bool {
+	return grid.inside(x, y) && !grid.blocked[y*grid.width+x]
+}
+
+// Whether there's an obstacle at (x, y). Cells off the grid count as obstacles.
+func (grid *GridGraph) Obstacle(x, y int) bool {
+	return !grid.free(x, y)
+}
+
+// Puts an obstacle at (x, y), remembering the edges that go with it for ChangedEdges. Cells off the grid, and cells that already have an obstacle, are ignored.
+func (grid *GridGraph) SetObstacle(x, y int) {
+	grid.setBlocked(x, y, true)
+}
+
+// Removes the obstacle at (x, y), remembering the edges that come back for ChangedEdges. Cells off the grid, and free cells, are ignored.
+func (grid *GridGraph) ClearObstacle(x, y int) {
+	grid.setBlocked(x, y, false)
+}
+
+func (grid *GridGraph) setBlocked(x, y int, blocked bool) {
+	if !grid.inside(x, y) || grid.blocked[y*grid.width+x] == blocked {
+		return
+	}
+	grid.blocked[y*grid.width+x] = blocked
+
+	// Every move into or out of the cell changes, and on an 8-connected grid so does every diagonal move that squeezes past its corner
+	cell := grid.CoordsToNode(x, y)
+	for _, d := range grid.directions() {
+		if neighbor := grid.CoordsToNode(x+d[0], y+d[1]); neighbor != nil {
+			grid.changed = append(grid.changed, GonumEdge{H: cell, T: neighbor})
+		}
+	}
+	if grid.diagonal {
+		for _, d := range [][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+			a, b := grid.CoordsToNode(x+d[0], y), grid.CoordsToNode(x, y+d[1])
+			if a != nil && b != nil {
+				grid.changed = append(grid.changed, GonumEdge{H: a, T: b})
+			}
+		}
+	}
+}
+
+// The moves from a cell, straight ones first. Shared, so don't change them.
+var gridMoves = [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func (grid *GridGraph) directions() [][2]int {
+	if grid.diagonal {
+		return gridMoves
+	}
+	return gridMoves[:4]
+}
+
+// Returns the agent's current position.
+func (grid *GridGraph) Position() Node {
+	return grid.position
+}
+
+// Moves the agent to target. Seeing what's around it is up to the caller, who reports it with SetObstacle and ClearObstacle.
+func (grid *GridGraph) Move(target Node) {
+	grid.position = target
+}
+
+// Returns and forgets the edges changed by SetObstacle and ClearObstacle since the last call. The cost function is always nil, since a move's cost never changes, only whether
+// it can be made. The edges are as D*-Lite wants them for an undirected graph, each in one direction, and may repeat.
+func (grid *GridGraph) ChangedEdges() (newCostFunc func(Node, Node) float64, changedEdges []Edge) {
+	changedEdges, grid.changed = grid.changed, nil
+	return nil, changedEdges
+}
+
+func (grid *GridGraph) Successors(node Node) []Node {
+	id := node.ID()
+	if id < 0 || id >= len(grid.blocked) || grid.blocked[id] {
+		return nil
+	}
+
+	x, y := grid.IDToCoords(id)
+	succs := make([]Node, 0, len(grid.directions()))
+	for _, d := range grid.directions() {
+		if grid.canMove(x, y, d[0], d[1]) {
+			succs = append(succs, grid.CoordsToNode(x+d[0], y+d[1]))
+		}
+	}
+
+	return succs
+}
+
+// Whether the agent can move from the free cell (x, y) by (dx, dy)
+func (grid *GridGraph) canMove(x, y, dx, dy int) bool {
+	if !grid.free(x+dx, y+dy) {
+		return false
+	}
+	if dx != 0 && dy != 0 {
+		return grid.diagonal && grid.free(x+dx, y) && grid.free(x, y+dy)
+	}
+	return true
+}
+
+func (grid *GridGraph) IsSuccessor(node, successor Node) bool {
+	if !grid.NodeExists(node) || !grid.NodeExists(successor) {
+		return false
+	}
+	x1, y1 := grid.IDToCoords(node.ID())
+	x2, y2 := grid.IDToCoords(successor.ID())
+	dx, dy := x2-x1, y2-y1
+	if dx < -1 || dx > 1 || dy < -1 || dy > 1 || dx == 0 && dy == 0 {
+		return false
+	}
+	return grid.canMove(x1, y1, dx, dy)
+}
+
+func (grid *GridGraph) Predecessors(node Node) []Node {
+	return grid.Successors(node)
+}
+
+func (grid *GridGraph) IsPredecessor(node, pred Node) bool {
+	return grid.IsSuccessor(node, pred)
+}
+
+func (grid *GridGraph) IsAdjacent(node, neighbor Node) bool {
+	return grid.IsSuccessor(node, neighbor)
+}
+
+// Whether node is a free cell. Cells with obstacles aren't in the graph.
+func (grid *GridGraph) NodeExists(node Node) bool {
+	id := node.ID()
+	return id >= 0 && id < len(grid.blocked) && !grid.blocked[id]
+}
+
+func (grid *GridGraph) Degree(node Node) int {
+	return len(grid.Successors(node)) * 2
+}
+
+func (grid *GridGraph) EdgeList() []Edge {
+	var edges []Edge
+	for _, node := range grid.NodeList() {
+		for _, succ := range grid.Successors(node) {
+			edges = append(edges, GonumEdge{H: node, T: succ})
+		}
+	}
+
+	return edges
+}
+
+func (grid *GridGraph) NodeList() []Node {
+	nodes := make([]Node, 0, len(grid.blocked))
+	for id, blocked := range grid.blocked {
+		if !blocked {
+			nodes = append(nodes, GonumNode(id))
+		}
+	}
+
+	return nodes
+}
+
+func (grid *GridGraph) IsDirected() bool {
+	return false
+}
+
+// The cost of a move: 1 along an axis and sqrt(2) diagonally. Whether the move can be made at all is up to Successors.
+func (grid *GridGraph) Cost(node1, node2 Node) float64 {
+	x1, y1 := grid.IDToCoords(node1.ID())
+	x2, y2 := grid.IDToCoords(node2.ID())
+	if x1 != x2 && y1 != y2 {
+		return math.Sqrt2
+	}
+	return 1
+}
+
+// The exact cost between two cells if there were no obstacles: the Manhattan distance on a 4-connected grid and the octile distance on an 8-connected one. It's what D*-Lite
+// uses if it isn't given a heuristic.
+func (grid *GridGraph) HeuristicCost(node1, node2 Node) float64 {
+	return grid.heuristic(node1, node2)
+}
+
+// The position of a cell, its x and y coordinates, as a PositionFunc for the geometric heuristics. Works for any node with an ID on the grid, free or not.
+func (grid *GridGraph) CellPosition(node Node) (x, y float64, ok bool) {
+	id := node.ID()
+	if id < 0 || id >= len(grid.blocked) {
+		return 0, 0, false
+	}
+	cx, cy := grid.IDToCoords(id)
+	return float64(cx), float64(cy), true
+}
@@ -0,0 +1,109 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestGridGraphMoves(t *testing.T) {
+	grid := graph.NewGridGraph(3, 3, true)
+	centre := grid.CoordsToNode(1, 1)
+	if n := len(grid.Successors(centre)); n != 8 {
+		t.Errorf("The centre of an open 8-connected grid has %d neighbors, expected 8", n)
+	}
+	if c := grid.Cost(centre, grid.CoordsToNode(2, 2)); c != math.Sqrt2 {
+		t.Errorf("A diagonal move costs %v, expected sqrt(2)", c)
+	}
+
+	// Blocking (2, 1) rules out moving onto it and squeezing past it diagonally
+	grid.SetObstacle(2, 1)
+	if grid.IsSuccessor(centre, grid.CoordsToNode(2, 2)) || grid.IsSuccessor(centre, grid.CoordsToNode(2, 0)) || grid.IsSuccessor(centre, grid.CoordsToNode(2, 1)) {
+		t.Error("Expected no moves onto or past the obstacle")
+	}
+	if n := len(grid.Successors(centre)); n != 5 {
+		t.Errorf("The centre has %d neighbors beside an obstacle, expected 5", n)
+	}
+	if grid.NodeExists(grid.CoordsToNode(2, 1)) || !grid.Obstacle(2, 1) || !grid.Obstacle(-1, 0) {
+		t.Error("Expected the obstacle, and cells off the grid, not to be in the graph")
+	}
+	_, changed := grid.ChangedEdges()
+	if len(changed) == 0 {
+		t.Error("Expected SetObstacle to change some edges")
+	}
+	if _, again := grid.ChangedEdges(); again != nil {
+		t.Errorf("Expected ChangedEdges to forget the edges it returned, got %d again", len(again))
+	}
+	grid.SetObstacle(2, 1)
+	if _, changed := grid.ChangedEdges(); changed != nil {
+		t.Error("Expected setting an obstacle twice to change nothing the second time")
+	}
+
+	grid.ClearObstacle(2, 1)
+	if n := len(grid.Successors(centre)); n != 8 {
+		t.Errorf("The centre has %d neighbors after clearing the obstacle, expected 8", n)
+	}
+
+	four := graph.NewGridGraph(3, 3, false)
+	if n := len(four.Successors(four.CoordsToNode(1, 1))); n != 4 {
+		t.Errorf("The centre of an open 4-connected grid has %d neighbors, expected 4", n)
+	}
+	if h := four.HeuristicCost(four.CoordsToNode(0, 0), four.CoordsToNode(2, 1)); h != 3 {
+		t.Errorf("The 4-connected heuristic from (0, 0) to (2, 1) is %v, expected 3", h)
+	}
+}
+
+func TestGridGraphDStar(t *testing.T) {
+	for _, diagonal := range []bool{false, true} {
+		src := rand.New(rand.NewSource(4))
+		truth := make(map[[2]int]bool)
+		for i := 0; i < 250; i++ {
+			truth[[2]int{src.Intn(30), src.Intn(30)}] = true
+		}
+		delete(truth, [2]int{0, 0})
+		delete(truth, [2]int{29, 29})
+
+		// The agent sees the obstacles within two cells of it, and checks each replan against A* on what it knows
+		grid := graph.NewGridGraph(30, 30, diagonal)
+		sense := func() {
+			x, y := grid.IDToCoords(grid.Position().ID())
+			for dx := -2; dx <= 2; dx++ {
+				for dy := -2; dy <= 2; dy++ {
+					if truth[[2]int{x + dx, y + dy}] {
+						grid.SetObstacle(x+dx, y+dy)
+					}
+				}
+			}
+		}
+		goal := grid.CoordsToNode(29, 29)
+		sense()
+		ds := graph.InitDStar(grid.Position(), goal, grid, nil, nil)
+
+		for steps := 0; grid.Position().ID() != goal.ID(); steps++ {
+			_, want, _ := graph.AStar(grid.Position(), goal, grid, nil, nil)
+			if _, cost, err := ds.Path(); err != nil || math.Abs(cost-want) > 1e-9 {
+				_, fresh, _ := graph.InitDStar(grid.Position(), goal, grid, nil, nil).Path()
+				p, _, _ := ds.Path()
+				ap, _, _ := graph.AStar(grid.Position(), goal, grid, nil, nil)
+				t.Log(fresh, nodeIDs(p), nodeIDs(ap))
+				t.Fatalf("diagonal %t: at %v the plan costs %v (%v), A* on the known grid %v", diagonal, grid.Position(), cost, err, want)
+			}
+			if steps > 30*30 {
+				t.Fatalf("diagonal %t: still going after %d steps", diagonal, steps)
+			}
+
+			next, err := ds.Step()
+			if err != nil {
+				t.Fatalf("diagonal %t: %v", diagonal, err)
+			}
+			if x, y := grid.IDToCoords(next.ID()); truth[[2]int{x, y}] {
+				t.Fatalf("diagonal %t: moved onto the obstacle at (%d, %d)", diagonal, x, y)
+			}
+			grid.Move(next)
+			sense()
+			ds.Update(grid.ChangedEdges())
+		}
+	}
+}
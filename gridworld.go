@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"errors"
+)
+
+// Returned by SimulateDStar when the planner tells the agent to move somewhere it can't: a tile that isn't next to it, or one it knows, or is about to find out, is a wall.
+var ErrIllegalMove = errors.New("Planner made an illegal move")
+
+// Returned by SimulateDStar when the agent hasn't reached the goal after the most steps it was allowed.
+var ErrStepLimit = errors.New("Agent didn't reach the goal within the step limit")
+
+// What happened during a SimulateDStar run.
+type DStarRun struct {
+	// Every tile the agent stood on, in order, from its start to where it stopped.
+	Path []Node
+	// How many moves revealed a difference from what the agent believed, so the plan had to be repaired.
+	Replans int
+	// How many changed edges were reported to the planner in total.
+	ChangedEdges int
+	// The instance that drove the agent, e.g. for its Stats if WithDStarStats was among the options.
+	DStar *DStarInstance
+}
+
+// The hidden map the agent is finding its way through.
+func (graph *RevealingTileGraph) Truth() *TileGraph {
+	return graph.truth
+}
+
+// Whether the agent has ever had node within sensor range, so that what it believes about the tile is the truth.
+func (graph *RevealingTileGraph) Seen(node Node) bool {
+	return node.ID() >= 0 && node.ID() < len(graph.seen) && graph.seen[node.ID()]
+}
+
+// Draws the world as the agent knows it: walls it has found as black squares, the agent as @, tiles it hasn't seen yet as dots and the rest as spaces.
+func (graph *RevealingTileGraph) String() string {
+	var outString string
+	for r := 0; r < graph.numRows; r++ {
+		for c := 0; c < graph.numCols; c++ {
+			if id := r*graph.numCols + c; id == graph.position.ID() {
+				outString += "@"
+			} else if !graph.seen[id] {
+				outString += "."
+			} else if graph.tiles[id] == false {
+				outString += "▀" // Black square
+			} else {
+				outString += " "
+			}
+		}
+
+		outString += "\n"
+	}
+
+	return outString[:len(outString)-1] // Kill final newline
+}
+
+// Whether the agent can move to node: a tile next to it that it believes is passable and really is
+func (graph *RevealingTileGraph) legalMove(node Node) bool {
+	for _, succ := range graph.Successors(graph.position) {
+		if succ.ID() == node.ID() {
+			return graph.truth.NodeExists(node)
+		}
+	}
+
+	return false
+}
+
+// Runs D*-Lite through world from the agent's current position to goal, step by step, as a robot would: each step the planner picks a move, the agent makes it, its sensor
+// reveals its new surroundings, and the planner is told what changed. Along the way it checks that the planner keeps to the DStarGraph contract, only ever moving the agent to
+// a neighboring tile it believes is passable and that really is, and returns ErrIllegalMove if it doesn't. That holds for any sensor radius of at least 1, since the agent
+// always sees its neighbors before moving.
+//
+// It stops at the goal, after maxSteps moves with ErrStepLimit, or with the planner's error if it finds there's no path. Whatever the outcome, the run so far is returned.
+// This makes it a harness for testing D*-Lite end to end and for demonstrating it, e.g. by printing the world after every move.
+func SimulateDStar(world *RevealingTileGraph, goal Node, maxSteps int, options ...DStarOption) (DStarRun, error) {
+	start := world.Position()
+	run := DStarRun{Path: []Node{start}, DStar: InitDStar(start, goal, world, nil, nil, options...)}
+
+	for world.Position().ID() != goal.ID() {
+		if len(run.Path) > maxSteps {
+			return run, ErrStepLimit
+		}
+
+		next, err := run.DStar.Step()
+		if err != nil {
+			return run, err
+		}
+		if !world.legalMove(next) {
+			return run, ErrIllegalMove
+		}
+
+		world.Move(next)
+		run.Path = append(run.Path, next)
+
+		cost, changed := world.ChangedEdges()
+		if len(changed) > 0 {
+			run.Replans++
+			run.ChangedEdges += len(changed)
+		}
+		run.DStar.Update(cost, changed)
+	}
+
+	return run, nil
+}
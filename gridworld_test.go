@@ -0,0 +1,83 @@
+package graph_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Whether path only ever steps between neighboring passable tiles
+func isWalk(tg *graph.TileGraph, path []graph.Node) bool {
+	for i, node := range path {
+		if !tg.NodeExists(node) {
+			return false
+		}
+		if i > 0 {
+			r0, c0 := tg.IDToCoords(path[i-1].ID())
+			r1, c1 := tg.IDToCoords(node.ID())
+			if abs(r1-r0)+abs(c1-c0) != 1 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestSimulateDStar(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		truth := graph.GenerateMaze(8, 8, graph.RecursiveBacktracker, rand.New(rand.NewSource(seed)))
+		start, goal := truth.CoordsToNode(1, 1), truth.CoordsToNode(15, 15)
+		world := graph.NewRevealingTileGraph(truth, start, 1)
+
+		run, err := graph.SimulateDStar(world, goal, 10000, graph.WithDStarStats())
+		if err != nil {
+			t.Fatalf("Seed %d: %v\n%v", seed, err, world)
+		}
+		if run.Path[0].ID() != start.ID() || run.Path[len(run.Path)-1].ID() != goal.ID() || !isWalk(truth, run.Path) {
+			t.Errorf("Seed %d: walked an impossible path\n%s", seed, truth.PathString(run.Path))
+		}
+		if optimal, _, _ := graph.AStar(start, goal, truth, nil, nil); len(run.Path) < len(optimal) {
+			t.Errorf("Seed %d: walked %d tiles, shorter than the shortest path's %d", seed, len(run.Path), len(optimal))
+		}
+		if run.Replans == 0 || run.ChangedEdges == 0 || run.DStar.Stats().Searches < 2 {
+			t.Errorf("Seed %d: a maze seen one tile ahead should take replanning, got %+v", seed, run)
+		}
+		if !world.Seen(goal) {
+			t.Errorf("Seed %d: reached the goal without seeing it\n%v", seed, world)
+		}
+	}
+}
+
+func TestSimulateDStarFailures(t *testing.T) {
+	walled := graph.NewTileGraph(3, 3, true)
+	for row := 0; row < 3; row++ {
+		walled.SetPassability(row, 1, false)
+	}
+	if _, err := graph.SimulateDStar(graph.NewRevealingTileGraph(walled, graph.GonumNode(0), 1), graph.GonumNode(2), 100); err == nil || err == graph.ErrIllegalMove || err == graph.ErrStepLimit {
+		t.Errorf("Expected the planner to find there's no path, got %v", err)
+	}
+
+	open := graph.NewTileGraph(1, 10, true)
+	if run, err := graph.SimulateDStar(graph.NewRevealingTileGraph(open, graph.GonumNode(0), 1), graph.GonumNode(9), 3); err != graph.ErrStepLimit || len(run.Path) != 4 {
+		t.Errorf("Expected to stop with ErrStepLimit after 3 moves, got %v after %d", err, len(run.Path)-1)
+	}
+
+	// A blind agent (radius 0) walks straight into the wall it couldn't see
+	if _, err := graph.SimulateDStar(graph.NewRevealingTileGraph(walled, graph.GonumNode(0), 0), graph.GonumNode(2), 100); err != graph.ErrIllegalMove {
+		t.Errorf("Expected ErrIllegalMove for a blind agent, got %v", err)
+	}
+}
+
+func TestRevealingTileGraphString(t *testing.T) {
+	truth := graph.NewTileGraph(3, 5, true)
+	truth.SetPassability(0, 1, false)
+	world := graph.NewRevealingTileGraph(truth, graph.GonumNode(0), 1)
+
+	expected := strings.Join([]string{"@▀...", "  ...", "....."}, "\n")
+	if world.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, world.String())
+	}
+}
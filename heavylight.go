@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"math"
+)
+
+// An Aggregate combines values along a path: Combine must be associative and commutative, and Identity must leave any value unchanged when combined with it.
+type Aggregate struct {
+	Identity float64
+	Combine  func(a, b float64) float64
+}
+
+// The common aggregates: the total weight of a path, and its heaviest and lightest edges (the bottleneck, if weights are capacities).
+var (
+	SumAggregate = Aggregate{0, func(a, b float64) float64 { return a + b }}
+	MaxAggregate = Aggregate{math.Inf(-1), math.Max}
+	MinAggregate = Aggregate{math.Inf(1), math.Min}
+)
+
+// A HeavyLight answers aggregate queries over the edge weights on the path between any two nodes of a tree, such as the total length, or the bottleneck capacity, of the route
+// between two sites of a tree shaped network, while weights change. Queries and updates take O(log² n).
+//
+// It's a heavy-light decomposition[1]: every node's edge to its child with the largest subtree is heavy and the others are light, so the heavy edges form chains, and any path
+// crosses O(log n) of them, since every light edge on the way down at least halves the subtree. Laid end to end, the chains are the leaves of a segment tree holding each
+// node's edge to its parent, so each chain's part of a path is aggregated in O(log n).
+//
+// [1] D. D. Sleator and R. E. Tarjan, "A data structure for dynamic trees", Journal of Computer and System Sciences 26 (1983)
+type HeavyLight struct {
+	Tree      *RootedTree
+	aggregate Aggregate
+	head      map[int]Node // The top of each node's chain
+	pos       map[int]int  // Each node's leaf in the segment tree
+	segments  []float64
+}
+
+// Decomposes the tree containing root, hung from root, with the weight of each edge as given by Cost (interpreted as in AStar) from parent to child. Returns ErrNotTree as
+// RootTree does.
+func NewHeavyLight(graph Graph, root Node, Cost func(Node, Node) float64, aggregate Aggregate) (*HeavyLight, error) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	tree, err := RootTree(graph, root)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(tree.Order)
+	hl := &HeavyLight{Tree: tree, aggregate: aggregate, head: map[int]Node{root.ID(): root}, pos: make(map[int]int, n), segments: make([]float64, 2*n)}
+
+	// Depth first, heavy child last onto the stack so it's next, which lays each chain out contiguously
+	stack := []Node{root}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		hl.pos[node.ID()] = len(hl.pos)
+
+		var heavy Node
+		for _, child := range tree.Children(node) {
+			if heavy == nil || tree.Size[child.ID()] > tree.Size[heavy.ID()] {
+				heavy = child
+			}
+		}
+		for _, child := range tree.Children(node) {
+			if child.ID() != heavy.ID() {
+				hl.head[child.ID()] = child
+				stack = append(stack, child)
+			}
+		}
+		if heavy != nil {
+			hl.head[heavy.ID()] = hl.head[node.ID()]
+			stack = append(stack, heavy)
+		}
+	}
+
+	for i := range hl.segments {
+		hl.segments[i] = aggregate.Identity
+	}
+	for _, node := range tree.Order {
+		if parent, ok := tree.Parent[node.ID()]; ok {
+			hl.segments[n+hl.pos[node.ID()]] = Cost(parent, node)
+		}
+	}
+	for i := n - 1; i > 0; i-- {
+		hl.segments[i] = aggregate.Combine(hl.segments[2*i], hl.segments[2*i+1])
+	}
+
+	return hl, nil
+}
+
+// The aggregate of the weights of the edges on the path between u and v, which is the Identity if u is v. Returns false if either isn't in the tree.
+func (hl *HeavyLight) Query(u, v Node) (value float64, ok bool) {
+	if !hl.contains(u) || !hl.contains(v) {
+		return hl.aggregate.Identity, false
+	}
+
+	value = hl.aggregate.Identity
+	tree := hl.Tree
+	for hl.head[u.ID()].ID() != hl.head[v.ID()].ID() {
+		if tree.Depth[hl.head[u.ID()].ID()] < tree.Depth[hl.head[v.ID()].ID()] {
+			u, v = v, u
+		}
+		head := hl.head[u.ID()]
+		value = hl.aggregate.Combine(value, hl.segment(hl.pos[head.ID()], hl.pos[u.ID()]))
+		u = tree.Parent[head.ID()]
+	}
+
+	// u and v are on one chain now, and the edge into the upper one isn't on the path
+	if tree.Depth[u.ID()] > tree.Depth[v.ID()] {
+		u, v = v, u
+	}
+	if u.ID() != v.ID() {
+		value = hl.aggregate.Combine(value, hl.segment(hl.pos[u.ID()]+1, hl.pos[v.ID()]))
+	}
+
+	return value, true
+}
+
+// The lowest common ancestor of u and v, and false if either isn't in the tree.
+func (hl *HeavyLight) LCA(u, v Node) (Node, bool) {
+	if !hl.contains(u) || !hl.contains(v) {
+		return nil, false
+	}
+
+	tree := hl.Tree
+	for hl.head[u.ID()].ID() != hl.head[v.ID()].ID() {
+		if tree.Depth[hl.head[u.ID()].ID()] < tree.Depth[hl.head[v.ID()].ID()] {
+			u, v = v, u
+		}
+		u = tree.Parent[hl.head[u.ID()].ID()]
+	}
+	if tree.Depth[u.ID()] > tree.Depth[v.ID()] {
+		return v, true
+	}
+	return u, true
+}
+
+// Sets the weight of the tree edge between u and v, in either order, returning false if there's no such edge.
+func (hl *HeavyLight) Update(u, v Node, weight float64) bool {
+	tree := hl.Tree
+	if parent, ok := tree.Parent[u.ID()]; ok && parent.ID() == v.ID() {
+		u, v = v, u
+	} else if parent, ok := tree.Parent[v.ID()]; !ok || parent.ID() != u.ID() {
+		return false
+	}
+
+	// v is the child, which holds the edge
+	i := len(tree.Order) + hl.pos[v.ID()]
+	hl.segments[i] = weight
+	for i /= 2; i > 0; i /= 2 {
+		hl.segments[i] = hl.aggregate.Combine(hl.segments[2*i], hl.segments[2*i+1])
+	}
+
+	return true
+}
+
+func (hl *HeavyLight) contains(node Node) bool {
+	_, ok := hl.pos[node.ID()]
+	return ok
+}
+
+// The aggregate of the leaves from l to r inclusive
+func (hl *HeavyLight) segment(l, r int) float64 {
+	n := len(hl.Tree.Order)
+	value := hl.aggregate.Identity
+	for l, r = l+n, r+n+1; l < r; l, r = l/2, r/2 {
+		if l&1 == 1 {
+			value = hl.aggregate.Combine(value, hl.segments[l])
+			l++
+		}
+		if r&1 == 1 {
+			r--
+			value = hl.aggregate.Combine(value, hl.segments[r])
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,75 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestHeavyLight(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	tree := randomTree(300, src)
+	for _, edge := range tree.EdgeList() {
+		tree.SetEdgeCost(edge, float64(src.Intn(100)))
+	}
+
+	aggregates := map[string]graph.Aggregate{"sum": graph.SumAggregate, "max": graph.MaxAggregate, "min": graph.MinAggregate}
+	decompositions := make(map[string]*graph.HeavyLight)
+	for name, aggregate := range aggregates {
+		hl, err := graph.NewHeavyLight(tree, graph.GonumNode(0), nil, aggregate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decompositions[name] = hl
+	}
+	rooted := decompositions["sum"].Tree
+
+	// Walks both nodes up to their common ancestor
+	brute := func(u, v graph.Node, aggregate graph.Aggregate) (value float64, lca graph.Node) {
+		value = aggregate.Identity
+		for u.ID() != v.ID() {
+			if rooted.Depth[u.ID()] < rooted.Depth[v.ID()] {
+				u, v = v, u
+			}
+			parent := rooted.Parent[u.ID()]
+			value = aggregate.Combine(value, tree.Cost(parent, u))
+			u = parent
+		}
+		return value, u
+	}
+
+	edges := tree.EdgeList()
+	for round := 0; round < 500; round++ {
+		if round%5 == 0 {
+			edge := edges[src.Intn(len(edges))]
+			weight := float64(src.Intn(100))
+			tree.SetEdgeCost(edge, weight)
+			for _, hl := range decompositions {
+				if !hl.Update(edge.Head(), edge.Tail(), weight) {
+					t.Fatalf("Couldn't update edge %v", edge)
+				}
+			}
+		}
+
+		u, v := graph.GonumNode(src.Intn(300)), graph.GonumNode(src.Intn(300))
+		for name, hl := range decompositions {
+			want, lca := brute(u, v, aggregates[name])
+			if got, ok := hl.Query(u, v); !ok || got != want && !(math.IsInf(got, 0) && math.IsInf(want, 0)) {
+				t.Errorf("%s of the path from %v to %v is %v, expected %v", name, u, v, got, want)
+			}
+			if got, ok := hl.LCA(u, v); !ok || got.ID() != lca.ID() {
+				t.Errorf("Lowest common ancestor of %v and %v is %v, expected %v", u, v, got, lca)
+			}
+		}
+	}
+
+	hl := decompositions["sum"]
+	if _, ok := hl.Query(graph.GonumNode(0), graph.GonumNode(1000)); ok {
+		t.Error("Queried a path to a node not in the tree")
+	}
+	if hl.Update(graph.GonumNode(0), graph.GonumNode(0), 1) {
+		t.Error("Updated an edge not in the tree")
+	}
+}
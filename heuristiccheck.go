@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// What's wrong with a heuristic's estimate.
+type HeuristicFault int
+
+const (
+	// The estimate from Node to Goal exceeds the true cost of the shortest path, Bound. A* and D*-Lite may then return a path that isn't the shortest.
+	Inadmissible HeuristicFault = iota
+	// The estimate from Node to Goal exceeds the cost of the edge to Successor plus the estimate from there, Bound. A* may then reopen nodes it has already expanded, which this
+	// package's A* doesn't do, so its paths may not be the shortest either.
+	Inconsistent
+)
+
+// One estimate of a heuristic that breaks its guarantees. Successor is only set for Inconsistent estimates.
+type HeuristicViolation struct {
+	Fault           HeuristicFault
+	Node, Goal      Node
+	Successor       Node
+	Estimate, Bound float64
+}
+
+func (v HeuristicViolation) String() string {
+	if v.Fault == Inadmissible {
+		return fmt.Sprintf("h(%v, %v) = %v overestimates the shortest path, which costs %v", v.Node, v.Goal, v.Estimate, v.Bound)
+	}
+	return fmt.Sprintf("h(%v, %v) = %v is more than cost(%v, %v) + h(%v, %v) = %v", v.Node, v.Goal, v.Estimate, v.Node, v.Successor, v.Successor, v.Goal, v.Bound)
+}
+
+// Checks a heuristic for every node of graph against each of goals (every node if goals is nil), reporting every estimate that is inadmissible (more than the true cost to the
+// goal) or inconsistent (more than the cost of an edge plus the estimate from its other end). An estimate is only reported if it's over its bound by more than tolerance, to allow
+// for rounding.
+//
+// This takes a shortest path search per goal and looks at every edge once per goal, so checking every goal is only practical on small graphs. On big ones, pass a random sample
+// of goals, ideally including the ones the heuristic will actually be used with; it only takes one bad estimate to make A* return a suboptimal path, so any violation found is
+// worth investigating.
+//
+// Violations are ordered by goal ID, then node ID, then successor ID. Cost and HeuristicCost are resolved as in AStar.
+func CheckHeuristic(graph Graph, Cost, HeuristicCost func(Node, Node) float64, goals []Node, tolerance float64) []HeuristicViolation {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	if goals == nil {
+		goals = nodes
+	} else {
+		goals = append([]Node(nil), goals...)
+		sort.Sort(byID(goals))
+	}
+
+	var violations []HeuristicViolation
+	for _, goal := range goals {
+		dist := distancesFrom(goal, graph, Cost, true)
+		for _, node := range nodes {
+			h := HeuristicCost(node, goal)
+			if d, ok := dist[node.ID()]; ok && h > d+tolerance {
+				violations = append(violations, HeuristicViolation{Fault: Inadmissible, Node: node, Goal: goal, Estimate: h, Bound: d})
+			}
+
+			succs := append([]Node(nil), graph.Successors(node)...)
+			sort.Sort(byID(succs))
+			for _, succ := range succs {
+				if bound := Cost(node, succ) + HeuristicCost(succ, goal); h > bound+tolerance {
+					violations = append(violations, HeuristicViolation{Fault: Inconsistent, Node: node, Goal: goal, Successor: succ, Estimate: h, Bound: bound})
+				}
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,51 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+)
+
+func TestCheckHeuristic(t *testing.T) {
+	maze := graph.GenerateMaze(5, 5, graph.RecursiveBacktracker, rand.New(rand.NewSource(1)))
+	manhattan := graph.ManhattanHeuristic(maze.Position, 1)
+	if violations := graph.CheckHeuristic(maze, nil, manhattan, nil, 1e-9); len(violations) != 0 {
+		t.Errorf("The Manhattan heuristic broke its guarantees on a maze: %v", violations[0])
+	}
+
+	// Twice the Manhattan distance overestimates everywhere a path isn't blocked
+	goal := maze.CoordsToNode(1, 1)
+	double := func(a, b graph.Node) float64 { return 2 * manhattan(a, b) }
+	violations := graph.CheckHeuristic(maze, nil, double, []graph.Node{goal}, 1e-9)
+	var inadmissible, inconsistent int
+	for _, v := range violations {
+		if v.Goal.ID() != goal.ID() || v.Estimate <= v.Bound {
+			t.Errorf("Bogus violation %v", v)
+		}
+		switch v.Fault {
+		case graph.Inadmissible:
+			inadmissible++
+		case graph.Inconsistent:
+			inconsistent++
+			if !maze.IsSuccessor(v.Node, v.Successor) {
+				t.Errorf("Violation %v is for a non-edge", v)
+			}
+		}
+	}
+	if inadmissible == 0 || inconsistent == 0 {
+		t.Errorf("Found %d inadmissible and %d inconsistent estimates for double the Manhattan distance, want some of each", inadmissible, inconsistent)
+	}
+
+	// A heuristic can be admissible without being consistent
+	g := dag([][2]int{{0, 1}, {1, 2}})
+	h := func(a, b graph.Node) float64 {
+		if a.ID() == 0 && b.ID() == 2 {
+			return 2
+		}
+		return 0
+	}
+	violations = graph.CheckHeuristic(g, nil, h, nil, 0)
+	if len(violations) != 1 || violations[0].Fault != graph.Inconsistent || violations[0].Successor.ID() != 1 {
+		t.Errorf("Got violations %v, want h(0, 2) to be inconsistent with the edge to 1", violations)
+	}
+}
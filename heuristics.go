@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"math"
+)
+
+// A node that knows where it is in the plane, for the geometric heuristics. PointNode is one.
+type Positioner interface {
+	Coordinates() (x, y float64)
+}
+
+// Where a node is, if that's known. NodeCoordinates works for Positioner nodes; TileGraph.Position and Layout.Position give positions for nodes that aren't.
+type PositionFunc func(node Node) (x, y float64, ok bool)
+
+// The coordinates of a Positioner node; ok is false for any other node.
+func NodeCoordinates(node Node) (x, y float64, ok bool) {
+	if p, ok := node.(Positioner); ok {
+		x, y = p.Coordinates()
+		return x, y, true
+	}
+
+	return 0, 0, false
+}
+
+func (node PointNode) Coordinates() (x, y float64) {
+	return node.X, node.Y
+}
+
+// The position of a tile: its column as x and its row as y, so neighboring tiles are 1 apart. Works for any node with an ID in the grid, passable or not.
+func (graph *TileGraph) Position(node Node) (x, y float64, ok bool) {
+	id := node.ID()
+	if id < 0 || id >= graph.numRows*graph.numCols {
+		return 0, 0, false
+	}
+
+	row, col := graph.IDToCoords(id)
+	return float64(col), float64(row), true
+}
+
+// The node's position in the layout, if it has one.
+func (layout Layout) Position(node Node) (x, y float64, ok bool) {
+	p, ok := layout[node.ID()]
+	return p.X, p.Y, ok
+}
+
+// Returns a heuristic from a distance between positions, found with position (NodeCoordinates if nil). A node without a position makes the estimate 0, which is always admissible.
+func geometricHeuristic(position PositionFunc, distance func(dx, dy float64) float64) func(a, b Node) float64 {
+	if position == nil {
+		position = NodeCoordinates
+	}
+
+	return func(a, b Node) float64 {
+		ax, ay, ok1 := position(a)
+		bx, by, ok2 := position(b)
+		if !ok1 || !ok2 {
+			return 0
+		}
+
+		return distance(math.Abs(ax-bx), math.Abs(ay-by))
+	}
+}
+
+// Returns a heuristic estimating the cost between two nodes as their straight line distance times scale. It's admissible for any graph where an edge costs at least scale times
+// the distance it spans, e.g. scale 1 for roads weighted by length, or 1/(top speed) for roads weighted by travel time.
+//
+// Positions come from position, or NodeCoordinates if it's nil; as for all the geometric heuristics, a node without a position gets an estimate of 0.
+func EuclideanHeuristic(position PositionFunc, scale float64) func(a, b Node) float64 {
+	return geometricHeuristic(position, func(dx, dy float64) float64 {
+		return scale * math.Hypot(dx, dy)
+	})
+}
+
+// Returns a heuristic estimating the cost between two nodes as their Manhattan (taxicab) distance times scale: the exact cost on a 4-connected grid where every step costs scale,
+// such as a TileGraph with scale 1 and positions from TileGraph.Position.
+func ManhattanHeuristic(position PositionFunc, scale float64) func(a, b Node) float64 {
+	return geometricHeuristic(position, func(dx, dy float64) float64 {
+		return scale * (dx + dy)
+	})
+}
+
+// Returns a heuristic for 8-connected grids where a straight step costs straight and a diagonal step costs diagonal (commonly 1 and sqrt(2)): the cost of going diagonally
+// until level with the goal and straight the rest of the way, which is exact on an open grid.
+func OctileHeuristic(position PositionFunc, straight, diagonal float64) func(a, b Node) float64 {
+	return geometricHeuristic(position, func(dx, dy float64) float64 {
+		return straight*math.Max(dx, dy) + (diagonal-straight)*math.Min(dx, dy)
+	})
+}
+
+// Returns a heuristic estimating the cost between two nodes as their Chebyshev distance (the larger of the distances along x and y) times scale: the exact cost on an 8-connected
+// grid where diagonal steps cost the same as straight ones, like a king's moves in chess.
+func ChebyshevHeuristic(position PositionFunc, scale float64) func(a, b Node) float64 {
+	return geometricHeuristic(position, func(dx, dy float64) float64 {
+		return scale * math.Max(dx, dy)
+	})
+}
@@ -0,0 +1,53 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestGeometricHeuristics(t *testing.T) {
+	layout := graph.Layout{0: {0, 0}, 1: {3, 4}}
+	a, b, c := graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(2)
+	for _, test := range []struct {
+		name      string
+		heuristic func(a, b graph.Node) float64
+		want      float64
+	}{
+		{"Euclidean", graph.EuclideanHeuristic(layout.Position, 2), 10},
+		{"Manhattan", graph.ManhattanHeuristic(layout.Position, 1), 7},
+		{"octile", graph.OctileHeuristic(layout.Position, 1, math.Sqrt2), 1 + 3*math.Sqrt2},
+		{"Chebyshev", graph.ChebyshevHeuristic(layout.Position, 1), 4},
+	} {
+		if h := test.heuristic(a, b); math.Abs(h-test.want) > 1e-12 {
+			t.Errorf("%s heuristic is %v, want %v", test.name, h, test.want)
+		}
+		if h := test.heuristic(a, c); h != 0 {
+			t.Errorf("%s heuristic is %v for a node without a position", test.name, h)
+		}
+	}
+
+	// PointNodes are Positioners
+	p, q := graph.PointNode{Id: 0, X: 1, Y: 1}, graph.PointNode{Id: 1, X: 4, Y: 5}
+	if h := graph.EuclideanHeuristic(nil, 1)(p, q); h != graph.EuclideanDistance(p, q) {
+		t.Errorf("Euclidean heuristic is %v between PointNodes, EuclideanDistance is %v", h, graph.EuclideanDistance(p, q))
+	}
+}
+
+func TestManhattanHeuristicTileGraph(t *testing.T) {
+	maze := graph.GenerateMaze(15, 15, graph.RecursiveBacktracker, rand.New(rand.NewSource(1)))
+	start, goal := maze.CoordsToNode(1, 1), maze.CoordsToNode(29, 29)
+	_, want, blind := graph.AStar(start, goal, maze, nil, nil)
+	_, cost, expanded := graph.AStar(start, goal, maze, nil, graph.ManhattanHeuristic(maze.Position, 1))
+	if cost != want || expanded > blind {
+		t.Errorf("With the Manhattan heuristic A* found cost %v expanding %d nodes, without %v expanding %d", cost, expanded, want, blind)
+	}
+
+	// Exact on an open grid
+	open := graph.NewTileGraph(10, 10, true)
+	h := graph.ManhattanHeuristic(open.Position, 1)
+	if _, cost, _ := graph.AStar(open.CoordsToNode(0, 0), open.CoordsToNode(9, 6), open, nil, h); cost != h(open.CoordsToNode(0, 0), open.CoordsToNode(9, 6)) {
+		t.Errorf("The Manhattan heuristic isn't exact on an open grid")
+	}
+}
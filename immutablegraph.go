@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// An ImmutableGraph is a frozen copy of another graph in compressed sparse row form: nodes are numbered 0 to n-1 in order of ID, and every node's successors, and their costs,
+// sit next to each other in one flat slice, as do its predecessors. There are no maps to hash into and next to nothing for the garbage collector to scan, so on graphs of
+// millions of nodes it's several times smaller than a GonumGraph and searches on it run faster, at the price of not being able to change it: to change the graph, change the
+// original and freeze it again.
+//
+// It lists nodes, successors, predecessors and edges in order of ID, like a GonumGraph in IDOrder. VisitSuccessors visits without allocating, and IsSuccessor and Cost are
+// binary searches. A node that isn't in the graph has no neighbors, and the cost of an edge that isn't there is +Inf.
+type ImmutableGraph struct {
+	nodes    []Node
+	index    map[int]int // From ID to position in nodes, or nil if every node's ID is its position
+	directed bool
+
+	// Node i's successors are succs[succStart[i]:succStart[i+1]], by position and in order, and succCosts holds the costs of the edges to them. An undirected graph's
+	// predecessors are its successors, and share their slices.
+	succStart, predStart []int
+	succs, preds         []int32
+	succCosts, predCosts []float64
+
+	heuristic func(Node, Node) float64
+}
+
+// Freezes g into an ImmutableGraph. Edge costs are g's, as its Cost method gives them, or 1 if it isn't a Coster; if it's a HeuristicCoster its HeuristicCost becomes the
+// frozen graph's, and is called as it is, so it shouldn't depend on anything about g that's going to change. Freezing takes a pass over every node's successors, and
+// a sort of each node's.
+func NewImmutableGraph(g Graph) *ImmutableGraph {
+	nodes := g.NodeList()
+	sort.Sort(byID(nodes))
+	graph := &ImmutableGraph{nodes: nodes, directed: g.IsDirected(), heuristic: NullHeuristic}
+	if hgraph, ok := g.(HeuristicCoster); ok {
+		graph.heuristic = hgraph.HeuristicCost
+	}
+	for i, node := range nodes {
+		if node.ID() != i {
+			graph.index = make(map[int]int, len(nodes))
+			for i, node := range nodes {
+				graph.index[node.ID()] = i
+			}
+			break
+		}
+	}
+
+	visit := successorVisitor(g, nil)
+	graph.succStart = make([]int, len(nodes)+1)
+	for i, node := range nodes {
+		start := len(graph.succs)
+		visit(node, func(succ Node, cost float64) bool {
+			if j, ok := graph.position(succ); ok {
+				graph.succs = append(graph.succs, int32(j))
+				graph.succCosts = append(graph.succCosts, cost)
+			}
+			return true
+		})
+		sort.Sort(byPosition{graph.succs[start:], graph.succCosts[start:]})
+		graph.succStart[i+1] = len(graph.succs)
+	}
+
+	if !graph.directed {
+		graph.predStart, graph.preds, graph.predCosts = graph.succStart, graph.succs, graph.succCosts
+		return graph
+	}
+
+	// Predecessors by counting sort: count each node's, then deal the edges out in order of their heads, which leaves every node's predecessors in order
+	graph.predStart = make([]int, len(nodes)+1)
+	for _, j := range graph.succs {
+		graph.predStart[j+1]++
+	}
+	for i := range nodes {
+		graph.predStart[i+1] += graph.predStart[i]
+	}
+	graph.preds = make([]int32, len(graph.succs))
+	graph.predCosts = make([]float64, len(graph.succs))
+	next := append([]int(nil), graph.predStart[:len(nodes)]...)
+	for i := range nodes {
+		for k := graph.succStart[i]; k < graph.succStart[i+1]; k++ {
+			j := graph.succs[k]
+			graph.preds[next[j]] = int32(i)
+			graph.predCosts[next[j]] = graph.succCosts[k]
+			next[j]++
+		}
+	}
+
+	return graph
+}
+
+// Where node is in the graph's node order, and whether it's in the graph at all.
+func (graph *ImmutableGraph) position(node Node) (int, bool) {
+	id := node.ID()
+	if graph.index == nil {
+		return id, id >= 0 && id < len(graph.nodes)
+	}
+	i, ok := graph.index[id]
+	return i, ok
+}
+
+// Where in neighbors, one node's successors or predecessors, the node at position j is, or -1 if it isn't.
+func searchNeighbors(neighbors []int32, j int) int {
+	k := sort.Search(len(neighbors), func(k int) bool { return int(neighbors[k]) >= j })
+	if k < len(neighbors) && int(neighbors[k]) == j {
+		return k
+	}
+	return -1
+}
+
+func (graph *ImmutableGraph) neighbors(node Node, start []int, neighbors []int32) []Node {
+	i, ok := graph.position(node)
+	if !ok {
+		return nil
+	}
+
+	nodes := make([]Node, start[i+1]-start[i])
+	for k, j := range neighbors[start[i]:start[i+1]] {
+		nodes[k] = graph.nodes[j]
+	}
+	return nodes
+}
+
+// Whether to is one of from's neighbors as start and neighbors list them.
+func (graph *ImmutableGraph) isNeighbor(from, to Node, start []int, neighbors []int32) bool {
+	i, ok := graph.position(from)
+	if !ok {
+		return false
+	}
+	j, ok := graph.position(to)
+	return ok && searchNeighbors(neighbors[start[i]:start[i+1]], j) >= 0
+}
+
+func (graph *ImmutableGraph) Successors(node Node) []Node {
+	return graph.neighbors(node, graph.succStart, graph.succs)
+}
+
+func (graph *ImmutableGraph) IsSuccessor(node, successor Node) bool {
+	return graph.isNeighbor(node, successor, graph.succStart, graph.succs)
+}
+
+func (graph *ImmutableGraph) Predecessors(node Node) []Node {
+	return graph.neighbors(node, graph.predStart, graph.preds)
+}
+
+func (graph *ImmutableGraph) IsPredecessor(node, predecessor Node) bool {
+	return graph.isNeighbor(node, predecessor, graph.predStart, graph.preds)
+}
+
+func (graph *ImmutableGraph) IsAdjacent(node, neighbor Node) bool {
+	return graph.IsSuccessor(node, neighbor) || graph.IsPredecessor(node, neighbor)
+}
+
+func (graph *ImmutableGraph) NodeExists(node Node) bool {
+	_, ok := graph.position(node)
+	return ok
+}
+
+func (graph *ImmutableGraph) Degree(node Node) int {
+	i, ok := graph.position(node)
+	if !ok {
+		return 0
+	}
+	return graph.succStart[i+1] - graph.succStart[i] + graph.predStart[i+1] - graph.predStart[i]
+}
+
+func (graph *ImmutableGraph) EdgeList() []Edge {
+	edges := make([]Edge, 0, len(graph.succs))
+	for i, node := range graph.nodes {
+		for _, j := range graph.succs[graph.succStart[i]:graph.succStart[i+1]] {
+			edges = append(edges, GonumEdge{node, graph.nodes[j]})
+		}
+	}
+	return edges
+}
+
+func (graph *ImmutableGraph) NodeList() []Node {
+	return append([]Node(nil), graph.nodes...)
+}
+
+func (graph *ImmutableGraph) IsDirected() bool {
+	return graph.directed
+}
+
+func (graph *ImmutableGraph) Cost(node, succ Node) float64 {
+	i, ok := graph.position(node)
+	if !ok {
+		return math.Inf(1)
+	}
+	j, ok := graph.position(succ)
+	if !ok {
+		return math.Inf(1)
+	}
+	start := graph.succStart[i]
+	if k := searchNeighbors(graph.succs[start:graph.succStart[i+1]], j); k >= 0 {
+		return graph.succCosts[start+k]
+	}
+	return math.Inf(1)
+}
+
+// The heuristic of the graph it was frozen from, or the null heuristic if that didn't have one.
+func (graph *ImmutableGraph) HeuristicCost(node1, node2 Node) float64 {
+	return graph.heuristic(node1, node2)
+}
+
+func (graph *ImmutableGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	i, ok := graph.position(node)
+	if !ok {
+		return
+	}
+	for k := graph.succStart[i]; k < graph.succStart[i+1]; k++ {
+		if !fn(graph.nodes[graph.succs[k]], graph.succCosts[k]) {
+			return
+		}
+	}
+}
+
+// Roughly the bytes the graph takes: its nodes, offsets and edges, and the index if IDs aren't positions.
+func (graph *ImmutableGraph) memoryFootprint() int64 {
+	n, m := int64(len(graph.nodes)), int64(len(graph.succs))
+	bytes := 16*n + 8*(n+1) + 12*m
+	if graph.directed {
+		bytes += 8*(n+1) + 12*m
+	}
+	if graph.index != nil {
+		bytes += mapBytes(len(graph.index), 8, 8)
+	}
+	return bytes
+}
+
+// Sorts one node's neighbors by position, their costs along with them
+type byPosition struct {
+	neighbors []int32
+	costs     []float64
+}
+
+func (b byPosition) Len() int {
+	return len(b.neighbors)
+}
+
+func (b byPosition) Less(i, j int) bool {
+	return b.neighbors[i] < b.neighbors[j]
+}
+
+func (b byPosition) Swap(i, j int) {
+	b.neighbors[i], b.neighbors[j] = b.neighbors[j], b.neighbors[i]
+	b.costs[i], b.costs[j] = b.costs[j], b.costs[i]
+}
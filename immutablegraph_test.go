@@ -0,0 +1,121 @@
+package graph_test
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestImmutableGraph(t *testing.T) {
+	directed := randomWeightedGraph(60, 0.08, 4)
+	undirected := graph.NewGonumGraph(false)
+	for _, edge := range directed.EdgeList() {
+		undirected.AddNode(edge.Head(), []graph.Node{edge.Tail()})
+		undirected.SetEdgeCost(edge, directed.Cost(edge.Head(), edge.Tail()))
+	}
+	// IDs that aren't positions, and an isolated node
+	sparse := graph.NewGonumGraph(true)
+	for _, edge := range directed.EdgeList() {
+		head, tail := graph.GonumNode(3*edge.Head().ID()-20), graph.GonumNode(3*edge.Tail().ID()-20)
+		sparse.AddNode(head, []graph.Node{tail})
+		sparse.SetEdgeCost(graph.GonumEdge{H: head, T: tail}, directed.Cost(edge.Head(), edge.Tail()))
+	}
+	sparse.AddNode(graph.GonumNode(1000), nil)
+
+	for name, g := range map[string]*graph.GonumGraph{"directed": directed, "undirected": undirected, "sparse": sparse} {
+		frozen := graph.NewImmutableGraph(g)
+		if frozen.IsDirected() != g.IsDirected() || !reflect.DeepEqual(sortedIDs(frozen.NodeList()), sortedIDs(g.NodeList())) || len(frozen.EdgeList()) != len(g.EdgeList()) {
+			t.Fatalf("%s: froze %d nodes and %d edges from %d and %d", name, len(frozen.NodeList()), len(frozen.EdgeList()), len(g.NodeList()), len(g.EdgeList()))
+		}
+		for _, node := range g.NodeList() {
+			if got, want := nodeIDs(frozen.Successors(node)), sortedIDs(g.Successors(node)); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("%s: %v has the successors %v frozen, %v before", name, node, got, want)
+			}
+			if got, want := nodeIDs(frozen.Predecessors(node)), sortedIDs(g.Predecessors(node)); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("%s: %v has the predecessors %v frozen, %v before", name, node, got, want)
+			}
+			if frozen.Degree(node) != g.Degree(node) {
+				t.Errorf("%s: %v has degree %d frozen, %d before", name, node, frozen.Degree(node), g.Degree(node))
+			}
+			for _, succ := range g.Successors(node) {
+				if !frozen.IsSuccessor(node, succ) || !frozen.IsPredecessor(succ, node) || frozen.Cost(node, succ) != g.Cost(node, succ) {
+					t.Errorf("%s: lost the edge from %v to %v", name, node, succ)
+				}
+			}
+			var visited []graph.Node
+			frozen.VisitSuccessors(node, func(succ graph.Node, cost float64) bool {
+				if cost != g.Cost(node, succ) {
+					t.Errorf("%s: visited %v from %v at %v, expected %v", name, succ, node, cost, g.Cost(node, succ))
+				}
+				visited = append(visited, succ)
+				return true
+			})
+			if fmt.Sprint(nodeIDs(visited)) != fmt.Sprint(nodeIDs(frozen.Successors(node))) {
+				t.Errorf("%s: visited %v from %v, expected %v", name, nodeIDs(visited), node, nodeIDs(frozen.Successors(node)))
+			}
+		}
+		if absent := graph.GonumNode(-1000); frozen.NodeExists(absent) || frozen.Successors(absent) != nil || frozen.IsSuccessor(absent, g.NodeList()[0]) ||
+			!math.IsInf(frozen.Cost(absent, g.NodeList()[0]), 1) {
+			t.Errorf("%s: found a node that isn't there", name)
+		}
+
+		source := g.NodeList()[0]
+		want, got := graph.DijkstraTree(source, g, nil), graph.DijkstraTree(source, frozen, nil)
+		for _, node := range g.NodeList() {
+			if got.DistTo(node) != want.DistTo(node) {
+				t.Errorf("%s: %v is %v from %v frozen, %v before", name, node, got.DistTo(node), source, want.DistTo(node))
+			}
+		}
+		if graph.MemoryFootprint(frozen) >= graph.MemoryFootprint(g) {
+			t.Errorf("%s: frozen graph takes %d bytes, more than the original's %d", name, graph.MemoryFootprint(frozen), graph.MemoryFootprint(g))
+		}
+	}
+}
+
+func sortedIDs(nodes []graph.Node) []int {
+	ids := nodeIDs(nodes)
+	sort.Ints(ids)
+	return ids
+}
+
+func TestDStarOnImmutableGraph(t *testing.T) {
+	tiles := graph.NewTileGraph(20, 20, true)
+	for row := 1; row < 19; row++ {
+		tiles.SetPassability(row, 10, false)
+	}
+	frozen := graph.NewImmutableGraph(tiles)
+	start, goal := tiles.CoordsToNode(10, 0), tiles.CoordsToNode(10, 19)
+
+	_, want, _ := graph.AStar(start, goal, tiles, nil, nil)
+	ds := graph.InitDStar(start, goal, frozen, nil, nil)
+	if path, cost, err := ds.Path(); err != nil || cost != want || len(path) != int(want)+1 {
+		t.Errorf("Expected a path costing %v, got %v costing %v (%v)", want, nodeIDs(path), cost, err)
+	}
+}
+
+func TestDStarScoresOutsideDenseIDs(t *testing.T) {
+	// Dense IDs, so D*-Lite keeps its scores in slices, until a node with a negative ID and one far past the rest join the graph
+	g := graph.NewGonumGraph(true)
+	graph.PathGraph(g, 10, true)
+	start, goal := graph.GonumNode(0), graph.GonumNode(9)
+	ds := graph.InitDStar(start, goal, g, nil, nil)
+
+	detour := []graph.Edge{
+		graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(-5)},
+		graph.GonumEdge{H: graph.GonumNode(-5), T: graph.GonumNode(1000000)},
+		graph.GonumEdge{H: graph.GonumNode(1000000), T: graph.GonumNode(9)},
+	}
+	for _, edge := range detour {
+		g.AddEdge(edge)
+	}
+	g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(4), T: graph.GonumNode(5)}, 100)
+	ds.Update(nil, append(detour, graph.GonumEdge{H: graph.GonumNode(4), T: graph.GonumNode(5)}))
+
+	if path, cost, err := ds.Path(); err != nil || cost != 3 || !reflect.DeepEqual(nodeIDs(path), []int{0, -5, 1000000, 9}) {
+		t.Errorf("Expected the detour through -5 and 1000000, got %v costing %v (%v)", nodeIDs(path), cost, err)
+	}
+}
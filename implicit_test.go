@@ -0,0 +1,117 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"testing"
+)
+
+// An unbounded 4-connected lattice with a wall across x = 3 from y = -4 to 4, defined only by its successor function
+type lattice struct{}
+
+type latticeNode struct{ x, y int }
+
+func (n latticeNode) ID() int {
+	return (n.x+1<<15)<<16 | (n.y + 1<<15)
+}
+
+func (lattice) blocked(x, y int) bool {
+	return x == 3 && y >= -4 && y <= 4
+}
+
+func (l lattice) Successors(node graph.Node) []graph.Node {
+	n := node.(latticeNode)
+	if l.blocked(n.x, n.y) {
+		return nil
+	}
+
+	succs := make([]graph.Node, 0, 4)
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		if x, y := n.x+d[0], n.y+d[1]; !l.blocked(x, y) {
+			succs = append(succs, latticeNode{x, y})
+		}
+	}
+	return succs
+}
+
+func (l lattice) Predecessors(node graph.Node) []graph.Node {
+	return l.Successors(node)
+}
+
+func (lattice) IsDirected() bool {
+	return false
+}
+
+func manhattan(a, b graph.Node) float64 {
+	an, bn := a.(latticeNode), b.(latticeNode)
+	return math.Abs(float64(an.x-bn.x)) + math.Abs(float64(an.y-bn.y))
+}
+
+// IsPath needs a full Graph, so lattice paths are checked step by step
+func isLatticePath(path []graph.Node) bool {
+	for i := 0; i+1 < len(path); i++ {
+		next := path[i+1].(latticeNode)
+		if manhattan(path[i], next) != 1 || (lattice{}).blocked(next.x, next.y) {
+			return false
+		}
+	}
+	return len(path) > 0
+}
+
+func TestImplicitGraphSearches(t *testing.T) {
+	start, goal := latticeNode{0, 0}, latticeNode{6, 0}
+	// Around the wall: 5 up, 6 across, 5 down
+	const want = 16.0
+
+	path, cost, _ := graph.AStar(start, goal, lattice{}, nil, manhattan)
+	if cost != want || !isLatticePath(path) {
+		t.Errorf("AStar found a path of cost %v on the lattice, want %v", cost, want)
+	}
+
+	path, cost, _ = graph.IDAStar(start, goal, lattice{}, nil, manhattan)
+	if cost != want || !isLatticePath(path) {
+		t.Errorf("IDAStar found a path of cost %v on the lattice, want %v", cost, want)
+	}
+	if path[0] != graph.Node(start) || path[len(path)-1] != graph.Node(goal) {
+		t.Errorf("IDAStar path runs from %v to %v", path[0], path[len(path)-1])
+	}
+
+	path, cost, _ = graph.NewPlanner(lattice{}, nil, manhattan).AStar(start, goal)
+	if cost != want || !isLatticePath(path) {
+		t.Errorf("Planner found a path of cost %v on the lattice, want %v", cost, want)
+	}
+
+	ds := graph.InitDStar(start, goal, lattice{}, nil, manhattan)
+	walked := []graph.Node{start}
+	for walked[len(walked)-1].ID() != goal.ID() {
+		if len(walked) > 100 {
+			t.Fatal("D*-Lite is going around in circles on the lattice")
+		}
+		next, err := ds.Step()
+		if err != nil {
+			t.Fatal("D*-Lite failed on the lattice:", err)
+		}
+		walked = append(walked, next)
+	}
+	if len(walked)-1 != int(want) || !isLatticePath(walked) {
+		t.Errorf("D*-Lite walked %d steps on the lattice, want %v", len(walked)-1, want)
+	}
+}
+
+func TestGreedyBestFirst(t *testing.T) {
+	start, goal := latticeNode{0, 0}, latticeNode{6, 0}
+	path, cost, _ := graph.GreedyBestFirst(start, goal, lattice{}, nil, manhattan)
+	if !isLatticePath(path) || path[0] != graph.Node(start) || path[len(path)-1] != graph.Node(goal) {
+		t.Fatalf("GreedyBestFirst found an invalid path around the wall: %v", path)
+	}
+	if cost != float64(len(path)-1) || cost < 16 {
+		t.Errorf("GreedyBestFirst's path has %d steps and cost %v, expected at least 16", len(path)-1, cost)
+	}
+
+	// In the open it walks straight to the goal, expanding only the nodes on its path
+	goal = latticeNode{2, -4}
+	path, cost, expanded := graph.GreedyBestFirst(start, goal, lattice{}, nil, manhattan)
+	if cost != 6 || !isLatticePath(path) || expanded != len(path) {
+		t.Errorf("Expected a direct path of 6 steps expanding 7 nodes, got cost %v expanding %d", cost, expanded)
+	}
+}
@@ -0,0 +1,171 @@
+package graph
+
+// An item in an IndexedHeap: a node and its priority.
+type HeapItem struct {
+	Node
+	Key interface{}
+}
+
+// An IndexedHeap is a min-priority queue of nodes that also keeps track of where each node is in the heap, so a queued node's priority can be changed (Fix) or the node taken
+// out of the queue (Remove) in O(log n) instead of a linear search. This is the decrease-key operation Dijkstra's algorithm, Prim's algorithm and A* are usually described with,
+// and the one D*-Lite can't do without.
+//
+// Priorities can be of any type, since the heap is ordered by the less function it's created with. For instance, a Dijkstra-style queue on float64 distances would be:
+//
+//     NewIndexedHeap(func(a, b HeapItem) bool { return a.Key.(float64) < b.Key.(float64) })
+//
+// A node is identified by its ID, so each node is queued at most once.
+//
+// The heap is binary unless it's created by NewDaryHeap. IndexedHeap is one of the PriorityQueue implementations.
+type IndexedHeap struct {
+	less  func(a, b HeapItem) bool
+	arity int
+	items []HeapItem
+	index map[int]int
+}
+
+// Creates an empty binary IndexedHeap ordered by less, which must be a strict weak ordering. Since less sees the whole items, it can break ties on the nodes as well as the keys.
+func NewIndexedHeap(less func(a, b HeapItem) bool) *IndexedHeap {
+	return NewDaryHeap(2, less)
+}
+
+// Creates an empty IndexedHeap in which every item has up to d children (d >= 2). A wider heap is shallower, so pushes and decreases sift up through fewer levels, at the cost
+// of comparing d children on the way down; 4 is usually the sweet spot, since the children tend to share a cache line.
+func NewDaryHeap(d int, less func(a, b HeapItem) bool) *IndexedHeap {
+	if d < 2 {
+		panic("A heap's arity must be at least 2")
+	}
+
+	return &IndexedHeap{less: less, arity: d, index: make(map[int]int)}
+}
+
+func (h *IndexedHeap) Len() int {
+	return len(h.items)
+}
+
+// Whether node is queued.
+func (h *IndexedHeap) Contains(node Node) bool {
+	_, ok := h.index[node.ID()]
+	return ok
+}
+
+// Returns the priority node is queued with, and whether it's queued at all.
+func (h *IndexedHeap) Key(node Node) (key interface{}, ok bool) {
+	if i, ok := h.index[node.ID()]; ok {
+		return h.items[i].Key, true
+	}
+
+	return nil, false
+}
+
+// Queues node with priority key. If node is already queued this is the same as Fix.
+func (h *IndexedHeap) Push(node Node, key interface{}) {
+	if h.Fix(node, key) {
+		return
+	}
+
+	h.items = append(h.items, HeapItem{Node: node, Key: key})
+	h.index[node.ID()] = len(h.items) - 1
+	h.up(len(h.items) - 1)
+}
+
+// Changes the priority of a queued node to key, whether it went up or down. Returns false, doing nothing, if node isn't queued.
+func (h *IndexedHeap) Fix(node Node, key interface{}) bool {
+	i, ok := h.index[node.ID()]
+	if !ok {
+		return false
+	}
+
+	h.items[i].Key = key
+	if !h.down(i) {
+		h.up(i)
+	}
+	return true
+}
+
+// Returns the item with the lowest priority without removing it. Panics if the heap is empty.
+func (h *IndexedHeap) Peek() HeapItem {
+	return h.items[0]
+}
+
+// Removes and returns the item with the lowest priority. Panics if the heap is empty.
+func (h *IndexedHeap) Pop() HeapItem {
+	return h.removeAt(0)
+}
+
+// Takes node out of the queue, returning false if it wasn't queued.
+func (h *IndexedHeap) Remove(node Node) bool {
+	i, ok := h.index[node.ID()]
+	if !ok {
+		return false
+	}
+
+	h.removeAt(i)
+	return true
+}
+
+// Empties the heap, keeping its allocated capacity.
+func (h *IndexedHeap) Clear() {
+	for id := range h.index {
+		delete(h.index, id)
+	}
+	h.items = h.items[:0]
+}
+
+// Returns the queued items in heap order (so the first is the minimum, but the rest aren't sorted). Pushing them in this order into an empty heap of the same arity rebuilds exactly the same heap.
+// The slice is the heap's own, and is only valid until the heap is next changed.
+func (h *IndexedHeap) Items() []HeapItem {
+	return h.items
+}
+
+func (h *IndexedHeap) removeAt(i int) HeapItem {
+	item := h.items[i]
+	last := len(h.items) - 1
+	if i != last {
+		h.swap(i, last)
+	}
+	h.items = h.items[:last]
+	delete(h.index, item.ID())
+
+	if i != last && !h.down(i) {
+		h.up(i)
+	}
+	return item
+}
+
+func (h *IndexedHeap) swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].ID()] = i
+	h.index[h.items[j].ID()] = j
+}
+
+func (h *IndexedHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / h.arity
+		if !h.less(h.items[i], h.items[parent]) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+// Returns whether the item moved
+func (h *IndexedHeap) down(i int) bool {
+	start := i
+	for {
+		min := i
+		for child := h.arity*i + 1; child <= h.arity*i+h.arity && child < len(h.items); child++ {
+			if h.less(h.items[child], h.items[min]) {
+				min = child
+			}
+		}
+		if min == i {
+			break
+		}
+		h.swap(i, min)
+		i = min
+	}
+
+	return i != start
+}
@@ -0,0 +1,63 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+)
+
+func TestIndexedHeap(t *testing.T) {
+	h := graph.NewIndexedHeap(func(a, b graph.HeapItem) bool {
+		return a.Key.(int) < b.Key.(int) || a.Key.(int) == b.Key.(int) && a.ID() < b.ID()
+	})
+	src := rand.New(rand.NewSource(1))
+	expect := make(map[int]int) // What should be queued, by ID
+
+	for i := 0; i < 5000; i++ {
+		node := graph.GonumNode(src.Intn(100))
+		switch op := src.Intn(4); {
+		case op < 2:
+			key := src.Intn(1000)
+			h.Push(node, key)
+			expect[node.ID()] = key
+		case op == 2:
+			_, queued := expect[node.ID()]
+			if removed := h.Remove(node); removed != queued {
+				t.Fatalf("Remove(%v) returned %v, but the node queued is %v", node, removed, queued)
+			}
+			delete(expect, node.ID())
+		default:
+			if h.Len() == 0 {
+				continue
+			}
+			min := -1
+			for id, key := range expect {
+				if min == -1 || key < expect[min] || key == expect[min] && id < min {
+					min = id
+				}
+			}
+			if item := h.Pop(); item.ID() != min || item.Key.(int) != expect[min] {
+				t.Fatalf("Popped %v with key %v, expected %d with key %d", item.Node, item.Key, min, expect[min])
+			}
+			delete(expect, min)
+		}
+
+		if h.Len() != len(expect) {
+			t.Fatalf("Heap has %d items, expected %d", h.Len(), len(expect))
+		}
+	}
+
+	for id, key := range expect {
+		if got, ok := h.Key(graph.GonumNode(id)); !ok || got.(int) != key || !h.Contains(graph.GonumNode(id)) {
+			t.Errorf("Node %d has key %v (queued: %v), expected %d", id, got, ok, key)
+		}
+	}
+	if h.Fix(graph.GonumNode(1000), 1) || h.Contains(graph.GonumNode(1000)) {
+		t.Error("Fix queued a node that wasn't queued")
+	}
+
+	h.Clear()
+	if h.Len() != 0 || len(h.Items()) != 0 || h.Contains(graph.GonumNode(0)) {
+		t.Error("Clear left items in the heap")
+	}
+}
@@ -0,0 +1,368 @@
+package graph
+
+import (
+	"sort"
+)
+
+// The kind of mapping a VF2 matcher searches for between the pattern graph and the target graph.
+type IsomorphismMode int
+
+const (
+	// Both graphs must be identical up to node relabeling; every node and edge of each graph is accounted for in the mapping
+	GraphIsomorphism IsomorphismMode = iota
+	// The pattern must be isomorphic to an induced subgraph of the target. That is, two mapped target nodes are connected if and only if their pattern nodes are connected
+	InducedSubgraphIsomorphism
+	// The pattern must be isomorphic to some (not necessarily induced) subgraph of the target. Extra edges between mapped target nodes are allowed. This is usually what you want for pattern mining
+	SubgraphMonomorphism
+)
+
+// A VF2 is an iterator over the mappings between a pattern graph and a target graph, as found by the VF2 algorithm[1].
+// It extends partial mappings one pattern node at a time, pruning any candidate pair which can't possibly be extended into a full mapping.
+// The pattern nodes are matched in a fixed order (a breadth first order starting from the highest degree nodes) so that, wherever possible,
+// candidates only need to be drawn from the neighbors of an already mapped node.
+//
+// Since the matching is done lazily, the caller can stop at the first match or enumerate all of them:
+//
+//	vf2 := NewVF2(target, pattern, SubgraphMonomorphism, nil, nil)
+//	for vf2.Next() {
+//	    mapping := vf2.Mapping()
+//	    ... use mapping ...
+//	}
+//
+// NodeMatch and EdgeMatch are compatibility predicates. If NodeMatch is non-nil, a target node may only be mapped to a pattern node if NodeMatch(targetNode, patternNode) is true.
+// Likewise, if EdgeMatch is non-nil, a pattern edge may only be mapped onto a target edge if EdgeMatch(targetEdge, patternEdge) is true. If either is nil, all nodes (or edges) are considered compatible.
+//
+// Neither graph should be modified while a VF2 is in use.
+//
+// [1] L. P. Cordella, P. Foggia, C. Sansone, M. Vento, "A (Sub)Graph Isomorphism Algorithm for Matching Large Graphs", IEEE PAMI 26(10), 2004
+type VF2 struct {
+	target, pattern Graph
+	mode            IsomorphismMode
+	nodeMatch       func(Node, Node) bool
+	edgeMatch       func(Edge, Edge) bool
+
+	targetNodes []Node
+	order       []Node
+	parent      []Node // The earlier neighbor each node in order is drawn from, or nil
+	parentIsSrc []bool // True if order[i] is a successor of parent[i], false if it's a predecessor
+
+	// Pattern node ID -> target node, and target node ID -> pattern node
+	core2, core1 map[int]Node
+
+	inDeg1, outDeg1, inDeg2, outDeg2 map[int]int
+
+	candidates [][]Node
+	pos        []int
+	depth      int
+	started    bool
+	done       bool
+}
+
+// Creates an iterator over all the mappings from the nodes of pattern to the nodes of target under the given mode. The match predicates are optional, see VF2 for details.
+func NewVF2(target, pattern Graph, mode IsomorphismMode, NodeMatch func(Node, Node) bool, EdgeMatch func(Edge, Edge) bool) *VF2 {
+	return &VF2{
+		target:    target,
+		pattern:   pattern,
+		mode:      mode,
+		nodeMatch: NodeMatch,
+		edgeMatch: EdgeMatch,
+	}
+}
+
+// Advances the iterator to the next mapping, returning false if there are none left.
+func (vf2 *VF2) Next() bool {
+	if vf2.done {
+		return false
+	}
+
+	if !vf2.started {
+		vf2.started = true
+		if !vf2.init() {
+			vf2.done = true
+			return false
+		}
+
+		// The empty pattern has exactly one (empty) mapping
+		if len(vf2.order) == 0 {
+			return true
+		}
+	} else if len(vf2.order) == 0 {
+		vf2.done = true
+		return false
+	} else {
+		// Undo the last node of the previously returned match so we can try its next candidate
+		vf2.unmap(vf2.order[vf2.depth])
+	}
+
+	for vf2.depth >= 0 {
+		if vf2.pos[vf2.depth] >= len(vf2.candidates[vf2.depth]) {
+			vf2.depth--
+			if vf2.depth >= 0 {
+				vf2.unmap(vf2.order[vf2.depth])
+			}
+			continue
+		}
+
+		cand := vf2.candidates[vf2.depth][vf2.pos[vf2.depth]]
+		vf2.pos[vf2.depth]++
+
+		p := vf2.order[vf2.depth]
+		if !vf2.feasible(p, cand) {
+			continue
+		}
+
+		vf2.core2[p.ID()] = cand
+		vf2.core1[cand.ID()] = p
+
+		if vf2.depth == len(vf2.order)-1 {
+			return true
+		}
+
+		vf2.depth++
+		vf2.candidates[vf2.depth] = vf2.candidatesFor(vf2.depth)
+		vf2.pos[vf2.depth] = 0
+	}
+
+	vf2.done = true
+	return false
+}
+
+// Returns the current mapping as a map from each pattern node's ID to the target node it is matched with. The returned map is a fresh copy the caller is free to keep or modify.
+//
+// Calling Mapping before Next has returned true, or after it has returned false, returns nil.
+func (vf2 *VF2) Mapping() map[int]Node {
+	if !vf2.started || vf2.done {
+		return nil
+	}
+
+	mapping := make(map[int]Node, len(vf2.core2))
+	for id, node := range vf2.core2 {
+		mapping[id] = node
+	}
+
+	return mapping
+}
+
+func (vf2 *VF2) init() bool {
+	vf2.targetNodes = vf2.target.NodeList()
+	patternNodes := vf2.pattern.NodeList()
+
+	if len(patternNodes) > len(vf2.targetNodes) {
+		return false
+	}
+	if vf2.mode == GraphIsomorphism {
+		if len(patternNodes) != len(vf2.targetNodes) || len(vf2.pattern.EdgeList()) != len(vf2.target.EdgeList()) {
+			return false
+		}
+	}
+
+	vf2.inDeg1, vf2.outDeg1 = degreeMaps(vf2.target, vf2.targetNodes)
+	vf2.inDeg2, vf2.outDeg2 = degreeMaps(vf2.pattern, patternNodes)
+
+	vf2.order, vf2.parent, vf2.parentIsSrc = vf2.matchOrder(patternNodes)
+	vf2.core1 = make(map[int]Node, len(patternNodes))
+	vf2.core2 = make(map[int]Node, len(patternNodes))
+	vf2.candidates = make([][]Node, len(vf2.order))
+	vf2.pos = make([]int, len(vf2.order))
+
+	if len(vf2.order) > 0 {
+		vf2.candidates[0] = vf2.candidatesFor(0)
+	}
+
+	return true
+}
+
+func degreeMaps(graph Graph, nodes []Node) (in, out map[int]int) {
+	in, out = make(map[int]int, len(nodes)), make(map[int]int, len(nodes))
+	for _, node := range nodes {
+		in[node.ID()] = len(graph.Predecessors(node))
+		out[node.ID()] = len(graph.Successors(node))
+	}
+
+	return in, out
+}
+
+// Computes a breadth first ordering of the pattern that starts each connected component at its highest degree node, along with the already ordered neighbor every subsequent node
+// can be drawn from.
+func (vf2 *VF2) matchOrder(nodes []Node) (order, parent []Node, parentIsSrc []bool) {
+	sorted := make([]Node, len(nodes))
+	copy(sorted, nodes)
+	sort.Sort(byDegree{nodes: sorted, in: vf2.inDeg2, out: vf2.outDeg2})
+
+	order = make([]Node, 0, len(nodes))
+	parent = make([]Node, 0, len(nodes))
+	parentIsSrc = make([]bool, 0, len(nodes))
+	seen := make(map[int]bool, len(nodes))
+
+	for _, root := range sorted {
+		if seen[root.ID()] {
+			continue
+		}
+
+		seen[root.ID()] = true
+		order, parent, parentIsSrc = append(order, root), append(parent, nil), append(parentIsSrc, false)
+		for i := len(order) - 1; i < len(order); i++ {
+			curr := order[i]
+			for _, succ := range vf2.pattern.Successors(curr) {
+				if !seen[succ.ID()] {
+					seen[succ.ID()] = true
+					order, parent, parentIsSrc = append(order, succ), append(parent, curr), append(parentIsSrc, true)
+				}
+			}
+			for _, pred := range vf2.pattern.Predecessors(curr) {
+				if !seen[pred.ID()] {
+					seen[pred.ID()] = true
+					order, parent, parentIsSrc = append(order, pred), append(parent, curr), append(parentIsSrc, false)
+				}
+			}
+		}
+	}
+
+	return order, parent, parentIsSrc
+}
+
+func (vf2 *VF2) candidatesFor(depth int) []Node {
+	par := vf2.parent[depth]
+	if par == nil {
+		return vf2.targetNodes
+	}
+
+	image := vf2.core2[par.ID()]
+	if vf2.parentIsSrc[depth] {
+		return vf2.target.Successors(image)
+	}
+
+	return vf2.target.Predecessors(image)
+}
+
+func (vf2 *VF2) unmap(p Node) {
+	if t, ok := vf2.core2[p.ID()]; ok {
+		delete(vf2.core1, t.ID())
+		delete(vf2.core2, p.ID())
+	}
+}
+
+// Determines whether pattern node p can be mapped to target node t given the current partial mapping.
+func (vf2 *VF2) feasible(p, t Node) bool {
+	if _, ok := vf2.core1[t.ID()]; ok {
+		return false
+	}
+
+	pin, pout := vf2.inDeg2[p.ID()], vf2.outDeg2[p.ID()]
+	tin, tout := vf2.inDeg1[t.ID()], vf2.outDeg1[t.ID()]
+	if vf2.mode == GraphIsomorphism {
+		if pin != tin || pout != tout {
+			return false
+		}
+	} else if pin > tin || pout > tout {
+		return false
+	}
+
+	if vf2.nodeMatch != nil && !vf2.nodeMatch(t, p) {
+		return false
+	}
+
+	// Self loops aren't covered by the neighbor checks below since p isn't mapped yet
+	pLoop, tLoop := vf2.pattern.IsSuccessor(p, p), vf2.target.IsSuccessor(t, t)
+	if pLoop && !tLoop || tLoop && !pLoop && vf2.mode != SubgraphMonomorphism {
+		return false
+	}
+	if pLoop && !vf2.edgesMatch(t, t, p, p) {
+		return false
+	}
+
+	// Every pattern edge to a mapped node must exist in the target
+	pUnmapped := 0
+	for _, succ := range vf2.pattern.Successors(p) {
+		if image, ok := vf2.core2[succ.ID()]; ok {
+			if !vf2.target.IsSuccessor(t, image) || !vf2.edgesMatch(t, image, p, succ) {
+				return false
+			}
+		} else if succ.ID() != p.ID() {
+			pUnmapped++
+		}
+	}
+	for _, pred := range vf2.pattern.Predecessors(p) {
+		if image, ok := vf2.core2[pred.ID()]; ok {
+			if !vf2.target.IsSuccessor(image, t) || !vf2.edgesMatch(image, t, pred, p) {
+				return false
+			}
+		} else if pred.ID() != p.ID() {
+			pUnmapped++
+		}
+	}
+
+	// Unless we're looking for a monomorphism, every target edge to a mapped node must exist in the pattern too
+	tUnmapped := 0
+	for _, succ := range vf2.target.Successors(t) {
+		if pre, ok := vf2.core1[succ.ID()]; ok {
+			if vf2.mode != SubgraphMonomorphism && !vf2.pattern.IsSuccessor(p, pre) {
+				return false
+			}
+		} else if succ.ID() != t.ID() {
+			tUnmapped++
+		}
+	}
+	for _, pred := range vf2.target.Predecessors(t) {
+		if pre, ok := vf2.core1[pred.ID()]; ok {
+			if vf2.mode != SubgraphMonomorphism && !vf2.pattern.IsSuccessor(pre, p) {
+				return false
+			}
+		} else if pred.ID() != t.ID() {
+			tUnmapped++
+		}
+	}
+
+	// Look ahead: the unmapped neighbors of p need distinct unmapped neighbors of t to go to
+	if vf2.mode == GraphIsomorphism {
+		return pUnmapped == tUnmapped
+	}
+
+	return pUnmapped <= tUnmapped
+}
+
+func (vf2 *VF2) edgesMatch(th, tt, ph, pt Node) bool {
+	if vf2.edgeMatch == nil {
+		return true
+	}
+
+	return vf2.edgeMatch(GonumEdge{H: th, T: tt}, GonumEdge{H: ph, T: pt})
+}
+
+type byDegree struct {
+	nodes   []Node
+	in, out map[int]int
+}
+
+func (bd byDegree) Len() int {
+	return len(bd.nodes)
+}
+
+func (bd byDegree) Less(i, j int) bool {
+	di := bd.in[bd.nodes[i].ID()] + bd.out[bd.nodes[i].ID()]
+	dj := bd.in[bd.nodes[j].ID()] + bd.out[bd.nodes[j].ID()]
+	if di != dj {
+		return di > dj
+	}
+
+	return bd.nodes[i].ID() < bd.nodes[j].ID()
+}
+
+func (bd byDegree) Swap(i, j int) {
+	bd.nodes[i], bd.nodes[j] = bd.nodes[j], bd.nodes[i]
+}
+
+// Returns true if the two graphs are isomorphic, respecting the (optional) compatibility predicates. See VF2 for details.
+func Isomorphic(g1, g2 Graph, NodeMatch func(Node, Node) bool, EdgeMatch func(Edge, Edge) bool) bool {
+	return NewVF2(g1, g2, GraphIsomorphism, NodeMatch, EdgeMatch).Next()
+}
+
+// Returns the first mapping from the nodes of pattern to an induced subgraph of target, or nil if the pattern doesn't appear in target. See VF2 for details.
+func SubgraphIsomorphism(target, pattern Graph, NodeMatch func(Node, Node) bool, EdgeMatch func(Edge, Edge) bool) map[int]Node {
+	vf2 := NewVF2(target, pattern, InducedSubgraphIsomorphism, NodeMatch, EdgeMatch)
+	if !vf2.Next() {
+		return nil
+	}
+
+	return vf2.Mapping()
+}
@@ -0,0 +1,86 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+// Builds a GonumGraph from a list of [head, tail] ID pairs
+func buildGraph(directed bool, edges [][2]int) *graph.GonumGraph {
+	g := graph.NewGonumGraph(directed)
+	for _, e := range edges {
+		if !g.NodeExists(graph.GonumNode(e[0])) {
+			g.AddNode(graph.GonumNode(e[0]), nil)
+		}
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+
+	return g
+}
+
+func countMappings(vf2 *graph.VF2) int {
+	count := 0
+	for vf2.Next() {
+		count++
+	}
+
+	return count
+}
+
+func TestIsomorphic(t *testing.T) {
+	square := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+	relabeled := buildGraph(false, [][2]int{{10, 12}, {12, 11}, {11, 13}, {13, 10}})
+	path := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}})
+
+	if !graph.Isomorphic(square, relabeled, nil, nil) {
+		t.Error("Relabeled 4-cycle not reported as isomorphic")
+	}
+	if graph.Isomorphic(square, path, nil, nil) {
+		t.Error("4-cycle and 4-path reported as isomorphic")
+	}
+
+	// The dihedral group of the square has 8 elements
+	if n := countMappings(graph.NewVF2(square, square, graph.GraphIsomorphism, nil, nil)); n != 8 {
+		t.Errorf("Wrong number of automorphisms of the 4-cycle; got %d, expected 8", n)
+	}
+
+	// Direction matters for directed graphs
+	cycle := buildGraph(true, [][2]int{{0, 1}, {1, 2}, {2, 0}})
+	notCycle := buildGraph(true, [][2]int{{0, 1}, {1, 2}, {0, 2}})
+	if graph.Isomorphic(cycle, notCycle, nil, nil) {
+		t.Error("Directed 3-cycle and transitive triangle reported as isomorphic")
+	}
+
+	odd := func(target, pattern graph.Node) bool {
+		return target.ID()%2 == pattern.ID()%2
+	}
+	if n := countMappings(graph.NewVF2(square, square, graph.GraphIsomorphism, odd, nil)); n != 4 {
+		t.Errorf("Node predicate not respected; got %d mappings, expected 4", n)
+	}
+}
+
+func TestSubgraphIsomorphism(t *testing.T) {
+	k4 := buildGraph(false, [][2]int{{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}})
+	triangle := buildGraph(false, [][2]int{{5, 6}, {6, 7}, {7, 5}})
+	path := buildGraph(false, [][2]int{{5, 6}, {6, 7}})
+
+	if n := countMappings(graph.NewVF2(k4, triangle, graph.SubgraphMonomorphism, nil, nil)); n != 24 {
+		t.Errorf("Wrong number of triangles in K4; got %d, expected 24", n)
+	}
+	if n := countMappings(graph.NewVF2(k4, path, graph.SubgraphMonomorphism, nil, nil)); n != 24 {
+		t.Errorf("Wrong number of 3-paths in K4; got %d, expected 24", n)
+	}
+	if mapping := graph.SubgraphIsomorphism(k4, path, nil, nil); mapping != nil {
+		t.Error("Found an induced 3-path in K4:", mapping)
+	}
+
+	mapping := graph.SubgraphIsomorphism(k4, triangle, nil, nil)
+	if len(mapping) != 3 {
+		t.Fatal("Failed to find triangle in K4")
+	}
+	for _, e := range triangle.EdgeList() {
+		if !k4.IsSuccessor(mapping[e.Head().ID()], mapping[e.Tail().ID()]) {
+			t.Error("Mapping doesn't preserve edge", e)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"math"
+	"math/big"
+)
+
+// Returns the weighted Laplacian matrix of the undirected graph underlying graph, L = D - A, with the nodes in order of ID as returned: the diagonal holds each node's weighted
+// degree, and L[i][j] is minus the weight of the edge between nodes i and j, if any. Weight is interpreted as Cost is in AStar; self loops are left out, since they don't change
+// the Laplacian's quadratic form.
+func LaplacianMatrix(graph Graph, Weight func(Node, Node) float64) (matrix [][]float64, nodes []Node) {
+	l := newLaplacian(graph, Weight)
+	return l.dense(), l.nodes
+}
+
+// Counts the spanning trees of the undirected graph underlying graph by Kirchhoff's matrix tree theorem: the count is the determinant of the Laplacian with any one node's row
+// and column removed. With weights (interpreted as Cost is in AStar), it's the sum over spanning trees of the product of their edges' weights, which is the count when they're
+// all 1. Zero if graph isn't connected (or is empty). The determinant is taken by Gaussian elimination with partial pivoting in O(n³); counts grow exponentially, so past a
+// few hundred nodes this overflows to +Inf, and long before then it's only good to about the precision of a float64. See SpanningTreeCountBig and ExactSpanningTreeCount.
+func SpanningTreeCount(graph Graph, Weight func(Node, Node) float64) float64 {
+	l := newLaplacian(graph, Weight)
+	if len(l.components) != 1 {
+		return 0
+	}
+	reduced := l.dense()[1:]
+	for i := range reduced {
+		reduced[i] = reduced[i][1:]
+	}
+
+	n := len(reduced)
+	det := 1.0
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(reduced[i][k]) > math.Abs(reduced[pivot][k]) {
+				pivot = i
+			}
+		}
+		if reduced[pivot][k] == 0 {
+			return 0
+		}
+		if pivot != k {
+			reduced[pivot], reduced[k] = reduced[k], reduced[pivot]
+			det = -det
+		}
+		det *= reduced[k][k]
+		for i := k + 1; i < n; i++ {
+			f := reduced[i][k] / reduced[k][k]
+			for j := k; j < n; j++ {
+				reduced[i][j] -= f * reduced[k][j]
+			}
+		}
+	}
+
+	return det
+}
+
+// Counts the spanning trees of the undirected graph underlying graph, as SpanningTreeCount does, but with big.Floats of the given precision in bits, so the count doesn't
+// overflow, and is good to as many digits as asked for.
+func SpanningTreeCountBig(graph Graph, Weight func(Node, Node) float64, prec uint) *big.Float {
+	l := newLaplacian(graph, Weight)
+	det := new(big.Float).SetPrec(prec)
+	if len(l.components) != 1 {
+		return det
+	}
+	matrix := l.dense()
+
+	n := len(matrix) - 1
+	reduced := make([][]*big.Float, n)
+	for i := range reduced {
+		reduced[i] = make([]*big.Float, n)
+		for j := range reduced[i] {
+			reduced[i][j] = new(big.Float).SetPrec(prec).SetFloat64(matrix[i+1][j+1])
+		}
+	}
+
+	det.SetInt64(1)
+	f, t := new(big.Float).SetPrec(prec), new(big.Float).SetPrec(prec)
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if new(big.Float).Abs(reduced[i][k]).Cmp(new(big.Float).Abs(reduced[pivot][k])) > 0 {
+				pivot = i
+			}
+		}
+		if reduced[pivot][k].Sign() == 0 {
+			return det.SetInt64(0)
+		}
+		if pivot != k {
+			reduced[pivot], reduced[k] = reduced[k], reduced[pivot]
+			det.Neg(det)
+		}
+		det.Mul(det, reduced[k][k])
+		for i := k + 1; i < n; i++ {
+			f.Quo(reduced[i][k], reduced[k][k])
+			for j := k; j < n; j++ {
+				reduced[i][j].Sub(reduced[i][j], t.Mul(f, reduced[k][j]))
+			}
+		}
+	}
+
+	return det
+}
+
+// Counts the spanning trees of the undirected graph underlying graph exactly, ignoring costs, by taking the determinant of the reduced Laplacian, which is all integers, with
+// Bareiss's fraction free elimination[1]: every intermediate value is itself a determinant of a submatrix, so it's exact in O(n³) operations on integers no longer than the
+// answer.
+//
+// [1] E. H. Bareiss, "Sylvester's identity and multistep integer-preserving Gaussian elimination", Mathematics of Computation 22 (1968)
+func ExactSpanningTreeCount(graph Graph) *big.Int {
+	l := newLaplacian(graph, UniformCost)
+	if len(l.components) != 1 {
+		return new(big.Int)
+	}
+	matrix := l.dense()
+
+	n := len(matrix) - 1
+	reduced := make([][]*big.Int, n)
+	for i := range reduced {
+		reduced[i] = make([]*big.Int, n)
+		for j := range reduced[i] {
+			reduced[i][j] = big.NewInt(int64(matrix[i+1][j+1]))
+		}
+	}
+
+	sign := 1
+	prev := big.NewInt(1)
+	a, b := new(big.Int), new(big.Int)
+	for k := 0; k < n; k++ {
+		if reduced[k][k].Sign() == 0 {
+			pivot := -1
+			for i := k + 1; i < n && pivot == -1; i++ {
+				if reduced[i][k].Sign() != 0 {
+					pivot = i
+				}
+			}
+			if pivot == -1 {
+				return new(big.Int)
+			}
+			reduced[pivot], reduced[k] = reduced[k], reduced[pivot]
+			sign = -sign
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				a.Mul(reduced[i][j], reduced[k][k])
+				b.Mul(reduced[i][k], reduced[k][j])
+				reduced[i][j].Quo(a.Sub(a, b), prev)
+			}
+		}
+		prev = reduced[k][k]
+	}
+
+	if n == 0 {
+		return big.NewInt(1)
+	}
+	det := new(big.Int).Set(reduced[n-1][n-1])
+	if sign < 0 {
+		det.Neg(det)
+	}
+	return det
+}
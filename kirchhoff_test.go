@@ -0,0 +1,74 @@
+package graph_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestSpanningTreeCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		fill     func(graph.MutableGraph)
+		expected int64
+	}{
+		// Cayley's formula, n^(n-2)
+		{"K6", func(g graph.MutableGraph) { graph.CompleteGraph(g, 6, false) }, 1296},
+		{"C7", func(g graph.MutableGraph) { graph.CycleGraph(g, 7, false) }, 7},
+		{"P5", func(g graph.MutableGraph) { graph.PathGraph(g, 5, false) }, 1},
+		// m^(n-1) n^(m-1)
+		{"K3,4", func(g graph.MutableGraph) { graph.CompleteBipartiteGraph(g, 3, 4) }, 432},
+		{"disconnected", func(g graph.MutableGraph) {
+			graph.PathGraph(g, 3, false)
+			g.AddNode(graph.GonumNode(3), nil)
+		}, 0},
+	}
+	for _, test := range tests {
+		g := graph.NewGonumGraph(false)
+		test.fill(g)
+		if count := graph.SpanningTreeCount(g, nil); math.Abs(count-float64(test.expected)) > 1e-6*float64(test.expected+1) {
+			t.Errorf("%s: expected %d spanning trees, got %v", test.name, test.expected, count)
+		}
+		if count, _ := graph.SpanningTreeCountBig(g, nil, 128).Float64(); math.Abs(count-float64(test.expected)) > 1e-20*float64(test.expected+1) {
+			t.Errorf("%s: expected %d spanning trees, got %v with big.Floats", test.name, test.expected, count)
+		}
+		if count := graph.ExactSpanningTreeCount(g); count.Cmp(big.NewInt(test.expected)) != 0 {
+			t.Errorf("%s: expected exactly %d spanning trees, got %v", test.name, test.expected, count)
+		}
+	}
+}
+
+func TestSpanningTreeCountLarge(t *testing.T) {
+	// K60 has 60^58 spanning trees, which a float64 can only approximate
+	g := graph.NewGonumGraph(false)
+	graph.CompleteGraph(g, 60, false)
+	expected := new(big.Int).Exp(big.NewInt(60), big.NewInt(58), nil)
+	if count := graph.ExactSpanningTreeCount(g); count.Cmp(expected) != 0 {
+		t.Errorf("Expected exactly %v spanning trees, got %v", expected, count)
+	}
+	count := graph.SpanningTreeCountBig(g, nil, 512)
+	if diff := new(big.Float).Sub(count, new(big.Float).SetInt(expected)); new(big.Float).Abs(diff).Cmp(big.NewFloat(1)) > 0 {
+		t.Errorf("Expected %v spanning trees, got %v", expected, count)
+	}
+	if f, _ := new(big.Float).SetInt(expected).Float64(); math.Abs(graph.SpanningTreeCount(g, nil)-f) > 1e-9*f {
+		t.Errorf("Expected about %v spanning trees, got %v", f, graph.SpanningTreeCount(g, nil))
+	}
+}
+
+func TestWeightedSpanningTreeCount(t *testing.T) {
+	// A triangle's trees are its pairs of edges, so the weighted count is ab + bc + ca
+	g := graph.NewGonumGraph(false)
+	graph.CycleGraph(g, 3, false)
+	weights := map[[2]int]float64{{0, 1}: 2, {1, 2}: 3, {0, 2}: 5}
+	w := func(u, v graph.Node) float64 {
+		if u.ID() > v.ID() {
+			u, v = v, u
+		}
+		return weights[[2]int{u.ID(), v.ID()}]
+	}
+	if count := graph.SpanningTreeCount(g, w); math.Abs(count-31) > 1e-9 {
+		t.Errorf("Expected a weighted count of 31, got %v", count)
+	}
+}
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// Returned when there's no path at all between the nodes asked about.
+var ErrNoPath = errors.New("No path exists")
+
+// Finds the k shortest loopless paths from start to goal by Yen's algorithm[1], in order of cost, along with their costs. Fewer are returned if there aren't k, and
+// ErrNoPath if there are none. Cost is interpreted as in AStar, and must not be negative. Ties between paths of equal cost are broken the same way every time.
+//
+// Each path after the first is found by deviating from one already found: for each node of the last path, the spur node, a shortest path is found from it to the goal with
+// the nodes before it on the path masked out, along with the next edge of every path found so far that shares the same beginning, so the deviation is new and loopless. The
+// cheapest deviation found so far becomes the next path. That's a shortest path search per node of each path, O(k n (m + n log n)) in all.
+//
+// [1] J. Y. Yen, "Finding the k shortest loopless paths in a network", Management Science 17 (1971)
+func KShortestPaths(start, goal Node, graph Graph, k int, Cost func(Node, Node) float64) (paths [][]Node, costs []float64, err error) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if k < 1 {
+		return nil, nil, nil
+	}
+
+	masked := &maskedGraph{graph: graph, nodes: make(map[int]bool), edges: make(map[[2]int]bool)}
+	first, cost, _ := UniformCostSearch(start, goal, masked, Cost)
+	if first == nil {
+		return nil, nil, ErrNoPath
+	}
+	paths, costs = [][]Node{first}, []float64{cost}
+
+	type candidate struct {
+		path []Node
+		cost float64
+	}
+	var candidates []candidate
+	seen := map[string]bool{pathKey(first): true}
+	for len(paths) < k {
+		last := paths[len(paths)-1]
+		rootCost := 0.0
+		for i := 0; i < len(last)-1; i++ {
+			spur, root := last[i], last[:i+1]
+
+			for id := range masked.nodes {
+				delete(masked.nodes, id)
+			}
+			for edge := range masked.edges {
+				delete(masked.edges, edge)
+			}
+			for _, node := range root[:i] {
+				masked.nodes[node.ID()] = true
+			}
+			for _, path := range paths {
+				if len(path) > i+1 && samePrefix(path, root) {
+					masked.edges[[2]int{path[i].ID(), path[i+1].ID()}] = true
+				}
+			}
+
+			if deviation, cost, _ := UniformCostSearch(spur, goal, masked, Cost); deviation != nil {
+				path := append(append([]Node(nil), root[:i]...), deviation...)
+				if key := pathKey(path); !seen[key] {
+					seen[key] = true
+					candidates = append(candidates, candidate{path, rootCost + cost})
+				}
+			}
+			rootCost += Cost(last[i], last[i+1])
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		best := 0
+		for i, c := range candidates {
+			if c.cost < candidates[best].cost || c.cost == candidates[best].cost && lessPath(c.path, candidates[best].path) {
+				best = i
+			}
+		}
+		paths, costs = append(paths, candidates[best].path), append(costs, candidates[best].cost)
+		candidates[best] = candidates[len(candidates)-1]
+		candidates = candidates[:len(candidates)-1]
+	}
+
+	return paths, costs, nil
+}
+
+// A graph with some nodes and edges hidden, and successors in order of ID so searches of it go the same way every time
+type maskedGraph struct {
+	graph Graph
+	nodes map[int]bool
+	edges map[[2]int]bool
+}
+
+func (g *maskedGraph) Successors(node Node) []Node {
+	var succs []Node
+	for _, succ := range g.graph.Successors(node) {
+		if !g.nodes[succ.ID()] && !g.edges[[2]int{node.ID(), succ.ID()}] {
+			succs = append(succs, succ)
+		}
+	}
+	sort.Sort(byID(succs))
+	return succs
+}
+
+// Whether path starts with prefix
+func samePrefix(path, prefix []Node) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i, node := range prefix {
+		if path[i].ID() != node.ID() {
+			return false
+		}
+	}
+	return true
+}
+
+// Whether a comes before b, comparing IDs in turn
+func lessPath(a, b []Node) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].ID() != b[i].ID() {
+			return a[i].ID() < b[i].ID()
+		}
+	}
+	return len(a) < len(b)
+}
+
+func pathKey(path []Node) string {
+	key := make([]byte, 0, 8*len(path))
+	for _, node := range path {
+		key = strconv.AppendInt(append(key, ','), int64(node.ID()), 10)
+	}
+	return string(key)
+}
@@ -0,0 +1,96 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// The costs of every loopless path from start to goal, cheapest first
+func allPathCosts(g *graph.GonumGraph, start, goal graph.Node) []float64 {
+	var costs []float64
+	onPath := map[int]bool{start.ID(): true}
+	var walk func(node graph.Node, cost float64)
+	walk = func(node graph.Node, cost float64) {
+		if node.ID() == goal.ID() {
+			costs = append(costs, cost)
+			return
+		}
+		for _, succ := range g.Successors(node) {
+			if !onPath[succ.ID()] {
+				onPath[succ.ID()] = true
+				walk(succ, cost+g.Cost(node, succ))
+				onPath[succ.ID()] = false
+			}
+		}
+	}
+	walk(start, 0)
+	sort.Float64s(costs)
+	return costs
+}
+
+func TestKShortestPaths(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 40; trial++ {
+		directed := trial%2 == 0
+		g := graph.NewGonumGraph(directed)
+		n := 2 + src.Intn(7)
+		graph.GnpRandomGraph(g, n, 0.5, directed, src)
+		for _, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(1+src.Intn(5)))
+		}
+		start, goal := graph.GonumNode(0), graph.GonumNode(n-1)
+		k := 1 + src.Intn(10)
+
+		expected := allPathCosts(g, start, goal)
+		paths, costs, err := graph.KShortestPaths(start, goal, g, k, nil)
+		if len(expected) == 0 {
+			if err != graph.ErrNoPath || paths != nil {
+				t.Errorf("Trial %d: expected ErrNoPath, got %v and %v", trial, paths, err)
+			}
+			continue
+		}
+		if len(expected) > k {
+			expected = expected[:k]
+		}
+		if err != nil || len(paths) != len(expected) || len(costs) != len(expected) {
+			t.Errorf("Trial %d: expected %d paths, got %v and %v", trial, len(expected), paths, err)
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for i, path := range paths {
+			if math.Abs(costs[i]-expected[i]) > 1e-9 {
+				t.Errorf("Trial %d: expected path %d to cost %v, got %v for %v", trial, i, expected[i], costs[i], path)
+			}
+			cost := 0.0
+			onPath := make(map[int]bool)
+			for j, node := range path {
+				if onPath[node.ID()] {
+					t.Errorf("Trial %d: path %v has a loop", trial, path)
+				}
+				onPath[node.ID()] = true
+				if j > 0 {
+					if !g.IsSuccessor(path[j-1], node) {
+						t.Errorf("Trial %d: path %v isn't in the graph", trial, path)
+					}
+					cost += g.Cost(path[j-1], node)
+				}
+			}
+			if path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID() || cost != costs[i] {
+				t.Errorf("Trial %d: path %v doesn't go from start to goal at cost %v", trial, path, costs[i])
+			}
+			key := ""
+			for _, node := range path {
+				key += string(rune('a' + node.ID()))
+			}
+			if seen[key] {
+				t.Errorf("Trial %d: path %v found twice", trial, path)
+			}
+			seen[key] = true
+		}
+	}
+}
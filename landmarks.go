@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Landmarks is a precomputed heuristic for shortest path searches on any graph with non-negative costs, no coordinates needed: the ALT ("A*, landmarks and triangle inequality")
+// technique[1]. A handful of landmark nodes are picked, and the distances from every landmark to every node, and from every node to every landmark, are computed once. Then for any
+// landmark L, the triangle inequality bounds the distance from v to t from below by both d(L,t) - d(L,v) and d(v,L) - d(t,L), and the largest such bound is the heuristic. It's
+// admissible and consistent, so it can be used with AStar, a Planner, or BatchShortestPaths, and since it only reads precomputed tables it's safe to share between goroutines.
+//
+// The preprocessing takes two Dijkstra searches per landmark (one on a directed graph's reversed edges), and the tables take memory proportional to the number of landmarks times the
+// number of nodes. The heuristic goes stale if the graph changes, but only in one direction: its bounds stay admissible as long as costs only go up and no edges are added, so
+// it can be handed to InitDStar as the heuristic for a robot that only ever finds new obstacles. A graph whose costs can come down needs the landmarks recomputing.
+//
+// [1] A. V. Goldberg and C. Harrelson, "Computing the shortest path: A* search meets graph theory", SODA 2005
+type Landmarks struct {
+	Nodes []Node
+	from  []map[int]float64 // from[i][v] = d(Nodes[i], v)
+	to    []map[int]float64 // to[i][v] = d(v, Nodes[i])
+}
+
+// Picks k landmarks by farthest point selection: the first is random, and each following one is the node farthest from the landmarks picked so far, which spreads them
+// around the edges of the graph where they give the tightest bounds. Nodes no landmark can reach count as infinitely far, so on a graph that isn't strongly connected they get landmarks
+// of their own. Fewer than k landmarks are picked if every node is a landmark already. Cost is interpreted as in AStar. If src is nil a time seeded source is used.
+func NewLandmarks(graph Graph, Cost func(Node, Node) float64, k int, src *rand.Rand) *Landmarks {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	src = randSource(src)
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes)) // So the landmarks only depend on src
+	lm := &Landmarks{}
+	if len(nodes) == 0 {
+		return lm
+	}
+	// Minimum distance from any landmark so far, for the farthest point selection
+	nearest := make(map[int]float64, len(nodes))
+
+	next := nodes[src.Intn(len(nodes))]
+	for i := 0; i < k && next != nil; i++ {
+		from := distancesFrom(next, graph, Cost, false)
+		to := from
+		if graph.IsDirected() {
+			to = distancesFrom(next, graph, Cost, true)
+		}
+		lm.Nodes = append(lm.Nodes, next)
+		lm.from = append(lm.from, from)
+		lm.to = append(lm.to, to)
+
+		next = nil
+		farthest := 0.0
+		for _, node := range nodes {
+			if d, ok := from[node.ID()]; ok {
+				if old, ok := nearest[node.ID()]; !ok || d < old {
+					nearest[node.ID()] = d
+				}
+			}
+
+			d, ok := nearest[node.ID()]
+			if !ok {
+				d = math.Inf(1)
+			}
+			if d > farthest {
+				farthest, next = d, node
+			}
+		}
+	}
+
+	return lm
+}
+
+// Returns a lower bound on the cost of the shortest path from a to b. Infinity means there's no path at all.
+func (lm *Landmarks) HeuristicCost(a, b Node) float64 {
+	h := 0.0
+	for i := range lm.Nodes {
+		h = math.Max(h, bound(lm.from[i], b.ID(), a.ID()))
+		h = math.Max(h, bound(lm.to[i], a.ID(), b.ID()))
+	}
+
+	return h
+}
+
+// d[x] - d[y] with missing distances being infinite, and no bound (0) from infinity minus infinity or when x is missing from a table that doesn't cover the whole graph
+func bound(d map[int]float64, x, y int) float64 {
+	dx, okx := d[x]
+	dy, oky := d[y]
+	switch {
+	case okx && oky:
+		return dx - dy
+	case !okx && oky:
+		return math.Inf(1)
+	default:
+		return 0
+	}
+}
+
+// Costs of the shortest paths from source to every reachable node, or from every node that can reach source if reverse is set
+func distancesFrom(source Node, graph Graph, Cost func(Node, Node) float64, reverse bool) map[int]float64 {
+	dist := map[int]float64{source.ID(): 0}
+	settled := make(map[int]bool)
+	openSet := NewDaryHeap(4, func(a, b HeapItem) bool {
+		return a.Key.(float64) < b.Key.(float64)
+	})
+
+	openSet.Push(source, 0.0)
+	for openSet.Len() != 0 {
+		curr := openSet.Pop()
+		settled[curr.ID()] = true
+
+		neighbors, cost := graph.Successors, Cost
+		if reverse {
+			neighbors, cost = graph.Predecessors, func(a, b Node) float64 { return Cost(b, a) }
+		}
+		for _, neighbor := range neighbors(curr.Node) {
+			if settled[neighbor.ID()] {
+				continue
+			}
+			d := curr.Key.(float64) + cost(curr.Node, neighbor)
+			if old, ok := dist[neighbor.ID()]; !ok || d < old {
+				dist[neighbor.ID()] = d
+				openSet.Push(neighbor, d)
+			}
+		}
+	}
+
+	return dist
+}
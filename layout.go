@@ -0,0 +1,281 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+)
+
+// A position in the plane.
+type Position struct {
+	X, Y float64
+}
+
+// Positions for drawing a graph's nodes, by node ID.
+type Layout map[int]Position
+
+// Which forces a force directed layout simulates.
+type LayoutForces int
+
+const (
+	// Fruchterman and Reingold's forces[1]: nodes repel each other with force k^2/d and edges pull their ends together with force d^2/k, so that edges tend towards length k.
+	// Good for small, fairly uniform graphs.
+	//
+	// [1] T. M. J. Fruchterman and E. M. Reingold, "Graph drawing by force-directed placement", Software: Practice and Experience 21 (1991)
+	FruchtermanReingoldForces LayoutForces = iota
+	// ForceAtlas2's forces[2]: repulsion grows with the degrees of both nodes (kr(deg+1)(deg'+1)/d), edges pull linearly with distance, and gravity pulls every node towards the
+	// origin in proportion to its degree. Hubs push each other apart and gather their leaves around them, which brings out the clusters of real world networks.
+	//
+	// [2] M. Jacomy, T. Venturini, S. Heymann and M. Bastian, "ForceAtlas2, a continuous graph layout algorithm for handy network visualization designed for the Gephi software",
+	// PLoS ONE 9 (2014)
+	ForceAtlas2Forces
+)
+
+// Parameters for ForceLayout. DefaultForceLayoutParams has sensible values to start from.
+type ForceLayoutParams struct {
+	Forces     LayoutForces
+	Iterations int
+	// The ideal edge length k for FruchtermanReingoldForces, or the repulsion strength kr for ForceAtlas2Forces
+	Scale float64
+	// The strength of ForceAtlas2's gravity; unused by FruchtermanReingoldForces, whose disconnected components drift apart
+	Gravity float64
+	// The Barnes-Hut accuracy: a group of nodes whose bounding square, seen from a node, is narrower than Theta radians is treated as a single body. 0 computes every pair of
+	// repulsions exactly, in O(n^2) per iteration; around 1 brings it down to O(n log n), at the cost of some accuracy.
+	Theta float64
+	// Starting positions for any of the nodes; the others start at random
+	Initial Layout
+}
+
+// Returns 300 iterations of FruchtermanReingoldForces with edges of length 1, computed exactly (Theta 0), or ForceAtlas2Forces with kr 1 and gravity 1. Theta should be raised
+// to around 1 for graphs of more than a few thousand nodes.
+func DefaultForceLayoutParams(forces LayoutForces) ForceLayoutParams {
+	return ForceLayoutParams{Forces: forces, Iterations: 300, Scale: 1, Gravity: 1}
+}
+
+// Lays a graph out by simulating it as a physical system, where nodes push each other apart and edges pull them together, until it settles. Every iteration moves each node along
+// the total force on it, but no further than a temperature which cools linearly to 0 over the iterations, so the layout settles rather than oscillating. (ForceAtlas2 proper
+// adapts each node's speed to how much it swings instead; cooling is simpler and predictable.)
+//
+// Direction is ignored, and an edge's pull is weighted by its cost from Cost, the graph's Coster, or UniformCost as usual. Random starting positions come from src, so the layout
+// is reproducible.
+func ForceLayout(graph Graph, Cost func(Node, Node) float64, params ForceLayoutParams, src *rand.Rand) Layout {
+	src = randSource(src)
+	pg := newPartGraph(graph, Cost)
+	n := len(pg.nodes)
+
+	// Repulsion between two nodes is strength*mass*mass'/d
+	strength := params.Scale * params.Scale
+	mass := make([]float64, n)
+	for i := range mass {
+		mass[i] = 1
+		if params.Forces == ForceAtlas2Forces {
+			mass[i] = float64(len(pg.adj[i]) + 1)
+		}
+	}
+	if params.Forces == ForceAtlas2Forces {
+		strength = params.Scale
+	}
+
+	side := math.Sqrt(float64(n)) * params.Scale
+	pos := make([]Position, n)
+	for i, node := range pg.nodes {
+		if p, ok := params.Initial[node.ID()]; ok {
+			pos[i] = p
+		} else {
+			pos[i] = Position{(src.Float64() - 0.5) * side, (src.Float64() - 0.5) * side}
+		}
+	}
+
+	disp := make([]Position, n)
+	var tree quadTree
+	temp := side / 10
+	for it := 0; it < params.Iterations; it++ {
+		for i := range disp {
+			disp[i] = Position{}
+		}
+
+		// Repulsion, with the Barnes-Hut approximation or exactly
+		if params.Theta > 0 {
+			tree.build(pos, mass)
+			for i := range pos {
+				fx, fy := tree.repulsion(pos[i], params.Theta)
+				disp[i].X += strength * mass[i] * fx
+				disp[i].Y += strength * mass[i] * fy
+			}
+		} else {
+			for i := range pos {
+				for j := i + 1; j < n; j++ {
+					dx, dy, d := separation(pos[i], pos[j], i, j)
+					f := strength * mass[i] * mass[j] / d
+					disp[i].X += dx / d * f
+					disp[i].Y += dy / d * f
+					disp[j].X -= dx / d * f
+					disp[j].Y -= dy / d * f
+				}
+			}
+		}
+
+		// Attraction along edges
+		for i, neighbors := range pg.adj {
+			for j, w := range neighbors {
+				if j <= i {
+					continue
+				}
+				dx, dy, d := separation(pos[i], pos[j], i, j)
+				f := w * d // ForceAtlas2's linear pull
+				if params.Forces == FruchtermanReingoldForces {
+					f = w * d * d / params.Scale
+				}
+				disp[i].X -= dx / d * f
+				disp[i].Y -= dy / d * f
+				disp[j].X += dx / d * f
+				disp[j].Y += dy / d * f
+			}
+		}
+
+		if params.Forces == ForceAtlas2Forces {
+			for i, p := range pos {
+				if d := math.Hypot(p.X, p.Y); d > 0 {
+					disp[i].X -= p.X / d * params.Gravity * mass[i]
+					disp[i].Y -= p.Y / d * params.Gravity * mass[i]
+				}
+			}
+		}
+
+		for i, dp := range disp {
+			if d := math.Hypot(dp.X, dp.Y); d > 0 {
+				step := math.Min(d, temp)
+				pos[i].X += dp.X / d * step
+				pos[i].Y += dp.Y / d * step
+			}
+		}
+		temp -= side / 10 / float64(params.Iterations)
+	}
+
+	layout := make(Layout, n)
+	for i, node := range pg.nodes {
+		layout[node.ID()] = pos[i]
+	}
+
+	return layout
+}
+
+// The vector from q to p and its length, nudging nodes that sit on top of each other apart (deterministically, by index) so forces between them have a direction
+func separation(p, q Position, i, j int) (dx, dy, d float64) {
+	dx, dy = p.X-q.X, p.Y-q.Y
+	if d = math.Hypot(dx, dy); d > 1e-9 {
+		return dx, dy, d
+	}
+
+	angle := float64(i*31+j*17) * 0.618
+	return math.Cos(angle) * 1e-9, math.Sin(angle) * 1e-9, 1e-9
+}
+
+// A Barnes-Hut quadtree[1] over weighted points, with each cell's total mass and center of mass, rebuilt every iteration. Cells are kept in one slice to save allocations.
+//
+// [1] J. Barnes and P. Hut, "A hierarchical O(N log N) force-calculation algorithm", Nature 324 (1986)
+type quadTree struct {
+	cells []quadCell
+}
+
+type quadCell struct {
+	x, y, size float64 // Lower left corner and side length
+	mass       float64
+	cx, cy     float64 // Center of mass
+	children   [4]int  // Indices into cells, 0 for none (the root is never a child)
+	leaf       bool
+	body       Position // The single body in a leaf, with its mass in mass
+}
+
+func (t *quadTree) build(pos []Position, mass []float64) {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, p := range pos {
+		minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+		maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+	}
+	size := math.Max(maxX-minX, maxY-minY) + 1e-9
+
+	t.cells = append(t.cells[:0], quadCell{x: minX, y: minY, size: size})
+	for i, p := range pos {
+		t.insert(0, p, mass[i], 0)
+	}
+}
+
+// The deepest a body is pushed down; bodies that still share a cell at this depth (practically on top of each other) share a leaf
+const quadTreeMaxDepth = 40
+
+func (t *quadTree) insert(c int, p Position, m float64, depth int) {
+	cell := &t.cells[c]
+	if cell.mass == 0 {
+		cell.mass, cell.cx, cell.cy, cell.leaf, cell.body = m, p.X, p.Y, true, p
+		return
+	}
+
+	total := cell.mass + m
+	cell.cx, cell.cy = (cell.cx*cell.mass+p.X*m)/total, (cell.cy*cell.mass+p.Y*m)/total
+	if cell.leaf && depth >= quadTreeMaxDepth {
+		cell.mass = total
+		cell.body = Position{cell.cx, cell.cy}
+		return
+	}
+
+	if cell.leaf {
+		// Push the resident body down before adding the new one
+		cell.leaf = false
+		body, bodyMass := cell.body, cell.mass
+		cell.mass = total
+		t.insert(t.child(c, body), body, bodyMass, depth+1)
+	} else {
+		cell.mass = total
+	}
+	t.insert(t.child(c, p), p, m, depth+1)
+}
+
+// The index of the quadrant of cell c containing p, creating it if needed
+func (t *quadTree) child(c int, p Position) int {
+	cell := t.cells[c]
+	half := cell.size / 2
+	q, x, y := 0, cell.x, cell.y
+	if p.X >= cell.x+half {
+		q, x = q+1, x+half
+	}
+	if p.Y >= cell.y+half {
+		q, y = q+2, y+half
+	}
+
+	if cell.children[q] == 0 {
+		t.cells = append(t.cells, quadCell{x: x, y: y, size: half})
+		t.cells[c].children[q] = len(t.cells) - 1
+	}
+	return t.cells[c].children[q]
+}
+
+// The sum of mass/d over all bodies, as a vector pointing away from them, treating cells narrower than theta radians as seen from p as single bodies. A body at p itself
+// (the node the repulsion is for) is skipped.
+func (t *quadTree) repulsion(p Position, theta float64) (fx, fy float64) {
+	var walk func(c int)
+	walk = func(c int) {
+		cell := &t.cells[c]
+		if cell.mass == 0 {
+			return
+		}
+
+		dx, dy := p.X-cell.cx, p.Y-cell.cy
+		d := math.Hypot(dx, dy)
+		if cell.leaf || cell.size < theta*d {
+			if d > 1e-9 {
+				fx += dx / d * cell.mass / d
+				fy += dy / d * cell.mass / d
+			}
+			return
+		}
+
+		for _, child := range cell.children {
+			if child != 0 {
+				walk(child)
+			}
+		}
+	}
+	walk(0)
+
+	return fx, fy
+}
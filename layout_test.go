@@ -0,0 +1,92 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// The mean distance between adjacent nodes and between non-adjacent ones
+func layoutSpread(g graph.Graph, layout graph.Layout) (adjacent, other float64) {
+	var nAdjacent, nOther int
+	nodes := g.NodeList()
+	for i, u := range nodes {
+		for _, v := range nodes[i+1:] {
+			p, q := layout[u.ID()], layout[v.ID()]
+			d := math.Hypot(p.X-q.X, p.Y-q.Y)
+			if g.IsSuccessor(u, v) || g.IsSuccessor(v, u) {
+				adjacent += d
+				nAdjacent++
+			} else {
+				other += d
+				nOther++
+			}
+		}
+	}
+
+	return adjacent / float64(nAdjacent), other / float64(nOther)
+}
+
+func TestForceLayout(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.CycleGraph(g, 30, false)
+
+	for _, forces := range []graph.LayoutForces{graph.FruchtermanReingoldForces, graph.ForceAtlas2Forces} {
+		for _, theta := range []float64{0, 0.8} {
+			params := graph.DefaultForceLayoutParams(forces)
+			params.Theta = theta
+			layout := graph.ForceLayout(g, nil, params, rand.New(rand.NewSource(1)))
+			if len(layout) != 30 {
+				t.Fatalf("Forces %d, theta %v: laid out %d nodes of 30", forces, theta, len(layout))
+			}
+			for id, p := range layout {
+				if math.IsNaN(p.X) || math.IsNaN(p.Y) || math.IsInf(p.X, 0) || math.IsInf(p.Y, 0) {
+					t.Fatalf("Forces %d, theta %v: node %d is at %v", forces, theta, id, p)
+				}
+			}
+
+			// A cycle opens out into a ring, with neighbors much closer than average
+			if adjacent, other := layoutSpread(g, layout); adjacent*3 > other {
+				t.Errorf("Forces %d, theta %v: adjacent nodes are %v apart on average, others %v", forces, theta, adjacent, other)
+			}
+		}
+	}
+}
+
+func TestForceLayoutClusters(t *testing.T) {
+	// Two 10-cliques joined by one edge separate into two clumps
+	g := graph.NewGonumGraph(false)
+	for c := 0; c < 2; c++ {
+		for i := 0; i < 10; i++ {
+			g.AddNode(graph.GonumNode(c*10+i), nil)
+			for j := 0; j < i; j++ {
+				g.AddEdge(graph.GonumEdge{graph.GonumNode(c*10 + i), graph.GonumNode(c*10 + j)})
+			}
+		}
+	}
+	g.AddEdge(graph.GonumEdge{graph.GonumNode(0), graph.GonumNode(10)})
+
+	layout := graph.ForceLayout(g, nil, graph.DefaultForceLayoutParams(graph.ForceAtlas2Forces), rand.New(rand.NewSource(1)))
+	var within, between float64
+	for u := 0; u < 20; u++ {
+		for v := u + 1; v < 20; v++ {
+			p, q := layout[u], layout[v]
+			if d := math.Hypot(p.X-q.X, p.Y-q.Y); u/10 == v/10 {
+				within += d / 90
+			} else {
+				between += d / 100
+			}
+		}
+	}
+	if within*2 > between {
+		t.Errorf("Nodes are %v apart within cliques and %v between them on average", within, between)
+	}
+
+	// Initial positions are respected as starting points, so a layout can be refined
+	params := graph.DefaultForceLayoutParams(graph.ForceAtlas2Forces)
+	params.Initial, params.Iterations = layout, 0
+	if again := graph.ForceLayout(g, nil, params, nil); again[3] != layout[3] {
+		t.Errorf("Node 3 started at %v, not its initial position %v", again[3], layout[3])
+	}
+}
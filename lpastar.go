@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+)
+
+// An LPAStarInstance runs Lifelong Planning A*[1] (LPA*): it finds a shortest path between a fixed start and goal, and when edges change, repairs it by searching again only
+// where the change made a difference, instead of starting over as A* would. It's the tool for recomputing a route in a service whose map changes under it, where nothing
+// moves along the route; for an agent that moves, use D*-Lite, which is LPA* turned round to search from the goal so the start can move.
+//
+// With the start staying put, the two are the same search, so an LPAStarInstance is a DStarInstance that never steps, and takes the same options. Edges are reported with
+// UpdateEdge as they change, and ComputePath repairs the path for all of them at once.
+//
+// [1] S. Koenig, M. Likhachev, D. Furcy, "Lifelong Planning A*", Artificial Intelligence 155, 2004
+type LPAStarInstance struct {
+	ds      *DStarInstance
+	changed []Edge
+}
+
+// Initializes LPA* on graph and computes the shortest path from start to goal. Cost and HeuristicCost are interpreted as in InitDStar; the heuristic is always asked for
+// estimates from start, so it must be admissible and consistent for paths out of the start.
+func InitLPAStar(start, goal Node, graph ReversibleGraph, Cost, HeuristicCost func(Node, Node) float64, options ...DStarOption) *LPAStarInstance {
+	return &LPAStarInstance{ds: InitDStar(start, goal, graph, Cost, HeuristicCost, options...)}
+}
+
+// Reports that edge has changed: its cost, or whether it's in the graph at all, as described for DStarGraph. Nothing is searched until ComputePath. In an undirected graph,
+// reporting an edge in one direction is enough.
+func (lpa *LPAStarInstance) UpdateEdge(edge Edge) {
+	lpa.changed = append(lpa.changed, edge)
+}
+
+// Repairs the shortest path for every edge reported since the last call, searching again only where it has to. If nothing changed, it's a no-op, unless a search was cut
+// short (see WithDStarBudget), in which case it's finished.
+func (lpa *LPAStarInstance) ComputePath() {
+	lpa.ComputePathCtx(context.Background())
+}
+
+// Like ComputePath, but gives up when ctx is cancelled and returns ctx.Err(). The unfinished work stays queued, and the changes count as reported, so the next ComputePath
+// carries on from where this one stopped.
+func (lpa *LPAStarInstance) ComputePathCtx(ctx context.Context) error {
+	changed := lpa.changed
+	lpa.changed = nil
+	return lpa.ds.UpdateCtx(ctx, nil, changed)
+}
+
+// Returns the shortest path from start to goal, both included, and its cost, as of the last ComputePath, or ErrNoPath if there isn't one.
+func (lpa *LPAStarInstance) Path() ([]Node, float64, error) {
+	return lpa.ds.Path()
+}
+
+// Returns the statistics collected so far, if the instance was created with WithDStarStats.
+func (lpa *LPAStarInstance) Stats() SearchStats {
+	return lpa.ds.Stats()
+}
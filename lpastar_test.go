@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestLPAStar(t *testing.T) {
+	g := randomWeightedGraph(300, 0.02, 11)
+	src := rand.New(rand.NewSource(12))
+	start, goal := graph.GonumNode(0), graph.GonumNode(299)
+	lpa := graph.InitLPAStar(start, goal, g, nil, nil, graph.WithDStarStats())
+
+	for round := 0; round < 30; round++ {
+		_, want, _ := graph.AStar(start, goal, g, nil, nil)
+		path, cost, err := lpa.Path()
+		if math.IsInf(want, 1) {
+			if err != graph.ErrNoPath {
+				t.Fatalf("Round %d: expected no path, got %v costing %v (%v)", round, nodeIDs(path), cost, err)
+			}
+		} else if err != nil || math.Abs(cost-want) > 1e-9 || path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID() || !graph.IsPath(path, g) {
+			t.Fatalf("Round %d: expected a path costing %v, got %v costing %v (%v)", round, want, nodeIDs(path), cost, err)
+		}
+
+		// Change a few costs, making the path's own edges dearer half the time so the repair has work to do, and remove an edge now and then
+		edges := g.EdgeList()
+		for i := 0; i < 5; i++ {
+			edge := edges[src.Intn(len(edges))]
+			if i == 0 && len(path) > 1 && round%2 == 0 {
+				k := src.Intn(len(path) - 1)
+				edge = graph.GonumEdge{H: path[k], T: path[k+1]}
+			}
+			if round%5 == 4 && i == 0 {
+				g.RemoveEdge(edge)
+			} else {
+				g.SetEdgeCost(edge, 1+src.Float64()*9)
+			}
+			lpa.UpdateEdge(edge)
+		}
+		lpa.ComputePath()
+	}
+
+	if stats := lpa.Stats(); stats.Searches != 31 || stats.Expansions == 0 {
+		t.Errorf("Expected 31 searches, got %+v", stats)
+	}
+}
+
+func TestLPAStarRemovedEdges(t *testing.T) {
+	g := randomWeightedGraph(500, 0.01, 13)
+	start, goal := graph.GonumNode(0), graph.GonumNode(499)
+	lpa := graph.InitLPAStar(start, goal, g, nil, nil)
+	_, want, _ := graph.AStar(start, goal, g, nil, nil)
+
+	// Cutting every edge out of the start leaves no path, and putting them back brings the original one back
+	succs := g.Successors(start)
+	costs := make([]float64, len(succs))
+	for i, succ := range succs {
+		costs[i] = g.Cost(start, succ)
+		g.RemoveEdge(graph.GonumEdge{H: start, T: succ})
+		lpa.UpdateEdge(graph.GonumEdge{H: start, T: succ})
+	}
+	if err := lpa.ComputePathCtx(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := lpa.Path(); err != graph.ErrNoPath {
+		t.Fatalf("Expected no path out of a start with no edges, got %v", err)
+	}
+
+	for i, succ := range succs {
+		g.AddEdge(graph.GonumEdge{H: start, T: succ})
+		g.SetEdgeCost(graph.GonumEdge{H: start, T: succ}, costs[i])
+		lpa.UpdateEdge(graph.GonumEdge{H: start, T: succ})
+	}
+	lpa.ComputePath()
+	if _, cost, err := lpa.Path(); err != nil || math.Abs(cost-want) > 1e-9 {
+		t.Errorf("Expected the path costing %v back, got %v (%v)", want, cost, err)
+	}
+}
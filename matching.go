@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"math"
+)
+
+// Finds a maximum matching between left and right by the algorithm of Hopcroft and Karp[1]: as many edges as possible, each between a node of left and one of right, with
+// no node an end of two of them, e.g. as many tasks as possible each given to a worker who can do it. Edges count whichever way round they go, and edges to nodes in neither
+// slice are ignored; a node shouldn't be in both. Returns the matched edges, with the ends as they are in graph, in the order of their ends in left. Runs in O(m sqrt(n)).
+//
+// For a matching that weighs the edges, see MinCostAssignment, or MaxWeightBMatching, which also lets nodes be matched more than once.
+//
+// [1] J. E. Hopcroft and R. M. Karp, "An n^5/2 algorithm for maximum matchings in bipartite graphs", SIAM Journal on Computing 2 (1973)
+func MaxBipartiteMatching(graph Graph, left, right []Node) []Edge {
+	b := newBipartite(graph, left, right, nil)
+
+	// Each phase finds a maximal set of shortest augmenting paths that share no nodes, and there are only O(sqrt(n)) phases
+	dist := make([]int, len(left))
+	for b.layer(dist) {
+		for i := range left {
+			if b.matchLeft[i] == -1 {
+				b.augment(i, dist)
+			}
+		}
+	}
+
+	var matching []Edge
+	for i, k := range b.matchLeft {
+		if k != -1 {
+			matching = append(matching, b.edges[i][k].Edge)
+		}
+	}
+	return matching
+}
+
+// Finds a maximum matching between left and right, as MaxBipartiteMatching, and of all of those, the one with the lowest total cost: the assignment problem, as in giving
+// tasks to workers to get as many done as possible for as little as possible. Cost is interpreted as in AStar, and may be negative. Returns the matched edges, in the order of
+// their ends in left, and their total cost.
+//
+// It's solved with the Hungarian algorithm in O(n^3), for n the larger of left and right, whatever the number of edges.
+func MinCostAssignment(graph Graph, left, right []Node, Cost func(Node, Node) float64) (assignment []Edge, cost float64) {
+	if Cost == nil {
+		Cost = UniformCost
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		}
+	}
+	b := newBipartite(graph, left, right, Cost)
+
+	// The missing edges cost +Inf, which hungarian avoids above all, and the padding of the shorter side to make the matrix square costs nothing
+	n := len(left)
+	if len(right) > n {
+		n = len(right)
+	}
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		if i >= len(left) {
+			continue
+		}
+		for j := range right {
+			matrix[i][j] = math.Inf(1)
+		}
+		for k, j := range b.adj[i] {
+			matrix[i][j] = b.edges[i][k].Weight
+		}
+	}
+
+	columns, _ := hungarian(matrix)
+	for i := range left {
+		j := columns[i]
+		if j >= len(right) || math.IsInf(matrix[i][j], 1) {
+			continue
+		}
+		for k, other := range b.adj[i] {
+			if other == j {
+				assignment = append(assignment, b.edges[i][k].Edge)
+				cost += matrix[i][j]
+				break
+			}
+		}
+	}
+	return assignment, cost
+}
+
+// The edges between two sides of a graph, by the positions of their ends in the slices of nodes
+type bipartite struct {
+	adj        [][]int          // adj[i] holds the positions in right of the neighbors of left[i]
+	edges      [][]WeightedEdge // edges[i][k] is the edge between left[i] and right[adj[i][k]]
+	matchLeft  []int            // The position in adj[i] of the edge left[i] is matched by, or -1
+	matchRight []int            // The position in left of the node right[j] is matched to, or -1
+}
+
+// Finds the edges between left and right, weighted by Weight if it isn't nil
+func newBipartite(graph Graph, left, right []Node, Weight func(Node, Node) float64) *bipartite {
+	if Weight == nil {
+		Weight = UniformCost
+	}
+	index := make(map[int]int, len(right))
+	for j, node := range right {
+		index[node.ID()] = j
+	}
+	b := &bipartite{adj: make([][]int, len(left)), edges: make([][]WeightedEdge, len(left)), matchLeft: make([]int, len(left)), matchRight: make([]int, len(right))}
+	for i, node := range left {
+		b.matchLeft[i] = -1
+		for _, edge := range neighborEdges(graph, node, Weight) {
+			if j, ok := index[otherEnd(edge, node).ID()]; ok {
+				b.adj[i] = append(b.adj[i], j)
+				b.edges[i] = append(b.edges[i], edge)
+			}
+		}
+	}
+	for j := range right {
+		b.matchRight[j] = -1
+	}
+	return b
+}
+
+// Finds how many edges of an alternating path from an unmatched left node it takes to reach each left node, and returns whether an unmatched right node can be reached
+func (b *bipartite) layer(dist []int) bool {
+	var queue []int
+	for i, k := range b.matchLeft {
+		dist[i] = -1
+		if k == -1 {
+			dist[i] = 0
+			queue = append(queue, i)
+		}
+	}
+
+	found := false
+	for len(queue) != 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, j := range b.adj[i] {
+			next := b.matchRight[j]
+			if next == -1 {
+				found = true
+			} else if dist[next] == -1 {
+				dist[next] = dist[i] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return found
+}
+
+// Looks for an augmenting path from left[i] going one layer deeper at every step, and flips it if there's one. Dead ends are taken out of the layers, so no node is tried
+// twice in a phase.
+func (b *bipartite) augment(i int, dist []int) bool {
+	for k, j := range b.adj[i] {
+		next := b.matchRight[j]
+		if next == -1 || dist[next] == dist[i]+1 && b.augment(next, dist) {
+			b.matchLeft[i], b.matchRight[j] = k, i
+			return true
+		}
+	}
+	dist[i] = -1
+	return false
+}
@@ -0,0 +1,121 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A random bipartite graph between the nodes 0 to l-1 and 100 to 100+r-1, with random costs
+func randomBipartite(l, r int, p float64, seed int64) (g *graph.GonumGraph, left, right []graph.Node) {
+	src := rand.New(rand.NewSource(seed))
+	g = graph.NewGonumGraph(false)
+	for i := 0; i < l; i++ {
+		left = append(left, graph.GonumNode(i))
+		g.AddNode(left[i], nil)
+	}
+	for j := 0; j < r; j++ {
+		right = append(right, graph.GonumNode(100+j))
+		g.AddNode(right[j], nil)
+	}
+	for _, u := range left {
+		for _, v := range right {
+			if src.Float64() < p {
+				edge := graph.GonumEdge{H: u, T: v}
+				g.AddEdge(edge)
+				g.SetEdgeCost(edge, float64(src.Intn(20)-5))
+			}
+		}
+	}
+	return g, left, right
+}
+
+// Checks the matching only joins left to right, and uses no node twice
+func checkMatching(t *testing.T, g graph.Graph, matching []graph.Edge, left, right []graph.Node) {
+	side := make(map[int]int)
+	for _, node := range left {
+		side[node.ID()] = 1
+	}
+	for _, node := range right {
+		side[node.ID()] = 2
+	}
+	used := make(map[int]bool)
+	for _, edge := range matching {
+		u, v := edge.Head().ID(), edge.Tail().ID()
+		if !g.IsSuccessor(edge.Head(), edge.Tail()) || side[u]+side[v] != 3 || used[u] || used[v] {
+			t.Fatalf("%v isn't an edge between the sides that can be added to the matching", [2]int{u, v})
+		}
+		used[u], used[v] = true, true
+	}
+}
+
+// The size of the largest matching, and the lowest cost of one that size, trying every one
+func bruteForceMatching(g graph.CostGraph, left []graph.Node, used map[int]bool) (size int, cost float64) {
+	if len(left) == 0 {
+		return 0, 0
+	}
+	size, cost = bruteForceMatching(g, left[1:], used)
+	for _, v := range g.Successors(left[0]) {
+		if used[v.ID()] {
+			continue
+		}
+		used[v.ID()] = true
+		s, c := bruteForceMatching(g, left[1:], used)
+		used[v.ID()] = false
+		s, c = s+1, c+g.Cost(left[0], v)
+		if s > size || s == size && c < cost {
+			size, cost = s, c
+		}
+	}
+	return size, cost
+}
+
+func TestMaxBipartiteMatching(t *testing.T) {
+	// Worker 0 can only do task 10 and only worker 2 can do task 12, so worker 1 has to do task 11, and nobody can do task 13
+	g := graph.NewDirectedGraph(0, 0)
+	for _, e := range [][2]int{{0, 10}, {1, 10}, {1, 11}, {2, 10}, {2, 11}, {2, 12}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+	g.AddNode(graph.GonumNode(13), nil)
+	workers := []graph.Node{graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(2)}
+	tasks := []graph.Node{graph.GonumNode(10), graph.GonumNode(11), graph.GonumNode(12), graph.GonumNode(13)}
+	want := [][2]int{{0, 10}, {1, 11}, {2, 12}}
+	matching := graph.MaxBipartiteMatching(g, workers, tasks)
+	checkMatching(t, g, matching, workers, tasks)
+	if ids := edgeIDs(matching); !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected the matching %v, got %v", want, ids)
+	}
+
+	// The sides can be given either way round, and the edges keep their direction
+	if ids := edgeIDs(graph.MaxBipartiteMatching(g, tasks, workers)); !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected the matching %v with the sides swapped, got %v", want, ids)
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		g, left, right := randomBipartite(7, 6, 0.3, seed)
+		matching := graph.MaxBipartiteMatching(g, left, right)
+		checkMatching(t, g, matching, left, right)
+		if size, _ := bruteForceMatching(g, left, make(map[int]bool)); len(matching) != size {
+			t.Errorf("seed %d: expected a matching of %d, got %d", seed, size, len(matching))
+		}
+	}
+}
+
+func TestMinCostAssignment(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		g, left, right := randomBipartite(5+int(seed%3), 6-int(seed%3), 0.5, seed)
+		assignment, cost := graph.MinCostAssignment(g, left, right, nil)
+		checkMatching(t, g, assignment, left, right)
+		total := 0.0
+		for _, edge := range assignment {
+			total += g.Cost(edge.Head(), edge.Tail())
+		}
+		size, want := bruteForceMatching(g, left, make(map[int]bool))
+		if len(assignment) != size || math.Abs(cost-want) > 1e-9 || math.Abs(total-cost) > 1e-9 {
+			t.Errorf("seed %d: expected %d assignments costing %v, got %d costing %v (adding up to %v)", seed, size, want, len(assignment), cost, total)
+		}
+	}
+}
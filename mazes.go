@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"math/rand"
+)
+
+/* Tile graph generators for pathfinding tests and benchmarks, plus a wrapper that hides obstacles until an agent gets close to them, for exercising D*-Lite the way it's used in practice. */
+
+// Selects the algorithm GenerateMaze carves with.
+type MazeAlgorithm int
+
+const (
+	// Randomized depth first search. Produces long, winding corridors with few dead ends, which makes for long paths.
+	RecursiveBacktracker MazeAlgorithm = iota
+	// Randomized Prim's algorithm. Produces many short dead ends branching off the main routes, so searches have to expand many nodes.
+	PrimMaze
+	// Wilson's algorithm (see RandomSpanningTree). Every perfect maze is equally likely, which puts it between the other two: some long corridors, some short dead ends.
+	WilsonMaze
+)
+
+// Generates a perfect maze (exactly one path between any two cells) with rows x cols cells. The result is a (2*rows+1) x (2*cols+1) TileGraph surrounded by a wall, where cell (r, c)
+// is the tile at (2*r+1, 2*c+1) and the tiles between neighboring cells are opened when the maze carves a passage between them. If src is nil a time seeded source is used.
+func GenerateMaze(rows, cols int, algorithm MazeAlgorithm, src *rand.Rand) *TileGraph {
+	src = randSource(src)
+	tg := NewTileGraph(2*rows+1, 2*cols+1, false)
+	if rows <= 0 || cols <= 0 {
+		return tg
+	}
+
+	visited := make([]bool, rows*cols)
+	neighbors := func(cell int) []int {
+		r, c := cell/cols, cell%cols
+		out := make([]int, 0, 4)
+		if r > 0 {
+			out = append(out, cell-cols)
+		}
+		if r+1 < rows {
+			out = append(out, cell+cols)
+		}
+		if c > 0 {
+			out = append(out, cell-1)
+		}
+		if c+1 < cols {
+			out = append(out, cell+1)
+		}
+		return out
+	}
+	open := func(cell int) {
+		visited[cell] = true
+		tg.SetPassability(2*(cell/cols)+1, 2*(cell%cols)+1, true)
+	}
+	carve := func(from, to int) {
+		tg.SetPassability((2*(from/cols)+1+2*(to/cols)+1)/2, (2*(from%cols)+1+2*(to%cols)+1)/2, true)
+		open(to)
+	}
+
+	start := src.Intn(rows * cols)
+	open(start)
+
+	switch algorithm {
+	case WilsonMaze:
+		cells := make([]Node, rows*cols)
+		for i := range cells {
+			cells[i] = GonumNode(i)
+		}
+		inTree := map[int]bool{start: true}
+		cellNeighbors := func(cell Node) []Node {
+			var out []Node
+			for _, neighbor := range neighbors(cell.ID()) {
+				out = append(out, GonumNode(neighbor))
+			}
+			return out
+		}
+		wilson(cells, cellNeighbors, inTree, func(from, to Node) { carve(to.ID(), from.ID()) }, src)
+	case PrimMaze:
+		// Walls between the maze and the cells outside it, as (inside, outside) pairs
+		frontier := make([][2]int, 0)
+		addWalls := func(cell int) {
+			for _, neighbor := range neighbors(cell) {
+				if !visited[neighbor] {
+					frontier = append(frontier, [2]int{cell, neighbor})
+				}
+			}
+		}
+		addWalls(start)
+		for len(frontier) > 0 {
+			i := src.Intn(len(frontier))
+			wall := frontier[i]
+			frontier[i] = frontier[len(frontier)-1]
+			frontier = frontier[:len(frontier)-1]
+
+			if !visited[wall[1]] {
+				carve(wall[0], wall[1])
+				addWalls(wall[1])
+			}
+		}
+	default:
+		stack := []int{start}
+		for len(stack) > 0 {
+			cell := stack[len(stack)-1]
+			unvisited := make([]int, 0, 4)
+			for _, neighbor := range neighbors(cell) {
+				if !visited[neighbor] {
+					unvisited = append(unvisited, neighbor)
+				}
+			}
+
+			if len(unvisited) == 0 {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			next := unvisited[src.Intn(len(unvisited))]
+			carve(cell, next)
+			stack = append(stack, next)
+		}
+	}
+
+	return tg
+}
+
+// Generates a rows x cols TileGraph where each tile is independently impassable with probability density, except that start and goal are always passable and connected to each other.
+// If the random obstacles happen to separate them, a random monotone corridor is cleared from start to goal, so the field stays random but is guaranteed to be solvable.
+// Start and goal are tile nodes, as returned by CoordsToNode. If src is nil a time seeded source is used.
+func RandomObstacleField(rows, cols int, density float64, start, goal Node, src *rand.Rand) *TileGraph {
+	src = randSource(src)
+	tg := NewTileGraph(rows, cols, true)
+	for i := range tg.tiles {
+		if src.Float64() < density {
+			tg.tiles[i] = false
+		}
+	}
+	tg.tiles[start.ID()], tg.tiles[goal.ID()] = true, true
+
+	if tilesConnected(tg, start, goal) {
+		return tg
+	}
+
+	r, c := tg.IDToCoords(start.ID())
+	gr, gc := tg.IDToCoords(goal.ID())
+	for r != gr || c != gc {
+		// Step towards the goal along a random axis that still needs moving along
+		if c == gc || r != gr && src.Intn(2) == 0 {
+			if r < gr {
+				r++
+			} else {
+				r--
+			}
+		} else {
+			if c < gc {
+				c++
+			} else {
+				c--
+			}
+		}
+		tg.SetPassability(r, c, true)
+	}
+
+	return tg
+}
+
+func tilesConnected(tg *TileGraph, start, goal Node) bool {
+	visited := map[int]bool{start.ID(): true}
+	queue := []Node{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node.ID() == goal.ID() {
+			return true
+		}
+		for _, succ := range tg.Successors(node) {
+			if !visited[succ.ID()] {
+				visited[succ.ID()] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	return false
+}
+
+// A RevealingTileGraph is a DStarGraph over a hidden TileGraph. The embedded TileGraph is what the agent currently knows: it starts out entirely passable (the freespace assumption),
+// and every time the agent moves, the true passability of every tile within radius (in both rows and columns) of its new position is revealed. ChangedEdges reports the edges
+// touching tiles whose passability turned out different from what the agent believed.
+//
+// This is the canonical setting D*-Lite was designed for, and it's a much more realistic workload than changing random edges.
+type RevealingTileGraph struct {
+	*TileGraph
+	truth    *TileGraph
+	position Node
+	radius   int
+	changed  []Edge
+	seen     []bool // Which tiles the agent has had within sensor range
+}
+
+// Creates a RevealingTileGraph hiding truth, with the agent at start. The surroundings of start are revealed immediately (and aren't reported by ChangedEdges), since an agent
+// can see them before planning its first move.
+func NewRevealingTileGraph(truth *TileGraph, start Node, radius int) *RevealingTileGraph {
+	rows, cols := truth.Dimensions()
+	graph := &RevealingTileGraph{TileGraph: NewTileGraph(rows, cols, true), truth: truth, position: start, radius: radius, seen: make([]bool, rows*cols)}
+	graph.reveal()
+	graph.changed = nil
+
+	return graph
+}
+
+// Returns the agent's current position.
+func (graph *RevealingTileGraph) Position() Node {
+	return graph.position
+}
+
+// Moves the agent to target and reveals its surroundings.
+func (graph *RevealingTileGraph) Move(target Node) {
+	graph.position = target
+	graph.reveal()
+}
+
+// Returns and forgets the edges changed by revelations since the last call. The cost function is always nil since TileGraphs are unweighted.
+func (graph *RevealingTileGraph) ChangedEdges() (newCostFunc func(Node, Node) float64, changedEdges []Edge) {
+	changedEdges, graph.changed = graph.changed, nil
+	return nil, changedEdges
+}
+
+func (graph *RevealingTileGraph) reveal() {
+	row, col := graph.IDToCoords(graph.position.ID())
+	for r := row - graph.radius; r <= row+graph.radius; r++ {
+		for c := col - graph.radius; c <= col+graph.radius; c++ {
+			id := graph.CoordsToID(r, c)
+			if id == -1 {
+				continue
+			}
+			graph.seen[id] = true
+			if graph.tiles[id] == graph.truth.tiles[id] {
+				continue
+			}
+
+			graph.tiles[id] = graph.truth.tiles[id]
+			tile := GonumNode(id)
+			for _, neighbor := range []Node{graph.CoordsToNode(r-1, c), graph.CoordsToNode(r+1, c), graph.CoordsToNode(r, c-1), graph.CoordsToNode(r, c+1)} {
+				if neighbor != nil {
+					graph.changed = append(graph.changed, GonumEdge{H: neighbor, T: tile}, GonumEdge{H: tile, T: neighbor})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateMaze(t *testing.T) {
+	for _, algorithm := range []graph.MazeAlgorithm{graph.RecursiveBacktracker, graph.PrimMaze, graph.WilsonMaze} {
+		maze := graph.GenerateMaze(8, 10, algorithm, rand.New(rand.NewSource(1)))
+		if rows, cols := maze.Dimensions(); rows != 17 || cols != 21 {
+			t.Fatalf("Maze has dimensions %dx%d, expected 17x21", rows, cols)
+		}
+
+		// A perfect maze is a spanning tree of the cells, with a passage tile per tree edge
+		nodes := len(maze.NodeList())
+		if nodes != 2*80-1 {
+			t.Errorf("Maze %d has %d open tiles, expected %d", algorithm, nodes, 2*80-1)
+		}
+		if edges := len(maze.EdgeList()) / 2; edges != nodes-1 {
+			t.Errorf("Maze %d isn't a tree: %d tiles and %d edges", algorithm, nodes, edges)
+		}
+		if path, _, _ := graph.AStar(maze.CoordsToNode(1, 1), maze.CoordsToNode(15, 19), maze, nil, nil); path == nil {
+			t.Errorf("Maze %d isn't connected", algorithm)
+		}
+	}
+}
+
+func TestRandomObstacleField(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		field := graph.RandomObstacleField(30, 30, 0.45, graph.GonumNode(0), graph.GonumNode(899), rand.New(rand.NewSource(seed)))
+		if path, _, _ := graph.AStar(graph.GonumNode(0), graph.GonumNode(899), field, nil, nil); path == nil {
+			t.Fatalf("Obstacle field with seed %d isn't solvable:\n%s", seed, field)
+		}
+	}
+}
+
+func TestRevealingTileGraph(t *testing.T) {
+	truth := graph.GenerateMaze(6, 6, graph.RecursiveBacktracker, rand.New(rand.NewSource(3)))
+	start := truth.CoordsToNode(1, 1)
+
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+	if _, edges := world.ChangedEdges(); len(edges) != 0 {
+		t.Error("Initial revelation reported as changes")
+	}
+	if !world.NodeExists(truth.CoordsToNode(5, 5)) {
+		t.Error("Distant wall known before being seen")
+	}
+
+	world.Move(truth.CoordsToNode(3, 3))
+	if _, edges := world.ChangedEdges(); len(edges) == 0 {
+		t.Error("Moving revealed nothing")
+	}
+	if _, edges := world.ChangedEdges(); len(edges) != 0 {
+		t.Error("ChangedEdges didn't drain its changes")
+	}
+}
@@ -0,0 +1,216 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"math/rand"
+)
+
+// Monte Carlo tree search[1] with UCT[2] selection, over an implicit graph whose nodes are positions and whose edges are moves. Instead of searching the graph exhaustively, it
+// plays many random games (rollouts) from the root, growing a tree of the moves it has tried and steering later games towards the moves that have done well so far, while still
+// exploring the others now and then. It's an anytime algorithm: it can be stopped after any number of rollouts, and Best is the best move found so far.
+//
+// Games are scored by a reward function, called with the moves played from the root (the root first) when a game ends, which is when it reaches a node without successors or the
+// maximum depth. Rewards should be between 0 and 1. In a one player setting (a planning problem, a puzzle) the reward is just how good the outcome is; in a two player game, with
+// WithMCTSTwoPlayer, it's how good the outcome is for the player who made the last move, and the players are assumed to alternate.
+//
+// The tree doesn't merge positions reached by different orders of moves, so the graph is effectively unrolled into a tree; it grows by one node per rollout.
+//
+// [1] R. Coulom, "Efficient selectivity and backup operators in Monte-Carlo tree search", Computers and Games (2006)
+// [2] L. Kocsis and C. Szepesvári, "Bandit based Monte-Carlo planning", ECML (2006)
+type MCTS struct {
+	graph       ImplicitGraph
+	reward      func(path []Node) float64
+	src         *rand.Rand
+	rollout     func(node Node, succs []Node, src *rand.Rand) Node
+	exploration float64
+	maxDepth    int
+	twoPlayer   bool
+
+	root *mctsNode
+	path []Node
+}
+
+type MCTSOption func(*MCTS)
+
+// Plays rollouts with policy instead of uniformly random moves. The policy picks one of succs, the successors of node, and can use domain knowledge to make rollouts more
+// realistic; it must not hold on to succs.
+func WithMCTSRollout(policy func(node Node, succs []Node, src *rand.Rand) Node) MCTSOption {
+	return func(mcts *MCTS) {
+		mcts.rollout = policy
+	}
+}
+
+// Sets UCT's exploration constant, which weighs trying moves that have been played less against the ones that have done well so far. The default is sqrt(2), the theoretical
+// value for rewards between 0 and 1.
+func WithMCTSExploration(c float64) MCTSOption {
+	return func(mcts *MCTS) {
+		mcts.exploration = c
+	}
+}
+
+// Ends every game after depth moves from the root, scoring it wherever it got to. The default is 1000.
+func WithMCTSMaxDepth(depth int) MCTSOption {
+	return func(mcts *MCTS) {
+		mcts.maxDepth = depth
+	}
+}
+
+// Treats the graph as a two player game, with the players alternating moves, so that each player picks the moves best for themselves in the tree.
+func WithMCTSTwoPlayer() MCTSOption {
+	return func(mcts *MCTS) {
+		mcts.twoPlayer = true
+	}
+}
+
+// A node of the search tree. Value is the total reward of the games through it, from the point of view of the player who moved into it.
+type mctsNode struct {
+	node     Node
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []Node
+	expanded bool
+	visits   int
+	value    float64
+}
+
+// Sets up a search from root. The randomness of the rollouts comes from src.
+func NewMCTS(root Node, graph ImplicitGraph, reward func(path []Node) float64, src *rand.Rand, options ...MCTSOption) *MCTS {
+	mcts := &MCTS{
+		graph:       graph,
+		reward:      reward,
+		src:         src,
+		exploration: math.Sqrt2,
+		maxDepth:    1000,
+		root:        &mctsNode{node: root},
+	}
+	for _, option := range options {
+		option(mcts)
+	}
+	if mcts.rollout == nil {
+		mcts.rollout = func(node Node, succs []Node, src *rand.Rand) Node {
+			return succs[src.Intn(len(succs))]
+		}
+	}
+
+	return mcts
+}
+
+// Plays the given number of rollouts.
+func (mcts *MCTS) Run(rollouts int) {
+	for i := 0; i < rollouts; i++ {
+		mcts.iterate()
+	}
+}
+
+// Plays rollouts until ctx is cancelled or the budget (counting rollouts as expansions) runs out, and returns how many it played along with the reason it stopped. The tree is
+// usable whatever the error; stopping is the normal way for an anytime search to end, so with neither a deadline nor a budget RunCtx never returns.
+func (mcts *MCTS) RunCtx(ctx context.Context, budget SearchBudget) (rollouts int, err error) {
+	cancel := budget.canceller(ctx)
+	for {
+		if err := cancel.err(); err != nil {
+			return rollouts, err
+		}
+		mcts.iterate()
+		rollouts++
+	}
+}
+
+// The root's most played move, which is the most robust choice of move, and the average reward of the games that started with it (from the point of view of the player making
+// the move). Returns nil if no move has been played yet.
+func (mcts *MCTS) Best() (move Node, value float64) {
+	var best *mctsNode
+	for _, child := range mcts.root.children {
+		if best == nil || child.visits > best.visits {
+			best = child
+		}
+	}
+	if best == nil {
+		return nil, 0
+	}
+
+	return best.node, best.value / float64(best.visits)
+}
+
+// Makes move from the root, keeping what's been learned about the position it leads to so later searches can build on it. The move doesn't have to have been tried, or even be
+// a successor of the root.
+func (mcts *MCTS) Advance(move Node) {
+	for _, child := range mcts.root.children {
+		if child.node.ID() == move.ID() {
+			child.parent = nil
+			mcts.root = child
+			return
+		}
+	}
+
+	mcts.root = &mctsNode{node: move}
+}
+
+// One round of selection, expansion, rollout and backpropagation
+func (mcts *MCTS) iterate() {
+	mcts.path = append(mcts.path[:0], mcts.root.node)
+
+	// Selection: follow the best children while every move of the node has been tried
+	curr := mcts.root
+	for {
+		if !curr.expanded {
+			curr.untried = append([]Node(nil), mcts.graph.Successors(curr.node)...)
+			curr.expanded = true
+		}
+		if len(curr.untried) > 0 || len(curr.children) == 0 || len(mcts.path) > mcts.maxDepth {
+			break
+		}
+		curr = mcts.selectChild(curr)
+		mcts.path = append(mcts.path, curr.node)
+	}
+
+	// Expansion: add one untried move to the tree
+	if len(curr.untried) > 0 && len(mcts.path) <= mcts.maxDepth {
+		i := mcts.src.Intn(len(curr.untried))
+		child := &mctsNode{node: curr.untried[i], parent: curr}
+		curr.untried[i] = curr.untried[len(curr.untried)-1]
+		curr.untried = curr.untried[:len(curr.untried)-1]
+		curr.children = append(curr.children, child)
+		curr = child
+		mcts.path = append(mcts.path, curr.node)
+	}
+
+	// Rollout: play on from there with the rollout policy
+	treeDepth := len(mcts.path)
+	for node := curr.node; len(mcts.path) <= mcts.maxDepth; {
+		succs := mcts.graph.Successors(node)
+		if len(succs) == 0 {
+			break
+		}
+		node = mcts.rollout(node, succs, mcts.src)
+		mcts.path = append(mcts.path, node)
+	}
+	reward := mcts.reward(mcts.path)
+
+	// Backpropagation: the reward is for whoever made the last move, and in a two player game whoever made the move before that lost as much
+	if mcts.twoPlayer && (len(mcts.path)-treeDepth)%2 == 1 {
+		reward = 1 - reward
+	}
+	for ; curr != nil; curr = curr.parent {
+		curr.visits++
+		curr.value += reward
+		if mcts.twoPlayer {
+			reward = 1 - reward
+		}
+	}
+}
+
+// The child of node with the highest upper confidence bound
+func (mcts *MCTS) selectChild(node *mctsNode) *mctsNode {
+	var best *mctsNode
+	bestBound := math.Inf(-1)
+	logVisits := math.Log(float64(node.visits))
+	for _, child := range node.children {
+		bound := child.value/float64(child.visits) + mcts.exploration*math.Sqrt(logVisits/float64(child.visits))
+		if bound > bestBound {
+			best, bestBound = child, bound
+		}
+	}
+
+	return best
+}
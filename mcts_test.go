@@ -0,0 +1,76 @@
+package graph_test
+
+import (
+	"context"
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Nim with one heap: each move takes 1 to 3 stones, and whoever takes the last one wins. A node is the number of stones left.
+type nim struct{}
+
+func (nim) Successors(node graph.Node) []graph.Node {
+	var succs []graph.Node
+	for take := 1; take <= 3 && take <= node.ID(); take++ {
+		succs = append(succs, graph.GonumNode(node.ID()-take))
+	}
+	return succs
+}
+
+// The last mover won if they emptied the heap
+func nimReward(path []graph.Node) float64 {
+	if path[len(path)-1].ID() == 0 {
+		return 1
+	}
+	return 0
+}
+
+func TestMCTSNim(t *testing.T) {
+	// Leaving a multiple of 4 wins
+	for stones, want := range map[int]int{10: 8, 7: 4, 13: 12} {
+		mcts := graph.NewMCTS(graph.GonumNode(stones), nim{}, nimReward, rand.New(rand.NewSource(1)), graph.WithMCTSTwoPlayer())
+		mcts.Run(5000)
+		if move, value := mcts.Best(); move.ID() != want || value < 0.5 {
+			t.Errorf("From %d stones MCTS moved to %v (winning %v of games), want %d", stones, move, value, want)
+		}
+	}
+
+	// Replying to the opponent's move reuses the tree
+	mcts := graph.NewMCTS(graph.GonumNode(10), nim{}, nimReward, rand.New(rand.NewSource(2)), graph.WithMCTSTwoPlayer())
+	mcts.Run(5000)
+	mcts.Advance(graph.GonumNode(9))
+	if move, _ := mcts.Best(); move == nil {
+		t.Fatal("Advancing lost the subtree")
+	}
+	mcts.Run(1000)
+	if move, _ := mcts.Best(); move.ID() != 8 {
+		t.Errorf("After the opponent took 1 from 10, MCTS moved to %v, want 8", move)
+	}
+}
+
+func TestMCTSOnePlayer(t *testing.T) {
+	// Reach the goal of a maze in as few moves as possible; a short rollout limit means random walks only get there from nearby
+	maze := graph.GenerateMaze(3, 3, graph.RecursiveBacktracker, rand.New(rand.NewSource(4)))
+	start, goal := maze.CoordsToNode(1, 1), maze.CoordsToNode(5, 5)
+	reward := func(path []graph.Node) float64 {
+		for i, node := range path {
+			if node.ID() == goal.ID() {
+				return 1 / float64(i)
+			}
+		}
+		return 0
+	}
+
+	mcts := graph.NewMCTS(start, maze, reward, rand.New(rand.NewSource(1)), graph.WithMCTSMaxDepth(40))
+	rollouts, err := mcts.RunCtx(context.Background(), graph.SearchBudget{MaxExpansions: 3000, MaxDuration: time.Minute})
+	if err != graph.ErrBudgetExhausted || rollouts != 3000 {
+		t.Errorf("RunCtx stopped after %d rollouts with %v", rollouts, err)
+	}
+
+	path, _, _ := graph.AStar(start, goal, maze, nil, nil)
+	if move, _ := mcts.Best(); move.ID() != path[1].ID() {
+		t.Errorf("MCTS moved to %v, the shortest path goes to %v", move, path[1])
+	}
+}
@@ -0,0 +1,212 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// A Markov decision process: at every node an agent picks one of the node's actions, and each action leads to one of several nodes at random, collecting a reward on the way. A
+// node without actions is terminal, and worth nothing once reached. Unlike the deterministic planners, which find a path, solving an MDP finds a policy -- the best action at
+// every node -- since where the agent will end up after any step isn't known in advance.
+type MDP interface {
+	NodeList() []Node
+	Actions(node Node) []MDPAction
+}
+
+// An action available at a node. The probabilities of its outcomes should add up to 1.
+type MDPAction struct {
+	Outcomes []MDPOutcome
+}
+
+// One possible result of taking an action: ending up at Node with the given probability, collecting Reward.
+type MDPOutcome struct {
+	Node        Node
+	Probability float64
+	Reward      float64
+}
+
+// The solution to an MDP. Values holds each node's expected discounted reward when following the policy from it, and Actions the index (into Actions(node)) of the action to take
+// at each node, -1 at terminal nodes.
+type MDPPolicy struct {
+	Values  map[int]float64
+	Actions map[int]int
+}
+
+// Solves an MDP by value iteration[1]: every node's value is repeatedly set to the best expected reward of its actions given the current values of their outcomes, until no value
+// changes by more than tolerance, or maxIterations sweeps (0 for no limit) have been made. The policy is then whichever action is best under the final values. Returns the policy
+// and the number of sweeps it took.
+//
+// The discount, between 0 and 1, weighs rewards collected a step later; below 1 value iteration always converges, and with a discount of 1 it converges as long as every policy
+// eventually reaches a terminal node, as in a shortest path problem.
+//
+// [1] R. Bellman, "A Markovian decision process", Journal of Mathematics and Mechanics 6 (1957)
+func ValueIteration(mdp MDP, discount, tolerance float64, maxIterations int) (policy MDPPolicy, iterations int) {
+	nodes := mdp.NodeList()
+	values := make(map[int]float64, len(nodes))
+	next := make(map[int]float64, len(nodes))
+
+	for maxIterations == 0 || iterations < maxIterations {
+		iterations++
+		delta := 0.0
+		for _, node := range nodes {
+			_, value := bestAction(mdp.Actions(node), values, discount)
+			next[node.ID()] = value
+			delta = math.Max(delta, math.Abs(value-values[node.ID()]))
+		}
+		values, next = next, values
+		if delta <= tolerance {
+			break
+		}
+	}
+
+	return greedyPolicy(mdp, nodes, values, discount), iterations
+}
+
+// Solves an MDP by policy iteration[1]: starting from taking the first action everywhere, the current policy is evaluated (by sweeps like ValueIteration's, until no value
+// changes by more than tolerance) and then every node switches to the action that's best under those values, until the policy stops changing or maxIterations rounds (0 for no
+// limit) have been made. Each round is costlier than a sweep of value iteration, but far fewer are usually needed. Returns the policy and the number of rounds.
+//
+// Evaluation is cut off after policyEvaluationSweeps sweeps, as in modified policy iteration[2]. Otherwise, with a discount of 1, a policy that goes around in circles (which
+// taking the first action everywhere easily does) would never finish being evaluated; cut off, it just looks bad enough to be improved on. The discount is otherwise interpreted
+// as in ValueIteration.
+//
+// [1] R. A. Howard, "Dynamic Programming and Markov Processes", MIT Press (1960)
+// [2] M. L. Puterman and M. C. Shin, "Modified policy iteration algorithms for discounted Markov decision problems", Management Science 24 (1978)
+func PolicyIteration(mdp MDP, discount, tolerance float64, maxIterations int) (policy MDPPolicy, iterations int) {
+	nodes := mdp.NodeList()
+	policy = MDPPolicy{Values: make(map[int]float64, len(nodes)), Actions: make(map[int]int, len(nodes))}
+	for _, node := range nodes {
+		policy.Actions[node.ID()] = -1
+		if len(mdp.Actions(node)) > 0 {
+			policy.Actions[node.ID()] = 0
+		}
+	}
+
+	for maxIterations == 0 || iterations < maxIterations {
+		iterations++
+		evaluated := false
+		for sweep := 0; sweep < policyEvaluationSweeps && !evaluated; sweep++ {
+			delta := 0.0
+			for _, node := range nodes {
+				if a := policy.Actions[node.ID()]; a >= 0 {
+					value := actionValue(mdp.Actions(node)[a], policy.Values, discount)
+					delta = math.Max(delta, math.Abs(value-policy.Values[node.ID()]))
+					policy.Values[node.ID()] = value
+				}
+			}
+			evaluated = delta <= tolerance
+		}
+
+		stable := true
+		for _, node := range nodes {
+			actions := mdp.Actions(node)
+			curr := policy.Actions[node.ID()]
+			if curr < 0 {
+				continue
+			}
+
+			// Only switch for a real improvement, or ties could flip back and forth forever
+			best, value := bestAction(actions, policy.Values, discount)
+			if value > actionValue(actions[curr], policy.Values, discount)+tolerance {
+				policy.Actions[node.ID()] = best
+				stable = false
+			}
+		}
+		if stable && evaluated {
+			break
+		}
+	}
+
+	return policy, iterations
+}
+
+// The most sweeps PolicyIteration spends evaluating a policy before improving on it
+const policyEvaluationSweeps = 100
+
+// The expected discounted reward of taking action, given the values of the nodes it can lead to
+func actionValue(action MDPAction, values map[int]float64, discount float64) float64 {
+	var value float64
+	for _, outcome := range action.Outcomes {
+		value += outcome.Probability * (outcome.Reward + discount*values[outcome.Node.ID()])
+	}
+
+	return value
+}
+
+// The index and value of the best of actions, or -1 and 0 if there are none
+func bestAction(actions []MDPAction, values map[int]float64, discount float64) (best int, value float64) {
+	best, value = -1, 0.0
+	for i, action := range actions {
+		if v := actionValue(action, values, discount); best == -1 || v > value {
+			best, value = i, v
+		}
+	}
+
+	return best, value
+}
+
+func greedyPolicy(mdp MDP, nodes []Node, values map[int]float64, discount float64) MDPPolicy {
+	policy := MDPPolicy{Values: values, Actions: make(map[int]int, len(nodes))}
+	for _, node := range nodes {
+		policy.Actions[node.ID()], _ = bestAction(mdp.Actions(node), values, discount)
+	}
+
+	return policy
+}
+
+// Turns a graph into an MDP where the agent moves along edges but doesn't always go where it means to: at every node except goal, there's an action for each successor (in
+// order of ID) which reaches that successor with probability 1-slip, and slips to one of the node's other successors (picked uniformly) otherwise; the intended successor is always
+// an action's first outcome. Every move is rewarded with the negated cost of the edge actually taken, and goal is terminal, so a policy's values are its expected costs to the
+// goal, negated. With slip 0 the optimal values are exactly the negated shortest path costs, as a check on the deterministic planners; with some slip, the policy learns to avoid
+// routes where a misstep is expensive.
+//
+// Cost is resolved as usual: Argument > Interface > UniformCost.
+func NoisyGraphMDP(graph Graph, Cost func(Node, Node) float64, goal Node, slip float64) MDP {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	return &noisyGraphMDP{graph: graph, cost: Cost, goal: goal, slip: slip}
+}
+
+type noisyGraphMDP struct {
+	graph Graph
+	cost  func(Node, Node) float64
+	goal  Node
+	slip  float64
+}
+
+func (mdp *noisyGraphMDP) NodeList() []Node {
+	return mdp.graph.NodeList()
+}
+
+func (mdp *noisyGraphMDP) Actions(node Node) []MDPAction {
+	succs := mdp.graph.Successors(node)
+	if node.ID() == mdp.goal.ID() || len(succs) == 0 {
+		return nil
+	}
+	// Sorted, so the indices of the actions don't change between calls
+	succs = append([]Node(nil), succs...)
+	sort.Sort(byID(succs))
+
+	actions := make([]MDPAction, len(succs))
+	for i, intended := range succs {
+		actions[i].Outcomes = append(make([]MDPOutcome, 0, len(succs)), MDPOutcome{intended, 1 - mdp.slip, -mdp.cost(node, intended)})
+		if len(succs) == 1 {
+			actions[i].Outcomes[0].Probability = 1
+			continue
+		}
+
+		for _, succ := range succs {
+			if succ.ID() != intended.ID() {
+				actions[i].Outcomes = append(actions[i].Outcomes, MDPOutcome{succ, mdp.slip / float64(len(succs)-1), -mdp.cost(node, succ)})
+			}
+		}
+	}
+
+	return actions
+}
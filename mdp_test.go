@@ -0,0 +1,67 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMDPShortestPaths(t *testing.T) {
+	maze := graph.GenerateMaze(5, 5, graph.RecursiveBacktracker, rand.New(rand.NewSource(1)))
+	goal := maze.CoordsToNode(1, 1)
+	mdp := graph.NoisyGraphMDP(maze, nil, goal, 0)
+
+	vi, _ := graph.ValueIteration(mdp, 1, 1e-9, 0)
+	pi, _ := graph.PolicyIteration(mdp, 1, 1e-9, 0)
+	for _, node := range maze.NodeList() {
+		path, cost, _ := graph.AStar(node, goal, maze, nil, nil)
+		if path == nil {
+			continue
+		}
+		for name, policy := range map[string]graph.MDPPolicy{"value iteration": vi, "policy iteration": pi} {
+			if math.Abs(policy.Values[node.ID()]+cost) > 1e-6 {
+				t.Errorf("%s: value of %v is %v, shortest path costs %v", name, node, policy.Values[node.ID()], cost)
+			}
+			if a := policy.Actions[node.ID()]; len(path) > 1 && mdp.Actions(node)[a].Outcomes[0].Node.ID() != path[1].ID() {
+				t.Errorf("%s: policy at %v doesn't follow the shortest path", name, node)
+			}
+		}
+	}
+	if vi.Actions[goal.ID()] != -1 || pi.Actions[goal.ID()] != -1 {
+		t.Errorf("The goal isn't terminal")
+	}
+}
+
+func TestMDPSlip(t *testing.T) {
+	// From 0, going by 1 is shortest, but from 1 there's a chance of slipping to 4, which is very expensive to get out of
+	g := graph.NewGonumGraph(true)
+	for _, edge := range []struct {
+		from, to int
+		cost     float64
+	}{{0, 1, 1}, {0, 2, 1}, {1, 3, 1}, {1, 4, 1}, {4, 3, 100}, {2, 3, 3}} {
+		g.AddNode(graph.GonumNode(edge.from), []graph.Node{graph.GonumNode(edge.to)})
+		g.AddEdge(graph.GonumEdge{graph.GonumNode(edge.from), graph.GonumNode(edge.to)})
+		g.SetEdgeCost(graph.GonumEdge{graph.GonumNode(edge.from), graph.GonumNode(edge.to)}, edge.cost)
+	}
+	goal := graph.GonumNode(3)
+
+	for slip, via := range map[float64]int{0: 1, 0.2: 2} {
+		mdp := graph.NoisyGraphMDP(g, nil, goal, slip)
+		vi, _ := graph.ValueIteration(mdp, 1, 1e-9, 0)
+		pi, _ := graph.PolicyIteration(mdp, 1, 1e-9, 0)
+		for name, policy := range map[string]graph.MDPPolicy{"value iteration": vi, "policy iteration": pi} {
+			if a := policy.Actions[0]; a != via-1 {
+				t.Errorf("%s with slip %v: took action %d from 0, want the one to %d", name, slip, a, via)
+			}
+		}
+		if math.Abs(vi.Values[0]-pi.Values[0]) > 1e-6 {
+			t.Errorf("Value and policy iteration disagree with slip %v: %v and %v", slip, vi.Values[0], pi.Values[0])
+		}
+	}
+
+	// Tolerance 0 is never met, so the sweep limit stops it
+	if _, sweeps := graph.ValueIteration(graph.NoisyGraphMDP(g, nil, goal, 0.2), 0.5, 0, 2); sweeps != 2 {
+		t.Errorf("Value iteration ran %d sweeps, limited to 2", sweeps)
+	}
+}
@@ -0,0 +1,81 @@
+package graph
+
+// The bytes a map's header takes, and the number of entries it keeps in each bucket.
+const (
+	mapHeaderBytes = 48
+	mapBucketSize  = 8
+)
+
+// The bytes a TileGraph takes besides its tiles.
+const tileGraphBytes = 40
+
+// Roughly how many bytes g takes up, for deciding whether a graph will fit before loading it or which representation to load it into. The estimate counts the maps and
+// slices the graph keeps, sized the way Go sizes them, but not whatever the nodes themselves point to, so it's a lower bound for nodes that hold data of their own.
+//
+// GonumGraphs, DirectedGraphs, UndirectedGraphs, ImmutableGraphs and TileGraphs are measured from what they hold. Any other Graph is estimated as what the same nodes and edges would take as a GonumGraph, which means walking
+// every node's successors and predecessors, so that's as slow as listing them.
+func MemoryFootprint(g Graph) int64 {
+	switch g := g.(type) {
+	case *GonumGraph:
+		return g.memoryFootprint()
+	case *DirectedGraph:
+		return g.memoryFootprint()
+	case *UndirectedGraph:
+		return g.memoryFootprint()
+	case *TileGraph:
+		return tileGraphBytes + int64(len(g.tiles))
+	case *ImmutableGraph:
+		return g.memoryFootprint()
+	}
+
+	nodes := g.NodeList()
+	bytes := mapBytes(len(nodes), 8, 16) + 2*mapBytes(len(nodes), 8, 8)
+	for _, node := range nodes {
+		bytes += mapBytes(len(g.Successors(node)), 8, 8) + mapBytes(len(g.Predecessors(node)), 8, 8)
+	}
+	return bytes
+}
+
+func (graph *GonumGraph) memoryFootprint() int64 {
+	bytes := mapBytes(len(graph.nodeMap), 8, 16) + mapBytes(len(graph.successors), 8, 8) + mapBytes(len(graph.predecessors), 8, 8)
+	for id, succs := range graph.successors {
+		bytes += mapBytes(len(succs), 8, 8) + mapBytes(len(graph.predecessors[id]), 8, 8)
+	}
+	if graph.ordering == InsertionOrder {
+		bytes += mapBytes(len(graph.nodeSeq), 8, 8) + mapBytes(len(graph.edgeSeq), 16, 8)
+	}
+	return bytes
+}
+
+// Roughly the bytes a map of n entries takes, with keys and values of the given sizes: its header, and buckets of eight entries, doubled whenever there are more than eight
+// entries and they'd average more than six and a half a bucket. An empty map has no buckets yet.
+func mapBytes(n int, keyBytes, valueBytes int64) int64 {
+	if n == 0 {
+		return mapHeaderBytes
+	}
+	buckets := int64(1)
+	for n > mapBucketSize && float64(n) > 6.5*float64(buckets) {
+		buckets *= 2
+	}
+	// Each bucket has a byte of hash per entry, the entries themselves and a pointer to an overflow bucket
+	return mapHeaderBytes + buckets*(mapBucketSize*(1+keyBytes+valueBytes)+8)
+}
+
+// How many nodes graph has, or at least how many it has room for, as far as can be told cheaply, for sizing maps keyed by node; 0 if it can't be told.
+func nodeCount(graph interface{}) int {
+	switch g := graph.(type) {
+	case *GonumGraph:
+		return len(g.nodeMap)
+	case *DirectedGraph:
+		return len(g.nodeMap)
+	case *UndirectedGraph:
+		return len(g.nodeMap)
+	case *TileGraph:
+		return len(g.tiles)
+	case *ImmutableGraph:
+		return len(g.nodes)
+	case Graph:
+		return len(g.NodeList())
+	}
+	return 0
+}
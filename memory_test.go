@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Hides a GonumGraph behind the Graph interface, so it's estimated as any other Graph would be.
+type opaqueGraph struct {
+	graph.Graph
+}
+
+func TestMemoryFootprint(t *testing.T) {
+	empty := graph.MemoryFootprint(graph.NewGonumGraph(true))
+	if empty <= 0 {
+		t.Fatalf("An empty graph takes %d bytes", empty)
+	}
+
+	g := graph.NewGonumGraph(true)
+	graph.GnmRandomGraph(g, 100, 400, true, rand.New(rand.NewSource(1)))
+	full := graph.MemoryFootprint(g)
+	if full <= empty {
+		t.Errorf("A graph with 400 edges takes %d bytes, no more than the empty graph's %d", full, empty)
+	}
+	if got := graph.MemoryFootprint(opaqueGraph{g}); got != full {
+		t.Errorf("A graph estimated through the Graph interface takes %d bytes, where the GonumGraph itself takes %d", got, full)
+	}
+	directed := graph.NewDirectedGraph(100, 400)
+	for _, node := range g.NodeList() {
+		directed.AddNode(node, nil)
+	}
+	for _, edge := range g.EdgeList() {
+		directed.AddEdge(edge)
+	}
+	if got := graph.MemoryFootprint(directed); got != full {
+		t.Errorf("A DirectedGraph takes %d bytes, where a GonumGraph with the same edges takes %d", got, full)
+	}
+
+	g.SetOrdering(graph.InsertionOrder)
+	if ordered := graph.MemoryFootprint(g); ordered <= full {
+		t.Errorf("Keeping insertion order takes %d bytes, no more than the %d it took without", ordered, full)
+	}
+
+	if got := graph.MemoryFootprint(graph.NewTileGraph(10, 10, true)); got < 100 {
+		t.Errorf("A 10x10 tile graph takes %d bytes, less than a byte a tile", got)
+	}
+}
+
+func TestHintedGraphs(t *testing.T) {
+	for _, g := range []graph.MutableGraph{graph.NewDirectedGraph(4, 4), graph.NewUndirectedGraph(4, 4), graph.NewDirectedGraph(0, 10), graph.NewUndirectedGraph(-1, -1)} {
+		g.AddNode(graph.GonumNode(0), []graph.Node{graph.GonumNode(1)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(3)})
+		g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)}, 5)
+
+		if n := len(g.NodeList()); n != 4 {
+			t.Errorf("Expected 4 nodes, got %d", n)
+		}
+		path, cost, _ := graph.UniformCostSearch(graph.GonumNode(0), graph.GonumNode(3), g, nil)
+		if len(path) != 4 || cost != 7 {
+			t.Errorf("Expected a path of 4 nodes costing 7 through a graph with directed %t, got %v costing %v", g.IsDirected(), nodeIDs(path), cost)
+		}
+		if back := g.IsSuccessor(graph.GonumNode(1), graph.GonumNode(0)); back == g.IsDirected() {
+			t.Errorf("A graph with directed %t has 1 as a successor of 0 both ways round: %t", g.IsDirected(), back)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/gonum/graph"
+)
+
+// Records what a D*-Lite service does in set, returning a service that's the same except that its events pass through the recording first. The metrics are:
+//
+//	dstar_searches_total         searches run, the initial one and every replan
+//	dstar_replans_per_minute     replanning searches in the last minute
+//	dstar_search_seconds         a histogram of how long each search took
+//	dstar_expansions_total       nodes expanded by all the searches
+//	dstar_moves_total            moves the agent made
+//	dstar_queue_length           nodes left on the queue after the latest search
+//	dstar_plan_cost              the cost of the current plan, +Inf if there's no path
+//
+// Services instrumented with the same set add to the same counters, and the gauges show whichever reported last.
+func InstrumentDStarService(svc *graph.DStarService, set *Set) *graph.DStarService {
+	searches := set.Counter("dstar_searches_total", "D*-Lite searches run, the initial one and every replan.")
+	replans := set.Rate("dstar_replans_per_minute", "D*-Lite replanning searches in the last minute.")
+	latency := set.Histogram("dstar_search_seconds", "How long each D*-Lite search took.", DefaultLatencyBuckets)
+	expansions := set.Counter("dstar_expansions_total", "Nodes expanded by D*-Lite searches.")
+	moves := set.Counter("dstar_moves_total", "Moves made by D*-Lite agents.")
+	queue := set.Gauge("dstar_queue_length", "Nodes left on the D*-Lite queue after the latest search.")
+	cost := set.Gauge("dstar_plan_cost", "The cost of the current D*-Lite plan.")
+
+	events := make(chan graph.DStarEvent)
+	go func() {
+		defer close(events)
+		var last graph.SearchStats
+		first := true
+		for event := range svc.Events {
+			if n := event.Stats.Searches - last.Searches; n > 0 {
+				searches.Add(uint64(n))
+				if first {
+					n--
+				}
+				if n > 0 {
+					replans.Mark(uint64(n))
+				}
+				latency.ObserveDuration(event.Stats.LastSearch)
+			}
+			if n := event.Stats.Expansions - last.Expansions; n > 0 {
+				expansions.Add(uint64(n))
+			}
+			if event.Move != nil {
+				moves.Inc()
+			}
+			queue.Set(float64(event.Stats.QueueLen))
+			cost.Set(event.Cost)
+			last, first = event.Stats, false
+			events <- event
+		}
+	}()
+
+	return &graph.DStarService{Commands: svc.Commands, Changes: svc.Changes, Events: events, Done: svc.Done}
+}
+
+// Records the results of a batch of queries, from BatchShortestPaths, in set as they're received, returning a channel of the same results. The metrics are:
+//
+//	batch_queries_total{found="true"}     queries answered with a path, and with found="false" those with none
+//	batch_query_seconds                   a histogram of how long each query took
+//	batch_expansions_total                nodes expanded answering them
+//
+// The results must still be received until the channel is closed.
+func InstrumentBatch(results <-chan graph.PathResult, set *Set) <-chan graph.PathResult {
+	found := set.Counter("batch_queries_total", "Shortest path queries answered, by whether a path was found.", "found", "true")
+	notFound := set.Counter("batch_queries_total", "Shortest path queries answered, by whether a path was found.", "found", "false")
+	latency := set.Histogram("batch_query_seconds", "How long each shortest path query took.", DefaultLatencyBuckets)
+	expansions := set.Counter("batch_expansions_total", "Nodes expanded answering shortest path queries.")
+
+	out := make(chan graph.PathResult)
+	go func() {
+		defer close(out)
+		for result := range results {
+			if result.Path != nil {
+				found.Inc()
+			} else {
+				notFound.Inc()
+			}
+			latency.ObserveDuration(result.Elapsed)
+			expansions.Add(uint64(result.NodesExpanded))
+			out <- result
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestInstrumentDStarService(t *testing.T) {
+	truth := graph.GenerateMaze(6, 6, graph.RecursiveBacktracker, rand.New(rand.NewSource(3)))
+	start, goal := truth.CoordsToNode(1, 1), truth.CoordsToNode(11, 11)
+	world := graph.NewRevealingTileGraph(truth, start, 1)
+
+	set := NewSet("")
+	service := InstrumentDStarService(graph.NewDStarService(start, goal, world, nil, nil), set)
+	service.Commands <- graph.DStarResume
+	var last graph.DStarEvent
+	events := 0
+	for event := range service.Events {
+		last = event
+		events++
+	}
+	if err := <-service.Done; err != nil {
+		t.Fatal(err)
+	}
+
+	if n := set.Counter("dstar_moves_total", "").Value(); n != uint64(events-1) {
+		t.Errorf("Expected a move for every event but the first, got %d of %d", n, events)
+	}
+	if n := set.Counter("dstar_searches_total", "").Value(); n != uint64(last.Stats.Searches) {
+		t.Errorf("Expected %d searches, got %d", last.Stats.Searches, n)
+	}
+	if n := set.Counter("dstar_expansions_total", "").Value(); n != uint64(last.Stats.Expansions) {
+		t.Errorf("Expected %d expansions, got %d", last.Stats.Expansions, n)
+	}
+	if n := set.Rate("dstar_replans_per_minute", "").PerMinute(); n != float64(last.Stats.Searches-1) {
+		t.Errorf("Expected every search but the first to be a replan, got %v", n)
+	}
+	if cumulative, _ := set.Histogram("dstar_search_seconds", "", nil).snapshot(); cumulative[len(cumulative)-1] == 0 {
+		t.Error("Expected search latencies to be observed")
+	}
+	if cost := set.Gauge("dstar_plan_cost", "").Value(); cost != 0 {
+		t.Errorf("Expected no cost left at the goal, got %v", cost)
+	}
+}
+
+func TestInstrumentBatch(t *testing.T) {
+	g := graph.NewTileGraph(4, 4, true)
+	g.SetPassability(3, 3, false)
+	queries := []graph.PathQuery{
+		{Source: g.CoordsToNode(0, 0), Target: g.CoordsToNode(2, 2)},
+		{Source: g.CoordsToNode(0, 0), Target: g.CoordsToNode(0, 3)},
+		{Source: g.CoordsToNode(0, 0), Target: g.CoordsToNode(3, 3)},
+	}
+
+	set := NewSet("")
+	received, expanded := 0, 0
+	for result := range InstrumentBatch(graph.BatchShortestPaths(g, nil, nil, queries, 2), set) {
+		received++
+		expanded += result.NodesExpanded
+	}
+	if received != len(queries) {
+		t.Fatalf("Expected %d results, got %d", len(queries), received)
+	}
+	found := set.Counter("batch_queries_total", "", "found", "true").Value()
+	notFound := set.Counter("batch_queries_total", "", "found", "false").Value()
+	if found != 2 || notFound != 1 {
+		t.Errorf("Expected 2 found and 1 not, got %d and %d", found, notFound)
+	}
+	if n := set.Counter("batch_expansions_total", "").Value(); n != uint64(expanded) {
+		t.Errorf("Expected %d expansions, got %d", expanded, n)
+	}
+}
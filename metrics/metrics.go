@@ -0,0 +1,385 @@
+// Package metrics instruments long running planners and services: counters, gauges, latency histograms and per minute rates, collected in a Set that can be published
+// with expvar, as a JSON object of every metric, and served in the Prometheus text format for scraping, without depending on anything outside the standard library.
+//
+// InstrumentDStarService and InstrumentBatch record what the graph package's D*-Lite service and batch query engine do, and graphhttp's Server.Instrument its requests.
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bucket bounds for latencies in seconds, from a tenth of a millisecond to ten seconds.
+var DefaultLatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// A count that only goes up. Safe for concurrent use.
+type Counter struct {
+	n uint64
+}
+
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.n, n)
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Value() uint64 {
+	return atomic.LoadUint64(&c.n)
+}
+
+func (c *Counter) String() string {
+	return strconv.FormatUint(c.Value(), 10)
+}
+
+// A value that goes up and down. Safe for concurrent use.
+type Gauge struct {
+	bits uint64
+}
+
+func (g *Gauge) Set(v float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+func (g *Gauge) String() string {
+	return formatFloat(g.Value())
+}
+
+// Counts observations into buckets by the upper bounds they're under, keeping their sum, as a Prometheus histogram does. Safe for concurrent use.
+type Histogram struct {
+	bounds []float64
+
+	mu     sync.Mutex
+	counts []uint64 // counts[i] observations were at most bounds[i] and more than bounds[i-1]; the last is for those over every bound
+	sum    float64
+}
+
+func newHistogram(bounds []float64) *Histogram {
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+	return &Histogram{bounds: bounds, counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// Observes a duration in seconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// The cumulative count of observations at most each bound, then of all of them, and their sum.
+func (h *Histogram) snapshot() (cumulative []uint64, sum float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cumulative = make([]uint64, len(h.counts))
+	total := uint64(0)
+	for i, n := range h.counts {
+		total += n
+		cumulative[i] = total
+	}
+	return cumulative, h.sum
+}
+
+// As JSON: the count, the sum, and the cumulative count under each bound.
+func (h *Histogram) String() string {
+	cumulative, sum := h.snapshot()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `{"count": %d, "sum": %s, "buckets": {`, cumulative[len(cumulative)-1], jsonFloat(sum))
+	for i, bound := range h.bounds {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%q: %d", formatFloat(bound), cumulative[i])
+	}
+	buf.WriteString("}}")
+	return buf.String()
+}
+
+// How many events happened in the last minute, counted by the second. Safe for concurrent use.
+type Rate struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	seconds [60]int64 // The Unix second each slot is counting
+	counts  [60]uint64
+}
+
+func (r *Rate) Mark(n uint64) {
+	sec := r.clock().Unix()
+	slot := int(sec % 60)
+	r.mu.Lock()
+	if r.seconds[slot] != sec {
+		r.seconds[slot], r.counts[slot] = sec, 0
+	}
+	r.counts[slot] += n
+	r.mu.Unlock()
+}
+
+// Events in the minute up to now, including the current, partial, second.
+func (r *Rate) PerMinute() float64 {
+	sec := r.clock().Unix()
+	total := uint64(0)
+	r.mu.Lock()
+	for i, s := range r.seconds {
+		if sec-s < 60 && s <= sec {
+			total += r.counts[i]
+		}
+	}
+	r.mu.Unlock()
+	return float64(total)
+}
+
+func (r *Rate) String() string {
+	return formatFloat(r.PerMinute())
+}
+
+func (r *Rate) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// A named collection of metrics. A metric is identified by its name and labels, given as alternating names and values; asking for one that exists gives it back, so instrumented
+// code can share metrics by asking for them by the same name. Names are prefixed with the set's namespace and an underscore, if it has one.
+//
+// A Set is an expvar.Var, whose String is a JSON object of every metric keyed by its name and labels as Prometheus would write them, and an http.Handler serving the Prometheus
+// text format. Safe for concurrent use.
+type Set struct {
+	namespace string
+
+	mu      sync.Mutex
+	metrics map[string]*entry // By name and labels
+}
+
+type entry struct {
+	name, labels, help, kind string
+	metric                   expvar.Var
+}
+
+// Creates an empty Set whose metrics' names start with namespace.
+func NewSet(namespace string) *Set {
+	return &Set{namespace: namespace, metrics: make(map[string]*entry)}
+}
+
+// The counter with the name and labels, created with the help text if it's new. Panics if the name and labels are taken by a metric of another kind.
+func (s *Set) Counter(name, help string, labels ...string) *Counter {
+	return s.get(name, help, "counter", labels, func() expvar.Var { return &Counter{} }).(*Counter)
+}
+
+// The gauge with the name and labels, as for Counter.
+func (s *Set) Gauge(name, help string, labels ...string) *Gauge {
+	return s.get(name, help, "gauge", labels, func() expvar.Var { return &Gauge{} }).(*Gauge)
+}
+
+// The histogram with the name and labels, as for Counter, created with the bucket bounds if it's new.
+func (s *Set) Histogram(name, help string, bounds []float64, labels ...string) *Histogram {
+	return s.get(name, help, "histogram", labels, func() expvar.Var { return newHistogram(bounds) }).(*Histogram)
+}
+
+// The rate with the name and labels, as for Counter. Prometheus sees it as a gauge.
+func (s *Set) Rate(name, help string, labels ...string) *Rate {
+	return s.get(name, help, "gauge", labels, func() expvar.Var { return &Rate{} }).(*Rate)
+}
+
+func (s *Set) get(name, help, kind string, labels []string, create func() expvar.Var) expvar.Var {
+	if s.namespace != "" {
+		name = s.namespace + "_" + name
+	}
+	formatted := formatLabels(labels)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.metrics[name+formatted]
+	if !ok {
+		for _, other := range s.metrics {
+			if other.name == name && other.kind != kind {
+				panic(fmt.Sprintf("metrics: %s is already a %s", name, other.kind))
+			}
+		}
+		e = &entry{name: name, labels: formatted, help: help, kind: kind, metric: create()}
+		s.metrics[name+formatted] = e
+	}
+	if e.kind != kind {
+		panic(fmt.Sprintf("metrics: %s%s is a %s, not a %s", name, formatted, e.kind, kind))
+	}
+	return e.metric
+}
+
+// The set's metrics, ordered by name and then labels.
+func (s *Set) entries() []*entry {
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.metrics))
+	for _, e := range s.metrics {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+	sort.Sort(byName(entries))
+	return entries
+}
+
+// Every metric as a JSON object.
+func (s *Set) String() string {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range s.entries() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		value := e.metric.String()
+		if _, ok := e.metric.(*Histogram); !ok {
+			value = jsonValue(value)
+		}
+		fmt.Fprintf(&buf, "%q: %s", e.name+e.labels, value)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// Publishes the set with expvar under name, so it's served at /debug/vars. Like expvar.Publish, panics if the name is taken.
+func (s *Set) PublishExpvar(name string) {
+	expvar.Publish(name, s)
+}
+
+// Writes every metric in the Prometheus text exposition format, version 0.0.4.
+func (s *Set) WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	last := ""
+	for _, e := range s.entries() {
+		if e.name != last {
+			fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s %s\n", e.name, escapeHelp(e.help), e.name, e.kind)
+			last = e.name
+		}
+		h, ok := e.metric.(*Histogram)
+		if !ok {
+			fmt.Fprintf(&buf, "%s%s %s\n", e.name, e.labels, e.metric.String())
+			continue
+		}
+		cumulative, sum := h.snapshot()
+		for i, bound := range h.bounds {
+			fmt.Fprintf(&buf, "%s_bucket%s %d\n", e.name, withLabel(e.labels, "le", formatFloat(bound)), cumulative[i])
+		}
+		count := cumulative[len(cumulative)-1]
+		fmt.Fprintf(&buf, "%s_bucket%s %d\n", e.name, withLabel(e.labels, "le", "+Inf"), count)
+		fmt.Fprintf(&buf, "%s_sum%s %s\n%s_count%s %d\n", e.name, e.labels, formatFloat(sum), e.name, e.labels, count)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Serves the metrics in the Prometheus text format, for scraping.
+func (s *Set) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.WritePrometheus(w)
+}
+
+// Formats labels given as alternating names and values as Prometheus writes them, {name="value",...}, sorted by name, or as nothing if there are none.
+func formatLabels(labels []string) string {
+	if len(labels)%2 != 0 {
+		panic("metrics: labels must come in name, value pairs")
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"="+strconv.Quote(labels[i+1]))
+	}
+	sort.Strings(pairs)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, pair := range pairs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(pair)
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+// Adds a label to formatted labels.
+func withLabel(labels, name, value string) string {
+	label := name + "=" + strconv.Quote(value)
+	if labels == "" {
+		return "{" + label + "}"
+	}
+	return labels[:len(labels)-1] + "," + label + "}"
+}
+
+func escapeHelp(help string) string {
+	var buf bytes.Buffer
+	for _, r := range help {
+		switch r {
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// JSON has no infinities or NaN, so they're written as strings.
+func jsonFloat(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.Quote(formatFloat(v))
+	}
+	return formatFloat(v)
+}
+
+func jsonValue(s string) string {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return jsonFloat(v)
+	}
+	return strconv.Quote(s)
+}
+
+type byName []*entry
+
+func (entries byName) Len() int {
+	return len(entries)
+}
+
+func (entries byName) Less(i, j int) bool {
+	if entries[i].name != entries[j].name {
+		return entries[i].name < entries[j].name
+	}
+	return entries[i].labels < entries[j].labels
+}
+
+func (entries byName) Swap(i, j int) {
+	entries[i], entries[j] = entries[j], entries[i]
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet("test")
+	s.Counter("requests_total", "Requests.", "code", "200").Add(3)
+	s.Counter("requests_total", "Requests.", "code", "500").Inc()
+	if n := s.Counter("requests_total", "Requests.", "code", "200").Value(); n != 3 {
+		t.Errorf("Expected asking again to give the same counter, got %d", n)
+	}
+	s.Gauge("temperature", "Line one\nline two.").Set(math.Inf(1))
+	h := s.Histogram("latency_seconds", "Latency.", []float64{0.5, 0.1})
+	h.Observe(0.1)
+	h.Observe(0.3)
+	h.ObserveDuration(2 * time.Second)
+
+	want := `# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.1"} 1
+test_latency_seconds_bucket{le="0.5"} 2
+test_latency_seconds_bucket{le="+Inf"} 3
+test_latency_seconds_sum 2.4
+test_latency_seconds_count 3
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{code="200"} 3
+test_requests_total{code="500"} 1
+# HELP test_temperature Line one\nline two.
+# TYPE test_temperature gauge
+test_temperature +Inf
+`
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if got := w.Body.String(); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+
+	var vars map[string]interface{}
+	if err := json.Unmarshal([]byte(s.String()), &vars); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", s.String(), err)
+	}
+	if vars[`test_requests_total{code="500"}`] != 1.0 || vars["test_temperature"] != "+Inf" {
+		t.Errorf("Unexpected expvar values %v", vars)
+	}
+	if latency, ok := vars["test_latency_seconds"].(map[string]interface{}); !ok || latency["count"] != 3.0 {
+		t.Errorf("Unexpected histogram %v", vars["test_latency_seconds"])
+	}
+}
+
+func TestSetKindClash(t *testing.T) {
+	s := NewSet("")
+	s.Counter("x", "")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic asking for a counter as a gauge")
+		}
+	}()
+	s.Gauge("x", "", "label", "value")
+}
+
+func TestLabels(t *testing.T) {
+	if got := formatLabels([]string{"b", `say "hi"`, "a", "1"}); got != `{a="1",b="say \"hi\""}` {
+		t.Errorf("Unexpected labels %s", got)
+	}
+	if got := withLabel(`{a="1"}`, "le", "0.5"); got != `{a="1",le="0.5"}` {
+		t.Errorf("Unexpected labels %s", got)
+	}
+}
+
+func TestRate(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := &Rate{now: func() time.Time { return now }}
+	r.Mark(2)
+	now = now.Add(30 * time.Second)
+	r.Mark(3)
+	if n := r.PerMinute(); n != 5 {
+		t.Errorf("Expected 5 in the last minute, got %v", n)
+	}
+	now = now.Add(45 * time.Second)
+	if n := r.PerMinute(); n != 3 {
+		t.Errorf("Expected the first marks to have expired, got %v", n)
+	}
+	now = now.Add(time.Hour)
+	r.Mark(1)
+	if n := r.PerMinute(); n != 1 {
+		t.Errorf("Expected only the latest mark, got %v", n)
+	}
+}
@@ -0,0 +1,197 @@
+package graph
+
+import (
+	"sort"
+)
+
+// The arc a MultiGraph makes for NewArc: its ID, its ends, and its weight.
+type GonumArc struct {
+	ArcID int
+	F, T  Node
+	W     float64
+}
+
+func (arc GonumArc) ID() int {
+	return arc.ArcID
+}
+
+func (arc GonumArc) From() Node {
+	return arc.F
+}
+
+func (arc GonumArc) To() Node {
+	return arc.T
+}
+
+func (arc GonumArc) Weight() float64 {
+	return arc.W
+}
+
+// A MultiGraph is an ArcGraph that can join two nodes by any number of arcs, each with an ID of its own, as a transport network has several lines between the same two
+// stations. Arcs are IdentifiedArcs, either made by the graph with NewArc or of the caller's own type, carrying whatever else an arc needs to know, with AddArc.
+//
+// Run algorithms on it through ArcsAsGraph, which sees the lightest of the arcs between each pair of nodes, and use ArcPath to turn the paths they find back into the arcs
+// followed. Nodes are listed in order of ID, and a node's arcs in the order they were added. In an undirected graph, an arc from a node to itself is listed once.
+type MultiGraph struct {
+	directed bool
+	nodes    map[int]Node
+	arcs     map[int]IdentifiedArc
+	from, to map[int][]int // IDs of the arcs from and to each node, in the order they were added. An undirected graph only uses from, for every arc touching the node
+	nextID   int           // No arc's ID is this or more, so it's free for NewArc
+}
+
+// Creates an empty MultiGraph.
+func NewMultiGraph(directed bool) *MultiGraph {
+	return &MultiGraph{
+		directed: directed,
+		nodes:    make(map[int]Node),
+		arcs:     make(map[int]IdentifiedArc),
+		from:     make(map[int][]int),
+		to:       make(map[int][]int),
+	}
+}
+
+// Adds node, if there isn't a node with its ID in the graph already.
+func (g *MultiGraph) AddNode(node Node) {
+	if _, ok := g.nodes[node.ID()]; !ok {
+		g.nodes[node.ID()] = node
+	}
+}
+
+// Removes node and every arc to or from it.
+func (g *MultiGraph) RemoveNode(node Node) {
+	if _, ok := g.nodes[node.ID()]; !ok {
+		return
+	}
+	for _, id := range append(append([]int(nil), g.from[node.ID()]...), g.to[node.ID()]...) {
+		g.RemoveArc(id)
+	}
+	delete(g.nodes, node.ID())
+	delete(g.from, node.ID())
+	delete(g.to, node.ID())
+}
+
+// Adds an arc from one node to another with the given weight and an ID no other arc in the graph has, adding the nodes if they aren't in the graph, and returns it.
+func (g *MultiGraph) NewArc(from, to Node, weight float64) IdentifiedArc {
+	arc := GonumArc{ArcID: g.nextID, F: from, T: to, W: weight}
+	g.AddArc(arc)
+	return arc
+}
+
+// Adds arc, adding its ends if they aren't in the graph. An arc already in the graph with the same ID is replaced.
+func (g *MultiGraph) AddArc(arc IdentifiedArc) {
+	g.RemoveArc(arc.ID())
+	g.AddNode(arc.From())
+	g.AddNode(arc.To())
+
+	id := arc.ID()
+	g.arcs[id] = arc
+	if id >= g.nextID {
+		g.nextID = id + 1
+	}
+	from, to := arc.From().ID(), arc.To().ID()
+	if g.directed {
+		g.from[from] = append(g.from[from], id)
+		g.to[to] = append(g.to[to], id)
+		return
+	}
+	g.from[from] = append(g.from[from], id)
+	if to != from {
+		g.from[to] = append(g.from[to], id)
+	}
+}
+
+// Removes the arc with the given ID, if there is one. Its ends stay in the graph.
+func (g *MultiGraph) RemoveArc(id int) {
+	arc, ok := g.arcs[id]
+	if !ok {
+		return
+	}
+	delete(g.arcs, id)
+	from, to := arc.From().ID(), arc.To().ID()
+	g.from[from] = removeID(g.from[from], id)
+	if g.directed {
+		g.to[to] = removeID(g.to[to], id)
+	} else {
+		g.from[to] = removeID(g.from[to], id)
+	}
+}
+
+func removeID(ids []int, id int) []int {
+	for i, other := range ids {
+		if other == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
+
+// Returns the arc with the given ID, or nil if there isn't one.
+func (g *MultiGraph) Arc(id int) IdentifiedArc {
+	return g.arcs[id]
+}
+
+// Returns every arc in the graph, in order of ID.
+func (g *MultiGraph) ArcList() []Arc {
+	ids := make([]int, 0, len(g.arcs))
+	for id := range g.arcs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return g.arcsByID(ids)
+}
+
+// Returns every arc from one node to the other, or, in an undirected graph, between them either way round, in the order they were added.
+func (g *MultiGraph) ArcsBetween(from, to Node) []Arc {
+	var arcs []Arc
+	for _, arc := range g.ArcsFrom(from) {
+		end := arc.To()
+		if !g.directed && end.ID() == from.ID() {
+			end = arc.From()
+		}
+		if end.ID() == to.ID() {
+			arcs = append(arcs, arc)
+		}
+	}
+	return arcs
+}
+
+func (g *MultiGraph) arcsByID(ids []int) []Arc {
+	if len(ids) == 0 {
+		return nil
+	}
+	arcs := make([]Arc, len(ids))
+	for i, id := range ids {
+		arcs[i] = g.arcs[id]
+	}
+	return arcs
+}
+
+func (g *MultiGraph) NodeList() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+func (g *MultiGraph) NodeExists(node Node) bool {
+	_, ok := g.nodes[node.ID()]
+	return ok
+}
+
+func (g *MultiGraph) ArcsFrom(node Node) []Arc {
+	return g.arcsByID(g.from[node.ID()])
+}
+
+func (g *MultiGraph) ArcsTo(node Node) []Arc {
+	if !g.directed {
+		return g.ArcsFrom(node)
+	}
+	return g.arcsByID(g.to[node.ID()])
+}
+
+func (g *MultiGraph) IsDirected() bool {
+	return g.directed
+}
@@ -0,0 +1,66 @@
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A train line between two stations, with how long it takes
+type line struct {
+	graph.GonumArc
+	name string
+}
+
+func arcIDs(arcs []graph.Arc) []int {
+	var ids []int
+	for _, arc := range arcs {
+		ids = append(ids, arc.(graph.IdentifiedArc).ID())
+	}
+	return ids
+}
+
+func TestMultiGraph(t *testing.T) {
+	for _, directed := range []bool{true, false} {
+		g := graph.NewMultiGraph(directed)
+		a, b, c := graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(2)
+		slow := g.NewArc(a, b, 30)
+		g.AddArc(line{graph.GonumArc{ArcID: 10, F: a, T: b, W: 12}, "express"})
+		g.NewArc(b, c, 5)
+		direct := g.NewArc(a, c, 40)
+		loop := g.NewArc(c, c, 1)
+
+		if slow.ID() != 0 || direct.ID() != 12 || loop.ID() != 13 {
+			t.Fatalf("directed %t: expected IDs 0, 12 and 13, got %d, %d and %d", directed, slow.ID(), direct.ID(), loop.ID())
+		}
+		if ids := arcIDs(g.ArcsBetween(a, b)); !reflect.DeepEqual(ids, []int{0, 10}) {
+			t.Errorf("directed %t: expected both lines between a and b, got %v", directed, ids)
+		}
+		if ids := arcIDs(g.ArcsBetween(b, a)); directed && ids != nil || !directed && !reflect.DeepEqual(ids, []int{0, 10}) {
+			t.Errorf("directed %t: got the arcs %v from b to a", directed, ids)
+		}
+		if ids := arcIDs(g.ArcsFrom(c)); directed && !reflect.DeepEqual(ids, []int{13}) || !directed && !reflect.DeepEqual(ids, []int{11, 12, 13}) {
+			t.Errorf("directed %t: got the arcs %v from c", directed, ids)
+		}
+		if ids := arcIDs(g.ArcList()); !reflect.DeepEqual(ids, []int{0, 10, 11, 12, 13}) {
+			t.Errorf("directed %t: listed the arcs %v", directed, ids)
+		}
+
+		// The search sees the express, and ArcPath finds it again
+		path, cost, _ := graph.AStar(a, c, graph.ArcsAsGraph(g), nil, nil)
+		arcs := graph.ArcPath(g, path)
+		if cost != 17 || !reflect.DeepEqual(arcIDs(arcs), []int{10, 11}) || arcs[0].(line).name != "express" {
+			t.Errorf("directed %t: expected the express and the line on to c, costing 17, got %v costing %v", directed, arcIDs(arcs), cost)
+		}
+
+		g.RemoveArc(10)
+		if _, cost, _ := graph.AStar(a, c, graph.ArcsAsGraph(g), nil, nil); cost != 35 || g.Arc(10) != nil {
+			t.Errorf("directed %t: expected the slow line once the express was gone, costing 35, got %v", directed, cost)
+		}
+		g.RemoveNode(b)
+		if _, cost, _ := graph.AStar(a, c, graph.ArcsAsGraph(g), nil, nil); cost != 40 || g.NodeExists(b) || g.Arc(0) != nil || len(g.ArcsTo(c)) != 2 {
+			t.Errorf("directed %t: expected the direct line once b was gone, costing 40, got %v", directed, cost)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// A Contraction is one level of a multilevel coarsening: an undirected graph whose nodes each stand for a group of nodes of the finer graph it was built from.
+//
+// Graph's nodes are GonumNode(0)...GonumNode(n-1), and the cost of an edge is the total weight of the finer edges it replaces. Weight holds the total weight of each coarse node
+// (the number of original nodes it contains, since original nodes weigh 1), and Map maps every node of the finer level, by ID, to the coarse node it was merged into.
+type Contraction struct {
+	Graph  *GonumGraph
+	Weight map[int]float64
+	Map    map[int]Node
+}
+
+// Repeatedly contracts a heavy edge matching of the graph until it has at most minNodes nodes or stops shrinking appreciably. Each round visits nodes from lowest to highest degree and matches each
+// unmatched node with the unmatched neighbor it shares the heaviest edge with, so that heavy edges end up hidden inside coarse nodes. This is the coarsening phase of METIS[1], and it's useful for any
+// algorithm that works hierarchically (solve on the small graph, then project the solution back and refine).
+//
+// The first Contraction maps the nodes of graph, each following one maps the nodes of the previous Contraction's Graph. Edge weights come from Cost, the graph's Coster, or UniformCost, and direction is ignored.
+//
+// [1] G. Karypis, V. Kumar, "A Fast and High Quality Multilevel Scheme for Partitioning Irregular Graphs", SIAM Journal on Scientific Computing 20(1), 1998
+func Coarsen(graph Graph, Cost func(Node, Node) float64, minNodes int) []*Contraction {
+	pg := newPartGraph(graph, Cost)
+	levels := make([]*Contraction, 0)
+	nodes := pg.nodes
+	for _, step := range pg.coarsenAll(minNodes) {
+		c := &Contraction{Graph: step.coarse.gonumGraph(), Weight: make(map[int]float64, len(step.coarse.weight)), Map: make(map[int]Node, len(step.cmap))}
+		for i, w := range step.coarse.weight {
+			c.Weight[i] = w
+		}
+		for i, ci := range step.cmap {
+			c.Map[nodes[i].ID()] = GonumNode(ci)
+		}
+		levels = append(levels, c)
+		nodes = step.coarse.nodes
+	}
+
+	return levels
+}
+
+type coarseStep struct {
+	coarse *partGraph
+	cmap   []int // fine index -> coarse index
+}
+
+func (pg *partGraph) coarsenAll(minNodes int) []coarseStep {
+	steps := make([]coarseStep, 0)
+	curr := pg
+	for len(curr.nodes) > minNodes {
+		coarse, cmap := curr.coarsen()
+		if float64(len(coarse.nodes)) > 0.95*float64(len(curr.nodes)) {
+			break
+		}
+		steps = append(steps, coarseStep{coarse, cmap})
+		curr = coarse
+	}
+
+	return steps
+}
+
+// Contracts a heavy edge matching, returning the coarse graph and the fine index -> coarse index map.
+func (pg *partGraph) coarsen() (*partGraph, []int) {
+	n := len(pg.nodes)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Stable(byKey{n, func(i int) float64 { return float64(len(pg.adj[order[i]])) }, func(i, j int) { order[i], order[j] = order[j], order[i] }})
+
+	match := make([]int, n)
+	for i := range match {
+		match[i] = -1
+	}
+	for _, u := range order {
+		if match[u] != -1 {
+			continue
+		}
+		best, bestW := u, math.Inf(-1)
+		for v, w := range pg.adj[u] {
+			if match[v] == -1 && v != u && (w > bestW || w == bestW && v < best) {
+				best, bestW = v, w
+			}
+		}
+		match[u], match[best] = best, u
+	}
+
+	cmap := make([]int, n)
+	for i := range cmap {
+		cmap[i] = -1
+	}
+	cn := 0
+	for _, u := range order {
+		if cmap[u] == -1 {
+			cmap[u], cmap[match[u]] = cn, cn
+			cn++
+		}
+	}
+
+	coarse := &partGraph{nodes: make([]Node, cn), index: make(map[int]int, cn), adj: make([]map[int]float64, cn), weight: make([]float64, cn)}
+	for i := 0; i < cn; i++ {
+		coarse.nodes[i] = GonumNode(i)
+		coarse.index[i] = i
+		coarse.adj[i] = make(map[int]float64)
+	}
+	for u, neighbors := range pg.adj {
+		cu := cmap[u]
+		for v, w := range neighbors {
+			if cv := cmap[v]; cv != cu {
+				coarse.adj[cu][cv] += w
+			}
+		}
+	}
+	for u, w := range pg.weight {
+		coarse.weight[cmap[u]] += w
+	}
+
+	return coarse, cmap
+}
+
+func (pg *partGraph) gonumGraph() *GonumGraph {
+	g := NewPreAllocatedGonumGraph(false, len(pg.nodes))
+	for i := range pg.nodes {
+		g.AddNode(GonumNode(i), nil)
+	}
+	for i, neighbors := range pg.adj {
+		for j, w := range neighbors {
+			if i < j {
+				e := GonumEdge{H: GonumNode(i), T: GonumNode(j)}
+				g.AddEdge(e)
+				g.SetEdgeCost(e, w)
+			}
+		}
+	}
+
+	return g
+}
+
+// Partitions the graph into k parts of roughly equal size with few edges between them, using the multilevel scheme of METIS: the graph is coarsened by heavy edge matching (see Coarsen),
+// the coarsest graph is partitioned by greedy region growing, and the partition is projected back level by level, improving it at each level with boundary refinement.
+//
+// Imbalance is the allowed relative excess over a perfectly balanced part, e.g. 0.03 permits each part to hold up to 3% more than n/k nodes. The refinement never violates the balance
+// constraint; if the initial partition does (which can happen on very lumpy coarse graphs) it also moves nodes out of overweight parts.
+//
+// Edge weights come from Cost, the graph's Coster, or UniformCost, and direction is ignored. The returned cut is the total weight of the edges between parts.
+func MultilevelPartition(graph Graph, Cost func(Node, Node) float64, k int, imbalance float64) (parts [][]Node, cut float64) {
+	pg := newPartGraph(graph, Cost)
+	n := len(pg.nodes)
+	if k < 1 {
+		k = 1
+	}
+
+	total := 0.0
+	for _, w := range pg.weight {
+		total += w
+	}
+	maxWeight := math.Max((1+imbalance)*total/float64(k), math.Ceil(total/float64(k)))
+
+	steps := pg.coarsenAll(20 * k)
+	coarsest := pg
+	if len(steps) > 0 {
+		coarsest = steps[len(steps)-1].coarse
+	}
+
+	part := coarsest.growPartition(k, total/float64(k))
+	coarsest.refine(part, k, maxWeight, 8)
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		fine := pg
+		if i > 0 {
+			fine = steps[i-1].coarse
+		}
+
+		finePart := make([]int, len(fine.nodes))
+		for u, cu := range steps[i].cmap {
+			finePart[u] = part[cu]
+		}
+		part = finePart
+		fine.refine(part, k, maxWeight, 8)
+	}
+
+	parts = make([][]Node, k)
+	for i := 0; i < n; i++ {
+		parts[part[i]] = append(parts[part[i]], pg.nodes[i])
+	}
+
+	return parts, pg.cut(part)
+}
+
+// Greedily grows k regions one after another from the heaviest unassigned node, always adding the unassigned node most strongly connected to the region, until the region reaches the target weight.
+// The last region gets whatever is left.
+func (pg *partGraph) growPartition(k int, target float64) []int {
+	n := len(pg.nodes)
+	part := make([]int, n)
+	for i := range part {
+		part[i] = -1
+	}
+
+	for p := 0; p < k-1; p++ {
+		weight := 0.0
+		conn := make(map[int]float64)
+		for weight < target {
+			next, bestConn := -1, -1.0
+			for u, c := range conn {
+				if c > bestConn || c == bestConn && u < next {
+					next, bestConn = u, c
+				}
+			}
+			if next == -1 {
+				// Start (or restart, for disconnected graphs) from the heaviest unassigned node
+				for u := 0; u < n; u++ {
+					if part[u] == -1 && (next == -1 || pg.weight[u] > pg.weight[next]) {
+						next = u
+					}
+				}
+				if next == -1 {
+					break
+				}
+			}
+
+			part[next] = p
+			weight += pg.weight[next]
+			delete(conn, next)
+			for v, w := range pg.adj[next] {
+				if part[v] == -1 {
+					conn[v] += w
+				}
+			}
+		}
+	}
+
+	for u := range part {
+		if part[u] == -1 {
+			part[u] = k - 1
+		}
+	}
+
+	return part
+}
+
+// Greedy k-way boundary refinement: repeatedly moves boundary nodes to the neighboring part that reduces the cut the most, as long as no part exceeds maxWeight.
+// Moves out of overweight parts are made even if they increase the cut, to restore balance.
+func (pg *partGraph) refine(part []int, k int, maxWeight float64, passes int) {
+	weights := make([]float64, k)
+	for u, p := range part {
+		weights[p] += pg.weight[u]
+	}
+
+	for pass := 0; pass < passes; pass++ {
+		moved := false
+		for u := range pg.nodes {
+			from := part[u]
+
+			conn := make(map[int]float64)
+			for v, w := range pg.adj[u] {
+				conn[part[v]] += w
+			}
+
+			overweight := weights[from] > maxWeight
+			best, bestGain := -1, 0.0
+			for to := 0; to < k; to++ {
+				if to == from || weights[to]+pg.weight[u] > maxWeight {
+					continue
+				}
+				if _, adjacent := conn[to]; !adjacent && !overweight {
+					continue
+				}
+
+				gain := conn[to] - conn[from]
+				if best == -1 || gain > bestGain || gain == bestGain && weights[to] < weights[best] {
+					best, bestGain = to, gain
+				}
+			}
+
+			if best == -1 {
+				continue
+			}
+			if bestGain > 0 || overweight || bestGain == 0 && weights[best]+pg.weight[u] < weights[from] {
+				part[u] = best
+				weights[from] -= pg.weight[u]
+				weights[best] += pg.weight[u]
+				moved = true
+			}
+		}
+
+		if !moved {
+			break
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package graph
+
+// A DirectedGraph is a GonumGraph that's always directed, for code that wants its types to say which kind of graph it's holding. It's a MutableGraph and a CostGraph, so
+// every algorithm in the package runs on it, and it can be changed between runs, or between a D*-Lite instance's Updates, like any other GonumGraph.
+//
+// It differs from a bare GonumGraph in what AddEdge does: the edge's head is added if it's missing, rather than the edge being dropped, and adding an edge that's already there
+// leaves its cost alone rather than resetting it to 1.
+type DirectedGraph struct {
+	GonumGraph
+}
+
+// Makes an empty directed graph with room for about nHint nodes and mHint edges, so that loading a graph whose size is known up front doesn't spend its time growing maps.
+// The hints only size the graph's maps: it grows past them as any other graph would, and hints of 0 or less make no room at all.
+func NewDirectedGraph(nHint, mHint int) *DirectedGraph {
+	return &DirectedGraph{*newHintedGraph(true, nHint, mHint)}
+}
+
+// Adds the edge from e's head to its tail, and whichever of them isn't in the graph yet. A new edge costs 1 until SetEdgeCost says otherwise.
+func (graph *DirectedGraph) AddEdge(e Edge) {
+	graph.GonumGraph.addEdge(e)
+}
+
+// A DirectedGraph can't be made undirected: SetDirected(false) panics.
+func (graph *DirectedGraph) SetDirected(directed bool) {
+	if !directed {
+		panic("A DirectedGraph can't be made undirected")
+	}
+}
+
+// An UndirectedGraph is a GonumGraph that's never directed, as DirectedGraph is one that always is, with the same AddEdge. Every edge goes both ways at the same cost.
+type UndirectedGraph struct {
+	GonumGraph
+}
+
+// As NewDirectedGraph, but undirected. Each of the mHint edges counts once, though it's stored both ways round.
+func NewUndirectedGraph(nHint, mHint int) *UndirectedGraph {
+	return &UndirectedGraph{*newHintedGraph(false, nHint, mHint)}
+}
+
+// Adds the edge between e's ends, and whichever of them isn't in the graph yet. A new edge costs 1 until SetEdgeCost says otherwise.
+func (graph *UndirectedGraph) AddEdge(e Edge) {
+	graph.GonumGraph.addEdge(e)
+}
+
+// An UndirectedGraph can't be made directed: SetDirected(true) panics.
+func (graph *UndirectedGraph) SetDirected(directed bool) {
+	if directed {
+		panic("An UndirectedGraph can't be made directed")
+	}
+}
+
+// Adds e and its ends, keeping the cost of an edge that's already there.
+func (graph *GonumGraph) addEdge(e Edge) {
+	if graph.IsSuccessor(e.Head(), e.Tail()) {
+		return
+	}
+	graph.AddNode(e.Head(), nil)
+	graph.AddEdge(e)
+}
@@ -0,0 +1,123 @@
+package graph_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestDirectedGraph(t *testing.T) {
+	g := graph.NewDirectedGraph(0, 0)
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)})
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)})
+	if !g.NodeExists(graph.GonumNode(0)) || !g.IsSuccessor(graph.GonumNode(0), graph.GonumNode(1)) {
+		t.Fatal("AddEdge didn't add the edge's head")
+	}
+	if g.IsSuccessor(graph.GonumNode(1), graph.GonumNode(0)) || !g.IsPredecessor(graph.GonumNode(1), graph.GonumNode(0)) {
+		t.Error("A DirectedGraph's edge goes both ways")
+	}
+
+	edge := graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}
+	g.SetEdgeCost(edge, 3)
+	g.AddEdge(edge)
+	if cost := g.Cost(graph.GonumNode(0), graph.GonumNode(1)); cost != 3 {
+		t.Errorf("Adding an edge again changed its cost to %v", cost)
+	}
+
+	g.RemoveEdge(edge)
+	if g.IsSuccessor(graph.GonumNode(0), graph.GonumNode(1)) || !g.NodeExists(graph.GonumNode(0)) {
+		t.Error("RemoveEdge didn't remove just the edge")
+	}
+	g.RemoveNode(graph.GonumNode(1))
+	if g.NodeExists(graph.GonumNode(1)) || len(g.Predecessors(graph.GonumNode(2))) != 0 {
+		t.Error("RemoveNode left the node or its edges behind")
+	}
+
+	g.SetDirected(true)
+	defer func() {
+		if recover() == nil {
+			t.Error("Making a DirectedGraph undirected didn't panic")
+		}
+	}()
+	g.SetDirected(false)
+}
+
+func TestUndirectedGraph(t *testing.T) {
+	g := graph.NewUndirectedGraph(3, 2)
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)})
+	g.AddEdge(graph.GonumEdge{H: graph.GonumNode(2), T: graph.GonumNode(1)})
+	g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(2)}, 4)
+	if g.IsDirected() || !g.IsSuccessor(graph.GonumNode(1), graph.GonumNode(0)) || g.Cost(graph.GonumNode(2), graph.GonumNode(1)) != 4 {
+		t.Fatal("An UndirectedGraph's edges don't go both ways at the same cost")
+	}
+
+	ids := nodeIDs(g.Successors(graph.GonumNode(1)))
+	sort.Ints(ids)
+	if !reflect.DeepEqual(ids, []int{0, 2}) {
+		t.Errorf("Expected 1's neighbours to be 0 and 2, got %v", ids)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Making an UndirectedGraph directed didn't panic")
+		}
+	}()
+	g.SetDirected(true)
+}
+
+// Changes a graph between D*-Lite's updates, and between a Planner's queries, checking each time that they agree with a fresh A* search.
+func TestMutableGraphsDuringSearches(t *testing.T) {
+	src := rand.New(rand.NewSource(11))
+	for _, g := range []graph.MutableGraph{graph.NewDirectedGraph(50, 200), graph.NewUndirectedGraph(50, 100)} {
+		for i := 0; i < 50; i++ {
+			g.AddNode(graph.GonumNode(i), nil)
+		}
+		for i := 0; i < 49; i++ {
+			g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode(i + 1)})
+		}
+		for i := 0; i < 150; i++ {
+			g.AddEdge(graph.GonumEdge{H: graph.GonumNode(src.Intn(50)), T: graph.GonumNode(src.Intn(50))})
+		}
+		for _, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, 1+src.Float64())
+		}
+
+		start, goal := graph.GonumNode(0), graph.GonumNode(49)
+		ds := graph.InitDStar(start, goal, g, nil, nil)
+		planner := graph.NewPlanner(g, nil, nil)
+		position := graph.Node(start)
+		for round := 0; position.ID() != goal.ID(); round++ {
+			_, want, _ := graph.AStar(position, goal, g, nil, nil)
+			if _, got, err := ds.Path(); err != nil || !graph.DefaultTolerance(got, want) {
+				t.Fatalf("Round %d with directed %t: D*-Lite's path costs %v (%v), A*'s %v", round, g.IsDirected(), got, err, want)
+			}
+			if _, got, _ := planner.AStar(position, goal); !graph.DefaultTolerance(got, want) {
+				t.Fatalf("Round %d with directed %t: the Planner's path costs %v, A*'s %v", round, g.IsDirected(), got, want)
+			}
+
+			next, err := ds.Step()
+			if err != nil {
+				t.Fatalf("Round %d with directed %t: %v", round, g.IsDirected(), err)
+			}
+			position = next
+
+			// Reprice a few edges and add a new one, keeping the chain from 0 to 49 so there's always a path
+			var changed []graph.Edge
+			edges := g.EdgeList()
+			for i := 0; i < 3; i++ {
+				edge := edges[src.Intn(len(edges))]
+				g.SetEdgeCost(edge, 1+3*src.Float64())
+				changed = append(changed, edge)
+			}
+			added := graph.GonumEdge{H: graph.GonumNode(src.Intn(50)), T: graph.GonumNode(src.Intn(50))}
+			if !g.IsSuccessor(added.H, added.T) {
+				g.AddEdge(added)
+				changed = append(changed, added)
+			}
+			ds.Update(nil, changed)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package graph
+
+/* Deterministic constructors for classic graphs. Like the random generators they empty dst first, and label their nodes GonumNode(0)...GonumNode(n-1) unless stated otherwise. */
+
+// Fills dst with the complete graph on n nodes. If directed, every ordered pair of distinct nodes gets an edge.
+func CompleteGraph(dst MutableGraph, n int, directed bool) {
+	dst.EmptyGraph()
+	dst.SetDirected(directed)
+	addNodes(dst, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(j)})
+			if directed {
+				dst.AddEdge(GonumEdge{H: GonumNode(j), T: GonumNode(i)})
+			}
+		}
+	}
+}
+
+// Fills dst with the undirected complete bipartite graph K(m,n). The first side is GonumNode(0)...GonumNode(m-1) and the second is GonumNode(m)...GonumNode(m+n-1).
+func CompleteBipartiteGraph(dst MutableGraph, m, n int) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	addNodes(dst, m+n)
+	for i := 0; i < m; i++ {
+		for j := m; j < m+n; j++ {
+			dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(j)})
+		}
+	}
+}
+
+// Fills dst with the path 0-1-...-(n-1). If directed, the edges point from lower to higher IDs.
+func PathGraph(dst MutableGraph, n int, directed bool) {
+	dst.EmptyGraph()
+	dst.SetDirected(directed)
+	addNodes(dst, n)
+	for i := 0; i+1 < n; i++ {
+		dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(i + 1)})
+	}
+}
+
+// Fills dst with the cycle 0-1-...-(n-1)-0. If directed, the edges point from lower to higher IDs, with the closing edge going from n-1 to 0.
+// A cycle needs at least 3 nodes; for smaller n this is the same as PathGraph.
+func CycleGraph(dst MutableGraph, n int, directed bool) {
+	PathGraph(dst, n, directed)
+	if n >= 3 {
+		dst.AddEdge(GonumEdge{H: GonumNode(n - 1), T: GonumNode(0)})
+	}
+}
+
+// Fills dst with the undirected star with n leaves: GonumNode(0) is the center, and GonumNode(1)...GonumNode(n) are the leaves.
+func StarGraph(dst MutableGraph, n int) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	addNodes(dst, n+1)
+	for i := 1; i <= n; i++ {
+		dst.AddEdge(GonumEdge{H: GonumNode(0), T: GonumNode(i)})
+	}
+}
+
+// Fills dst with the undirected d-dimensional hypercube: 2^d nodes, with an edge between two nodes if their IDs differ in exactly one bit.
+func HypercubeGraph(dst MutableGraph, d int) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	n := 1 << uint(d)
+	addNodes(dst, n)
+	for i := 0; i < n; i++ {
+		for b := 0; b < d; b++ {
+			if j := i ^ (1 << uint(b)); i < j {
+				dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(j)})
+			}
+		}
+	}
+}
+
+// Fills dst with the Petersen graph: the outer cycle 0-1-2-3-4, spokes i-(i+5), and the inner pentagram 5-7-9-6-8. It's a 3-regular non-planar graph with 10 nodes and 15 edges,
+// and a favorite counterexample.
+func PetersenGraph(dst MutableGraph) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	addNodes(dst, 10)
+	for i := 0; i < 5; i++ {
+		dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode((i + 1) % 5)})
+		dst.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(i + 5)})
+		dst.AddEdge(GonumEdge{H: GonumNode(i + 5), T: GonumNode((i+2)%5 + 5)})
+	}
+}
+
+// Each member's higher numbered friends in Zachary's karate club
+var karateClub = [][]int{
+	0:  {1, 2, 3, 4, 5, 6, 7, 8, 10, 11, 12, 13, 17, 19, 21, 31},
+	1:  {2, 3, 7, 13, 17, 19, 21, 30},
+	2:  {3, 7, 8, 9, 13, 27, 28, 32},
+	3:  {7, 12, 13},
+	4:  {6, 10},
+	5:  {6, 10, 16},
+	6:  {16},
+	8:  {30, 32, 33},
+	9:  {33},
+	13: {33},
+	14: {32, 33},
+	15: {32, 33},
+	18: {32, 33},
+	19: {33},
+	20: {32, 33},
+	22: {32, 33},
+	23: {25, 27, 29, 32, 33},
+	24: {25, 27, 31},
+	25: {31},
+	26: {29, 33},
+	27: {33},
+	28: {31, 33},
+	29: {32, 33},
+	30: {32, 33},
+	31: {32, 33},
+	32: {33},
+	33: nil,
+}
+
+// The members who sided with the instructor (node 0) when the club split; everyone else followed the administrator (node 33)
+var karateInstructor = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 10, 11, 12, 13, 16, 17, 19, 21}
+
+// Fills dst with Zachary's karate club network[1]: 34 members of a university karate club and the 78 friendships between them, observed shortly before the club split in two.
+// It's the standard small benchmark for community detection, so the nodes are BlockNodes whose Block is the faction each member joined after the split: 0 for the instructor's (node 0)
+// and 1 for the administrator's (node 33).
+//
+// [1] W. W. Zachary, "An Information Flow Model for Conflict and Fission in Small Groups", Journal of Anthropological Research 33(4), 1977
+func KarateClubGraph(dst MutableGraph) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+
+	nodes := make([]Node, len(karateClub))
+	for i := range nodes {
+		nodes[i] = BlockNode{Id: i, Block: 1}
+	}
+	for _, i := range karateInstructor {
+		nodes[i] = BlockNode{Id: i, Block: 0}
+	}
+	for _, node := range nodes {
+		dst.AddNode(node, nil)
+	}
+
+	for i, friends := range karateClub {
+		for _, j := range friends {
+			dst.AddEdge(GonumEdge{H: nodes[i], T: nodes[j]})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+// Checks the node count, edge count and that every node has the given degree (if degree >= 0)
+func checkShape(t *testing.T, name string, g *graph.GonumGraph, nodes, edges, degree int) {
+	if n := len(g.NodeList()); n != nodes {
+		t.Errorf("%s has %d nodes, expected %d", name, n, nodes)
+	}
+	m := len(g.EdgeList())
+	if !g.IsDirected() {
+		m /= 2
+	}
+	if m != edges {
+		t.Errorf("%s has %d edges, expected %d", name, m, edges)
+	}
+	if degree < 0 {
+		return
+	}
+	for _, node := range g.NodeList() {
+		if d := len(g.Successors(node)); d != degree {
+			t.Errorf("%s: node %v has degree %d, expected %d", name, node, d, degree)
+		}
+	}
+}
+
+func TestNamedGraphs(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+
+	graph.CompleteGraph(g, 6, false)
+	checkShape(t, "K6", g, 6, 15, 5)
+	graph.CompleteGraph(g, 4, true)
+	checkShape(t, "Directed K4", g, 4, 12, 3)
+
+	graph.CompleteBipartiteGraph(g, 3, 4)
+	checkShape(t, "K3,4", g, 7, 12, -1)
+	if g.IsAdjacent(graph.GonumNode(0), graph.GonumNode(1)) {
+		t.Error("K3,4 has an edge within a side")
+	}
+
+	graph.PathGraph(g, 5, false)
+	checkShape(t, "P5", g, 5, 4, -1)
+	graph.CycleGraph(g, 5, false)
+	checkShape(t, "C5", g, 5, 5, 2)
+	graph.CycleGraph(g, 5, true)
+	checkShape(t, "Directed C5", g, 5, 5, 1)
+
+	graph.StarGraph(g, 7)
+	checkShape(t, "S7", g, 8, 7, -1)
+	if d := len(g.Successors(graph.GonumNode(0))); d != 7 {
+		t.Errorf("Star center has degree %d, expected 7", d)
+	}
+
+	graph.HypercubeGraph(g, 4)
+	checkShape(t, "Q4", g, 16, 32, 4)
+
+	graph.PetersenGraph(g)
+	checkShape(t, "Petersen", g, 10, 15, 3)
+	if graph.IsPlanar(g) {
+		t.Error("Petersen graph reported as planar")
+	}
+
+	graph.KarateClubGraph(g)
+	checkShape(t, "Karate club", g, 34, 78, -1)
+	factions := make(map[int]int)
+	for _, node := range g.NodeList() {
+		factions[node.(graph.BlockNode).Block]++
+	}
+	if factions[0] != 17 || factions[1] != 17 {
+		t.Error("Wrong karate club factions:", factions)
+	}
+	if d := len(g.Successors(graph.GonumNode(33))); d != 17 {
+		t.Errorf("Administrator has degree %d, expected 17", d)
+	}
+}
@@ -0,0 +1,229 @@
+package graph
+
+import (
+	"errors"
+	"math"
+)
+
+// A navigation mesh: the walkable area of a 2D world, covered by convex polygons. Each polygon is a node (GonumNode(i) for Polygons[i]), and polygons sharing an edge are
+// adjacent, with the shared edge as the portal between them. Searching the mesh finds a corridor of polygons, and the funnel algorithm (Funnel) then finds the shortest line
+// through that corridor, which a grid can only approximate.
+//
+// Edges cost the distance between the polygons' centroids, and the heuristic is the straight line distance between centroids, so the mesh can be searched with AStar directly.
+// FindPath does all of it.
+type NavMesh struct {
+	Polygons  [][]Position
+	centroids []Position
+	neighbors []map[int][2]Position // The portal to each neighbor, as its {left, right} ends seen from inside this polygon
+}
+
+// Builds a mesh from convex polygons, each listing its corners counterclockwise (with Y up). Polygons are joined where one has an edge between the same two corners as another,
+// so a mesh should share corners exactly, as mesh generators produce them.
+func NewNavMesh(polygons [][]Position) *NavMesh {
+	mesh := &NavMesh{
+		Polygons:  polygons,
+		centroids: make([]Position, len(polygons)),
+		neighbors: make([]map[int][2]Position, len(polygons)),
+	}
+
+	edges := make(map[[2]Position]int)
+	for i, polygon := range polygons {
+		mesh.neighbors[i] = make(map[int][2]Position)
+		for j, p := range polygon {
+			mesh.centroids[i].X += p.X / float64(len(polygon))
+			mesh.centroids[i].Y += p.Y / float64(len(polygon))
+
+			// The same edge runs the other way round in the neighboring polygon. Leaving a counterclockwise polygon through the edge p->q, q is on the left.
+			q := polygon[(j+1)%len(polygon)]
+			if k, ok := edges[[2]Position{q, p}]; ok {
+				mesh.neighbors[i][k] = [2]Position{q, p}
+				mesh.neighbors[k][i] = [2]Position{p, q}
+			}
+			edges[[2]Position{p, q}] = i
+		}
+	}
+
+	return mesh
+}
+
+func (mesh *NavMesh) valid(node Node) bool {
+	return node.ID() >= 0 && node.ID() < len(mesh.Polygons)
+}
+
+func (mesh *NavMesh) Successors(node Node) []Node {
+	if !mesh.valid(node) {
+		return nil
+	}
+
+	succs := make([]Node, 0, len(mesh.neighbors[node.ID()]))
+	for k := range mesh.neighbors[node.ID()] {
+		succs = append(succs, GonumNode(k))
+	}
+	return succs
+}
+
+func (mesh *NavMesh) IsSuccessor(node, successor Node) bool {
+	if !mesh.valid(node) {
+		return false
+	}
+	_, ok := mesh.neighbors[node.ID()][successor.ID()]
+	return ok
+}
+
+func (mesh *NavMesh) Predecessors(node Node) []Node {
+	return mesh.Successors(node)
+}
+
+func (mesh *NavMesh) IsPredecessor(node, pred Node) bool {
+	return mesh.IsSuccessor(node, pred)
+}
+
+func (mesh *NavMesh) IsAdjacent(node, neighbor Node) bool {
+	return mesh.IsSuccessor(node, neighbor)
+}
+
+func (mesh *NavMesh) NodeExists(node Node) bool {
+	return mesh.valid(node)
+}
+
+func (mesh *NavMesh) Degree(node Node) int {
+	return len(mesh.Successors(node)) * 2
+}
+
+func (mesh *NavMesh) EdgeList() []Edge {
+	edges := make([]Edge, 0)
+	for i := range mesh.Polygons {
+		for _, succ := range mesh.Successors(GonumNode(i)) {
+			edges = append(edges, GonumEdge{GonumNode(i), succ})
+		}
+	}
+
+	return edges
+}
+
+func (mesh *NavMesh) NodeList() []Node {
+	nodes := make([]Node, len(mesh.Polygons))
+	for i := range nodes {
+		nodes[i] = GonumNode(i)
+	}
+
+	return nodes
+}
+
+func (mesh *NavMesh) IsDirected() bool {
+	return false
+}
+
+func (mesh *NavMesh) Cost(node1, node2 Node) float64 {
+	return mesh.HeuristicCost(node1, node2)
+}
+
+func (mesh *NavMesh) HeuristicCost(node1, node2 Node) float64 {
+	a, b := mesh.centroids[node1.ID()], mesh.centroids[node2.ID()]
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// The polygon containing p (on its boundary counts), or nil if p is off the mesh.
+func (mesh *NavMesh) Locate(p Position) Node {
+	for i, polygon := range mesh.Polygons {
+		inside := true
+		for j, a := range polygon {
+			if b := polygon[(j+1)%len(polygon)]; triArea2(a, b, p) > 0 {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return GonumNode(i)
+		}
+	}
+
+	return nil
+}
+
+// The portal from one polygon into a neighboring one, as its left and right ends seen when crossing it.
+func (mesh *NavMesh) Portal(from, to Node) (left, right Position, ok bool) {
+	if !mesh.valid(from) {
+		return left, right, false
+	}
+	portal, ok := mesh.neighbors[from.ID()][to.ID()]
+	return portal[0], portal[1], ok
+}
+
+// Twice the signed area of the triangle a, b, c: positive when c is to the right of the line from a to b (clockwise), negative to the left
+func triArea2(a, b, c Position) float64 {
+	return (c.X-a.X)*(b.Y-a.Y) - (b.X-a.X)*(c.Y-a.Y)
+}
+
+// Returned by FindPath when the start or goal is off the mesh or there's no way between them.
+var ErrNoNavPath = errors.New("No path across the navigation mesh")
+
+// Finds the shortest path from start to goal through a corridor of polygons (as found by a search of the mesh, first containing start and last containing goal), with the
+// simple stupid funnel algorithm[1]: a funnel from the current corner, bounded by the left and right ends of the portals, is narrowed portal by portal, and whenever one side
+// would cross over the other, the corner it crossed becomes a turn of the path and the funnel restarts from there. Returns the points of the path, start and goal included.
+//
+// The path is the shortest within the corridor, which is not necessarily the shortest across the whole mesh, since the search picks a corridor by centroid distances.
+//
+// [1] M. Mononen, "Simple Stupid Funnel Algorithm" (2010), http://digestingduck.blogspot.com/2010/03/simple-stupid-funnel-algorithm.html
+func (mesh *NavMesh) Funnel(corridor []Node, start, goal Position) []Position {
+	portals := [][2]Position{{start, start}}
+	for i := 0; i+1 < len(corridor); i++ {
+		left, right, _ := mesh.Portal(corridor[i], corridor[i+1])
+		portals = append(portals, [2]Position{left, right})
+	}
+	portals = append(portals, [2]Position{goal, goal})
+
+	path := []Position{start}
+	apex, left, right := start, start, start
+	apexIndex, leftIndex, rightIndex := 0, 0, 0
+	for i := 1; i < len(portals); i++ {
+		newLeft, newRight := portals[i][0], portals[i][1]
+
+		// Narrow the funnel from the right, unless that crosses the left side, which makes the left side's corner a turn
+		if triArea2(apex, right, newRight) <= 0 {
+			if apex == right || triArea2(apex, left, newRight) > 0 {
+				right, rightIndex = newRight, i
+			} else {
+				path = append(path, left)
+				apex, apexIndex = left, leftIndex
+				left, right, leftIndex, rightIndex = apex, apex, apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+
+		// And likewise from the left
+		if triArea2(apex, left, newLeft) >= 0 {
+			if apex == left || triArea2(apex, right, newLeft) < 0 {
+				left, leftIndex = newLeft, i
+			} else {
+				path = append(path, right)
+				apex, apexIndex = right, rightIndex
+				left, right, leftIndex, rightIndex = apex, apex, apexIndex, apexIndex
+				i = apexIndex
+				continue
+			}
+		}
+	}
+
+	if path[len(path)-1] != goal {
+		path = append(path, goal)
+	}
+	return path
+}
+
+// Finds a path across the mesh from start to goal: locates the polygons they're in, searches for a corridor between them with AStar, and straightens it with Funnel. Returns
+// ErrNoNavPath if either point is off the mesh or they're in disconnected parts of it.
+func (mesh *NavMesh) FindPath(start, goal Position) ([]Position, error) {
+	from, to := mesh.Locate(start), mesh.Locate(goal)
+	if from == nil || to == nil {
+		return nil, ErrNoNavPath
+	}
+
+	corridor, _, _ := AStar(from, to, mesh, nil, nil)
+	if corridor == nil {
+		return nil, ErrNoNavPath
+	}
+
+	return mesh.Funnel(corridor, start, goal), nil
+}
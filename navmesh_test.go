@@ -0,0 +1,119 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A plus-ish corridor of unit squares: 0 on the left, 1 in the middle, 2 above the middle, 3 below it
+func crossMesh() *graph.NavMesh {
+	square := func(x, y float64) []graph.Position {
+		return []graph.Position{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+	}
+	return graph.NewNavMesh([][]graph.Position{square(0, 0), square(1, 0), square(1, 1), square(1, -1)})
+}
+
+func TestNavMeshAdjacency(t *testing.T) {
+	mesh := crossMesh()
+	for _, pair := range [][2]int{{0, 1}, {1, 2}, {1, 3}} {
+		if !mesh.IsAdjacent(graph.GonumNode(pair[0]), graph.GonumNode(pair[1])) || !mesh.IsAdjacent(graph.GonumNode(pair[1]), graph.GonumNode(pair[0])) {
+			t.Errorf("Polygons %d and %d share an edge but aren't adjacent", pair[0], pair[1])
+		}
+	}
+	if mesh.IsAdjacent(graph.GonumNode(0), graph.GonumNode(2)) || mesh.IsAdjacent(graph.GonumNode(2), graph.GonumNode(3)) {
+		t.Error("Polygons touching at a corner or not at all are adjacent")
+	}
+	if len(mesh.EdgeList()) != 6 {
+		t.Errorf("Expected 6 directed edges, got %d", len(mesh.EdgeList()))
+	}
+
+	left, right, ok := mesh.Portal(graph.GonumNode(0), graph.GonumNode(1))
+	if !ok || left != (graph.Position{1, 1}) || right != (graph.Position{1, 0}) {
+		t.Errorf("Going right from 0 to 1, expected the portal to run from (1, 1) on the left to (1, 0) on the right, got %v, %v", left, right)
+	}
+	left, right, _ = mesh.Portal(graph.GonumNode(1), graph.GonumNode(0))
+	if left != (graph.Position{1, 0}) || right != (graph.Position{1, 1}) {
+		t.Errorf("Going back left, expected the portal's ends to swap, got %v, %v", left, right)
+	}
+
+	if node := mesh.Locate(graph.Position{1.5, 1.5}); node == nil || node.ID() != 2 {
+		t.Errorf("Expected (1.5, 1.5) to be in polygon 2, got %v", node)
+	}
+	if node := mesh.Locate(graph.Position{0.5, 1.5}); node != nil {
+		t.Errorf("Expected (0.5, 1.5) to be off the mesh, got %v", node)
+	}
+}
+
+func TestFunnel(t *testing.T) {
+	mesh := crossMesh()
+	start := graph.Position{0.5, 0.5}
+
+	tests := []struct {
+		name     string
+		corridor []int
+		goal     graph.Position
+		expected []graph.Position
+	}{
+		{"straight", []int{0, 1}, graph.Position{1.8, 0.3}, []graph.Position{start, {1.8, 0.3}}},
+		{"left turn", []int{0, 1, 2}, graph.Position{1.5, 1.9}, []graph.Position{start, {1, 1}, {1.5, 1.9}}},
+		{"right turn", []int{0, 1, 3}, graph.Position{1.5, -0.9}, []graph.Position{start, {1, 0}, {1.5, -0.9}}},
+		{"visible around the corner", []int{0, 1, 2}, graph.Position{1.9, 1.1}, []graph.Position{start, {1.9, 1.1}}},
+		{"same polygon", []int{0}, graph.Position{0.9, 0.1}, []graph.Position{start, {0.9, 0.1}}},
+	}
+
+	for _, test := range tests {
+		corridor := make([]graph.Node, len(test.corridor))
+		for i, id := range test.corridor {
+			corridor[i] = graph.GonumNode(id)
+		}
+
+		path := mesh.Funnel(corridor, start, test.goal)
+		if len(path) != len(test.expected) {
+			t.Errorf("%s: expected path %v, got %v", test.name, test.expected, path)
+			continue
+		}
+		for i := range path {
+			if path[i] != test.expected[i] {
+				t.Errorf("%s: expected path %v, got %v", test.name, test.expected, path)
+				break
+			}
+		}
+	}
+}
+
+func TestNavMeshFindPath(t *testing.T) {
+	// A U: up the left column, across the top and down the right, so the path turns at two inner corners
+	square := func(x, y float64) []graph.Position {
+		return []graph.Position{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}}
+	}
+	mesh := graph.NewNavMesh([][]graph.Position{square(0, 0), square(0, 1), square(0, 2), square(1, 2), square(2, 2), square(2, 1), square(2, 0)})
+
+	start, goal := graph.Position{0.5, 0.5}, graph.Position{2.5, 0.5}
+	path, err := mesh.FindPath(start, goal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []graph.Position{start, {1, 2}, {2, 2}, goal}
+	if len(path) != len(expected) {
+		t.Fatalf("Expected path %v, got %v", expected, path)
+	}
+	for i := range path {
+		if path[i] != expected[i] {
+			t.Fatalf("Expected path %v, got %v", expected, path)
+		}
+	}
+
+	length := 0.0
+	for i := 1; i < len(path); i++ {
+		length += math.Hypot(path[i].X-path[i-1].X, path[i].Y-path[i-1].Y)
+	}
+	if want := 2*math.Hypot(0.5, 1.5) + 1; math.Abs(length-want) > 1e-9 {
+		t.Errorf("Expected a path of length %v, got %v", want, length)
+	}
+
+	if _, err := mesh.FindPath(start, graph.Position{1.5, 0.5}); err != graph.ErrNoNavPath {
+		t.Errorf("Expected ErrNoNavPath for a goal off the mesh, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package graph
+
+// A SearchObserver is notified of a search's progress as it happens, for visualization (e.g. animating the expansion order, or how replanning spreads after an obstacle appears)
+// and debugging. Calls are made synchronously from inside the search, so observers should be quick, and must not modify the graph.
+//
+// The meaning of the scores depends on the search. For D*-Lite, g is the node's g-score, rhs its one step lookahead, and the key is the queue key (K1, K2).
+type SearchObserver interface {
+	OnExpand(node Node, g float64)            // A node was taken off the queue and its score settled (or, in D*-Lite, invalidated)
+	OnUpdateVertex(node Node, g, rhs float64) // A node's tentative score was recomputed
+	OnKeyChange(node Node, k1, k2 float64)    // A node was queued, or its priority in the queue changed
+	OnPathFound(path []Node, cost float64)    // A search finished with a path from the start to the goal
+}
+
+// NullObserver implements SearchObserver by ignoring everything. Embed it in an observer to only implement the notifications you care about.
+type NullObserver struct{}
+
+func (NullObserver) OnExpand(node Node, g float64)            {}
+func (NullObserver) OnUpdateVertex(node Node, g, rhs float64) {}
+func (NullObserver) OnKeyChange(node Node, k1, k2 float64)    {}
+func (NullObserver) OnPathFound(path []Node, cost float64)    {}
+
+// What a SearchEvent reports, after the SearchObserver method it stands for.
+type SearchEventKind int
+
+const (
+	ExpandEvent SearchEventKind = iota
+	UpdateVertexEvent
+	KeyChangeEvent
+	PathFoundEvent
+)
+
+// One notification from a search, as a ChannelObserver sends it. Only the fields that go with the kind are set: G for an expansion, G and RHS for a vertex update, K1 and K2 for
+// a key change, and Path and Cost for a path found. Node is nil for a path found.
+type SearchEvent struct {
+	Kind   SearchEventKind
+	Node   Node
+	G, RHS float64
+	K1, K2 float64
+	Path   []Node
+	Cost   float64
+}
+
+// A ChannelObserver sends every notification down its channel as a SearchEvent, to stream a search to a UI that runs in another goroutine. Sends block, so an unbuffered channel
+// steps the search along one event at a time as the receiver takes them, which suits animation; a buffered one lets the search run ahead. The search holds up until its events
+// are received, so the receiver has to keep taking them until the search returns. The channel is never closed.
+type ChannelObserver chan<- SearchEvent
+
+func (ch ChannelObserver) OnExpand(node Node, g float64) {
+	ch <- SearchEvent{Kind: ExpandEvent, Node: node, G: g}
+}
+
+func (ch ChannelObserver) OnUpdateVertex(node Node, g, rhs float64) {
+	ch <- SearchEvent{Kind: UpdateVertexEvent, Node: node, G: g, RHS: rhs}
+}
+
+func (ch ChannelObserver) OnKeyChange(node Node, k1, k2 float64) {
+	ch <- SearchEvent{Kind: KeyChangeEvent, Node: node, K1: k1, K2: k2}
+}
+
+// The path is copied, so the receiver may keep it.
+func (ch ChannelObserver) OnPathFound(path []Node, cost float64) {
+	ch <- SearchEvent{Kind: PathFoundEvent, Path: append([]Node(nil), path...), Cost: cost}
+}
@@ -0,0 +1,61 @@
+package graph_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestAStarObserved(t *testing.T) {
+	g := randomWeightedGraph(200, 0.03, 9)
+	start, goal := graph.GonumNode(0), graph.GonumNode(150)
+
+	observer := &recordingObserver{}
+	path, cost, expanded := graph.AStarObserved(start, goal, g, nil, nil, observer)
+	wantPath, wantCost, wantExpanded := graph.AStar(start, goal, g, nil, nil)
+	if !reflect.DeepEqual(nodeIDs(path), nodeIDs(wantPath)) || cost != wantCost || expanded != wantExpanded {
+		t.Fatalf("Observing A* changed its result: %v costing %v after %d expansions, expected %v costing %v after %d", nodeIDs(path), cost, expanded, nodeIDs(wantPath), wantCost, wantExpanded)
+	}
+	if len(observer.expanded) != expanded || observer.expanded[0].ID() != start.ID() || observer.expanded[expanded-1].ID() != goal.ID() {
+		t.Errorf("Observed %d expansions, expected %d from the start to the goal", len(observer.expanded), expanded)
+	}
+	if observer.keys < expanded {
+		t.Errorf("Observed %d nodes queued for %d expansions", observer.keys, expanded)
+	}
+	if len(observer.paths) != 1 || observer.costs[0] != cost || !reflect.DeepEqual(nodeIDs(observer.paths[0]), nodeIDs(path)) {
+		t.Errorf("Observed paths %v costing %v, expected only %v", observer.paths, observer.costs, nodeIDs(path))
+	}
+}
+
+func TestChannelObserver(t *testing.T) {
+	start, goal := graph.GonumNode(0), graph.GonumNode(15*15-1)
+	truth := graph.RandomObstacleField(15, 15, 0.2, start, goal, rand.New(rand.NewSource(2)))
+
+	events := make(chan graph.SearchEvent)
+	done := make(chan *graph.DStarInstance)
+	go func() {
+		done <- graph.InitDStar(start, goal, truth, nil, nil, graph.WithDStarObserver(graph.ChannelObserver(events)), graph.WithDStarStats())
+	}()
+
+	counts := make(map[graph.SearchEventKind]int)
+	var last graph.SearchEvent
+	var ds *graph.DStarInstance
+	for ds == nil {
+		select {
+		case event := <-events:
+			counts[event.Kind]++
+			last = event
+		case ds = <-done:
+		}
+	}
+
+	stats := ds.Stats()
+	if counts[graph.ExpandEvent] != stats.Expansions || counts[graph.UpdateVertexEvent] != stats.VertexUpdates || counts[graph.KeyChangeEvent] != stats.Pushes+stats.Fixes {
+		t.Errorf("Streamed %v events, stats counted %+v", counts, stats)
+	}
+	if last.Kind != graph.PathFoundEvent || len(last.Path) != len(ds.PlanAhead())+1 || last.Node != nil {
+		t.Errorf("Expected the last event to be the path found, got %+v", last)
+	}
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// Ranks the nodes of graph by PageRank[1]: the long run share of time a random surfer spends at each node, who at every step follows a random edge out of the node they're at
+// with probability damping, and otherwise jumps to a node chosen uniformly at random, as they always do from a node with no edges out. Ranks add up to 1, and are keyed by ID.
+// A damping of 0.85 is customary; it must be less than 1 for the ranks to be well defined. Edges are as Successors gives them, so an undirected edge is followed either way,
+// and costs are ignored.
+//
+// The ranks are found by power iteration, each iteration taking O(n + m), until the total change in rank from one iteration to the next is no more than tol, or for maxIter
+// iterations if that comes first. The error after an iteration is at most damping times the error before, so the iterations needed are about log(tol)/log(damping).
+//
+// [1] S. Brin and L. Page, "The anatomy of a large-scale hypertextual web search engine", Computer Networks and ISDN Systems 30 (1998)
+func PageRank(graph Graph, damping, tol float64, maxIter int) map[int]float64 {
+	ranks, _, _ := PersonalizedPageRank(graph, nil, damping, tol, maxIter)
+	return ranks
+}
+
+// Ranks the nodes of graph by personalized PageRank: as PageRank, but the surfer only ever jumps to one of seeds, chosen uniformly at random, including from a node with no
+// edges out. Ranks are then the share of time spent at each node by someone who keeps returning to the seeds, so they measure how close each node is to the seeds, and
+// nodes the seeds can't reach rank 0. It's the usual basis for "related to these" recommendations. A seed given more than once counts once, and seeds that aren't in the
+// graph are ignored; with none left, or with no seeds at all, the ranks are PageRank's.
+//
+// Also returns how many iterations were run, and whether they converged: whether the last one changed the ranks by no more than tol in total, rather than stopping at maxIter.
+func PersonalizedPageRank(graph Graph, seeds []Node, damping, tol float64, maxIter int) (ranks map[int]float64, iterations int, converged bool) {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	n := len(nodes)
+	index := make(map[int]int, n)
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+	out := make([][]int, n)
+	for i, node := range nodes {
+		for _, succ := range graph.Successors(node) {
+			if j, ok := index[succ.ID()]; ok {
+				out[i] = append(out[i], j)
+			}
+		}
+	}
+
+	// Where the surfer jumps to: the share of jumps that land on each node
+	jump := make([]float64, n)
+	seeded := 0
+	for _, seed := range seeds {
+		if i, ok := index[seed.ID()]; ok && jump[i] == 0 {
+			jump[i] = 1
+			seeded++
+		}
+	}
+	for i := range jump {
+		if seeded == 0 {
+			jump[i] = 1 / float64(n)
+		} else {
+			jump[i] /= float64(seeded)
+		}
+	}
+
+	rank, next := make([]float64, n), make([]float64, n)
+	copy(rank, jump)
+	for iterations < maxIter {
+		iterations++
+
+		// What's spread by jumps, including those of the surfers stuck at nodes with no way out
+		jumping := 1 - damping
+		for i, succs := range out {
+			if len(succs) == 0 {
+				jumping += damping * rank[i]
+			}
+		}
+		for i := range next {
+			next[i] = jumping * jump[i]
+		}
+		for i, succs := range out {
+			for _, j := range succs {
+				next[j] += damping * rank[i] / float64(len(succs))
+			}
+		}
+
+		change := 0.0
+		for i := range rank {
+			change += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if change <= tol {
+			converged = true
+			break
+		}
+	}
+
+	ranks = make(map[int]float64, n)
+	for i, node := range nodes {
+		ranks[node.ID()] = rank[i]
+	}
+	return ranks, iterations, converged
+}
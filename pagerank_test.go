@@ -0,0 +1,144 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestPageRank(t *testing.T) {
+	// Every node of a cycle is alike
+	cycle := ruleGraph([]int{0, 1, 2, 3}, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+	for id, rank := range graph.PageRank(cycle, 0.85, 1e-12, 100) {
+		if math.Abs(rank-0.25) > 1e-9 {
+			t.Errorf("Expected node %d of a cycle to rank 1/4, got %v", id, rank)
+		}
+	}
+
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		g := graph.NewGonumGraph(true)
+		n := 1 + src.Intn(10)
+		graph.GnpRandomGraph(g, n, 0.3, true, src)
+		damping := 0.5 + 0.4*src.Float64()
+
+		// The ranks solve (I - damping P^T) r = b, where P has a row per node, spread over its successors or, for a node with none, over every node. Solved by elimination
+		a := make([][]float64, n)
+		for i := range a {
+			a[i] = make([]float64, n+1)
+			a[i][i] = 1
+			a[i][n] = (1 - damping) / float64(n)
+		}
+		for j := 0; j < n; j++ {
+			succs := g.Successors(graph.GonumNode(j))
+			if len(succs) == 0 {
+				for i := 0; i < n; i++ {
+					a[i][j] -= damping / float64(n)
+				}
+			}
+			for _, succ := range succs {
+				a[succ.ID()][j] -= damping / float64(len(succs))
+			}
+		}
+		for k := 0; k < n; k++ {
+			for i := 0; i < n; i++ {
+				if i != k {
+					f := a[i][k] / a[k][k]
+					for j := k; j <= n; j++ {
+						a[i][j] -= f * a[k][j]
+					}
+				}
+			}
+		}
+
+		ranks := graph.PageRank(g, damping, 1e-12, 1000)
+		total := 0.0
+		for i := 0; i < n; i++ {
+			if expected := a[i][n] / a[i][i]; math.Abs(ranks[i]-expected) > 1e-9 {
+				t.Errorf("Trial %d: expected node %d to rank %v, got %v", trial, i, expected, ranks[i])
+			}
+			total += ranks[i]
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("Trial %d: expected ranks to add up to 1, got %v", trial, total)
+		}
+	}
+}
+
+func TestPersonalizedPageRank(t *testing.T) {
+	src := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 20; trial++ {
+		g := graph.NewGonumGraph(true)
+		n := 2 + src.Intn(10)
+		graph.GnpRandomGraph(g, n, 0.3, true, src)
+		damping := 0.5 + 0.4*src.Float64()
+		seeds := []graph.Node{graph.GonumNode(src.Intn(n)), graph.GonumNode(src.Intn(n)), graph.GonumNode(100)}
+
+		// As in TestPageRank, but jumps, and the surfers stuck with no way out, only go to the seeds
+		jump := make([]float64, n)
+		reach := make(map[int]bool)
+		for _, seed := range seeds[:2] {
+			jump[seed.ID()] = 1
+			for _, node := range graph.DijkstraTree(seed, g, nil).Order {
+				reach[node.ID()] = true
+			}
+		}
+		if seeds[0].ID() != seeds[1].ID() {
+			jump[seeds[0].ID()], jump[seeds[1].ID()] = 0.5, 0.5
+		}
+		a := make([][]float64, n)
+		for i := range a {
+			a[i] = make([]float64, n+1)
+			a[i][i] = 1
+			a[i][n] = (1 - damping) * jump[i]
+		}
+		for j := 0; j < n; j++ {
+			succs := g.Successors(graph.GonumNode(j))
+			if len(succs) == 0 {
+				for i := 0; i < n; i++ {
+					a[i][j] -= damping * jump[i]
+				}
+			}
+			for _, succ := range succs {
+				a[succ.ID()][j] -= damping / float64(len(succs))
+			}
+		}
+		for k := 0; k < n; k++ {
+			for i := 0; i < n; i++ {
+				if i != k {
+					f := a[i][k] / a[k][k]
+					for j := k; j <= n; j++ {
+						a[i][j] -= f * a[k][j]
+					}
+				}
+			}
+		}
+
+		ranks, iterations, converged := graph.PersonalizedPageRank(g, seeds, damping, 1e-12, 1000)
+		if !converged || iterations >= 1000 {
+			t.Errorf("Trial %d: expected convergence, took %d iterations", trial, iterations)
+		}
+		for i := 0; i < n; i++ {
+			if expected := a[i][n] / a[i][i]; math.Abs(ranks[i]-expected) > 1e-9 {
+				t.Errorf("Trial %d: expected node %d to rank %v, got %v", trial, i, expected, ranks[i])
+			}
+			if !reach[i] && ranks[i] != 0 {
+				t.Errorf("Trial %d: node %d can't be reached from the seeds but ranks %v", trial, i, ranks[i])
+			}
+		}
+	}
+
+	g := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(g, 20, 0.2, true, src)
+	ranks, _, _ := graph.PersonalizedPageRank(g, []graph.Node{graph.GonumNode(-1)}, 0.85, 1e-12, 1000)
+	for id, rank := range graph.PageRank(g, 0.85, 1e-12, 1000) {
+		if ranks[id] != rank {
+			t.Errorf("Expected seeds outside the graph to leave PageRank, got %v for %d rather than %v", ranks[id], id, rank)
+		}
+	}
+	if _, iterations, converged := graph.PersonalizedPageRank(g, nil, 0.85, 1e-12, 3); converged || iterations != 3 {
+		t.Errorf("Expected to stop unconverged after 3 iterations, got %d, converged %t", iterations, converged)
+	}
+}
@@ -0,0 +1,191 @@
+package graph
+
+// A PairingHeap is a PriorityQueue implemented as a pairing heap: a tree where every node's key is no greater than its children's, with no constraint on its shape. Pushing and
+// decreasing a key are O(1), since they just meld a tree onto the root, and all the restructuring is put off until the minimum is popped (O(log n) amortized). That makes it a good
+// fit for dense graphs, where a node's key tends to be decreased many times before it's popped.
+type PairingHeap struct {
+	less  func(a, b HeapItem) bool
+	root  *pairingNode
+	index map[int]*pairingNode
+	pairs []*pairingNode // Scratch space for mergePairs
+}
+
+type pairingNode struct {
+	item           HeapItem
+	child, sibling *pairingNode
+	prev           *pairingNode // The parent if this is the first child, otherwise the previous sibling
+}
+
+// Creates an empty PairingHeap ordered by less, which must be a strict weak ordering.
+func NewPairingHeap(less func(a, b HeapItem) bool) *PairingHeap {
+	return &PairingHeap{less: less, index: make(map[int]*pairingNode)}
+}
+
+func (h *PairingHeap) Len() int {
+	return len(h.index)
+}
+
+func (h *PairingHeap) Contains(node Node) bool {
+	_, ok := h.index[node.ID()]
+	return ok
+}
+
+func (h *PairingHeap) Key(node Node) (key interface{}, ok bool) {
+	if n, ok := h.index[node.ID()]; ok {
+		return n.item.Key, true
+	}
+
+	return nil, false
+}
+
+func (h *PairingHeap) Push(node Node, key interface{}) {
+	if h.Fix(node, key) {
+		return
+	}
+
+	n := &pairingNode{item: HeapItem{Node: node, Key: key}}
+	h.index[node.ID()] = n
+	h.root = h.meld(h.root, n)
+}
+
+func (h *PairingHeap) Fix(node Node, key interface{}) bool {
+	n, ok := h.index[node.ID()]
+	if !ok {
+		return false
+	}
+
+	old := n.item
+	n.item.Key = key
+	if !h.less(old, n.item) {
+		// Decreased (or unchanged): the subtree is still in order, and only needs to move up if it's no longer in order with its parent
+		if n != h.root {
+			h.cut(n)
+			h.root = h.meld(h.root, n)
+		}
+		return true
+	}
+
+	// Increased: the children may now be smaller, so they're split off and the node goes back in on its own
+	if n == h.root {
+		h.root = h.mergePairs(n.child)
+	} else {
+		h.cut(n)
+		h.root = h.meld(h.root, h.mergePairs(n.child))
+	}
+	n.child = nil
+	h.root = h.meld(h.root, n)
+	return true
+}
+
+func (h *PairingHeap) Peek() HeapItem {
+	return h.root.item
+}
+
+func (h *PairingHeap) Pop() HeapItem {
+	n := h.root
+	delete(h.index, n.item.ID())
+	h.root = h.mergePairs(n.child)
+
+	return n.item
+}
+
+func (h *PairingHeap) Remove(node Node) bool {
+	n, ok := h.index[node.ID()]
+	if !ok {
+		return false
+	}
+
+	if n == h.root {
+		h.Pop()
+		return true
+	}
+	delete(h.index, node.ID())
+	h.cut(n)
+	h.root = h.meld(h.root, h.mergePairs(n.child))
+	return true
+}
+
+func (h *PairingHeap) Clear() {
+	for id := range h.index {
+		delete(h.index, id)
+	}
+	h.root = nil
+}
+
+// Returns the queued items, the minimum first and the rest in no particular order. The slice is freshly allocated.
+func (h *PairingHeap) Items() []HeapItem {
+	items := make([]HeapItem, 0, len(h.index))
+	stack := []*pairingNode{}
+	if h.root != nil {
+		stack = append(stack, h.root)
+	}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		items = append(items, n.item)
+		for child := n.child; child != nil; child = child.sibling {
+			stack = append(stack, child)
+		}
+	}
+
+	return items
+}
+
+// Melds two detached trees, making the one with the larger root the first child of the other
+func (h *PairingHeap) meld(a, b *pairingNode) *pairingNode {
+	if a == nil {
+		return b
+	} else if b == nil {
+		return a
+	}
+	if h.less(b.item, a.item) {
+		a, b = b, a
+	}
+
+	b.prev, b.sibling = a, a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	a.prev, a.sibling = nil, nil
+	return a
+}
+
+// Detaches the subtree rooted at n (which mustn't be the root) from its parent
+func (h *PairingHeap) cut(n *pairingNode) {
+	if n.prev.child == n {
+		n.prev.child = n.sibling
+	} else {
+		n.prev.sibling = n.sibling
+	}
+	if n.sibling != nil {
+		n.sibling.prev = n.prev
+	}
+	n.prev, n.sibling = nil, nil
+}
+
+// Melds a list of sibling trees into one: first pairwise left to right, then the pairs right to left, which is what gives the pairing heap its amortized bounds
+func (h *PairingHeap) mergePairs(first *pairingNode) *pairingNode {
+	if first == nil {
+		return nil
+	}
+
+	h.pairs = h.pairs[:0]
+	for first != nil {
+		a, b := first, first.sibling
+		if b == nil {
+			a.prev, a.sibling = nil, nil
+			h.pairs = append(h.pairs, a)
+			break
+		}
+		first = b.sibling
+		a.prev, a.sibling, b.prev, b.sibling = nil, nil, nil, nil
+		h.pairs = append(h.pairs, h.meld(a, b))
+	}
+
+	merged := h.pairs[len(h.pairs)-1]
+	for i := len(h.pairs) - 2; i >= 0; i-- {
+		merged = h.meld(h.pairs[i], merged)
+	}
+	return merged
+}
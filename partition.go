@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"sort"
+)
+
+// An undirected, edge weighted view of a graph over dense node indices. This is what the partitioning algorithms work on, since direction doesn't matter for edge cuts.
+type partGraph struct {
+	nodes  []Node
+	index  map[int]int
+	adj    []map[int]float64
+	weight []float64 // Node weights, all 1 for an input graph but the sum of the merged nodes' weights when coarsened
+}
+
+// Builds the symmetric view of graph. An edge in either direction contributes its cost to the undirected edge weight; self loops are dropped since they can never be cut.
+func newPartGraph(graph Graph, Cost func(Node, Node) float64) *partGraph {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	pg := &partGraph{
+		nodes:  nodes,
+		index:  make(map[int]int, len(nodes)),
+		adj:    make([]map[int]float64, len(nodes)),
+		weight: make([]float64, len(nodes)),
+	}
+	for i, node := range nodes {
+		pg.index[node.ID()] = i
+		pg.adj[i] = make(map[int]float64)
+		pg.weight[i] = 1
+	}
+
+	for i, node := range nodes {
+		for _, succ := range graph.Successors(node) {
+			j, ok := pg.index[succ.ID()]
+			if !ok || j == i {
+				continue
+			}
+
+			c := Cost(node, succ)
+			pg.adj[i][j] += c
+			if graph.IsDirected() {
+				pg.adj[j][i] += c
+			}
+		}
+	}
+
+	return pg
+}
+
+func (pg *partGraph) cut(part []int) float64 {
+	total := 0.0
+	for i, neighbors := range pg.adj {
+		for j, w := range neighbors {
+			if i < j && part[i] != part[j] {
+				total += w
+			}
+		}
+	}
+
+	return total
+}
+
+// Bisects the graph with the Kernighan-Lin heuristic[1], minimizing the total weight of the edges crossing between the two halves while keeping the halves the same size (to within one node).
+//
+// Starting from an initial split (the first half of the nodes by ID, unless initial is non-nil, in which case nodes for which initial returns true start in partA), each pass tentatively swaps the
+// pair of nodes with the best gain, locks them, and repeats until every node is locked, then keeps the best prefix of those swaps. Passes continue until one fails to improve the cut or maxPasses
+// is reached (maxPasses <= 0 means no limit). Each pass is O(n^2 log n) in the worst case.
+//
+// Edge weights come from Cost, the graph's Coster, or UniformCost in that order of precedence. Direction is ignored, an edge in each direction counts twice. The returned cut is the weight of the crossing edges.
+//
+// [1] B. W. Kernighan, S. Lin, "An Efficient Heuristic Procedure for Partitioning Graphs", Bell System Technical Journal 49(2), 1970
+func KernighanLin(graph Graph, Cost func(Node, Node) float64, initial func(Node) bool, maxPasses int) (partA, partB []Node, cut float64) {
+	pg := newPartGraph(graph, Cost)
+	n := len(pg.nodes)
+
+	part := make([]int, n)
+	if initial != nil {
+		for i, node := range pg.nodes {
+			if !initial(node) {
+				part[i] = 1
+			}
+		}
+	} else {
+		for i := n / 2; i < n; i++ {
+			part[i] = 1
+		}
+	}
+
+	for pass := 0; maxPasses <= 0 || pass < maxPasses; pass++ {
+		if !pg.klPass(part) {
+			break
+		}
+	}
+
+	for i, node := range pg.nodes {
+		if part[i] == 0 {
+			partA = append(partA, node)
+		} else {
+			partB = append(partB, node)
+		}
+	}
+
+	return partA, partB, pg.cut(part)
+}
+
+// Runs a single Kernighan-Lin pass, applying the best prefix of swaps to part. Returns true if the cut improved.
+func (pg *partGraph) klPass(part []int) bool {
+	n := len(pg.nodes)
+
+	// D[v] = external cost - internal cost
+	d := make([]float64, n)
+	for i, neighbors := range pg.adj {
+		for j, w := range neighbors {
+			if part[i] == part[j] {
+				d[i] -= w
+			} else {
+				d[i] += w
+			}
+		}
+	}
+
+	locked := make([]bool, n)
+	type swap struct {
+		a, b int
+		gain float64
+	}
+	swaps := make([]swap, 0, n/2)
+
+	for {
+		sideA, sideB := make([]int, 0), make([]int, 0)
+		for i := 0; i < n; i++ {
+			if locked[i] {
+				continue
+			}
+			if part[i] == 0 {
+				sideA = append(sideA, i)
+			} else {
+				sideB = append(sideB, i)
+			}
+		}
+		if len(sideA) == 0 || len(sideB) == 0 {
+			break
+		}
+
+		byGain := func(side []int) {
+			sort.Sort(byKey{len(side), func(i int) float64 { return -d[side[i]] }, func(i, j int) { side[i], side[j] = side[j], side[i] }})
+		}
+		byGain(sideA)
+		byGain(sideB)
+
+		// Since weights are non-negative, D[a]+D[b] bounds the gain of swapping a and b, so we can stop scanning early
+		best := swap{a: -1}
+		for _, a := range sideA {
+			if best.a != -1 && d[a]+d[sideB[0]] <= best.gain {
+				break
+			}
+			for _, b := range sideB {
+				if best.a != -1 && d[a]+d[b] <= best.gain {
+					break
+				}
+				if gain := d[a] + d[b] - 2*pg.adj[a][b]; best.a == -1 || gain > best.gain {
+					best = swap{a, b, gain}
+				}
+			}
+		}
+
+		swaps = append(swaps, best)
+		locked[best.a], locked[best.b] = true, true
+
+		// Update D as if a and b had been swapped
+		for j, w := range pg.adj[best.a] {
+			if locked[j] {
+				continue
+			}
+			if part[j] == part[best.a] {
+				d[j] += 2 * w
+			} else {
+				d[j] -= 2 * w
+			}
+		}
+		for j, w := range pg.adj[best.b] {
+			if locked[j] {
+				continue
+			}
+			if part[j] == part[best.b] {
+				d[j] += 2 * w
+			} else {
+				d[j] -= 2 * w
+			}
+		}
+	}
+
+	bestK, bestGain, total := -1, 0.0, 0.0
+	for k, s := range swaps {
+		total += s.gain
+		if total > bestGain+1e-12 {
+			bestK, bestGain = k, total
+		}
+	}
+
+	for k := 0; k <= bestK; k++ {
+		part[swaps[k].a], part[swaps[k].b] = part[swaps[k].b], part[swaps[k].a]
+	}
+
+	return bestK >= 0
+}
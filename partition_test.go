@@ -0,0 +1,74 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+// Two K4s, {0..3} and {4..7}, joined by the edge 3-4
+func barbell() *graph.GonumGraph {
+	edges := completeEdges(4)
+	for _, e := range completeEdges(4) {
+		edges = append(edges, [2]int{e[0] + 4, e[1] + 4})
+	}
+
+	return buildGraph(false, append(edges, [2]int{3, 4}))
+}
+
+func TestKernighanLin(t *testing.T) {
+	g := barbell()
+	even := func(n graph.Node) bool {
+		return n.ID()%2 == 0
+	}
+
+	a, b, cut := graph.KernighanLin(g, nil, even, 0)
+	if cut != 1 {
+		t.Errorf("Kernighan-Lin cut is %v, expected 1", cut)
+	}
+	if len(a) != 4 || len(b) != 4 {
+		t.Fatalf("Unbalanced bisection: %v | %v", a, b)
+	}
+
+	side := a[0].ID() / 4
+	for _, node := range a {
+		if node.ID()/4 != side {
+			t.Error("Cliques not separated:", a, b)
+		}
+	}
+}
+
+func TestMultilevelPartition(t *testing.T) {
+	parts, cut := graph.MultilevelPartition(barbell(), nil, 2, 0)
+	if cut != 1 || len(parts[0]) != 4 || len(parts[1]) != 4 {
+		t.Errorf("Barbell partitioned badly: %v, cut %v", parts, cut)
+	}
+
+	grid := buildGraph(false, gridEdges(16, 16))
+	parts, cut = graph.MultilevelPartition(grid, nil, 4, 0.05)
+	if len(parts) != 4 {
+		t.Fatalf("Got %d parts, expected 4", len(parts))
+	}
+	for _, p := range parts {
+		if float64(len(p)) > 1.05*64 {
+			t.Errorf("Part of size %d exceeds the balance constraint", len(p))
+		}
+	}
+	// Quartering the grid cuts 32 edges
+	if cut > 48 {
+		t.Errorf("Cut of %v is much worse than optimal (32)", cut)
+	}
+
+	levels := graph.Coarsen(grid, nil, 16)
+	if len(levels) == 0 {
+		t.Fatal("Grid wasn't coarsened")
+	}
+	for _, level := range levels {
+		total := 0.0
+		for _, w := range level.Weight {
+			total += w
+		}
+		if total != 256 {
+			t.Errorf("Coarse node weights sum to %v, expected 256", total)
+		}
+	}
+}
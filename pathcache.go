@@ -0,0 +1,176 @@
+package graph
+
+import (
+	"container/list"
+	"math"
+	"sync"
+)
+
+// A PathCache memoizes the answers to recent shortest path queries on one graph, for services whose queries repeat a lot (the same few depots, the same popular
+// destinations). Misses are answered by A* through a Planner, and the least recently used answer is evicted once the cache is full.
+//
+// When the graph changes, Invalidate drops the answers the change may have made wrong: paths through an edge whose cost changed, and paths an edge that got cheaper (or was
+// added) might now shortcut. The latter is decided with the heuristic, so the better the heuristic (Landmarks are a good choice), the fewer answers are needlessly dropped;
+// with the NullHeuristic any cheaper edge drops nearly everything.
+//
+// A PathCache is safe for concurrent use. Queries are answered one at a time, so a busy service should put it in front of a pool of Planners rather than rely on its
+// single one.
+type PathCache struct {
+	mu            sync.Mutex
+	graph         ImplicitGraph
+	cost          func(Node, Node) float64 // As passed in, for rebuilding the planner
+	edgeCost      func(Node, Node) float64 // Resolved, for checking changed edges
+	heuristicCost func(Node, Node) float64
+	planner       *Planner
+	directed      bool
+
+	capacity     int
+	entries      map[[2]int]*list.Element // Of *pathCacheEntry, most recently used at the front
+	lru          *list.List
+	hits, misses int
+}
+
+type pathCacheEntry struct {
+	start, goal Node
+	path        []Node
+	edgeCosts   []float64 // The cost of each edge along path when it was found
+	cost        float64   // +Inf if there's no path
+}
+
+// Creates a cache of up to capacity answers for graph. Cost and HeuristicCost are interpreted as in AStar.
+func NewPathCache(graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64, capacity int) *PathCache {
+	c := &PathCache{graph: graph, cost: Cost, capacity: capacity, entries: make(map[[2]int]*list.Element), lru: list.New(), directed: isDirected(graph)}
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+	c.heuristicCost = HeuristicCost
+	c.setCost(Cost)
+
+	return c
+}
+
+func (c *PathCache) setCost(Cost func(Node, Node) float64) {
+	c.cost = Cost
+	c.edgeCost = Cost
+	if Cost == nil {
+		if cgraph, ok := c.graph.(Coster); ok {
+			c.edgeCost = cgraph.Cost
+		} else {
+			c.edgeCost = UniformCost
+		}
+	}
+	c.planner = NewPlanner(c.graph, Cost, c.heuristicCost)
+}
+
+// Returns the shortest path from start to goal and its cost, from the cache if it's there and by A* otherwise, as AStar would. The path is the caller's to modify.
+func (c *PathCache) ShortestPath(start, goal Node) (path []Node, cost float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := [2]int{start.ID(), goal.ID()}
+	if elem, ok := c.entries[key]; ok {
+		c.hits++
+		c.lru.MoveToFront(elem)
+		entry := elem.Value.(*pathCacheEntry)
+		if entry.path == nil {
+			return nil, 0
+		}
+		return append([]Node(nil), entry.path...), entry.cost
+	}
+
+	c.misses++
+	path, cost, _ = c.planner.AStar(start, goal)
+	entry := &pathCacheEntry{start: start, goal: goal, path: path, cost: cost, edgeCosts: make([]float64, 0, len(path))}
+	if path == nil {
+		entry.cost = math.Inf(1)
+	}
+	for i := 0; i+1 < len(path); i++ {
+		entry.edgeCosts = append(entry.edgeCosts, c.edgeCost(path[i], path[i+1]))
+	}
+
+	if c.capacity > 0 {
+		if c.lru.Len() >= c.capacity {
+			oldest := c.lru.Back()
+			c.remove(oldest)
+		}
+		c.entries[key] = c.lru.PushFront(entry)
+	}
+
+	return append([]Node(nil), path...), cost
+}
+
+func (c *PathCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*pathCacheEntry)
+	delete(c.entries, [2]int{entry.start.ID(), entry.goal.ID()})
+}
+
+// Tells the cache that the costs of changedEdges have changed (or that they were added or removed, removed edges costing +Inf), with newCost as the new cost function if it's
+// non-nil; these are the same arguments as DStarInstance.Update takes, so a DStarGraph's ChangedEdges can be passed straight on. Returns how many answers were dropped.
+//
+// An answer is dropped if its path uses a changed edge whose cost is now different from when it was found, or if a changed edge could now be part of a cheaper path: when the
+// heuristic estimate from the start to the edge, plus the edge's cost, plus the estimate from the edge to the goal, is less than the answer's cost. Costs rising elsewhere can't
+// make an answer wrong, so everything else is kept.
+func (c *PathCache) Invalidate(newCost func(Node, Node) float64, changedEdges []Edge) (dropped int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if newCost != nil {
+		c.setCost(newCost)
+	}
+
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*pathCacheEntry); c.stale(entry, changedEdges) {
+			c.remove(elem)
+			dropped++
+		}
+		elem = next
+	}
+
+	return dropped
+}
+
+// Whether any of the changed edges may have made entry wrong
+func (c *PathCache) stale(entry *pathCacheEntry, changedEdges []Edge) bool {
+	for _, edge := range changedEdges {
+		for _, dir := range [2][2]Node{{edge.Head(), edge.Tail()}, {edge.Tail(), edge.Head()}} {
+			u, v := dir[0], dir[1]
+			cost := c.edgeCost(u, v)
+			for i := 0; i+1 < len(entry.path); i++ {
+				if entry.path[i].ID() == u.ID() && entry.path[i+1].ID() == v.ID() && cost != entry.edgeCosts[i] {
+					return true
+				}
+			}
+			if c.heuristicCost(entry.start, u)+cost+c.heuristicCost(v, entry.goal) < entry.cost {
+				return true
+			}
+
+			if c.directed {
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// Drops every answer, for when the graph has changed in ways that are easier to start over from than to describe.
+func (c *PathCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[[2]int]*list.Element)
+	c.lru.Init()
+}
+
+// How many queries were answered from the cache, and how many had to be searched.
+func (c *PathCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.hits, c.misses
+}
@@ -0,0 +1,100 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestPathCache(t *testing.T) {
+	g := graph.NewGonumGraph(false)
+	graph.RandomGeometricGraph(g, 200, 2, 0.15, rand.New(rand.NewSource(1)))
+	cache := graph.NewPathCache(g, nil, graph.EuclideanDistance, 50)
+
+	src := rand.New(rand.NewSource(2))
+	queries := make([][2]graph.Node, 20)
+	for i := range queries {
+		queries[i] = [2]graph.Node{graph.GonumNode(src.Intn(200)), graph.GonumNode(src.Intn(200))}
+	}
+
+	// Every answer, cached or not, must match a fresh search
+	check := func(when string) {
+		for _, q := range queries {
+			path, cost := cache.ShortestPath(q[0], q[1])
+			_, want, _ := graph.AStar(q[0], q[1], g, nil, graph.EuclideanDistance)
+			if math.Abs(cost-want) > 1e-9 || path != nil && !graph.IsPath(path, g) {
+				t.Fatalf("%s: cached answer from %v to %v costs %v, a fresh search finds %v", when, q[0], q[1], cost, want)
+			}
+		}
+	}
+
+	check("First queries")
+	check("Repeated queries")
+	if hits, misses := cache.Stats(); hits != 20 || misses != 20 {
+		t.Errorf("Expected 20 hits and 20 misses, got %d and %d", hits, misses)
+	}
+
+	path, _ := cache.ShortestPath(queries[0][0], queries[0][1])
+	if len(path) > 1 {
+		path[1] = graph.GonumNode(-1)
+		if again, _ := cache.ShortestPath(queries[0][0], queries[0][1]); again[1].ID() == -1 {
+			t.Error("Modifying a returned path changed the cached one")
+		}
+	}
+
+	// Making an edge away from every cached path much dearer can't change any answer
+	onPath := make(map[int]bool)
+	for _, q := range queries {
+		path, _ := cache.ShortestPath(q[0], q[1])
+		for _, node := range path {
+			onPath[node.ID()] = true
+		}
+	}
+	var far graph.Edge
+	for _, edge := range g.EdgeList() {
+		if !onPath[edge.Head().ID()] && !onPath[edge.Tail().ID()] {
+			far = edge
+			break
+		}
+	}
+	if far != nil {
+		g.SetEdgeCost(far, 100)
+		if dropped := cache.Invalidate(nil, []graph.Edge{far}); dropped != 0 {
+			t.Errorf("Raising the cost of an edge on no cached path dropped %d answers", dropped)
+		}
+		check("After raising an unused edge")
+	}
+
+	// Random changes either way, after which every answer must still be right
+	edges := g.EdgeList()
+	for round := 0; round < 20; round++ {
+		var changed []graph.Edge
+		for i := 0; i < 5; i++ {
+			edge := edges[src.Intn(len(edges))]
+			g.SetEdgeCost(edge, g.Cost(edge.Head(), edge.Tail())*(0.2+1.6*src.Float64()))
+			changed = append(changed, edge)
+		}
+		cache.Invalidate(nil, changed)
+		check("After random changes")
+	}
+}
+
+func TestPathCacheEviction(t *testing.T) {
+	tg := graph.NewTileGraph(5, 5, true)
+	cache := graph.NewPathCache(tg, nil, nil, 2)
+	for _, goal := range []int{4, 20, 24, 4} {
+		cache.ShortestPath(graph.GonumNode(0), graph.GonumNode(goal))
+	}
+	if hits, misses := cache.Stats(); hits != 0 || misses != 4 {
+		t.Errorf("Expected the first query to be evicted before it was repeated, got %d hits and %d misses", hits, misses)
+	}
+
+	cache.ShortestPath(graph.GonumNode(0), graph.GonumNode(24))
+	cache.Clear()
+	cache.ShortestPath(graph.GonumNode(0), graph.GonumNode(24))
+	if hits, misses := cache.Stats(); hits != 1 || misses != 5 {
+		t.Errorf("Expected 1 hit and 5 misses, got %d and %d", hits, misses)
+	}
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Returns the face to the right of the half edge v->w as the cyclic sequence of nodes met walking around its boundary, starting with v and w. The walk repeatedly turns to the
+// next half edge counterclockwise around the node it arrives at. Returns nil if v->w isn't in the embedding.
+//
+// A node may appear more than once in a face (e.g. both endpoints of a bridge lie on the same face twice).
+func (pe *PlanarEmbedding) TraverseFace(v, w Node) []Node {
+	if _, ok := pe.cw[[2]int{v.ID(), w.ID()}]; !ok {
+		return nil
+	}
+
+	face := []Node{v}
+	for a, b := v.ID(), w.ID(); b != v.ID() || pe.ccw[[2]int{b, a}] != w.ID(); a, b = b, pe.ccw[[2]int{b, a}] {
+		face = append(face, pe.nodes[b])
+	}
+
+	return face
+}
+
+// Returns every face of the embedding. Each face is given as in TraverseFace, and every half edge is on exactly one face, so each edge borders one or two faces.
+//
+// Which face is the "outer" one is a property of a drawing, not of a combinatorial embedding, so no face is singled out. Isolated nodes belong to no face.
+// For a connected planar graph, Euler's formula guarantees len(faces) == E - V + 2.
+func (pe *PlanarEmbedding) Faces() [][]Node {
+	faces, _ := pe.faces()
+	return faces
+}
+
+// Computes the faces along with the index of the face each half edge is on.
+func (pe *PlanarEmbedding) faces() ([][]Node, map[[2]int]int) {
+	faceOf := make(map[[2]int]int, len(pe.cw))
+	faces := make([][]Node, 0)
+	for _, v := range pe.sortedNodes() {
+		for _, w := range pe.Neighbors(v) {
+			if _, ok := faceOf[[2]int{v.ID(), w.ID()}]; ok {
+				continue
+			}
+
+			face := pe.TraverseFace(v, w)
+			for i := range face {
+				faceOf[[2]int{face[i].ID(), face[(i+1)%len(face)].ID()}] = len(faces)
+			}
+			faces = append(faces, face)
+		}
+	}
+
+	return faces, faceOf
+}
+
+func (pe *PlanarEmbedding) sortedNodes() []Node {
+	nodes := pe.NodeList()
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+// A DualEdge is an edge of a planar dual; it crosses exactly one edge of the primal graph. Left and Right are the indices (into PlanarDual.Faces) of the faces on either side of Primal.
+// If Left == Right, the primal edge is a bridge and the dual edge is a loop.
+type DualEdge struct {
+	Left, Right int
+	Primal      Edge
+}
+
+// The dual of a planar embedding: one node per face, and one edge per primal edge, connecting the two faces that edge separates.
+//
+// The dual is generally a multigraph with loops (e.g. the two faces of a cycle are joined by one dual edge per cycle edge), so the edges are kept as a list. Use Graph if
+// an ordinary weighted graph is good enough.
+type PlanarDual struct {
+	Faces [][]Node
+	Edges []DualEdge
+}
+
+// Computes the faces and the dual of the embedding.
+func (pe *PlanarEmbedding) Dual() *PlanarDual {
+	faces, faceOf := pe.faces()
+	dual := &PlanarDual{Faces: faces, Edges: make([]DualEdge, 0, len(pe.cw)/2)}
+	for _, v := range pe.sortedNodes() {
+		for _, w := range pe.Neighbors(v) {
+			if v.ID() > w.ID() {
+				continue
+			}
+
+			dual.Edges = append(dual.Edges, DualEdge{
+				Left:   faceOf[[2]int{w.ID(), v.ID()}],
+				Right:  faceOf[[2]int{v.ID(), w.ID()}],
+				Primal: GonumEdge{H: v, T: w},
+			})
+		}
+	}
+
+	return dual
+}
+
+// Collapses the dual into an undirected GonumGraph whose nodes are GonumNode(face index). Parallel dual edges are merged into one edge whose cost is the sum of their primal edges' costs,
+// which preserves the weight of every cut. Loops are kept.
+//
+// As usual, Cost takes precedence, and falls back to UniformCost if nil (the embedding has no costs of its own).
+func (pd *PlanarDual) Graph(Cost func(Node, Node) float64) *GonumGraph {
+	if Cost == nil {
+		Cost = UniformCost
+	}
+
+	dual := NewGonumGraph(false)
+	for i := range pd.Faces {
+		dual.AddNode(GonumNode(i), nil)
+	}
+
+	weights := make(map[[2]int]float64)
+	for _, e := range pd.Edges {
+		l, r := e.Left, e.Right
+		if r < l {
+			l, r = r, l
+		}
+		weights[[2]int{l, r}] += Cost(e.Primal.Head(), e.Primal.Tail())
+	}
+	for faces, weight := range weights {
+		edge := GonumEdge{H: GonumNode(faces[0]), T: GonumNode(faces[1])}
+		dual.AddEdge(edge)
+		dual.SetEdgeCost(edge, weight)
+	}
+
+	return dual
+}
@@ -0,0 +1,584 @@
+package graph
+
+import (
+	"sort"
+)
+
+// A PlanarEmbedding is a combinatorial embedding of a planar graph: for every node, the clockwise order of its neighbors around it in some crossing-free drawing.
+// Edges are undirected in an embedding, every edge is recorded as two half edges, one around each of its endpoints.
+type PlanarEmbedding struct {
+	nodes map[int]Node
+	first map[int]int
+	cw    map[[2]int]int
+	ccw   map[[2]int]int
+}
+
+func newPlanarEmbedding() *PlanarEmbedding {
+	return &PlanarEmbedding{
+		nodes: make(map[int]Node),
+		first: make(map[int]int),
+		cw:    make(map[[2]int]int),
+		ccw:   make(map[[2]int]int),
+	}
+}
+
+// Returns all nodes in the embedding, in no particular order.
+func (pe *PlanarEmbedding) NodeList() []Node {
+	nodes := make([]Node, 0, len(pe.nodes))
+	for _, node := range pe.nodes {
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
+// Returns the neighbors of node in clockwise order. Which neighbor comes first is arbitrary but fixed.
+func (pe *PlanarEmbedding) Neighbors(node Node) []Node {
+	id := node.ID()
+	first, ok := pe.first[id]
+	if !ok {
+		return nil
+	}
+
+	neighbors := []Node{pe.nodes[first]}
+	for next := pe.cw[[2]int{id, first}]; next != first; next = pe.cw[[2]int{id, next}] {
+		neighbors = append(neighbors, pe.nodes[next])
+	}
+
+	return neighbors
+}
+
+// Returns the neighbor following neighbor clockwise around node. Returns nil if the edge isn't in the embedding.
+func (pe *PlanarEmbedding) Clockwise(node, neighbor Node) Node {
+	next, ok := pe.cw[[2]int{node.ID(), neighbor.ID()}]
+	if !ok {
+		return nil
+	}
+
+	return pe.nodes[next]
+}
+
+// Returns the neighbor following neighbor counterclockwise around node. Returns nil if the edge isn't in the embedding.
+func (pe *PlanarEmbedding) CounterClockwise(node, neighbor Node) Node {
+	next, ok := pe.ccw[[2]int{node.ID(), neighbor.ID()}]
+	if !ok {
+		return nil
+	}
+
+	return pe.nodes[next]
+}
+
+func (pe *PlanarEmbedding) addNode(node Node) {
+	pe.nodes[node.ID()] = node
+}
+
+// Adds the half edge start->end as the only half edge around start.
+func (pe *PlanarEmbedding) addHalfEdgeAlone(start, end int) {
+	pe.cw[[2]int{start, end}] = end
+	pe.ccw[[2]int{start, end}] = end
+	pe.first[start] = end
+}
+
+// Inserts the half edge start->end immediately clockwise of start->reference.
+func (pe *PlanarEmbedding) addHalfEdgeCW(start, end, reference int) {
+	cwReference := pe.cw[[2]int{start, reference}]
+	pe.cw[[2]int{start, end}] = cwReference
+	pe.ccw[[2]int{start, end}] = reference
+	pe.cw[[2]int{start, reference}] = end
+	pe.ccw[[2]int{start, cwReference}] = end
+}
+
+// Inserts the half edge start->end immediately counterclockwise of start->reference.
+func (pe *PlanarEmbedding) addHalfEdgeCCW(start, end, reference int) {
+	pe.addHalfEdgeCW(start, end, pe.ccw[[2]int{start, reference}])
+	if pe.first[start] == reference {
+		pe.first[start] = end
+	}
+}
+
+func (pe *PlanarEmbedding) addHalfEdgeFirst(start, end int) {
+	if first, ok := pe.first[start]; ok {
+		pe.addHalfEdgeCCW(start, end, first)
+	} else {
+		pe.addHalfEdgeAlone(start, end)
+	}
+}
+
+// Tests whether the graph is planar with the left-right planarity test of de Fraysseix and Rosenstiehl, as described by Brandes[1]. The test itself is linear time.
+// Edge directions, self loops, and parallel edges are ignored since they have no bearing on planarity.
+//
+// If the graph is planar, a combinatorial embedding is returned and witness is nil. If not, embedding is nil and witness is a Kuratowski subgraph: a set of edges forming a subdivision of
+// K5 or K3,3, which proves the graph can't be drawn without crossings. Extracting the witness repeatedly reruns the test with edges removed, so it's quadratic in the number of edges. Use IsPlanar if you
+// don't need either.
+//
+// [1] U. Brandes, "The Left-Right Planarity Test", 2009
+func Planarity(graph Graph) (embedding *PlanarEmbedding, witness []Edge) {
+	lr := newLRPlanarity(graph, nil)
+	if embedding := lr.run(); embedding != nil {
+		return embedding, nil
+	}
+
+	// Greedily drop every edge that isn't needed for non-planarity; what's left is minimal and therefore a Kuratowski subgraph
+	edges := lr.edgeList()
+	removed := make(map[[2]int]bool, len(edges))
+	for _, e := range edges {
+		removed[lr.edgeKey(e)] = true
+		if newLRPlanarity(graph, removed).run() != nil {
+			delete(removed, lr.edgeKey(e))
+			witness = append(witness, e)
+		}
+	}
+
+	return nil, witness
+}
+
+// Returns true if the graph can be drawn in the plane without edge crossings. Edge directions, self loops, and parallel edges are ignored.
+func IsPlanar(graph Graph) bool {
+	return newLRPlanarity(graph, nil).run() != nil
+}
+
+type lrInterval struct {
+	low, high [2]int
+}
+
+var noLREdge = [2]int{-1, -1}
+
+func (i lrInterval) empty() bool {
+	return i.low == noLREdge && i.high == noLREdge
+}
+
+type lrConflictPair struct {
+	left, right lrInterval
+}
+
+func newLRConflictPair() *lrConflictPair {
+	return &lrConflictPair{left: lrInterval{noLREdge, noLREdge}, right: lrInterval{noLREdge, noLREdge}}
+}
+
+func (p *lrConflictPair) swap() {
+	p.left, p.right = p.right, p.left
+}
+
+// The state of a single run of the left-right planarity test. Nodes are referred to by dense indices so edges can be [2]int keys.
+type lrPlanarity struct {
+	nodes []Node
+	index map[int]int
+	adj   [][]int
+
+	height        []int
+	parentEdge    [][2]int
+	roots         []int
+	oriented      map[[2]int]bool
+	outEdges      [][]int
+	lowpt, lowpt2 map[[2]int]int
+	nestingDepth  map[[2]int]int
+	orderedAdj    [][]int
+
+	ref               map[[2]int][2]int
+	side              map[[2]int]int
+	stack             []*lrConflictPair
+	stackBottom       map[[2]int]*lrConflictPair
+	lowptEdge         map[[2]int][2]int
+	leftRef, rightRef []int
+	embedding         *PlanarEmbedding
+}
+
+// Builds the simple undirected graph underlying graph, leaving out any edge in removed (keyed by node ID pairs, smallest first).
+func newLRPlanarity(graph Graph, removed map[[2]int]bool) *lrPlanarity {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	lr := &lrPlanarity{nodes: nodes, index: make(map[int]int, len(nodes)), adj: make([][]int, len(nodes))}
+	for i, node := range nodes {
+		lr.index[node.ID()] = i
+	}
+
+	for i, node := range nodes {
+		seen := make(map[int]bool)
+		add := func(other Node) {
+			j, ok := lr.index[other.ID()]
+			if !ok || j == i || seen[j] || removed[lr.edgeKey(GonumEdge{H: node, T: other})] {
+				return
+			}
+			seen[j] = true
+			lr.adj[i] = append(lr.adj[i], j)
+		}
+		for _, succ := range graph.Successors(node) {
+			add(succ)
+		}
+		for _, pred := range graph.Predecessors(node) {
+			add(pred)
+		}
+	}
+
+	return lr
+}
+
+func (lr *lrPlanarity) edgeKey(e Edge) [2]int {
+	h, t := e.Head().ID(), e.Tail().ID()
+	if t < h {
+		h, t = t, h
+	}
+
+	return [2]int{h, t}
+}
+
+// Each undirected edge exactly once
+func (lr *lrPlanarity) edgeList() []Edge {
+	edges := make([]Edge, 0)
+	for i, neighbors := range lr.adj {
+		for _, j := range neighbors {
+			if i < j {
+				edges = append(edges, GonumEdge{H: lr.nodes[i], T: lr.nodes[j]})
+			}
+		}
+	}
+
+	return edges
+}
+
+func (lr *lrPlanarity) run() *PlanarEmbedding {
+	n := len(lr.nodes)
+	m := 0
+	for _, neighbors := range lr.adj {
+		m += len(neighbors)
+	}
+	m /= 2
+	if n > 2 && m > 3*n-6 {
+		return nil
+	}
+
+	lr.height = make([]int, n)
+	lr.parentEdge = make([][2]int, n)
+	lr.outEdges = make([][]int, n)
+	for i := range lr.height {
+		lr.height[i] = -1
+		lr.parentEdge[i] = noLREdge
+	}
+	lr.oriented = make(map[[2]int]bool, m)
+	lr.lowpt = make(map[[2]int]int, m)
+	lr.lowpt2 = make(map[[2]int]int, m)
+	lr.nestingDepth = make(map[[2]int]int, m)
+
+	for v := 0; v < n; v++ {
+		if lr.height[v] == -1 {
+			lr.height[v] = 0
+			lr.roots = append(lr.roots, v)
+			lr.orient(v)
+		}
+	}
+
+	lr.ref = make(map[[2]int][2]int)
+	lr.side = make(map[[2]int]int)
+	lr.stackBottom = make(map[[2]int]*lrConflictPair)
+	lr.lowptEdge = make(map[[2]int][2]int)
+	lr.orderedAdj = make([][]int, n)
+	for v := 0; v < n; v++ {
+		lr.sortByNesting(v)
+	}
+
+	for _, root := range lr.roots {
+		if !lr.test(root) {
+			return nil
+		}
+	}
+
+	for v := 0; v < n; v++ {
+		for _, w := range lr.outEdges[v] {
+			e := [2]int{v, w}
+			lr.nestingDepth[e] = lr.sign(e) * lr.nestingDepth[e]
+		}
+	}
+
+	lr.embedding = newPlanarEmbedding()
+	for v := 0; v < n; v++ {
+		lr.embedding.addNode(lr.nodes[v])
+		lr.sortByNesting(v)
+		for i, w := range lr.orderedAdj[v] {
+			if i == 0 {
+				lr.embedding.addHalfEdgeAlone(lr.nodes[v].ID(), lr.nodes[w].ID())
+			} else {
+				lr.embedding.addHalfEdgeCW(lr.nodes[v].ID(), lr.nodes[w].ID(), lr.nodes[lr.orderedAdj[v][i-1]].ID())
+			}
+		}
+	}
+
+	lr.leftRef, lr.rightRef = make([]int, n), make([]int, n)
+	for _, root := range lr.roots {
+		lr.embed(root)
+	}
+
+	return lr.embedding
+}
+
+func (lr *lrPlanarity) sortByNesting(v int) {
+	ordered := make([]int, len(lr.outEdges[v]))
+	copy(ordered, lr.outEdges[v])
+	sort.Stable(byKey{len(ordered), func(i int) float64 { return float64(lr.nestingDepth[[2]int{v, ordered[i]}]) }, func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] }})
+	lr.orderedAdj[v] = ordered
+}
+
+// Phase 1: orient the edges with a DFS and compute lowpoints and nesting depths
+func (lr *lrPlanarity) orient(v int) {
+	e := lr.parentEdge[v]
+	for _, w := range lr.adj[v] {
+		if lr.oriented[[2]int{v, w}] || lr.oriented[[2]int{w, v}] {
+			continue
+		}
+
+		vw := [2]int{v, w}
+		lr.oriented[vw] = true
+		lr.outEdges[v] = append(lr.outEdges[v], w)
+		lr.lowpt[vw] = lr.height[v]
+		lr.lowpt2[vw] = lr.height[v]
+		if lr.height[w] == -1 { // tree edge
+			lr.parentEdge[w] = vw
+			lr.height[w] = lr.height[v] + 1
+			lr.orient(w)
+		} else { // back edge
+			lr.lowpt[vw] = lr.height[w]
+		}
+
+		lr.nestingDepth[vw] = 2 * lr.lowpt[vw]
+		if lr.lowpt2[vw] < lr.height[v] { // chordal
+			lr.nestingDepth[vw]++
+		}
+
+		if e != noLREdge {
+			if lr.lowpt[vw] < lr.lowpt[e] {
+				lr.lowpt2[e] = minInt(lr.lowpt[e], lr.lowpt2[vw])
+				lr.lowpt[e] = lr.lowpt[vw]
+			} else if lr.lowpt[vw] > lr.lowpt[e] {
+				lr.lowpt2[e] = minInt(lr.lowpt2[e], lr.lowpt[vw])
+			} else {
+				lr.lowpt2[e] = minInt(lr.lowpt2[e], lr.lowpt2[vw])
+			}
+		}
+	}
+}
+
+func (lr *lrPlanarity) top() *lrConflictPair {
+	if len(lr.stack) == 0 {
+		return nil
+	}
+	return lr.stack[len(lr.stack)-1]
+}
+
+func (lr *lrPlanarity) pop() *lrConflictPair {
+	p := lr.stack[len(lr.stack)-1]
+	lr.stack = lr.stack[:len(lr.stack)-1]
+	return p
+}
+
+func (lr *lrPlanarity) conflicting(i lrInterval, b [2]int) bool {
+	return !i.empty() && lr.lowpt[i.high] > lr.lowpt[b]
+}
+
+func (lr *lrPlanarity) lowest(p *lrConflictPair) int {
+	if p.left.empty() {
+		return lr.lowpt[p.right.low]
+	}
+	if p.right.empty() {
+		return lr.lowpt[p.left.low]
+	}
+	return minInt(lr.lowpt[p.left.low], lr.lowpt[p.right.low])
+}
+
+// Phase 2: test for planarity by partitioning the back edges into left and right
+func (lr *lrPlanarity) test(v int) bool {
+	e := lr.parentEdge[v]
+	for _, w := range lr.orderedAdj[v] {
+		ei := [2]int{v, w}
+		lr.stackBottom[ei] = lr.top()
+		if ei == lr.parentEdge[w] { // tree edge
+			if !lr.test(w) {
+				return false
+			}
+		} else { // back edge
+			lr.lowptEdge[ei] = ei
+			p := newLRConflictPair()
+			p.right = lrInterval{ei, ei}
+			lr.stack = append(lr.stack, p)
+		}
+
+		// Integrate new return edges
+		if lr.lowpt[ei] < lr.height[v] {
+			if w == lr.orderedAdj[v][0] {
+				lr.lowptEdge[e] = lr.lowptEdge[ei]
+			} else if !lr.addConstraints(ei, e) {
+				return false
+			}
+		}
+	}
+
+	if e != noLREdge {
+		lr.removeBackEdges(e)
+	}
+
+	return true
+}
+
+func (lr *lrPlanarity) addConstraints(ei, e [2]int) bool {
+	p := newLRConflictPair()
+
+	// Merge the return edges of ei into p.right
+	for {
+		q := lr.pop()
+		if !q.left.empty() {
+			q.swap()
+		}
+		if !q.left.empty() {
+			return false
+		}
+
+		if lr.lowpt[q.right.low] > lr.lowpt[e] {
+			if p.right.empty() {
+				p.right = q.right
+			} else {
+				lr.ref[p.right.low] = q.right.high
+			}
+			p.right.low = q.right.low
+		} else {
+			lr.ref[q.right.low] = lr.lowptEdge[e]
+		}
+
+		if lr.top() == lr.stackBottom[ei] {
+			break
+		}
+	}
+
+	// Merge the conflicting return edges of the earlier siblings into p.left
+	for top := lr.top(); top != nil && (lr.conflicting(top.left, ei) || lr.conflicting(top.right, ei)); top = lr.top() {
+		q := lr.pop()
+		if lr.conflicting(q.right, ei) {
+			q.swap()
+		}
+		if lr.conflicting(q.right, ei) {
+			return false
+		}
+
+		lr.ref[p.right.low] = q.right.high
+		if q.right.low != noLREdge {
+			p.right.low = q.right.low
+		}
+
+		if p.left.empty() {
+			p.left = q.left
+		} else {
+			lr.ref[p.left.low] = q.left.high
+		}
+		p.left.low = q.left.low
+	}
+
+	if !p.left.empty() || !p.right.empty() {
+		lr.stack = append(lr.stack, p)
+	}
+
+	return true
+}
+
+func (lr *lrPlanarity) refOf(e [2]int) [2]int {
+	if r, ok := lr.ref[e]; ok {
+		return r
+	}
+	return noLREdge
+}
+
+func (lr *lrPlanarity) removeBackEdges(e [2]int) {
+	u := e[0]
+
+	// Drop entire conflict pairs
+	for len(lr.stack) > 0 && lr.lowest(lr.top()) == lr.height[u] {
+		p := lr.pop()
+		if p.left.low != noLREdge {
+			lr.side[p.left.low] = -1
+		}
+	}
+
+	// Trim the next conflict pair
+	if len(lr.stack) > 0 {
+		p := lr.pop()
+		for p.left.high != noLREdge && p.left.high[1] == u {
+			p.left.high = lr.refOf(p.left.high)
+		}
+		if p.left.high == noLREdge && p.left.low != noLREdge {
+			lr.ref[p.left.low] = p.right.low
+			lr.side[p.left.low] = -1
+			p.left.low = noLREdge
+		}
+
+		for p.right.high != noLREdge && p.right.high[1] == u {
+			p.right.high = lr.refOf(p.right.high)
+		}
+		if p.right.high == noLREdge && p.right.low != noLREdge {
+			lr.ref[p.right.low] = p.left.low
+			lr.side[p.right.low] = -1
+			p.right.low = noLREdge
+		}
+		lr.stack = append(lr.stack, p)
+	}
+
+	// The side of e is the side of a highest return edge
+	if lr.lowpt[e] < lr.height[u] && len(lr.stack) > 0 {
+		hl, hr := lr.top().left.high, lr.top().right.high
+		if hl != noLREdge && (hr == noLREdge || lr.lowpt[hl] > lr.lowpt[hr]) {
+			lr.ref[e] = hl
+		} else {
+			lr.ref[e] = hr
+		}
+	}
+}
+
+func (lr *lrPlanarity) sign(e [2]int) int {
+	if _, ok := lr.side[e]; !ok {
+		lr.side[e] = 1
+	}
+
+	if r := lr.refOf(e); r != noLREdge {
+		lr.side[e] *= lr.sign(r)
+		delete(lr.ref, e)
+	}
+
+	return lr.side[e]
+}
+
+// Phase 3: add the back edges' reverse half edges to complete the embedding
+func (lr *lrPlanarity) embed(v int) {
+	for _, w := range lr.orderedAdj[v] {
+		ei := [2]int{v, w}
+		vID, wID := lr.nodes[v].ID(), lr.nodes[w].ID()
+		if ei == lr.parentEdge[w] { // tree edge
+			lr.embedding.addHalfEdgeFirst(wID, vID)
+			lr.leftRef[v] = wID
+			lr.rightRef[v] = wID
+			lr.embed(w)
+		} else { // back edge
+			if side, ok := lr.side[ei]; !ok || side == 1 {
+				lr.embedding.addHalfEdgeCW(wID, vID, lr.rightRef[w])
+			} else {
+				lr.embedding.addHalfEdgeCCW(wID, vID, lr.leftRef[w])
+				lr.leftRef[w] = vID
+			}
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+type byID []Node
+
+func (b byID) Len() int {
+	return len(b)
+}
+
+func (b byID) Less(i, j int) bool {
+	return b[i].ID() < b[j].ID()
+}
+
+func (b byID) Swap(i, j int) {
+	b[i], b[j] = b[j], b[i]
+}
@@ -0,0 +1,145 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+func completeEdges(n int) [][2]int {
+	edges := make([][2]int, 0)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			edges = append(edges, [2]int{i, j})
+		}
+	}
+
+	return edges
+}
+
+func gridEdges(rows, cols int) [][2]int {
+	edges := make([][2]int, 0)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if c+1 < cols {
+				edges = append(edges, [2]int{r*cols + c, r*cols + c + 1})
+			}
+			if r+1 < rows {
+				edges = append(edges, [2]int{r*cols + c, (r+1)*cols + c})
+			}
+		}
+	}
+
+	return edges
+}
+
+// Counts the faces of an embedding by following half edges, for checking Euler's formula
+func countFaces(pe *graph.PlanarEmbedding) int {
+	visited := make(map[[2]int]bool)
+	faces := 0
+	for _, v := range pe.NodeList() {
+		for _, w := range pe.Neighbors(v) {
+			if visited[[2]int{v.ID(), w.ID()}] {
+				continue
+			}
+			faces++
+			for a, b := v, w; !visited[[2]int{a.ID(), b.ID()}]; a, b = b, pe.CounterClockwise(b, a) {
+				visited[[2]int{a.ID(), b.ID()}] = true
+			}
+		}
+	}
+
+	return faces
+}
+
+func TestPlanarity(t *testing.T) {
+	planar := map[string][][2]int{
+		"K4":       completeEdges(4),
+		"5x5 grid": gridEdges(5, 5),
+		"cube":     {{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 7}, {7, 4}, {0, 4}, {1, 5}, {2, 6}, {3, 7}},
+		"wheel":    {{0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}, {1, 2}, {2, 3}, {3, 4}, {4, 5}, {5, 1}},
+	}
+	for name, edges := range planar {
+		g := buildGraph(false, edges)
+		embedding, witness := graph.Planarity(g)
+		if embedding == nil || witness != nil {
+			t.Errorf("%s reported as non-planar", name)
+			continue
+		}
+
+		v, e := len(g.NodeList()), len(edges)
+		if f := countFaces(embedding); v-e+f != 2 {
+			t.Errorf("Embedding of %s violates Euler's formula: V=%d E=%d F=%d", name, v, e, f)
+		}
+	}
+
+	petersen := [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 0}, {0, 5}, {1, 6}, {2, 7}, {3, 8}, {4, 9}, {5, 7}, {7, 9}, {9, 6}, {6, 8}, {8, 5}}
+	nonPlanar := map[string][][2]int{
+		"K5":       completeEdges(5),
+		"K3,3":     {{0, 3}, {0, 4}, {0, 5}, {1, 3}, {1, 4}, {1, 5}, {2, 3}, {2, 4}, {2, 5}},
+		"Petersen": petersen,
+	}
+	for name, edges := range nonPlanar {
+		g := buildGraph(false, edges)
+		if graph.IsPlanar(g) {
+			t.Errorf("%s reported as planar", name)
+			continue
+		}
+
+		embedding, witness := graph.Planarity(g)
+		if embedding != nil || len(witness) == 0 {
+			t.Errorf("No Kuratowski witness for %s", name)
+			continue
+		}
+
+		pairs := make([][2]int, len(witness))
+		for i, e := range witness {
+			pairs[i] = [2]int{e.Head().ID(), e.Tail().ID()}
+		}
+		if graph.IsPlanar(buildGraph(false, pairs)) {
+			t.Errorf("Kuratowski witness for %s is planar", name)
+		}
+	}
+}
+
+func TestPlanarDual(t *testing.T) {
+	cube := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 7}, {7, 4}, {0, 4}, {1, 5}, {2, 6}, {3, 7}})
+	embedding, _ := graph.Planarity(cube)
+	if embedding == nil {
+		t.Fatal("Cube reported as non-planar")
+	}
+
+	faces := embedding.Faces()
+	if len(faces) != 6 {
+		t.Fatalf("Cube has %d faces, expected 6", len(faces))
+	}
+	for _, face := range faces {
+		if len(face) != 4 {
+			t.Error("Cube face isn't a square:", face)
+		}
+	}
+
+	// The dual of the cube is the octahedron
+	dual := embedding.Dual()
+	if len(dual.Edges) != 12 {
+		t.Errorf("Dual of cube has %d edges, expected 12", len(dual.Edges))
+	}
+	octahedron := dual.Graph(nil)
+	for _, node := range octahedron.NodeList() {
+		if len(octahedron.Successors(node)) != 4 {
+			t.Errorf("Dual node %v has degree %d, expected 4", node, len(octahedron.Successors(node)))
+		}
+	}
+
+	// A tree has a single face, and every dual edge is a loop
+	tree := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {1, 3}})
+	embedding, _ = graph.Planarity(tree)
+	dual = embedding.Dual()
+	if len(dual.Faces) != 1 || len(dual.Faces[0]) != 6 {
+		t.Error("Tree doesn't have a single face walking each edge twice:", dual.Faces)
+	}
+	for _, e := range dual.Edges {
+		if e.Left != e.Right {
+			t.Error("Bridge doesn't produce a dual loop:", e)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package graph
+
+import (
+	"sync"
+)
+
+// A Planner answers repeated queries on one graph, reusing its search state between queries instead of allocating it afresh every time. It's meant for services answering
+// thousands of queries a second, and for analyses that run a search from every node or from thousands of sampled ones, where the searches' per-query maps and heaps would make
+// up most of the garbage.
+//
+// Nodes are given a slot the first time they're seen, and every score, predecessor and closed flag lives in a flat slice indexed by slot. Instead of clearing those slices
+// between queries, every entry is stamped with the query (generation) that wrote it, and entries from older generations read as unset, so starting a new query is O(1). After
+// the first few queries have grown everything to size, an AStar query only allocates the path it returns, and a Dijkstra nothing at all, plus whatever the graph allocates to
+// list successors (nothing, for graphs that implement SuccessorVisitor without boxing nodes).
+//
+// A Planner isn't safe for concurrent use. Use one per goroutine, or share a PlannerPool.
+type Planner struct {
+	visit         func(node Node, fn func(succ Node, cost float64) bool)
+	heuristicCost func(Node, Node) float64
+	arena         *searchArena
+
+	// The state of the current expansion, for relax
+	relaxFn func(succ Node, cost float64) bool
+	curr    arenaEntry
+	goal    Node // nil for a Dijkstra, which has no heuristic
+}
+
+// Creates a Planner for graph. Cost and HeuristicCost are interpreted as in AStar. If graph can list its nodes (it's a Graph), every node is given its slot up front; otherwise, and for
+// nodes added to the graph later, slots are handed out as nodes are reached.
+func NewPlanner(graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) *Planner {
+	visit := successorVisitor(graph, Cost)
+	if HeuristicCost == nil {
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+
+	var nodes []Node
+	if g, ok := graph.(Graph); ok {
+		nodes = g.NodeList()
+	}
+	p := &Planner{
+		visit:         visit,
+		heuristicCost: HeuristicCost,
+		arena:         newSearchArena(len(nodes)),
+	}
+	for _, node := range nodes {
+		p.arena.slot(node)
+	}
+	p.relaxFn = p.relax // Bound once, since a method value allocates
+
+	return p
+}
+
+// Runs A* from start to goal, with the same results as AStar.
+func (p *Planner) AStar(start, goal Node) (path []Node, cost float64, nodesExpanded int) {
+	a := p.arena
+	a.reset()
+	p.goal = goal
+
+	s := a.slot(start)
+	a.setScore(s, 0, -1)
+	a.push(arenaEntry{s, 0, p.heuristicCost(start, goal)})
+
+	for len(a.open) != 0 {
+		curr := a.pop()
+		if a.isClosed(curr.slot) {
+			continue
+		}
+
+		nodesExpanded += 1
+		node := a.nodes[curr.slot]
+		if node.ID() == goal.ID() {
+			return a.path(curr.slot), curr.gscore, nodesExpanded
+		}
+		a.close(curr.slot)
+
+		p.curr = curr
+		p.visit(node, p.relaxFn)
+	}
+
+	return nil, 0.0, nodesExpanded
+}
+
+// Runs Dijkstra's algorithm from start, calling fn with each node reached in order of the cost of the shortest path to it, along with the node before it on that path (nil for
+// start itself), until fn returns false or every reachable node has been seen. The heuristic isn't used. Returns the number of nodes fn was called with.
+//
+// This is the building block for anything that needs the whole shortest path tree from a node rather than one path: sampled all pairs distances, closeness and betweenness, and
+// the like. Nothing is allocated per call once the Planner has grown to the graph's size, so fn should copy out whatever it needs to keep.
+func (p *Planner) Dijkstra(start Node, fn func(node, pred Node, cost float64) bool) (nodesExpanded int) {
+	a := p.arena
+	a.reset()
+	p.goal = nil
+
+	s := a.slot(start)
+	a.setScore(s, 0, -1)
+	a.push(arenaEntry{s, 0, 0})
+
+	for len(a.open) != 0 {
+		curr := a.pop()
+		if a.isClosed(curr.slot) {
+			continue
+		}
+		a.close(curr.slot)
+
+		nodesExpanded += 1
+		node := a.nodes[curr.slot]
+		if !fn(node, a.predecessor(curr.slot), curr.gscore) {
+			return nodesExpanded
+		}
+
+		p.curr = curr
+		p.visit(node, p.relaxFn)
+	}
+
+	return nodesExpanded
+}
+
+// Relaxes the edge from the node being expanded to neighbor
+func (p *Planner) relax(neighbor Node, cost float64) bool {
+	a := p.arena
+	n := a.slot(neighbor)
+	if a.isClosed(n) {
+		return true // Scores are final once expanded, as in AStar
+	}
+
+	g := p.curr.gscore + cost
+	if old, ok := a.score(n); ok && g >= old {
+		return true
+	}
+	a.setScore(n, g, p.curr.slot)
+	f := g
+	if p.goal != nil {
+		f += p.heuristicCost(neighbor, p.goal)
+	}
+	a.push(arenaEntry{n, g, f})
+	return true
+}
+
+// A PlannerPool hands out Planners for one graph to any number of goroutines, so a concurrent workload gets a Planner's reuse of search state without each goroutine keeping
+// one of its own. Planners that are put back are reused by the next Get; idle ones may be garbage collected, as with a sync.Pool, which backs it.
+//
+// A PlannerPool is safe for concurrent use.
+type PlannerPool struct {
+	pool sync.Pool
+}
+
+// Creates a pool of Planners for graph, each made as NewPlanner would with the same arguments.
+func NewPlannerPool(graph ImplicitGraph, Cost, HeuristicCost func(Node, Node) float64) *PlannerPool {
+	pp := &PlannerPool{}
+	pp.pool.New = func() interface{} {
+		return NewPlanner(graph, Cost, HeuristicCost)
+	}
+	return pp
+}
+
+// A Planner for the caller's use alone until it's Put back.
+func (pp *PlannerPool) Get() *Planner {
+	return pp.pool.Get().(*Planner)
+}
+
+// Returns a Planner to the pool. The caller mustn't use it afterwards.
+func (pp *PlannerPool) Put(p *Planner) {
+	pp.pool.Put(p)
+}
+
+// Runs A* from start to goal on a Planner from the pool, as Planner.AStar.
+func (pp *PlannerPool) AStar(start, goal Node) (path []Node, cost float64, nodesExpanded int) {
+	p := pp.Get()
+	defer pp.Put(p)
+	return p.AStar(start, goal)
+}
+
+// Runs Dijkstra's algorithm from start on a Planner from the pool, as Planner.Dijkstra.
+func (pp *PlannerPool) Dijkstra(start Node, fn func(node, pred Node, cost float64) bool) (nodesExpanded int) {
+	p := pp.Get()
+	defer pp.Put(p)
+	return p.Dijkstra(start, fn)
+}
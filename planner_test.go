@@ -0,0 +1,215 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestPlannerMatchesAStar(t *testing.T) {
+	src := rand.New(rand.NewSource(4))
+	tg := graph.RandomObstacleField(40, 40, 0.3, graph.GonumNode(0), graph.GonumNode(1599), src)
+	planner := graph.NewPlanner(tg, nil, nil)
+	nodes := tg.NodeList()
+
+	for i := 0; i < 200; i++ {
+		start, goal := nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]
+		path, cost, _ := planner.AStar(start, goal)
+		expectPath, expectCost, _ := graph.AStar(start, goal, tg, nil, nil)
+
+		if (path == nil) != (expectPath == nil) || cost != expectCost {
+			t.Fatalf("Planner found path %v with cost %v from %v to %v, AStar found %v with cost %v", path, cost, start, goal, expectPath, expectCost)
+		}
+		if path == nil {
+			continue
+		}
+		if path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID() || float64(len(path)-1) != cost {
+			t.Fatalf("Planner's path %v from %v to %v doesn't match its cost %v", path, start, goal, cost)
+		}
+		for j := 1; j < len(path); j++ {
+			if !tg.IsSuccessor(path[j-1], path[j]) {
+				t.Fatalf("Planner's path %v has a non-edge %v-%v", path, path[j-1], path[j])
+			}
+		}
+	}
+}
+
+func TestPlannerGrowsWithGraph(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	planner := graph.NewPlanner(g, nil, nil)
+	for i := 0; i < 5; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for i := 1; i < 5; i++ {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i - 1), T: graph.GonumNode(i)})
+	}
+
+	for i := 0; i < 3; i++ {
+		if path, cost, _ := planner.AStar(graph.GonumNode(0), graph.GonumNode(4)); len(path) != 5 || cost != 4 {
+			t.Fatalf("Query %d on nodes added after the planner was created found %v with cost %v", i, path, cost)
+		}
+	}
+}
+
+// A graph whose Successors doesn't allocate, so the allocations of a search itself can be counted
+type cachedSuccessors struct {
+	graph.Graph
+	succs map[int][]graph.Node
+}
+
+func (g cachedSuccessors) Successors(node graph.Node) []graph.Node {
+	return g.succs[node.ID()]
+}
+
+func TestPlannerAllocations(t *testing.T) {
+	tg := graph.NewTileGraph(30, 30, true)
+	g := cachedSuccessors{Graph: tg, succs: make(map[int][]graph.Node)}
+	for _, node := range tg.NodeList() {
+		g.succs[node.ID()] = tg.Successors(node)
+	}
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(29, 29)
+	planner := graph.NewPlanner(g, nil, nil)
+	planner.AStar(start, goal)
+
+	if allocs := testing.AllocsPerRun(10, func() { planner.AStar(start, goal) }); allocs > 1 {
+		t.Errorf("Planner allocated %v times per query, expected only the path", allocs)
+	}
+}
+
+func TestPlannerDijkstra(t *testing.T) {
+	src := rand.New(rand.NewSource(5))
+	g := graph.NewGonumGraph(true)
+	graph.GnmRandomGraph(g, 60, 240, true, src)
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, 1+src.Float64())
+	}
+	planner := graph.NewPlanner(g, nil, nil)
+
+	for _, start := range g.NodeList() {
+		seen := make(map[int]float64)
+		last := 0.0
+		planner.Dijkstra(start, func(node, pred graph.Node, cost float64) bool {
+			if cost < last {
+				t.Fatalf("From %v, Dijkstra reached %v at cost %v after a node at cost %v", start, node, cost, last)
+			}
+			last = cost
+			if pred == nil {
+				if node.ID() != start.ID() || cost != 0 {
+					t.Fatalf("From %v, Dijkstra reached %v at cost %v with no predecessor", start, node, cost)
+				}
+			} else if predCost, ok := seen[pred.ID()]; !ok || !graph.DefaultTolerance(predCost+g.Cost(pred, node), cost) {
+				t.Fatalf("From %v, Dijkstra reached %v at cost %v from %v, which was seen at %v (%t)", start, node, cost, pred, predCost, ok)
+			}
+			seen[node.ID()] = cost
+			return true
+		})
+
+		for _, goal := range g.NodeList() {
+			path, want, _ := graph.UniformCostSearch(start, goal, g, nil)
+			got, ok := seen[goal.ID()]
+			if (path != nil) != ok || ok && !graph.DefaultTolerance(got, want) {
+				t.Fatalf("From %v to %v, Dijkstra found cost %v (%t) where UniformCostSearch found %v", start, goal, got, ok, want)
+			}
+		}
+	}
+
+	// Stopping early leaves the next search unaffected
+	count := 0
+	planner.Dijkstra(graph.GonumNode(0), func(node, pred graph.Node, cost float64) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Errorf("Dijkstra carried on for %d nodes after being told to stop at 3", count)
+	}
+	path, cost, _ := planner.AStar(graph.GonumNode(0), graph.GonumNode(1))
+	wantPath, wantCost, _ := graph.AStar(graph.GonumNode(0), graph.GonumNode(1), g, nil, nil)
+	if len(path) != len(wantPath) || cost != wantCost {
+		t.Errorf("After a Dijkstra, AStar found %v costing %v, where a fresh search finds %v costing %v", path, cost, wantPath, wantCost)
+	}
+}
+
+func TestPlannerDijkstraAllocations(t *testing.T) {
+	tg := graph.NewTileGraph(30, 30, true)
+	g := cachedSuccessors{Graph: tg, succs: make(map[int][]graph.Node)}
+	for _, node := range tg.NodeList() {
+		g.succs[node.ID()] = tg.Successors(node)
+	}
+	planner := graph.NewPlanner(g, nil, nil)
+	total := 0.0
+	fn := func(node, pred graph.Node, cost float64) bool {
+		total += cost
+		return true
+	}
+	planner.Dijkstra(tg.CoordsToNode(0, 0), fn)
+
+	if allocs := testing.AllocsPerRun(10, func() { planner.Dijkstra(tg.CoordsToNode(0, 0), fn) }); allocs > 0 {
+		t.Errorf("Dijkstra allocated %v times per search, expected none", allocs)
+	}
+}
+
+func TestPlannerPool(t *testing.T) {
+	src := rand.New(rand.NewSource(6))
+	tg := graph.RandomObstacleField(30, 30, 0.3, graph.GonumNode(0), graph.GonumNode(899), src)
+	pool := graph.NewPlannerPool(tg, nil, nil)
+	nodes := tg.NodeList()
+	queries := make([][2]graph.Node, 200)
+	for i := range queries {
+		queries[i] = [2]graph.Node{nodes[src.Intn(len(nodes))], nodes[src.Intn(len(nodes))]}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, len(queries))
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(queries); i += 4 {
+				start, goal := queries[i][0], queries[i][1]
+				path, cost, _ := pool.AStar(start, goal)
+				want, wantCost, _ := graph.AStar(start, goal, tg, nil, nil)
+				if (path == nil) != (want == nil) || cost != wantCost {
+					errs <- "pooled AStar disagrees with AStar"
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	p := pool.Get()
+	reached := 0
+	p.Dijkstra(nodes[0], func(node, pred graph.Node, cost float64) bool {
+		reached++
+		return true
+	})
+	pool.Put(p)
+	if n := pool.Dijkstra(nodes[0], func(graph.Node, graph.Node, float64) bool { return true }); n != reached {
+		t.Errorf("The pool's Dijkstra reached %d nodes, a Planner from it %d", n, reached)
+	}
+}
+
+func BenchmarkPlannerAStar(b *testing.B) {
+	tg := graph.NewTileGraph(100, 100, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(99, 99)
+	planner := graph.NewPlanner(tg, nil, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		planner.AStar(start, goal)
+	}
+}
+
+func BenchmarkAStar(b *testing.B) {
+	tg := graph.NewTileGraph(100, 100, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(99, 99)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.AStar(start, goal, tg, nil, nil)
+	}
+}
@@ -0,0 +1,41 @@
+package graph
+
+// A PriorityQueue is a min-priority queue of nodes with decrease-key, as used by D*-Lite and DijkstraQueue. The methods behave as documented on IndexedHeap, except that Items may
+// return the items in any order, and only IndexedHeap's is guaranteed to be the queue's own slice.
+type PriorityQueue interface {
+	Len() int
+	Contains(node Node) bool
+	Key(node Node) (key interface{}, ok bool)
+	Push(node Node, key interface{})
+	Fix(node Node, key interface{}) bool
+	Peek() HeapItem
+	Pop() HeapItem
+	Remove(node Node) bool
+	Clear()
+	Items() []HeapItem
+}
+
+// Selects a PriorityQueue implementation. Which is fastest depends on the workload (BenchmarkDijkstraQueue is a starting point), but each kind is documented with a rule of thumb.
+type QueueKind int
+
+const (
+	// A binary IndexedHeap. The default, and a safe choice for anything.
+	BinaryHeapQueue QueueKind = iota
+	// A 4-ary IndexedHeap. Usually a little faster than a binary heap on sparse graphs like grids, where there are about as many pops as pushes.
+	QuaternaryHeapQueue
+	// A PairingHeap. Its O(1) decrease-key can pay off on dense graphs, where keys are decreased far more often than the minimum is popped, but it chases pointers where the
+	// array heaps don't, so measure before switching.
+	PairingHeapQueue
+)
+
+// Creates an empty PriorityQueue of the given kind, ordered by less.
+func NewPriorityQueue(kind QueueKind, less func(a, b HeapItem) bool) PriorityQueue {
+	switch kind {
+	case QuaternaryHeapQueue:
+		return NewDaryHeap(4, less)
+	case PairingHeapQueue:
+		return NewPairingHeap(less)
+	default:
+		return NewIndexedHeap(less)
+	}
+}
@@ -0,0 +1,135 @@
+package graph_test
+
+import (
+	"fmt"
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var queueKinds = map[string]graph.QueueKind{
+	"binary":     graph.BinaryHeapQueue,
+	"quaternary": graph.QuaternaryHeapQueue,
+	"pairing":    graph.PairingHeapQueue,
+}
+
+func TestPriorityQueues(t *testing.T) {
+	for name, kind := range queueKinds {
+		q := graph.NewPriorityQueue(kind, func(a, b graph.HeapItem) bool {
+			return a.Key.(int) < b.Key.(int) || a.Key.(int) == b.Key.(int) && a.ID() < b.ID()
+		})
+		src := rand.New(rand.NewSource(1))
+		expect := make(map[int]int)
+
+		for i := 0; i < 20000; i++ {
+			node := graph.GonumNode(src.Intn(200))
+			switch op := src.Intn(5); {
+			case op < 3:
+				// Mostly decreases, like a search, but increases too
+				key := src.Intn(1000)
+				q.Push(node, key)
+				expect[node.ID()] = key
+			case op == 3:
+				_, queued := expect[node.ID()]
+				if removed := q.Remove(node); removed != queued {
+					t.Fatalf("%s: Remove(%v) returned %v, but the node queued is %v", name, node, removed, queued)
+				}
+				delete(expect, node.ID())
+			default:
+				if q.Len() == 0 {
+					continue
+				}
+				min := -1
+				for id, key := range expect {
+					if min == -1 || key < expect[min] || key == expect[min] && id < min {
+						min = id
+					}
+				}
+				if item := q.Peek(); item.ID() != min {
+					t.Fatalf("%s: Peeked %v, expected %d", name, item.Node, min)
+				}
+				if item := q.Pop(); item.ID() != min || item.Key.(int) != expect[min] {
+					t.Fatalf("%s: Popped %v with key %v, expected %d with key %d", name, item.Node, item.Key, min, expect[min])
+				}
+				delete(expect, min)
+			}
+
+			if q.Len() != len(expect) {
+				t.Fatalf("%s: Queue has %d items, expected %d", name, q.Len(), len(expect))
+			}
+		}
+
+		items := q.Items()
+		if len(items) != len(expect) {
+			t.Fatalf("%s: Items returned %d items, expected %d", name, len(items), len(expect))
+		}
+		for _, item := range items {
+			if key, ok := expect[item.ID()]; !ok || key != item.Key.(int) {
+				t.Errorf("%s: Items has %v with key %v, expected key %v", name, item.Node, item.Key, key)
+			}
+		}
+	}
+}
+
+func randomWeightedGraph(n int, p float64, seed int64) *graph.GonumGraph {
+	src := rand.New(rand.NewSource(seed))
+	g := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(g, n, p, true, src)
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, 1+src.Float64()*9)
+	}
+
+	return g
+}
+
+func TestDijkstraQueue(t *testing.T) {
+	g := randomWeightedGraph(100, 0.1, 2)
+	source := graph.GonumNode(0)
+
+	for name, kind := range queueKinds {
+		paths, costs := graph.DijkstraQueue(source, g, nil, kind)
+		for _, node := range g.NodeList() {
+			_, expect, _ := graph.AStar(source, node, g, nil, nil)
+			path, ok := paths[node.ID()]
+			if !ok {
+				t.Errorf("%s: no path to %v", name, node)
+				continue
+			}
+
+			sum := 0.0
+			for i := 1; i < len(path); i++ {
+				sum += g.Cost(path[i-1], path[i])
+			}
+			if math.Abs(costs[node.ID()]-expect) > 1e-9 || math.Abs(sum-expect) > 1e-9 {
+				t.Errorf("%s: cost to %v is %v (path %v costing %v), expected %v", name, node, costs[node.ID()], path, sum, expect)
+			}
+		}
+	}
+}
+
+func TestDStarQueues(t *testing.T) {
+	truth := graph.RandomObstacleField(30, 30, 0.3, graph.GonumNode(0), graph.GonumNode(899), rand.New(rand.NewSource(6)))
+	start, goal := truth.CoordsToNode(0, 0), truth.CoordsToNode(29, 29)
+
+	expect := len(walkDStar(t, graph.NewRevealingTileGraph(truth, start, 2), goal, graph.WithDStarComparator(graph.DStarTieBreakByID)))
+	for name, kind := range queueKinds {
+		path := walkDStar(t, graph.NewRevealingTileGraph(truth, start, 2), goal, graph.WithDStarComparator(graph.DStarTieBreakByID), graph.WithDStarQueue(kind))
+		if len(path) != expect {
+			t.Errorf("%s: walk took %d steps, expected %d", name, len(path), expect)
+		}
+	}
+}
+
+func BenchmarkDijkstraQueue(b *testing.B) {
+	for _, density := range []float64{0.01, 0.5} {
+		g := randomWeightedGraph(500, density, 1)
+		for _, name := range []string{"binary", "quaternary", "pairing"} {
+			b.Run(fmt.Sprintf("p=%v/%s", density, name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					graph.DijkstraQueue(graph.GonumNode(0), g, nil, queueKinds[name])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"math"
+	"time"
+)
+
+// A snapshot of how far a long computation has got: Settled of Total units of work are done (what a unit is depends on the algorithm, e.g. one source in all pairs shortest paths),
+// and Bound is the algorithm's current best bound, or NaN if it doesn't have a meaningful one.
+type Progress struct {
+	Settled, Total int
+	Bound          float64
+}
+
+// A ProgressFunc receives progress reports, e.g. to drive a progress bar. It's called synchronously from the computation, so it should return quickly.
+type ProgressFunc func(Progress)
+
+// Rate limits progress reports: a report is passed on if at least interval has passed since the last one, and the final report (Settled == Total) always is.
+// A nil reporter, or one with a nil func, does nothing, so algorithms can report unconditionally.
+type progressReporter struct {
+	report   ProgressFunc
+	interval time.Duration
+	last     time.Time
+}
+
+func newProgressReporter(report ProgressFunc, interval time.Duration) *progressReporter {
+	return &progressReporter{report: report, interval: interval}
+}
+
+func (p *progressReporter) update(settled, total int, bound float64) {
+	if p == nil || p.report == nil {
+		return
+	}
+
+	now := time.Now()
+	if settled < total && !p.last.IsZero() && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.last = now
+	p.report(Progress{Settled: settled, Total: total, Bound: bound})
+}
+
+var noBound = math.NaN()
@@ -0,0 +1,29 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+	"time"
+)
+
+func TestJohnsonProgress(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	graph.CycleGraph(g, 20, true)
+
+	reports := make([]graph.Progress, 0)
+	graph.JohnsonProgress(g, nil, func(p graph.Progress) { reports = append(reports, p) }, 0)
+	if len(reports) != 20 {
+		t.Fatalf("Got %d progress reports with no interval, expected one per node", len(reports))
+	}
+	for i, report := range reports {
+		if report.Settled != i+1 || report.Total != 20 {
+			t.Errorf("Report %d is %+v", i, report)
+		}
+	}
+
+	reports = reports[:0]
+	graph.JohnsonProgress(g, nil, func(p graph.Progress) { reports = append(reports, p) }, time.Hour)
+	if len(reports) != 2 || reports[1].Settled != 20 {
+		t.Errorf("Expected only the first and final reports with a long interval, got %+v", reports)
+	}
+}
@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"sort"
+)
+
+// A Query is a set of nodes of a graph, built up by chaining selections, expansions along edges and set operations, so that exploratory questions such as "the successors
+// within three hops of any node tagged X, along edges cheaper than 5, that aren't tagged Y" don't each need a breadth first search written by hand:
+//
+//	cheap := func(e Edge) bool { return g.Cost(e.Head(), e.Tail()) < 5 }
+//	nodes := Select(g, taggedX).Out(cheap, 3).Where(notTaggedY).Nodes()
+//
+// Nodes carry no attributes in this package, so predicates look up whatever the caller keeps alongside the graph by ID. A Query is never changed by its methods, which each
+// return a new one, so intermediate queries can be kept and reused. Every result is sorted by ID, and the graph shouldn't be modified while its queries are in use.
+type Query struct {
+	graph Graph
+	nodes []Node // Sorted by ID, without repeats
+}
+
+// A query of the nodes of graph for which match is true, or all of them if it's nil.
+func Select(graph Graph, match func(Node) bool) *Query {
+	var nodes []Node
+	for _, node := range graph.NodeList() {
+		if match == nil || match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Sort(byID(nodes))
+	return &Query{graph: graph, nodes: nodes}
+}
+
+// A query of the given nodes of graph. Nodes not in the graph are left out.
+func From(graph Graph, nodes ...Node) *Query {
+	var in []Node
+	for _, node := range nodes {
+		if graph.NodeExists(node) {
+			in = append(in, node)
+		}
+	}
+	sort.Sort(byID(in))
+	return &Query{graph: graph, nodes: uniqueByID(in)}
+}
+
+// The nodes of q for which match is true.
+func (q *Query) Where(match func(Node) bool) *Query {
+	var nodes []Node
+	for _, node := range q.nodes {
+		if match(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return &Query{graph: q.graph, nodes: nodes}
+}
+
+// The nodes reachable from the nodes of q in between 1 and depth steps along edges for which match is true (every edge if it's nil), followed from head to tail. A node of q
+// is only included if it's reachable from one, and a negative depth has no limit. Edges are given to match as GonumEdges, in the graph's direction.
+func (q *Query) Out(match func(Edge) bool, depth int) *Query {
+	return q.expand(match, depth, true, false)
+}
+
+// The nodes from which the nodes of q are reachable, as Out, but following edges backwards from tail to head.
+func (q *Query) In(match func(Edge) bool, depth int) *Query {
+	return q.expand(match, depth, false, true)
+}
+
+// The nodes reachable from the nodes of q following edges either way, as Out.
+func (q *Query) Both(match func(Edge) bool, depth int) *Query {
+	return q.expand(match, depth, true, true)
+}
+
+// Breadth first search from all the nodes of q at once
+func (q *Query) expand(match func(Edge) bool, depth int, forward, backward bool) *Query {
+	seen := make(map[int]bool)
+	reached := make(map[int]bool)
+	var result []Node
+	frontier := q.nodes
+	for _, node := range frontier {
+		seen[node.ID()] = true
+	}
+
+	for d := 0; len(frontier) != 0 && (depth < 0 || d < depth); d++ {
+		var next []Node
+		visit := func(node Node, edge Edge) {
+			if match != nil && !match(edge) || reached[node.ID()] {
+				return
+			}
+			reached[node.ID()] = true
+			result = append(result, node)
+			if !seen[node.ID()] {
+				seen[node.ID()] = true
+				next = append(next, node)
+			}
+		}
+		for _, node := range frontier {
+			if forward {
+				for _, succ := range q.graph.Successors(node) {
+					visit(succ, GonumEdge{H: node, T: succ})
+				}
+			}
+			if backward {
+				for _, pred := range q.graph.Predecessors(node) {
+					visit(pred, GonumEdge{H: pred, T: node})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sort.Sort(byID(result))
+	return &Query{graph: q.graph, nodes: result}
+}
+
+// The nodes in q or other, which must be of the same graph.
+func (q *Query) Union(other *Query) *Query {
+	nodes := append(append([]Node(nil), q.nodes...), other.nodes...)
+	sort.Sort(byID(nodes))
+	return &Query{graph: q.graph, nodes: uniqueByID(nodes)}
+}
+
+// The nodes in both q and other.
+func (q *Query) Intersect(other *Query) *Query {
+	in := other.set()
+	return q.Where(func(node Node) bool { return in[node.ID()] })
+}
+
+// The nodes in q but not other.
+func (q *Query) Minus(other *Query) *Query {
+	in := other.set()
+	return q.Where(func(node Node) bool { return !in[node.ID()] })
+}
+
+// The nodes of q, sorted by ID. The slice is freshly allocated.
+func (q *Query) Nodes() []Node {
+	return append([]Node(nil), q.nodes...)
+}
+
+// How many nodes q has.
+func (q *Query) Count() int {
+	return len(q.nodes)
+}
+
+// Whether node is one of the nodes of q.
+func (q *Query) Contains(node Node) bool {
+	i := sort.Search(len(q.nodes), func(i int) bool { return q.nodes[i].ID() >= node.ID() })
+	return i < len(q.nodes) && q.nodes[i].ID() == node.ID()
+}
+
+// The edges between nodes of q, in order of head then tail ID. An undirected edge is given once, from its lower ID end.
+func (q *Query) Edges() []Edge {
+	in := q.set()
+	var edges []Edge
+	for _, node := range q.nodes {
+		succs := append([]Node(nil), q.graph.Successors(node)...)
+		sort.Sort(byID(succs))
+		for _, succ := range succs {
+			if in[succ.ID()] && (q.graph.IsDirected() || node.ID() <= succ.ID()) {
+				edges = append(edges, GonumEdge{H: node, T: succ})
+			}
+		}
+	}
+	return edges
+}
+
+// Fills dst with the subgraph q induces: its nodes, and the edges between them with their costs if the graph is a Coster. dst is made directed or not as the graph is.
+func (q *Query) Subgraph(dst MutableGraph) {
+	dst.EmptyGraph()
+	dst.SetDirected(q.graph.IsDirected())
+	for _, node := range q.nodes {
+		dst.AddNode(node, nil)
+	}
+	cgraph, isCoster := q.graph.(Coster)
+	for _, edge := range q.Edges() {
+		dst.AddEdge(edge)
+		if isCoster {
+			dst.SetEdgeCost(edge, cgraph.Cost(edge.Head(), edge.Tail()))
+		}
+	}
+}
+
+func (q *Query) set() map[int]bool {
+	in := make(map[int]bool, len(q.nodes))
+	for _, node := range q.nodes {
+		in[node.ID()] = true
+	}
+	return in
+}
@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func ids(nodes []graph.Node) []int {
+	ids := make([]int, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID()
+	}
+	return ids
+}
+
+func TestQuery(t *testing.T) {
+	// 0 -> 1 -> 2 -> 3 -> 4, with a costly shortcut 0 -> 5 -> 4, and 6 on its own
+	g := ruleGraph([]int{0, 1, 2, 3, 4, 5, 6}, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {0, 5}, {5, 4}})
+	g.SetEdgeCost(graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(5)}, 10)
+	even := func(node graph.Node) bool { return node.ID()%2 == 0 }
+	cheap := func(e graph.Edge) bool { return g.Cost(e.Head(), e.Tail()) < 5 }
+
+	tests := []struct {
+		name     string
+		query    *graph.Query
+		expected []int
+	}{
+		{"Select", graph.Select(g, even), []int{0, 2, 4, 6}},
+		{"From", graph.From(g, graph.GonumNode(3), graph.GonumNode(9), graph.GonumNode(1), graph.GonumNode(3)), []int{1, 3}},
+		{"Out", graph.From(g, graph.GonumNode(0)).Out(nil, 2), []int{1, 2, 4, 5}},
+		{"Out cheap", graph.From(g, graph.GonumNode(0)).Out(cheap, 2), []int{1, 2}},
+		{"Out unlimited", graph.From(g, graph.GonumNode(0)).Out(cheap, -1), []int{1, 2, 3, 4}},
+		{"In", graph.From(g, graph.GonumNode(4)).In(nil, 1), []int{3, 5}},
+		{"Both", graph.From(g, graph.GonumNode(2)).Both(nil, 1), []int{1, 3}},
+		{"Where", graph.Select(g, nil).Out(nil, 1).Where(even), []int{2, 4}},
+		{"Union", graph.From(g, graph.GonumNode(6)).Union(graph.From(g, graph.GonumNode(4)).In(nil, 1)), []int{3, 5, 6}},
+		{"Intersect", graph.Select(g, even).Intersect(graph.From(g, graph.GonumNode(0)).Out(nil, 2)), []int{2, 4}},
+		{"Minus", graph.Select(g, nil).Minus(graph.From(g, graph.GonumNode(2)).Out(nil, -1)), []int{0, 1, 2, 5, 6}},
+	}
+	for _, test := range tests {
+		if got := ids(test.query.Nodes()); !reflect.DeepEqual(got, test.expected) && !(len(got) == 0 && len(test.expected) == 0) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+		if test.query.Count() != len(test.expected) {
+			t.Errorf("%s: expected a count of %d, got %d", test.name, len(test.expected), test.query.Count())
+		}
+	}
+
+	// A node is only in its own expansion if it can reach itself
+	cycle := ruleGraph([]int{0, 1, 2}, [][2]int{{0, 1}, {1, 2}, {2, 0}})
+	if got := ids(graph.From(cycle, graph.GonumNode(0)).Out(nil, 2).Nodes()); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Expected 1 and 2 within 2 steps around the cycle, got %v", got)
+	}
+	if got := ids(graph.From(cycle, graph.GonumNode(0)).Out(nil, 3).Nodes()); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("Expected the whole cycle within 3 steps, got %v", got)
+	}
+
+	q := graph.From(g, graph.GonumNode(0), graph.GonumNode(5), graph.GonumNode(4), graph.GonumNode(3))
+	if !q.Contains(graph.GonumNode(5)) || q.Contains(graph.GonumNode(1)) {
+		t.Errorf("Contains disagrees with %v", ids(q.Nodes()))
+	}
+	sub := graph.NewGonumGraph(false)
+	q.Subgraph(sub)
+	if len(sub.NodeList()) != 4 || len(sub.EdgeList()) != 3 || !sub.IsDirected() || sub.Cost(graph.GonumNode(0), graph.GonumNode(5)) != 10 {
+		t.Errorf("Expected the subgraph on 0, 3, 4 and 5, got %v", sub.EdgeList())
+	}
+}
@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// Reaches are a precomputed speedup for shortest path queries on road networks[1]. The reach of a node is the largest, over every shortest path through it, of the smaller of the
+// distances from the path's start to the node and from the node to the path's end: a node with a small reach is only on shortest paths that start or end near it, like a cul-de-sac,
+// while motorway junctions have large reaches. A search that's far from both the start and goal can skip every node whose reach is smaller than those distances, so long queries only
+// look at the few nodes of the main roads.
+//
+// Computing exact reaches takes a Dijkstra search from every node, so it's for graphs of modest size or preprocessed offline. The reaches go stale if the graph changes.
+//
+// [1] R. Gutman, "Reach-based routing: A new approach to shortest path algorithms optimized for road networks", ALENEX 2004
+type Reaches struct {
+	graph Graph
+	cost  func(Node, Node) float64
+	reach map[int]float64
+}
+
+// Computes the reach of every node in graph. Cost is interpreted as in AStar, and costs must be positive. Ties between shortest paths are accounted for, so every reach is exact
+// rather than depending on which of several equally short paths a search would happen to find.
+func NewReaches(graph Graph, Cost func(Node, Node) float64) *Reaches {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	r := &Reaches{graph: graph, cost: Cost, reach: make(map[int]float64)}
+	for _, source := range graph.NodeList() {
+		dist := distancesFrom(source, graph, Cost, false)
+		nodes := make([]Node, 0, len(dist))
+		for _, node := range graph.NodeList() {
+			if _, ok := dist[node.ID()]; ok {
+				nodes = append(nodes, node)
+			}
+		}
+		sort.Sort(byKey{len(nodes), func(i int) float64 { return -dist[nodes[i].ID()] }, func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] }})
+
+		// The height of a node is the farthest it's followed along any shortest path from source, found from the farthest nodes back; its reach over the paths from source is
+		// the smaller of that and its distance from source
+		height := make(map[int]float64, len(nodes))
+		for _, node := range nodes {
+			d := dist[node.ID()]
+			for _, succ := range graph.Successors(node) {
+				ds, ok := dist[succ.ID()]
+				if c := Cost(node, succ); ok && ds > d && DefaultTolerance(d+c, ds) {
+					height[node.ID()] = math.Max(height[node.ID()], c+height[succ.ID()])
+				}
+			}
+			r.reach[node.ID()] = math.Max(r.reach[node.ID()], math.Min(d, height[node.ID()]))
+		}
+	}
+
+	return r
+}
+
+// The reach of node, or +Inf if it wasn't in the graph when the reaches were computed.
+func (r *Reaches) Reach(node Node) float64 {
+	if reach, ok := r.reach[node.ID()]; ok {
+		return reach
+	}
+	return math.Inf(1)
+}
+
+// Finds the shortest path from start to goal by bidirectional Dijkstra, pruning every node whose reach is smaller than both its distance from the side it was reached from and a lower
+// bound on its distance to the other side. The bound is how far the other search has got, or HeuristicCost if it's larger, so passing the HeuristicCost of Landmarks (ALT) prunes
+// more than the search radius alone. HeuristicCost must be admissible, and if it's nil the graph's HeuristicCoster or else the NullHeuristic is used. Results are as for AStar.
+func (r *Reaches) ShortestPath(start, goal Node, HeuristicCost func(Node, Node) float64) (path []Node, cost float64, nodesExpanded int) {
+	if HeuristicCost == nil {
+		if hgraph, ok := r.graph.(HeuristicCoster); ok {
+			HeuristicCost = hgraph.HeuristicCost
+		} else {
+			HeuristicCost = NullHeuristic
+		}
+	}
+	if start.ID() == goal.ID() {
+		return []Node{start}, 0, 0
+	}
+
+	forward := newReachSide(start, r.graph.Successors, r.cost, func(node Node) float64 { return HeuristicCost(node, goal) })
+	backward := newReachSide(goal, r.graph.Predecessors, func(a, b Node) float64 { return r.cost(b, a) }, func(node Node) float64 { return HeuristicCost(start, node) })
+
+	best := math.Inf(1)
+	var meetFrom, meetTo Node // The edge where the best path so far crosses from the forward search to the backward one
+	for {
+		rf, rb := forward.radius(), backward.radius()
+		if rf+rb >= best || math.IsInf(rf, 1) || math.IsInf(rb, 1) {
+			break
+		}
+		side, other := forward, backward
+		if rb < rf {
+			side, other = backward, forward
+		}
+
+		curr := heap.Pop(side.queue).(internalNode)
+		side.settled[curr.ID()] = true
+		nodesExpanded += 1
+
+		for _, succ := range side.next(curr.Node) {
+			d := curr.gscore + side.cost(curr.Node, succ)
+			if side.settled[succ.ID()] || math.IsInf(d, 1) {
+				continue
+			}
+
+			// Meetings count even if succ is then pruned, since the path through it was found all the same
+			if od, ok := other.dist[succ.ID()]; ok && d+od < best {
+				best = d + od
+				if side == forward {
+					meetFrom, meetTo = curr.Node, succ
+				} else {
+					meetFrom, meetTo = succ, curr.Node
+				}
+			}
+
+			if old, ok := side.dist[succ.ID()]; ok && d >= old {
+				continue
+			}
+			bound := side.bound(succ)
+			if other.settled[succ.ID()] {
+				bound = other.dist[succ.ID()]
+			} else {
+				bound = math.Max(bound, other.radius())
+			}
+			if reach := r.Reach(succ); reachBelow(reach, d) && reachBelow(reach, bound) {
+				continue
+			}
+
+			side.dist[succ.ID()] = d
+			side.parent[succ.ID()] = curr.Node
+			heap.Push(side.queue, internalNode{succ, d, d})
+		}
+	}
+
+	if meetFrom == nil {
+		return nil, 0.0, nodesExpanded
+	}
+	path = rebuildPath(forward.parent, meetFrom)
+	for node := meetTo; node != nil; node = backward.parent[node.ID()] {
+		path = append(path, node)
+	}
+
+	return path, best, nodesExpanded
+}
+
+// Whether reach is definitely smaller than d, and not just by rounding
+func reachBelow(reach, d float64) bool {
+	return reach < d && !DefaultTolerance(reach, d)
+}
+
+// One direction of a reach pruned bidirectional Dijkstra search
+type reachSide struct {
+	dist    map[int]float64
+	parent  map[int]Node
+	settled map[int]bool
+	queue   *aStarPriorityQueue
+	next    func(Node) []Node
+	cost    func(Node, Node) float64 // Along this side's direction of search
+	bound   func(Node) float64       // A lower bound on the distance from a node to the other side's origin
+}
+
+func newReachSide(origin Node, next func(Node) []Node, cost func(Node, Node) float64, bound func(Node) float64) *reachSide {
+	return &reachSide{
+		dist:    map[int]float64{origin.ID(): 0},
+		parent:  make(map[int]Node),
+		settled: make(map[int]bool),
+		queue:   &aStarPriorityQueue{{origin, 0, 0}},
+		next:    next,
+		cost:    cost,
+		bound:   bound,
+	}
+}
+
+// The distance of the closest unsettled node this side has found, or +Inf if there are none left; no node it hasn't settled can be closer
+func (side *reachSide) radius() float64 {
+	for side.queue.Len() != 0 {
+		top := (*side.queue)[0]
+		if !side.settled[top.ID()] && top.gscore <= side.dist[top.ID()] {
+			return top.gscore
+		}
+		heap.Pop(side.queue)
+	}
+	return math.Inf(1)
+}
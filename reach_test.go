@@ -0,0 +1,61 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestReaches(t *testing.T) {
+	// On a path, a node's reach is its distance to the nearer end
+	line := graph.NewGonumGraph(false)
+	for i := 4; i > 0; i-- {
+		line.AddNode(graph.GonumNode(i-1), []graph.Node{graph.GonumNode(i)})
+	}
+	reaches := graph.NewReaches(line, nil)
+	for i, want := range []float64{0, 1, 2, 1, 0} {
+		if got := reaches.Reach(graph.GonumNode(i)); got != want {
+			t.Errorf("Node %d on a path of 5 has reach %v, expected %v", i, got, want)
+		}
+	}
+
+	geometric := graph.NewGonumGraph(false)
+	graph.RandomGeometricGraph(geometric, 250, 2, 0.12, rand.New(rand.NewSource(1)))
+
+	directed := graph.NewGonumGraph(true)
+	graph.GnpRandomGraph(directed, 150, 0.04, true, rand.New(rand.NewSource(2)))
+	src := rand.New(rand.NewSource(3))
+	for _, edge := range directed.EdgeList() {
+		directed.SetEdgeCost(edge, 1+src.Float64())
+	}
+
+	for name, g := range map[string]*graph.GonumGraph{"geometric": geometric, "directed": directed} {
+		reaches := graph.NewReaches(g, nil)
+		landmarks := graph.NewLandmarks(g, nil, 4, rand.New(rand.NewSource(4)))
+
+		n := len(g.NodeList())
+		plain, pruned, alt := 0, 0, 0
+		for i := 0; i < 100; i++ {
+			start, goal := graph.GonumNode(src.Intn(n)), graph.GonumNode(src.Intn(n))
+			want, wantCost, expanded := graph.UniformCostSearch(start, goal, g, nil)
+			plain += expanded
+
+			for j, h := range []func(graph.Node, graph.Node) float64{graph.NullHeuristic, landmarks.HeuristicCost} {
+				path, cost, expanded := reaches.ShortestPath(start, goal, h)
+				if (path == nil) != (want == nil) || math.Abs(cost-wantCost) > 1e-9 || path != nil && (!graph.IsPath(path, g) || path[0].ID() != start.ID() || path[len(path)-1].ID() != goal.ID()) {
+					t.Errorf("%s: from %v to %v reach pruning found %v costing %v, expected %v costing %v", name, start, goal, path, cost, want, wantCost)
+				}
+				if j == 0 {
+					pruned += expanded
+				} else {
+					alt += expanded
+				}
+			}
+		}
+		if pruned >= plain || alt > pruned {
+			t.Errorf("%s: expected reach pruning to expand fewer nodes than Dijkstra, and fewer still with landmarks; expanded %d, %d with pruning, %d with landmarks", name, plain, pruned, alt)
+		}
+	}
+}
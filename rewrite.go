@@ -0,0 +1,210 @@
+package graph
+
+import (
+	"errors"
+	"sort"
+)
+
+// Returned by Rewrite when the rules can still be applied after the most steps it was allowed.
+var ErrRewriteLimit = errors.New("Rewriting didn't finish within the step limit")
+
+// A RewriteRule replaces each occurrence of a small Pattern graph with a Replacement graph. The two share nodes by ID: pattern nodes with no counterpart in the replacement
+// are deleted, along with every edge they have, matched or not; replacement nodes with no counterpart in the pattern are created; and the nodes in both are kept, along with
+// their edges to the rest of the graph. Likewise pattern edges between matched nodes that aren't in the replacement are deleted, and replacement edges that aren't in the pattern
+// are added, with the replacement's costs. This is the single pushout approach to graph rewriting[1]. Pattern and Replacement should be directed or not as the graph to be
+// rewritten is.
+//
+// Matches are found by VF2, with Mode, NodeMatch and EdgeMatch as documented there; Mode should be set, since its zero value, GraphIsomorphism, only matches the whole graph.
+// Condition, if not nil, is a further test of each match, by pattern node ID, against the whole graph, for conditions a pattern can't express, such as a node having no other
+// successors. Applied, if not nil, is called after each application of the rule with the match and the nodes the replacement's IDs went to, so that data kept alongside the
+// graph, such as labels, can be updated.
+//
+// [1] M. Löwe, "Algebraic approach to single-pushout graph transformation", Theoretical Computer Science 109 (1993)
+type RewriteRule struct {
+	Pattern, Replacement Graph
+	Mode                 IsomorphismMode
+	NodeMatch            func(Node, Node) bool
+	EdgeMatch            func(Edge, Edge) bool
+	Condition            func(graph Graph, match map[int]Node) bool
+	Applied              func(match, image map[int]Node)
+}
+
+// Returns the first match of the rule's pattern in graph that meets its Condition, by pattern node ID, or nil if there's none. Matches are tried in an order that depends only on
+// the IDs of graph's nodes, so rewriting is deterministic.
+func (rule *RewriteRule) Match(graph Graph) map[int]Node {
+	vf2 := NewVF2(sortedGraph{graph}, rule.Pattern, rule.Mode, rule.NodeMatch, rule.EdgeMatch)
+	for vf2.Next() {
+		if match := vf2.Mapping(); rule.Condition == nil || rule.Condition(graph, match) {
+			return match
+		}
+	}
+	return nil
+}
+
+// Returns every match of the rule's pattern in graph that meets its Condition. Matches that overlap are all included, as are the several ways a symmetric pattern can match the
+// same nodes.
+func (rule *RewriteRule) Matches(graph Graph) []map[int]Node {
+	var matches []map[int]Node
+	vf2 := NewVF2(sortedGraph{graph}, rule.Pattern, rule.Mode, rule.NodeMatch, rule.EdgeMatch)
+	for vf2.Next() {
+		if match := vf2.Mapping(); rule.Condition == nil || rule.Condition(graph, match) {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// Rewrites graph at match, which must be a match of the rule's pattern such as Match returns, and returns the image of the replacement: the node each of its IDs now
+// corresponds to in graph, new nodes being created by NewNode. Calls Applied, if the rule has it.
+func (rule *RewriteRule) Apply(graph MutableGraph, match map[int]Node) (image map[int]Node) {
+	inReplacement := make(map[int]bool)
+	replacementNodes := rule.Replacement.NodeList()
+	sort.Sort(byID(replacementNodes))
+	for _, node := range replacementNodes {
+		inReplacement[node.ID()] = true
+	}
+	patternEdges, replacementEdges := ruleEdges(rule.Pattern), ruleEdges(rule.Replacement)
+
+	for _, edge := range sortedEdgeKeys(patternEdges) {
+		if !replacementEdges[edge] {
+			graph.RemoveEdge(GonumEdge{H: match[edge[0]], T: match[edge[1]]})
+		}
+	}
+	patternNodes := rule.Pattern.NodeList()
+	sort.Sort(byID(patternNodes))
+	for _, node := range patternNodes {
+		if !inReplacement[node.ID()] {
+			graph.RemoveNode(match[node.ID()])
+		}
+	}
+
+	image = make(map[int]Node, len(replacementNodes))
+	for _, node := range replacementNodes {
+		if kept, ok := match[node.ID()]; ok {
+			image[node.ID()] = kept
+		} else {
+			image[node.ID()] = graph.NewNode(nil)
+		}
+	}
+	cgraph, isCoster := rule.Replacement.(Coster)
+	for _, edge := range sortedEdgeKeys(replacementEdges) {
+		if patternEdges[edge] {
+			continue
+		}
+		e := GonumEdge{H: image[edge[0]], T: image[edge[1]]}
+		graph.AddEdge(e)
+		if isCoster {
+			graph.SetEdgeCost(e, cgraph.Cost(GonumNode(edge[0]), GonumNode(edge[1])))
+		}
+	}
+
+	if rule.Applied != nil {
+		rule.Applied(match, image)
+	}
+	return image
+}
+
+// How Rewrite chooses which rule to apply next.
+type RewriteStrategy int
+
+const (
+	// Every step applies the first rule that matches anywhere, so a rule is only applied where none before it can be, until none can. Suits a set of simplifications
+	// where some must take precedence.
+	RewriteByPriority RewriteStrategy = iota
+	// Each rule is applied until it no longer matches, then the next, once through the list, as a compiler runs its passes. A rule isn't revisited even if a later one
+	// makes it match again.
+	RewriteInSequence
+)
+
+// Rewrites graph with rules, by strategy, until no more can be applied, and returns how many rewrites were made. If maxSteps is positive and that many rewrites are made with
+// more still to go, stops and returns ErrRewriteLimit, since there's no telling in general whether a set of rules ever runs out of matches: a rule whose replacement contains its
+// own pattern never does.
+func Rewrite(graph MutableGraph, rules []*RewriteRule, strategy RewriteStrategy, maxSteps int) (steps int, err error) {
+	// Returns whether the rule was applied
+	step := func(rule *RewriteRule) (bool, error) {
+		match := rule.Match(graph)
+		if match == nil {
+			return false, nil
+		}
+		if maxSteps > 0 && steps == maxSteps {
+			return false, ErrRewriteLimit
+		}
+		rule.Apply(graph, match)
+		steps++
+		return true, nil
+	}
+
+	switch strategy {
+	case RewriteInSequence:
+		for _, rule := range rules {
+			for {
+				applied, err := step(rule)
+				if err != nil {
+					return steps, err
+				}
+				if !applied {
+					break
+				}
+			}
+		}
+	default:
+		for applied := true; applied; {
+			applied = false
+			for _, rule := range rules {
+				if applied, err = step(rule); err != nil {
+					return steps, err
+				}
+				if applied {
+					break
+				}
+			}
+		}
+	}
+
+	return steps, nil
+}
+
+// The edges of a rule's graph by {head ID, tail ID}, each undirected edge once, from its lower ID
+func ruleEdges(graph Graph) map[[2]int]bool {
+	edges := make(map[[2]int]bool)
+	for _, edge := range graph.EdgeList() {
+		h, t := edge.Head().ID(), edge.Tail().ID()
+		if !graph.IsDirected() && h > t {
+			h, t = t, h
+		}
+		edges[[2]int{h, t}] = true
+	}
+	return edges
+}
+
+func sortedEdgeKeys(edges map[[2]int]bool) [][2]int {
+	keys := make([][2]int, 0, len(edges))
+	for edge := range edges {
+		keys = append(keys, edge)
+	}
+	sort.Sort(byPair(keys))
+	return keys
+}
+
+// A view of a graph with its node lists sorted by ID, so that searches over it go in a predictable order
+type sortedGraph struct {
+	Graph
+}
+
+func (g sortedGraph) NodeList() []Node {
+	nodes := g.Graph.NodeList()
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+func (g sortedGraph) Successors(node Node) []Node {
+	nodes := append([]Node(nil), g.Graph.Successors(node)...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
+
+func (g sortedGraph) Predecessors(node Node) []Node {
+	nodes := append([]Node(nil), g.Graph.Predecessors(node)...)
+	sort.Sort(byID(nodes))
+	return nodes
+}
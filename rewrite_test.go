@@ -0,0 +1,89 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A directed graph of the given nodes and edges
+func ruleGraph(nodes []int, edges [][2]int) *graph.GonumGraph {
+	g := graph.NewGonumGraph(true)
+	for _, id := range nodes {
+		g.AddNode(graph.GonumNode(id), nil)
+	}
+	for _, e := range edges {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+	return g
+}
+
+func TestRewrite(t *testing.T) {
+	// Collapses a -> b -> c to a -> c wherever b has no other edges, as when removing copies from a dataflow graph
+	collapse := &graph.RewriteRule{
+		Pattern:     ruleGraph([]int{0, 1, 2}, [][2]int{{0, 1}, {1, 2}}),
+		Replacement: ruleGraph([]int{0, 2}, [][2]int{{0, 2}}),
+		Mode:        graph.SubgraphMonomorphism,
+		Condition: func(g graph.Graph, match map[int]graph.Node) bool {
+			return len(g.Successors(match[1])) == 1 && len(g.Predecessors(match[1])) == 1
+		},
+	}
+	g := ruleGraph([]int{0, 1, 2, 3, 4, 5, 6}, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {6, 3}, {4, 5}})
+	steps, err := graph.Rewrite(g, []*graph.RewriteRule{collapse}, graph.RewriteByPriority, 0)
+	if err != nil || steps != 3 {
+		t.Errorf("Expected 3 collapses, got %d and error %v", steps, err)
+	}
+	expected := ruleGraph([]int{0, 3, 5, 6}, [][2]int{{0, 3}, {6, 3}, {3, 5}})
+	if !graph.Isomorphic(g, expected, func(a, b graph.Node) bool { return a.ID() == b.ID() }, nil) {
+		t.Errorf("Expected %v, got %v", expected.EdgeList(), g.EdgeList())
+	}
+
+	// Subdivides an edge with a new node, which makes a new match every time
+	var created []int
+	subdivide := &graph.RewriteRule{
+		Pattern:     ruleGraph([]int{0, 1}, [][2]int{{0, 1}}),
+		Replacement: ruleGraph([]int{0, 1, 2}, [][2]int{{0, 2}, {2, 1}}),
+		Mode:        graph.SubgraphMonomorphism,
+		Applied: func(match, image map[int]graph.Node) {
+			created = append(created, image[2].ID())
+		},
+	}
+	g = ruleGraph([]int{0, 1}, [][2]int{{0, 1}})
+	steps, err = graph.Rewrite(g, []*graph.RewriteRule{subdivide}, graph.RewriteByPriority, 5)
+	if err != graph.ErrRewriteLimit || steps != 5 {
+		t.Errorf("Expected to stop at the limit of 5 steps, got %d and error %v", steps, err)
+	}
+	if len(g.NodeList()) != 7 || len(g.EdgeList()) != 6 || len(created) != 5 {
+		t.Errorf("Expected 7 nodes and 6 edges, got %v, having created %v", g.EdgeList(), created)
+	}
+
+	// Deletes a node with a self loop, and gives a sink a self loop. By priority these eat a DAG from the sinks back; in sequence, the deleting goes first, so sinks are only
+	// looped
+	deleteLooped := &graph.RewriteRule{
+		Pattern:     ruleGraph([]int{0}, [][2]int{{0, 0}}),
+		Replacement: ruleGraph(nil, nil),
+		Mode:        graph.SubgraphMonomorphism,
+	}
+	loopSink := &graph.RewriteRule{
+		Pattern:     ruleGraph([]int{0}, nil),
+		Replacement: ruleGraph([]int{0}, [][2]int{{0, 0}}),
+		Mode:        graph.SubgraphMonomorphism,
+		Condition: func(g graph.Graph, match map[int]graph.Node) bool {
+			return len(g.Successors(match[0])) == 0
+		},
+	}
+	rules := []*graph.RewriteRule{deleteLooped, loopSink}
+	g = ruleGraph([]int{0, 1, 2}, [][2]int{{0, 1}, {0, 2}})
+	if steps, err := graph.Rewrite(g, rules, graph.RewriteByPriority, 100); err != nil || steps != 6 || len(g.NodeList()) != 0 {
+		t.Errorf("Expected 6 steps to empty the graph, got %d and error %v, leaving %v", steps, err, g.NodeList())
+	}
+	g = ruleGraph([]int{0, 1, 2}, [][2]int{{0, 1}, {0, 2}})
+	if steps, err := graph.Rewrite(g, rules, graph.RewriteInSequence, 100); err != nil || steps != 2 || len(g.EdgeList()) != 4 {
+		t.Errorf("Expected 2 steps looping the sinks, got %d and error %v, leaving %v", steps, err, g.EdgeList())
+	}
+
+	matches := collapse.Matches(ruleGraph([]int{0, 1, 2, 3}, [][2]int{{0, 1}, {1, 2}, {2, 3}}))
+	if len(matches) != 2 || matches[0][1].ID() != 1 || matches[1][1].ID() != 2 {
+		t.Errorf("Expected matches around 1 and 2, got %v", matches)
+	}
+}
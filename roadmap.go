@@ -0,0 +1,299 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// A continuous space to plan motions through, e.g. the positions of a robot, or the joint angles of an arm, given as vectors of coordinates. The planners know nothing about
+// the space beyond what these functions tell them.
+type ConfigurationSpace struct {
+	// Draws a configuration uniformly at random from the space, which may or may not be free. Required.
+	Sample func(src *rand.Rand) []float64
+	// The collision checker: whether a configuration is free of obstacles. Required.
+	Free func(q []float64) bool
+	// The distance between two configurations, which is also the cost of moving between them. If nil, it's the Euclidean distance.
+	Distance func(a, b []float64) float64
+	// A straight move between two configurations is checked for collisions at points at most this far apart, so it should be smaller than the thinnest obstacle. If 0 or less,
+	// only its ends are checked.
+	Resolution float64
+}
+
+func (space *ConfigurationSpace) distance(a, b []float64) float64 {
+	if space.Distance != nil {
+		return space.Distance(a, b)
+	}
+
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return math.Sqrt(sum)
+}
+
+// Whether the straight move from a to b is collision free, checking points along it Resolution apart
+func (space *ConfigurationSpace) motionFree(a, b []float64) bool {
+	steps := 1
+	if space.Resolution > 0 {
+		steps = int(math.Ceil(space.distance(a, b) / space.Resolution))
+	}
+	if steps == 0 {
+		steps = 1
+	}
+
+	q := make([]float64, len(a))
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		for i := range q {
+			q[i] = a[i] + t*(b[i]-a[i])
+		}
+		if !space.Free(q) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A roadmap over a ConfigurationSpace: its nodes are free configurations, and its edges are collision free straight moves between them, costing their distance. It's built by
+// PRM or RRT, and implements Graph, Coster and HeuristicCoster, so it can be searched like any other graph; Query does that between two configurations.
+type Roadmap struct {
+	Space *ConfigurationSpace
+	// How far apart configurations Query connects to the roadmap can be.
+	Radius  float64
+	configs [][]float64
+	adj     []map[int]float64
+}
+
+// Returned by Query when the start or goal isn't free, or the roadmap doesn't connect them.
+var ErrNoRoadmapPath = errors.New("No path through the roadmap")
+
+// Builds a probabilistic roadmap[1]: samples free configurations until it has n of them (giving up after 100n samples), then joins each pair of configurations within radius of
+// each other whose straight move is collision free. The roadmap can then answer any number of queries. It takes O(n^2) distance computations; radius should be large enough
+// for the samples to connect through every passage of the free space, which narrow passages make harder.
+//
+// [1] L. E. Kavraki, P. Švestka, J.-C. Latombe and M. H. Overmars, "Probabilistic roadmaps for path planning in high-dimensional configuration spaces", IEEE Transactions on
+// Robotics and Automation 12(4) (1996)
+func PRM(space *ConfigurationSpace, n int, radius float64, src *rand.Rand) *Roadmap {
+	src = randSource(src)
+	roadmap := &Roadmap{Space: space, Radius: radius}
+	for tries := 0; len(roadmap.configs) < n && tries < 100*n; tries++ {
+		if q := space.Sample(src); space.Free(q) {
+			roadmap.AddConfiguration(q)
+		}
+	}
+
+	return roadmap
+}
+
+// Parameters for RRT.
+type RRTParams struct {
+	// The most samples to draw before giving up on reaching the goal.
+	Iterations int
+	// The furthest the tree grows towards a sample at once.
+	Step float64
+	// The probability of drawing the goal itself as the sample, pulling the tree towards it.
+	GoalBias float64
+	// How close to the goal the tree must get for it to be joined on directly.
+	GoalTolerance float64
+}
+
+// Grows a rapidly-exploring random tree[1] from start until it reaches goal: each iteration draws a sample (the goal itself with probability GoalBias) and extends the nearest
+// configuration in the tree up to Step towards it, if that move is collision free. Once a new configuration is within GoalTolerance of goal and can move straight to it, goal
+// joins the tree and the path through the tree is returned, as configurations from start to goal; if that doesn't happen within Iterations samples, path is nil.
+//
+// Unlike PRM this is aimed at a single query, and the path isn't the shortest, though the tree is returned as a Roadmap (connecting within Step) and can be searched or refined.
+//
+// [1] S. M. LaValle, "Rapidly-exploring random trees: a new tool for path planning", TR 98-11, Computer Science Dept., Iowa State University (1998)
+func RRT(space *ConfigurationSpace, start, goal []float64, params RRTParams, src *rand.Rand) (roadmap *Roadmap, path [][]float64) {
+	src = randSource(src)
+	roadmap = &Roadmap{Space: space, Radius: params.Step}
+	if !space.Free(start) || !space.Free(goal) {
+		return roadmap, nil
+	}
+	roadmap.configs = [][]float64{start}
+	roadmap.adj = []map[int]float64{{}}
+
+	parent := []int{-1}
+	for i := 0; i < params.Iterations; i++ {
+		sample := goal
+		if src.Float64() >= params.GoalBias {
+			sample = space.Sample(src)
+		}
+
+		near := roadmap.nearest(sample)
+		q := sample
+		if d := space.distance(roadmap.configs[near], sample); d > params.Step {
+			q = make([]float64, len(sample))
+			for j := range q {
+				q[j] = roadmap.configs[near][j] + (sample[j]-roadmap.configs[near][j])*params.Step/d
+			}
+		}
+		if !space.motionFree(roadmap.configs[near], q) {
+			continue
+		}
+
+		added := roadmap.join(q, near)
+		parent = append(parent, near)
+		if space.distance(q, goal) <= params.GoalTolerance && space.motionFree(q, goal) {
+			if space.distance(q, goal) > 0 {
+				roadmap.join(goal, added)
+				parent = append(parent, added)
+			}
+
+			for n := len(roadmap.configs) - 1; n != -1; n = parent[n] {
+				path = append(path, roadmap.configs[n])
+			}
+			for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+				path[l], path[r] = path[r], path[l]
+			}
+			return roadmap, path
+		}
+	}
+
+	return roadmap, nil
+}
+
+// The configuration in the roadmap nearest to q
+func (roadmap *Roadmap) nearest(q []float64) int {
+	best, bestDist := -1, math.Inf(1)
+	for i, c := range roadmap.configs {
+		if d := roadmap.Space.distance(c, q); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+
+	return best
+}
+
+// Adds q to the roadmap with a single edge to configuration to, returning its index
+func (roadmap *Roadmap) join(q []float64, to int) int {
+	id := len(roadmap.configs)
+	d := roadmap.Space.distance(q, roadmap.configs[to])
+	roadmap.configs = append(roadmap.configs, q)
+	roadmap.adj = append(roadmap.adj, map[int]float64{to: d})
+	roadmap.adj[to][id] = d
+
+	return id
+}
+
+// Adds configuration q to the roadmap, joined to every configuration within Radius that it can move straight to, and returns its node. q should be free.
+func (roadmap *Roadmap) AddConfiguration(q []float64) Node {
+	id := len(roadmap.configs)
+	roadmap.configs = append(roadmap.configs, q)
+	roadmap.adj = append(roadmap.adj, make(map[int]float64))
+
+	for i, c := range roadmap.configs[:id] {
+		if d := roadmap.Space.distance(q, c); d <= roadmap.Radius && roadmap.Space.motionFree(q, c) {
+			roadmap.adj[id][i] = d
+			roadmap.adj[i][id] = d
+		}
+	}
+
+	return GonumNode(id)
+}
+
+// The configuration at a node of the roadmap.
+func (roadmap *Roadmap) Configuration(node Node) []float64 {
+	return roadmap.configs[node.ID()]
+}
+
+// Finds the shortest path through the roadmap from start to goal with AStar, after adding them to it with AddConfiguration (they stay, so later queries can use them), and
+// returns it as configurations along with its cost. Returns ErrNoRoadmapPath if start or goal isn't free or the roadmap doesn't connect them.
+func (roadmap *Roadmap) Query(start, goal []float64) (path [][]float64, cost float64, err error) {
+	if !roadmap.Space.Free(start) || !roadmap.Space.Free(goal) {
+		return nil, 0, ErrNoRoadmapPath
+	}
+
+	from, to := roadmap.AddConfiguration(start), roadmap.AddConfiguration(goal)
+	nodes, cost, _ := AStar(from, to, roadmap, nil, nil)
+	if nodes == nil {
+		return nil, 0, ErrNoRoadmapPath
+	}
+
+	path = make([][]float64, len(nodes))
+	for i, node := range nodes {
+		path[i] = roadmap.configs[node.ID()]
+	}
+	return path, cost, nil
+}
+
+func (roadmap *Roadmap) valid(node Node) bool {
+	return node.ID() >= 0 && node.ID() < len(roadmap.configs)
+}
+
+func (roadmap *Roadmap) Successors(node Node) []Node {
+	if !roadmap.valid(node) {
+		return nil
+	}
+
+	succs := make([]Node, 0, len(roadmap.adj[node.ID()]))
+	for id := range roadmap.adj[node.ID()] {
+		succs = append(succs, GonumNode(id))
+	}
+	return succs
+}
+
+func (roadmap *Roadmap) IsSuccessor(node, successor Node) bool {
+	if !roadmap.valid(node) {
+		return false
+	}
+	_, ok := roadmap.adj[node.ID()][successor.ID()]
+	return ok
+}
+
+func (roadmap *Roadmap) Predecessors(node Node) []Node {
+	return roadmap.Successors(node)
+}
+
+func (roadmap *Roadmap) IsPredecessor(node, pred Node) bool {
+	return roadmap.IsSuccessor(node, pred)
+}
+
+func (roadmap *Roadmap) IsAdjacent(node, neighbor Node) bool {
+	return roadmap.IsSuccessor(node, neighbor)
+}
+
+func (roadmap *Roadmap) NodeExists(node Node) bool {
+	return roadmap.valid(node)
+}
+
+func (roadmap *Roadmap) Degree(node Node) int {
+	return len(roadmap.Successors(node)) * 2
+}
+
+func (roadmap *Roadmap) EdgeList() []Edge {
+	edges := make([]Edge, 0)
+	for i := range roadmap.configs {
+		for _, succ := range roadmap.Successors(GonumNode(i)) {
+			edges = append(edges, GonumEdge{GonumNode(i), succ})
+		}
+	}
+
+	return edges
+}
+
+func (roadmap *Roadmap) NodeList() []Node {
+	nodes := make([]Node, len(roadmap.configs))
+	for i := range nodes {
+		nodes[i] = GonumNode(i)
+	}
+
+	return nodes
+}
+
+func (roadmap *Roadmap) IsDirected() bool {
+	return false
+}
+
+func (roadmap *Roadmap) Cost(node1, node2 Node) float64 {
+	if d, ok := roadmap.adj[node1.ID()][node2.ID()]; ok {
+		return d
+	}
+	return roadmap.HeuristicCost(node1, node2)
+}
+
+func (roadmap *Roadmap) HeuristicCost(node1, node2 Node) float64 {
+	return roadmap.Space.distance(roadmap.configs[node1.ID()], roadmap.configs[node2.ID()])
+}
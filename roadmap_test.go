@@ -0,0 +1,89 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A 10x10 room with a wall up the middle, from the floor to y = 7, so getting from one side to the other means going over it
+func wallSpace() *graph.ConfigurationSpace {
+	return &graph.ConfigurationSpace{
+		Sample: func(src *rand.Rand) []float64 {
+			return []float64{10 * src.Float64(), 10 * src.Float64()}
+		},
+		Free: func(q []float64) bool {
+			return q[0] >= 0 && q[0] <= 10 && q[1] >= 0 && q[1] <= 10 && !(q[0] >= 4 && q[0] <= 6 && q[1] <= 7)
+		},
+		Resolution: 0.05,
+	}
+}
+
+func checkMotions(t *testing.T, space *graph.ConfigurationSpace, path [][]float64, start, goal []float64) {
+	if math.Hypot(path[0][0]-start[0], path[0][1]-start[1]) != 0 || math.Hypot(path[len(path)-1][0]-goal[0], path[len(path)-1][1]-goal[1]) != 0 {
+		t.Errorf("Path doesn't run from %v to %v: %v", start, goal, path)
+	}
+	for i := 1; i < len(path); i++ {
+		for s := 0.0; s <= 1; s += 0.01 {
+			q := []float64{path[i-1][0] + s*(path[i][0]-path[i-1][0]), path[i-1][1] + s*(path[i][1]-path[i-1][1])}
+			if !space.Free(q) {
+				t.Fatalf("Move from %v to %v hits the wall at %v", path[i-1], path[i], q)
+			}
+		}
+	}
+}
+
+func TestPRM(t *testing.T) {
+	space := wallSpace()
+	roadmap := graph.PRM(space, 300, 2, rand.New(rand.NewSource(1)))
+	if n := len(roadmap.NodeList()); n != 300 {
+		t.Fatalf("Expected 300 configurations, got %d", n)
+	}
+	for _, edge := range roadmap.EdgeList() {
+		a, b := roadmap.Configuration(edge.Head()), roadmap.Configuration(edge.Tail())
+		if d := math.Hypot(a[0]-b[0], a[1]-b[1]); d > 2 || math.Abs(roadmap.Cost(edge.Head(), edge.Tail())-d) > 1e-9 {
+			t.Fatalf("Edge from %v to %v is too long or misweighted", a, b)
+		}
+	}
+
+	start, goal := []float64{1, 1}, []float64{9, 1}
+	path, cost, err := roadmap.Query(start, goal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMotions(t, space, path, start, goal)
+	if shortest := 2*math.Hypot(3, 6) + 2; cost < shortest || cost > 1.5*shortest {
+		t.Errorf("Expected a path over the wall costing at least %v (and not much more), got %v", shortest, cost)
+	}
+
+	if _, _, err := roadmap.Query(start, []float64{5, 5}); err != graph.ErrNoRoadmapPath {
+		t.Errorf("Expected ErrNoRoadmapPath for a goal inside the wall, got %v", err)
+	}
+}
+
+func TestRRT(t *testing.T) {
+	space := wallSpace()
+	start, goal := []float64{1, 1}, []float64{9, 1}
+	params := graph.RRTParams{Iterations: 5000, Step: 0.5, GoalBias: 0.1, GoalTolerance: 0.5}
+	roadmap, path := graph.RRT(space, start, goal, params, rand.New(rand.NewSource(1)))
+	if path == nil {
+		t.Fatal("RRT didn't reach the goal")
+	}
+	checkMotions(t, space, path, start, goal)
+	for i := 1; i < len(path); i++ {
+		if d := math.Hypot(path[i][0]-path[i-1][0], path[i][1]-path[i-1][1]); d > params.Step+1e-9 {
+			t.Errorf("Step from %v to %v is longer than %v", path[i-1], path[i], params.Step)
+		}
+	}
+
+	// It's a tree
+	if nodes, edges := len(roadmap.NodeList()), len(roadmap.EdgeList()); edges != 2*(nodes-1) {
+		t.Errorf("Expected a tree, got %d nodes and %d edges", nodes, edges/2)
+	}
+
+	if _, path := graph.RRT(space, start, []float64{5, 5}, params, nil); path != nil {
+		t.Errorf("Found a path to a goal inside the wall: %v", path)
+	}
+}
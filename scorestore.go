@@ -0,0 +1,197 @@
+package graph
+
+import (
+	"math"
+)
+
+// A ScoreStore holds a float64 score per node ID, for planners that keep scores across calls (g-scores, rhs values and so on). A score that was never set is +Inf, which is what
+// "not reached yet" means to every planner in this package.
+//
+// Get may be called from several goroutines at once (see WithDStarParallelism), as long as nothing calls Set at the same time.
+type ScoreStore interface {
+	Get(id int) float64
+	Set(id int, score float64)
+	// Forgets every score, keeping any allocated capacity for reuse
+	Clear()
+	// Calls fn with every finite score, in no particular order
+	Range(fn func(id int, score float64))
+}
+
+// The default ScoreStore, suitable for any graph.
+type MapScoreStore map[int]float64
+
+func (store MapScoreStore) Get(id int) float64 {
+	if score, ok := store[id]; ok {
+		return score
+	}
+
+	return math.Inf(1)
+}
+
+func (store MapScoreStore) Set(id int, score float64) {
+	store[id] = score
+}
+
+func (store MapScoreStore) Clear() {
+	for id := range store {
+		delete(store, id)
+	}
+}
+
+func (store MapScoreStore) Range(fn func(id int, score float64)) {
+	for id, score := range store {
+		if !math.IsInf(score, 1) {
+			fn(id, score)
+		}
+	}
+}
+
+// A ScoreStore backed by a flat slice indexed by ID, for graphs whose IDs are dense, non-negative integers (like TileGraph's, or GonumGraph's when nodes are numbered from 0).
+// Lookups are plain indexing instead of hashing, and there's nothing for the garbage collector to scan, which adds up on graphs with millions of nodes. The slice grows as needed,
+// but memory use is proportional to the largest ID, so sparse IDs are better off in a MapScoreStore. Setting a negative ID panics.
+type DenseScoreStore struct {
+	scores []float64
+}
+
+// Creates a DenseScoreStore with room for IDs 0 through n-1.
+func NewDenseScoreStore(n int) *DenseScoreStore {
+	store := &DenseScoreStore{scores: make([]float64, n)}
+	store.Clear()
+
+	return store
+}
+
+func (store *DenseScoreStore) Get(id int) float64 {
+	if id < 0 || id >= len(store.scores) {
+		return math.Inf(1)
+	}
+
+	return store.scores[id]
+}
+
+func (store *DenseScoreStore) Set(id int, score float64) {
+	for id >= len(store.scores) {
+		store.scores = append(store.scores, math.Inf(1))
+	}
+	store.scores[id] = score
+}
+
+func (store *DenseScoreStore) Clear() {
+	for i := range store.scores {
+		store.scores[i] = math.Inf(1)
+	}
+}
+
+func (store *DenseScoreStore) Range(fn func(id int, score float64)) {
+	for id, score := range store.scores {
+		if !math.IsInf(score, 1) {
+			fn(id, score)
+		}
+	}
+}
+
+// The ScoreStore D*-Lite picks for itself on a graph whose IDs are dense (see denseIDBound): a slice for the IDs the graph had when planning started, and a map for any
+// outside them, such as a node added since with an ID far past the rest, or a negative one, so that it's as safe as a MapScoreStore whatever the graph turns into.
+type boundedScoreStore struct {
+	dense  []float64
+	sparse MapScoreStore
+}
+
+func newBoundedScoreStore(n int) *boundedScoreStore {
+	store := &boundedScoreStore{dense: make([]float64, n), sparse: make(MapScoreStore)}
+	store.Clear()
+
+	return store
+}
+
+func (store *boundedScoreStore) Get(id int) float64 {
+	if id >= 0 && id < len(store.dense) {
+		return store.dense[id]
+	}
+
+	return store.sparse.Get(id)
+}
+
+func (store *boundedScoreStore) Set(id int, score float64) {
+	if id >= 0 && id < len(store.dense) {
+		store.dense[id] = score
+		return
+	}
+	store.sparse.Set(id, score)
+}
+
+func (store *boundedScoreStore) Clear() {
+	for i := range store.dense {
+		store.dense[i] = math.Inf(1)
+	}
+	store.sparse.Clear()
+}
+
+func (store *boundedScoreStore) Range(fn func(id int, score float64)) {
+	for id, score := range store.dense {
+		if !math.IsInf(score, 1) {
+			fn(id, score)
+		}
+	}
+	store.sparse.Range(fn)
+}
+
+// If every node of graph has an ID from 0 to n-1, and there are at least half as many nodes as that, returns n; otherwise, or if graph can't list its nodes, 0. A slice of n
+// scores is then no bigger than a map of the nodes' scores would be, and much quicker.
+func denseIDBound(graph interface{}) int {
+	switch g := graph.(type) {
+	case *TileGraph:
+		return len(g.tiles)
+	case *GridGraph:
+		return g.width * g.height
+	case *ImmutableGraph:
+		if g.index == nil {
+			return len(g.nodes)
+		}
+		return boundIDs(len(g.nodes), func(fn func(id int)) {
+			for id := range g.index {
+				fn(id)
+			}
+		})
+	case *GonumGraph:
+		return g.denseIDBound()
+	case *DirectedGraph:
+		return g.denseIDBound()
+	case *UndirectedGraph:
+		return g.denseIDBound()
+	case Graph:
+		nodes := g.NodeList()
+		return boundIDs(len(nodes), func(fn func(id int)) {
+			for _, node := range nodes {
+				fn(node.ID())
+			}
+		})
+	}
+	return 0
+}
+
+func (graph *GonumGraph) denseIDBound() int {
+	return boundIDs(len(graph.nodeMap), func(fn func(id int)) {
+		for id := range graph.nodeMap {
+			fn(id)
+		}
+	})
+}
+
+// The bound denseIDBound returns for n nodes whose IDs ids ranges over.
+func boundIDs(n int, ids func(fn func(id int))) int {
+	lowest, highest := 0, -1
+	ids(func(id int) {
+		if id < lowest {
+			lowest = id
+		}
+		if id > highest {
+			highest = id
+		}
+	})
+	if n == 0 || lowest < 0 || highest >= 2*n {
+		return 0
+	}
+
+	return highest + 1
+}
@@ -0,0 +1,75 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestScoreStores(t *testing.T) {
+	for name, store := range map[string]graph.ScoreStore{"map": graph.MapScoreStore{}, "dense": graph.NewDenseScoreStore(4)} {
+		if score := store.Get(2); !math.IsInf(score, 1) {
+			t.Errorf("%s: unset score is %v, expected +Inf", name, score)
+		}
+		store.Set(2, 1.5)
+		store.Set(10, 3) // Past the dense store's initial size
+		store.Set(5, math.Inf(1))
+		if store.Get(2) != 1.5 || store.Get(10) != 3 || !math.IsInf(store.Get(7), 1) || !math.IsInf(store.Get(-1), 1) {
+			t.Errorf("%s: got scores %v, %v, %v, %v", name, store.Get(2), store.Get(10), store.Get(7), store.Get(-1))
+		}
+
+		finite := make(map[int]float64)
+		store.Range(func(id int, score float64) {
+			finite[id] = score
+		})
+		if !reflect.DeepEqual(finite, map[int]float64{2: 1.5, 10: 3}) {
+			t.Errorf("%s: Range gave %v", name, finite)
+		}
+
+		store.Clear()
+		store.Range(func(id int, score float64) {
+			t.Errorf("%s: score %v for %d survived Clear", name, score, id)
+		})
+	}
+}
+
+func TestDStarDenseScoreStores(t *testing.T) {
+	truth := graph.RandomObstacleField(30, 30, 0.3, graph.GonumNode(0), graph.GonumNode(899), rand.New(rand.NewSource(2)))
+	start, goal := truth.CoordsToNode(0, 0), truth.CoordsToNode(29, 29)
+
+	sparse := walkDStar(t, graph.NewRevealingTileGraph(truth, start, 2), goal)
+	dense := walkDStar(t, graph.NewRevealingTileGraph(truth, start, 2), goal,
+		graph.WithDStarScoreStores(graph.NewDenseScoreStore(0), graph.NewDenseScoreStore(0)))
+	if len(sparse) != len(dense) {
+		t.Errorf("Walk with dense score stores took %d steps, with maps %d", len(dense), len(sparse))
+	}
+}
+
+func benchmarkDStarScores(b *testing.B, stores func(n int) []graph.DStarOption) {
+	tg := graph.NewTileGraph(300, 300, true)
+	start, goal := tg.CoordsToNode(0, 0), tg.CoordsToNode(299, 299)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.InitDStar(start, goal, tg, nil, nil, stores(300*300)...)
+	}
+}
+
+func BenchmarkDStarMapScores(b *testing.B) {
+	benchmarkDStarScores(b, func(n int) []graph.DStarOption {
+		return []graph.DStarOption{graph.WithDStarScoreStores(graph.MapScoreStore{}, graph.MapScoreStore{})}
+	})
+}
+
+func BenchmarkDStarDefaultScores(b *testing.B) {
+	benchmarkDStarScores(b, func(n int) []graph.DStarOption {
+		return nil
+	})
+}
+
+func BenchmarkDStarDenseScores(b *testing.B) {
+	benchmarkDStarScores(b, func(n int) []graph.DStarOption {
+		return []graph.DStarOption{graph.WithDStarScoreStores(graph.NewDenseScoreStore(n), graph.NewDenseScoreStore(n))}
+	})
+}
@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"time"
+)
+
+// SearchStats counts the work a search has done, for performance tuning. Which fields are meaningful depends on the algorithm; the D*-Lite mapping is documented on WithDStarStats.
+type SearchStats struct {
+	Expansions    int // Nodes popped from the queue and processed (not counting stale entries that were just requeued)
+	Pushes        int // Heap insertions
+	Pops          int // Heap extractions of the minimum
+	Fixes         int // Heap updates of a queued node's priority
+	Removes       int // Heap removals of a node that isn't the minimum
+	VertexUpdates int // Recomputations of a node's tentative score (UpdateVertex in the D*-Lite paper)
+	QueueLen      int // Nodes still queued when the stats were taken
+
+	Searches   int           // Number of (re)planning searches run
+	LastSearch time.Duration // Wall time of the most recent search
+	SearchTime time.Duration // Total wall time of all searches
+}
+
+// Records a search that started at start.
+func (stats *SearchStats) searchDone(start time.Time) {
+	elapsed := time.Since(start)
+	stats.Searches++
+	stats.LastSearch = elapsed
+	stats.SearchTime += elapsed
+}
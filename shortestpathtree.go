@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"math"
+)
+
+// A ShortestPathTree holds the shortest paths from one source to every node it can reach, as Dijkstra's algorithm finds them, so any number of paths and distances can be read
+// off one search: the distances from a goal to every node for a heuristic, say, or the routes from a depot to every customer.
+type ShortestPathTree struct {
+	Source Node
+	Order  []Node // Every node the source reaches, nearest first, so the source itself comes first
+
+	dist map[int]float64
+	pred map[int]Node
+}
+
+// Runs Dijkstra's algorithm from source, finding the shortest path to every node it can reach. Cost is interpreted as in AStar, and mustn't be negative. graph only needs to
+// list successors, so the tree can be grown on an implicit graph, as long as the part reachable from source is finite.
+func DijkstraTree(source Node, graph ImplicitGraph, Cost func(Node, Node) float64) *ShortestPathTree {
+	tree, _ := dijkstraTree(nil, source, graph, Cost)
+	return tree
+}
+
+// Like DijkstraTree, but gives up when ctx is cancelled, returning ctx.Err() along with the tree as far as it had grown: the nodes in its Order, whose paths and distances are
+// already final. Nodes it hadn't got to yet have no path, as though they couldn't be reached.
+func DijkstraTreeCtx(ctx context.Context, source Node, graph ImplicitGraph, Cost func(Node, Node) float64) (*ShortestPathTree, error) {
+	return dijkstraTree(newCanceller(ctx), source, graph, Cost)
+}
+
+func dijkstraTree(cancel *canceller, source Node, graph ImplicitGraph, Cost func(Node, Node) float64) (*ShortestPathTree, error) {
+	visit := successorVisitor(graph, Cost)
+	tree := &ShortestPathTree{
+		Source: source,
+		dist:   map[int]float64{source.ID(): 0},
+		pred:   make(map[int]Node),
+	}
+	settled := make(map[int]bool)
+	openSet := NewPriorityQueue(BinaryHeapQueue, func(a, b HeapItem) bool {
+		return a.Key.(float64) < b.Key.(float64)
+	})
+
+	openSet.Push(source, 0.0)
+	for openSet.Len() != 0 {
+		if err := cancel.err(); err != nil {
+			// Distances to nodes still queued might yet come down, so only the settled ones are kept
+			for id := range tree.dist {
+				if !settled[id] {
+					delete(tree.dist, id)
+					delete(tree.pred, id)
+				}
+			}
+			return tree, err
+		}
+		curr := openSet.Pop()
+		settled[curr.ID()] = true
+		tree.Order = append(tree.Order, curr.Node)
+
+		d := curr.Key.(float64)
+		visit(curr.Node, func(succ Node, cost float64) bool {
+			if settled[succ.ID()] {
+				return true
+			}
+			if old, ok := tree.dist[succ.ID()]; !ok || d+cost < old {
+				tree.dist[succ.ID()] = d + cost
+				tree.pred[succ.ID()] = curr.Node
+				openSet.Push(succ, d+cost) // Decreases the key if it's already queued
+			}
+			return true
+		})
+	}
+
+	return tree, nil
+}
+
+// The cost of the shortest path from the source to node, or +Inf if there's none.
+func (tree *ShortestPathTree) DistTo(node Node) float64 {
+	if d, ok := tree.dist[node.ID()]; ok {
+		return d
+	}
+	return math.Inf(1)
+}
+
+// The shortest path from the source to node, both included, and its cost; nil and +Inf if there's none. The path is the caller's own.
+func (tree *ShortestPathTree) PathTo(node Node) ([]Node, float64) {
+	d, ok := tree.dist[node.ID()]
+	if !ok {
+		return nil, math.Inf(1)
+	}
+	return rebuildPath(tree.pred, node), d
+}
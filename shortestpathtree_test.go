@@ -0,0 +1,50 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestDijkstraTree(t *testing.T) {
+	g := randomWeightedGraph(100, 0.05, 3)
+	g.AddNode(graph.GonumNode(1000), nil)
+	source := graph.GonumNode(0)
+	tree := graph.DijkstraTree(source, g, nil)
+	paths, costs := graph.Dijkstra(source, g, nil)
+
+	if tree.Order[0].ID() != source.ID() {
+		t.Errorf("The tree's order starts at %v, not the source", tree.Order[0])
+	}
+	for i := 1; i < len(tree.Order); i++ {
+		if tree.DistTo(tree.Order[i]) < tree.DistTo(tree.Order[i-1]) {
+			t.Errorf("%v comes after %v in the tree's order, but is nearer the source", tree.Order[i], tree.Order[i-1])
+		}
+	}
+
+	for _, node := range g.NodeList() {
+		_, expect, _ := graph.AStar(source, node, g, nil, nil)
+		path, cost := tree.PathTo(node)
+		if path == nil {
+			if expect != 0 || node.ID() == source.ID() || !math.IsInf(cost, 1) || !math.IsInf(tree.DistTo(node), 1) {
+				t.Errorf("No path to %v costing %v, where A* finds one costing %v", node, cost, expect)
+			}
+			if _, ok := costs[node.ID()]; ok {
+				t.Errorf("Dijkstra has a cost for %v, which can't be reached", node)
+			}
+			continue
+		}
+
+		sum := 0.0
+		for i := 1; i < len(path); i++ {
+			sum += g.Cost(path[i-1], path[i])
+		}
+		if path[0].ID() != source.ID() || path[len(path)-1].ID() != node.ID() || math.Abs(sum-expect) > 1e-9 || cost != tree.DistTo(node) || math.Abs(cost-expect) > 1e-9 {
+			t.Errorf("The path to %v is %v costing %v (%v by its edges), expected a path costing %v", node, nodeIDs(path), cost, sum, expect)
+		}
+		if math.Abs(costs[node.ID()]-expect) > 1e-9 || len(paths[node.ID()]) != len(path) {
+			t.Errorf("Dijkstra's path to %v is %v costing %v, expected the tree's %v costing %v", node, nodeIDs(paths[node.ID()]), costs[node.ID()], nodeIDs(path), expect)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"math"
+)
+
+// Whether a straight line from the center of tile a to the center of tile b crosses only passable tiles. A line passing exactly through a corner needs both tiles beside the
+// corner to be passable, so lines never squeeze diagonally between two walls or graze the corner of one.
+func (graph *TileGraph) LineOfSight(a, b Node) bool {
+	passable := func(col, row int) bool {
+		id := graph.CoordsToID(row, col)
+		return id != -1 && graph.tiles[id]
+	}
+
+	r0, c0 := graph.IDToCoords(a.ID())
+	r1, c1 := graph.IDToCoords(b.ID())
+	dx, dy := c1-c0, r1-r0
+	sx, sy := 1, 1
+	if dx < 0 {
+		sx, dx = -1, -dx
+	}
+	if dy < 0 {
+		sy, dy = -1, -dy
+	}
+
+	// Walks every tile the line touches (a supercover of it), deciding at each step by the sign of err whether the line leaves the tile through its side, top or bottom, or
+	// exactly through the corner
+	x, y := c0, r0
+	err := dx - dy
+	for n := 1 + dx + dy; n > 0; n-- {
+		if !passable(x, y) {
+			return false
+		}
+		switch {
+		case err > 0:
+			x += sx
+			err -= 2 * dy
+		case err < 0:
+			y += sy
+			err += 2 * dx
+		default:
+			if n > 1 && (!passable(x+sx, y) || !passable(x, y+sy)) {
+				return false
+			}
+			x, y = x+sx, y+sy
+			err += 2 * (dx - dy)
+			n--
+		}
+	}
+
+	return true
+}
+
+// Shortens a path over a TileGraph into the waypoints of an any-angle path, by string pulling: from each waypoint, the path is followed as far as there's a straight line of
+// sight (see LineOfSight), and the last node in sight becomes the next waypoint. The result runs from the same start to the same goal and is never longer in straight line
+// distance, but its consecutive waypoints usually aren't neighbors in the graph; it's for agents that move freely between tile centers.
+func (graph *TileGraph) SmoothPath(path []Node) []Node {
+	if len(path) < 3 {
+		return append([]Node(nil), path...)
+	}
+
+	smooth := []Node{path[0]}
+	anchor := path[0]
+	for i := 2; i < len(path); i++ {
+		if !graph.LineOfSight(anchor, path[i]) {
+			anchor = path[i-1]
+			smooth = append(smooth, anchor)
+		}
+	}
+
+	return append(smooth, path[len(path)-1])
+}
+
+// Removes the waypoints in the middle of straight runs of a path: every node that lies exactly on the line between the nodes before and after it, continuing in the same
+// direction. Positions come from position, or NodeCoordinates if it's nil, and nodes without a position are always kept. The first and last nodes are always kept.
+func RemoveCollinear(path []Node, position PositionFunc) []Node {
+	if position == nil {
+		position = NodeCoordinates
+	}
+	if len(path) < 3 {
+		return append([]Node(nil), path...)
+	}
+
+	simple := []Node{path[0]}
+	for i := 1; i+1 < len(path); i++ {
+		ax, ay, ok1 := position(simple[len(simple)-1])
+		bx, by, ok2 := position(path[i])
+		cx, cy, ok3 := position(path[i+1])
+		if ok1 && ok2 && ok3 {
+			ux, uy, vx, vy := bx-ax, by-ay, cx-bx, cy-by
+			if ux*vy-uy*vx == 0 && ux*vx+uy*vy > 0 {
+				continue
+			}
+		}
+		simple = append(simple, path[i])
+	}
+
+	return append(simple, path[len(path)-1])
+}
+
+// Simplifies a path with the Ramer-Douglas-Peucker algorithm[1]: if every node lies within epsilon of the straight line between the first and last, only those two are kept,
+// otherwise the path is split at the node furthest from that line and each half is simplified in turn. The result stays within epsilon of the original path, with fewer, more
+// meaningful waypoints, e.g. for paths through a dense road or navigation graph.
+//
+// Positions come from position, or NodeCoordinates if it's nil. Nodes without a position are always kept, and the path is simplified on either side of them.
+//
+// [1] D. H. Douglas and T. K. Peucker, "Algorithms for the reduction of the number of points required to represent a digitized line or its caricature", Cartographica 10 (1973)
+func SimplifyPath(path []Node, position PositionFunc, epsilon float64) []Node {
+	if position == nil {
+		position = NodeCoordinates
+	}
+
+	var simple []Node
+	start := 0
+	for i := range path {
+		if _, _, ok := position(path[i]); !ok {
+			simple = append(simple, rdp(path[start:i], position, epsilon)...)
+			simple = append(simple, path[i])
+			start = i + 1
+		}
+	}
+
+	return append(simple, rdp(path[start:], position, epsilon)...)
+}
+
+// Ramer-Douglas-Peucker over nodes that all have positions
+func rdp(path []Node, position PositionFunc, epsilon float64) []Node {
+	if len(path) < 3 {
+		return append([]Node(nil), path...)
+	}
+
+	ax, ay, _ := position(path[0])
+	bx, by, _ := position(path[len(path)-1])
+	furthest, worst := 0, -1.0
+	for i := 1; i+1 < len(path); i++ {
+		px, py, _ := position(path[i])
+		if d := segmentDistance(px, py, ax, ay, bx, by); d > worst {
+			furthest, worst = i, d
+		}
+	}
+	if worst <= epsilon {
+		return []Node{path[0], path[len(path)-1]}
+	}
+
+	left := rdp(path[:furthest+1], position, epsilon)
+	return append(left[:len(left)-1], rdp(path[furthest:], position, epsilon)...)
+}
+
+// The distance from p to the segment from a to b
+func segmentDistance(px, py, ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((px-ax)*dx+(py-ay)*dy)/l))
+	}
+
+	return math.Hypot(px-(ax+t*dx), py-(ay+t*dy))
+}
@@ -0,0 +1,104 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math"
+	"testing"
+)
+
+func tileLength(tiles *graph.TileGraph, path []graph.Node) float64 {
+	length := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		x0, y0, _ := tiles.Position(path[i])
+		x1, y1, _ := tiles.Position(path[i+1])
+		length += math.Hypot(x1-x0, y1-y0)
+	}
+	return length
+}
+
+func TestSmoothPath(t *testing.T) {
+	open := graph.NewTileGraph(10, 10, true)
+	path, _, _ := graph.AStar(open.CoordsToNode(0, 0), open.CoordsToNode(9, 9), open, nil, nil)
+	if smooth := open.SmoothPath(path); len(smooth) != 2 {
+		t.Errorf("Smoothing a path across an open grid left %v", smooth)
+	}
+
+	// A wall down column 5, open only at the bottom
+	walled := graph.NewTileGraph(10, 10, true)
+	for row := 0; row < 8; row++ {
+		walled.SetPassability(row, 5, false)
+	}
+	path, _, _ = graph.AStar(walled.CoordsToNode(0, 0), walled.CoordsToNode(0, 9), walled, nil, nil)
+	smooth := walled.SmoothPath(path)
+	if len(smooth) < 3 || smooth[0] != path[0] || smooth[len(smooth)-1] != path[len(path)-1] {
+		t.Fatalf("Smoothing a path around a wall gave %v", smooth)
+	}
+	for i := 0; i+1 < len(smooth); i++ {
+		if !walled.LineOfSight(smooth[i], smooth[i+1]) {
+			t.Errorf("No line of sight between waypoints %v and %v", smooth[i], smooth[i+1])
+		}
+	}
+	if tileLength(walled, smooth) >= tileLength(walled, path) {
+		t.Errorf("The smoothed path is %v long, the original %v", tileLength(walled, smooth), tileLength(walled, path))
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	tiles := graph.NewTileGraph(3, 3, true)
+	if !tiles.LineOfSight(tiles.CoordsToNode(0, 0), tiles.CoordsToNode(2, 2)) {
+		t.Error("The diagonal of an open grid is blocked")
+	}
+	// Touching a wall's corner counts as hitting it, so paths keep clear of corners
+	tiles.SetPassability(0, 1, false)
+	if tiles.LineOfSight(tiles.CoordsToNode(0, 0), tiles.CoordsToNode(1, 1)) {
+		t.Error("A line grazed the corner of a wall")
+	}
+	if tiles.LineOfSight(tiles.CoordsToNode(0, 0), tiles.CoordsToNode(0, 2)) {
+		t.Error("A line passed through a wall")
+	}
+	if !tiles.LineOfSight(tiles.CoordsToNode(2, 0), tiles.CoordsToNode(1, 2)) {
+		t.Error("A line across open tiles is blocked")
+	}
+}
+
+func TestRemoveCollinear(t *testing.T) {
+	// An L: along the x axis, then up
+	layout := graph.Layout{0: {0, 0}, 1: {1, 0}, 2: {2, 0}, 3: {2, 1}, 4: {2, 2}, 5: {2, 3}}
+	var path []graph.Node
+	for i := 0; i < 6; i++ {
+		path = append(path, graph.GonumNode(i))
+	}
+	got := graph.RemoveCollinear(path, layout.Position)
+	if len(got) != 3 || got[0].ID() != 0 || got[1].ID() != 2 || got[2].ID() != 5 {
+		t.Errorf("Got %v, want the ends and the corner", got)
+	}
+
+	// Doubling back isn't a straight run
+	layout[2] = graph.Position{0.5, 0}
+	if got := graph.RemoveCollinear(path[:3], layout.Position); len(got) != 3 {
+		t.Errorf("Removed the turning point of a path that doubles back: %v", got)
+	}
+}
+
+func TestSimplifyPath(t *testing.T) {
+	// A zigzag of amplitude 0.1 along the x axis
+	layout := graph.Layout{}
+	var path []graph.Node
+	for i := 0; i <= 10; i++ {
+		layout[i] = graph.Position{float64(i), 0.1 * float64(i%2)}
+		path = append(path, graph.GonumNode(i))
+	}
+
+	if got := graph.SimplifyPath(path, layout.Position, 0.2); len(got) != 2 {
+		t.Errorf("Simplifying within 0.2 kept %v", got)
+	}
+	if got := graph.SimplifyPath(path, layout.Position, 0.05); len(got) != len(path) {
+		t.Errorf("Simplifying within 0.05 kept %v, want every node", got)
+	}
+
+	// A node without a position splits the path
+	delete(layout, 5)
+	if got := graph.SimplifyPath(path, layout.Position, 0.2); len(got) != 5 || got[2].ID() != 5 {
+		t.Errorf("Simplifying around a node without a position gave %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"sort"
+)
+
+// Fills dst with a greedy t-spanner of the undirected graph underlying graph[1]: a subgraph in which the distance between any two nodes is at most stretch times their distance
+// in graph. Edges are taken from lightest to heaviest, and each is kept only if the spanner so far has no path between its ends within stretch times its weight. With a
+// stretch of 2k-1 the spanner has O(n^(1+1/k)) edges, whatever graph's density, and is about as light as any spanner can be, which makes it a cheap stand in for a dense
+// graph in algorithms that tolerate the stretch. A stretch of 1 keeps just the edges on some shortest path (less any ties); a huge one gives a minimum spanning forest.
+//
+// Every node of graph is added to dst, which is made undirected, with the costs of the kept edges. Stretch below 1 is taken as 1. As with other algorithms that use Cost, the
+// order of precedence is Argument > Interface > UniformCost, and costs must not be negative. Takes O(m) Dijkstra searches, each cut off at stretch times the edge's weight.
+//
+// [1] I. Althöfer, G. Das, D. Dobkin, D. Joseph and J. Soares, "On sparse spanners of weighted graphs", Discrete & Computational Geometry 9 (1993)
+func GreedySpanner(dst MutableGraph, graph Graph, Cost func(Node, Node) float64, stretch float64) {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+	if stretch < 1 {
+		stretch = 1
+	}
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+
+	for _, node := range graph.NodeList() {
+		dst.AddNode(node, nil)
+	}
+
+	edgeList := graph.EdgeList()
+	edgeWeights := make(edgeSorter, 0, len(edgeList))
+	for _, edge := range edgeList {
+		if edge.Head().ID() != edge.Tail().ID() {
+			edgeWeights = append(edgeWeights, WeightedEdge{Edge: edge, Weight: Cost(edge.Head(), edge.Tail())})
+		}
+	}
+	sort.Stable(edgeWeights)
+
+	// The spanner so far, kept here rather than asked of dst, for speed
+	type neighbor struct {
+		node Node
+		cost float64
+	}
+	adj := make(map[int][]neighbor)
+	for _, edge := range edgeWeights {
+		u, v := edge.Head(), edge.Tail()
+		bound := stretch * edge.Weight
+
+		// Dijkstra from u, cut off beyond the bound
+		dist := map[int]float64{u.ID(): 0}
+		settled := make(map[int]bool)
+		openSet := NewDaryHeap(4, func(a, b HeapItem) bool {
+			return a.Key.(float64) < b.Key.(float64)
+		})
+		openSet.Push(u, 0.0)
+		for openSet.Len() != 0 && !settled[v.ID()] {
+			curr := openSet.Pop()
+			settled[curr.ID()] = true
+			for _, next := range adj[curr.ID()] {
+				d := curr.Key.(float64) + next.cost
+				if old, ok := dist[next.node.ID()]; d <= bound && !settled[next.node.ID()] && (!ok || d < old) {
+					dist[next.node.ID()] = d
+					openSet.Push(next.node, d)
+				}
+			}
+		}
+		if settled[v.ID()] {
+			continue
+		}
+
+		adj[u.ID()] = append(adj[u.ID()], neighbor{v, edge.Weight})
+		adj[v.ID()] = append(adj[v.ID()], neighbor{u, edge.Weight})
+		dst.AddEdge(edge.Edge)
+		dst.SetEdgeCost(edge.Edge, edge.Weight)
+	}
+}
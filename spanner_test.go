@@ -0,0 +1,95 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestGreedySpanner(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 10; trial++ {
+		g := graph.NewGonumGraph(false)
+		graph.GnpRandomGraph(g, 30, 0.4, false, src)
+		for _, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, 1+9*src.Float64())
+		}
+
+		for _, stretch := range []float64{1, 1.5, 3} {
+			spanner := graph.NewGonumGraph(false)
+			graph.GreedySpanner(spanner, g, nil, stretch)
+			if len(spanner.NodeList()) != len(g.NodeList()) {
+				t.Errorf("Spanner has %d nodes, expected %d", len(spanner.NodeList()), len(g.NodeList()))
+			}
+			if len(spanner.EdgeList()) > len(g.EdgeList()) {
+				t.Errorf("Spanner has %d edges, more than the graph's %d", len(spanner.EdgeList()), len(g.EdgeList()))
+			}
+			// Stretching every edge by no more than stretch stretches every path by no more
+			for _, edge := range g.EdgeList() {
+				_, cost, _ := graph.UniformCostSearch(edge.Head(), edge.Tail(), spanner, nil)
+				if w := g.Cost(edge.Head(), edge.Tail()); cost > stretch*w+1e-9 {
+					t.Errorf("Stretch %v: edge of weight %v is stretched to %v", stretch, w, cost)
+				}
+			}
+		}
+	}
+}
+
+func TestGreedySpannerForest(t *testing.T) {
+	src := rand.New(rand.NewSource(2))
+	g := graph.NewGonumGraph(false)
+	graph.GnpRandomGraph(g, 40, 0.2, false, src)
+	for _, edge := range g.EdgeList() {
+		g.SetEdgeCost(edge, src.Float64())
+	}
+
+	// With a huge stretch, only edges joining components are kept, just as in Kruskal's algorithm
+	spanner := graph.NewGonumGraph(false)
+	graph.GreedySpanner(spanner, g, nil, 1e9)
+	if !graph.IsForest(spanner) {
+		t.Errorf("Spanner with a huge stretch isn't a forest")
+	}
+
+	edges := g.EdgeList()
+	sort.Sort(byCost{edges, g})
+	component := make(map[int]int)
+	find := func(id int) int {
+		for {
+			parent, ok := component[id]
+			if !ok {
+				return id
+			}
+			id = parent
+		}
+	}
+	forest, weight := 0.0, 0.0
+	for _, edge := range edges {
+		if a, b := find(edge.Head().ID()), find(edge.Tail().ID()); a != b {
+			component[a] = b
+			forest += g.Cost(edge.Head(), edge.Tail())
+		}
+	}
+	// Undirected edges are listed both ways
+	for _, edge := range spanner.EdgeList() {
+		if edge.Head().ID() < edge.Tail().ID() {
+			weight += spanner.Cost(edge.Head(), edge.Tail())
+		}
+	}
+	if math.Abs(weight-forest) > 1e-9 {
+		t.Errorf("Spanner weighs %v, but the minimum spanning forest %v", weight, forest)
+	}
+}
+
+type byCost struct {
+	edges []graph.Edge
+	g     graph.Coster
+}
+
+func (bc byCost) Len() int { return len(bc.edges) }
+func (bc byCost) Less(i, j int) bool {
+	return bc.g.Cost(bc.edges[i].Head(), bc.edges[i].Tail()) < bc.g.Cost(bc.edges[j].Head(), bc.edges[j].Tail())
+}
+func (bc byCost) Swap(i, j int) { bc.edges[i], bc.edges[j] = bc.edges[j], bc.edges[i] }
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/gonum/graph/set"
+)
+
+// The edges of a minimum spanning forest of graph, found by Prim's algorithm, each with its cost. Cost is interpreted as in AStar.
+//
+// A minimum spanning forest has a tree for each connected component of the graph, joining the component's nodes for as little total cost as any tree can; on a connected
+// graph it's a minimum spanning tree. So a graph with n nodes in c components gives n - c edges, and a node with no edges is a component, and a tree, of its own without any.
+// A directed graph's edges are taken as undirected, each edge costing the least of the edges either way between its ends, and kept the way round the graph has it.
+//
+// Trees are grown from each component's lowest ID node in turn, and the edges are in the order they joined their trees.
+func PrimEdges(graph Graph, Cost func(Node, Node) float64) []WeightedEdge {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	inTree := make(map[int]bool, len(nodes))
+	// The cheapest edge found so far joining each node outside the trees to the tree being grown
+	cheapest := make(map[int]WeightedEdge)
+	openSet := NewPriorityQueue(BinaryHeapQueue, func(a, b HeapItem) bool {
+		return a.Key.(float64) < b.Key.(float64)
+	})
+	consider := func(outside Node, edge Edge, weight float64) {
+		if inTree[outside.ID()] {
+			return
+		}
+		if old, ok := cheapest[outside.ID()]; ok && old.Weight <= weight {
+			return
+		}
+		cheapest[outside.ID()] = WeightedEdge{Edge: edge, Weight: weight}
+		openSet.Push(outside, weight) // Decreases the key if it's already queued
+	}
+
+	var forest []WeightedEdge
+	for _, root := range nodes {
+		if inTree[root.ID()] {
+			continue
+		}
+		openSet.Push(root, 0.0)
+		for openSet.Len() != 0 {
+			node := openSet.Pop().Node
+			inTree[node.ID()] = true
+			if edge, ok := cheapest[node.ID()]; ok {
+				forest = append(forest, edge)
+			}
+
+			for _, succ := range graph.Successors(node) {
+				consider(succ, GonumEdge{H: node, T: succ}, Cost(node, succ))
+			}
+			if graph.IsDirected() {
+				for _, pred := range graph.Predecessors(node) {
+					consider(pred, GonumEdge{H: pred, T: node}, Cost(pred, node))
+				}
+			}
+		}
+	}
+
+	return forest
+}
+
+// The edges of a minimum spanning forest of graph, as PrimEdges describes, found by Kruskal's algorithm instead. The edges are in order of cost, and edges of equal cost in the
+// order EdgeList gives them.
+func KruskalEdges(graph Graph, Cost func(Node, Node) float64) []WeightedEdge {
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	edgeList := graph.EdgeList()
+	edgeWeights := make(edgeSorter, 0, len(edgeList))
+	for _, edge := range edgeList {
+		edgeWeights = append(edgeWeights, WeightedEdge{Edge: edge, Weight: Cost(edge.Head(), edge.Tail())})
+	}
+	sort.Stable(edgeWeights)
+
+	ds := set.NewDisjointSet()
+	for _, node := range graph.NodeList() {
+		ds.MakeSet(node.ID())
+	}
+
+	var forest []WeightedEdge
+	for _, edge := range edgeWeights {
+		if s1, s2 := ds.Find(edge.Edge.Head().ID()), ds.Find(edge.Edge.Tail().ID()); s1 != s2 {
+			ds.Union(s1, s2)
+			forest = append(forest, edge)
+		}
+	}
+
+	return forest
+}
+
+// Fills dst with an undirected forest: every node of graph, and the given edges at their weights.
+func fillForest(dst MutableGraph, graph Graph, forest []WeightedEdge) {
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	for _, node := range graph.NodeList() {
+		dst.AddNode(node, nil)
+	}
+	for _, edge := range forest {
+		dst.AddEdge(edge.Edge)
+		dst.SetEdgeCost(edge.Edge, edge.Weight)
+	}
+}
@@ -0,0 +1,89 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func forestWeight(forest []graph.WeightedEdge) float64 {
+	total := 0.0
+	for _, edge := range forest {
+		total += edge.Weight
+	}
+	return total
+}
+
+func TestSpanningForests(t *testing.T) {
+	src := rand.New(rand.NewSource(8))
+	for i := 0; i < 20; i++ {
+		directed := i%2 == 0
+		g := graph.NewGonumGraph(directed)
+		// Sparse enough that most graphs fall apart into a few components, and some nodes have no edges at all
+		graph.GnmRandomGraph(g, 40, 35+src.Intn(20), directed, src)
+		for _, edge := range g.EdgeList() {
+			g.SetEdgeCost(edge, float64(1+src.Intn(10)))
+		}
+		components := len(graph.Tarjan(undirectedCopy(g)))
+
+		prim, kruskal := graph.PrimEdges(g, nil), graph.KruskalEdges(g, nil)
+		for name, forest := range map[string][]graph.WeightedEdge{"Prim": prim, "Kruskal": kruskal} {
+			if len(forest) != 40-components {
+				t.Errorf("%s's forest has %d edges, expected %d for 40 nodes in %d components", name, len(forest), 40-components, components)
+			}
+			joined := graph.NewGonumGraph(false)
+			for _, node := range g.NodeList() {
+				joined.AddNode(node, nil)
+			}
+			for _, edge := range forest {
+				if !g.IsSuccessor(edge.Head(), edge.Tail()) || edge.Weight != g.Cost(edge.Head(), edge.Tail()) {
+					t.Fatalf("%s's forest has the edge %v -> %v costing %v, which isn't in the graph", name, edge.Head(), edge.Tail(), edge.Weight)
+				}
+				joined.AddEdge(edge.Edge)
+			}
+			if got := len(graph.Tarjan(joined)); got != components {
+				t.Errorf("%s's forest has %d components, the graph %d", name, got, components)
+			}
+		}
+		if p, k := forestWeight(prim), forestWeight(kruskal); math.Abs(p-k) > 1e-9 {
+			t.Errorf("Prim's forest weighs %v, Kruskal's %v", p, k)
+		}
+	}
+}
+
+func TestPrimFillsForest(t *testing.T) {
+	g := graph.NewUndirectedGraph(0, 0)
+	for _, e := range [][3]int{{0, 1, 4}, {1, 2, 1}, {0, 2, 2}, {3, 4, 5}} {
+		edge := graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])}
+		g.AddEdge(edge)
+		g.SetEdgeCost(edge, float64(e[2]))
+	}
+	g.AddNode(graph.GonumNode(5), nil)
+
+	for name, mst := range map[string]func(graph.MutableGraph, graph.Graph, func(graph.Node, graph.Node) float64){"Prim": graph.Prim, "Kruskal": graph.Kruskal} {
+		dst := graph.NewGonumGraph(true)
+		mst(dst, g, nil)
+		if dst.IsDirected() || len(dst.NodeList()) != 6 || !dst.NodeExists(graph.GonumNode(5)) {
+			t.Errorf("%s: expected an undirected forest with all 6 nodes, got %d nodes, directed %t", name, len(dst.NodeList()), dst.IsDirected())
+		}
+		if !dst.IsSuccessor(graph.GonumNode(1), graph.GonumNode(2)) || !dst.IsSuccessor(graph.GonumNode(0), graph.GonumNode(2)) || dst.IsSuccessor(graph.GonumNode(0), graph.GonumNode(1)) {
+			t.Errorf("%s: expected the edges 1-2 and 0-2, got %v", name, edgeIDs(dst.EdgeList()))
+		}
+		if dst.Cost(graph.GonumNode(3), graph.GonumNode(4)) != 5 {
+			t.Errorf("%s: expected 3-4 to cost 5, got %v", name, dst.Cost(graph.GonumNode(3), graph.GonumNode(4)))
+		}
+	}
+}
+
+func undirectedCopy(g graph.Graph) *graph.GonumGraph {
+	u := graph.NewGonumGraph(false)
+	for _, node := range g.NodeList() {
+		u.AddNode(node, nil)
+	}
+	for _, edge := range g.EdgeList() {
+		u.AddEdge(edge)
+	}
+	return u
+}
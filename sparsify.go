@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Computes the effective resistance between the ends of every edge of the undirected graph underlying graph, taken as an electrical network in which each edge is a conductor
+// with conductance Weight (interpreted as Cost is in AStar). The effective resistance is the voltage between the ends when a unit current is sent from one to the other: 1/w
+// for a bridge of weight w, and less the more other routes there are. Results are keyed by {smaller ID, larger ID}.
+//
+// Each needs solutions of the Laplacian system Lx = b, found by conjugate gradients. If projections isn't positive the resistances are exact, by one solve per node. Otherwise
+// they're estimated by the Johnson-Lindenstrauss projection of Spielman and Srivastava[1], with one solve per projection: with O(log n / ε²) projections they're all within a
+// factor 1±ε, with high probability. If src is nil a time seeded source is used.
+//
+// [1] D. A. Spielman and N. Srivastava, "Graph sparsification by effective resistances", SIAM Journal on Computing 40 (2011)
+func EffectiveResistances(graph Graph, Weight func(Node, Node) float64, projections int, src *rand.Rand) map[[2]int]float64 {
+	l := newLaplacian(graph, Weight)
+	n := len(l.nodes)
+	resistances := make(map[[2]int]float64)
+
+	if projections <= 0 {
+		// Column u of the pseudoinverse, up to a constant on each component, which cancels out of the differences
+		columns := make([][]float64, n)
+		for u := range columns {
+			b := make([]float64, n)
+			b[u] = 1
+			for _, v := range l.components[l.component[u]] {
+				b[v] -= 1 / float64(len(l.components[l.component[u]]))
+			}
+			columns[u] = l.solve(b)
+		}
+		l.eachEdge(func(u, v int, w float64) {
+			resistances[l.key(u, v)] = columns[u][u] + columns[v][v] - columns[u][v] - columns[v][u]
+		})
+		return resistances
+	}
+
+	src = randSource(src)
+	scale := 1 / math.Sqrt(float64(projections))
+	for i := 0; i < projections; i++ {
+		// A random ±1 combination of the edges' rows of W^½B, projected through the pseudoinverse
+		b := make([]float64, n)
+		l.eachEdge(func(u, v int, w float64) {
+			x := scale * math.Sqrt(w)
+			if src.Intn(2) == 0 {
+				x = -x
+			}
+			b[u] += x
+			b[v] -= x
+		})
+		z := l.solve(b)
+		l.eachEdge(func(u, v int, w float64) {
+			resistances[l.key(u, v)] += (z[u] - z[v]) * (z[u] - z[v])
+		})
+	}
+
+	return resistances
+}
+
+// Fills dst with a spectral sparsifier of the undirected graph underlying graph, by sampling edges by their effective resistances[1]: samples edges are drawn with
+// replacement, each with probability proportional to its weight times its effective resistance, and each draw adds its weight divided by samples times that probability to the
+// edge in dst. In expectation that's the original graph, and with O(n log n / ε²) samples the Laplacian quadratic form xᵀLx of dst is within a factor 1±ε of graph's for every
+// x, so every cut's weight is too, with high probability. Edges that matter to connectivity, such as bridges, are all but certain to be drawn, while those among many
+// parallel routes are thinned out.
+//
+// Weight is interpreted as Cost is in AStar, and dst, which is made undirected and gets every node of graph, holds the new weights as edge costs. The resistances are estimated
+// with projections as in EffectiveResistances. If src is nil a time seeded source is used.
+//
+// [1] D. A. Spielman and N. Srivastava, "Graph sparsification by effective resistances", SIAM Journal on Computing 40 (2011)
+func SpectralSparsify(dst MutableGraph, graph Graph, Weight func(Node, Node) float64, samples, projections int, src *rand.Rand) {
+	src = randSource(src)
+	l := newLaplacian(graph, Weight)
+	resistances := EffectiveResistances(graph, Weight, projections, src)
+
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+	for _, node := range l.nodes {
+		dst.AddNode(node, nil)
+	}
+
+	type edge struct {
+		u, v   int
+		weight float64
+	}
+	var edges []edge
+	var cumulative []float64
+	total := 0.0
+	l.eachEdge(func(u, v int, w float64) {
+		total += w * resistances[l.key(u, v)]
+		edges = append(edges, edge{u, v, w})
+		cumulative = append(cumulative, total)
+	})
+	if total <= 0 {
+		return
+	}
+
+	sampled := make(map[int]float64)
+	for i := 0; i < samples; i++ {
+		k := sort.SearchFloat64s(cumulative, src.Float64()*total)
+		if k == len(edges) {
+			k--
+		}
+		p := edges[k].weight * resistances[l.key(edges[k].u, edges[k].v)] / total
+		sampled[k] += edges[k].weight / (float64(samples) * p)
+	}
+
+	keys := make([]int, 0, len(sampled))
+	for k := range sampled {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		e := GonumEdge{H: l.nodes[edges[k].u], T: l.nodes[edges[k].v]}
+		dst.AddEdge(e)
+		dst.SetEdgeCost(e, sampled[k])
+	}
+}
+
+// The weighted Laplacian of the undirected graph underlying a graph, with the nodes numbered in order of ID
+type laplacian struct {
+	nodes      []Node
+	neighbors  [][]int
+	weights    [][]float64
+	degree     []float64
+	component  []int   // The connected component of each node
+	components [][]int // The nodes of each component
+}
+
+func newLaplacian(graph Graph, Weight func(Node, Node) float64) *laplacian {
+	if Weight == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Weight = cgraph.Cost
+		} else {
+			Weight = UniformCost
+		}
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	index := make(map[int]int, len(nodes))
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+
+	n := len(nodes)
+	l := &laplacian{nodes: nodes, neighbors: make([][]int, n), weights: make([][]float64, n), degree: make([]float64, n), component: make([]int, n)}
+	for i, node := range nodes {
+		for _, edge := range neighborEdges(graph, node, Weight) {
+			// Each edge is added from its lower end, so it weighs the same both ways
+			j, ok := index[otherEnd(edge, node).ID()]
+			if !ok || j < i {
+				continue
+			}
+			l.neighbors[i], l.neighbors[j] = append(l.neighbors[i], j), append(l.neighbors[j], i)
+			l.weights[i], l.weights[j] = append(l.weights[i], edge.Weight), append(l.weights[j], edge.Weight)
+			l.degree[i] += edge.Weight
+			l.degree[j] += edge.Weight
+		}
+	}
+
+	for i := range l.component {
+		l.component[i] = -1
+	}
+	for i := range nodes {
+		if l.component[i] != -1 {
+			continue
+		}
+		c := len(l.components)
+		l.component[i] = c
+		members := []int{i}
+		for k := 0; k < len(members); k++ {
+			for _, j := range l.neighbors[members[k]] {
+				if l.component[j] == -1 {
+					l.component[j] = c
+					members = append(members, j)
+				}
+			}
+		}
+		l.components = append(l.components, members)
+	}
+
+	return l
+}
+
+// Calls fn once for each edge, with u < v
+func (l *laplacian) eachEdge(fn func(u, v int, w float64)) {
+	for u, neighbors := range l.neighbors {
+		for k, v := range neighbors {
+			if u < v {
+				fn(u, v, l.weights[u][k])
+			}
+		}
+	}
+}
+
+func (l *laplacian) key(u, v int) [2]int {
+	return [2]int{l.nodes[u].ID(), l.nodes[v].ID()}
+}
+
+// The Laplacian as a matrix
+func (l *laplacian) dense() [][]float64 {
+	matrix := make([][]float64, len(l.nodes))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(l.nodes))
+		matrix[i][i] = l.degree[i]
+		for k, j := range l.neighbors[i] {
+			matrix[i][j] -= l.weights[i][k]
+		}
+	}
+	return matrix
+}
+
+// Sets y to Lx
+func (l *laplacian) mul(x, y []float64) {
+	for u := range x {
+		y[u] = l.degree[u] * x[u]
+		for k, v := range l.neighbors[u] {
+			y[u] -= l.weights[u][k] * x[v]
+		}
+	}
+}
+
+// Solves Lx = b by conjugate gradients. b must add up to zero on each component, as it does when it's a difference of currents; x is then determined up to a constant on each
+// component, and is the solution of least norm.
+func (l *laplacian) solve(b []float64) []float64 {
+	n := len(b)
+	x := make([]float64, n)
+	r := append([]float64(nil), b...)
+	p := append([]float64(nil), b...)
+	lp := make([]float64, n)
+	dot := func(a, b []float64) (sum float64) {
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+
+	rr := dot(r, r)
+	tolerance := 1e-20 * math.Max(rr, 1e-300)
+	for iter := 0; iter < 10*n+10 && rr > tolerance; iter++ {
+		l.mul(p, lp)
+		pLp := dot(p, lp)
+		if pLp <= 0 {
+			break
+		}
+		alpha := rr / pLp
+		for i := range x {
+			x[i] += alpha * p[i]
+			r[i] -= alpha * lp[i]
+		}
+		next := dot(r, r)
+		for i := range p {
+			p[i] = r[i] + next/rr*p[i]
+		}
+		rr = next
+	}
+
+	return x
+}
@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestEffectiveResistances(t *testing.T) {
+	// Bridges have resistance 1, adjacent nodes of a cycle (n-1)/n, and of a complete graph 2/n
+	tests := []struct {
+		name     string
+		fill     func(graph.MutableGraph)
+		expected float64
+	}{
+		{"path", func(g graph.MutableGraph) { graph.PathGraph(g, 6, false) }, 1},
+		{"cycle", func(g graph.MutableGraph) { graph.CycleGraph(g, 6, false) }, 5.0 / 6},
+		{"complete", func(g graph.MutableGraph) { graph.CompleteGraph(g, 8, false) }, 2.0 / 8},
+	}
+	for _, test := range tests {
+		g := graph.NewGonumGraph(false)
+		test.fill(g)
+
+		exact := graph.EffectiveResistances(g, nil, 0, nil)
+		estimated := graph.EffectiveResistances(g, nil, 400, rand.New(rand.NewSource(1)))
+		if len(exact) == 0 || len(estimated) != len(exact) {
+			t.Errorf("%s: expected a resistance for each edge, got %v and %v", test.name, exact, estimated)
+		}
+		for edge, r := range exact {
+			if math.Abs(r-test.expected) > 1e-6 {
+				t.Errorf("%s: edge %v has resistance %v, expected %v", test.name, edge, r, test.expected)
+			}
+			if e := estimated[edge]; math.Abs(e-r) > 0.3*r {
+				t.Errorf("%s: edge %v has estimated resistance %v, expected about %v", test.name, edge, e, r)
+			}
+		}
+	}
+}
+
+// xᵀLx for the graph's Laplacian, with costs as weights
+func quadraticForm(g *graph.GonumGraph, x map[int]float64) (sum float64) {
+	for _, edge := range g.EdgeList() {
+		if u, v := edge.Head().ID(), edge.Tail().ID(); u < v {
+			sum += g.Cost(edge.Head(), edge.Tail()) * (x[u] - x[v]) * (x[u] - x[v])
+		}
+	}
+	return sum
+}
+
+func TestSpectralSparsify(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	g := graph.NewGonumGraph(false)
+	graph.CompleteGraph(g, 40, false)
+	// A pendant node, hanging by a bridge, which must survive
+	g.AddNode(graph.GonumNode(40), []graph.Node{graph.GonumNode(0)})
+
+	sparse := graph.NewGonumGraph(false)
+	graph.SpectralSparsify(sparse, g, nil, 400, 0, src)
+	if len(sparse.NodeList()) != 41 {
+		t.Errorf("Expected all 41 nodes, got %d", len(sparse.NodeList()))
+	}
+	if edges, all := len(sparse.EdgeList()), len(g.EdgeList()); edges >= all/2 {
+		t.Errorf("Sparsifier has %d of %d edges", edges, all)
+	}
+	if !sparse.IsSuccessor(graph.GonumNode(40), graph.GonumNode(0)) {
+		t.Errorf("Expected the bridge to be kept")
+	} else if w := sparse.Cost(graph.GonumNode(40), graph.GonumNode(0)); w < 0.5 || w > 1.5 {
+		t.Errorf("Expected the bridge to weigh about 1, got %v", w)
+	}
+
+	for trial := 0; trial < 20; trial++ {
+		x := make(map[int]float64)
+		for i := 0; i <= 40; i++ {
+			x[i] = src.NormFloat64()
+		}
+		if q, qs := quadraticForm(g, x), quadraticForm(sparse, x); qs < 0.6*q || qs > 1.4*q {
+			t.Errorf("Quadratic form is %v in the sparsifier, but %v in the graph", qs, q)
+		}
+	}
+}
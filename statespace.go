@@ -0,0 +1,123 @@
+package graph
+
+// A StateSpace describes a search problem (a puzzle, a planning problem) by its states instead of an explicit graph: what can follow a state, which states are goals, and
+// optionally how far a state is from a goal. States can be of any type usable as a map key, or of any type at all if Key is given to map them to one; they're numbered internally
+// as they're discovered, so the searches here run on the graph the space implies without it ever being built up front.
+//
+// A goal is a test rather than a node, so the search looks for any state satisfying IsGoal -- internally every goal state gets a free edge to a single hidden goal node, which is
+// what the underlying AStar and IDAStar actually search for.
+type StateSpace struct {
+	// The states reachable in one move from state, with the cost of each move. Costs must be positive.
+	Successors func(state interface{}) []StateTransition
+	// Whether state is a goal.
+	IsGoal func(state interface{}) bool
+	// An estimate of the cost from state to the nearest goal, which must never overestimate it for the searches to find the cheapest path. If nil, 0 is used, and the searches
+	// degrade to uniform cost search.
+	Heuristic func(state interface{}) float64
+	// Maps a state to a map key identifying it, for states that aren't comparable themselves (slices, for instance) or that are equal by some looser definition. If nil the state is
+	// its own key.
+	Key func(state interface{}) interface{}
+}
+
+// A move from one state to State, costing Cost.
+type StateTransition struct {
+	State interface{}
+	Cost  float64
+}
+
+// Finds the cheapest sequence of states from start to a goal with AStar, returning it (start first, the goal reached last) with its cost and the number of states expanded. The
+// path is nil if no goal is reachable; if the space is infinite and no goal is reachable, AStar doesn't return.
+func (space *StateSpace) AStar(start interface{}) (path []interface{}, cost float64, nodesExpanded int) {
+	sg, startNode := space.graph(start)
+	nodes, cost, nodesExpanded := AStar(startNode, stateGoal, sg, nil, nil)
+	return sg.states(nodes), cost, nodesExpanded
+}
+
+// Finds the cheapest sequence of states from start to a goal with IDAStar, which keeps only the current path in memory (though every state it meets is still numbered). Returns
+// the same as AStar.
+func (space *StateSpace) IDAStar(start interface{}) (path []interface{}, cost float64, nodesExpanded int) {
+	sg, startNode := space.graph(start)
+	nodes, cost, nodesExpanded := IDAStar(startNode, stateGoal, sg, nil, nil)
+	return sg.states(nodes), cost, nodesExpanded
+}
+
+// The hidden node every goal state leads to
+var stateGoal = GonumNode(-1)
+
+// The implicit graph a StateSpace describes, with each state numbered by its index in byID
+type stateGraph struct {
+	space *StateSpace
+	ids   map[interface{}]int
+	byID  []interface{}
+}
+
+func (space *StateSpace) graph(start interface{}) (*stateGraph, Node) {
+	sg := &stateGraph{space: space, ids: make(map[interface{}]int)}
+	return sg, sg.node(start)
+}
+
+// The node for state, numbering it if it hasn't been seen before
+func (sg *stateGraph) node(state interface{}) Node {
+	key := state
+	if sg.space.Key != nil {
+		key = sg.space.Key(state)
+	}
+	if id, ok := sg.ids[key]; ok {
+		return GonumNode(id)
+	}
+
+	id := len(sg.byID)
+	sg.ids[key] = id
+	sg.byID = append(sg.byID, state)
+	return GonumNode(id)
+}
+
+func (sg *stateGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	if node.ID() == stateGoal.ID() {
+		return
+	}
+
+	state := sg.byID[node.ID()]
+	if sg.space.IsGoal(state) && !fn(stateGoal, 0) {
+		return
+	}
+	for _, move := range sg.space.Successors(state) {
+		if !fn(sg.node(move.State), move.Cost) {
+			return
+		}
+	}
+}
+
+func (sg *stateGraph) Successors(node Node) []Node {
+	var succs []Node
+	sg.VisitSuccessors(node, func(succ Node, cost float64) bool {
+		succs = append(succs, succ)
+		return true
+	})
+
+	return succs
+}
+
+func (sg *stateGraph) HeuristicCost(node, goal Node) float64 {
+	if node.ID() == stateGoal.ID() || sg.space.Heuristic == nil {
+		return 0
+	}
+
+	return sg.space.Heuristic(sg.byID[node.ID()])
+}
+
+// The states along a path of nodes, leaving off the hidden goal
+func (sg *stateGraph) states(path []Node) []interface{} {
+	if path == nil {
+		return nil
+	}
+
+	states := make([]interface{}, 0, len(path)-1)
+	for _, node := range path {
+		if node.ID() != stateGoal.ID() {
+			states = append(states, sg.byID[node.ID()])
+		}
+	}
+
+	return states
+}
@@ -0,0 +1,139 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"testing"
+)
+
+// The 8-puzzle, with 0 for the blank
+type puzzle [9]byte
+
+var solvedPuzzle = puzzle{1, 2, 3, 4, 5, 6, 7, 8, 0}
+
+func (p puzzle) moves() []puzzle {
+	var blank int
+	for i, tile := range p {
+		if tile == 0 {
+			blank = i
+		}
+	}
+
+	var moves []puzzle
+	row, col := blank/3, blank%3
+	for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+		if r, c := row+d[0], col+d[1]; r >= 0 && r < 3 && c >= 0 && c < 3 {
+			next := p
+			next[blank], next[r*3+c] = next[r*3+c], 0
+			moves = append(moves, next)
+		}
+	}
+	return moves
+}
+
+func puzzleSpace() *graph.StateSpace {
+	return &graph.StateSpace{
+		Successors: func(state interface{}) []graph.StateTransition {
+			var moves []graph.StateTransition
+			for _, next := range state.(puzzle).moves() {
+				moves = append(moves, graph.StateTransition{next, 1})
+			}
+			return moves
+		},
+		IsGoal: func(state interface{}) bool {
+			return state.(puzzle) == solvedPuzzle
+		},
+		Heuristic: func(state interface{}) float64 {
+			var dist int
+			for i, tile := range state.(puzzle) {
+				if tile != 0 {
+					dist += abs(i/3-int(tile-1)/3) + abs(i%3-int(tile-1)%3)
+				}
+			}
+			return float64(dist)
+		},
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func isPuzzleSolution(path []interface{}, start puzzle) bool {
+	if len(path) == 0 || path[0].(puzzle) != start || path[len(path)-1].(puzzle) != solvedPuzzle {
+		return false
+	}
+	for i := 0; i+1 < len(path); i++ {
+		legal := false
+		for _, next := range path[i].(puzzle).moves() {
+			legal = legal || next == path[i+1].(puzzle)
+		}
+		if !legal {
+			return false
+		}
+	}
+	return true
+}
+
+func TestStateSpacePuzzle(t *testing.T) {
+	space := puzzleSpace()
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		start := solvedPuzzle
+		for j := 0; j < 30; j++ {
+			moves := start.moves()
+			start = moves[rnd.Intn(len(moves))]
+		}
+
+		path, cost, _ := space.AStar(start)
+		if !isPuzzleSolution(path, start) || cost != float64(len(path)-1) || cost > 30 {
+			t.Fatalf("AStar solved %v in %v moves with an invalid path %v", start, cost, path)
+		}
+		idaPath, idaCost, _ := space.IDAStar(start)
+		if !isPuzzleSolution(idaPath, start) || idaCost != cost {
+			t.Errorf("IDAStar solved %v in %v moves, AStar in %v", start, idaCost, cost)
+		}
+	}
+
+	if path, cost, _ := space.AStar(solvedPuzzle); len(path) != 1 || cost != 0 {
+		t.Errorf("Searching from a goal returned %v with cost %v", path, cost)
+	}
+
+	// Half of all arrangements can't reach the solved one
+	unsolvable := puzzle{2, 1, 3, 4, 5, 6, 7, 8, 0}
+	if path, _, expanded := space.AStar(unsolvable); path != nil || expanded != 9*8*7*6*5*4*3*2/2 {
+		t.Errorf("AStar returned %v for an unsolvable puzzle after expanding %d states", path, expanded)
+	}
+}
+
+func TestStateSpaceKey(t *testing.T) {
+	// Slices aren't comparable, so they're keyed by their contents
+	space := &graph.StateSpace{
+		Successors: func(state interface{}) []graph.StateTransition {
+			s := state.([]int)
+			return []graph.StateTransition{{append(s[:len(s):len(s)], 1), 1}, {append(s[:len(s):len(s)], 2), 3}}
+		},
+		IsGoal: func(state interface{}) bool {
+			var sum int
+			for _, x := range state.([]int) {
+				sum += x
+			}
+			return sum == 4
+		},
+		Key: func(state interface{}) interface{} {
+			key := ""
+			for _, x := range state.([]int) {
+				key += string(rune('0' + x))
+			}
+			return key
+		},
+	}
+
+	path, cost, _ := space.IDAStar([]int{})
+	if len(path) != 5 || cost != 4 {
+		t.Errorf("Got path %v with cost %v, want four 1s for 4", path, cost)
+	}
+}
@@ -0,0 +1,314 @@
+package graph
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Returned by algorithms that need a directed acyclic graph when given anything else.
+var ErrNotDAG = errors.New("Graph is not a directed acyclic graph")
+
+// Lays out a DAG in layers from the top down, in the style of Sugiyama et al.[1] and dot, so every edge points downwards: the classic picture of a dependency graph. Nodes are
+// placed on the layer below all their predecessors (the longest path from a source decides the layer), edges spanning several layers are routed through dummy nodes on the layers
+// in between, and the order of each layer is improved by sweeping up and down it sweeps times, moving every node to the barycenter of its neighbors on the previous layer, then
+// swapping neighboring nodes while that removes crossings, and keeping whichever ordering has the fewest crossings[2]. Finally each node is pulled horizontally towards its
+// neighbors, keeping nodes on a layer at least nodeSpacing apart. Crossing minimization is a heuristic; it finds a crossing-free ordering in easy cases, not always.
+//
+// Layer i is at Y = i*layerSpacing, and X starts at 0. Bends holds the points each edge spanning more than one layer passes through, keyed by the edge's {head ID, tail ID}, in
+// order from head to tail; edges between adjacent layers are straight. Returns ErrNotDAG if the graph is undirected or has a cycle; a nearly acyclic graph can be laid out
+// by first reversing the edges of its FeedbackArcSet.
+//
+// [1] K. Sugiyama, S. Tagawa and M. Toda, "Methods for visual understanding of hierarchical system structures", IEEE Transactions on Systems, Man, and Cybernetics 11 (1981)
+// [2] E. R. Gansner, E. Koutsofios, S. C. North and K.-P. Vo, "A technique for drawing directed graphs", IEEE Transactions on Software Engineering 19 (1993)
+func SugiyamaLayout(graph Graph, nodeSpacing, layerSpacing float64, sweeps int) (layout Layout, bends map[[2]int][]Position, err error) {
+	if !graph.IsDirected() {
+		return nil, nil, ErrNotDAG
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	n := len(nodes)
+	index := make(map[int]int, n)
+	for i, node := range nodes {
+		index[node.ID()] = i
+	}
+	succs := make([][]int, n)
+	indegree := make([]int, n)
+	for i, node := range nodes {
+		for _, succ := range graph.Successors(node) {
+			j := index[succ.ID()]
+			succs[i] = append(succs[i], j)
+			indegree[j]++
+		}
+		sort.Ints(succs[i])
+	}
+
+	// Layering, by longest path from a source in topological order (Kahn's algorithm, which also finds cycles)
+	layer := make([]int, n)
+	var ready []int
+	for i := range nodes {
+		if indegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	placed := 0
+	for len(ready) > 0 {
+		u := ready[0]
+		ready = ready[1:]
+		placed++
+		for _, v := range succs[u] {
+			if layer[u]+1 > layer[v] {
+				layer[v] = layer[u] + 1
+			}
+			if indegree[v]--; indegree[v] == 0 {
+				ready = append(ready, v)
+			}
+		}
+	}
+	if placed < n {
+		return nil, nil, ErrNotDAG
+	}
+
+	// The layered graph: real nodes are vertices 0 to n-1, dummies follow. Long edges become chains through dummies, remembered by edge for the bends.
+	vertexLayer := append([]int(nil), layer...)
+	up := make([][]int, n)
+	down := make([][]int, n)
+	chains := make(map[[2]int][]int)
+	for u := range nodes {
+		for _, v := range succs[u] {
+			prev := u
+			var chain []int
+			for l := layer[u] + 1; l < layer[v]; l++ {
+				dummy := len(vertexLayer)
+				vertexLayer = append(vertexLayer, l)
+				up, down = append(up, nil), append(down, nil)
+				down[prev] = append(down[prev], dummy)
+				up[dummy] = append(up[dummy], prev)
+				chain = append(chain, dummy)
+				prev = dummy
+			}
+			down[prev] = append(down[prev], v)
+			up[v] = append(up[v], prev)
+			if chain != nil {
+				chains[[2]int{nodes[u].ID(), nodes[v].ID()}] = chain
+			}
+		}
+	}
+
+	layers := [][]int{}
+	for v, l := range vertexLayer {
+		for len(layers) <= l {
+			layers = append(layers, nil)
+		}
+		layers[l] = append(layers[l], v)
+	}
+	order := newLayerOrder(layers, up, down)
+	order.minimizeCrossings(sweeps)
+
+	x := order.coordinates(nodeSpacing)
+	layout = make(Layout, n)
+	for i, node := range nodes {
+		layout[node.ID()] = Position{x[i], float64(layer[i]) * layerSpacing}
+	}
+	bends = make(map[[2]int][]Position, len(chains))
+	for edge, chain := range chains {
+		points := make([]Position, len(chain))
+		for i, dummy := range chain {
+			points[i] = Position{x[dummy], float64(vertexLayer[dummy]) * layerSpacing}
+		}
+		bends[edge] = points
+	}
+
+	return layout, bends, nil
+}
+
+// The vertices of a layered graph in order within each layer, with each vertex's neighbors on the layers above and below
+type layerOrder struct {
+	layers   [][]int
+	up, down [][]int
+	pos      []int // Each vertex's index within its layer
+}
+
+func newLayerOrder(layers [][]int, up, down [][]int) *layerOrder {
+	o := &layerOrder{layers: layers, up: up, down: down, pos: make([]int, len(up))}
+	o.reindex()
+	return o
+}
+
+func (o *layerOrder) reindex() {
+	for _, layer := range o.layers {
+		for i, v := range layer {
+			o.pos[v] = i
+		}
+	}
+}
+
+// Barycenter sweeps, alternately downwards (ordering each layer by its neighbors above) and upwards, keeping the best ordering seen
+func (o *layerOrder) minimizeCrossings(sweeps int) {
+	best, bestCrossings := o.snapshot(), o.crossings()
+	for sweep := 0; sweep < sweeps && bestCrossings > 0; sweep++ {
+		if sweep%2 == 0 {
+			for l := 1; l < len(o.layers); l++ {
+				o.sortByBarycenter(l, o.up)
+			}
+		} else {
+			for l := len(o.layers) - 2; l >= 0; l-- {
+				o.sortByBarycenter(l, o.down)
+			}
+		}
+
+		o.transpose()
+
+		if c := o.crossings(); c < bestCrossings {
+			best, bestCrossings = o.snapshot(), c
+		}
+	}
+
+	o.layers = best
+	o.reindex()
+}
+
+// Swaps neighboring vertices within layers wherever that reduces crossings, until no swap does. This settles the ties that barycenters can't break.
+func (o *layerOrder) transpose() {
+	for improved := true; improved; {
+		improved = false
+		for _, layer := range o.layers {
+			for i := 0; i+1 < len(layer); i++ {
+				u, v := layer[i], layer[i+1]
+				if o.pairCrossings(v, u) < o.pairCrossings(u, v) {
+					layer[i], layer[i+1] = v, u
+					o.pos[u], o.pos[v] = i+1, i
+					improved = true
+				}
+			}
+		}
+	}
+}
+
+// The number of crossings between the edges of u and those of v, with u placed just left of v
+func (o *layerOrder) pairCrossings(u, v int) int {
+	total := 0
+	for _, neighbors := range [2][][]int{o.up, o.down} {
+		for _, a := range neighbors[u] {
+			for _, b := range neighbors[v] {
+				if o.pos[a] > o.pos[b] {
+					total++
+				}
+			}
+		}
+	}
+	return total
+}
+
+func (o *layerOrder) snapshot() [][]int {
+	layers := make([][]int, len(o.layers))
+	for l, layer := range o.layers {
+		layers[l] = append([]int(nil), layer...)
+	}
+	return layers
+}
+
+// Vertices without neighbors on the adjacent layer keep their place
+func (o *layerOrder) sortByBarycenter(l int, neighbors [][]int) {
+	layer := o.layers[l]
+	keys := make(map[int]float64, len(layer))
+	for i, v := range layer {
+		keys[v] = float64(i)
+		if len(neighbors[v]) > 0 {
+			sum := 0.0
+			for _, w := range neighbors[v] {
+				sum += float64(o.pos[w])
+			}
+			keys[v] = sum / float64(len(neighbors[v]))
+		}
+	}
+
+	sort.Stable(byKey{len(layer), func(i int) float64 { return keys[layer[i]] }, func(i, j int) { layer[i], layer[j] = layer[j], layer[i] }})
+	for i, v := range layer {
+		o.pos[v] = i
+	}
+}
+
+// The number of pairs of edges that cross between adjacent layers. Quadratic in the edges between each pair of layers, which is plenty fast for the graphs one would draw.
+func (o *layerOrder) crossings() int {
+	total := 0
+	for l := 0; l+1 < len(o.layers); l++ {
+		var edges [][2]int
+		for _, u := range o.layers[l] {
+			for _, v := range o.down[u] {
+				edges = append(edges, [2]int{o.pos[u], o.pos[v]})
+			}
+		}
+		for i, e := range edges {
+			for _, f := range edges[i+1:] {
+				if (e[0]-f[0])*(e[1]-f[1]) < 0 {
+					total++
+				}
+			}
+		}
+	}
+
+	return total
+}
+
+// Assigns X coordinates: each layer starts packed nodeSpacing apart, and then in alternating sweeps every vertex is pulled to the mean X of its neighbors on the previous layer.
+// The pulls can't all be met without overlaps, so a layer is placed once packing leftwards and once rightwards from its desired positions, and the two are averaged, which keeps
+// the spacing.
+func (o *layerOrder) coordinates(nodeSpacing float64) []float64 {
+	x := make([]float64, len(o.pos))
+	for _, layer := range o.layers {
+		for i, v := range layer {
+			x[v] = float64(i) * nodeSpacing
+		}
+	}
+
+	desired := make([]float64, len(x))
+	for sweep := 0; sweep < 8; sweep++ {
+		neighbors, from, to, step := o.up, 1, len(o.layers), 1
+		if sweep%2 == 1 {
+			neighbors, from, to, step = o.down, len(o.layers)-2, -1, -1
+		}
+
+		for l := from; l != to; l += step {
+			layer := o.layers[l]
+			for _, v := range layer {
+				desired[v] = x[v]
+				if len(neighbors[v]) > 0 {
+					sum := 0.0
+					for _, w := range neighbors[v] {
+						sum += x[w]
+					}
+					desired[v] = sum / float64(len(neighbors[v]))
+				}
+			}
+
+			left, right := make([]float64, len(layer)), make([]float64, len(layer))
+			for i, v := range layer {
+				left[i] = desired[v]
+				if i > 0 {
+					left[i] = math.Max(left[i], left[i-1]+nodeSpacing)
+				}
+			}
+			for i := len(layer) - 1; i >= 0; i-- {
+				right[i] = desired[layer[i]]
+				if i < len(layer)-1 {
+					right[i] = math.Min(right[i], right[i+1]-nodeSpacing)
+				}
+			}
+			for i, v := range layer {
+				x[v] = (left[i] + right[i]) / 2
+			}
+		}
+	}
+
+	minX := math.Inf(1)
+	for _, v := range x {
+		minX = math.Min(minX, v)
+	}
+	for i := range x {
+		x[i] -= minX
+	}
+
+	return x
+}
@@ -0,0 +1,95 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+func dag(edges [][2]int) *graph.GonumGraph {
+	g := graph.NewGonumGraph(true)
+	for _, e := range edges {
+		g.AddNode(graph.GonumNode(e[0]), nil)
+		g.AddEdge(graph.GonumEdge{graph.GonumNode(e[0]), graph.GonumNode(e[1])})
+	}
+	return g
+}
+
+// The number of pairs of straight segments that cross, with long edges split at their bends
+func layoutCrossings(g graph.Graph, layout graph.Layout, bends map[[2]int][]graph.Position) int {
+	var segments [][2]graph.Position
+	for _, edge := range g.EdgeList() {
+		points := []graph.Position{layout[edge.Head().ID()]}
+		points = append(points, bends[[2]int{edge.Head().ID(), edge.Tail().ID()}]...)
+		points = append(points, layout[edge.Tail().ID()])
+		for i := 0; i+1 < len(points); i++ {
+			segments = append(segments, [2]graph.Position{points[i], points[i+1]})
+		}
+	}
+
+	crossings := 0
+	for i, s := range segments {
+		for _, r := range segments[i+1:] {
+			// Segments between the same pair of layers cross if their ends swap sides
+			if s[0].Y == r[0].Y && s[1].Y == r[1].Y && (s[0].X-r[0].X)*(s[1].X-r[1].X) < 0 {
+				crossings++
+			}
+		}
+	}
+	return crossings
+}
+
+func TestSugiyamaLayout(t *testing.T) {
+	// 0 and 1 on top with their children swapped below, and a long edge from 0 to 6 past 3 and 5
+	g := dag([][2]int{{0, 3}, {1, 2}, {2, 4}, {3, 5}, {5, 6}, {0, 6}, {4, 7}})
+	layout, bends, err := graph.SugiyamaLayout(g, 1, 2, 8)
+	if err != nil {
+		t.Fatal("Failed to lay out a DAG:", err)
+	}
+
+	for _, edge := range g.EdgeList() {
+		if head, tail := layout[edge.Head().ID()], layout[edge.Tail().ID()]; head.Y >= tail.Y {
+			t.Errorf("Edge %v points upwards, from %v to %v", edge, head, tail)
+		}
+	}
+	if layout[0].Y != 0 || layout[5].Y != 4 || layout[6].Y != 6 {
+		t.Errorf("Got layers at %v, %v and %v, want 0, 4 and 6", layout[0].Y, layout[5].Y, layout[6].Y)
+	}
+	if points := bends[[2]int{0, 6}]; len(points) != 2 || points[0].Y != 2 || points[1].Y != 4 {
+		t.Errorf("The edge from 0 to 6 bends at %v, want one point on each of the two layers between", points)
+	}
+	if c := layoutCrossings(g, layout, bends); c != 0 {
+		t.Errorf("Layout has %d crossings, the graph is planar", c)
+	}
+
+	// No two nodes or bends on a layer overlap
+	byLayer := make(map[float64][]float64)
+	for _, p := range layout {
+		byLayer[p.Y] = append(byLayer[p.Y], p.X)
+	}
+	for _, points := range bends {
+		for _, p := range points {
+			byLayer[p.Y] = append(byLayer[p.Y], p.X)
+		}
+	}
+	for y, xs := range byLayer {
+		for i, a := range xs {
+			for _, b := range xs[i+1:] {
+				if a-b < 1-1e-9 && b-a < 1-1e-9 {
+					t.Errorf("Two points on layer %v are only %v apart", y, a-b)
+				}
+			}
+		}
+	}
+}
+
+func TestSugiyamaLayoutNotDAG(t *testing.T) {
+	if _, _, err := graph.SugiyamaLayout(dag([][2]int{{0, 1}, {1, 2}, {2, 0}}), 1, 1, 4); err != graph.ErrNotDAG {
+		t.Error("Laid out a cycle")
+	}
+
+	g := graph.NewGonumGraph(false)
+	graph.PathGraph(g, 3, false)
+	if _, _, err := graph.SugiyamaLayout(g, 1, 1, 4); err != graph.ErrNotDAG {
+		t.Error("Laid out an undirected graph")
+	}
+}
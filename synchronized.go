@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"sync"
+)
+
+// A SynchronizedGraph guards another graph with a read-write lock, so a search can read it on one goroutine while another changes it. Every Graph method, and Cost and
+// HeuristicCost, takes the read lock; changes go through Mutate, which takes the write lock. Changing the wrapped graph directly, other than in Mutate, isn't safe.
+//
+// Cost is the wrapped graph's, or 1 if it isn't a Coster, and HeuristicCost is likewise the graph's or the null heuristic. The lock only keeps each call from racing with a
+// change: a search that reads the graph on either side of a change sees the graph both before and after it. A one-shot search like AStar may find a path that no longer exists,
+// and D*-Lite still has to be told which edges changed (see SynchronizedDStar), or it'll plan with costs it read before the change.
+//
+// If the reads and changes are both driven from one place, like a DStarService and its Changes, it's simpler and cheaper to make the changes on the service's own goroutine
+// with DStarChange.Apply, which needs no lock at all.
+type SynchronizedGraph struct {
+	mu        sync.RWMutex
+	graph     Graph
+	cost      func(Node, Node) float64
+	heuristic func(Node, Node) float64
+}
+
+// Wraps g in a SynchronizedGraph. From then on, g should only be changed through the wrapper's Mutate.
+func Synchronized(g Graph) *SynchronizedGraph {
+	s := &SynchronizedGraph{graph: g, cost: UniformCost, heuristic: NullHeuristic}
+	if cgraph, ok := g.(Coster); ok {
+		s.cost = cgraph.Cost
+	}
+	if hgraph, ok := g.(HeuristicCoster); ok {
+		s.heuristic = hgraph.HeuristicCost
+	}
+	return s
+}
+
+// Calls fn while holding the write lock, so no method of the wrapper is running while it changes the wrapped graph. fn mustn't call the wrapper's methods itself, it would
+// deadlock; it should change the graph through the reference the caller already has.
+func (s *SynchronizedGraph) Mutate(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+// Calls fn while holding the read lock, for several reads of the wrapped graph that have to see it in the same state. As with Mutate, fn mustn't call the wrapper's methods.
+func (s *SynchronizedGraph) Read(fn func()) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn()
+}
+
+func (s *SynchronizedGraph) Successors(node Node) []Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.Successors(node)
+}
+
+func (s *SynchronizedGraph) IsSuccessor(node, successor Node) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.IsSuccessor(node, successor)
+}
+
+func (s *SynchronizedGraph) Predecessors(node Node) []Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.Predecessors(node)
+}
+
+func (s *SynchronizedGraph) IsPredecessor(node, predecessor Node) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.IsPredecessor(node, predecessor)
+}
+
+func (s *SynchronizedGraph) IsAdjacent(node, neighbor Node) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.IsAdjacent(node, neighbor)
+}
+
+func (s *SynchronizedGraph) NodeExists(node Node) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.NodeExists(node)
+}
+
+func (s *SynchronizedGraph) Degree(node Node) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.Degree(node)
+}
+
+func (s *SynchronizedGraph) EdgeList() []Edge {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.EdgeList()
+}
+
+func (s *SynchronizedGraph) NodeList() []Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.NodeList()
+}
+
+func (s *SynchronizedGraph) IsDirected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph.IsDirected()
+}
+
+func (s *SynchronizedGraph) Cost(node1, node2 Node) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cost(node1, node2)
+}
+
+func (s *SynchronizedGraph) HeuristicCost(node1, node2 Node) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.heuristic(node1, node2)
+}
+
+// A SynchronizedDStarGraph is a SynchronizedGraph around a DStarGraph, for running D*-Lite (with SynchronizedDStarLite, a DStarService, or by hand) on a graph that other
+// goroutines change too. Move and ChangedEdges take the write lock, since a DStarGraph usually changes itself as it moves, and the cost function ChangedEdges returns takes
+// the read lock.
+//
+// D*-Lite only learns about changes through ChangedEdges, so a change made with Mutate has to be reported by the next call to it, e.g. by having the wrapped graph record
+// the edges it changed, as a RevealingTileGraph does for the tiles it reveals.
+type SynchronizedDStarGraph struct {
+	*SynchronizedGraph
+	graph DStarGraph
+}
+
+// Wraps g in a SynchronizedDStarGraph. From then on, g should only be changed through the wrapper's Mutate.
+func SynchronizedDStar(g DStarGraph) *SynchronizedDStarGraph {
+	return &SynchronizedDStarGraph{SynchronizedGraph: Synchronized(g), graph: g}
+}
+
+func (s *SynchronizedDStarGraph) Move(target Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.graph.Move(target)
+}
+
+func (s *SynchronizedDStarGraph) ChangedEdges() (newCostFunc func(Node, Node) float64, changedEdges []Edge) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	newCost, edges := s.graph.ChangedEdges()
+	if newCost == nil {
+		return nil, edges
+	}
+	return func(node1, node2 Node) float64 {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return newCost(node1, node2)
+	}, edges
+}
@@ -0,0 +1,92 @@
+package graph_test
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestSynchronized(t *testing.T) {
+	g := randomWeightedGraph(50, 0.1, 9)
+	s := graph.Synchronized(g)
+	for _, edge := range g.EdgeList() {
+		if s.Cost(edge.Head(), edge.Tail()) != g.Cost(edge.Head(), edge.Tail()) {
+			t.Fatalf("Edge %v-%v costs %v through the wrapper, %v without", edge.Head(), edge.Tail(), s.Cost(edge.Head(), edge.Tail()), g.Cost(edge.Head(), edge.Tail()))
+		}
+	}
+	if tiles := graph.Synchronized(graph.NewTileGraph(3, 3, true)); tiles.Cost(graph.GonumNode(0), graph.GonumNode(1)) != 1 || tiles.HeuristicCost(graph.GonumNode(0), graph.GonumNode(8)) != 0 {
+		t.Errorf("Expected uniform costs and the null heuristic for a graph with neither")
+	}
+
+	// Searches read the graph while its costs keep changing underneath them; run with -race to check the wrapper keeps them apart
+	nodes := g.NodeList()
+	edges := g.EdgeList()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				graph.AStar(nodes[i], nodes[(i+j)%len(nodes)], s, nil, nil)
+			}
+		}(i)
+	}
+	for j := 0; j < 200; j++ {
+		edge := edges[j%len(edges)]
+		s.Mutate(func() {
+			g.SetEdgeCost(edge, float64(j%7+1))
+		})
+	}
+	wg.Wait()
+
+	var cost float64
+	s.Read(func() {
+		cost = g.Cost(edges[0].Head(), edges[0].Tail())
+	})
+	if cost != s.Cost(edges[0].Head(), edges[0].Tail()) || math.IsInf(cost, 0) {
+		t.Errorf("Read saw the cost %v, Cost gives %v", cost, s.Cost(edges[0].Head(), edges[0].Tail()))
+	}
+}
+
+func TestSynchronizedDStarService(t *testing.T) {
+	truth := graph.NewTileGraph(12, 12, true)
+	start, goal := truth.CoordsToNode(5, 0), truth.CoordsToNode(5, 11)
+	world := graph.SynchronizedDStar(graph.NewRevealingTileGraph(truth, start, 1))
+
+	// Builds a wall across the middle of the map while the service makes its first plan, leaving a gap in the bottom row. The agent finds it as it goes, since tiles are
+	// only revealed when it moves, under the lock
+	walled := make(chan struct{})
+	go func() {
+		defer close(walled)
+		for row := 0; row < 11; row++ {
+			world.Mutate(func() {
+				truth.SetPassability(row, 6, false)
+			})
+		}
+	}()
+
+	service := graph.NewDStarService(start, goal, world, nil, nil)
+	<-service.Events
+	<-walled
+	service.Commands <- graph.DStarResume
+	prev := start
+	for event := range service.Events {
+		if event.Move == nil {
+			continue
+		}
+		r1, c1 := truth.IDToCoords(prev.ID())
+		r2, c2 := truth.IDToCoords(event.Move.ID())
+		if math.Abs(float64(r1-r2))+math.Abs(float64(c1-c2)) != 1 {
+			t.Fatalf("Service moved from %v to %v, which aren't adjacent", prev, event.Move)
+		}
+		prev = event.Move
+	}
+	if err := <-service.Done; err != nil {
+		t.Fatal("Service failed:", err)
+	}
+	if prev.ID() != goal.ID() {
+		t.Errorf("Service stopped at %v, expected to be at the goal %v", prev, goal)
+	}
+}
@@ -0,0 +1,33 @@
+package graph
+
+import (
+	"math"
+)
+
+// A Tolerance decides whether two floating point costs are close enough to be treated as equal. Algorithms that compare accumulated costs for equality take one, so that what counts as
+// "equal" can be matched to the scale of a graph's costs rather than hard coded.
+type Tolerance func(a, b float64) bool
+
+// The tolerance used when none is given: equal to within one part in a billion. Being relative, it behaves the same whether costs are measured in nanometers or light years.
+var DefaultTolerance = RelativeTolerance(1e-9)
+
+// Treats a and b as equal if they differ by at most eps. Only appropriate when the scale of the costs is known in advance.
+func AbsoluteTolerance(eps float64) Tolerance {
+	return func(a, b float64) bool {
+		return a == b || math.Abs(a-b) <= eps
+	}
+}
+
+// Treats a and b as equal if they differ by at most eps times the larger of their magnitudes. Equal infinities are equal, and nothing finite is equal to an infinity.
+func RelativeTolerance(eps float64) Tolerance {
+	return func(a, b float64) bool {
+		if a == b {
+			return true
+		}
+		if math.IsInf(a, 0) || math.IsInf(b, 0) {
+			return false
+		}
+
+		return math.Abs(a-b) <= eps*math.Max(math.Abs(a), math.Abs(b))
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// The error TopologicalSort returns for a graph with a cycle, with a cycle it found as a witness: Cycle[0] has an edge to Cycle[1], and so on round to the last node, which has
+// an edge back to Cycle[0]. A self loop is a cycle of one node.
+type CycleError struct {
+	Cycle []Node
+}
+
+// Lists the cycle's node IDs, in the form "Graph has a cycle: 1 -> 2 -> 3 -> 1".
+func (e *CycleError) Error() string {
+	var buf bytes.Buffer
+	buf.WriteString("Graph has a cycle: ")
+	for _, node := range e.Cycle {
+		fmt.Fprintf(&buf, "%d -> ", node.ID())
+	}
+	fmt.Fprintf(&buf, "%d", e.Cycle[0].ID())
+	return buf.String()
+}
+
+// Orders the nodes of a DAG so that every edge goes from an earlier node to a later one, as for running a build's steps one at a time. The depth first search it's found by
+// starts from nodes and follows edges in order of ID, so the order is a function of the graph alone.
+//
+// If the graph has a cycle, the error is a *CycleError holding one, to say what has to be broken for the graph to be sorted. An undirected graph can't be sorted, and gives
+// ErrNotDAG, as does TopologicalGenerations, which groups the nodes into rounds that could each run in parallel.
+func TopologicalSort(graph Graph) ([]Node, error) {
+	if !graph.IsDirected() {
+		return nil, ErrNotDAG
+	}
+
+	const (
+		unvisited = iota
+		onStack
+		finished
+	)
+	type frame struct {
+		node  Node
+		succs []Node
+		next  int
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	state := make(map[int]int, len(nodes))
+	depth := make(map[int]int) // Where each node on the stack is in it
+	order := make([]Node, len(nodes))
+	placed := len(nodes)
+
+	var stack []frame
+	push := func(node Node) {
+		succs := graph.Successors(node)
+		sort.Sort(byID(succs))
+		state[node.ID()], depth[node.ID()] = onStack, len(stack)
+		stack = append(stack, frame{node: node, succs: succs})
+	}
+
+	// A depth first search finishes every node after all of its successors, so filling the order from the back as nodes finish sorts them
+	for _, root := range nodes {
+		if state[root.ID()] != unvisited {
+			continue
+		}
+		push(root)
+		for len(stack) != 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(top.succs) {
+				state[top.node.ID()] = finished
+				placed--
+				order[placed] = top.node
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			succ := top.succs[top.next]
+			top.next++
+			switch state[succ.ID()] {
+			case unvisited:
+				push(succ)
+			case onStack:
+				// The stack runs from succ to the top's node, which has an edge back to succ
+				cycle := make([]Node, 0, len(stack)-depth[succ.ID()])
+				for _, f := range stack[depth[succ.ID()]:] {
+					cycle = append(cycle, f.node)
+				}
+				return nil, &CycleError{Cycle: cycle}
+			}
+		}
+	}
+
+	return order, nil
+}
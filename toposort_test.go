@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	g := graph.NewDirectedGraph(0, 0)
+	for _, e := range [][2]int{{5, 2}, {5, 0}, {4, 0}, {4, 1}, {2, 3}, {3, 1}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+	order, err := graph.TopologicalSort(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{5, 4, 2, 3, 1, 0}; !reflect.DeepEqual(nodeIDs(order), want) {
+		t.Errorf("Expected the order %v, got %v", want, nodeIDs(order))
+	}
+
+	// Random DAGs, edges going from lower IDs to higher ones
+	src := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		dag := graph.NewDirectedGraph(30, 60)
+		for j := 0; j < 30; j++ {
+			dag.AddNode(graph.GonumNode(j), nil)
+		}
+		for j := 0; j < 60; j++ {
+			a, b := src.Intn(30), src.Intn(30)
+			if a < b {
+				dag.AddEdge(graph.GonumEdge{H: graph.GonumNode(a), T: graph.GonumNode(b)})
+			}
+		}
+		order, err := graph.TopologicalSort(dag)
+		if err != nil || len(order) != 30 {
+			t.Fatalf("Sorting a DAG gave %d nodes (%v)", len(order), err)
+		}
+		position := make(map[int]int)
+		for j, node := range order {
+			position[node.ID()] = j
+		}
+		for _, edge := range dag.EdgeList() {
+			if position[edge.Head().ID()] >= position[edge.Tail().ID()] {
+				t.Fatalf("The edge %v -> %v goes backwards in the order %v", edge.Head(), edge.Tail(), nodeIDs(order))
+			}
+		}
+	}
+}
+
+func TestTopologicalSortCycles(t *testing.T) {
+	g := graph.NewDirectedGraph(0, 0)
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 1}, {3, 4}} {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(e[0]), T: graph.GonumNode(e[1])})
+	}
+	_, err := graph.TopologicalSort(g)
+	cerr, ok := err.(*graph.CycleError)
+	if !ok {
+		t.Fatalf("Expected a CycleError, got %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(nodeIDs(cerr.Cycle), want) {
+		t.Errorf("Expected the cycle %v, got %v", want, nodeIDs(cerr.Cycle))
+	}
+	if msg := cerr.Error(); msg != "Graph has a cycle: 1 -> 2 -> 3 -> 1" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	loop := graph.NewDirectedGraph(0, 0)
+	loop.AddEdge(graph.GonumEdge{H: graph.GonumNode(7), T: graph.GonumNode(7)})
+	if _, err := graph.TopologicalSort(loop); err == nil || !reflect.DeepEqual(nodeIDs(err.(*graph.CycleError).Cycle), []int{7}) {
+		t.Errorf("Expected the self loop at 7 as a cycle, got %v", err)
+	}
+
+	if _, err := graph.TopologicalSort(graph.NewUndirectedGraph(0, 0)); err != graph.ErrNotDAG {
+		t.Errorf("Expected ErrNotDAG for an undirected graph, got %v", err)
+	}
+}
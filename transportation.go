@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"math"
+)
+
+// Solves the transportation problem: ships goods from suppliers, each with supply[i] to give, to consumers, each wanting demand[j], as cheaply as possible, where cost[i][j] is
+// the cost per unit shipped from supplier i to consumer j. An infinite or NaN cost means i can't ship to j. If capacity isn't nil, capacity[i][j] limits what i can ship to
+// j, which makes it the capacitated transportation problem; with unit supplies and demands, it's the assignment problem. Supplies can add up to more than the demands, but
+// every consumer must get exactly what it wants.
+//
+// Returns the plan, plan[i][j] being the amount shipped from i to j, and its total cost. Returns ErrInfeasible if the demands can't be met. The plan is a minimum cost flow
+// through the network from a source, to the suppliers, to the consumers, to a sink; if supplies, demands and capacities are whole numbers, so is every amount shipped. Panics
+// if cost, or capacity, isn't len(supply) by len(demand).
+func Transportation(supply, demand []float64, cost, capacity [][]float64) (plan [][]float64, total float64, err error) {
+	m, n := len(supply), len(demand)
+	if len(cost) != m || capacity != nil && len(capacity) != m {
+		panic("Transportation costs and capacities must have a row for each supplier")
+	}
+	for i := range cost {
+		if len(cost[i]) != n || capacity != nil && len(capacity[i]) != n {
+			panic("Transportation costs and capacities must have a column for each consumer")
+		}
+	}
+
+	// Suppliers are nodes 0 to m-1 and consumers m to m+n-1, then the source and sink
+	source, sink := m+n, m+n+1
+	network := newFlowNetwork(m + n + 2)
+	wanted, largest := 0.0, 0.0
+	for i, s := range supply {
+		network.addArc(source, i, s)
+		largest = math.Max(largest, s)
+	}
+	for j, d := range demand {
+		network.addArc(m+j, sink, d)
+		wanted += d
+		largest = math.Max(largest, d)
+	}
+	routes := make([][]int, m)
+	for i := range routes {
+		routes[i] = make([]int, n)
+		for j := range routes[i] {
+			routes[i][j] = -1
+			c := cost[i][j]
+			if math.IsInf(c, 0) || math.IsNaN(c) {
+				continue
+			}
+			limit := math.Min(supply[i], demand[j])
+			if capacity != nil {
+				limit = math.Min(limit, capacity[i][j])
+			}
+			if limit > 0 {
+				routes[i][j] = network.addArc(i, m+j, limit)
+				network.setCost(routes[i][j], c)
+			}
+		}
+	}
+
+	eps := 1e-9 * math.Max(1, largest)
+	shipped, total := network.minCostFlow(source, sink, wanted, math.Inf(1), eps)
+	if shipped < wanted-eps*float64(n+1) {
+		return nil, 0, ErrInfeasible
+	}
+
+	plan = make([][]float64, m)
+	for i := range plan {
+		plan[i] = make([]float64, n)
+		for j, arc := range routes[i] {
+			if arc != -1 {
+				plan[i][j] = network.flow(arc)
+			}
+		}
+	}
+
+	return plan, total, nil
+}
@@ -0,0 +1,102 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestTransportation(t *testing.T) {
+	supply, demand := []float64{2, 1}, []float64{1, 2}
+	cost := [][]float64{{1, 5}, {4, 2}}
+	plan, total, err := graph.Transportation(supply, demand, cost, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 8 {
+		t.Errorf("Expected a cost of 8, got %v for %v", total, plan)
+	}
+	expected := [][]float64{{1, 1}, {0, 1}}
+	for i := range plan {
+		for j := range plan[i] {
+			if plan[i][j] != expected[i][j] {
+				t.Errorf("Expected plan %v, got %v", expected, plan)
+			}
+		}
+	}
+
+	// Consumer 1 must get at least 1 from supplier 0
+	if _, _, err := graph.Transportation(supply, demand, cost, [][]float64{{2, 0}, {1, 1}}); err != graph.ErrInfeasible {
+		t.Errorf("Expected ErrInfeasible with no capacity from 0 to 1, got %v", err)
+	}
+	if _, _, err := graph.Transportation(supply, demand, [][]float64{{1, math.Inf(1)}, {4, 2}}, nil); err != graph.ErrInfeasible {
+		t.Errorf("Expected ErrInfeasible with no route from 0 to 1, got %v", err)
+	}
+}
+
+// The cheapest assignment, by trying every permutation
+func bruteForceAssignment(cost [][]float64) float64 {
+	n := len(cost)
+	perm := make([]int, n)
+	used := make([]bool, n)
+	best := math.Inf(1)
+	var try func(i int, sum float64)
+	try = func(i int, sum float64) {
+		if i == n {
+			best = math.Min(best, sum)
+			return
+		}
+		for j := 0; j < n; j++ {
+			if !used[j] {
+				used[j], perm[i] = true, j
+				try(i+1, sum+cost[i][j])
+				used[j] = false
+			}
+		}
+	}
+	try(0, 0)
+	return best
+}
+
+func TestTransportationAssignment(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		n := 2 + src.Intn(5)
+		ones := make([]float64, n)
+		cost := make([][]float64, n)
+		for i := range cost {
+			ones[i] = 1
+			cost[i] = make([]float64, n)
+			for j := range cost[i] {
+				cost[i][j] = float64(src.Intn(21) - 5)
+			}
+		}
+
+		plan, total, err := graph.Transportation(ones, ones, cost, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if best := bruteForceAssignment(cost); math.Abs(total-best) > 1e-9 {
+			t.Errorf("Expected an assignment costing %v, got %v", best, total)
+		}
+		sum := 0.0
+		for i := range plan {
+			row := 0.0
+			for j := range plan[i] {
+				if plan[i][j] != 0 && plan[i][j] != 1 {
+					t.Errorf("Fractional assignment %v", plan)
+				}
+				row += plan[i][j]
+				sum += plan[i][j] * cost[i][j]
+			}
+			if row != 1 {
+				t.Errorf("Supplier %d is assigned %v times", i, row)
+			}
+		}
+		if math.Abs(sum-total) > 1e-9 {
+			t.Errorf("Plan costs %v, but the total is %v", sum, total)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package graph
+
+import (
+	"sort"
+)
+
+// How an edge met during a traversal relates to the traversal's tree.
+type EdgeClass int
+
+const (
+	TreeEdge    EdgeClass = iota // The edge by which its tail was discovered
+	BackEdge                     // To an ancestor still being explored: a DFS meeting one has found a cycle
+	ForwardEdge                  // To a descendant already finished, by a shortcut around the tree (directed DFS only)
+	CrossEdge                    // To a node in another, finished branch; BFS reports every edge that isn't a tree edge as one
+)
+
+func (class EdgeClass) String() string {
+	switch class {
+	case TreeEdge:
+		return "tree"
+	case BackEdge:
+		return "back"
+	case ForwardEdge:
+		return "forward"
+	case CrossEdge:
+		return "cross"
+	}
+	return "unknown"
+}
+
+// A TraversalVisitor is called back by BreadthFirst and DepthFirst as they go. Each method returns whether to carry on, so returning false from any of them ends the traversal
+// there, e.g. once the node being looked for is discovered. Like SearchObserver, embed NullVisitor to only implement the callbacks you care about.
+type TraversalVisitor interface {
+	DiscoverNode(node Node, depth int) bool       // The node was reached for the first time, depth edges from where the traversal started
+	ExamineEdge(edge Edge) bool                   // An edge out of the node being explored is about to be followed
+	ClassifyEdge(edge Edge, class EdgeClass) bool // The edge just examined turned out to be of this class
+	FinishNode(node Node) bool                    // All the node's edges have been examined (in DFS, and everything discovered through them finished too)
+}
+
+// NullVisitor implements TraversalVisitor by carrying on regardless.
+type NullVisitor struct{}
+
+func (NullVisitor) DiscoverNode(node Node, depth int) bool       { return true }
+func (NullVisitor) ExamineEdge(edge Edge) bool                   { return true }
+func (NullVisitor) ClassifyEdge(edge Edge, class EdgeClass) bool { return true }
+func (NullVisitor) FinishNode(node Node) bool                    { return true }
+
+// Traverses graph breadth first from start, discovering nodes in order of their distance in edges from it, and calling visitor back along the way. A node is discovered when it's
+// first reached, and finished after all its edges have been examined. Edges to nodes discovered earlier are classified as CrossEdges, since BFS doesn't tell back edges apart.
+//
+// Returns false if the visitor stopped the traversal, and true if it ran until everything reachable from start was finished. Successors are followed in the order the graph
+// gives them.
+func BreadthFirst(start Node, graph ImplicitGraph, visitor TraversalVisitor) bool {
+	depth := map[int]int{start.ID(): 0}
+	if !visitor.DiscoverNode(start, 0) {
+		return false
+	}
+
+	for queue := []Node{start}; len(queue) > 0; queue = queue[1:] {
+		node := queue[0]
+		for _, succ := range graph.Successors(node) {
+			edge := GonumEdge{H: node, T: succ}
+			if !visitor.ExamineEdge(edge) {
+				return false
+			}
+
+			if _, seen := depth[succ.ID()]; seen {
+				if !visitor.ClassifyEdge(edge, CrossEdge) {
+					return false
+				}
+				continue
+			}
+
+			depth[succ.ID()] = depth[node.ID()] + 1
+			if !visitor.ClassifyEdge(edge, TreeEdge) || !visitor.DiscoverNode(succ, depth[succ.ID()]) {
+				return false
+			}
+			queue = append(queue, succ)
+		}
+
+		if !visitor.FinishNode(node) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A node being explored by DepthFirst, with how far through its successors it's got
+type dfsFrame struct {
+	node  Node
+	succs []Node
+	next  int
+}
+
+// The state of a depth first traversal, which may span several trees
+type dfsState struct {
+	graph     ImplicitGraph
+	visitor   TraversalVisitor
+	directed  bool
+	discovery map[int]int // The order nodes were discovered in
+	finished  map[int]bool
+	parent    map[int]int
+}
+
+func newDFSState(graph ImplicitGraph, visitor TraversalVisitor) *dfsState {
+	return &dfsState{
+		graph:     graph,
+		visitor:   visitor,
+		directed:  isDirected(graph),
+		discovery: make(map[int]int),
+		finished:  make(map[int]bool),
+		parent:    make(map[int]int),
+	}
+}
+
+// Explores everything reachable from root that hasn't been discovered yet, returning false if the visitor stopped it
+func (dfs *dfsState) explore(root Node) bool {
+	dfs.discovery[root.ID()] = len(dfs.discovery)
+	if !dfs.visitor.DiscoverNode(root, 0) {
+		return false
+	}
+
+	// An explicit stack rather than recursion, so very deep graphs (like long paths) don't overflow the goroutine's stack
+	stack := []*dfsFrame{{node: root, succs: dfs.graph.Successors(root)}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if top.next == len(top.succs) {
+			stack = stack[:len(stack)-1]
+			dfs.finished[top.node.ID()] = true
+			if !dfs.visitor.FinishNode(top.node) {
+				return false
+			}
+			continue
+		}
+
+		succ := top.succs[top.next]
+		top.next++
+		edge := GonumEdge{H: top.node, T: succ}
+
+		_, discovered := dfs.discovery[succ.ID()]
+		if !dfs.directed && discovered {
+			// In an undirected graph every edge is met from both ends: the second time, it's either the tree edge back to the parent or a back edge seen from the ancestor's end
+			if parent, ok := dfs.parent[top.node.ID()]; (ok && parent == succ.ID()) || dfs.finished[succ.ID()] {
+				continue
+			}
+		}
+
+		if !dfs.visitor.ExamineEdge(edge) {
+			return false
+		}
+
+		var class EdgeClass
+		switch {
+		case !discovered:
+			class = TreeEdge
+		case !dfs.finished[succ.ID()]:
+			class = BackEdge
+		case dfs.discovery[top.node.ID()] < dfs.discovery[succ.ID()]:
+			class = ForwardEdge
+		default:
+			class = CrossEdge
+		}
+		if !dfs.visitor.ClassifyEdge(edge, class) {
+			return false
+		}
+
+		if class == TreeEdge {
+			dfs.discovery[succ.ID()] = len(dfs.discovery)
+			dfs.parent[succ.ID()] = top.node.ID()
+			if !dfs.visitor.DiscoverNode(succ, len(stack)) {
+				return false
+			}
+			stack = append(stack, &dfsFrame{node: succ, succs: dfs.graph.Successors(succ)})
+		}
+	}
+
+	return true
+}
+
+// Traverses graph depth first from start, calling visitor back along the way. Edges are classified as in the classic DFS of Cormen et al.: tree edges, back edges to ancestors
+// still being explored, forward edges to finished descendants and cross edges to anything else. In an undirected graph (one with an IsDirected method returning false), each
+// edge is examined once, from the end that's explored first, and is either a tree edge or a back edge; the other end skips it.
+//
+// Returns false if the visitor stopped the traversal, and true if it ran until everything reachable from start was finished. Successors are followed in the order the graph
+// gives them. The traversal keeps its own stack, so it's safe on graphs of any depth.
+func DepthFirst(start Node, graph ImplicitGraph, visitor TraversalVisitor) bool {
+	return newDFSState(graph, visitor).explore(start)
+}
+
+// Traverses every node of graph depth first, starting a new tree from each node (in order of ID) not reached from an earlier one, so every node is discovered and finished once.
+// The roots of the trees are discovered at depth 0. Returns false if the visitor stopped the traversal.
+func DepthFirstForest(graph Graph, visitor TraversalVisitor) bool {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+
+	dfs := newDFSState(graph, visitor)
+	for _, node := range nodes {
+		if _, discovered := dfs.discovery[node.ID()]; discovered {
+			continue
+		}
+		if !dfs.explore(node) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+package graph_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A graph given as adjacency lists, whose successors come in a fixed order
+type adjacency map[int][]int
+
+func (adj adjacency) Successors(node graph.Node) []graph.Node {
+	var succs []graph.Node
+	for _, id := range adj[node.ID()] {
+		succs = append(succs, graph.GonumNode(id))
+	}
+	return succs
+}
+
+type undirectedAdjacency struct {
+	adjacency
+}
+
+func (undirectedAdjacency) IsDirected() bool {
+	return false
+}
+
+// Records everything a traversal does as strings, and stops it at a given node if stopAt isn't -1
+type recordingVisitor struct {
+	graph.NullVisitor
+	events []string
+	stopAt int
+}
+
+func (v *recordingVisitor) DiscoverNode(node graph.Node, depth int) bool {
+	v.events = append(v.events, fmt.Sprintf("discover %d at %d", node.ID(), depth))
+	return node.ID() != v.stopAt
+}
+
+func (v *recordingVisitor) ClassifyEdge(edge graph.Edge, class graph.EdgeClass) bool {
+	v.events = append(v.events, fmt.Sprintf("%v %d->%d", class, edge.Head().ID(), edge.Tail().ID()))
+	return true
+}
+
+func (v *recordingVisitor) FinishNode(node graph.Node) bool {
+	v.events = append(v.events, fmt.Sprintf("finish %d", node.ID()))
+	return true
+}
+
+func TestDepthFirstDirected(t *testing.T) {
+	adj := adjacency{0: {1, 2}, 1: {2}, 2: {0, 3}, 4: {3}}
+	v := &recordingVisitor{stopAt: -1}
+	if !graph.DepthFirst(graph.GonumNode(0), adj, v) {
+		t.Error("DepthFirst stopped early")
+	}
+
+	expected := []string{
+		"discover 0 at 0", "tree 0->1", "discover 1 at 1", "tree 1->2", "discover 2 at 2", "back 2->0", "tree 2->3", "discover 3 at 3",
+		"finish 3", "finish 2", "finish 1", "forward 0->2", "finish 0",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, v.events)
+	}
+}
+
+func TestDepthFirstUndirected(t *testing.T) {
+	adj := undirectedAdjacency{adjacency{0: {1, 2}, 1: {0, 2}, 2: {0, 1, 3}, 3: {2}}}
+	v := &recordingVisitor{stopAt: -1}
+	graph.DepthFirst(graph.GonumNode(0), adj, v)
+
+	expected := []string{
+		"discover 0 at 0", "tree 0->1", "discover 1 at 1", "tree 1->2", "discover 2 at 2", "back 2->0", "tree 2->3", "discover 3 at 3",
+		"finish 3", "finish 2", "finish 1", "finish 0",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, v.events)
+	}
+}
+
+func TestDepthFirstForest(t *testing.T) {
+	g := graph.NewGonumGraph(true)
+	for _, edge := range [][2]int{{1, 0}, {2, 0}, {3, 4}} {
+		g.AddNode(graph.GonumNode(edge[0]), []graph.Node{graph.GonumNode(edge[1])})
+	}
+
+	v := &recordingVisitor{stopAt: -1}
+	graph.DepthFirstForest(g, v)
+	expected := []string{
+		"discover 0 at 0", "finish 0",
+		"discover 1 at 0", "cross 1->0", "finish 1",
+		"discover 2 at 0", "cross 2->0", "finish 2",
+		"discover 3 at 0", "tree 3->4", "discover 4 at 1", "finish 4", "finish 3",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, v.events)
+	}
+}
+
+func TestBreadthFirst(t *testing.T) {
+	adj := adjacency{0: {1, 2}, 1: {2, 3}, 2: {0}, 3: {4}}
+	v := &recordingVisitor{stopAt: -1}
+	if !graph.BreadthFirst(graph.GonumNode(0), adj, v) {
+		t.Error("BreadthFirst stopped early")
+	}
+
+	expected := []string{
+		"discover 0 at 0", "tree 0->1", "discover 1 at 1", "tree 0->2", "discover 2 at 1", "finish 0",
+		"cross 1->2", "tree 1->3", "discover 3 at 2", "finish 1",
+		"cross 2->0", "finish 2",
+		"tree 3->4", "discover 4 at 3", "finish 3",
+		"finish 4",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("Expected\n%v\ngot\n%v", expected, v.events)
+	}
+
+	v = &recordingVisitor{stopAt: 3}
+	if graph.BreadthFirst(graph.GonumNode(0), adj, v) {
+		t.Error("BreadthFirst didn't report being stopped")
+	}
+	if last := v.events[len(v.events)-1]; last != "discover 3 at 2" {
+		t.Errorf("Expected the traversal to stop on discovering 3, but it went on to %q", last)
+	}
+}
+
+func TestDepthFirstDeep(t *testing.T) {
+	const n = 100000
+	adj := make(adjacency, n)
+	for i := 0; i+1 < n; i++ {
+		adj[i] = []int{i + 1}
+	}
+
+	deepest := 0
+	v := &depthVisitor{deepest: &deepest}
+	graph.DepthFirst(graph.GonumNode(0), adj, v)
+	if deepest != n-1 {
+		t.Errorf("Expected to reach depth %d, got %d", n-1, deepest)
+	}
+}
+
+type depthVisitor struct {
+	graph.NullVisitor
+	deepest *int
+}
+
+func (v depthVisitor) DiscoverNode(node graph.Node, depth int) bool {
+	if depth > *v.deepest {
+		*v.deepest = depth
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package graph
+
+import (
+	"errors"
+	"sort"
+)
+
+// Returned when a tree decomposition is too wide for an algorithm that's exponential in its width.
+var ErrTreewidthTooLarge = errors.New("Tree decomposition is too wide")
+
+// How NewTreeDecomposition picks the next node to eliminate.
+type EliminationHeuristic int
+
+const (
+	MinDegree EliminationHeuristic = iota // The node with the fewest neighbors left: fast, and usually close to min fill in
+	MinFillIn                             // The node whose neighbors need the fewest edges added to make them a clique: slower, and usually narrower
+)
+
+// A TreeDecomposition covers a graph with bags of nodes, arranged in a tree, such that every edge of the graph has both ends in some bag and the bags containing any one node
+// form a subtree. Its width is the size of the largest bag less one; the smallest possible width is the graph's treewidth. Many problems that are NP-hard in general can be solved
+// by dynamic programming over the bags in time exponential only in the width, so they're easy on graphs of low treewidth: trees (width 1), series-parallel graphs (2), and
+// many road networks and circuits.
+//
+// Bags are indexed from 0, and Tree is an undirected graph with a GonumNode for each bag; it's connected even if the graph isn't.
+type TreeDecomposition struct {
+	Bags  [][]Node
+	Tree  *GonumGraph
+	Width int
+}
+
+// Builds a tree decomposition of the undirected graph underlying graph (directions are ignored) by eliminating nodes one at a time in the order the heuristic chooses: each
+// eliminated node's bag is itself and its remaining neighbors, which are then joined into a clique. The bag hangs from the bag of whichever of those neighbors is eliminated first.
+// Finding the treewidth is NP-hard, but both heuristics find decompositions at or near it on most graphs in practice[1]. Takes O(n²) time with MinDegree, more with MinFillIn.
+//
+// [1] H. L. Bodlaender and A. M. C. A. Koster, "Treewidth computations I. Upper bounds", Information and Computation 208 (2010)
+func NewTreeDecomposition(graph Graph, heuristic EliminationHeuristic) *TreeDecomposition {
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	adj := make(map[int]map[int]bool, len(nodes))
+	byNodeID := make(map[int]Node, len(nodes))
+	for _, node := range nodes {
+		byNodeID[node.ID()] = node
+		adj[node.ID()] = make(map[int]bool)
+		neighbors, _ := treeNeighbors(graph, node)
+		for _, neighbor := range neighbors {
+			adj[node.ID()][neighbor.ID()] = true
+		}
+	}
+
+	// The number of edges eliminating v would add between its neighbors
+	fill := func(v int) int {
+		missing := 0
+		for a := range adj[v] {
+			for b := range adj[v] {
+				if a < b && !adj[a][b] {
+					missing++
+				}
+			}
+		}
+		return missing
+	}
+
+	td := &TreeDecomposition{Tree: NewGonumGraph(false)}
+	step := make(map[int]int, len(nodes)) // When each node was eliminated, which is also its bag's index
+	var bagNeighbors [][]int              // Each bag's neighbors that are eliminated later
+	for len(step) < len(nodes) {
+		v, best := -1, 0
+		for _, node := range nodes {
+			if _, gone := step[node.ID()]; gone {
+				continue
+			}
+			score := len(adj[node.ID()])
+			if heuristic == MinFillIn {
+				score = fill(node.ID())
+			}
+			if v == -1 || score < best {
+				v, best = node.ID(), score
+			}
+		}
+
+		bag := []Node{byNodeID[v]}
+		var later []int
+		for w := range adj[v] {
+			bag = append(bag, byNodeID[w])
+			later = append(later, w)
+			delete(adj[w], v)
+			for x := range adj[v] {
+				if x != w {
+					adj[w][x] = true
+				}
+			}
+		}
+		delete(adj, v)
+		sort.Sort(byID(bag))
+		step[v] = len(td.Bags)
+		td.Bags = append(td.Bags, bag)
+		bagNeighbors = append(bagNeighbors, later)
+		if len(bag)-1 > td.Width {
+			td.Width = len(bag) - 1
+		}
+	}
+
+	for i := range td.Bags {
+		td.Tree.AddNode(GonumNode(i), nil)
+	}
+	lastRoot := -1
+	for i, later := range bagNeighbors {
+		parent := -1
+		for _, w := range later {
+			if parent == -1 || step[w] < parent {
+				parent = step[w]
+			}
+		}
+		// Bags without a parent are the roots of the decomposition of each connected component, which are chained together
+		if parent == -1 {
+			parent, lastRoot = lastRoot, i
+		}
+		if parent != -1 {
+			td.Tree.AddEdge(GonumEdge{H: GonumNode(i), T: GonumNode(parent)})
+		}
+	}
+
+	return td
+}
+
+// Orders the bags for dynamic programming from the leaves up: every bag comes after all its children, with the tree rooted at bag 0, and parent holds each bag's parent (-1 for
+// the root). A typical dynamic program computes a table for each bag in this order from its own nodes and its children's tables, and reads the answer off the root's table.
+func (td *TreeDecomposition) PostOrder() (order []int, parent []int) {
+	n := len(td.Bags)
+	parent = make([]int, n)
+	if n == 0 {
+		return nil, parent
+	}
+
+	parent[0] = -1
+	preorder := []int{0}
+	for i := 0; i < len(preorder); i++ {
+		bag := preorder[i]
+		for _, next := range td.Tree.Successors(GonumNode(bag)) {
+			if next.ID() != parent[bag] {
+				parent[next.ID()] = bag
+				preorder = append(preorder, next.ID())
+			}
+		}
+	}
+
+	order = make([]int, n)
+	for i, bag := range preorder {
+		order[n-1-i] = bag
+	}
+	return order, parent
+}
+
+// The heaviest set of pairwise non-adjacent nodes of the undirected graph underlying graph, and its weight, found exactly by dynamic programming over td, which must be a tree
+// decomposition of graph. Weight gives each node's weight; if it's nil every node weighs 1, which gives a maximum independent set. This is NP-hard in general, but takes
+// O(4^w n) time here for a decomposition of width w: for each bag, the best weight of the subtree below it for each independent subset of the bag. Returns ErrTreewidthTooLarge if
+// the width is more than 20.
+func MaximumWeightIndependentSet(graph Graph, td *TreeDecomposition, Weight func(Node) float64) (set []Node, weight float64, err error) {
+	if td.Width > 20 {
+		return nil, 0, ErrTreewidthTooLarge
+	}
+	if Weight == nil {
+		Weight = func(Node) float64 { return 1 }
+	}
+
+	order, parent := td.PostOrder()
+	children := make([][]int, len(td.Bags))
+	for _, bag := range order {
+		if parent[bag] != -1 {
+			children[parent[bag]] = append(children[parent[bag]], bag)
+		}
+	}
+
+	// Within each bag nodes are numbered by position, and sets are bitmasks of positions
+	positions := make([]map[int]uint, len(td.Bags))
+	for i, bag := range td.Bags {
+		positions[i] = make(map[int]uint, len(bag))
+		for j, node := range bag {
+			positions[i][node.ID()] = uint(j)
+		}
+	}
+	maskWeight := func(bag int, mask uint) float64 {
+		w := 0.0
+		for j, node := range td.Bags[bag] {
+			if mask&(1<<uint(j)) != 0 {
+				w += Weight(node)
+			}
+		}
+		return w
+	}
+	// The part of mask (a set in bag from) that's also in bag to, as a set in to
+	project := func(from, to int, mask uint) uint {
+		projected := uint(0)
+		for j, node := range td.Bags[from] {
+			if k, ok := positions[to][node.ID()]; ok && mask&(1<<uint(j)) != 0 {
+				projected |= 1 << k
+			}
+		}
+		return projected
+	}
+
+	tables := make([]map[uint]float64, len(td.Bags))
+	choices := make([]map[uint][]uint, len(td.Bags)) // The set chosen in each child, for each set in the bag
+	for _, bag := range order {
+		nodes := td.Bags[bag]
+		adjacent := make([]uint, len(nodes))
+		for j, node := range nodes {
+			neighbors, _ := treeNeighbors(graph, node)
+			for _, neighbor := range neighbors {
+				if k, ok := positions[bag][neighbor.ID()]; ok {
+					adjacent[j] |= 1 << k
+				}
+			}
+		}
+
+		// The best of each child's table for each set it shares with this bag, less the weight of the shared nodes, which this bag counts
+		best := make([]map[uint]float64, len(children[bag]))
+		bestMask := make([]map[uint]uint, len(children[bag]))
+		for c, child := range children[bag] {
+			best[c], bestMask[c] = make(map[uint]float64), make(map[uint]uint)
+			for mask, value := range tables[child] {
+				shared := project(child, bag, mask)
+				value -= maskWeight(bag, shared)
+				if old, ok := best[c][shared]; !ok || value > old {
+					best[c][shared], bestMask[c][shared] = value, mask
+				}
+			}
+		}
+
+		tables[bag], choices[bag] = make(map[uint]float64), make(map[uint][]uint)
+	masks:
+		for mask := uint(0); mask < 1<<uint(len(nodes)); mask++ {
+			for j := range nodes {
+				if mask&(1<<uint(j)) != 0 && adjacent[j]&mask != 0 {
+					continue masks
+				}
+			}
+
+			value := maskWeight(bag, mask)
+			chosen := make([]uint, len(children[bag]))
+			for c, child := range children[bag] {
+				// The child's set must agree with this one on the nodes they share
+				shared := project(child, bag, project(bag, child, mask))
+				v, ok := best[c][shared]
+				if !ok {
+					continue masks
+				}
+				value += v
+				chosen[c] = bestMask[c][shared]
+			}
+			tables[bag][mask], choices[bag][mask] = value, chosen
+		}
+	}
+
+	if len(order) == 0 {
+		return nil, 0, nil
+	}
+
+	// Read the sets off from the root down
+	root := order[len(order)-1]
+	var rootMask uint
+	weight = -1
+	for mask, value := range tables[root] {
+		if value > weight || value == weight && mask < rootMask {
+			rootMask, weight = mask, value
+		}
+	}
+	type bagSet struct {
+		bag  int
+		mask uint
+	}
+	inSet := make(map[int]Node)
+	stack := []bagSet{{root, rootMask}}
+	for len(stack) != 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for j, node := range td.Bags[top.bag] {
+			if top.mask&(1<<uint(j)) != 0 {
+				inSet[node.ID()] = node
+			}
+		}
+		for c, child := range children[top.bag] {
+			stack = append(stack, bagSet{child, choices[top.bag][top.mask][c]})
+		}
+	}
+
+	for _, node := range inSet {
+		set = append(set, node)
+	}
+	sort.Sort(byID(set))
+
+	return set, weight, nil
+}
@@ -0,0 +1,157 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Checks the three properties of a tree decomposition of g
+func checkTreeDecomposition(t *testing.T, name string, g graph.Graph, td *graph.TreeDecomposition) {
+	if len(td.Bags) > 0 && !graph.IsTree(td.Tree) {
+		t.Errorf("%s: the bags don't form a tree", name)
+	}
+	width := 0
+	inBags := make(map[int][]int)
+	for i, bag := range td.Bags {
+		if len(bag)-1 > width {
+			width = len(bag) - 1
+		}
+		for _, node := range bag {
+			inBags[node.ID()] = append(inBags[node.ID()], i)
+		}
+	}
+	if width != td.Width {
+		t.Errorf("%s: reported width %d, the bags have width %d", name, td.Width, width)
+	}
+
+	for _, node := range g.NodeList() {
+		bags := inBags[node.ID()]
+		if len(bags) == 0 {
+			t.Errorf("%s: node %v is in no bag", name, node)
+			continue
+		}
+		// The bags containing node must be connected in the tree
+		in := make(map[int]bool)
+		for _, bag := range bags {
+			in[bag] = true
+		}
+		seen := map[int]bool{bags[0]: true}
+		stack := []int{bags[0]}
+		for len(stack) != 0 {
+			bag := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, next := range td.Tree.Successors(graph.GonumNode(bag)) {
+				if in[next.ID()] && !seen[next.ID()] {
+					seen[next.ID()] = true
+					stack = append(stack, next.ID())
+				}
+			}
+		}
+		if len(seen) != len(bags) {
+			t.Errorf("%s: the bags containing node %v aren't connected", name, node)
+		}
+	}
+
+	for _, edge := range g.EdgeList() {
+		covered := false
+		for _, i := range inBags[edge.Head().ID()] {
+			for _, j := range inBags[edge.Tail().ID()] {
+				covered = covered || i == j
+			}
+		}
+		if !covered {
+			t.Errorf("%s: edge %v is in no bag", name, edge)
+		}
+	}
+}
+
+func TestTreeDecomposition(t *testing.T) {
+	for _, heuristic := range []graph.EliminationHeuristic{graph.MinDegree, graph.MinFillIn} {
+		tree := randomTree(50, rand.New(rand.NewSource(1)))
+		td := graph.NewTreeDecomposition(tree, heuristic)
+		checkTreeDecomposition(t, "tree", tree, td)
+		if td.Width != 1 {
+			t.Errorf("Expected a tree to have width 1, got %d", td.Width)
+		}
+
+		grid := graph.NewTileGraph(4, 10, true)
+		td = graph.NewTreeDecomposition(grid, heuristic)
+		checkTreeDecomposition(t, "grid", grid, td)
+		if td.Width < 4 || td.Width > 6 {
+			t.Errorf("Expected a 4x10 grid, of treewidth 4, to get a decomposition of width 4 to 6, got %d", td.Width)
+		}
+
+		// Two components, and an isolated node
+		random := graph.NewGonumGraph(false)
+		graph.GnpRandomGraph(random, 40, 0.08, false, rand.New(rand.NewSource(2)))
+		random.AddNode(graph.GonumNode(100), []graph.Node{graph.GonumNode(101), graph.GonumNode(102)})
+		random.AddNode(graph.GonumNode(200), nil)
+		checkTreeDecomposition(t, "random", random, graph.NewTreeDecomposition(random, heuristic))
+	}
+
+	if td := graph.NewTreeDecomposition(graph.NewGonumGraph(false), graph.MinDegree); len(td.Bags) != 0 || td.Width != 0 {
+		t.Errorf("Expected no bags for the empty graph, got %v", td.Bags)
+	}
+}
+
+func TestMaximumWeightIndependentSet(t *testing.T) {
+	src := rand.New(rand.NewSource(3))
+	for trial := 0; trial < 20; trial++ {
+		g := graph.NewGonumGraph(false)
+		graph.GnpRandomGraph(g, 12, 0.25, false, src)
+		nodes := g.NodeList()
+		weights := make(map[int]float64)
+		for _, node := range nodes {
+			weights[node.ID()] = float64(1 + src.Intn(10))
+		}
+		Weight := func(node graph.Node) float64 { return weights[node.ID()] }
+
+		best := 0.0
+		for mask := 0; mask < 1<<uint(len(nodes)); mask++ {
+			w, independent := 0.0, true
+			for i, u := range nodes {
+				if mask&(1<<uint(i)) == 0 {
+					continue
+				}
+				w += Weight(u)
+				for j, v := range nodes {
+					if mask&(1<<uint(j)) != 0 && g.IsAdjacent(u, v) {
+						independent = false
+					}
+				}
+			}
+			if independent {
+				best = math.Max(best, w)
+			}
+		}
+
+		set, weight, err := graph.MaximumWeightIndependentSet(g, graph.NewTreeDecomposition(g, graph.MinFillIn), Weight)
+		total := 0.0
+		for i, u := range set {
+			total += Weight(u)
+			for _, v := range set[i+1:] {
+				if g.IsAdjacent(u, v) {
+					t.Errorf("Nodes %v and %v in the independent set are adjacent", u, v)
+				}
+			}
+		}
+		if err != nil || weight != best || total != weight {
+			t.Errorf("Expected an independent set of weight %v, got %v weighing %v (reported %v), %v", best, set, total, weight, err)
+		}
+	}
+
+	// On a tree, far too big for brute force, an independent set covers at least half the nodes
+	tree := randomTree(1000, src)
+	if set, size, _ := graph.MaximumWeightIndependentSet(tree, graph.NewTreeDecomposition(tree, graph.MinDegree), nil); len(set) < 500 || float64(len(set)) != size {
+		t.Errorf("Expected an independent set of at least half a tree's 1000 nodes, got %d", len(set))
+	}
+
+	dense := graph.NewGonumGraph(false)
+	graph.CompleteGraph(dense, 25, false)
+	if _, _, err := graph.MaximumWeightIndependentSet(dense, graph.NewTreeDecomposition(dense, graph.MinDegree), nil); err != graph.ErrTreewidthTooLarge {
+		t.Errorf("Expected ErrTreewidthTooLarge for a complete graph of 25 nodes, got %v", err)
+	}
+}
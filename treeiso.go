@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Computes the canonical encoding of the tree containing root, hung from root, by the algorithm of Aho, Hopcroft and Ullman[1]: two rooted trees are isomorphic exactly when
+// their encodings are equal, so the encoding can key a map to deduplicate trees, such as parse trees or phylogenies. The encoding is a balanced parenthesis string, a node being
+// "(" followed by its children's encodings and ")", with the children in a canonical order.
+//
+// Rather than sort the children by their encodings, which takes time proportional to n times the depth, the subtrees are named level by level from the bottom up: each node's
+// children's names, sorted, are its key, and the keys on a level are ranked to give the names. That takes O(n log n). Returns ErrNotTree as RootTree does.
+//
+// [1] A. V. Aho, J. E. Hopcroft and J. D. Ullman, "The Design and Analysis of Computer Algorithms", Addison-Wesley (1974)
+func RootedTreeEncoding(graph Graph, root Node) (string, error) {
+	tree, err := RootTree(graph, root)
+	if err != nil {
+		return "", err
+	}
+
+	levels := [][]Node{}
+	for _, node := range tree.Order {
+		d := tree.Depth[node.ID()]
+		if d == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[d] = append(levels[d], node)
+	}
+
+	name := make(map[int]int, len(tree.Order))
+	keys := make(map[int][]int, len(tree.Order))
+	for d := len(levels) - 1; d >= 0; d-- {
+		level := levels[d]
+		for _, node := range level {
+			key := make([]int, 0, len(tree.Children(node)))
+			for _, child := range tree.Children(node) {
+				key = append(key, name[child.ID()])
+			}
+			sort.Ints(key)
+			keys[node.ID()] = key
+		}
+
+		sort.Sort(byKeys{level, keys})
+		for i, node := range level {
+			name[node.ID()] = i
+			if i > 0 && !lessKeys(keys[level[i-1].ID()], keys[node.ID()]) {
+				name[node.ID()] = name[level[i-1].ID()]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	var encode func(Node)
+	encode = func(node Node) {
+		children := append([]Node(nil), tree.Children(node)...)
+		sort.Sort(byKey{len(children), func(i int) float64 { return float64(name[children[i].ID()]) }, func(i, j int) { children[i], children[j] = children[j], children[i] }})
+		buf.WriteByte('(')
+		for _, child := range children {
+			encode(child)
+		}
+		buf.WriteByte(')')
+	}
+	encode(root)
+
+	return buf.String(), nil
+}
+
+// Computes the canonical encoding of graph as an unrooted tree, such that two trees are isomorphic exactly when their encodings are equal. A tree has one center (the middle of
+// its longest paths) or two adjacent ones, so the encoding is RootedTreeEncoding from the center, or the smaller of the two. Returns ErrNotTree if graph isn't a tree.
+func TreeEncoding(graph Graph) (string, error) {
+	if !IsTree(graph) {
+		return "", ErrNotTree
+	}
+
+	var encoding string
+	for _, center := range treeCenters(graph) {
+		enc, _ := RootedTreeEncoding(graph, center)
+		if encoding == "" || enc < encoding {
+			encoding = enc
+		}
+	}
+
+	return encoding, nil
+}
+
+// Whether the trees containing root1 in g1 and root2 in g2 are isomorphic by a mapping taking root1 to root2. False if either isn't a tree.
+func RootedTreesIsomorphic(g1 Graph, root1 Node, g2 Graph, root2 Node) bool {
+	enc1, err1 := RootedTreeEncoding(g1, root1)
+	enc2, err2 := RootedTreeEncoding(g2, root2)
+	return err1 == nil && err2 == nil && enc1 == enc2
+}
+
+// Whether the trees g1 and g2 are isomorphic. False if either isn't a tree; for any other graphs, see Isomorphic.
+func TreesIsomorphic(g1, g2 Graph) bool {
+	enc1, err1 := TreeEncoding(g1)
+	enc2, err2 := TreeEncoding(g2)
+	return err1 == nil && err2 == nil && enc1 == enc2
+}
+
+// The one or two centers of a tree, found by stripping leaves layer by layer until at most two nodes are left
+func treeCenters(graph Graph) []Node {
+	nodes := graph.NodeList()
+	degree := make(map[int]int, len(nodes))
+	neighbors := make(map[int][]Node, len(nodes))
+	var leaves []Node
+	for _, node := range nodes {
+		neighbors[node.ID()], _ = treeNeighbors(graph, node)
+		degree[node.ID()] = len(neighbors[node.ID()])
+		if degree[node.ID()] <= 1 {
+			leaves = append(leaves, node)
+		}
+	}
+
+	left := len(nodes)
+	for left > 2 {
+		left -= len(leaves)
+		var next []Node
+		for _, leaf := range leaves {
+			for _, neighbor := range neighbors[leaf.ID()] {
+				if degree[neighbor.ID()]--; degree[neighbor.ID()] == 1 {
+					next = append(next, neighbor)
+				}
+			}
+		}
+		leaves = next
+	}
+
+	return leaves
+}
+
+// Sorts nodes by their keys, lexicographically
+type byKeys struct {
+	nodes []Node
+	keys  map[int][]int
+}
+
+func (bk byKeys) Len() int {
+	return len(bk.nodes)
+}
+
+func (bk byKeys) Less(i, j int) bool {
+	return lessKeys(bk.keys[bk.nodes[i].ID()], bk.keys[bk.nodes[j].ID()])
+}
+
+func (bk byKeys) Swap(i, j int) {
+	bk.nodes[i], bk.nodes[j] = bk.nodes[j], bk.nodes[i]
+}
+
+func lessKeys(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
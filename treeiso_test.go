@@ -0,0 +1,76 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A copy of tree with its nodes renumbered by perm
+func relabel(tree graph.Graph, perm []int) *graph.GonumGraph {
+	dst := graph.NewGonumGraph(false)
+	for _, node := range tree.NodeList() {
+		dst.AddNode(graph.GonumNode(perm[node.ID()]), nil)
+	}
+	for _, edge := range tree.EdgeList() {
+		dst.AddEdge(graph.GonumEdge{H: graph.GonumNode(perm[edge.Head().ID()]), T: graph.GonumNode(perm[edge.Tail().ID()])})
+	}
+	return dst
+}
+
+func TestTreeEncoding(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		tree := randomTree(60, src)
+		perm := src.Perm(60)
+		copied := relabel(tree, perm)
+
+		if !graph.TreesIsomorphic(tree, copied) {
+			t.Errorf("A tree isn't isomorphic to a relabeled copy of itself")
+		}
+		root := src.Intn(60)
+		if !graph.RootedTreesIsomorphic(tree, graph.GonumNode(root), copied, graph.GonumNode(perm[root])) {
+			t.Errorf("A rooted tree isn't isomorphic to a relabeled copy of itself")
+		}
+
+		other := randomTree(60, src)
+		enc1, _ := graph.TreeEncoding(tree)
+		enc2, _ := graph.TreeEncoding(other)
+		if (enc1 == enc2) != graph.Isomorphic(tree, other, nil, nil) {
+			t.Errorf("Encodings disagree with VF2 on whether two random trees are isomorphic")
+		}
+	}
+
+	// Counting the isomorphism classes of small trees by their encodings: there are 6 trees on 6 nodes and 9 rooted trees on 5
+	unrooted, rooted := make(map[string]bool), make(map[string]bool)
+	for trial := 0; trial < 2000; trial++ {
+		enc, err := graph.TreeEncoding(randomTree(6, src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		unrooted[enc] = true
+
+		small := randomTree(5, src)
+		enc, _ = graph.RootedTreeEncoding(small, graph.GonumNode(src.Intn(5)))
+		rooted[enc] = true
+	}
+	if len(unrooted) != 6 || len(rooted) != 9 {
+		t.Errorf("Expected 6 trees on 6 nodes and 9 rooted trees on 5, found %d and %d", len(unrooted), len(rooted))
+	}
+
+	path := graph.NewGonumGraph(false)
+	graph.PathGraph(path, 3, false)
+	if enc, _ := graph.RootedTreeEncoding(path, graph.GonumNode(0)); enc != "((()))" {
+		t.Errorf("Expected a path rooted at its end to encode as ((())), got %s", enc)
+	}
+	if enc, _ := graph.TreeEncoding(path); enc != "(()())" {
+		t.Errorf("Expected a path to encode as (()()) from its center, got %s", enc)
+	}
+
+	cycle := graph.NewGonumGraph(false)
+	graph.CycleGraph(cycle, 4, false)
+	if _, err := graph.TreeEncoding(cycle); err != graph.ErrNotTree {
+		t.Errorf("Expected ErrNotTree encoding a cycle, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package graph
+
+import (
+	"errors"
+	"sort"
+)
+
+// Returned by algorithms that need a tree when given a graph with a cycle, or one that isn't connected.
+var ErrNotTree = errors.New("Graph is not a tree")
+
+// Whether graph has no cycles, looking at the simple undirected graph underlying it: edge directions are ignored, an edge in both directions counts once, and a self loop is a
+// cycle. Each connected component of a forest is a tree.
+func IsForest(graph Graph) bool {
+	nodes := graph.NodeList()
+	edges := 0
+	for _, node := range nodes {
+		neighbors, loop := treeNeighbors(graph, node)
+		if loop {
+			return false
+		}
+		edges += len(neighbors)
+	}
+
+	// A forest has exactly as many edges as nodes less components
+	return edges/2 == len(nodes)-len(treeComponents(graph, nodes))
+}
+
+// Whether graph is a tree: a forest, as IsForest decides, that is connected and not empty.
+func IsTree(graph Graph) bool {
+	nodes := graph.NodeList()
+	return len(nodes) != 0 && IsForest(graph) && len(treeComponents(graph, nodes)) == 1
+}
+
+// A RootedTree is a tree hung from one of its nodes, with each node's parent, depth and subtree size, the basic tables for divide and conquer and dynamic programming on trees.
+type RootedTree struct {
+	Root   Node
+	Parent map[int]Node // By node ID; the root has none
+	Depth  map[int]int  // Edges between each node and the root
+	Size   map[int]int  // Nodes in each node's subtree, itself included
+	Order  []Node       // Every node, each after its parent (breadth first from the root), so walking it backwards visits children before parents
+
+	children map[int][]Node
+}
+
+// Roots the tree containing root at root. The rest of the graph is ignored, so each tree of a forest can be rooted on its own; ErrNotTree is returned if root's component of the
+// underlying undirected graph has a cycle, or if root isn't in the graph. Children are listed in order of ID.
+func RootTree(graph Graph, root Node) (*RootedTree, error) {
+	if !graph.NodeExists(root) {
+		return nil, ErrNotTree
+	}
+
+	tree := &RootedTree{Root: root, Parent: make(map[int]Node), Depth: map[int]int{root.ID(): 0}, Size: make(map[int]int), children: make(map[int][]Node)}
+	tree.Order = []Node{root}
+	for i := 0; i < len(tree.Order); i++ {
+		node := tree.Order[i]
+		neighbors, loop := treeNeighbors(graph, node)
+		if loop {
+			return nil, ErrNotTree
+		}
+		for _, neighbor := range neighbors {
+			if parent, ok := tree.Parent[node.ID()]; ok && parent.ID() == neighbor.ID() {
+				continue
+			}
+			if _, seen := tree.Depth[neighbor.ID()]; seen {
+				return nil, ErrNotTree
+			}
+			tree.Parent[neighbor.ID()] = node
+			tree.Depth[neighbor.ID()] = tree.Depth[node.ID()] + 1
+			tree.children[node.ID()] = append(tree.children[node.ID()], neighbor)
+			tree.Order = append(tree.Order, neighbor)
+		}
+	}
+
+	for i := len(tree.Order) - 1; i >= 0; i-- {
+		node := tree.Order[i]
+		tree.Size[node.ID()]++
+		if parent, ok := tree.Parent[node.ID()]; ok {
+			tree.Size[parent.ID()] += tree.Size[node.ID()]
+		}
+	}
+
+	return tree, nil
+}
+
+// The children of node, in order of ID. The slice is the tree's own.
+func (tree *RootedTree) Children(node Node) []Node {
+	return tree.children[node.ID()]
+}
+
+// Finds the centroid decomposition of a tree[1]: its centroid (a node whose removal leaves no component of more than half the nodes) is the root, and the centroids of the
+// components left by removing it are its children, recursively. The result has depth O(log n), and every path in the tree passes through the shallowest centroid on it, so a
+// problem about all paths can be solved by handling, at each centroid, the paths through it: O(n log n) work in all for linear work per component.
+//
+// In the returned RootedTree, Parent and Depth describe the centroid tree rather than graph, and each Size is the number of nodes in the component the node was the centroid of.
+// Returns ErrNotTree if graph isn't a tree.
+//
+// [1] C. Jordan, "Sur les assemblages de lignes", Journal für die reine und angewandte Mathematik 70 (1869)
+func CentroidDecomposition(graph Graph) (*RootedTree, error) {
+	if !IsTree(graph) {
+		return nil, ErrNotTree
+	}
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	neighbors := make(map[int][]Node, len(nodes))
+	for _, node := range nodes {
+		neighbors[node.ID()], _ = treeNeighbors(graph, node)
+	}
+
+	decomposition := &RootedTree{Parent: make(map[int]Node), Depth: make(map[int]int), Size: make(map[int]int), children: make(map[int][]Node)}
+	removed := make(map[int]bool, len(nodes))
+
+	// Each component is given by any of its nodes and the centroid it hangs from, if any
+	type component struct {
+		node, parent Node
+	}
+	queue := []component{{nodes[0], nil}}
+	for len(queue) != 0 {
+		comp := queue[0]
+		queue = queue[1:]
+
+		// Subtree sizes within the component, rooted at any of its nodes, then walk from there towards the heavy side until no side is heavier than half
+		order, parent := []Node{comp.node}, map[int]Node{}
+		for i := 0; i < len(order); i++ {
+			for _, next := range neighbors[order[i].ID()] {
+				if p, ok := parent[order[i].ID()]; removed[next.ID()] || ok && p.ID() == next.ID() {
+					continue
+				}
+				parent[next.ID()] = order[i]
+				order = append(order, next)
+			}
+		}
+		size := make(map[int]int, len(order))
+		for i := len(order) - 1; i >= 0; i-- {
+			size[order[i].ID()]++
+			if p, ok := parent[order[i].ID()]; ok {
+				size[p.ID()] += size[order[i].ID()]
+			}
+		}
+
+		n, centroid := len(order), comp.node
+		for moved := true; moved; {
+			moved = false
+			for _, next := range neighbors[centroid.ID()] {
+				if p, ok := parent[centroid.ID()]; removed[next.ID()] || ok && p.ID() == next.ID() {
+					continue
+				}
+				if size[next.ID()] > n/2 {
+					centroid, moved = next, true
+					break
+				}
+			}
+		}
+
+		removed[centroid.ID()] = true
+		decomposition.Size[centroid.ID()] = n
+		decomposition.Order = append(decomposition.Order, centroid)
+		if comp.parent == nil {
+			decomposition.Root = centroid
+		} else {
+			decomposition.Parent[centroid.ID()] = comp.parent
+			decomposition.Depth[centroid.ID()] = decomposition.Depth[comp.parent.ID()] + 1
+			decomposition.children[comp.parent.ID()] = append(decomposition.children[comp.parent.ID()], centroid)
+		}
+		for _, next := range neighbors[centroid.ID()] {
+			if !removed[next.ID()] {
+				queue = append(queue, component{next, centroid})
+			}
+		}
+	}
+
+	return decomposition, nil
+}
+
+// The distinct neighbors of node in the undirected graph underlying graph, in order of ID, and whether it has a self loop
+func treeNeighbors(graph Graph, node Node) (neighbors []Node, loop bool) {
+	seen := make(map[int]bool)
+	for _, list := range [2][]Node{graph.Successors(node), graph.Predecessors(node)} {
+		for _, other := range list {
+			if other.ID() == node.ID() {
+				loop = true
+			} else if !seen[other.ID()] {
+				seen[other.ID()] = true
+				neighbors = append(neighbors, other)
+			}
+		}
+	}
+	sort.Sort(byID(neighbors))
+
+	return neighbors, loop
+}
+
+// The connected components of the undirected graph underlying graph, each given by its first node
+func treeComponents(graph Graph, nodes []Node) (roots []Node) {
+	seen := make(map[int]bool, len(nodes))
+	for _, node := range nodes {
+		if seen[node.ID()] {
+			continue
+		}
+		roots = append(roots, node)
+		seen[node.ID()] = true
+		stack := []Node{node}
+		for len(stack) != 0 {
+			curr := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			neighbors, _ := treeNeighbors(graph, curr)
+			for _, next := range neighbors {
+				if !seen[next.ID()] {
+					seen[next.ID()] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+	}
+
+	return roots
+}
@@ -0,0 +1,140 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A uniformly attached random tree: node i hangs from a random earlier node
+func randomTree(n int, src *rand.Rand) *graph.GonumGraph {
+	tree := graph.NewGonumGraph(false)
+	tree.AddNode(graph.GonumNode(0), nil)
+	for i := 1; i < n; i++ {
+		tree.AddNode(graph.GonumNode(i), []graph.Node{graph.GonumNode(src.Intn(i))})
+	}
+	return tree
+}
+
+func TestIsTree(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	tree := randomTree(50, src)
+	if !graph.IsTree(tree) || !graph.IsForest(tree) {
+		t.Error("A random tree isn't a tree")
+	}
+
+	forest := randomTree(20, src)
+	forest.AddNode(graph.GonumNode(100), []graph.Node{graph.GonumNode(101)})
+	if graph.IsTree(forest) || !graph.IsForest(forest) {
+		t.Error("Expected two trees to be a forest but not a tree")
+	}
+
+	cycle := graph.NewGonumGraph(false)
+	graph.CycleGraph(cycle, 5, false)
+	if graph.IsTree(cycle) || graph.IsForest(cycle) {
+		t.Error("A cycle is a forest")
+	}
+
+	// Direction is ignored, and an edge both ways is one edge
+	directed := graph.NewGonumGraph(true)
+	graph.PathGraph(directed, 5, true)
+	directed.AddEdge(graph.GonumEdge{H: graph.GonumNode(3), T: graph.GonumNode(2)})
+	if !graph.IsTree(directed) {
+		t.Error("A directed path with one edge doubled isn't a tree")
+	}
+	directed.AddEdge(graph.GonumEdge{H: graph.GonumNode(4), T: graph.GonumNode(4)})
+	if graph.IsForest(directed) {
+		t.Error("A graph with a self loop is a forest")
+	}
+
+	if graph.IsTree(graph.NewGonumGraph(false)) {
+		t.Error("The empty graph is a tree")
+	}
+}
+
+func TestRootTree(t *testing.T) {
+	tree := randomTree(100, rand.New(rand.NewSource(2)))
+	root := graph.GonumNode(37)
+	rooted, err := graph.RootTree(tree, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rooted.Size[root.ID()] != 100 || len(rooted.Order) != 100 || rooted.Order[0] != graph.Node(root) {
+		t.Errorf("Expected the root's subtree to hold all 100 nodes, got %d", rooted.Size[root.ID()])
+	}
+	for i, node := range rooted.Order {
+		parent, ok := rooted.Parent[node.ID()]
+		if node.ID() == root.ID() {
+			if ok {
+				t.Errorf("The root has a parent, %v", parent)
+			}
+			continue
+		}
+		if !tree.IsAdjacent(node, parent) || rooted.Depth[node.ID()] != rooted.Depth[parent.ID()]+1 {
+			t.Errorf("Node %v has parent %v at depth %d, itself at depth %d", node, parent, rooted.Depth[parent.ID()], rooted.Depth[node.ID()])
+		}
+		size := 1
+		for _, child := range rooted.Children(node) {
+			size += rooted.Size[child.ID()]
+		}
+		if size != rooted.Size[node.ID()] {
+			t.Errorf("Node %v has subtree size %d, but its children's add up to %d", node, rooted.Size[node.ID()], size-1)
+		}
+		for _, earlier := range rooted.Order[:i] {
+			if earlier.ID() == parent.ID() {
+				parent = nil
+				break
+			}
+		}
+		if parent != nil {
+			t.Errorf("Node %v comes before its parent in the order", node)
+		}
+	}
+
+	cycle := graph.NewGonumGraph(false)
+	graph.CycleGraph(cycle, 5, false)
+	if _, err := graph.RootTree(cycle, graph.GonumNode(0)); err != graph.ErrNotTree {
+		t.Errorf("Expected ErrNotTree rooting a cycle, got %v", err)
+	}
+}
+
+func TestCentroidDecomposition(t *testing.T) {
+	path := graph.NewGonumGraph(false)
+	graph.PathGraph(path, 7, false)
+	decomposition, err := graph.CentroidDecomposition(path)
+	if err != nil || decomposition.Root.ID() != 3 {
+		t.Fatalf("Expected the middle of a path of 7 to be its centroid, got %v, %v", decomposition, err)
+	}
+
+	tree := randomTree(200, rand.New(rand.NewSource(3)))
+	decomposition, err = graph.CentroidDecomposition(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decomposition.Order) != 200 || decomposition.Size[decomposition.Root.ID()] != 200 {
+		t.Fatalf("Expected every node to be a centroid once, got %d", len(decomposition.Order))
+	}
+	for _, node := range decomposition.Order {
+		if decomposition.Depth[node.ID()] > 8 {
+			t.Errorf("Centroid %v is at depth %d, deeper than log2(200)", node, decomposition.Depth[node.ID()])
+		}
+		size := 1
+		for _, child := range decomposition.Children(node) {
+			size += decomposition.Size[child.ID()]
+			if 2*decomposition.Size[child.ID()] > decomposition.Size[node.ID()] {
+				t.Errorf("Centroid %v leaves a component of %d of its %d nodes", node, decomposition.Size[child.ID()], decomposition.Size[node.ID()])
+			}
+		}
+		if size != decomposition.Size[node.ID()] {
+			t.Errorf("Centroid %v's component has %d nodes, but its children's components add up to %d", node, decomposition.Size[node.ID()], size-1)
+		}
+	}
+
+	cycle := graph.NewGonumGraph(false)
+	graph.CycleGraph(cycle, 5, false)
+	if _, err := graph.CentroidDecomposition(cycle); err != graph.ErrNotTree {
+		t.Errorf("Expected ErrNotTree decomposing a cycle, got %v", err)
+	}
+}
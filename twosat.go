@@ -0,0 +1,117 @@
+package graph
+
+// A Literal is a boolean variable or its negation. Variable i, numbered from 0, is Literal(2*i), and its negation Literal(2*i+1); use Var and Not rather than counting on it.
+type Literal int
+
+// The literal that's true when variable i is.
+func Var(i int) Literal {
+	return Literal(2 * i)
+}
+
+// The negation of l.
+func Not(l Literal) Literal {
+	return l ^ 1
+}
+
+// The variable l is of.
+func (l Literal) Var() int {
+	return int(l) / 2
+}
+
+// Whether l is a negated variable.
+func (l Literal) Negated() bool {
+	return l&1 == 1
+}
+
+// A TwoSAT is a 2-satisfiability problem: a conjunction of clauses of two literals each, over boolean variables numbered from 0, which is to be satisfied. Unlike satisfiability
+// in general, 2-SAT is solved in linear time, by way of the implication graph: the clause (a ∨ b) is the implications ¬a ⇒ b and ¬b ⇒ a, and the problem is satisfiable
+// unless some variable implies its own negation and the other way around, so they're in the same strongly connected component[1].
+//
+// [1] B. Aspvall, M. F. Plass and R. E. Tarjan, "A linear-time algorithm for testing the truth of certain quantified boolean formulas", Information Processing Letters 8 (1979)
+type TwoSAT struct {
+	variables int
+	clauses   [][2]Literal
+}
+
+// Creates a 2-SAT problem with no clauses over the given number of variables.
+func NewTwoSAT(variables int) *TwoSAT {
+	return &TwoSAT{variables: variables}
+}
+
+// Adds the clause (a ∨ b). A clause with one literal, which must be true, is AddClause(a, a).
+func (s *TwoSAT) AddClause(a, b Literal) {
+	s.clauses = append(s.clauses, [2]Literal{a, b})
+}
+
+// Adds the clause a ⇒ b, which is (¬a ∨ b).
+func (s *TwoSAT) AddImplication(a, b Literal) {
+	s.AddClause(Not(a), b)
+}
+
+// Adds clauses making a and b unequal, which is (a ∨ b) ∧ (¬a ∨ ¬b).
+func (s *TwoSAT) AddXor(a, b Literal) {
+	s.AddClause(a, b)
+	s.AddClause(Not(a), Not(b))
+}
+
+// Returns the implication graph, which has a node for each literal, with the Literal as its ID, and an edge from ¬a to b and from ¬b to a for each clause (a ∨ b).
+func (s *TwoSAT) ImplicationGraph() *GonumGraph {
+	g := NewGonumGraph(true)
+	for l := 0; l < 2*s.variables; l++ {
+		g.AddNode(GonumNode(l), nil)
+	}
+	for _, clause := range s.clauses {
+		a, b := clause[0], clause[1]
+		g.AddEdge(GonumEdge{H: GonumNode(Not(a)), T: GonumNode(b)})
+		g.AddEdge(GonumEdge{H: GonumNode(Not(b)), T: GonumNode(a)})
+	}
+	return g
+}
+
+// Solves the problem, returning a satisfying assignment, indexed by variable, and true if there is one. A variable is set true exactly when its literal's component comes after
+// its negation's in topological order, so no implication ever leads from true to false.
+//
+// If there's no satisfying assignment, returns false with a witness: a cycle of implications from some literal x through ¬x back to x, each implied by the one before by a clause,
+// which makes x and ¬x both impossible.
+func (s *TwoSAT) Solve() (assignment []bool, witness []Literal, ok bool) {
+	g := s.ImplicationGraph()
+	_, _, componentOf := Condense(g)
+
+	assignment = make([]bool, s.variables)
+	for i := range assignment {
+		x, notX := componentOf[int(Var(i))].ID(), componentOf[int(Not(Var(i)))].ID()
+		if x == notX {
+			path := s.implications(g, Var(i), Not(Var(i)))
+			back := s.implications(g, Not(Var(i)), Var(i))
+			return nil, append(path, back[1:]...), false
+		}
+		assignment[i] = x > notX
+	}
+
+	return assignment, nil, true
+}
+
+// A shortest chain of implications from a to b, found by breadth first search of the implication graph
+func (s *TwoSAT) implications(g *GonumGraph, a, b Literal) []Literal {
+	parent := map[int]int{int(a): -1}
+	queue := []int{int(a)}
+	for len(queue) != 0 && queue[0] != int(b) {
+		u := queue[0]
+		queue = queue[1:]
+		for _, succ := range g.Successors(GonumNode(u)) {
+			if _, seen := parent[succ.ID()]; !seen {
+				parent[succ.ID()] = u
+				queue = append(queue, succ.ID())
+			}
+		}
+	}
+
+	var path []Literal
+	for l := int(b); l != -1; l = parent[l] {
+		path = append(path, Literal(l))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
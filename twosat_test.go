@@ -0,0 +1,96 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func satisfies(assignment []bool, clauses [][2]graph.Literal) bool {
+	value := func(l graph.Literal) bool {
+		return assignment[l.Var()] != l.Negated()
+	}
+	for _, clause := range clauses {
+		if !value(clause[0]) && !value(clause[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTwoSAT(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	satisfiable := 0
+	for trial := 0; trial < 200; trial++ {
+		n := 1 + src.Intn(6)
+		s := graph.NewTwoSAT(n)
+		var clauses [][2]graph.Literal
+		literal := func() graph.Literal {
+			l := graph.Var(src.Intn(n))
+			if src.Intn(2) == 0 {
+				l = graph.Not(l)
+			}
+			return l
+		}
+		for i := 0; i < 1+src.Intn(2*n+1); i++ {
+			clause := [2]graph.Literal{literal(), literal()}
+			s.AddClause(clause[0], clause[1])
+			clauses = append(clauses, clause)
+		}
+
+		possible := false
+		for mask := 0; mask < 1<<uint(n) && !possible; mask++ {
+			assignment := make([]bool, n)
+			for i := range assignment {
+				assignment[i] = mask&(1<<uint(i)) != 0
+			}
+			possible = satisfies(assignment, clauses)
+		}
+
+		assignment, witness, ok := s.Solve()
+		if ok != possible {
+			t.Fatalf("Solve says %v, but brute force says %v, for %v", ok, possible, clauses)
+		}
+		if ok {
+			satisfiable++
+			if !satisfies(assignment, clauses) {
+				t.Errorf("Assignment %v doesn't satisfy %v", assignment, clauses)
+			}
+			continue
+		}
+
+		// The witness must go from x to ¬x and back, by implications from the clauses
+		g := s.ImplicationGraph()
+		if len(witness) < 3 || witness[0] != witness[len(witness)-1] {
+			t.Fatalf("Witness %v isn't a cycle", witness)
+		}
+		hasNegation := false
+		for i, l := range witness {
+			hasNegation = hasNegation || l == graph.Not(witness[0])
+			if i > 0 && !g.IsSuccessor(graph.GonumNode(witness[i-1]), graph.GonumNode(l)) {
+				t.Errorf("Witness %v has %v ⇒ %v, which isn't implied", witness, witness[i-1], l)
+			}
+		}
+		if !hasNegation {
+			t.Errorf("Witness %v doesn't go through the negation of %v", witness, witness[0])
+		}
+	}
+	if satisfiable == 0 || satisfiable == 200 {
+		t.Errorf("Expected some satisfiable and some unsatisfiable problems, got %d satisfiable", satisfiable)
+	}
+}
+
+func TestTwoSATXor(t *testing.T) {
+	// An odd cycle of inequalities can't be satisfied, an even one can
+	for _, n := range []int{3, 4} {
+		s := graph.NewTwoSAT(n)
+		for i := 0; i < n; i++ {
+			s.AddXor(graph.Var(i), graph.Var((i+1)%n))
+		}
+		s.AddImplication(graph.Var(0), graph.Var(0))
+		if _, _, ok := s.Solve(); ok != (n%2 == 0) {
+			t.Errorf("Cycle of %d inequalities: expected satisfiable to be %v", n, n%2 == 0)
+		}
+	}
+}
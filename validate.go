@@ -0,0 +1,286 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// The kinds of problem Validate looks for.
+type IssueKind int
+
+const (
+	DuplicateNode      IssueKind = iota // NodeList has the same ID more than once
+	MissingNode                         // NodeList has a node NodeExists denies
+	DanglingEdge                        // An edge leads to or from a node that isn't in NodeList
+	InconsistentEdge                    // Successors, Predecessors, IsSuccessor, IsPredecessor and EdgeList don't agree about an edge
+	UndirectedOneWay                    // An undirected graph has an edge one way but not the other
+	DuplicateEdge                       // Successors or Predecessors lists the same node more than once
+	BadCost                             // An edge's cost is NaN or -Inf, which breaks every shortest path algorithm
+	SelfLoop                            // An edge from a node to itself, which is legal but often unintended
+	NegativeCost                        // An edge costs less than 0, which BellmanFordTree and JohnsonAllPairs handle, but Dijkstra's algorithm, A* and D*-Lite don't
+	Unreachable                         // The goal given to ValidatePlan can't be reached from the start, or either isn't in the graph
+	UnknownChangedNode                  // An edge given to ValidateChangedEdges touches a node that isn't in the graph
+)
+
+// How much an issue matters: an IssueError breaks the promises algorithms count on, while an IssueWarning is legal, but worth a look, since it's more often a mistake than not
+// or only some algorithms cope with it.
+type IssueSeverity int
+
+const (
+	IssueWarning IssueSeverity = iota
+	IssueError
+)
+
+// The severity of an issue of kind: IssueWarning for SelfLoop, NegativeCost and UnknownChangedNode, and IssueError for the rest.
+func (kind IssueKind) Severity() IssueSeverity {
+	switch kind {
+	case SelfLoop, NegativeCost, UnknownChangedNode:
+		return IssueWarning
+	}
+	return IssueError
+}
+
+// An Issue is a problem Validate found, with the nodes it involves: one node, or the two ends of an edge.
+type Issue struct {
+	Kind     IssueKind
+	Severity IssueSeverity // Kind.Severity(), for filtering without a switch on the kind
+	Nodes    []Node
+	Message  string
+}
+
+// A ValidationReport lists every problem Validate found with a graph, in order of the node they were found at.
+type ValidationReport struct {
+	Issues []Issue
+}
+
+// Whether no problems were found.
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// The issues of IssueError severity, e.g. to reject a graph that has any, while letting warnings through.
+func (r *ValidationReport) Errors() []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Severity == IssueError {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// The issues of kind.
+func (r *ValidationReport) Of(kind IssueKind) []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// Lists the issues, one per line.
+func (r *ValidationReport) String() string {
+	var buf bytes.Buffer
+	for _, issue := range r.Issues {
+		buf.WriteString(issue.Message)
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func (r *ValidationReport) add(kind IssueKind, nodes []Node, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Kind: kind, Severity: kind.Severity(), Nodes: nodes, Message: fmt.Sprintf(format, args...)})
+}
+
+// Checks that graph keeps the promises of the Graph interface that the algorithms in this package count on without checking: that its node list has no duplicates, that
+// Successors, Predecessors, the Is methods and EdgeList all describe the same edges, between nodes in the graph, both ways if it's undirected, and that if it's a Coster no cost
+// is NaN or -Inf. Self loops and negative costs are reported too, as warnings (see IssueSeverity). A custom Graph implementation should have no errors before it's trusted with
+// anything else, and the errors of a graph loaded from outside are a reason to refuse it.
+//
+// Takes O(n + m·d) time, d being the largest degree, since it checks every edge against the lists at both ends.
+func Validate(graph Graph) *ValidationReport {
+	report := &ValidationReport{}
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+
+	inGraph := make(map[int]Node, len(nodes))
+	for i, node := range nodes {
+		if i > 0 && nodes[i-1].ID() == node.ID() {
+			if i == 1 || nodes[i-2].ID() != node.ID() {
+				report.add(DuplicateNode, []Node{node}, "Node %d is listed more than once", node.ID())
+			}
+			continue
+		}
+		inGraph[node.ID()] = node
+		if !graph.NodeExists(node) {
+			report.add(MissingNode, []Node{node}, "Node %d is listed, but NodeExists says it isn't in the graph", node.ID())
+		}
+	}
+	nodes = uniqueByID(nodes)
+
+	contains := func(list []Node, node Node) bool {
+		for _, other := range list {
+			if other.ID() == node.ID() {
+				return true
+			}
+		}
+		return false
+	}
+	duplicates := func(list []Node) (dups []Node) {
+		seen := make(map[int]bool, len(list))
+		for _, other := range list {
+			if seen[other.ID()] && !contains(dups, other) {
+				dups = append(dups, other)
+			}
+			seen[other.ID()] = true
+		}
+		return dups
+	}
+	edges := make(map[[2]int]bool)
+	for _, edge := range graph.EdgeList() {
+		edges[[2]int{edge.Head().ID(), edge.Tail().ID()}] = true
+	}
+
+	cgraph, isCoster := graph.(Coster)
+	for _, u := range nodes {
+		succs, preds := graph.Successors(u), graph.Predecessors(u)
+		for _, v := range duplicates(succs) {
+			report.add(DuplicateEdge, []Node{u, v}, "Successors of %d lists %d more than once", u.ID(), v.ID())
+		}
+		for _, v := range duplicates(preds) {
+			report.add(DuplicateEdge, []Node{v, u}, "Predecessors of %d lists %d more than once", u.ID(), v.ID())
+		}
+
+		for _, v := range succs {
+			if inGraph[v.ID()] == nil {
+				report.add(DanglingEdge, []Node{u, v}, "Edge from %d leads to %d, which isn't in the graph", u.ID(), v.ID())
+				continue
+			}
+			switch {
+			case !graph.IsSuccessor(u, v):
+				report.add(InconsistentEdge, []Node{u, v}, "%d is a successor of %d, but IsSuccessor says it isn't", v.ID(), u.ID())
+			case !contains(graph.Predecessors(v), u):
+				report.add(InconsistentEdge, []Node{u, v}, "%d is a successor of %d, but %d isn't a predecessor of %d", v.ID(), u.ID(), u.ID(), v.ID())
+			case !graph.IsPredecessor(v, u):
+				report.add(InconsistentEdge, []Node{u, v}, "%d is a successor of %d, but IsPredecessor says %d isn't a predecessor of %d", v.ID(), u.ID(), u.ID(), v.ID())
+			case !edges[[2]int{u.ID(), v.ID()}]:
+				report.add(InconsistentEdge, []Node{u, v}, "%d is a successor of %d, but EdgeList doesn't have the edge", v.ID(), u.ID())
+			}
+			if !graph.IsDirected() && !contains(graph.Successors(v), u) {
+				report.add(UndirectedOneWay, []Node{u, v}, "The graph is undirected, but has an edge from %d to %d and not back", u.ID(), v.ID())
+			}
+			if u.ID() == v.ID() {
+				report.add(SelfLoop, []Node{u, v}, "Node %d has an edge to itself", u.ID())
+			}
+			if isCoster {
+				if c := cgraph.Cost(u, v); math.IsNaN(c) || math.IsInf(c, -1) {
+					report.add(BadCost, []Node{u, v}, "Edge from %d to %d costs %v", u.ID(), v.ID(), c)
+				} else if c < 0 {
+					report.add(NegativeCost, []Node{u, v}, "Edge from %d to %d costs %v, which is less than 0", u.ID(), v.ID(), c)
+				}
+			}
+		}
+
+		for _, v := range preds {
+			if inGraph[v.ID()] == nil {
+				report.add(DanglingEdge, []Node{v, u}, "Edge into %d comes from %d, which isn't in the graph", u.ID(), v.ID())
+			} else if !contains(graph.Successors(v), u) {
+				report.add(InconsistentEdge, []Node{v, u}, "%d is a predecessor of %d, but %d isn't a successor of %d", v.ID(), u.ID(), u.ID(), v.ID())
+			}
+		}
+	}
+
+	// Edges only EdgeList knows about
+	var extra [][2]int
+	for edge := range edges {
+		if head, tail := inGraph[edge[0]], inGraph[edge[1]]; head == nil || tail == nil || !contains(graph.Successors(head), tail) {
+			extra = append(extra, edge)
+		}
+	}
+	sort.Sort(byPair(extra))
+	for _, edge := range extra {
+		report.add(InconsistentEdge, []Node{GonumNode(edge[0]), GonumNode(edge[1])}, "EdgeList has an edge from %d to %d, but Successors doesn't", edge[0], edge[1])
+	}
+
+	return report
+}
+
+// Validates graph as Validate does, and also that goal can be reached from start, reporting Unreachable if it can't, or if either isn't in the graph: the check to make on
+// a planning problem before handing it to a search, which would otherwise only say there's no path.
+func ValidatePlan(graph Graph, start, goal Node) *ValidationReport {
+	report := Validate(graph)
+	switch {
+	case !graph.NodeExists(start):
+		report.add(Unreachable, []Node{start, goal}, "The start, %d, isn't in the graph", start.ID())
+	case !graph.NodeExists(goal):
+		report.add(Unreachable, []Node{start, goal}, "The goal, %d, isn't in the graph", goal.ID())
+	case !reachable(graph, start, goal):
+		report.add(Unreachable, []Node{start, goal}, "The goal, %d, can't be reached from the start, %d", goal.ID(), start.ID())
+	}
+	return report
+}
+
+// Whether there's a path from start to goal, by breadth first search
+func reachable(graph Graph, start, goal Node) bool {
+	seen := map[int]bool{start.ID(): true}
+	queue := []Node{start}
+	for len(queue) != 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if node.ID() == goal.ID() {
+			return true
+		}
+		for _, succ := range graph.Successors(node) {
+			if !seen[succ.ID()] {
+				seen[succ.ID()] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+	return false
+}
+
+// Checks the edges a DStarGraph's ChangedEdges reported (before they're passed to Update, since ChangedEdges only reports them once) against graph, reporting
+// UnknownChangedNode for any end that isn't in it. That's a warning rather than an error, since an edge to a node that's just been removed is a change D*-Lite needs to hear
+// about, but an end that was never in the graph means it reports edges it doesn't have.
+func ValidateChangedEdges(graph Graph, changed []Edge) *ValidationReport {
+	report := &ValidationReport{}
+	for _, edge := range changed {
+		for _, end := range []Node{edge.Head(), edge.Tail()} {
+			if !graph.NodeExists(end) {
+				report.add(UnknownChangedNode, []Node{edge.Head(), edge.Tail()}, "Changed edge from %d to %d touches %d, which isn't in the graph", edge.Head().ID(), edge.Tail().ID(), end.ID())
+			}
+		}
+	}
+	return report
+}
+
+// nodes, sorted by ID, without repeats
+func uniqueByID(nodes []Node) []Node {
+	var unique []Node
+	for i, node := range nodes {
+		if i == 0 || nodes[i-1].ID() != node.ID() {
+			unique = append(unique, node)
+		}
+	}
+	return unique
+}
+
+// Sorts pairs lexicographically
+type byPair [][2]int
+
+func (p byPair) Len() int {
+	return len(p)
+}
+
+func (p byPair) Less(i, j int) bool {
+	return p[i][0] < p[j][0] || p[i][0] == p[j][0] && p[i][1] < p[j][1]
+}
+
+func (p byPair) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
@@ -0,0 +1,162 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// A Graph whose methods each read their own table, so they can be made to disagree
+type brokenGraph struct {
+	nodes      []graph.Node
+	succs      map[int][]graph.Node
+	preds      map[int][]graph.Node
+	edges      []graph.Edge
+	costs      map[[2]int]float64
+	undirected bool
+}
+
+func (g *brokenGraph) Successors(node graph.Node) []graph.Node {
+	return g.succs[node.ID()]
+}
+
+func (g *brokenGraph) IsSuccessor(node, succ graph.Node) bool {
+	for _, n := range g.succs[node.ID()] {
+		if n.ID() == succ.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *brokenGraph) Predecessors(node graph.Node) []graph.Node {
+	return g.preds[node.ID()]
+}
+
+func (g *brokenGraph) IsPredecessor(node, pred graph.Node) bool {
+	for _, n := range g.preds[node.ID()] {
+		if n.ID() == pred.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *brokenGraph) IsAdjacent(node, neighbor graph.Node) bool {
+	return g.IsSuccessor(node, neighbor) || g.IsPredecessor(node, neighbor)
+}
+
+func (g *brokenGraph) NodeExists(node graph.Node) bool {
+	for _, n := range g.nodes {
+		if n.ID() == node.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *brokenGraph) Degree(node graph.Node) int {
+	return len(g.succs[node.ID()]) + len(g.preds[node.ID()])
+}
+
+func (g *brokenGraph) EdgeList() []graph.Edge {
+	return g.edges
+}
+
+func (g *brokenGraph) NodeList() []graph.Node {
+	return append([]graph.Node(nil), g.nodes...)
+}
+
+func (g *brokenGraph) IsDirected() bool {
+	return !g.undirected
+}
+
+func (g *brokenGraph) Cost(u, v graph.Node) float64 {
+	if c, ok := g.costs[[2]int{u.ID(), v.ID()}]; ok {
+		return c
+	}
+	return 1
+}
+
+func TestValidate(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for _, directed := range []bool{true, false} {
+		g := graph.NewGonumGraph(directed)
+		graph.GnpRandomGraph(g, 20, 0.2, directed, src)
+		if report := graph.Validate(g); !report.OK() {
+			t.Errorf("Expected a GonumGraph to be valid, got:\n%s", report)
+		}
+	}
+
+	n := func(id int) graph.Node { return graph.GonumNode(id) }
+	edge := func(h, t int) graph.Edge { return graph.GonumEdge{H: n(h), T: n(t)} }
+	g := &brokenGraph{
+		nodes: []graph.Node{n(0), n(1), n(2), n(3), n(2)},
+		succs: map[int][]graph.Node{0: {n(1), n(1)}, 1: {n(2), n(7)}, 2: {n(2)}, 3: {n(0)}},
+		preds: map[int][]graph.Node{1: {n(0)}, 2: {n(2)}, 0: {n(3), n(9)}},
+		edges: []graph.Edge{edge(0, 1), edge(1, 2), edge(2, 2), edge(3, 0), edge(1, 3)},
+		costs: map[[2]int]float64{{3, 0}: math.NaN()},
+	}
+	report := graph.Validate(g)
+	expected := map[graph.IssueKind]int{
+		graph.DuplicateNode:    1, // 2 is listed twice
+		graph.DanglingEdge:     2, // 1 -> 7 and 9 -> 0
+		graph.DuplicateEdge:    1, // 0 -> 1 twice
+		graph.InconsistentEdge: 2, // 1 -> 2 isn't among 2's predecessors, and EdgeList has 1 -> 3
+		graph.SelfLoop:         1,
+		graph.BadCost:          1,
+	}
+	for kind, count := range expected {
+		if issues := report.Of(kind); len(issues) != count {
+			t.Errorf("Expected %d issues of kind %d, got %v", count, kind, issues)
+		}
+	}
+	if len(report.Issues) != 8 {
+		t.Errorf("Expected 8 issues, got:\n%s", report)
+	}
+
+	// An undirected graph must have every edge both ways
+	g = &brokenGraph{
+		nodes:      []graph.Node{n(0), n(1)},
+		succs:      map[int][]graph.Node{0: {n(1)}},
+		preds:      map[int][]graph.Node{1: {n(0)}},
+		edges:      []graph.Edge{edge(0, 1)},
+		undirected: true,
+	}
+	if issues := graph.Validate(g).Of(graph.UndirectedOneWay); len(issues) != 1 {
+		t.Errorf("Expected an undirected edge one way only, got %v", issues)
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	// 0 -> 1 -> 2 with a negative cost and a self loop, which are only warnings, and 3 on its own
+	g := weightedDigraph([][3]float64{{0, 1, 2}, {1, 2, -1}, {2, 2, 1}})
+	g.AddNode(graph.GonumNode(3), nil)
+	report := graph.Validate(g)
+	if len(report.Of(graph.NegativeCost)) != 1 || len(report.Of(graph.SelfLoop)) != 1 || len(report.Issues) != 2 || len(report.Errors()) != 0 {
+		t.Errorf("Expected a negative cost and a self loop, as warnings, got:\n%s", report)
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity != graph.IssueWarning {
+			t.Errorf("Expected %q to be a warning", issue.Message)
+		}
+	}
+
+	if report := graph.ValidatePlan(g, graph.GonumNode(0), graph.GonumNode(2)); len(report.Errors()) != 0 {
+		t.Errorf("Expected 2 to be reachable from 0, got:\n%s", report)
+	}
+	for _, goal := range []graph.Node{graph.GonumNode(3), graph.GonumNode(9)} {
+		report := graph.ValidatePlan(g, graph.GonumNode(0), goal)
+		if issues := report.Errors(); len(issues) != 1 || issues[0].Kind != graph.Unreachable {
+			t.Errorf("Expected %v to be unreachable from 0, got:\n%s", goal, report)
+		}
+	}
+
+	changed := []graph.Edge{graph.GonumEdge{H: graph.GonumNode(0), T: graph.GonumNode(1)}, graph.GonumEdge{H: graph.GonumNode(1), T: graph.GonumNode(5)}}
+	report = graph.ValidateChangedEdges(g, changed)
+	if issues := report.Of(graph.UnknownChangedNode); len(issues) != 1 || issues[0].Nodes[1].ID() != 5 || len(report.Errors()) != 0 {
+		t.Errorf("Expected a warning about the edge to 5, got:\n%s", report)
+	}
+}
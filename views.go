@@ -0,0 +1,394 @@
+package graph
+
+import (
+	"math"
+)
+
+// Returns a view of graph with only the nodes keep returns true for, and the edges between them. Nothing is copied: every call goes through to graph and asks keep again, so
+// the view follows graph as it changes, and keep can change its mind too, e.g. to close a node that's found to be blocked. FilterNodes, FilterEdges, Reverse, Union and
+// Intersect all return views of this kind, and can be stacked on each other.
+//
+// The view is a HeuristicCoster, with graph's costs and heuristic (or uniform costs and the null heuristic), so searches use them without being told. A heuristic that's
+// admissible for graph is admissible for any view taking things out of it, since taking things out only makes paths longer. The cost of an edge that isn't in the view is +Inf.
+//
+// To run D*-Lite on a view whose filter changes, tell it which edges changed, as for any graph (see DStarGraph): those of a node that's been taken out, or put back.
+func FilterNodes(graph Graph, keep func(Node) bool) Graph {
+	return newFilteredGraph(graph, keep, nil)
+}
+
+// Returns a view of graph with all its nodes, but only the edges keep returns true for, as FilterNodes. keep is asked about an edge from its head to its tail; in an undirected
+// graph, it's always asked about an edge from the end with the lower ID, so it doesn't have to be careful to answer the same either way round.
+func FilterEdges(graph Graph, keep func(Edge) bool) Graph {
+	return newFilteredGraph(graph, nil, keep)
+}
+
+type filteredGraph struct {
+	graph     Graph
+	keepNode  func(Node) bool // Nil if every node is kept
+	keepEdge  func(Edge) bool // Nil if every edge between kept nodes is kept
+	cost      func(Node, Node) float64
+	heuristic func(Node, Node) float64
+}
+
+func newFilteredGraph(graph Graph, keepNode func(Node) bool, keepEdge func(Edge) bool) *filteredGraph {
+	view := &filteredGraph{graph: graph, keepNode: keepNode, keepEdge: keepEdge, cost: UniformCost, heuristic: NullHeuristic}
+	if cgraph, ok := graph.(Coster); ok {
+		view.cost = cgraph.Cost
+	}
+	if hgraph, ok := graph.(HeuristicCoster); ok {
+		view.heuristic = hgraph.HeuristicCost
+	}
+	return view
+}
+
+func (view *filteredGraph) hasNode(node Node) bool {
+	return view.keepNode == nil || view.keepNode(node)
+}
+
+// Whether the edge from head to tail is kept, given that it's in graph
+func (view *filteredGraph) hasEdge(head, tail Node) bool {
+	if !view.hasNode(head) || !view.hasNode(tail) {
+		return false
+	}
+	if view.keepEdge == nil {
+		return true
+	}
+	if !view.graph.IsDirected() && tail.ID() < head.ID() {
+		head, tail = tail, head
+	}
+	return view.keepEdge(GonumEdge{H: head, T: tail})
+}
+
+func (view *filteredGraph) Successors(node Node) []Node {
+	if !view.hasNode(node) {
+		return nil
+	}
+	var successors []Node
+	for _, succ := range view.graph.Successors(node) {
+		if view.hasEdge(node, succ) {
+			successors = append(successors, succ)
+		}
+	}
+	return successors
+}
+
+func (view *filteredGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	if !view.hasNode(node) {
+		return
+	}
+	successorVisitor(view.graph, nil)(node, func(succ Node, cost float64) bool {
+		return !view.hasEdge(node, succ) || fn(succ, cost)
+	})
+}
+
+func (view *filteredGraph) IsSuccessor(node, successor Node) bool {
+	return view.graph.IsSuccessor(node, successor) && view.hasEdge(node, successor)
+}
+
+func (view *filteredGraph) Predecessors(node Node) []Node {
+	if !view.hasNode(node) {
+		return nil
+	}
+	var predecessors []Node
+	for _, pred := range view.graph.Predecessors(node) {
+		if view.hasEdge(pred, node) {
+			predecessors = append(predecessors, pred)
+		}
+	}
+	return predecessors
+}
+
+func (view *filteredGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	if !view.hasNode(node) {
+		return
+	}
+	predecessorVisitor(view.graph, nil)(node, func(pred Node, cost float64) bool {
+		return !view.hasEdge(pred, node) || fn(pred, cost)
+	})
+}
+
+func (view *filteredGraph) IsPredecessor(node, predecessor Node) bool {
+	return view.graph.IsPredecessor(node, predecessor) && view.hasEdge(predecessor, node)
+}
+
+func (view *filteredGraph) IsAdjacent(node, neighbor Node) bool {
+	return view.IsSuccessor(node, neighbor) || view.IsPredecessor(node, neighbor)
+}
+
+func (view *filteredGraph) NodeExists(node Node) bool {
+	return view.graph.NodeExists(node) && view.hasNode(node)
+}
+
+func (view *filteredGraph) Degree(node Node) int {
+	return len(view.Successors(node)) + len(view.Predecessors(node))
+}
+
+func (view *filteredGraph) EdgeList() []Edge {
+	var edges []Edge
+	for _, edge := range view.graph.EdgeList() {
+		if view.hasEdge(edge.Head(), edge.Tail()) {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func (view *filteredGraph) NodeList() []Node {
+	var nodes []Node
+	for _, node := range view.graph.NodeList() {
+		if view.hasNode(node) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+func (view *filteredGraph) IsDirected() bool {
+	return view.graph.IsDirected()
+}
+
+func (view *filteredGraph) Cost(node1, node2 Node) float64 {
+	if !view.hasEdge(node1, node2) {
+		return math.Inf(1)
+	}
+	return view.cost(node1, node2)
+}
+
+func (view *filteredGraph) HeuristicCost(node1, node2 Node) float64 {
+	return view.heuristic(node1, node2)
+}
+
+// Returns a view of graph with every edge turned round, as FilterNodes: the successors of a node are its predecessors in graph, the cost from one node to another is graph's
+// cost from the other to the one, and so is the heuristic. Searching it from a node finds the shortest paths to that node in graph, e.g. DijkstraTree on the reverse of a road
+// network gives every junction's distance to a depot. An undirected graph is its own reverse, and is returned as it is.
+func Reverse(graph Graph) Graph {
+	if !graph.IsDirected() {
+		return graph
+	}
+	view := &reversedGraph{graph: graph, cost: UniformCost, heuristic: NullHeuristic}
+	if cgraph, ok := graph.(Coster); ok {
+		view.cost = cgraph.Cost
+	}
+	if hgraph, ok := graph.(HeuristicCoster); ok {
+		view.heuristic = hgraph.HeuristicCost
+	}
+	return view
+}
+
+type reversedGraph struct {
+	graph     Graph
+	cost      func(Node, Node) float64
+	heuristic func(Node, Node) float64
+}
+
+func (view *reversedGraph) Successors(node Node) []Node {
+	return view.graph.Predecessors(node)
+}
+
+func (view *reversedGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	predecessorVisitor(view.graph, nil)(node, fn)
+}
+
+func (view *reversedGraph) IsSuccessor(node, successor Node) bool {
+	return view.graph.IsPredecessor(node, successor)
+}
+
+func (view *reversedGraph) Predecessors(node Node) []Node {
+	return view.graph.Successors(node)
+}
+
+func (view *reversedGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	successorVisitor(view.graph, nil)(node, fn)
+}
+
+func (view *reversedGraph) IsPredecessor(node, predecessor Node) bool {
+	return view.graph.IsSuccessor(node, predecessor)
+}
+
+func (view *reversedGraph) IsAdjacent(node, neighbor Node) bool {
+	return view.graph.IsAdjacent(node, neighbor)
+}
+
+func (view *reversedGraph) NodeExists(node Node) bool {
+	return view.graph.NodeExists(node)
+}
+
+func (view *reversedGraph) Degree(node Node) int {
+	return view.graph.Degree(node)
+}
+
+func (view *reversedGraph) EdgeList() []Edge {
+	edges := view.graph.EdgeList()
+	for i, edge := range edges {
+		edges[i] = GonumEdge{H: edge.Tail(), T: edge.Head()}
+	}
+	return edges
+}
+
+func (view *reversedGraph) NodeList() []Node {
+	return view.graph.NodeList()
+}
+
+func (view *reversedGraph) IsDirected() bool {
+	return true
+}
+
+func (view *reversedGraph) Cost(node1, node2 Node) float64 {
+	return view.cost(node2, node1)
+}
+
+func (view *reversedGraph) HeuristicCost(node1, node2 Node) float64 {
+	return view.heuristic(node2, node1)
+}
+
+// Returns a view of the nodes and edges in either g1 or g2, as FilterNodes, e.g. a road network with a set of ferry routes added. It's directed if either is. An edge in
+// both costs the cheaper of its two costs, so Union(g1, g2) and Union(g2, g1) are the same graph, though a node in both is given as g1's.
+//
+// A path in the union can mix edges from both graphs, so neither graph's heuristic need be admissible for it, and its HeuristicCost is the null heuristic; pass a search
+// one that's admissible for both, such as a straight line distance, if there is one.
+func Union(g1, g2 Graph) Graph {
+	return newCombinedGraph(g1, g2, true)
+}
+
+// Returns a view of the nodes and edges in both g1 and g2, as FilterNodes, e.g. the roads open to both a truck and a bus. It's directed if either is, and an edge costs the
+// dearer of its two costs. Paths in the intersection are paths in both graphs, costing at least as much as in either, so its HeuristicCost is the greater of the two
+// graphs' heuristics, which is admissible if both are.
+func Intersect(g1, g2 Graph) Graph {
+	return newCombinedGraph(g1, g2, false)
+}
+
+type combinedGraph struct {
+	graphs     [2]Graph
+	costs      [2]func(Node, Node) float64
+	heuristics [2]func(Node, Node) float64
+	union      bool // Whether it's the union of the graphs, or their intersection
+}
+
+func newCombinedGraph(g1, g2 Graph, union bool) *combinedGraph {
+	view := &combinedGraph{graphs: [2]Graph{g1, g2}, union: union}
+	for i, graph := range view.graphs {
+		view.costs[i], view.heuristics[i] = UniformCost, NullHeuristic
+		if cgraph, ok := graph.(Coster); ok {
+			view.costs[i] = cgraph.Cost
+		}
+		if hgraph, ok := graph.(HeuristicCoster); ok {
+			view.heuristics[i] = hgraph.HeuristicCost
+		}
+	}
+	return view
+}
+
+// Whether the view has something the two graphs say they do or don't have
+func (view *combinedGraph) combine(in1, in2 bool) bool {
+	if view.union {
+		return in1 || in2
+	}
+	return in1 && in2
+}
+
+// The nodes in neighbors[0] and neighbors[1] that combine says are in the view, with g1's listed first, and none twice
+func (view *combinedGraph) merge(neighbors [2][]Node) []Node {
+	var nodes []Node
+	in2 := make(map[int]bool, len(neighbors[1]))
+	for _, node := range neighbors[1] {
+		in2[node.ID()] = true
+	}
+	in1 := make(map[int]bool, len(neighbors[0]))
+	for _, node := range neighbors[0] {
+		in1[node.ID()] = true
+		if view.combine(true, in2[node.ID()]) {
+			nodes = append(nodes, node)
+		}
+	}
+	if view.union {
+		for _, node := range neighbors[1] {
+			if !in1[node.ID()] {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
+func (view *combinedGraph) Successors(node Node) []Node {
+	var neighbors [2][]Node
+	for i, graph := range view.graphs {
+		if graph.NodeExists(node) {
+			neighbors[i] = graph.Successors(node)
+		}
+	}
+	return view.merge(neighbors)
+}
+
+func (view *combinedGraph) IsSuccessor(node, successor Node) bool {
+	return view.combine(view.graphs[0].IsSuccessor(node, successor), view.graphs[1].IsSuccessor(node, successor))
+}
+
+func (view *combinedGraph) Predecessors(node Node) []Node {
+	var neighbors [2][]Node
+	for i, graph := range view.graphs {
+		if graph.NodeExists(node) {
+			neighbors[i] = graph.Predecessors(node)
+		}
+	}
+	return view.merge(neighbors)
+}
+
+func (view *combinedGraph) IsPredecessor(node, predecessor Node) bool {
+	return view.combine(view.graphs[0].IsPredecessor(node, predecessor), view.graphs[1].IsPredecessor(node, predecessor))
+}
+
+func (view *combinedGraph) IsAdjacent(node, neighbor Node) bool {
+	return view.IsSuccessor(node, neighbor) || view.IsPredecessor(node, neighbor)
+}
+
+func (view *combinedGraph) NodeExists(node Node) bool {
+	return view.combine(view.graphs[0].NodeExists(node), view.graphs[1].NodeExists(node))
+}
+
+func (view *combinedGraph) Degree(node Node) int {
+	return len(view.Successors(node)) + len(view.Predecessors(node))
+}
+
+func (view *combinedGraph) EdgeList() []Edge {
+	var edges []Edge
+	for _, node := range view.NodeList() {
+		for _, succ := range view.Successors(node) {
+			edges = append(edges, GonumEdge{H: node, T: succ})
+		}
+	}
+	return edges
+}
+
+func (view *combinedGraph) NodeList() []Node {
+	return view.merge([2][]Node{view.graphs[0].NodeList(), view.graphs[1].NodeList()})
+}
+
+func (view *combinedGraph) IsDirected() bool {
+	return view.graphs[0].IsDirected() || view.graphs[1].IsDirected()
+}
+
+func (view *combinedGraph) Cost(node1, node2 Node) float64 {
+	var costs []float64
+	for i, graph := range view.graphs {
+		if graph.IsSuccessor(node1, node2) {
+			costs = append(costs, view.costs[i](node1, node2))
+		}
+	}
+	switch {
+	case len(costs) == 0 || !view.union && len(costs) == 1:
+		return math.Inf(1)
+	case len(costs) == 1:
+		return costs[0]
+	case view.union:
+		return math.Min(costs[0], costs[1])
+	}
+	return math.Max(costs[0], costs[1])
+}
+
+func (view *combinedGraph) HeuristicCost(node1, node2 Node) float64 {
+	if view.union {
+		return 0
+	}
+	return math.Max(view.heuristics[0](node1, node2), view.heuristics[1](node1, node2))
+}
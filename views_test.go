@@ -0,0 +1,95 @@
+package graph_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+// Two ways from 0 to 3, through 1 for 2 or through 2 for 4, and a direct road for 10
+func viewRoads() *graph.DirectedGraph {
+	return weightedDigraph([][3]float64{{0, 1, 1}, {1, 3, 1}, {0, 2, 2}, {2, 3, 2}, {0, 3, 10}})
+}
+
+func TestFilterNodes(t *testing.T) {
+	g := viewRoads()
+	view := graph.FilterNodes(g, func(node graph.Node) bool { return node.ID() != 1 })
+	if ids := sortedIDs(view.NodeList()); !reflect.DeepEqual(ids, []int{0, 2, 3}) || len(view.EdgeList()) != 3 || view.NodeExists(graph.GonumNode(1)) {
+		t.Errorf("Expected the nodes [0 2 3] and 3 edges, got %v and %v", ids, edgeIDs(view.EdgeList()))
+	}
+	if path, cost, _ := graph.AStar(graph.GonumNode(0), graph.GonumNode(3), view, nil, nil); cost != 4 || !reflect.DeepEqual(nodeIDs(path), []int{0, 2, 3}) {
+		t.Errorf("Expected the path [0 2 3] costing 4 without 1, got %v costing %v", nodeIDs(path), cost)
+	}
+	if !math.IsInf(view.(graph.Coster).Cost(graph.GonumNode(0), graph.GonumNode(1)), 1) {
+		t.Error("Expected an edge to a node filtered out to cost +Inf")
+	}
+}
+
+func TestFilterEdgesDStar(t *testing.T) {
+	g := viewRoads()
+	closed := make(map[[2]int]bool)
+	view := graph.FilterEdges(g, func(edge graph.Edge) bool { return !closed[[2]int{edge.Head().ID(), edge.Tail().ID()}] })
+
+	ds := graph.InitDStar(graph.GonumNode(0), graph.GonumNode(3), view, nil, nil)
+	for _, step := range []struct {
+		edge   [2]int
+		closed bool
+		cost   float64
+	}{{[2]int{1, 3}, true, 4}, {[2]int{2, 3}, true, 10}, {[2]int{1, 3}, false, 2}} {
+		closed[step.edge] = step.closed
+		ds.Update(nil, []graph.Edge{graph.GonumEdge{H: graph.GonumNode(step.edge[0]), T: graph.GonumNode(step.edge[1])}})
+		if _, cost, err := ds.Path(); err != nil || cost != step.cost {
+			t.Errorf("Expected a path costing %v once %v was closed: %t, got %v (%v)", step.cost, step.edge, step.closed, cost, err)
+		}
+	}
+	if ids := edgeIDs(view.EdgeList()); len(ids) != 4 || view.IsSuccessor(graph.GonumNode(2), graph.GonumNode(3)) {
+		t.Errorf("Expected 4 edges, without 2 -> 3, got %v", ids)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	g := randomWeightedGraph(60, 0.08, 11)
+	goal := graph.GonumNode(7)
+	tree := graph.DijkstraTree(goal, graph.Reverse(g), nil)
+	for _, node := range g.NodeList() {
+		_, want, _ := graph.AStar(node, goal, g, nil, nil)
+		if d := tree.DistTo(node); math.Abs(d-want) > 1e-9 && !(math.IsInf(d, 1) && math.IsInf(want, 1)) {
+			t.Errorf("Expected the distance from %v to %v to be %v, got %v searching the reverse", node, goal, want, d)
+		}
+	}
+
+	undirected := graph.NewGonumGraph(false)
+	if graph.Reverse(undirected) != graph.Graph(undirected) {
+		t.Error("Expected an undirected graph to be its own reverse")
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	g1 := viewRoads()
+	g2 := weightedDigraph([][3]float64{{0, 3, 1}, {1, 3, 0.5}, {3, 4, 1}})
+	zero, one, three, four := graph.GonumNode(0), graph.GonumNode(1), graph.GonumNode(3), graph.GonumNode(4)
+
+	union := graph.Union(g1, g2)
+	if ids := sortedIDs(union.NodeList()); !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 4}) || len(union.EdgeList()) != 6 {
+		t.Errorf("Expected the union to have the nodes [0 1 2 3 4] and 6 edges, got %v and %v", ids, edgeIDs(union.EdgeList()))
+	}
+	if path, cost, _ := graph.AStar(zero, four, union, nil, nil); cost != 2 || !reflect.DeepEqual(nodeIDs(path), []int{0, 3, 4}) {
+		t.Errorf("Expected the path [0 3 4] costing 2 in the union, got %v costing %v", nodeIDs(path), cost)
+	}
+	if cost := union.(graph.Coster).Cost(one, three); cost != 0.5 {
+		t.Errorf("Expected the cheaper cost of 1 -> 3 in the union, 0.5, got %v", cost)
+	}
+
+	intersection := graph.Intersect(g1, g2)
+	if ids := sortedIDs(intersection.NodeList()); !reflect.DeepEqual(ids, []int{0, 1, 3}) || len(intersection.EdgeList()) != 2 {
+		t.Errorf("Expected the intersection to have the nodes [0 1 3] and 2 edges, got %v and %v", ids, edgeIDs(intersection.EdgeList()))
+	}
+	if path, cost, _ := graph.AStar(zero, three, intersection, nil, nil); cost != 10 || !reflect.DeepEqual(nodeIDs(path), []int{0, 3}) {
+		t.Errorf("Expected the path [0 3] costing 10 in the intersection, got %v costing %v", nodeIDs(path), cost)
+	}
+	if intersection.IsSuccessor(zero, one) || intersection.NodeExists(four) {
+		t.Error("Expected the intersection to leave out what's only in one graph")
+	}
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	"math"
+	"sort"
+)
+
+// A visibility graph amongst polygonal obstacles: its nodes are the obstacles' corners, as PointNodes, and two nodes are adjacent when the straight line between them doesn't
+// pass through any obstacle. The shortest path between two points amongst polygonal obstacles always runs straight from corner to corner, so a shortest path search of the
+// visibility graph (with the start and goal added by AddPoint) finds the shortest any-angle path, not just an approximation of it as on a grid.
+//
+// Edges cost their length, and HeuristicCost is the straight line distance, so AStar can search it directly. Lines may run along an obstacle's sides and touch its corners,
+// only crossing its interior blocks them, which means paths hug the obstacles exactly; pad the obstacles to keep an agent clear of them.
+type VisibilityGraph struct {
+	Obstacles [][]Position
+	nodes     []PointNode
+	adj       []map[int]float64
+}
+
+// Builds the visibility graph amongst obstacles, each a simple polygon listing its corners in order, either way round. Obstacles may be concave and may touch or overlap; corners
+// inside another obstacle are left unconnected. Checking every pair of corners against every side takes O(n^3) time for n corners, so it suits maps of up to a few hundred
+// corners, built once and searched many times.
+func NewVisibilityGraph(obstacles [][]Position) *VisibilityGraph {
+	graph := &VisibilityGraph{Obstacles: obstacles}
+	for _, obstacle := range obstacles {
+		for _, p := range obstacle {
+			graph.AddPoint(p)
+		}
+	}
+
+	return graph
+}
+
+// Adds a node at p, such as the start or goal of a search, connected to every node it can see, and returns it.
+func (graph *VisibilityGraph) AddPoint(p Position) Node {
+	node := PointNode{Id: len(graph.nodes), X: p.X, Y: p.Y}
+	graph.nodes = append(graph.nodes, node)
+	graph.adj = append(graph.adj, make(map[int]float64))
+
+	for _, other := range graph.nodes[:node.Id] {
+		q := Position{other.X, other.Y}
+		if graph.Visible(p, q) {
+			d := math.Hypot(p.X-q.X, p.Y-q.Y)
+			graph.adj[node.Id][other.Id] = d
+			graph.adj[other.Id][node.Id] = d
+		}
+	}
+
+	return node
+}
+
+// Whether the straight line from a to b stays out of the interior of every obstacle.
+func (graph *VisibilityGraph) Visible(a, b Position) bool {
+	// Cut the line wherever it meets the side of an obstacle; in between, each piece is either wholly inside an obstacle or wholly outside all of them, which its midpoint tells
+	ts := []float64{0, 1}
+	for _, obstacle := range graph.Obstacles {
+		for i, c := range obstacle {
+			ts = append(ts, segmentMeets(a, b, c, obstacle[(i+1)%len(obstacle)])...)
+		}
+	}
+	sort.Float64s(ts)
+
+	for i := 1; i < len(ts); i++ {
+		if ts[i]-ts[i-1] < 1e-12 {
+			continue
+		}
+		t := (ts[i-1] + ts[i]) / 2
+		mid := Position{a.X + t*(b.X-a.X), a.Y + t*(b.Y-a.Y)}
+		for _, obstacle := range graph.Obstacles {
+			if insidePolygon(mid, obstacle) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Where, as fractions of the way from a to b, the segment a-b meets the segment c-d: nothing, a single point, or the ends of the overlap if they're collinear.
+func segmentMeets(a, b, c, d Position) []float64 {
+	const eps = 1e-12
+	cross := func(p, q Position) float64 { return p.X*q.Y - p.Y*q.X }
+	r, s, ac := Position{b.X - a.X, b.Y - a.Y}, Position{d.X - c.X, d.Y - c.Y}, Position{c.X - a.X, c.Y - a.Y}
+
+	denom := cross(r, s)
+	if denom != 0 {
+		t, u := cross(ac, s)/denom, cross(ac, r)/denom
+		if t >= -eps && t <= 1+eps && u >= -eps && u <= 1+eps {
+			return []float64{math.Max(0, math.Min(1, t))}
+		}
+		return nil
+	}
+
+	rr := r.X*r.X + r.Y*r.Y
+	if cross(ac, r) != 0 || rr == 0 {
+		return nil
+	}
+	var ts []float64
+	for _, p := range []Position{c, d} {
+		if t := ((p.X-a.X)*r.X + (p.Y-a.Y)*r.Y) / rr; t > 0 && t < 1 {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+// Whether p is strictly inside polygon, by counting the polygon's sides crossed by a ray from p. Points on the boundary are outside.
+func insidePolygon(p Position, polygon []Position) bool {
+	inside := false
+	for i, a := range polygon {
+		b := polygon[(i+1)%len(polygon)]
+		if triArea2(a, b, p) == 0 && p.X >= math.Min(a.X, b.X) && p.X <= math.Max(a.X, b.X) && p.Y >= math.Min(a.Y, b.Y) && p.Y <= math.Max(a.Y, b.Y) {
+			return false
+		}
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func (graph *VisibilityGraph) valid(node Node) bool {
+	return node.ID() >= 0 && node.ID() < len(graph.nodes)
+}
+
+func (graph *VisibilityGraph) Successors(node Node) []Node {
+	if !graph.valid(node) {
+		return nil
+	}
+
+	succs := make([]Node, 0, len(graph.adj[node.ID()]))
+	for id := range graph.adj[node.ID()] {
+		succs = append(succs, graph.nodes[id])
+	}
+	return succs
+}
+
+func (graph *VisibilityGraph) IsSuccessor(node, successor Node) bool {
+	if !graph.valid(node) {
+		return false
+	}
+	_, ok := graph.adj[node.ID()][successor.ID()]
+	return ok
+}
+
+func (graph *VisibilityGraph) Predecessors(node Node) []Node {
+	return graph.Successors(node)
+}
+
+func (graph *VisibilityGraph) IsPredecessor(node, pred Node) bool {
+	return graph.IsSuccessor(node, pred)
+}
+
+func (graph *VisibilityGraph) IsAdjacent(node, neighbor Node) bool {
+	return graph.IsSuccessor(node, neighbor)
+}
+
+func (graph *VisibilityGraph) NodeExists(node Node) bool {
+	return graph.valid(node)
+}
+
+func (graph *VisibilityGraph) Degree(node Node) int {
+	return len(graph.Successors(node)) * 2
+}
+
+func (graph *VisibilityGraph) EdgeList() []Edge {
+	edges := make([]Edge, 0)
+	for _, node := range graph.nodes {
+		for _, succ := range graph.Successors(node) {
+			edges = append(edges, GonumEdge{node, succ})
+		}
+	}
+
+	return edges
+}
+
+func (graph *VisibilityGraph) NodeList() []Node {
+	nodes := make([]Node, len(graph.nodes))
+	for i, node := range graph.nodes {
+		nodes[i] = node
+	}
+
+	return nodes
+}
+
+func (graph *VisibilityGraph) IsDirected() bool {
+	return false
+}
+
+func (graph *VisibilityGraph) Cost(node1, node2 Node) float64 {
+	return graph.HeuristicCost(node1, node2)
+}
+
+func (graph *VisibilityGraph) HeuristicCost(node1, node2 Node) float64 {
+	a, b := graph.nodes[node1.ID()], graph.nodes[node2.ID()]
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
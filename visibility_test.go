@@ -0,0 +1,76 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestVisibilityGraph(t *testing.T) {
+	square := []graph.Position{{1, 1}, {2, 1}, {2, 2}, {1, 2}}
+	vg := graph.NewVisibilityGraph([][]graph.Position{square})
+
+	if len(vg.NodeList()) != 4 {
+		t.Fatalf("Expected a node per corner, got %d nodes", len(vg.NodeList()))
+	}
+	// Corners along a side see each other, across the diagonal they don't
+	for i := 0; i < 4; i++ {
+		if !vg.IsAdjacent(graph.GonumNode(i), graph.GonumNode((i+1)%4)) {
+			t.Errorf("Corners %d and %d are along a side but not adjacent", i, (i+1)%4)
+		}
+	}
+	if vg.IsAdjacent(graph.GonumNode(0), graph.GonumNode(2)) || vg.IsAdjacent(graph.GonumNode(1), graph.GonumNode(3)) {
+		t.Error("Opposite corners see each other through the obstacle")
+	}
+
+	start := vg.AddPoint(graph.Position{0, 1.5})
+	goal := vg.AddPoint(graph.Position{3, 1.5})
+	if vg.IsAdjacent(start, goal) {
+		t.Error("Start and goal see each other through the obstacle")
+	}
+
+	path, cost, _ := graph.AStar(start, goal, vg, nil, nil)
+	if want := 2*math.Hypot(1, 0.5) + 1; math.Abs(cost-want) > 1e-9 {
+		t.Errorf("Expected the path around the obstacle to cost %v, got %v (%v)", want, cost, path)
+	}
+	if len(path) != 4 {
+		t.Errorf("Expected the path to turn at two corners, got %v", path)
+	}
+}
+
+func TestVisibilityConcave(t *testing.T) {
+	// A U shaped obstacle, open at the top: its inner corners see out through the opening, but not through the walls
+	u := []graph.Position{{0, 0}, {3, 0}, {3, 3}, {2, 3}, {2, 1}, {1, 1}, {1, 3}, {0, 3}}
+	vg := graph.NewVisibilityGraph([][]graph.Position{u})
+
+	tests := []struct {
+		a, b    graph.Position
+		visible bool
+	}{
+		{graph.Position{1.5, 2}, graph.Position{1.5, 4}, true},
+		{graph.Position{1.5, 2}, graph.Position{4, 2}, false},
+		{graph.Position{1, 3}, graph.Position{2, 3}, true},    // across the opening
+		{graph.Position{0, 3}, graph.Position{3, 3}, true},    // along the tops of both arms
+		{graph.Position{0, 0}, graph.Position{3, 3}, false},   // through the base
+		{graph.Position{-1, 3}, graph.Position{4, 3}, true},   // grazing the tops
+		{graph.Position{-1, 2}, graph.Position{-1, -1}, true}, // clear of it entirely
+		{graph.Position{1, 1}, graph.Position{2, 3}, true},    // across the inside of the U
+		{graph.Position{0.5, 4}, graph.Position{0.5, 2}, false},
+	}
+	for _, test := range tests {
+		if got := vg.Visible(test.a, test.b); got != test.visible {
+			t.Errorf("Visible(%v, %v) = %t, expected %t", test.a, test.b, got, test.visible)
+		}
+	}
+
+	// The concave corner at the bottom of the U sees its neighbors and the opposite top corner of the gap, but not the base's corners
+	inner := graph.GonumNode(4)
+	if !vg.IsAdjacent(inner, graph.GonumNode(5)) || !vg.IsAdjacent(inner, graph.GonumNode(6)) || vg.IsAdjacent(inner, graph.GonumNode(0)) {
+		t.Errorf("Unexpected neighbors for the inner corner: %v", vg.Successors(inner))
+	}
+
+	if inside := vg.AddPoint(graph.Position{0.5, 0.5}); len(vg.Successors(inside)) != 0 {
+		t.Errorf("A point inside the obstacle sees %v", vg.Successors(inside))
+	}
+}
@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"math"
+)
+
+// Calls fn with every successor of node and the cost of the edge to it, stopping early if fn returns false. Graphs that implement SuccessorVisitor are visited without allocating;
+// for any other graph this falls back to Successors, with costs from its Cost if it's a Coster, or 1.
+func VisitSuccessors(graph ImplicitGraph, node Node, fn func(succ Node, cost float64) bool) {
+	successorVisitor(graph, nil)(node, fn)
+}
+
+// Returns how a search should visit successors: with the graph's own VisitSuccessors if Cost is nil (meaning the graph's costs are wanted) and it has one, otherwise by ranging over
+// Successors and calling Cost, resolved as usual. Must be called with the Cost argument as the caller received it, before it's defaulted.
+func successorVisitor(graph ImplicitGraph, Cost func(Node, Node) float64) func(node Node, fn func(succ Node, cost float64) bool) {
+	if visitor, ok := graph.(SuccessorVisitor); ok && Cost == nil {
+		return visitor.VisitSuccessors
+	}
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	return func(node Node, fn func(succ Node, cost float64) bool) {
+		for _, succ := range graph.Successors(node) {
+			if !fn(succ, Cost(node, succ)) {
+				return
+			}
+		}
+	}
+}
+
+// Calls fn with every predecessor of node and the cost of the edge from it to node, stopping early if fn returns false. As VisitSuccessors, but backwards: graphs that implement
+// PredecessorVisitor are visited without allocating, and any other falls back to Predecessors.
+func VisitPredecessors(graph ReversibleGraph, node Node, fn func(pred Node, cost float64) bool) {
+	predecessorVisitor(graph, nil)(node, fn)
+}
+
+// As successorVisitor, but for predecessors.
+func predecessorVisitor(graph ReversibleGraph, Cost func(Node, Node) float64) func(node Node, fn func(pred Node, cost float64) bool) {
+	if visitor, ok := graph.(PredecessorVisitor); ok && Cost == nil {
+		return visitor.VisitPredecessors
+	}
+	if Cost == nil {
+		if cgraph, ok := graph.(Coster); ok {
+			Cost = cgraph.Cost
+		} else {
+			Cost = UniformCost
+		}
+	}
+
+	return func(node Node, fn func(pred Node, cost float64) bool) {
+		for _, pred := range graph.Predecessors(node) {
+			if !fn(pred, Cost(pred, node)) {
+				return
+			}
+		}
+	}
+}
+
+func (graph *GonumGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	for succ, cost := range graph.successors[node.ID()] {
+		if !fn(graph.nodeMap[succ], cost) {
+			return
+		}
+	}
+}
+
+func (graph *GonumGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	for pred, cost := range graph.predecessors[node.ID()] {
+		if !fn(graph.nodeMap[pred], cost) {
+			return
+		}
+	}
+}
+
+func (graph *TileGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	id := node.ID()
+	if id < 0 || id >= len(graph.tiles) || graph.tiles[id] == false {
+		return
+	}
+
+	// In the same order as Successors
+	row, col := graph.IDToCoords(id)
+	for _, neighbor := range [4]int{graph.CoordsToID(row-1, col), graph.CoordsToID(row+1, col), graph.CoordsToID(row, col-1), graph.CoordsToID(row, col+1)} {
+		if neighbor != -1 && graph.tiles[neighbor] == true {
+			if !fn(GonumNode(neighbor), 1) {
+				return
+			}
+		}
+	}
+}
+
+// A TileGraph is undirected, so its predecessors are its successors.
+func (graph *TileGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	graph.VisitSuccessors(node, fn)
+}
+
+func (graph *ImmutableGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	i, ok := graph.position(node)
+	if !ok {
+		return
+	}
+	for k := graph.predStart[i]; k < graph.predStart[i+1]; k++ {
+		if !fn(graph.nodes[graph.preds[k]], graph.predCosts[k]) {
+			return
+		}
+	}
+}
+
+// In the same order as Successors
+func (grid *GridGraph) VisitSuccessors(node Node, fn func(succ Node, cost float64) bool) {
+	id := node.ID()
+	if id < 0 || id >= len(grid.blocked) || grid.blocked[id] {
+		return
+	}
+
+	x, y := grid.IDToCoords(id)
+	for _, d := range grid.directions() {
+		if grid.canMove(x, y, d[0], d[1]) {
+			cost := 1.0
+			if d[0] != 0 && d[1] != 0 {
+				cost = math.Sqrt2
+			}
+			if !fn(grid.CoordsToNode(x+d[0], y+d[1]), cost) {
+				return
+			}
+		}
+	}
+}
+
+// A GridGraph is undirected, so its predecessors are its successors.
+func (grid *GridGraph) VisitPredecessors(node Node, fn func(pred Node, cost float64) bool) {
+	grid.VisitSuccessors(node, fn)
+}
+
+// Whether graph is directed, which for a graph that doesn't say (an implicit graph without IsDirected) is assumed
+func isDirected(graph ImplicitGraph) bool {
+	if g, ok := graph.(interface {
+		IsDirected() bool
+	}); ok {
+		return g.IsDirected()
+	}
+
+	return true
+}
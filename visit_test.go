@@ -0,0 +1,111 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestVisitSuccessors(t *testing.T) {
+	tg := graph.RandomObstacleField(10, 10, 0.3, graph.GonumNode(0), graph.GonumNode(99), rand.New(rand.NewSource(1)))
+	gg := randomWeightedGraph(50, 0.1, 1)
+	legacy := cachedSuccessors{Graph: tg, succs: make(map[int][]graph.Node)}
+	for _, node := range tg.NodeList() {
+		legacy.succs[node.ID()] = tg.Successors(node)
+	}
+
+	cost := func(g graph.Graph) func(a, b graph.Node) float64 {
+		if c, ok := g.(graph.Coster); ok {
+			return c.Cost
+		}
+		return graph.UniformCost
+	}
+	grid := graph.NewGridGraph(8, 8, true)
+	grid.SetObstacle(3, 3)
+	for name, g := range map[string]graph.Graph{"tiles": tg, "gonum": gg, "legacy": legacy, "grid": grid, "immutable": graph.NewImmutableGraph(gg)} {
+		for _, node := range g.NodeList() {
+			expect := make(map[int]float64)
+			for _, succ := range g.Successors(node) {
+				expect[succ.ID()] = cost(g)(node, succ)
+			}
+
+			visited := make(map[int]float64)
+			graph.VisitSuccessors(g, node, func(succ graph.Node, cost float64) bool {
+				if _, ok := visited[succ.ID()]; ok {
+					t.Errorf("%s: %v visited twice from %v", name, succ, node)
+				}
+				visited[succ.ID()] = cost
+				return true
+			})
+			if len(visited) != len(expect) {
+				t.Errorf("%s: visited %d successors of %v, expected %d", name, len(visited), node, len(expect))
+			}
+			for id, c := range expect {
+				if visited[id] != c {
+					t.Errorf("%s: edge from %v to %d cost %v, expected %v", name, node, id, visited[id], c)
+				}
+			}
+
+			calls := 0
+			graph.VisitSuccessors(g, node, func(graph.Node, float64) bool {
+				calls++
+				return false
+			})
+			if len(expect) > 0 && calls != 1 {
+				t.Errorf("%s: visiting continued after fn returned false", name)
+			}
+		}
+	}
+}
+
+func TestVisitPredecessors(t *testing.T) {
+	gg := randomWeightedGraph(50, 0.1, 1)
+	undirected := graph.NewGonumGraph(false)
+	graph.CopyGraph(undirected, graph.NewImmutableGraph(gg))
+	grid := graph.NewGridGraph(8, 8, true)
+	grid.SetObstacle(3, 3)
+	tg := graph.RandomObstacleField(10, 10, 0.3, graph.GonumNode(0), graph.GonumNode(99), rand.New(rand.NewSource(1)))
+
+	for name, g := range map[string]graph.Graph{"tiles": tg, "gonum": gg, "undirected": undirected, "grid": grid, "immutable": graph.NewImmutableGraph(gg),
+		"legacy": struct{ graph.CostGraph }{gg}} {
+		cost := graph.UniformCost
+		if c, ok := g.(graph.Coster); ok {
+			cost = c.Cost
+		}
+		for _, node := range g.NodeList() {
+			expect := make(map[int]float64)
+			for _, pred := range g.Predecessors(node) {
+				expect[pred.ID()] = cost(pred, node)
+			}
+
+			visited := make(map[int]float64)
+			graph.VisitPredecessors(g, node, func(pred graph.Node, cost float64) bool {
+				visited[pred.ID()] = cost
+				return true
+			})
+			if !reflect.DeepEqual(visited, expect) {
+				t.Errorf("%s: visited the predecessors %v of %v, expected %v", name, visited, node, expect)
+			}
+		}
+	}
+}
+
+func BenchmarkDStarGridReplan(b *testing.B) {
+	grid := graph.NewGridGraph(200, 200, true)
+	start, goal := grid.CoordsToNode(0, 100), grid.CoordsToNode(199, 100)
+	ds := graph.InitDStar(start, goal, grid, nil, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Put up a wall across the way and take it down again, replanning each time
+		for y := 50; y < 150; y++ {
+			grid.SetObstacle(100, y)
+		}
+		ds.Update(grid.ChangedEdges())
+		for y := 50; y < 150; y++ {
+			grid.ClearObstacle(100, y)
+		}
+		ds.Update(grid.ChangedEdges())
+	}
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Fills dst with a spanning tree of the undirected graph underlying graph, drawn uniformly at random from all its spanning trees by Wilson's algorithm[1]. If graph isn't
+// connected, it's a spanning forest, with a tree for each component drawn independently. dst is made undirected and gets every node of graph.
+//
+// Each node not yet in the tree starts a random walk, which goes until it hits the tree, and the walk with its loops erased (in the order they were made) joins the tree. That
+// the result is uniform is a small miracle, and not true of the random spanning trees that randomized Prim's or Kruskal's algorithm make, which are biased toward short,
+// bushy trees. Takes expected time proportional to the mean hitting time of the graph. If src is nil a time seeded source is used.
+//
+// [1] D. B. Wilson, "Generating random spanning trees more quickly than the cover time", Proceedings of the 28th ACM Symposium on Theory of Computing (1996)
+func RandomSpanningTree(dst MutableGraph, graph Graph, src *rand.Rand) {
+	src = randSource(src)
+	dst.EmptyGraph()
+	dst.SetDirected(false)
+
+	nodes := graph.NodeList()
+	sort.Sort(byID(nodes))
+	neighbors := make(map[int][]Node, len(nodes))
+	for _, node := range nodes {
+		dst.AddNode(node, nil)
+		neighbors[node.ID()], _ = treeNeighbors(graph, node)
+	}
+
+	inTree := make(map[int]bool, len(nodes))
+	for _, root := range treeComponents(graph, nodes) {
+		inTree[root.ID()] = true
+	}
+	wilson(nodes, func(node Node) []Node { return neighbors[node.ID()] }, inTree, func(u, v Node) { dst.AddEdge(GonumEdge{H: u, T: v}) }, src)
+}
+
+// Grows the tree of nodes in inTree by loop erased random walks from each of nodes in turn, calling join for each edge added from a new node to the node it leads to
+func wilson(nodes []Node, neighbors func(Node) []Node, inTree map[int]bool, join func(u, v Node), src *rand.Rand) {
+	next := make(map[int]Node)
+	for _, node := range nodes {
+		// Walking remembers only the last way out of each node, which erases loops as they're closed
+		for u := node; !inTree[u.ID()]; u = next[u.ID()] {
+			out := neighbors(u)
+			next[u.ID()] = out[src.Intn(len(out))]
+		}
+		for u := node; !inTree[u.ID()]; u = next[u.ID()] {
+			inTree[u.ID()] = true
+			join(u, next[u.ID()])
+		}
+	}
+}
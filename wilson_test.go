@@ -0,0 +1,60 @@
+package graph_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/gonum/graph"
+)
+
+func TestRandomSpanningTree(t *testing.T) {
+	// K4 has 16 spanning trees, which should come up equally often
+	g := graph.NewGonumGraph(false)
+	graph.CompleteGraph(g, 4, false)
+	src := rand.New(rand.NewSource(1))
+	counts := make(map[string]int)
+	const samples = 16000
+	for i := 0; i < samples; i++ {
+		tree := graph.NewGonumGraph(false)
+		graph.RandomSpanningTree(tree, g, src)
+		if !graph.IsTree(tree) || len(tree.NodeList()) != 4 {
+			t.Fatalf("Not a spanning tree: %v", tree.EdgeList())
+		}
+		var edges []string
+		for _, edge := range tree.EdgeList() {
+			if edge.Head().ID() < edge.Tail().ID() {
+				edges = append(edges, fmt.Sprint(edge.Head().ID(), edge.Tail().ID()))
+			}
+		}
+		sort.Strings(edges)
+		counts[fmt.Sprint(edges)]++
+	}
+	if len(counts) != 16 {
+		t.Errorf("Expected 16 different trees, got %d", len(counts))
+	}
+	for tree, count := range counts {
+		if count < samples/16*8/10 || count > samples/16*12/10 {
+			t.Errorf("Tree %s came up %d times, expected about %d", tree, count, samples/16)
+		}
+	}
+}
+
+func TestRandomSpanningForest(t *testing.T) {
+	// Two cycles and an isolated node
+	g := graph.NewGonumGraph(false)
+	for i := 0; i < 9; i++ {
+		g.AddNode(graph.GonumNode(i), nil)
+	}
+	for i := 0; i < 4; i++ {
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(i), T: graph.GonumNode((i + 1) % 4)})
+		g.AddEdge(graph.GonumEdge{H: graph.GonumNode(4 + i), T: graph.GonumNode(4 + (i+1)%4)})
+	}
+
+	forest := graph.NewGonumGraph(false)
+	graph.RandomSpanningTree(forest, g, rand.New(rand.NewSource(1)))
+	if !graph.IsForest(forest) || len(forest.NodeList()) != 9 || len(forest.EdgeList()) != 2*6 {
+		t.Errorf("Expected a spanning forest of 9 nodes and 6 edges, got %v", forest.EdgeList())
+	}
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Computes a Weisfeiler-Lehman[1] fingerprint of the graph's structure. Each node starts with a label (from NodeLabel, or the empty string if it's nil), and every iteration relabels
+// each node with a hash of its own label and the sorted multiset of its neighbors' labels. The fingerprint is a hash of the label histograms of every iteration.
+//
+// Isomorphic graphs always produce the same hash, so if two hashes differ the graphs are certainly not isomorphic. The converse doesn't hold (the 1-WL test can't distinguish, e.g., regular graphs
+// of the same size and degree), so equal hashes should be confirmed with an actual isomorphism test such as VF2 if it matters. This makes it a cheap pre-filter for isomorphism and a good key
+// for deduplicating large sets of small graphs.
+//
+// If EdgeLabel is non-nil, the label of the edge to each neighbor is folded into that neighbor's contribution. Directed graphs hash their successors and predecessors separately.
+// Three or four iterations is typically plenty; the hash is stable across runs and platforms.
+//
+// [1] N. Shervashidze et al., "Weisfeiler-Lehman Graph Kernels", JMLR 12, 2011
+func WeisfeilerLehmanHash(graph Graph, iterations int, NodeLabel func(Node) string, EdgeLabel func(Edge) string) string {
+	labels := WeisfeilerLehmanLabels(graph, iterations, NodeLabel, EdgeLabel)
+
+	var buf []string
+	for _, round := range labels {
+		histogram := make(map[string]int)
+		for _, label := range round {
+			histogram[label]++
+		}
+
+		counts := make([]string, 0, len(histogram))
+		for label, count := range histogram {
+			counts = append(counts, label+":"+strconv.Itoa(count))
+		}
+		sort.Strings(counts)
+		buf = append(buf, strings.Join(counts, ","))
+	}
+
+	return wlDigest(strings.Join(buf, "|"))
+}
+
+// Returns the label of every node after each round of Weisfeiler-Lehman refinement. The result has iterations+1 entries, the first holding the initial labels, each mapping a node ID to its label at that round.
+// Two nodes with equal labels at round i have isomorphic i-hop neighborhoods as far as the 1-WL test can tell, so these double as per-node (subgraph) hashes.
+func WeisfeilerLehmanLabels(graph Graph, iterations int, NodeLabel func(Node) string, EdgeLabel func(Edge) string) []map[int]string {
+	nodes := graph.NodeList()
+	labels := make([]map[int]string, 0, iterations+1)
+
+	curr := make(map[int]string, len(nodes))
+	for _, node := range nodes {
+		if NodeLabel != nil {
+			curr[node.ID()] = wlDigest(NodeLabel(node))
+		} else {
+			curr[node.ID()] = wlDigest("")
+		}
+	}
+	labels = append(labels, curr)
+
+	for i := 0; i < iterations; i++ {
+		next := make(map[int]string, len(nodes))
+		for _, node := range nodes {
+			succs := make([]string, 0)
+			for _, succ := range graph.Successors(node) {
+				label := curr[succ.ID()]
+				if EdgeLabel != nil {
+					label = EdgeLabel(GonumEdge{H: node, T: succ}) + "/" + label
+				}
+				succs = append(succs, label)
+			}
+			sort.Strings(succs)
+			signature := curr[node.ID()] + ">" + strings.Join(succs, ",")
+
+			if graph.IsDirected() {
+				preds := make([]string, 0)
+				for _, pred := range graph.Predecessors(node) {
+					label := curr[pred.ID()]
+					if EdgeLabel != nil {
+						label = EdgeLabel(GonumEdge{H: pred, T: node}) + "/" + label
+					}
+					preds = append(preds, label)
+				}
+				sort.Strings(preds)
+				signature += "<" + strings.Join(preds, ",")
+			}
+
+			next[node.ID()] = wlDigest(signature)
+		}
+
+		labels = append(labels, next)
+		curr = next
+	}
+
+	return labels
+}
+
+func wlDigest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:16])
+}
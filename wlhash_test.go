@@ -0,0 +1,29 @@
+package graph_test
+
+import (
+	"github.com/gonum/graph"
+	"testing"
+)
+
+func TestWeisfeilerLehmanHash(t *testing.T) {
+	square := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}})
+	relabeled := buildGraph(false, [][2]int{{10, 12}, {12, 11}, {11, 13}, {13, 10}})
+	path := buildGraph(false, [][2]int{{0, 1}, {1, 2}, {2, 3}})
+
+	if graph.WeisfeilerLehmanHash(square, 3, nil, nil) != graph.WeisfeilerLehmanHash(relabeled, 3, nil, nil) {
+		t.Error("Isomorphic graphs hash differently")
+	}
+	if graph.WeisfeilerLehmanHash(square, 3, nil, nil) == graph.WeisfeilerLehmanHash(path, 3, nil, nil) {
+		t.Error("4-cycle and 4-path hash the same")
+	}
+
+	parity := func(n graph.Node) string {
+		if n.ID()%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	}
+	if graph.WeisfeilerLehmanHash(square, 3, parity, nil) == graph.WeisfeilerLehmanHash(relabeled, 3, parity, nil) {
+		t.Error("Node labels not taken into account")
+	}
+}